@@ -0,0 +1,37 @@
+package main
+
+import (
+	"os"
+
+	"github.com/ethereum-optimism/op-txverify/core"
+	cli "github.com/urfave/cli/v2"
+)
+
+// addressBookFlag returns the --address-book flag used by commands that verify transactions
+func addressBookFlag() cli.Flag {
+	return &cli.StringSliceFlag{
+		Name:  "address-book",
+		Usage: "Address book file of named addresses, including counterfactual create2 entries (repeatable; the default address book file is always loaded when present)",
+	}
+}
+
+// loadAddressBook loads the default address book file (if present) and every --address-book file
+func loadAddressBook(c *cli.Context) (*core.AddressRegistry, error) {
+	book := core.NewAddressRegistry()
+
+	if path, err := core.DefaultAddressBookPath(); err == nil {
+		if _, err := os.Stat(path); err == nil {
+			if err := book.LoadAddressBookFile(path); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	for _, path := range c.StringSlice("address-book") {
+		if err := book.LoadAddressBookFile(path); err != nil {
+			return nil, err
+		}
+	}
+
+	return book, nil
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/ethereum-optimism/op-txverify/core"
+	"github.com/ethereum-optimism/op-txverify/output"
+	cli "github.com/urfave/cli/v2"
+)
+
+// ceremonyCommand returns the command that verifies every transaction of a multi-Safe ceremony
+func ceremonyCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "ceremony",
+		Usage: "Verify an upgrade queued on several Safes and chains against its intended calls",
+		Description: "Fetches and verifies every transaction listed in a ceremony file, checks that each makes the\n" +
+			"call intended for its chain, and lists the hashes each signer signs. For a nested approval the\n" +
+			"intended call is that of the approved child transaction. Fails if any transaction differs.\n\n" +
+			"  {\"name\": \"Upgrade 14\", \"transactions\": [{\"name\": \"op\", \"network\": \"op\", \"safe\": \"0x...\",\n" +
+			"    \"nonce\": 97, \"signers\": [\"Foundation\"],\n" +
+			"    \"expect\": {\"to\": \"0x...\", \"operation\": 1, \"dataHash\": \"0x...\"}}]}",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:     "file",
+				Aliases:  []string{"f"},
+				Usage:    "Ceremony file listing the transactions and their intended calls (required)",
+				Required: true,
+			},
+			&cli.StringFlag{
+				Name:    "output",
+				Aliases: []string{"o"},
+				Usage:   "Output format: terminal, json",
+				Value:   "terminal",
+			},
+			denylistFlag(),
+			addressBookFlag(),
+			independentDecodeFlag(),
+			pagerFlag(),
+		},
+		Action: ceremonyAction,
+	}
+}
+
+func ceremonyAction(c *cli.Context) error {
+	outputFormat := c.String("output")
+	if outputFormat != "terminal" && outputFormat != "json" {
+		return fmt.Errorf("unknown output format: %s", outputFormat)
+	}
+
+	ceremony, err := core.LoadCeremonyFile(c.String("file"))
+	if err != nil {
+		return err
+	}
+	options, err := verifyOptions(c)
+	if err != nil {
+		return err
+	}
+
+	results := make([]*core.VerificationResult, 0, len(ceremony.Transactions))
+	for _, ceremonyTx := range ceremony.Transactions {
+		tx, err := core.GenerateTransaction(c.Context, ceremonyTx.Network, ceremonyTx.Safe, ceremonyTx.Nonce, "")
+		if err != nil {
+			return fmt.Errorf("%s: %w", ceremonyTx.Name, err)
+		}
+		result, err := core.VerifyTransaction(*tx, options)
+		if err != nil {
+			return fmt.Errorf("%s: error verifying transaction: %w", ceremonyTx.Name, err)
+		}
+		results = append(results, result)
+	}
+
+	report, err := core.CheckCeremony(ceremony, results)
+	if err != nil {
+		return err
+	}
+
+	if outputFormat == "json" {
+		err = output.FormatJSON(struct {
+			Report  *core.CeremonyReport       `json:"report"`
+			Results []*core.VerificationResult `json:"results"`
+		}{report, results}, os.Stdout)
+	} else {
+		err = writeTerminalOutput(c, func(w io.Writer) error {
+			for _, result := range results {
+				if err := output.FormatTerminal(result, w); err != nil {
+					return err
+				}
+			}
+			return output.FormatCeremonyTerminal(report, w)
+		})
+	}
+	if err != nil {
+		return err
+	}
+
+	if !report.OK() {
+		return fmt.Errorf("ceremony check failed")
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/ethereum-optimism/op-txverify/core"
+	"github.com/ethereum-optimism/op-txverify/output"
+	cli "github.com/urfave/cli/v2"
+)
+
+// checksCommand returns the command that describes the checks this build runs
+func checksCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "checks",
+		Usage: "Describe the checks this build runs on transactions",
+		Subcommands: []*cli.Command{
+			{
+				Name:  "list",
+				Usage: "List every analyzer with its version, parameters, and the warnings it raises",
+				Description: "Prints the check manifest of this build: each analyzer, when it runs, the thresholds and\n" +
+					"lists it uses, and its warning codes. The digest changes whenever any of them does, so a runbook\n" +
+					"or audit can record which protections were in force. Needs no network access.",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:    "output",
+						Aliases: []string{"o"},
+						Usage:   "Output format: terminal, json",
+						Value:   "terminal",
+					},
+					pagerFlag(),
+				},
+				Action: checksListAction,
+			},
+		},
+	}
+}
+
+func checksListAction(c *cli.Context) error {
+	outputFormat := c.String("output")
+	if outputFormat != "terminal" && outputFormat != "json" {
+		return fmt.Errorf("unknown output format: %s", outputFormat)
+	}
+
+	manifest, err := core.NewCheckManifest(Version, Commit)
+	if err != nil {
+		return err
+	}
+	if outputFormat == "json" {
+		return output.FormatJSON(manifest, os.Stdout)
+	}
+	return writeTerminalOutput(c, func(w io.Writer) error {
+		return output.FormatCheckManifestTerminal(manifest, w)
+	})
+}
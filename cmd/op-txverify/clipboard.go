@@ -0,0 +1,71 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/ethereum-optimism/op-txverify/core"
+	"github.com/ethereum-optimism/op-txverify/output"
+	cli "github.com/urfave/cli/v2"
+)
+
+// copyFlag returns the --copy flag used by commands that produce a verification result
+func copyFlag() cli.Flag {
+	return &cli.StringFlag{
+		Name:  "copy",
+		Usage: "Copy to the system clipboard after rendering: hashes",
+	}
+}
+
+// clipboardCommands lists the clipboard programs to try, in order, for each platform
+var clipboardCommands = map[string][][]string{
+	"darwin":  {{"pbcopy"}},
+	"windows": {{"clip"}},
+	"linux":   {{"wl-copy"}, {"xclip", "-selection", "clipboard"}, {"xsel", "--clipboard", "--input"}},
+}
+
+// copyResult handles --copy for a verification result
+func copyResult(c *cli.Context, result *core.VerificationResult) error {
+	switch what := c.String("copy"); what {
+	case "":
+		return nil
+	case "hashes":
+		if err := copyToClipboard(output.HashBlock(result), noExec(c)); err != nil {
+			return err
+		}
+		// stderr, so the rendered result on stdout stays parseable (--output json)
+		fmt.Fprintln(os.Stderr, "Copied domain, message, and Safe tx hashes to the clipboard.")
+		return nil
+	default:
+		return fmt.Errorf("invalid copy target: %s (must be hashes)", what)
+	}
+}
+
+// copyToClipboard writes text to the system clipboard using the first available clipboard
+// program. noExec forbids starting one, and so copying.
+func copyToClipboard(text string, noExec bool) error {
+	if noExec {
+		return fmt.Errorf("cannot copy to the clipboard: it needs a clipboard program, and --no-exec forbids starting one")
+	}
+	var tried []string
+	for _, args := range clipboardCommands[runtime.GOOS] {
+		if _, err := exec.LookPath(args[0]); err != nil {
+			tried = append(tried, args[0])
+			continue
+		}
+		cmd := exec.Command(args[0], args[1:]...)
+		cmd.Stdin = bytes.NewBufferString(text)
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("failed to copy to clipboard with %s: %w", args[0], err)
+		}
+		return nil
+	}
+	if len(tried) == 0 {
+		return fmt.Errorf("clipboard is not supported on %s", runtime.GOOS)
+	}
+	return fmt.Errorf("no clipboard program found (tried %s)", strings.Join(tried, ", "))
+}
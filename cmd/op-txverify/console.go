@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/ethereum-optimism/op-txverify/output"
+	"github.com/fatih/color"
+	cli "github.com/urfave/cli/v2"
+)
+
+// Glyph modes accepted by --glyphs
+const (
+	glyphsAuto    = "auto"
+	glyphsUnicode = "unicode"
+	glyphsASCII   = "ascii"
+)
+
+// consoleCapabilities is what the console stdout is attached to can show
+type consoleCapabilities struct {
+	// color is whether ANSI color sequences are interpreted rather than printed
+	color bool
+	// unicode is whether box-drawing characters and emoji are shown rather than garbled
+	unicode bool
+}
+
+// asciiGlyphs is whether terminal output replaces box-drawing characters and emoji with ASCII
+var asciiGlyphs bool
+
+// glyphsFlag returns the global flag that selects how box-drawing characters and emoji are printed
+func glyphsFlag() cli.Flag {
+	return &cli.StringFlag{
+		Name:  "glyphs",
+		Usage: "Print box-drawing characters and emoji as: auto (ASCII on consoles that cannot show them, such as the legacy Windows console), unicode, ascii",
+		Value: glyphsAuto,
+	}
+}
+
+// applyConsole prepares the console for terminal output and picks the glyphs it is printed with
+func applyConsole(c *cli.Context) error {
+	capabilities := setupConsole()
+	if !capabilities.color {
+		color.NoColor = true
+	}
+	switch mode := c.String("glyphs"); mode {
+	case "", glyphsAuto:
+		asciiGlyphs = !capabilities.unicode
+	case glyphsUnicode:
+		asciiGlyphs = false
+	case glyphsASCII:
+		asciiGlyphs = true
+	default:
+		return fmt.Errorf("invalid glyphs mode: %s (must be auto, unicode, or ascii)", mode)
+	}
+	return nil
+}
+
+// console returns w, replacing box-drawing characters and emoji with ASCII when the console
+// cannot show them. Terminal output is written through it; JSON is not, so that it stays
+// exactly what was verified.
+func console(w io.Writer) io.Writer {
+	if asciiGlyphs {
+		return output.NewASCIIWriter(w)
+	}
+	return w
+}
@@ -0,0 +1,9 @@
+//go:build !windows
+
+package main
+
+// setupConsole has nothing to prepare on this platform: terminals interpret ANSI colors, and
+// fatih/color already disables them when stdout is not a terminal
+func setupConsole() consoleCapabilities {
+	return consoleCapabilities{color: true, unicode: true}
+}
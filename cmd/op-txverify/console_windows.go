@@ -0,0 +1,38 @@
+//go:build windows
+
+package main
+
+import (
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// utf8CodePage is the console code page of UTF-8
+const utf8CodePage = 65001
+
+// setupConsole turns on ANSI color sequences and UTF-8 output for the console stdout is attached
+// to, and reports what it can show. The console host of Windows 10 and later interprets ANSI
+// sequences once asked to; older versions print them as garbage, so colors are turned off there.
+// The legacy console's fonts have no emoji and, on most code pages, no box-drawing characters,
+// so unicode is only reported for terminals known to show them: Windows Terminal, ConEmu,
+// VS Code, and mintty or other terminals that set TERM.
+func setupConsole() consoleCapabilities {
+	stdout := windows.Handle(os.Stdout.Fd())
+	var mode uint32
+	if err := windows.GetConsoleMode(stdout, &mode); err != nil {
+		// Not a console: a file, a pipe, or a terminal such as mintty that talks through pipes
+		return consoleCapabilities{color: true, unicode: true}
+	}
+
+	capabilities := consoleCapabilities{
+		color: windows.SetConsoleMode(stdout, mode|windows.ENABLE_VIRTUAL_TERMINAL_PROCESSING) == nil,
+	}
+	if windows.SetConsoleOutputCP(utf8CodePage) == nil {
+		capabilities.unicode = os.Getenv("WT_SESSION") != "" ||
+			os.Getenv("ConEmuANSI") == "ON" ||
+			os.Getenv("TERM_PROGRAM") == "vscode" ||
+			os.Getenv("TERM") != ""
+	}
+	return capabilities
+}
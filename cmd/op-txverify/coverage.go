@@ -0,0 +1,58 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/ethereum-optimism/op-txverify/core"
+	"github.com/ethereum-optimism/op-txverify/output"
+	cli "github.com/urfave/cli/v2"
+)
+
+// coverageCommand returns the command that reports which calls of a transaction were decoded
+func coverageCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "coverage",
+		Usage: "Report how many calls of a transaction decoded and which selectors are missing",
+		Description: "Counts the calls of a transaction file, including batched subcalls and approved child\n" +
+			"transactions, that decoded fully, decoded only their function, or were not recognized, and lists\n" +
+			"the missing selectors with links to look up their signatures and the targets' verified source.",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:     "tx",
+				Aliases:  []string{"t"},
+				Usage:    "Path to transaction file (required)",
+				Required: true,
+			},
+			&cli.StringFlag{
+				Name:    "output",
+				Aliases: []string{"o"},
+				Usage:   "Output format: terminal, json",
+				Value:   "terminal",
+			},
+			pagerFlag(),
+		},
+		Action: coverageAction,
+	}
+}
+
+func coverageAction(c *cli.Context) error {
+	outputFormat := c.String("output")
+	if outputFormat != "terminal" && outputFormat != "json" {
+		return fmt.Errorf("unknown output format: %s", outputFormat)
+	}
+
+	result, err := readTransactionFile(c.String("tx"), core.VerifyOptions{})
+	if err != nil {
+		return err
+	}
+	report := core.Coverage(result)
+
+	if outputFormat == "json" {
+		return output.FormatJSON(report, os.Stdout)
+	}
+	return writeTerminalOutput(c, func(w io.Writer) error {
+		return output.FormatCoverageTerminal(report, w)
+	})
+}
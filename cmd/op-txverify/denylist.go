@@ -0,0 +1,168 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ethereum-optimism/op-txverify/core"
+	cli "github.com/urfave/cli/v2"
+)
+
+// denylistFlag returns the --denylist flag used by commands that verify transactions
+func denylistFlag() cli.Flag {
+	return &cli.StringSliceFlag{
+		Name:  "denylist",
+		Usage: "Denylist file or https manifest URL of known-malicious addresses (repeatable; the default denylist file is always loaded when present)",
+	}
+}
+
+// independentDecodeFlag returns the --independent-decode flag used by commands that verify
+// transactions
+func independentDecodeFlag() cli.Flag {
+	return &cli.BoolFlag{
+		Name:  "independent-decode",
+		Usage: "Decode calldata with two independent decoders and fail if they disagree",
+	}
+}
+
+// complianceFlag returns the --compliance flag used by commands that verify transactions
+func complianceFlag() cli.Flag {
+	return &cli.BoolFlag{
+		Name:  "compliance",
+		Usage: "Add a summary of the transaction's value flows (asset, amount, counterparty, label, chain) for compliance review",
+	}
+}
+
+// annotationsFlag returns the --annotations flag used by commands that verify transactions
+func annotationsFlag() cli.Flag {
+	return &cli.StringSliceFlag{
+		Name:  "annotations",
+		Usage: "Annotations file from the facilitator explaining decoded arguments (\"<path> = <explanation>\" per line, or JSON), shown next to the values (repeatable)",
+	}
+}
+
+// grantsFlag returns the --grants flag used by commands that verify transactions
+func grantsFlag() cli.Flag {
+	return &cli.StringSliceFlag{
+		Name:  "grants",
+		Usage: "Grants manifest (JSON) published by the grants council; every vesting schedule the transaction creates must match a grant's recipient, total, and duration (repeatable)",
+	}
+}
+
+// verifyOptions builds the verification options shared by the verifying commands
+func verifyOptions(c *cli.Context) (core.VerifyOptions, error) {
+	denylist, err := loadDenylist(c)
+	if err != nil {
+		return core.VerifyOptions{}, err
+	}
+	addressBook, err := loadAddressBook(c)
+	if err != nil {
+		return core.VerifyOptions{}, err
+	}
+	annotations := core.NewArgumentAnnotations()
+	for _, path := range c.StringSlice("annotations") {
+		if err := annotations.LoadArgumentAnnotationsFile(path); err != nil {
+			return core.VerifyOptions{}, err
+		}
+	}
+	config, err := loadConfig(c)
+	if err != nil {
+		return core.VerifyOptions{}, err
+	}
+	for _, role := range config.Roles {
+		core.RegisterRole(role)
+	}
+	var grants *core.GrantsManifest
+	for _, path := range c.StringSlice("grants") {
+		if grants == nil {
+			grants = core.NewGrantsManifest()
+		}
+		if err := grants.LoadGrantsManifestFile(path); err != nil {
+			return core.VerifyOptions{}, err
+		}
+	}
+	return core.VerifyOptions{
+		Verbosity:         c.Count("verbose"),
+		Denylist:          denylist,
+		AddressBook:       addressBook,
+		IndependentDecode: c.Bool("independent-decode"),
+		Compliance:        c.Bool("compliance"),
+		Annotations:       annotations,
+		Grants:            grants,
+	}, nil
+}
+
+// loadDenylist loads the default denylist file (if present), the policy of the --profile, and
+// every --denylist source
+func loadDenylist(c *cli.Context) (*core.Denylist, error) {
+	denylist := core.NewDenylist()
+
+	if path, err := core.DefaultDenylistPath(); err == nil {
+		if _, err := os.Stat(path); err == nil {
+			if err := denylist.LoadDenylistFile(path); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	profile, err := selectedProfile(c)
+	if err != nil {
+		return nil, err
+	}
+	if profile != nil && profile.Policy != "" {
+		if err := denylist.LoadDenylistFile(profile.Policy); err != nil {
+			return nil, fmt.Errorf("profile %s: %w", profile.Name, err)
+		}
+	}
+
+	for _, source := range c.StringSlice("denylist") {
+		if strings.Contains(source, "://") {
+			data, err := core.FetchDenylistManifest(c.Context, source)
+			if err != nil {
+				return nil, err
+			}
+			if err := denylist.ParseDenylist(source, data); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if err := denylist.LoadDenylistFile(source); err != nil {
+			return nil, err
+		}
+	}
+
+	return denylist, nil
+}
+
+func updateDenylistAction(c *cli.Context) error {
+	out := c.String("output")
+	if out == "" {
+		path, err := core.DefaultDenylistPath()
+		if err != nil {
+			return fmt.Errorf("failed to locate default denylist: %w", err)
+		}
+		out = path
+	}
+
+	data, err := core.FetchDenylistManifest(c.Context, c.String("url"))
+	if err != nil {
+		return err
+	}
+
+	denylist := core.NewDenylist()
+	if err := denylist.ParseDenylist(c.String("url"), data); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(out), 0o755); err != nil {
+		return fmt.Errorf("failed to create denylist directory: %w", err)
+	}
+	if err := os.WriteFile(out, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write denylist: %w", err)
+	}
+
+	fmt.Printf("Saved %d denylisted addresses to %s\n", len(denylist.Entries), out)
+	return nil
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/ethereum-optimism/op-txverify/core"
+	"github.com/ethereum-optimism/op-txverify/doctor"
+	"github.com/ethereum-optimism/op-txverify/output"
+	"github.com/ethereum-optimism/op-txverify/qr"
+	"github.com/ethereum-optimism/op-txverify/registry"
+	"github.com/fatih/color"
+	cli "github.com/urfave/cli/v2"
+)
+
+// doctorCommand returns the command that checks a machine is ready for a signing ceremony
+func doctorCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "doctor",
+		Usage: "Check that this machine is ready for a signing ceremony",
+		Description: "Checks the clock against the Safe services, that the Safe services, mirrors, and RPC endpoints\n" +
+			"of the networks are reachable (or, with --offline, that none are), that the camera scanner can\n" +
+			"start, the terminal, the build and digest of this binary, and how fresh the registry cache is.\n" +
+			"Prints a readiness report and fails if any check failed, so facilitators can require it first.",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:  "offline",
+				Usage: "This is an air-gapped machine: check that no host is reachable instead",
+			},
+			&cli.StringSliceFlag{
+				Name:    "network",
+				Aliases: []string{"n"},
+				Usage:   "Network the ceremony uses: " + core.NetworkNames + " (repeatable; defaults to all)",
+			},
+			&cli.StringSliceFlag{
+				Name:  "host",
+				Usage: "Another URL the ceremony needs to reach (repeatable)",
+			},
+			&cli.StringFlag{
+				Name:  "sha256sums",
+				Usage: "Release SHA256SUMS file that must list the digest of this binary",
+			},
+			&cli.DurationFlag{
+				Name:  "max-clock-skew",
+				Usage: "Fail when the clock is further than this from the Safe service's",
+				Value: doctor.DefaultMaxClockSkew,
+			},
+			&cli.DurationFlag{
+				Name:  "max-registry-age",
+				Usage: "Warn when the registry cache was synced longer ago than this",
+				Value: doctor.DefaultMaxRegistryAge,
+			},
+			configFlag(),
+			&cli.StringFlag{
+				Name:    "output",
+				Aliases: []string{"o"},
+				Usage:   "Output format: terminal, json",
+				Value:   "terminal",
+			},
+		},
+		Action: doctorAction,
+	}
+}
+
+func doctorAction(c *cli.Context) error {
+	outputFormat := c.String("output")
+	if outputFormat != "terminal" && outputFormat != "json" {
+		return fmt.Errorf("unknown output format: %s", outputFormat)
+	}
+	config, err := loadConfig(c)
+	if err != nil {
+		return err
+	}
+	hosts, err := doctorHosts(c, config)
+	if err != nil {
+		return err
+	}
+	// Without a cache directory there is no registry cache to check
+	registryCache, _ := registry.DefaultCachePath()
+
+	report := doctor.Run(c.Context, doctor.Options{
+		Version:        Version,
+		Commit:         Commit,
+		Offline:        c.Bool("offline"),
+		Hosts:          hosts,
+		MaxClockSkew:   c.Duration("max-clock-skew"),
+		RegistryCache:  registryCache,
+		MaxRegistryAge: c.Duration("max-registry-age"),
+		Sums:           c.String("sha256sums"),
+	})
+	qr.CheckCamera(report, qrOptions(c))
+	checkTerminal(report)
+
+	if outputFormat == "json" {
+		err = output.FormatJSON(report, os.Stdout)
+	} else {
+		err = output.FormatDoctorReportTerminal(report, console(os.Stdout))
+	}
+	if err != nil {
+		return err
+	}
+	if !report.Ready() {
+		return fmt.Errorf("this machine is not ready: %d check(s) failed", report.Count(doctor.Fail))
+	}
+	return nil
+}
+
+// doctorHosts returns the Safe services, service mirrors, and configured RPC endpoints of the
+// --network chains, or of every supported chain, followed by each --host
+func doctorHosts(c *cli.Context, config *core.Config) ([]string, error) {
+	chains := map[uint64]bool{}
+	for _, network := range c.StringSlice("network") {
+		if err := core.ValidateNetwork(network); err != nil {
+			return nil, err
+		}
+		chains[core.Networks[strings.ToLower(network)]] = true
+	}
+	if len(chains) == 0 {
+		for chainID := range core.SafeServiceURLs {
+			chains[chainID] = true
+		}
+	}
+	chainIDs := make([]uint64, 0, len(chains))
+	for chainID := range chains {
+		chainIDs = append(chainIDs, chainID)
+	}
+	sort.Slice(chainIDs, func(i, j int) bool { return chainIDs[i] < chainIDs[j] })
+
+	var hosts []string
+	for _, chainID := range chainIDs {
+		hosts = append(hosts, core.SafeServiceURLs[chainID])
+		hosts = append(hosts, config.ServiceMirrors[chainID]...)
+		hosts = append(hosts, config.RPCURLs(chainID)...)
+	}
+	return append(hosts, c.StringSlice("host")...), nil
+}
+
+// checkTerminal adds what the console can show to a readiness report. Prompts and review
+// sessions need stdin and stdout to be a terminal.
+func checkTerminal(report *doctor.Report) {
+	colors := "colors on"
+	if color.NoColor {
+		colors = "colors off"
+	}
+	glyphs := "Unicode glyphs"
+	if asciiGlyphs {
+		glyphs = "ASCII glyphs"
+	}
+	switch {
+	case !isTerminal(os.Stdin):
+		report.Add("terminal", doctor.Warn, "stdin is not a terminal, so prompts and review sessions cannot be answered (%s, %s)", colors, glyphs)
+	case !isTerminal(os.Stdout):
+		report.Add("terminal", doctor.Warn, "stdout is not a terminal, so output is not paged or colored (%s)", glyphs)
+	default:
+		report.Add("terminal", doctor.Pass, "stdin and stdout are terminals (%s, %s)", colors, glyphs)
+	}
+}
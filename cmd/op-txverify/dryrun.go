@@ -0,0 +1,40 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/ethereum-optimism/op-txverify/core"
+	cli "github.com/urfave/cli/v2"
+)
+
+// dryRun prints the requests of a --dry-run instead of sending them, and is nil otherwise
+var dryRun *core.DryRunTransport
+
+// dryRunFlag returns the global flag that prints requests instead of sending them
+func dryRunFlag() cli.Flag {
+	return &cli.BoolFlag{
+		Name:  "dry-run",
+		Usage: "Print every HTTP request and RPC call instead of sending it; the command stops at the first response it needs",
+	}
+}
+
+// applyDryRun routes every request through the dry-run transport. All of the clients of core
+// send through http.DefaultClient.
+func applyDryRun(c *cli.Context) {
+	if c.Bool("dry-run") {
+		dryRun = &core.DryRunTransport{Out: os.Stderr}
+		http.DefaultClient.Transport = dryRun
+	}
+}
+
+// dryRunStopped reports whether a command failed because a dry run did not send its requests,
+// explaining so
+func dryRunStopped() bool {
+	if dryRun == nil || dryRun.Requests() == 0 {
+		return false
+	}
+	fmt.Fprintf(os.Stderr, "Dry run: %d request(s) printed, none sent. Later requests depend on the responses, so the command stopped here.\n", dryRun.Requests())
+	return true
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"errors"
+
+	"github.com/ethereum-optimism/op-txverify/core"
+)
+
+// exitCodes are the exit codes of the kinds of errors scripts may want to tell apart, checked in
+// order. Any other error exits with 1.
+var exitCodes = []struct {
+	kind error
+	code int
+}{
+	{core.ErrPolicyViolation, 2},
+	{core.ErrTxNotFound, 3},
+	{core.ErrUnsupportedChain, 4},
+	{core.ErrUnsupportedSafeVersion, 5},
+	{core.ErrDecodeFailure, 6},
+}
+
+// exitCode returns the exit code of an error
+func exitCode(err error) int {
+	for _, exit := range exitCodes {
+		if errors.Is(err, exit.kind) {
+			return exit.code
+		}
+	}
+	return 1
+}
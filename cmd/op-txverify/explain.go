@@ -0,0 +1,63 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/ethereum-optimism/op-txverify/core"
+	"github.com/ethereum-optimism/op-txverify/output"
+	cli "github.com/urfave/cli/v2"
+)
+
+// explainCommand returns the command that explains warning codes
+func explainCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "explain",
+		Usage:     "Explain what a warning code means, why it matters, and what to do about it",
+		ArgsUsage: "[OPTX-W001]",
+		Description: "Every warning is printed with a code such as OPTX-W007. Explains the code given, or lists\n" +
+			"every code without one. The explanations are built in and need no network access.",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    "output",
+				Aliases: []string{"o"},
+				Usage:   "Output format: terminal, json",
+				Value:   "terminal",
+			},
+			pagerFlag(),
+		},
+		Action: explainAction,
+	}
+}
+
+func explainAction(c *cli.Context) error {
+	outputFormat := c.String("output")
+	if outputFormat != "terminal" && outputFormat != "json" {
+		return fmt.Errorf("unknown output format: %s", outputFormat)
+	}
+	if c.NArg() > 1 {
+		return fmt.Errorf("explain takes one warning code, got %d", c.NArg())
+	}
+
+	if c.NArg() == 0 {
+		checks := core.WarningChecks()
+		if outputFormat == "json" {
+			return output.FormatJSON(checks, os.Stdout)
+		}
+		return writeTerminalOutput(c, func(w io.Writer) error {
+			return output.FormatWarningChecksTerminal(checks, w)
+		})
+	}
+
+	check, err := core.LookupWarningCheck(c.Args().First())
+	if err != nil {
+		return fmt.Errorf("%w; run `op-txverify explain` to list the codes", err)
+	}
+	if outputFormat == "json" {
+		return output.FormatJSON(check, os.Stdout)
+	}
+	return writeTerminalOutput(c, func(w io.Writer) error {
+		return output.FormatWarningCheckTerminal(check, w)
+	})
+}
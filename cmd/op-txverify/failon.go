@@ -0,0 +1,15 @@
+package main
+
+import (
+	"github.com/ethereum-optimism/op-txverify/core"
+	cli "github.com/urfave/cli/v2"
+)
+
+// failOnFlag returns the --fail-on flag that sets which warnings fail a verifying command
+func failOnFlag() cli.Flag {
+	return &cli.StringFlag{
+		Name:  "fail-on",
+		Usage: "Exit with status 2 after printing the result when verification raises a warning of this severity or above: warning, critical, or never",
+		Value: core.FailOnNever,
+	}
+}
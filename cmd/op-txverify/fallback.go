@@ -0,0 +1,49 @@
+package main
+
+import (
+	"time"
+
+	"github.com/ethereum-optimism/op-txverify/core"
+	cli "github.com/urfave/cli/v2"
+)
+
+// serviceAttempts and serviceBackoff are how often and how patiently the Safe service is asked
+// before its fallbacks are
+const (
+	serviceAttempts = 3
+	serviceBackoff  = time.Second
+)
+
+// serviceFallbackFlag returns the global flag that turns off the Safe service fallbacks
+func serviceFallbackFlag() cli.Flag {
+	return &cli.BoolFlag{
+		Name:  "no-service-fallback",
+		Usage: "Only ask the Safe Transaction Service, without retrying it or falling back to mirrors, cached responses, or the chain",
+	}
+}
+
+// applyServiceFallback retries the Safe service and falls back to the mirrors and RPC endpoints
+// of the default configuration file and to the cached responses of the service
+func applyServiceFallback(c *cli.Context) error {
+	if c.Bool("no-service-fallback") {
+		return nil
+	}
+	fallback := core.ServiceFallback{Attempts: serviceAttempts, Backoff: serviceBackoff}
+	if dryRun != nil {
+		// Retrying a request that is never sent would only print it again
+		fallback.Attempts = 1
+	}
+	if dir, err := core.DefaultServiceCacheDir(); err == nil {
+		fallback.Cache = &core.ServiceCache{Dir: dir}
+	}
+	if path, err := core.DefaultConfigPath(); err == nil {
+		config, err := core.LoadConfigFile(path, true)
+		if err != nil {
+			return err
+		}
+		fallback.Mirrors = config.ServiceMirrors
+		fallback.RPC = config.RPC
+	}
+	core.SetServiceFallback(fallback)
+	return nil
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ethereum-optimism/op-txverify/core"
+	"github.com/ethereum-optimism/op-txverify/output"
+	cli "github.com/urfave/cli/v2"
+)
+
+// graphFlag returns the --graph flag shared by verifying commands
+func graphFlag() cli.Flag {
+	return &cli.StringFlag{
+		Name:  "graph",
+		Usage: "Also write the call graph to this file: Mermaid for .mmd or .mermaid files, Graphviz DOT otherwise",
+	}
+}
+
+// writeGraph writes the call graph of a result to the --graph file, if one was given
+func writeGraph(c *cli.Context, result *core.VerificationResult) error {
+	path := c.String("graph")
+	if path == "" {
+		return nil
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create graph file: %w", err)
+	}
+	defer file.Close()
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".mmd", ".mermaid":
+		err = output.FormatMermaid(result, file, c.Bool("expand-all"))
+	default:
+		err = output.FormatDOT(result, file, c.Bool("expand-all"))
+	}
+	if err != nil {
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to write graph file: %w", err)
+	}
+	fmt.Fprintf(os.Stderr, "Wrote the call graph to %s\n", path)
+	return nil
+}
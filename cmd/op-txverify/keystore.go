@@ -0,0 +1,219 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/ethereum-optimism/op-txverify/core"
+	"github.com/ethereum-optimism/op-txverify/kms"
+	"github.com/ethereum-optimism/op-txverify/output"
+	"github.com/ethereum-optimism/op-txverify/qr"
+	cli "github.com/urfave/cli/v2"
+	"golang.org/x/oauth2"
+	"google.golang.org/api/option"
+)
+
+// signCommand returns the command that signs a verified transaction with an encrypted keyfile
+func signCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "sign",
+		Usage: "Verify a transaction file and sign it with an encrypted keyfile or a cloud KMS key",
+		Description: "For operational Safes whose owners do not all use hardware wallets. The transaction is\n" +
+			"verified and shown first; signing is refused if verification raises a critical warning.\n\n" +
+			"KMS keys must be secp256k1 keys. AWS KMS finds credentials and region as the AWS CLI does; Cloud\n" +
+			"KMS uses Application Default Credentials, or GOOGLE_OAUTH_ACCESS_TOKEN when it is set.",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:     "tx",
+				Usage:    "Path to transaction JSON file (required)",
+				Required: true,
+			},
+			&cli.StringFlag{
+				Name:    "keystore",
+				Aliases: []string{"k"},
+				Usage:   "Path to a version 3 encrypted keyfile (geth, cast, and most wallets)",
+			},
+			&cli.StringFlag{
+				Name:  "kms",
+				Usage: "Cloud KMS key to sign with: aws:<key ID, alias, or ARN> or gcp:<key version resource name>",
+			},
+			&cli.StringFlag{
+				Name:  "passphrase-file",
+				Usage: "Read the keyfile passphrase from this file instead of prompting",
+			},
+			&cli.StringFlag{
+				Name:  "hash",
+				Usage: "Safe tx hash to sign (required for nested approvals, to choose the child or parent hash)",
+			},
+			&cli.StringFlag{
+				Name:    "output",
+				Aliases: []string{"o"},
+				Usage:   "Output format: terminal, json, qr (show the signature as QR codes for import-signature)",
+				Value:   "terminal",
+			},
+			denylistFlag(),
+			addressBookFlag(),
+			independentDecodeFlag(),
+			scheduleFlag(),
+			deadlineFlag(),
+			notBeforeFlag(),
+		},
+		Action: signAction,
+	}
+}
+
+func signAction(c *cli.Context) error {
+	options, err := verifyOptions(c)
+	if err != nil {
+		return err
+	}
+	result, err := readTransactionFile(c.String("tx"), options)
+	if err != nil {
+		return err
+	}
+
+	if err := checkSchedule(c, result); err != nil {
+		return err
+	}
+
+	// Always show what is being signed, even when the signature itself is written as JSON
+	if err := output.FormatTerminal(result, console(os.Stderr)); err != nil {
+		return err
+	}
+	if err := core.RefuseCritical("sign", result.Warnings); err != nil {
+		return err
+	}
+
+	// Refuse a --hash this transaction does not produce before anything is unlocked or signed
+	hash, err := core.SignableHash(result, c.String("hash"))
+	if err != nil {
+		return err
+	}
+
+	signer, err := loadSigner(c)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Signing as %s\n", signer.Address())
+
+	signature, err := signer.SignSafeTxHash(c.Context, hash)
+	if err != nil {
+		return err
+	}
+	export, err := core.NewSignatureExport(result, hash, signature)
+	if err != nil {
+		return err
+	}
+
+	switch c.String("output") {
+	case "json":
+		return output.FormatJSON(export, os.Stdout)
+	case "terminal":
+		return output.FormatSignatureTerminal(*export, "", console(os.Stdout))
+	case "qr":
+		if err := output.FormatSignatureTerminal(*export, "", console(os.Stdout)); err != nil {
+			return err
+		}
+		payload, err := json.Marshal(export)
+		if err != nil {
+			return fmt.Errorf("failed to encode signature: %w", err)
+		}
+		return qr.DisplayQRCode(c.Context, payload, qrOptions(c))
+	default:
+		return fmt.Errorf("unknown output format: %s", c.String("output"))
+	}
+}
+
+// loadSigner returns the signer selected by --keystore or --kms
+func loadSigner(c *cli.Context) (core.Signer, error) {
+	keystorePath, kmsKey := c.String("keystore"), c.String("kms")
+	if (keystorePath == "") == (kmsKey == "") {
+		return nil, fmt.Errorf("exactly one of --keystore or --kms is required")
+	}
+	if kmsKey != "" {
+		backend, err := kmsBackend(c.Context, kmsKey)
+		if err != nil {
+			return nil, err
+		}
+		return core.NewRemoteSigner(c.Context, backend)
+	}
+
+	data, err := os.ReadFile(keystorePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read keystore: %w", err)
+	}
+	keystore, err := core.ParseKeystore(data)
+	if err != nil {
+		return nil, err
+	}
+	for _, warning := range keystore.Warnings() {
+		fmt.Fprintf(console(os.Stderr), "⚠️  [%s] %s\n", warning.Code, warning.Message)
+	}
+	passphrase, err := keystorePassphrase(c)
+	if err != nil {
+		return nil, err
+	}
+	return keystore.Decrypt(passphrase)
+}
+
+// kmsBackend parses a --kms value. Credentials are found the way each provider's own CLI finds
+// them; a dry run sends the requests through the dry-run transport instead.
+func kmsBackend(ctx context.Context, spec string) (core.DigestSigner, error) {
+	provider, key, ok := strings.Cut(spec, ":")
+	if !ok || key == "" {
+		return nil, fmt.Errorf("invalid --kms value %q: expected aws:<key> or gcp:<key version>", spec)
+	}
+	switch provider {
+	case "aws":
+		var optFns []func(*config.LoadOptions) error
+		if dryRun != nil {
+			optFns = append(optFns, config.WithHTTPClient(http.DefaultClient))
+		}
+		return kms.NewAWSSigner(ctx, key, optFns...)
+	case "gcp":
+		var opts []option.ClientOption
+		if token := os.Getenv("GOOGLE_OAUTH_ACCESS_TOKEN"); token != "" {
+			opts = append(opts, option.WithTokenSource(oauth2.StaticTokenSource(&oauth2.Token{AccessToken: token})))
+		}
+		if dryRun != nil {
+			opts = append(opts, option.WithHTTPClient(http.DefaultClient))
+		}
+		return kms.NewGCPSigner(ctx, key, opts...)
+	default:
+		return nil, fmt.Errorf("unknown KMS provider %q: must be aws or gcp", provider)
+	}
+}
+
+// keystorePassphrase reads the keyfile passphrase from --passphrase-file or prompts for it
+func keystorePassphrase(c *cli.Context) (string, error) {
+	if path := c.String("passphrase-file"); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("failed to read passphrase file: %w", err)
+		}
+		return strings.TrimRight(string(data), "\r\n"), nil
+	}
+
+	fmt.Fprint(os.Stderr, "Keystore passphrase: ")
+	passphrase, err := readPassphrase(os.Stdin)
+	fmt.Fprintln(os.Stderr, "")
+	if err != nil {
+		return "", fmt.Errorf("failed to read passphrase: %w", err)
+	}
+	return passphrase, nil
+}
+
+// readLine reads a single line from f without buffering past it
+func readLine(f *os.File) (string, error) {
+	line, err := bufio.NewReaderSize(f, 16).ReadString('\n')
+	if err != nil && line == "" {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
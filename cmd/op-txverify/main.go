@@ -1,14 +1,19 @@
 package main
 
 import (
-	"encoding/base64"
+	"context"
 	"encoding/json"
 	"fmt"
-	"net/url"
+	"io"
 	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
 
 	"github.com/ethereum-optimism/op-txverify/core"
 	"github.com/ethereum-optimism/op-txverify/output"
+	"github.com/ethereum-optimism/op-txverify/qr"
 	cli "github.com/urfave/cli/v2"
 )
 
@@ -44,17 +49,56 @@ func main() {
 						Usage:    "Path to transaction file (required)",
 						Required: true,
 					},
+					&cli.StringFlag{
+						Name:    "network",
+						Aliases: []string{"n"},
+						Usage:   "Network of a transaction file that does not name its chain, such as a Safe transaction service proposal: " + core.NetworkNames,
+					},
+					&cli.StringFlag{
+						Name:  "safe-version",
+						Usage: "Safe version of a transaction file that does not name it (typed data only implies a range of versions)",
+					},
+					&cli.IntFlag{
+						Name:  "nonce",
+						Usage: "Safe nonce of a forge script broadcast artifact, which does not record it",
+					},
 					&cli.StringFlag{
 						Name:    "output",
 						Aliases: []string{"o"},
-						Usage:   "Output format: terminal, json",
+						Usage:   "Output format: terminal, json, mermaid (call graph)",
 						Value:   "terminal",
 					},
+					pagerFlag(),
+					verboseFlag(),
+					showFlag(),
 					&cli.BoolFlag{
-						Name:    "verbose",
-						Aliases: []string{"v"},
-						Usage:   "Show verbose output",
+						Name:  "expand-all",
+						Usage: "Show every subcall in full instead of grouping identical calls",
 					},
+					copyFlag(),
+					&cli.BoolFlag{
+						Name:  "phonetic",
+						Usage: "Spell out the Safe tx hash in NATO alphabet groups for comparing by phone",
+					},
+					denylistFlag(),
+					addressBookFlag(),
+					independentDecodeFlag(),
+					&cli.BoolFlag{
+						Name:  "annotate-calldata",
+						Usage: "Print the raw calldata word by word with byte offsets, labelled with the decoded fields (terminal output)",
+					},
+					roleFlag(),
+					graphFlag(),
+					templateFlag(),
+					redactFlag(),
+					complianceFlag(),
+					annotationsFlag(),
+					grantsFlag(),
+					scheduleFlag(),
+					deadlineFlag(),
+					notBeforeFlag(),
+					sessionFlag(),
+					failOnFlag(),
 				},
 				Action: offlineAction,
 			},
@@ -63,33 +107,68 @@ func main() {
 				Usage: "Generate and verify a transaction in one step",
 				Flags: []cli.Flag{
 					&cli.StringFlag{
-						Name:     "network",
-						Aliases:  []string{"n"},
-						Usage:    "Network name: ethereum, op, base (required)",
-						Required: true,
+						Name:    "network",
+						Aliases: []string{"n"},
+						Usage:   "Network name: " + core.NetworkNames + " (prompted for if omitted)",
 					},
 					&cli.StringFlag{
-						Name:     "safe",
-						Aliases:  []string{"a"},
-						Usage:    "Safe address (required)",
-						Required: true,
+						Name:    "safe",
+						Aliases: []string{"a"},
+						Usage:   "Safe address (prompted for if omitted, offering recently verified Safes)",
 					},
+					profileFlag(),
 					&cli.Uint64Flag{
-						Name:     "nonce",
-						Usage:    "Transaction nonce (required)",
-						Required: true,
+						Name:  "nonce",
+						Usage: "Transaction nonce (prompted for if omitted, offering the Safe's pending transactions)",
+					},
+					safeTxHashFlag(),
+					&cli.BoolFlag{
+						Name:  "diff-previous",
+						Usage: "Show what changed since the transaction at the previous nonce, such as the one a corrected transaction replaces",
+					},
+					&cli.Uint64Flag{
+						Name:  "diff-nonce",
+						Usage: "Nonce of the transaction to show changes since (implies --diff-previous)",
 					},
 					&cli.StringFlag{
 						Name:    "output",
 						Aliases: []string{"o"},
-						Usage:   "Output format: terminal, json",
+						Usage:   "Output format: terminal, json, mermaid (call graph)",
 						Value:   "terminal",
 					},
+					pagerFlag(),
+					verboseFlag(),
+					showFlag(),
 					&cli.BoolFlag{
-						Name:    "verbose",
-						Aliases: []string{"v"},
-						Usage:   "Show verbose output",
+						Name:  "expand-all",
+						Usage: "Show every subcall in full instead of grouping identical calls",
+					},
+					copyFlag(),
+					&cli.BoolFlag{
+						Name:  "phonetic",
+						Usage: "Spell out the Safe tx hash in NATO alphabet groups for comparing by phone",
 					},
+					denylistFlag(),
+					addressBookFlag(),
+					independentDecodeFlag(),
+					&cli.BoolFlag{
+						Name:  "annotate-calldata",
+						Usage: "Print the raw calldata word by word with byte offsets, labelled with the decoded fields (terminal output)",
+					},
+					roleFlag(),
+					graphFlag(),
+					templateFlag(),
+					redactFlag(),
+					complianceFlag(),
+					annotationsFlag(),
+					grantsFlag(),
+					scheduleFlag(),
+					deadlineFlag(),
+					notBeforeFlag(),
+					sessionFlag(),
+					failOnFlag(),
+					rpcURLFlag(),
+					configFlag(),
 				},
 				Action: onlineAction,
 			},
@@ -98,22 +177,21 @@ func main() {
 				Usage: "Generate a transaction JSON file",
 				Flags: []cli.Flag{
 					&cli.StringFlag{
-						Name:     "network",
-						Aliases:  []string{"n"},
-						Usage:    "Network name: ethereum, op, base (required)",
-						Required: true,
+						Name:    "network",
+						Aliases: []string{"n"},
+						Usage:   "Network name: " + core.NetworkNames + " (prompted for if omitted)",
 					},
 					&cli.StringFlag{
-						Name:     "safe",
-						Aliases:  []string{"a"},
-						Usage:    "Safe address (required)",
-						Required: true,
+						Name:    "safe",
+						Aliases: []string{"a"},
+						Usage:   "Safe address (prompted for if omitted, offering recently verified Safes)",
 					},
+					profileFlag(),
 					&cli.Uint64Flag{
-						Name:     "nonce",
-						Usage:    "Transaction nonce (required)",
-						Required: true,
+						Name:  "nonce",
+						Usage: "Transaction nonce (prompted for if omitted, offering the Safe's pending transactions)",
 					},
+					safeTxHashFlag(),
 					&cli.StringFlag{
 						Name:    "output",
 						Aliases: []string{"o"},
@@ -122,6 +200,90 @@ func main() {
 				},
 				Action: downloadAction,
 			},
+			{
+				Name:  "modules",
+				Usage: "Show transactions executed on a Safe by its enabled modules",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:    "network",
+						Aliases: []string{"n"},
+						Usage:   "Network name: " + core.NetworkNames + " (required unless --profile is given)",
+					},
+					&cli.StringFlag{
+						Name:    "safe",
+						Aliases: []string{"a"},
+						Usage:   "Safe address (required unless --profile is given)",
+					},
+					profileFlag(),
+					&cli.IntFlag{
+						Name:  "limit",
+						Usage: "Number of most recent module transactions to show",
+						Value: 10,
+					},
+					&cli.StringFlag{
+						Name:    "output",
+						Aliases: []string{"o"},
+						Usage:   "Output format: terminal, json",
+						Value:   "terminal",
+					},
+					pagerFlag(),
+					verboseFlag(),
+				},
+				Action: modulesAction,
+			},
+			{
+				Name:  "messages",
+				Usage: "Show and hash the off-chain messages proposed for a Safe",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:    "network",
+						Aliases: []string{"n"},
+						Usage:   "Network name: " + core.NetworkNames + " (required unless --profile is given)",
+					},
+					&cli.StringFlag{
+						Name:    "safe",
+						Aliases: []string{"a"},
+						Usage:   "Safe address (required unless --profile is given)",
+					},
+					profileFlag(),
+					&cli.BoolFlag{
+						Name:  "pending",
+						Usage: "Only show messages with fewer confirmations than the Safe's threshold",
+					},
+					&cli.IntFlag{
+						Name:  "limit",
+						Usage: "Show at most this many messages, newest first (0 shows all)",
+					},
+					&cli.IntFlag{
+						Name:  "offset",
+						Usage: "Skip this many of the newest messages, to page through the rest with --limit",
+					},
+					&cli.StringFlag{
+						Name:    "output",
+						Aliases: []string{"o"},
+						Usage:   "Output format: terminal, json",
+						Value:   "terminal",
+					},
+					pagerFlag(),
+				},
+				Action: messagesAction,
+			},
+			{
+				Name:      "compare-hashes",
+				Usage:     "Compare hashes from other signers' outputs",
+				ArgsUsage: "[file|- ...]",
+				Description: "Reads two or more outputs (files, or - for stdin) and reports whether each hash matches.\n" +
+					"With no arguments, reads stdin and splits it into blobs on lines containing only ---.",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:    "output",
+						Aliases: []string{"o"},
+						Usage:   "Output format: terminal, json",
+						Value:   "terminal",
+					},
+				},
+				Action: compareHashesAction,
+			},
 			{
 				Name:  "qr",
 				Usage: "Scan a transaction QR code using your camera",
@@ -135,8 +297,94 @@ func main() {
 					&cli.StringFlag{
 						Name:    "url",
 						Aliases: []string{"u"},
-						Usage:   "Link with base64-encoded tx param (skips scanner)",
+						Usage:   "Deprecated: use the url command. Link with base64-encoded tx param, or a Safe UI transaction link (skips scanner)",
+					},
+					&cli.StringFlag{
+						Name:    "output",
+						Aliases: []string{"o"},
+						Usage:   "Output format: terminal, json, mermaid (call graph)",
+						Value:   "terminal",
+					},
+					pagerFlag(),
+					verboseFlag(),
+					showFlag(),
+					&cli.BoolFlag{
+						Name:  "expand-all",
+						Usage: "Show every subcall in full instead of grouping identical calls",
+					},
+					copyFlag(),
+					&cli.BoolFlag{
+						Name:  "phonetic",
+						Usage: "Spell out the Safe tx hash in NATO alphabet groups for comparing by phone",
+					},
+					denylistFlag(),
+					addressBookFlag(),
+					independentDecodeFlag(),
+					&cli.BoolFlag{
+						Name:  "annotate-calldata",
+						Usage: "Print the raw calldata word by word with byte offsets, labelled with the decoded fields (terminal output)",
+					},
+					roleFlag(),
+					graphFlag(),
+					templateFlag(),
+					redactFlag(),
+					complianceFlag(),
+					annotationsFlag(),
+					grantsFlag(),
+					scheduleFlag(),
+					deadlineFlag(),
+					notBeforeFlag(),
+					sessionFlag(),
+					failOnFlag(),
+					rpcURLFlag(),
+					configFlag(),
+				},
+				Action: qrAction,
+			},
+			{
+				Name:  "runbook",
+				Usage: "Generate a markdown runbook for a signing ceremony",
+				Description: "Verifies the transaction, then writes the commands each signer runs, the expected hashes,\n" +
+					"a QR code link, and checklists for signers and the facilitator.",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:    "network",
+						Aliases: []string{"n"},
+						Usage:   "Network name: " + core.NetworkNames + " (prompted for if omitted)",
+					},
+					&cli.StringFlag{
+						Name:    "safe",
+						Aliases: []string{"a"},
+						Usage:   "Safe address (prompted for if omitted, offering recently verified Safes)",
+					},
+					profileFlag(),
+					&cli.Uint64Flag{
+						Name:  "nonce",
+						Usage: "Transaction nonce (prompted for if omitted, offering the Safe's pending transactions)",
+					},
+					safeTxHashFlag(),
+					&cli.StringFlag{
+						Name:    "output",
+						Aliases: []string{"o"},
+						Usage:   "Output file path (defaults to stdout if not specified)",
+					},
+					&cli.StringFlag{
+						Name:  "qr-site",
+						Usage: "Page that displays a transaction as QR codes",
+						Value: output.DefaultQRSiteURL,
 					},
+					showFlag(),
+					denylistFlag(),
+					addressBookFlag(),
+					independentDecodeFlag(),
+					grantsFlag(),
+				},
+				Action: runbookAction,
+			},
+			{
+				Name:  "provenance",
+				Usage: "Show how this binary was built: module versions, commit, build flags, and digests",
+				Flags: []cli.Flag{
 					&cli.StringFlag{
 						Name:    "output",
 						Aliases: []string{"o"},
@@ -146,40 +394,241 @@ func main() {
 					&cli.BoolFlag{
 						Name:    "verbose",
 						Aliases: []string{"v"},
-						Usage:   "Show verbose output",
+						Usage:   "List every dependency compiled into the binary",
 					},
 				},
-				Action: qrAction,
+				Action: provenanceAction,
+			},
+			{
+				Name:  "perturb",
+				Usage: "Training mode: change one field at a time and show how every hash changes",
+				Description: "Flips the target's last byte, multiplies the value by 10, moves the nonce by one, and so on,\n" +
+					"recomputing the hashes each time to show that the signature binds every field.\n" +
+					"The perturbed transactions are for demonstration only and must never be signed.",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:     "tx",
+						Usage:    "Path to transaction JSON file (required)",
+						Required: true,
+					},
+					&cli.StringSliceFlag{
+						Name:  "field",
+						Usage: "Field to perturb (repeatable): " + strings.Join(core.PerturbFields, ", ") + " (defaults to all)",
+					},
+					&cli.StringFlag{
+						Name:    "output",
+						Aliases: []string{"o"},
+						Usage:   "Output format: terminal, json",
+						Value:   "terminal",
+					},
+					pagerFlag(),
+				},
+				Action: perturbAction,
+			},
+			{
+				Name:  "airdrop",
+				Usage: "Cross-check a Safe CSV Airdrop file against the transfers a batch transaction makes",
+				Description: "Every CSV row must match a transfer in the transaction, with the same token, receiver,\n" +
+					"and amount, and the transaction must send nothing else. Rows and calls may be in any order.",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:     "tx",
+						Usage:    "Path to transaction JSON file (required)",
+						Required: true,
+					},
+					&cli.StringFlag{
+						Name:     "csv",
+						Usage:    "Path to the CSV file used to build the batch (required)",
+						Required: true,
+					},
+					&cli.StringSliceFlag{
+						Name:  "decimals",
+						Usage: "Decimals of a token that is not a known contract, as <token address>=<decimals> (repeatable)",
+					},
+					&cli.StringFlag{
+						Name:    "output",
+						Aliases: []string{"o"},
+						Usage:   "Output format: terminal, json",
+						Value:   "terminal",
+					},
+					pagerFlag(),
+					denylistFlag(),
+					addressBookFlag(),
+					independentDecodeFlag(),
+				},
+				Action: airdropAction,
+			},
+			{
+				Name:  "commit",
+				Usage: "Compute a Merkle root and per-token totals over the calls of a large batch so reviewers can split it",
+				Description: "Reviewers each check one chunk of calls with --chunk N. The chunk roots combine into the\n" +
+					"batch's Merkle root, so everyone comparing the same commitment at the end reviewed the same batch.",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:     "tx",
+						Usage:    "Path to transaction JSON file (required)",
+						Required: true,
+					},
+					&cli.IntFlag{
+						Name:  "chunk-size",
+						Usage: "Calls per chunk, a power of two",
+						Value: 64,
+					},
+					&cli.IntFlag{
+						Name:  "chunk",
+						Usage: "List the calls of this chunk for review, starting at 1",
+					},
+					&cli.StringSliceFlag{
+						Name:  "decimals",
+						Usage: "Decimals of a token that is not a known contract, as <token address>=<decimals> (repeatable)",
+					},
+					&cli.StringFlag{
+						Name:    "output",
+						Aliases: []string{"o"},
+						Usage:   "Output format: terminal, json",
+						Value:   "terminal",
+					},
+					pagerFlag(),
+					denylistFlag(),
+					addressBookFlag(),
+					independentDecodeFlag(),
+				},
+				Action: commitAction,
+			},
+			{
+				Name:  "withdrawal",
+				Usage: "Recompute the hash of a withdrawal a transaction proves or finalizes through an OptimismPortal",
+				Description: "Recomputes the withdrawal hash from the parameters the call supplies. For\n" +
+					"proveWithdrawalTransaction it also computes the output root and checks that the storage\n" +
+					"proof shows the withdrawal in the message passer storage root. With an L2 node, it checks\n" +
+					"that L2ToL1MessagePasser committed to the withdrawal.",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:     "tx",
+						Usage:    "Path to transaction JSON file (required)",
+						Required: true,
+					},
+					l2RPCURLFlag(),
+					configFlag(),
+					&cli.StringFlag{
+						Name:    "output",
+						Aliases: []string{"o"},
+						Usage:   "Output format: terminal, json",
+						Value:   "terminal",
+					},
+					pagerFlag(),
+					denylistFlag(),
+					addressBookFlag(),
+					independentDecodeFlag(),
+				},
+				Action: withdrawalAction,
+			},
+			{
+				Name:  "userop",
+				Usage: "Verify an ERC-4337 UserOperation for a Safe operated through Safe4337Module",
+				Description: "Owners of Safes using Safe4337Module sign a SafeOp rather than a Safe transaction. This\n" +
+					"computes the SafeOp hashes, decodes the call the Safe makes, and flags the paymaster,\n" +
+					"factory, and validity window.",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:     "file",
+						Aliases:  []string{"f"},
+						Usage:    "Path to a JSON file with chain, entryPoint, validAfter, validUntil, and userOperation (required)",
+						Required: true,
+					},
+					&cli.StringFlag{
+						Name:    "output",
+						Aliases: []string{"o"},
+						Usage:   "Output format: terminal, json",
+						Value:   "terminal",
+					},
+					pagerFlag(),
+					denylistFlag(),
+					addressBookFlag(),
+					independentDecodeFlag(),
+				},
+				Action: userOperationAction,
+			},
+			{
+				Name:  "update-denylist",
+				Usage: "Download a denylist manifest of known-malicious addresses",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:     "url",
+						Aliases:  []string{"u"},
+						Usage:    "HTTPS URL of the denylist manifest (required)",
+						Required: true,
+					},
+					&cli.StringFlag{
+						Name:    "output",
+						Aliases: []string{"o"},
+						Usage:   "File to write (defaults to the denylist file loaded automatically)",
+					},
+				},
+				Action: updateDenylistAction,
 			},
 		},
 	}
 
-	if err := app.Run(os.Args); err != nil {
+	app.Commands = append(app.Commands, urlCommand())
+	app.Commands = append(app.Commands, shareCommand())
+	app.Commands = append(app.Commands, signCommand())
+	app.Commands = append(app.Commands, signatureCommands()...)
+	app.Commands = append(app.Commands, rpcCheckCommand())
+	app.Commands = append(app.Commands, safesCommand())
+	app.Commands = append(app.Commands, ceremonyCommand())
+	app.Commands = append(app.Commands, coverageCommand())
+	app.Commands = append(app.Commands, registryCommand())
+	app.Commands = append(app.Commands, lintRegistryCommand())
+	app.Commands = append(app.Commands, explainCommand())
+	app.Commands = append(app.Commands, checksCommand())
+	app.Commands = append(app.Commands, doctorCommand())
+	app.Flags = append(app.Flags, themeFlag(), glyphsFlag(), serviceFallbackFlag(), dryRunFlag(), noExecFlag())
+	app.Before = func(c *cli.Context) error {
+		if err := applyConsole(c); err != nil {
+			return err
+		}
+		if err := applyTheme(c); err != nil {
+			return err
+		}
+		applyDryRun(c)
+		if err := applyNoExec(c); err != nil {
+			return err
+		}
+		if err := applyServiceFallback(c); err != nil {
+			return err
+		}
+		return applyRegistryCache(c)
+	}
+
+	// Cancel in-flight requests and shut down the scanner server on Ctrl+C
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := app.RunContext(ctx, os.Args); err != nil {
+		if dryRunStopped() {
+			return
+		}
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+		stop()
+		os.Exit(exitCode(err))
 	}
 }
 
 func offlineAction(c *cli.Context) error {
-	txFile := c.String("tx")
-	outputFormat := c.String("output")
-	verbose := c.Bool("verbose")
-
-	// Read the transaction file
-	data, err := os.ReadFile(txFile)
+	// Read and parse the transaction file
+	tx, err := parseOfflineTransactionFile(c)
 	if err != nil {
-		return fmt.Errorf("failed to read transaction file: %w", err)
+		return err
 	}
-
-	// Parse the transaction
-	var tx core.SafeTransaction
-	if err := json.Unmarshal(data, &tx); err != nil {
-		return fmt.Errorf("failed to parse transaction: %w", err)
+	if err := core.CompleteTransaction(&tx, c.String("network"), c.String("safe-version")); err != nil {
+		return err
 	}
 
 	// Set verification options
-	options := core.VerifyOptions{
-		Verbose: verbose,
+	options, err := verifyOptions(c)
+	if err != nil {
+		return err
 	}
 
 	// Verify the transaction
@@ -188,43 +637,36 @@ func offlineAction(c *cli.Context) error {
 		return fmt.Errorf("error verifying transaction: %w", err)
 	}
 
-	// Output the result in the requested format
-	switch outputFormat {
-	case "json":
-		output.FormatJSON(result, os.Stdout)
-	case "terminal":
-		output.FormatTerminal(result, os.Stdout)
-	default:
-		return fmt.Errorf("unknown output format: %s", outputFormat)
+	if err := checkSchedule(c, result); err != nil {
+		return err
 	}
 
-	return nil
+	// Output the result in the requested format
+	return renderResult(c, result)
 }
 
 func onlineAction(c *cli.Context) error {
-	network := c.String("network")
-	address := c.String("safe")
-	nonce := c.Uint64("nonce")
-	outputFormat := c.String("output")
-	verbose := c.Bool("verbose")
+	network, address, nonce, err := transactionTarget(c)
+	if err != nil {
+		return err
+	}
 
 	// Validate network
-	if network != "ethereum" && network != "op" && network != "base" && network != "sepolia" {
-		return fmt.Errorf("invalid network: %s (must be ethereum, op, or base)", network)
+	if err := core.ValidateNetwork(network); err != nil {
+		return err
 	}
 
-	// Strip the chain prefix if present
-	address = core.StripChainPrefix(address)
-
-	// Generate the transaction
-	tx, err := core.GenerateTransaction(network, address, nonce)
+	// Generate the transaction (a chain prefix on the address is cross-checked against the network)
+	tx, err := generateTransaction(c.Context, network, address, nonce, c.String("safe-tx-hash"))
 	if err != nil {
 		return err
 	}
+	rememberSafe(network, address)
 
 	// Set verification options
-	options := core.VerifyOptions{
-		Verbose: verbose,
+	options, err := verifyOptions(c)
+	if err != nil {
+		return err
 	}
 
 	// Verify the generated transaction
@@ -233,35 +675,78 @@ func onlineAction(c *cli.Context) error {
 		return fmt.Errorf("error verifying transaction: %w", err)
 	}
 
+	// Attestations about an already executed transaction should only be made once it is final,
+	// and against what it actually did
+	if err := checkExecution(c, result); err != nil {
+		return err
+	}
+	if err := checkOwnerChanges(c, result); err != nil {
+		return err
+	}
+	if err := checkETHRecipients(c, result); err != nil {
+		return err
+	}
+
+	if err := checkSchedule(c, result); err != nil {
+		return err
+	}
+	if err := diffPrevious(c, network, address, nonce, options, result); err != nil {
+		return err
+	}
+
 	// Output the result in the requested format
-	switch outputFormat {
-	case "json":
-		output.FormatJSON(result, os.Stdout)
-	case "terminal":
-		output.FormatTerminal(result, os.Stdout)
-	default:
-		return fmt.Errorf("unknown output format: %s", outputFormat)
+	return renderResult(c, result)
+}
+
+// diffPrevious fetches and verifies the transaction at the previous nonce (or --diff-nonce) of the
+// same Safe and records how the verified transaction differs from it, when asked to
+func diffPrevious(c *cli.Context, network, address string, nonce uint64, options core.VerifyOptions, result *core.VerificationResult) error {
+	if !c.Bool("diff-previous") && !c.IsSet("diff-nonce") {
+		return nil
+	}
+	previousNonce := c.Uint64("diff-nonce")
+	if !c.IsSet("diff-nonce") {
+		if nonce == 0 {
+			return fmt.Errorf("there is no nonce before 0 to show changes since; give one with --diff-nonce")
+		}
+		previousNonce = nonce - 1
+	}
+	if previousNonce == nonce {
+		return fmt.Errorf("--diff-nonce must differ from the verified nonce %d", nonce)
 	}
 
+	tx, err := generateTransaction(c.Context, network, address, previousNonce, "")
+	if err != nil {
+		return fmt.Errorf("error fetching the transaction to show changes since: %w", err)
+	}
+	// The earlier transaction is only compared against, so its annotations would be misplaced
+	options.Annotations = nil
+	previous, err := core.VerifyTransaction(*tx, options)
+	if err != nil {
+		return fmt.Errorf("error verifying the transaction at nonce %d: %w", previousNonce, err)
+	}
+	result.PreviousDiff = core.DiffTransactions(previous, result)
 	return nil
 }
 
 func downloadAction(c *cli.Context) error {
-	network := c.String("network")
-	address := c.String("safe")
-	nonce := c.Uint64("nonce")
+	network, address, nonce, err := transactionTarget(c)
+	if err != nil {
+		return err
+	}
 	outputFile := c.String("output")
 
 	// Validate network
-	if network != "ethereum" && network != "op" && network != "base" {
-		return fmt.Errorf("invalid network: %s (must be ethereum, op, or base)", network)
+	if err := core.ValidateNetwork(network); err != nil {
+		return err
 	}
 
 	// Generate the transaction JSON
-	tx, err := core.GenerateTransaction(network, address, nonce)
+	tx, err := generateTransaction(c.Context, network, address, nonce, c.String("safe-tx-hash"))
 	if err != nil {
 		return fmt.Errorf("error generating transaction: %w", err)
 	}
+	rememberSafe(network, address)
 
 	// Output the transaction JSON
 	if outputFile != "" {
@@ -277,45 +762,497 @@ func downloadAction(c *cli.Context) error {
 	return output.FormatJSON(tx, os.Stdout)
 }
 
+func modulesAction(c *cli.Context) error {
+	network, address, err := safeTarget(c)
+	if err != nil {
+		return err
+	}
+	limit := c.Int("limit")
+	outputFormat := c.String("output")
+
+	if limit <= 0 {
+		return fmt.Errorf("invalid limit: %d (must be positive)", limit)
+	}
+
+	results, err := core.FetchModuleTransactions(c.Context, network, address, limit, core.VerifyOptions{Verbosity: c.Count("verbose")})
+	if err != nil {
+		return err
+	}
+
+	switch outputFormat {
+	case "json":
+		return output.FormatJSON(results, os.Stdout)
+	case "terminal":
+		return writeTerminalOutput(c, func(w io.Writer) error {
+			return output.FormatModuleTransactionsTerminal(results, w)
+		})
+	default:
+		return fmt.Errorf("unknown output format: %s", outputFormat)
+	}
+}
+
+func messagesAction(c *cli.Context) error {
+	network, address, err := safeTarget(c)
+	if err != nil {
+		return err
+	}
+	outputFormat := c.String("output")
+
+	results, err := core.FetchMessages(c.Context, network, address, core.MessageFilter{
+		Pending: c.Bool("pending"),
+		Offset:  c.Int("offset"),
+		Limit:   c.Int("limit"),
+	})
+	if err != nil {
+		return err
+	}
+
+	switch outputFormat {
+	case "json":
+		return output.FormatJSON(results, os.Stdout)
+	case "terminal":
+		return writeTerminalOutput(c, func(w io.Writer) error {
+			return output.FormatMessagesTerminal(results, w)
+		})
+	default:
+		return fmt.Errorf("unknown output format: %s", outputFormat)
+	}
+}
+
+func compareHashesAction(c *cli.Context) error {
+	outputFormat := c.String("output")
+
+	blobs, err := readHashBlobs(c.Args().Slice())
+	if err != nil {
+		return err
+	}
+	if len(blobs) < 2 {
+		return fmt.Errorf("need at least two outputs to compare, got %d", len(blobs))
+	}
+
+	sets := make([]core.HashSet, 0, len(blobs))
+	for _, blob := range blobs {
+		set, err := core.ParseHashSet(blob.source, blob.text)
+		if err != nil {
+			return err
+		}
+		sets = append(sets, set)
+	}
+
+	comparisons := core.CompareHashSets(sets)
+	if len(comparisons) == 0 {
+		return fmt.Errorf("no hash was reported by more than one output")
+	}
+
+	switch outputFormat {
+	case "json":
+		err = output.FormatJSON(comparisons, os.Stdout)
+	case "terminal":
+		err = output.FormatHashComparisonTerminal(comparisons, console(os.Stdout))
+	default:
+		return fmt.Errorf("unknown output format: %s", outputFormat)
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, comparison := range comparisons {
+		if !comparison.Match {
+			return fmt.Errorf("hash mismatch")
+		}
+	}
+	return nil
+}
+
+func runbookAction(c *cli.Context) error {
+	network, address, nonce, err := transactionTarget(c)
+	if err != nil {
+		return err
+	}
+	outputFile := c.String("output")
+
+	// Validate network
+	if err := core.ValidateNetwork(network); err != nil {
+		return err
+	}
+
+	tx, err := generateTransaction(c.Context, network, address, nonce, c.String("safe-tx-hash"))
+	if err != nil {
+		return fmt.Errorf("error generating transaction: %w", err)
+	}
+	rememberSafe(network, address)
+
+	options, err := verifyOptions(c)
+	if err != nil {
+		return err
+	}
+
+	result, err := core.VerifyTransaction(*tx, options)
+	if err != nil {
+		return fmt.Errorf("error verifying transaction: %w", err)
+	}
+
+	show, err := output.ParseSections(c.String("show"))
+	if err != nil {
+		return err
+	}
+	runbookOptions := output.RunbookOptions{
+		Network:   network,
+		Safe:      address,
+		Nonce:     nonce,
+		QRSiteURL: c.String("qr-site"),
+		Show:      show,
+	}
+
+	if outputFile != "" {
+		file, err := os.Create(outputFile)
+		if err != nil {
+			return fmt.Errorf("error creating output file: %w", err)
+		}
+		defer file.Close()
+		return output.FormatRunbookMarkdown(*tx, result, runbookOptions, file)
+	}
+	return output.FormatRunbookMarkdown(*tx, result, runbookOptions, os.Stdout)
+}
+
+func provenanceAction(c *cli.Context) error {
+	outputFormat := c.String("output")
+
+	provenance, err := core.ReadBuildProvenance(Version, Commit)
+	if err != nil {
+		return err
+	}
+
+	switch outputFormat {
+	case "json":
+		return output.FormatJSON(provenance, os.Stdout)
+	case "terminal":
+		return output.FormatBuildProvenanceTerminal(provenance, console(os.Stdout), c.Bool("verbose"))
+	default:
+		return fmt.Errorf("unknown output format: %s", outputFormat)
+	}
+}
+
+func perturbAction(c *cli.Context) error {
+	outputFormat := c.String("output")
+
+	tx, err := parseTransactionFile(c.String("tx"))
+	if err != nil {
+		return err
+	}
+
+	report, err := core.PerturbTransaction(tx, c.StringSlice("field"))
+	if err != nil {
+		return err
+	}
+
+	switch outputFormat {
+	case "json":
+		return output.FormatJSON(report, os.Stdout)
+	case "terminal":
+		return writeTerminalOutput(c, func(w io.Writer) error {
+			return output.FormatPerturbationsTerminal(report, w)
+		})
+	default:
+		return fmt.Errorf("unknown output format: %s", outputFormat)
+	}
+}
+
+func airdropAction(c *cli.Context) error {
+	outputFormat := c.String("output")
+
+	options, err := verifyOptions(c)
+	if err != nil {
+		return err
+	}
+	result, err := readTransactionFile(c.String("tx"), options)
+	if err != nil {
+		return err
+	}
+	// For a nested approval the batch is the child transaction
+	tx := result.Transaction
+	if result.NestedResult != nil {
+		tx = result.NestedResult.Transaction
+	}
+
+	file, err := os.Open(c.String("csv"))
+	if err != nil {
+		return fmt.Errorf("failed to read CSV file: %w", err)
+	}
+	defer file.Close()
+	rows, err := core.ParseAirdropCSV(file)
+	if err != nil {
+		return err
+	}
+
+	decimals, err := tokenDecimals(c)
+	if err != nil {
+		return err
+	}
+
+	check, err := core.CheckAirdrop(tx, rows, decimals)
+	if err != nil {
+		return err
+	}
+
+	switch outputFormat {
+	case "json":
+		err = output.FormatJSON(check, os.Stdout)
+	case "terminal":
+		err = writeTerminalOutput(c, func(w io.Writer) error {
+			if err := output.FormatTerminal(result, w); err != nil {
+				return err
+			}
+			return output.FormatAirdropCheckTerminal(check, w)
+		})
+	default:
+		return fmt.Errorf("unknown output format: %s", outputFormat)
+	}
+	if err != nil {
+		return err
+	}
+
+	if !check.OK() {
+		return fmt.Errorf("airdrop file and transaction diverge")
+	}
+	return nil
+}
+
+func commitAction(c *cli.Context) error {
+	outputFormat := c.String("output")
+
+	options, err := verifyOptions(c)
+	if err != nil {
+		return err
+	}
+	result, err := readTransactionFile(c.String("tx"), options)
+	if err != nil {
+		return err
+	}
+	// For a nested approval the batch is the child transaction
+	tx := result.Transaction
+	if result.NestedResult != nil {
+		tx = result.NestedResult.Transaction
+	}
+
+	decimals, err := tokenDecimals(c)
+	if err != nil {
+		return err
+	}
+	commitment, err := core.CommitBatch(tx, c.Int("chunk-size"), decimals)
+	if err != nil {
+		return err
+	}
+	if chunk := c.Int("chunk"); chunk != 0 {
+		if _, _, err := commitment.Chunk(chunk); err != nil {
+			return err
+		}
+	}
+
+	switch outputFormat {
+	case "json":
+		return output.FormatJSON(commitment, os.Stdout)
+	case "terminal":
+		return writeTerminalOutput(c, func(w io.Writer) error {
+			if err := output.FormatTerminal(result, w); err != nil {
+				return err
+			}
+			return output.FormatBatchCommitmentTerminal(commitment, c.Int("chunk"), w)
+		})
+	default:
+		return fmt.Errorf("unknown output format: %s", outputFormat)
+	}
+}
+
+func withdrawalAction(c *cli.Context) error {
+	outputFormat := c.String("output")
+
+	options, err := verifyOptions(c)
+	if err != nil {
+		return err
+	}
+	result, err := readTransactionFile(c.String("tx"), options)
+	if err != nil {
+		return err
+	}
+	// For a nested approval the withdrawal is made by the child transaction
+	tx := result.Transaction
+	if result.NestedResult != nil {
+		tx = result.NestedResult.Transaction
+	}
+
+	check, err := core.CheckWithdrawals(tx)
+	if err != nil {
+		return err
+	}
+	client, err := l2RPCClient(c, check)
+	if err != nil {
+		return err
+	}
+	if client != nil {
+		if err := core.CheckWithdrawalCommitments(c.Context, client, check); err != nil {
+			return err
+		}
+	}
+
+	switch outputFormat {
+	case "json":
+		err = output.FormatJSON(check, os.Stdout)
+	case "terminal":
+		err = writeTerminalOutput(c, func(w io.Writer) error {
+			if err := output.FormatTerminal(result, w); err != nil {
+				return err
+			}
+			return output.FormatWithdrawalCheckTerminal(check, w)
+		})
+	default:
+		return fmt.Errorf("unknown output format: %s", outputFormat)
+	}
+	if err != nil {
+		return err
+	}
+
+	if !check.OK() {
+		return fmt.Errorf("withdrawal parameters do not match the proof or the L2 commitment")
+	}
+	return nil
+}
+
+// tokenDecimals parses the --decimals flag into decimals keyed by lowercase token address
+func tokenDecimals(c *cli.Context) (map[string]int, error) {
+	decimals := map[string]int{}
+	for _, value := range c.StringSlice("decimals") {
+		token, digits, ok := strings.Cut(value, "=")
+		n, err := strconv.Atoi(digits)
+		if !ok || err != nil || n < 0 || n > 77 || token == "" || core.ValidateFullAddress("token", token) != nil {
+			return nil, fmt.Errorf("invalid --decimals value %q: expected <token address>=<decimals>", value)
+		}
+		decimals[strings.ToLower(token)] = n
+	}
+	return decimals, nil
+}
+
+func userOperationAction(c *cli.Context) error {
+	outputFormat := c.String("output")
+
+	options, err := verifyOptions(c)
+	if err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(c.String("file"))
+	if err != nil {
+		return fmt.Errorf("failed to read user operation file: %w", err)
+	}
+
+	var request core.UserOperationRequest
+	if err := json.Unmarshal(data, &request); err != nil {
+		return fmt.Errorf("failed to parse user operation: %w", err)
+	}
+
+	result, err := core.VerifyUserOperation(request, options)
+	if err != nil {
+		return err
+	}
+
+	switch outputFormat {
+	case "json":
+		return output.FormatJSON(result, os.Stdout)
+	case "terminal":
+		return writeTerminalOutput(c, func(w io.Writer) error {
+			return output.FormatUserOperationTerminal(result, w)
+		})
+	default:
+		return fmt.Errorf("unknown output format: %s", outputFormat)
+	}
+}
+
+// hashBlob is one signer's pasted output
+type hashBlob struct {
+	source string
+	text   string
+}
+
+// readHashBlobs reads the named files ("-" is stdin), or splits stdin on "---" lines when no
+// files are given
+func readHashBlobs(paths []string) ([]hashBlob, error) {
+	if len(paths) == 0 {
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read stdin: %w", err)
+		}
+		var blobs []hashBlob
+		var current []string
+		flush := func() {
+			if text := strings.TrimSpace(strings.Join(current, "\n")); text != "" {
+				blobs = append(blobs, hashBlob{source: fmt.Sprintf("blob %d", len(blobs)+1), text: text})
+			}
+			current = nil
+		}
+		for _, line := range strings.Split(string(data), "\n") {
+			if strings.TrimSpace(line) == "---" {
+				flush()
+				continue
+			}
+			current = append(current, line)
+		}
+		flush()
+		return blobs, nil
+	}
+
+	blobs := make([]hashBlob, 0, len(paths))
+	for _, path := range paths {
+		var data []byte
+		var err error
+		if path == "-" {
+			data, err = io.ReadAll(os.Stdin)
+		} else {
+			data, err = os.ReadFile(path)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		blobs = append(blobs, hashBlob{source: path, text: string(data)})
+	}
+	return blobs, nil
+}
+
 func qrAction(c *cli.Context) error {
 	deviceID := c.String("device")
 	rawURL := c.String("url")
-	outputFormat := c.String("output")
-	verbose := c.Bool("verbose")
 
 	var tx core.SafeTransaction
+	var linkWarnings []core.Warning
 
 	if rawURL != "" {
-		// Parse tx from provided URL: extract tx query param, base64-decode, then JSON-decode
-		parsed, err := url.Parse(rawURL)
-		if err != nil {
-			return fmt.Errorf("invalid url: %w", err)
-		}
-		txParam := parsed.Query().Get("tx")
-		if txParam == "" {
-			return fmt.Errorf("tx parameter not found in url")
+		fmt.Fprintln(os.Stderr, "qr --url is deprecated and will be removed; use: op-txverify url <link>")
+		if core.IsQueueItemLink(rawURL) {
+			// Safe UI transaction links reference a queue item by id rather than embedding it
+			return safeUILinkAction(c, rawURL)
 		}
-		decoded, err := base64.StdEncoding.DecodeString(txParam)
+		linked, warnings, err := core.DecodeTransactionLink(rawURL)
 		if err != nil {
-			return fmt.Errorf("invalid base64 tx parameter: %w", err)
-		}
-		if err := json.Unmarshal(decoded, &tx); err != nil {
-			return fmt.Errorf("failed to parse transaction from url: %w", err)
+			return err
 		}
+		tx = *linked
+		linkWarnings = warnings
 	} else {
 		// Scan QR code from camera
-		data, err := core.ScanQRCode(deviceID)
+		data, err := qr.ScanQRCode(c.Context, deviceID, qrOptions(c))
 		if err != nil {
 			return fmt.Errorf("failed to scan QR code: %w", err)
 		}
-		if err := json.Unmarshal([]byte(data), &tx); err != nil {
+		scanned, err := core.DecodeTransactionPayload([]byte(data))
+		if err != nil {
 			return fmt.Errorf("failed to parse transaction from QR code: %w", err)
 		}
+		tx = *scanned
 	}
 
 	// Set verification options
-	options := core.VerifyOptions{
-		Verbose: verbose,
+	options, err := verifyOptions(c)
+	if err != nil {
+		return err
 	}
 
 	// Verify the transaction
@@ -323,16 +1260,146 @@ func qrAction(c *cli.Context) error {
 	if err != nil {
 		return fmt.Errorf("error verifying transaction: %w", err)
 	}
+	result.Warnings = append(result.Warnings, linkWarnings...)
+
+	if err := checkSchedule(c, result); err != nil {
+		return err
+	}
 
 	// Output the result in the requested format
-	switch outputFormat {
-	case "json":
-		output.FormatJSON(result, os.Stdout)
-	case "terminal":
-		output.FormatTerminal(result, os.Stdout)
+	return renderResult(c, result)
+}
+
+// renderResult writes a verification result in the requested output format, redacted with
+// --redact, and handles --copy. It then fails the command when a warning reaches --fail-on.
+func renderResult(c *cli.Context, result *core.VerificationResult) error {
+	failOn, err := core.ParseFailOn(c.String("fail-on"))
+	if err != nil {
+		return err
+	}
+	if err := reviewSession(c, result); err != nil {
+		return err
+	}
+	if c.Bool("redact") {
+		result = core.RedactResult(result)
+	}
+	outputFormat := c.String("output")
+	options := output.TerminalOptions{
+		Verbosity: c.Count("verbose"),
+		ExpandAll: c.Bool("expand-all"),
+		Phonetic:  c.Bool("phonetic"),
+
+		AnnotateCalldata: c.Bool("annotate-calldata"),
+	}
+	show, err := output.ParseSections(c.String("show"))
+	if err != nil {
+		return err
+	}
+	options.Show = show
+	options, err = output.ApplyRole(options, c.String("role"))
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case c.String("template") != "":
+		err = writeTemplate(c, result)
+	case outputFormat == "json":
+		err = output.FormatJSON(result, os.Stdout)
+	case outputFormat == "mermaid":
+		err = output.FormatMermaid(result, os.Stdout, options.ExpandAll)
+	case outputFormat == "terminal":
+		if options.RunCode, err = output.NewRunCode(result); err != nil {
+			return err
+		}
+		err = writeTerminalOutput(c, func(w io.Writer) error {
+			return output.FormatTerminalWithOptions(result, w, options)
+		})
 	default:
 		return fmt.Errorf("unknown output format: %s", outputFormat)
 	}
+	if err != nil {
+		return err
+	}
+	if err := writeGraph(c, result); err != nil {
+		return err
+	}
 
-	return nil
+	if err := copyResult(c, result); err != nil {
+		return err
+	}
+	return core.CheckFailOn(failOn, result.Warnings)
+}
+
+// safeUILinkAction fetches and renders the item referenced by a Safe UI transaction link
+func safeUILinkAction(c *cli.Context, rawURL string) error {
+	outputFormat := c.String("output")
+
+	item, err := core.ParseQueueItem(rawURL)
+	if err != nil {
+		return err
+	}
+
+	switch item.Kind {
+	case core.QueueItemMultisig:
+		tx, err := core.FetchQueueItemTransaction(c.Context, item)
+		if err != nil {
+			return err
+		}
+		options, err := verifyOptions(c)
+		if err != nil {
+			return err
+		}
+		result, err := core.VerifyTransaction(*tx, options)
+		if err != nil {
+			return fmt.Errorf("error verifying transaction: %w", err)
+		}
+		if err := checkExecution(c, result); err != nil {
+			return err
+		}
+		if err := checkOwnerChanges(c, result); err != nil {
+			return err
+		}
+		if err := checkETHRecipients(c, result); err != nil {
+			return err
+		}
+		if err := checkSchedule(c, result); err != nil {
+			return err
+		}
+		return renderResult(c, result)
+	case core.QueueItemTransfer:
+		result, err := core.FetchTransfer(c.Context, item)
+		if err != nil {
+			return err
+		}
+		switch outputFormat {
+		case "json":
+			return output.FormatJSON(result, os.Stdout)
+		case "terminal":
+			return writeTerminalOutput(c, func(w io.Writer) error {
+				return output.FormatTransferTerminal(result, w)
+			})
+		}
+	case core.QueueItemModule:
+		options, err := verifyOptions(c)
+		if err != nil {
+			return err
+		}
+		result, err := core.FetchQueueItemModuleTransaction(c.Context, item, options)
+		if err != nil {
+			return err
+		}
+		switch outputFormat {
+		case "json":
+			return output.FormatJSON(result, os.Stdout)
+		case "terminal":
+			return writeTerminalOutput(c, func(w io.Writer) error {
+				return output.FormatModuleTransactionsTerminal([]core.ModuleTransactionResult{*result}, w)
+			})
+		}
+	default:
+		return fmt.Errorf("%s items are not supported: this is %s", item.Kind, item.Kind.Explain())
+	}
+
+	return fmt.Errorf("unknown output format: %s", outputFormat)
 }
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"github.com/ethereum-optimism/op-txverify/core"
+	"github.com/ethereum-optimism/op-txverify/qr"
+	cli "github.com/urfave/cli/v2"
+)
+
+// noExecFlag returns the global flag that forbids starting other programs
+func noExecFlag() cli.Flag {
+	return &cli.BoolFlag{
+		Name:  "no-exec",
+		Usage: "Never start other programs: print URLs to open by hand instead of opening the browser, and never page or copy to the clipboard (also \"noExec\" in the configuration file)",
+	}
+}
+
+// noExecKey is the App.Metadata key of whether starting other programs is forbidden
+const noExecKey = "noExec"
+
+// applyNoExec records whether starting other programs is forbidden, by --no-exec or the noExec
+// of the default configuration file
+func applyNoExec(c *cli.Context) error {
+	forbid := c.Bool("no-exec")
+	if !forbid {
+		if path, err := core.DefaultConfigPath(); err == nil {
+			config, err := core.LoadConfigFile(path, true)
+			if err != nil {
+				return err
+			}
+			forbid = config.NoExec
+		}
+	}
+	if c.App.Metadata == nil {
+		c.App.Metadata = map[string]interface{}{}
+	}
+	c.App.Metadata[noExecKey] = forbid
+	return nil
+}
+
+// noExec reports whether applyNoExec forbade starting other programs
+func noExec(c *cli.Context) bool {
+	forbid, _ := c.App.Metadata[noExecKey].(bool)
+	return forbid
+}
+
+// qrOptions returns the options of the QR pages for a command
+func qrOptions(c *cli.Context) qr.Options {
+	return qr.Options{NoExec: noExec(c)}
+}
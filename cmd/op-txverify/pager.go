@@ -0,0 +1,98 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+
+	cli "github.com/urfave/cli/v2"
+)
+
+// Pager modes accepted by --pager
+const (
+	pagerAuto   = "auto"
+	pagerAlways = "always"
+	pagerNever  = "never"
+)
+
+// defaultPager is used when $PAGER is not set
+const defaultPager = "less -R"
+
+// pagerFlag returns the --pager flag used by every command that renders terminal output
+func pagerFlag() cli.Flag {
+	return &cli.StringFlag{
+		Name:  "pager",
+		Usage: "Page terminal output: auto (when it does not fit the terminal), always, never",
+		Value: pagerAuto,
+	}
+}
+
+// writeTerminalOutput renders output into memory and writes it to stdout, piping it through
+// $PAGER when requested or when it is taller than the terminal. Paging keeps the start of the
+// output (e.g. the TRANSACTION SUMMARY) reachable instead of lost off the top of the scrollback.
+func writeTerminalOutput(c *cli.Context, render func(w io.Writer) error) error {
+	mode := c.String("pager")
+	if mode == "" {
+		mode = pagerAuto
+	}
+
+	var buf bytes.Buffer
+	if err := render(console(&buf)); err != nil {
+		return err
+	}
+
+	switch mode {
+	case pagerNever:
+		_, err := os.Stdout.Write(buf.Bytes())
+		return err
+	case pagerAlways:
+		return runPager(buf.Bytes(), noExec(c))
+	case pagerAuto:
+		height, ok := terminalHeight(os.Stdout)
+		if !ok || bytes.Count(buf.Bytes(), []byte("\n")) < height {
+			_, err := os.Stdout.Write(buf.Bytes())
+			return err
+		}
+		return runPager(buf.Bytes(), noExec(c))
+	default:
+		return fmt.Errorf("invalid pager mode: %s (must be auto, always, or never)", mode)
+	}
+}
+
+// runPager pipes output through $PAGER (or less -R). If the pager cannot be started the output
+// is written directly so that nothing is ever silently dropped. Under --no-exec it is always
+// written directly, since the pager is another program.
+func runPager(output []byte, noExec bool) error {
+	if noExec {
+		_, err := os.Stdout.Write(output)
+		return err
+	}
+	pager := strings.TrimSpace(os.Getenv("PAGER"))
+	if pager == "" {
+		pager = defaultPager
+	}
+	args := strings.Fields(pager)
+
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdin = bytes.NewReader(output)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	// Keep colors, and leave the output on screen after the pager exits (same defaults as git)
+	if _, set := os.LookupEnv("LESS"); !set {
+		cmd.Env = append(os.Environ(), "LESS=FRX")
+	}
+
+	if err := cmd.Run(); err != nil {
+		if _, ok := err.(*exec.ExitError); ok {
+			return nil
+		}
+		fmt.Fprintf(os.Stderr, "Warning: could not start pager %q: %v\n", pager, err)
+		_, err := os.Stdout.Write(output)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,10 @@
+//go:build !unix
+
+package main
+
+import "os"
+
+// terminalHeight is not implemented on this platform, so automatic paging is disabled
+func terminalHeight(f *os.File) (int, bool) {
+	return 0, false
+}
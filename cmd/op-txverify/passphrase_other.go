@@ -0,0 +1,15 @@
+//go:build !(linux || darwin || dragonfly || freebsd || netbsd || openbsd)
+
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+// readPassphrase reads a line from f. Hiding input is not implemented on this platform, so the
+// passphrase is echoed; use --passphrase-file to avoid that.
+func readPassphrase(f *os.File) (string, error) {
+	fmt.Fprint(os.Stderr, "(input will be visible) ")
+	return readLine(f)
+}
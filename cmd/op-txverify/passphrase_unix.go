@@ -0,0 +1,30 @@
+//go:build linux || darwin || dragonfly || freebsd || netbsd || openbsd
+
+package main
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// readPassphrase reads a line from f without echoing it when f is a terminal
+func readPassphrase(f *os.File) (string, error) {
+	fd := int(f.Fd())
+	state, err := unix.IoctlGetTermios(fd, ioctlReadTermios)
+	if err != nil {
+		// Not a terminal (e.g. piped input), so there is nothing to hide
+		return readLine(f)
+	}
+
+	hidden := *state
+	hidden.Lflag &^= unix.ECHO
+	hidden.Lflag |= unix.ICANON | unix.ISIG
+	hidden.Iflag |= unix.ICRNL
+	if err := unix.IoctlSetTermios(fd, ioctlWriteTermios, &hidden); err != nil {
+		return "", err
+	}
+	defer unix.IoctlSetTermios(fd, ioctlWriteTermios, state)
+
+	return readLine(f)
+}
@@ -0,0 +1,216 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/ethereum-optimism/op-txverify/core"
+	"github.com/ethereum-optimism/op-txverify/output"
+	"github.com/ethereum-optimism/op-txverify/safes"
+	cli "github.com/urfave/cli/v2"
+)
+
+// profileFlag returns the --profile flag used by commands that take a network and Safe
+func profileFlag() cli.Flag {
+	return &cli.StringFlag{
+		Name:  "profile",
+		Usage: "Saved Safe profile to take the network, Safe, and policy from (see `safes list`)",
+	}
+}
+
+// safesCommand returns the command that manages saved Safe profiles
+func safesCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "safes",
+		Usage: "Manage saved Safe profiles",
+		Description: "A profile names a Safe so it can be used with --profile instead of --network and --safe:\n\n" +
+			"  op-txverify safes add --name foundation-upgrade --network op --safe 0x... --description \"Protocol upgrades\"\n" +
+			"  op-txverify online --profile foundation-upgrade --nonce 97",
+		Subcommands: []*cli.Command{
+			{
+				Name:  "add",
+				Usage: "Save a Safe profile",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:     "name",
+						Usage:    "Profile name, used with --profile (required)",
+						Required: true,
+					},
+					&cli.StringFlag{
+						Name:     "network",
+						Aliases:  []string{"n"},
+						Usage:    "Network name: " + core.NetworkNames + " (required)",
+						Required: true,
+					},
+					&cli.StringFlag{
+						Name:     "safe",
+						Aliases:  []string{"a"},
+						Usage:    "Safe address (required)",
+						Required: true,
+					},
+					&cli.StringFlag{
+						Name:  "description",
+						Usage: "What the Safe is for, shown whenever the profile is used",
+					},
+					&cli.StringFlag{
+						Name:  "policy",
+						Usage: "Denylist file of addresses this Safe must never interact with, loaded whenever the profile is used",
+					},
+				},
+				Action: safesAddAction,
+			},
+			{
+				Name:  "list",
+				Usage: "List saved Safe profiles",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:    "output",
+						Aliases: []string{"o"},
+						Usage:   "Output format: terminal, json",
+						Value:   "terminal",
+					},
+				},
+				Action: safesListAction,
+			},
+			{
+				Name:      "remove",
+				Usage:     "Remove a saved Safe profile",
+				ArgsUsage: "<name>",
+				Action:    safesRemoveAction,
+			},
+		},
+	}
+}
+
+// loadProfiles loads the saved profiles and returns them with the file they are saved in
+func loadProfiles() (*safes.Profiles, string, error) {
+	path, err := safes.DefaultProfilesPath()
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to locate profiles: %w", err)
+	}
+	profiles, err := safes.LoadProfilesFile(path)
+	if err != nil {
+		return nil, "", err
+	}
+	return profiles, path, nil
+}
+
+// selectedProfile returns the profile chosen with --profile, or nil when none is
+func selectedProfile(c *cli.Context) (*safes.Profile, error) {
+	name := c.String("profile")
+	if name == "" {
+		return nil, nil
+	}
+	if c.IsSet("network") || c.IsSet("safe") {
+		return nil, fmt.Errorf("--profile cannot be combined with --network or --safe")
+	}
+	profiles, _, err := loadProfiles()
+	if err != nil {
+		return nil, err
+	}
+	profile, err := profiles.Lookup(name)
+	if err != nil {
+		return nil, err
+	}
+	return &profile, nil
+}
+
+// profileTarget returns the network and Safe of the --profile flag, or else the --network and
+// --safe flags, which are empty when omitted
+func profileTarget(c *cli.Context) (string, string, *safes.Profile, error) {
+	profile, err := selectedProfile(c)
+	if err != nil {
+		return "", "", nil, err
+	}
+	if profile == nil {
+		return c.String("network"), c.String("safe"), nil, nil
+	}
+
+	fmt.Fprintf(os.Stderr, "Using profile %s: %s on %s", profile.Name, profile.Safe, profile.Network)
+	if profile.Description != "" {
+		fmt.Fprintf(os.Stderr, " (%s)", profile.Description)
+	}
+	fmt.Fprintln(os.Stderr)
+	return profile.Network, profile.Safe, profile, nil
+}
+
+// safeTarget returns the network and Safe for commands that do not prompt for them
+func safeTarget(c *cli.Context) (string, string, error) {
+	network, safe, _, err := profileTarget(c)
+	if err != nil {
+		return "", "", err
+	}
+	if network == "" || safe == "" {
+		return "", "", fmt.Errorf("required flags not set: --network and --safe, or --profile")
+	}
+	return network, safe, nil
+}
+
+func safesAddAction(c *cli.Context) error {
+	profile := safes.Profile{
+		Name:        c.String("name"),
+		Network:     c.String("network"),
+		Safe:        c.String("safe"),
+		Description: c.String("description"),
+	}
+	if policy := c.String("policy"); policy != "" {
+		// Profiles are used from any directory, so the policy is saved by its absolute path
+		path, err := filepath.Abs(policy)
+		if err != nil {
+			return fmt.Errorf("invalid policy path: %w", err)
+		}
+		if err := core.NewDenylist().LoadDenylistFile(path); err != nil {
+			return err
+		}
+		profile.Policy = path
+	}
+
+	profiles, path, err := loadProfiles()
+	if err != nil {
+		return err
+	}
+	if err := profiles.Add(profile); err != nil {
+		return err
+	}
+	if err := profiles.SaveProfilesFile(path); err != nil {
+		return err
+	}
+	fmt.Printf("Saved profile %s to %s\n", profile.Name, path)
+	return nil
+}
+
+func safesListAction(c *cli.Context) error {
+	profiles, _, err := loadProfiles()
+	if err != nil {
+		return err
+	}
+
+	switch outputFormat := c.String("output"); outputFormat {
+	case "json":
+		return output.FormatJSON(profiles.Sorted(), os.Stdout)
+	case "terminal":
+		return output.FormatSafeProfilesTerminal(profiles.Sorted(), console(os.Stdout))
+	default:
+		return fmt.Errorf("unknown output format: %s", outputFormat)
+	}
+}
+
+func safesRemoveAction(c *cli.Context) error {
+	if c.NArg() != 1 {
+		return fmt.Errorf("expected a profile name")
+	}
+
+	profiles, path, err := loadProfiles()
+	if err != nil {
+		return err
+	}
+	if err := profiles.Remove(c.Args().First()); err != nil {
+		return err
+	}
+	if err := profiles.SaveProfilesFile(path); err != nil {
+		return err
+	}
+	fmt.Printf("Removed profile %s\n", c.Args().First())
+	return nil
+}
@@ -0,0 +1,337 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum-optimism/op-txverify/core"
+	"github.com/ethereum-optimism/op-txverify/safes"
+	"github.com/urfave/cli/v2"
+)
+
+// errPromptCancelled is returned when a choice is abandoned with Ctrl-C, Esc, or q
+var errPromptCancelled = errors.New("cancelled")
+
+// transactionTarget returns the network, Safe, and nonce of a command, from --profile or the
+// --network and --safe flags, and --nonce. When any of them is omitted and stdin is a terminal,
+// it is asked for instead: recently verified Safes are offered first, and the nonce is picked
+// from the transactions queued on the Safe service.
+func transactionTarget(c *cli.Context) (string, string, uint64, error) {
+	network, safe, profile, err := profileTarget(c)
+	if err != nil {
+		return "", "", 0, err
+	}
+	nonce := c.Uint64("nonce")
+
+	var missing []string
+	if network == "" {
+		missing = append(missing, "network")
+	}
+	if safe == "" {
+		missing = append(missing, "safe")
+	}
+	if !c.IsSet("nonce") {
+		missing = append(missing, "nonce")
+	}
+	if len(missing) == 0 {
+		return network, safe, nonce, nil
+	}
+	if !isTerminal(os.Stdin) {
+		return "", "", 0, fmt.Errorf("required flags not set: --%s (run in a terminal to be prompted for them)", strings.Join(missing, ", --"))
+	}
+
+	if safe == "" {
+		if network, safe, err = promptRecentSafe(network); err != nil {
+			return "", "", 0, err
+		}
+	}
+	if network == "" {
+		if network, err = promptNetwork(); err != nil {
+			return "", "", 0, err
+		}
+	} else if err := core.ValidateNetwork(network); err != nil {
+		return "", "", 0, err
+	}
+	if safe == "" {
+		if safe, err = promptSafe(); err != nil {
+			return "", "", 0, err
+		}
+	}
+	if !c.IsSet("nonce") {
+		if nonce, err = promptNonce(c.Context, network, safe); err != nil {
+			return "", "", 0, err
+		}
+	}
+
+	target := fmt.Sprintf("--network %s --safe %s", network, safe)
+	if profile != nil {
+		target = "--profile " + profile.Name
+	}
+	fmt.Fprintf(os.Stderr, "\nTo skip these questions next time, run:\n  %s %s %s --nonce %d\n\n", c.App.Name, c.Command.Name, target, nonce)
+	return network, safe, nonce, nil
+}
+
+// promptRecentSafe offers the Safes in the history, limited to network when it is already known.
+// It returns an empty Safe when there is no history or another Safe is chosen.
+func promptRecentSafe(network string) (string, string, error) {
+	history := loadHistory()
+	var recent []safes.HistoryEntry
+	var options []string
+	for _, entry := range history.Safes {
+		if network != "" && entry.Network != network {
+			continue
+		}
+		recent = append(recent, entry)
+		options = append(options, fmt.Sprintf("%-12s %s  (last verified %s)", entry.Network, entry.Safe, entry.LastUsed.Local().Format("2006-01-02")))
+	}
+	if len(recent) == 0 {
+		return network, "", nil
+	}
+
+	choice, err := selectOption("Which Safe?", append(options, "Another Safe"))
+	if err != nil {
+		return "", "", err
+	}
+	if choice == len(recent) {
+		return network, "", nil
+	}
+	return recent[choice].Network, recent[choice].Safe, nil
+}
+
+// promptNetwork asks which network the Safe is on
+func promptNetwork() (string, error) {
+	networks := strings.Split(core.NetworkNames, ", ")
+	choice, err := selectOption("Which network is the Safe on?", networks)
+	if err != nil {
+		return "", err
+	}
+	return networks[choice], nil
+}
+
+// promptSafe asks for a Safe address until a complete one is given
+func promptSafe() (string, error) {
+	for {
+		safe, err := promptLine("Safe address")
+		if err != nil {
+			return "", err
+		}
+		safe = strings.TrimSpace(safe)
+		if err := core.ValidateFullAddress("safe", core.StripChainPrefix(safe)); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			continue
+		}
+		return safe, nil
+	}
+}
+
+// promptNonce offers the transactions queued for a Safe, falling back to asking for a nonce when
+// there are none or the service cannot be reached
+func promptNonce(ctx context.Context, network, safe string) (uint64, error) {
+	fmt.Fprintln(os.Stderr, "Fetching pending transactions...")
+	pending, err := core.FetchPendingTransactions(ctx, network, safe)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Could not fetch pending transactions: %v\n", err)
+		return promptNumber("Nonce")
+	}
+	if len(pending) == 0 {
+		fmt.Fprintln(os.Stderr, "No pending transactions found for this Safe.")
+		return promptNumber("Nonce")
+	}
+
+	options := make([]string, 0, len(pending)+1)
+	for _, tx := range pending {
+		options = append(options, describePending(tx, safe))
+	}
+	choice, err := selectOption("Which transaction?", append(options, "Another nonce"))
+	if err != nil {
+		return 0, err
+	}
+	if choice == len(pending) {
+		return promptNumber("Nonce")
+	}
+	return pending[choice].Nonce, nil
+}
+
+// describePending is how a queued transaction is offered for selection
+func describePending(tx core.PendingTransaction, safe string) string {
+	action := tx.Method
+	switch {
+	case action == "" && strings.EqualFold(tx.To, core.StripChainPrefix(safe)):
+		action = "rejection (empty transaction to the Safe)"
+	case action == "":
+		action = "call to " + tx.To
+	default:
+		action += " on " + tx.To
+	}
+	return fmt.Sprintf("nonce %d  %s  %d/%d confirmations  %s",
+		tx.Nonce, action, tx.Confirmations, tx.Required, tx.SafeTxHash)
+}
+
+// safeTxHashFlag returns the --safe-tx-hash flag of the commands that fetch a transaction by nonce
+func safeTxHashFlag() cli.Flag {
+	return &cli.StringFlag{
+		Name:  "safe-tx-hash",
+		Usage: "safeTxHash of the transaction to verify when several are queued for the nonce, such as a replacement and the transaction it replaces",
+	}
+}
+
+// generateTransaction fetches the transaction at a nonce. When several are queued for it, as
+// after a replacement or cancellation was proposed, and safeTxHash does not pick one, they are
+// offered for selection on a terminal; otherwise the conflict is returned as an error.
+func generateTransaction(ctx context.Context, network, safe string, nonce uint64, safeTxHash string) (*core.SafeTransaction, error) {
+	tx, err := core.GenerateTransaction(ctx, network, safe, nonce, safeTxHash)
+	var conflict *core.NonceConflictError
+	if !errors.As(err, &conflict) {
+		return tx, err
+	}
+	if !isTerminal(os.Stdin) {
+		return nil, fmt.Errorf("%w (choose with --safe-tx-hash)", err)
+	}
+
+	fmt.Fprintf(os.Stderr, "%d transactions are queued for nonce %d. Only one of them can execute, so verify the one you are asked to sign.\n",
+		len(conflict.Candidates), nonce)
+	options := make([]string, len(conflict.Candidates))
+	for i, candidate := range conflict.Candidates {
+		options[i] = describePending(candidate, safe)
+		if candidate.Proposer != "" {
+			options[i] += "  proposed by " + core.ChecksumAddress(candidate.Proposer)
+		}
+		if candidate.Submitted != "" {
+			options[i] += " at " + candidate.Submitted
+		}
+	}
+	choice, err := selectOption("Which transaction?", options)
+	if err != nil {
+		return nil, err
+	}
+	return core.GenerateTransaction(ctx, network, safe, nonce, conflict.Candidates[choice].SafeTxHash)
+}
+
+// selectOption asks for one of options and returns its index. On a terminal that supports it the
+// choice is made with the arrow keys; otherwise the options are numbered and a number is typed.
+func selectOption(title string, options []string) (int, error) {
+	restore, err := rawMode(os.Stdin)
+	if err != nil {
+		return selectNumbered(title, options)
+	}
+	defer restore()
+
+	fmt.Fprintf(os.Stderr, "%s (↑/↓ to move, enter to select)\n", title)
+	selected := 0
+	draw := func() {
+		for i, option := range options {
+			marker := "  "
+			if i == selected {
+				marker = "> "
+			}
+			fmt.Fprintf(os.Stderr, "\r\x1b[2K%s%s\n", marker, option)
+		}
+	}
+	draw()
+
+	buf := make([]byte, 64)
+	for {
+		n, err := os.Stdin.Read(buf)
+		if err != nil {
+			return 0, err
+		}
+		for _, key := range splitKeys(buf[:n]) {
+			switch {
+			case key == "\x1b[A" || key == "\x1bOA" || key == "k":
+				selected = (selected + len(options) - 1) % len(options)
+			case key == "\x1b[B" || key == "\x1bOB" || key == "j":
+				selected = (selected + 1) % len(options)
+			case key == "\r" || key == "\n":
+				return selected, nil
+			case key == "\x03" || key == "\x1b" || key == "q":
+				return 0, errPromptCancelled
+			case len(key) == 1 && key[0] >= '1' && key[0] <= '9' && int(key[0]-'1') < len(options):
+				selected = int(key[0] - '1')
+			}
+		}
+		fmt.Fprintf(os.Stderr, "\x1b[%dA", len(options))
+		draw()
+	}
+}
+
+// splitKeys splits terminal input into keypresses, keeping arrow key escape sequences whole
+func splitKeys(input []byte) []string {
+	var keys []string
+	for len(input) > 0 {
+		size := 1
+		if input[0] == 0x1b && len(input) >= 3 && (input[1] == '[' || input[1] == 'O') {
+			size = 3
+		}
+		keys = append(keys, string(input[:size]))
+		input = input[size:]
+	}
+	return keys
+}
+
+// selectNumbered lists options by number and asks for one until a valid number is given
+func selectNumbered(title string, options []string) (int, error) {
+	fmt.Fprintln(os.Stderr, title)
+	for i, option := range options {
+		fmt.Fprintf(os.Stderr, "  %d) %s\n", i+1, option)
+	}
+	for {
+		answer, err := promptLine(fmt.Sprintf("Choose 1-%d", len(options)))
+		if err != nil {
+			return 0, err
+		}
+		if n, err := strconv.Atoi(strings.TrimSpace(answer)); err == nil && n >= 1 && n <= len(options) {
+			return n - 1, nil
+		}
+		fmt.Fprintf(os.Stderr, "Please enter a number between 1 and %d\n", len(options))
+	}
+}
+
+// promptNumber asks for a non-negative integer until one is given
+func promptNumber(label string) (uint64, error) {
+	for {
+		answer, err := promptLine(label)
+		if err != nil {
+			return 0, err
+		}
+		if n, err := strconv.ParseUint(strings.TrimSpace(answer), 10, 64); err == nil {
+			return n, nil
+		}
+		fmt.Fprintln(os.Stderr, "Please enter a whole number")
+	}
+}
+
+// promptLine writes a prompt to stderr, so it never mixes with command output, and reads a line
+func promptLine(label string) (string, error) {
+	fmt.Fprintf(os.Stderr, "%s: ", label)
+	return readLine(os.Stdin)
+}
+
+// loadHistory returns the recently verified Safes, or an empty history if they cannot be read
+func loadHistory() *safes.History {
+	path, err := safes.DefaultHistoryPath()
+	if err != nil {
+		return &safes.History{}
+	}
+	history, err := safes.LoadHistoryFile(path)
+	if err != nil {
+		return &safes.History{}
+	}
+	return history
+}
+
+// rememberSafe records a Safe in the history offered by the prompts. The history is only a
+// convenience, so failing to update it does not fail the command.
+func rememberSafe(network, safe string) {
+	path, err := safes.DefaultHistoryPath()
+	if err != nil {
+		return
+	}
+	history := loadHistory()
+	history.Record(network, safe, time.Now())
+	history.SaveHistoryFile(path)
+}
@@ -0,0 +1,19 @@
+//go:build !(linux || darwin || dragonfly || freebsd || netbsd || openbsd)
+
+package main
+
+import (
+	"errors"
+	"os"
+)
+
+// isTerminal reports whether f is a character device, which is as close as this platform gets
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// rawMode is not implemented on this platform, so choices are typed as numbers instead
+func rawMode(f *os.File) (func(), error) {
+	return nil, errors.New("raw terminal mode is not supported on this platform")
+}
@@ -0,0 +1,13 @@
+package main
+
+import (
+	cli "github.com/urfave/cli/v2"
+)
+
+// redactFlag returns the --redact flag that masks recipients and amounts for public sharing
+func redactFlag() cli.Flag {
+	return &cli.BoolFlag{
+		Name:  "redact",
+		Usage: "Mask recipient addresses, amounts, and calldata, keeping the hashes and call structure, so the output can be shared publicly before a confidential transaction executes",
+	}
+}
@@ -0,0 +1,223 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ethereum-optimism/op-txverify/core"
+	"github.com/ethereum-optimism/op-txverify/output"
+	"github.com/ethereum-optimism/op-txverify/registry"
+	cli "github.com/urfave/cli/v2"
+)
+
+// registryCommand returns the command that manages the local cache of labels, tokens, ABIs, and
+// hashes
+func registryCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "registry",
+		Usage: "Manage the local cache of contract labels, token lists, ABIs, and known hashes",
+		Subcommands: []*cli.Command{
+			{
+				Name:  "sync",
+				Usage: "Download the signed label, token, ABI, and hash files into the local cache",
+				Description: "Downloads each configured file and its \".sig\" signature, checks that a trusted signer\n" +
+					"signed it, and shows what changed. New or renamed labels and hashes are only saved once you accept them.\n" +
+					"Sources and signers are read from the \"registry\" section of the configuration file:\n\n" +
+					"  {\"registry\": {\"labels\": \"https://.../labels.json\", \"tokens\": \"https://.../tokens.json\",\n" +
+					"    \"abis\": \"https://.../abis.json\", \"hashes\": \"https://.../hashes.json\", \"signers\": [\"0x...\"]}}",
+				Flags: []cli.Flag{
+					configFlag(),
+					&cli.StringFlag{
+						Name:  "labels",
+						Usage: "HTTPS URL of the label file (overrides the configuration)",
+					},
+					&cli.StringFlag{
+						Name:  "tokens",
+						Usage: "HTTPS URL of the token list (overrides the configuration)",
+					},
+					&cli.StringFlag{
+						Name:  "abis",
+						Usage: "HTTPS URL of the ABI manifest (overrides the configuration)",
+					},
+					&cli.StringFlag{
+						Name:  "hashes",
+						Usage: "HTTPS URL of the hash file, such as prestate hashes (overrides the configuration)",
+					},
+					&cli.StringSliceFlag{
+						Name:  "signer",
+						Usage: "Address trusted to sign the files (repeatable; overrides the configuration)",
+					},
+				},
+				Action: registrySyncAction,
+			},
+		},
+	}
+}
+
+// applyRegistryCache adds the synced labels, tokens, ABIs, and hashes to the known contracts,
+// functions, and hashes
+func applyRegistryCache(c *cli.Context) error {
+	path, err := registry.DefaultCachePath()
+	if err != nil {
+		return nil
+	}
+	cache, err := core.LoadRegistryCache(path)
+	if err != nil {
+		return fmt.Errorf("%w (run `op-txverify registry sync` again, or delete the cache)", err)
+	}
+	for _, conflict := range cache.Apply() {
+		fmt.Fprintf(os.Stderr, "Ignoring a registry label: %v\n", &conflict)
+	}
+	return nil
+}
+
+func registrySyncAction(c *cli.Context) error {
+	config, err := loadConfig(c)
+	if err != nil {
+		return err
+	}
+	var sources core.RegistrySources
+	if config.Registry != nil {
+		sources = *config.Registry
+	}
+	if url := c.String("labels"); url != "" {
+		sources.Labels = url
+	}
+	if url := c.String("tokens"); url != "" {
+		sources.Tokens = url
+	}
+	if url := c.String("abis"); url != "" {
+		sources.ABIs = url
+	}
+	if url := c.String("hashes"); url != "" {
+		sources.Hashes = url
+	}
+	if signers := c.StringSlice("signer"); len(signers) > 0 {
+		sources.Signers = signers
+	}
+	if sources.Labels == "" && sources.Tokens == "" && sources.ABIs == "" && sources.Hashes == "" {
+		return fmt.Errorf("no registry sources configured; add a \"registry\" section to the configuration file or use --labels, --tokens, --abis, or --hashes")
+	}
+
+	path, err := registry.DefaultCachePath()
+	if err != nil {
+		return fmt.Errorf("failed to locate registry cache: %w", err)
+	}
+	cached, err := core.LoadRegistryCache(path)
+	if err != nil {
+		return err
+	}
+	updated, signedBy, err := registry.Sync(c.Context, cached, sources)
+	if err != nil {
+		return err
+	}
+
+	diff := registry.Compare(cached, updated)
+	if err := output.FormatRegistryDiffTerminal(diff, signedBy, console(os.Stdout)); err != nil {
+		return err
+	}
+	if diff.Empty() {
+		return nil
+	}
+
+	if n := diff.NewLabels(); n > 0 {
+		answer, err := promptLine(fmt.Sprintf("Accept %d new or renamed labels and hashes? They will name addresses and values in every verification [y/N]", n))
+		if err != nil {
+			return err
+		}
+		if a := strings.ToLower(strings.TrimSpace(answer)); a != "y" && a != "yes" {
+			return fmt.Errorf("labels not accepted; the registry cache was not updated")
+		}
+	}
+
+	if err := updated.Save(path); err != nil {
+		return err
+	}
+	fmt.Printf("Saved %d labels, %d tokens, %d ABIs, and %d hashes to %s\n", len(updated.Labels), len(updated.Tokens), len(updated.ABIs), len(updated.Hashes), path)
+	return nil
+}
+
+// lintRegistryCommand returns the command that checks registry files before they are distributed
+func lintRegistryCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "lint-registry",
+		Usage: "Check label, token, ABI, and hash files before they are signed and distributed",
+		Description: "Reports every invalid or badly checksummed address, address listed twice or named differently\n" +
+			"from a built-in contract, entry on an unsupported chain, selector collision, and hash named twice,\n" +
+			"and counts what the files add on each chain. Fails when any error is found, or any warning with --strict.",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "labels",
+				Usage: "Path to the label file",
+			},
+			&cli.StringFlag{
+				Name:  "tokens",
+				Usage: "Path to the token list",
+			},
+			&cli.StringFlag{
+				Name:  "abis",
+				Usage: "Path to the ABI manifest",
+			},
+			&cli.StringFlag{
+				Name:  "hashes",
+				Usage: "Path to the hash file",
+			},
+			&cli.BoolFlag{
+				Name:  "strict",
+				Usage: "Fail on warnings as well as errors",
+			},
+			&cli.StringFlag{
+				Name:    "output",
+				Aliases: []string{"o"},
+				Usage:   "Output format: terminal, json",
+				Value:   "terminal",
+			},
+		},
+		Action: lintRegistryAction,
+	}
+}
+
+func lintRegistryAction(c *cli.Context) error {
+	outputFormat := c.String("output")
+	if outputFormat != "terminal" && outputFormat != "json" {
+		return fmt.Errorf("unknown output format: %s", outputFormat)
+	}
+
+	files := core.RegistryLintFiles{Names: map[string]string{}}
+	for _, file := range []struct {
+		kind string
+		data *[]byte
+	}{
+		{"labels", &files.Labels}, {"tokens", &files.Tokens}, {"abis", &files.ABIs}, {"hashes", &files.Hashes},
+	} {
+		path := c.String(file.kind)
+		if path == "" {
+			continue
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		*file.data = data
+		files.Names[file.kind] = path
+	}
+	if len(files.Names) == 0 {
+		return fmt.Errorf("no registry files given; use --labels, --tokens, --abis, or --hashes")
+	}
+
+	report := core.LintRegistry(files)
+	if outputFormat == "json" {
+		if err := output.FormatJSON(report, os.Stdout); err != nil {
+			return err
+		}
+	} else if err := output.FormatRegistryLintTerminal(report, console(os.Stdout)); err != nil {
+		return err
+	}
+
+	errors, warnings := report.Count(core.SeverityCritical), report.Count(core.SeverityWarning)
+	if errors > 0 || (c.Bool("strict") && warnings > 0) {
+		return fmt.Errorf("registry lint found %d errors and %d warnings", errors, warnings)
+	}
+	return nil
+}
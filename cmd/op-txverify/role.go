@@ -0,0 +1,14 @@
+package main
+
+import (
+	"github.com/ethereum-optimism/op-txverify/output"
+	cli "github.com/urfave/cli/v2"
+)
+
+// roleFlag returns the --role flag that tailors verification output to a ceremony role
+func roleFlag() cli.Flag {
+	return &cli.StringFlag{
+		Name:  "role",
+		Usage: "Ceremony role preset: " + output.RoleSigner + " (review and compare hashes) or " + output.RoleFacilitator + " (verbose, with signer progress, service cross-checks, and links)",
+	}
+}
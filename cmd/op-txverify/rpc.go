@@ -0,0 +1,227 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/ethereum-optimism/op-txverify/core"
+	"github.com/ethereum-optimism/op-txverify/output"
+	"github.com/urfave/cli/v2"
+)
+
+func rpcURLFlag() cli.Flag {
+	return &cli.StringFlag{
+		Name:  "rpc-url",
+		Usage: "JSON-RPC endpoint of the transaction's chain, used to check that an already executed transaction is finalized and did what its calldata says, to read the current owners of a Safe whose owners the transaction changes, and to check that contracts sent ETH accept it (defaults to the healthy endpoint configured for the chain)",
+	}
+}
+
+// configFlag returns the --config flag used by commands that read the configuration file
+func configFlag() cli.Flag {
+	return &cli.StringFlag{
+		Name:  "config",
+		Usage: "Configuration file (defaults to the config.json loaded automatically when present)",
+	}
+}
+
+// loadConfig loads the --config file, or the default configuration file when present
+func loadConfig(c *cli.Context) (*core.Config, error) {
+	if path := c.String("config"); path != "" {
+		config, err := core.LoadConfigFile(path, false)
+		if err != nil {
+			return nil, err
+		}
+		// The default configuration's theme was applied before the command ran
+		if c.String("theme") == "" && config.Theme != "" {
+			if err := output.SetTheme(config.Theme); err != nil {
+				return nil, fmt.Errorf("%s: %w", path, err)
+			}
+		}
+		return config, nil
+	}
+	path, err := core.DefaultConfigPath()
+	if err != nil {
+		return &core.Config{}, nil
+	}
+	return core.LoadConfigFile(path, true)
+}
+
+// rpcClient returns --rpc-url, or else the first configured endpoint for the chain that passes
+// its health check, or nil when there is neither
+func rpcClient(c *cli.Context, chainID uint64) (*core.RPCClient, error) {
+	if url := c.String("rpc-url"); url != "" {
+		return core.NewRPCClient(url), nil
+	}
+	config, err := loadConfig(c)
+	if err != nil {
+		return nil, err
+	}
+	urls := config.RPCURLs(chainID)
+	if len(urls) == 0 {
+		return nil, nil
+	}
+	client, _, err := core.HealthyRPCClient(c.Context, urls, chainID, core.DefaultMaxBlockAge)
+	return client, err
+}
+
+// checkExecution checks an already executed transaction against a node: that it is finalized,
+// and that its events match the calldata. Without a node it only notes that the execution was
+// not checked.
+func checkExecution(c *cli.Context, result *core.VerificationResult) error {
+	if !core.Executed(result) {
+		return nil
+	}
+
+	client, err := rpcClient(c, uint64(result.Transaction.Chain))
+	if err != nil {
+		return err
+	}
+	if client == nil {
+		return core.CheckFinality(c.Context, nil, result)
+	}
+	if err := core.CheckFinality(c.Context, client, result); err != nil {
+		return err
+	}
+	return core.CheckExecutionEffects(c.Context, client, result)
+}
+
+// checkOwnerChanges reads the current owners of a Safe whose owners or threshold the transaction
+// changes, so the owners afterwards can be shown. Without a node only the changes are shown.
+func checkOwnerChanges(c *cli.Context, result *core.VerificationResult) error {
+	if !core.ChangesOwners(result) {
+		return nil
+	}
+
+	client, err := rpcClient(c, uint64(result.Transaction.Chain))
+	if err != nil {
+		return err
+	}
+	return core.CheckOwnerChanges(c.Context, client, result)
+}
+
+// checkETHRecipients checks that the contracts a transaction sends plain ETH to accept it.
+// Without a node the recipients are only noted as not checked.
+func checkETHRecipients(c *cli.Context, result *core.VerificationResult) error {
+	if !core.SendsETH(result) {
+		return nil
+	}
+
+	client, err := rpcClient(c, uint64(result.Transaction.Chain))
+	if err != nil {
+		return err
+	}
+	return core.CheckETHRecipients(c.Context, client, result)
+}
+
+func l2RPCURLFlag() cli.Flag {
+	return &cli.StringFlag{
+		Name:  "l2-rpc-url",
+		Usage: "JSON-RPC endpoint of the L2 chain the withdrawal was initiated on, used to check that L2ToL1MessagePasser committed to it (defaults to the healthy endpoint configured for the chain of a known portal)",
+	}
+}
+
+// l2RPCClient returns --l2-rpc-url, or else a healthy configured endpoint of the L2 chain of
+// the portal every withdrawal goes through, or nil when there is neither
+func l2RPCClient(c *cli.Context, check *core.WithdrawalCheck) (*core.RPCClient, error) {
+	if url := c.String("l2-rpc-url"); url != "" {
+		return core.NewRPCClient(url), nil
+	}
+	var chainID uint64
+	for _, withdrawal := range check.Withdrawals {
+		portalChainID, ok := core.PortalL2Chains[strings.ToLower(withdrawal.Portal)]
+		if !ok || (chainID != 0 && portalChainID != chainID) {
+			return nil, nil
+		}
+		chainID = portalChainID
+	}
+	config, err := loadConfig(c)
+	if err != nil {
+		return nil, err
+	}
+	urls := config.RPCURLs(chainID)
+	if len(urls) == 0 {
+		return nil, nil
+	}
+	client, _, err := core.HealthyRPCClient(c.Context, urls, chainID, core.DefaultMaxBlockAge)
+	return client, err
+}
+
+// rpcCheckCommand returns the command that checks the configured RPC endpoints
+func rpcCheckCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "rpc-check",
+		Usage: "Check the configured RPC endpoints before a signing ceremony",
+		Description: "Checks that every endpoint in the rpc section of the configuration file serves the chain it\n" +
+			"is configured for, is synced, has a recent latest block, and how fast it answers. Fails if\n" +
+			"any endpoint is unhealthy. The configuration maps chain IDs to endpoints in order of preference:\n\n" +
+			"  {\"rpc\": {\"1\": [\"https://eth.example\"], \"10\": [\"https://op.example\", \"https://op-backup.example\"]}}",
+		Flags: []cli.Flag{
+			configFlag(),
+			&cli.Uint64Flag{
+				Name:  "chain",
+				Usage: "Only check the endpoints of this chain ID",
+			},
+			&cli.DurationFlag{
+				Name:  "max-block-age",
+				Usage: "Flag endpoints whose latest block is older than this",
+				Value: core.DefaultMaxBlockAge,
+			},
+			&cli.StringFlag{
+				Name:    "output",
+				Aliases: []string{"o"},
+				Usage:   "Output format: terminal, json",
+				Value:   "terminal",
+			},
+		},
+		Action: rpcCheckAction,
+	}
+}
+
+func rpcCheckAction(c *cli.Context) error {
+	config, err := loadConfig(c)
+	if err != nil {
+		return err
+	}
+
+	var chainIDs []uint64
+	for chainID := range config.RPC {
+		if c.IsSet("chain") && chainID != c.Uint64("chain") {
+			continue
+		}
+		chainIDs = append(chainIDs, chainID)
+	}
+	if len(chainIDs) == 0 {
+		return fmt.Errorf("no RPC endpoints configured; add an rpc section to the configuration file")
+	}
+	sort.Slice(chainIDs, func(i, j int) bool { return chainIDs[i] < chainIDs[j] })
+
+	var checks []core.RPCHealth
+	unhealthy := 0
+	for _, chainID := range chainIDs {
+		for _, url := range config.RPCURLs(chainID) {
+			health := core.CheckRPCHealth(c.Context, core.NewRPCClient(url), chainID, c.Duration("max-block-age"))
+			if !health.Healthy() {
+				unhealthy++
+			}
+			checks = append(checks, health)
+		}
+	}
+
+	switch outputFormat := c.String("output"); outputFormat {
+	case "json":
+		err = output.FormatJSON(checks, os.Stdout)
+	case "terminal":
+		err = output.FormatRPCHealthTerminal(checks, console(os.Stdout))
+	default:
+		return fmt.Errorf("unknown output format: %s", outputFormat)
+	}
+	if err != nil {
+		return err
+	}
+	if unhealthy > 0 {
+		return fmt.Errorf("%d of %d RPC endpoints are unhealthy", unhealthy, len(checks))
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/ethereum-optimism/op-txverify/core"
+	cli "github.com/urfave/cli/v2"
+)
+
+// scheduleFlag returns the --schedule flag shared by verifying commands
+func scheduleFlag() cli.Flag {
+	return &cli.StringSliceFlag{
+		Name:  "schedule",
+		Usage: "Schedule file of signing windows by Safe tx hash (repeatable; the default schedule file is always loaded when present)",
+	}
+}
+
+// deadlineFlag returns the --deadline flag shared by verifying commands
+func deadlineFlag() cli.Flag {
+	return &cli.StringFlag{
+		Name:  "deadline",
+		Usage: "Time the transaction must be signed by, such as a veto deadline (RFC 3339, e.g. 2025-06-01T12:00:00Z; overrides the schedule)",
+	}
+}
+
+// notBeforeFlag returns the --not-before flag shared by verifying commands
+func notBeforeFlag() cli.Flag {
+	return &cli.StringFlag{
+		Name:  "not-before",
+		Usage: "Time the transaction may be signed from (RFC 3339; overrides the schedule)",
+	}
+}
+
+// checkSchedule records the signing window of a transaction, from --not-before and --deadline
+// or else from the schedule files, and warns when signing now is outside of it
+func checkSchedule(c *cli.Context, result *core.VerificationResult) error {
+	window, ok, err := flagWindow(c)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		schedule, err := loadSchedule(c)
+		if err != nil {
+			return err
+		}
+		if window, ok = schedule.Lookup(result); !ok {
+			return nil
+		}
+	}
+	core.CheckSchedule(result, window, time.Now())
+	return nil
+}
+
+// flagWindow returns the signing window given with --not-before and --deadline, if any
+func flagWindow(c *cli.Context) (core.SigningWindow, bool, error) {
+	var window core.SigningWindow
+	for _, flag := range []struct {
+		name string
+		time **time.Time
+	}{{"not-before", &window.Opens}, {"deadline", &window.Closes}} {
+		value := c.String(flag.name)
+		if value == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return window, false, fmt.Errorf("invalid --%s %q: use RFC 3339, e.g. 2025-06-01T12:00:00Z", flag.name, value)
+		}
+		*flag.time = &t
+	}
+	if window.Opens == nil && window.Closes == nil {
+		return window, false, nil
+	}
+	if err := window.Validate(); err != nil {
+		return window, false, err
+	}
+	return window, true, nil
+}
+
+// loadSchedule loads the default schedule file (if present) and every --schedule file
+func loadSchedule(c *cli.Context) (*core.Schedule, error) {
+	schedule := core.NewSchedule()
+
+	if path, err := core.DefaultSchedulePath(); err == nil {
+		if _, err := os.Stat(path); err == nil {
+			if err := schedule.LoadScheduleFile(path); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	for _, path := range c.StringSlice("schedule") {
+		if err := schedule.LoadScheduleFile(path); err != nil {
+			return nil, err
+		}
+	}
+
+	return schedule, nil
+}
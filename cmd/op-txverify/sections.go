@@ -0,0 +1,25 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/ethereum-optimism/op-txverify/output"
+	cli "github.com/urfave/cli/v2"
+)
+
+// verboseFlag returns the -v flag, which raises the verbosity each time it is given
+func verboseFlag() cli.Flag {
+	return &cli.BoolFlag{
+		Name:    "verbose",
+		Aliases: []string{"v"},
+		Usage:   "Show more detail; repeat (-v -v) to also print the raw calldata",
+	}
+}
+
+// showFlag returns the --show flag that limits terminal output to the listed sections
+func showFlag() cli.Flag {
+	return &cli.StringFlag{
+		Name:  "show",
+		Usage: "Comma-separated sections to print, in place of the defaults: " + strings.Join(output.Sections, ", ") + " (critical warnings are always printed)",
+	}
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/ethereum-optimism/op-txverify/core"
+	"github.com/ethereum-optimism/op-txverify/output"
+	cli "github.com/urfave/cli/v2"
+)
+
+// sessionFlag returns the --session flag shared by verifying commands
+func sessionFlag() cli.Flag {
+	return &cli.StringFlag{
+		Name:  "session",
+		Usage: "Review session file: acknowledge each critical warning and review the summary and every call one at a time, with notes, saving progress after each step and resuming where an earlier run stopped (created if missing)",
+	}
+}
+
+// reviewSession applies the --session file to a result. On a terminal, what was not reviewed
+// yet is then shown one step at a time: each critical warning, to be acknowledged, the summary
+// and call flow, and each call, to be marked as reviewed. Every step takes an optional note, and
+// the session is saved after each of them. Stopping saves the progress and fails the command, so
+// the final report is only printed once everything was reviewed. Critical warnings that are not
+// acknowledged fail the command even when not on a terminal, so no hashes are printed for them.
+func reviewSession(c *cli.Context, result *core.VerificationResult) error {
+	path := c.String("session")
+	if path == "" {
+		return nil
+	}
+	session, err := core.LoadReviewSessionFile(path)
+	if err != nil {
+		return err
+	}
+	progress, warnings := core.ApplyReviewSession(result, session, sessionSource(c, result))
+	result.Warnings = append(result.Warnings, warnings...)
+
+	if isTerminal(os.Stdin) {
+		if err := reviewSteps(path, session, progress, result); err != nil {
+			return err
+		}
+	}
+	if pending := progress.Unacknowledged(result.Warnings); len(pending) > 0 {
+		return fmt.Errorf("%w: %d critical warning(s) are not acknowledged, so the hashes are not printed; run again on a terminal with --session %s to review them", core.ErrPolicyViolation, len(pending), path)
+	}
+	return nil
+}
+
+// reviewStep is one thing a reviewer is asked to look at and mark as reviewed
+type reviewStep struct {
+	title  string
+	show   func() error
+	record func(note string)
+}
+
+// reviewSteps asks the reviewer to go through every step not reviewed yet, saving the session
+// after each
+func reviewSteps(path string, session *core.ReviewSession, progress *core.SessionTransaction, result *core.VerificationResult) error {
+	var steps []reviewStep
+	for _, warning := range progress.Unacknowledged(result.Warnings) {
+		warning := warning
+		steps = append(steps, reviewStep{
+			title: "Acknowledge this critical warning",
+			show: func() error {
+				_, err := fmt.Fprintf(console(os.Stderr), "\n❌ CRITICAL [%s]: %s\n\n", warning.Code, warning.Message)
+				return err
+			},
+			record: func(note string) { progress.Acknowledge(warning, note, time.Now()) },
+		})
+	}
+	sections := []string{output.SectionSummary}
+	if len(result.Call.SubCalls) > 0 || result.NestedResult != nil {
+		sections = append(sections, output.SectionFlow)
+	}
+	for _, section := range sections {
+		if progress.SectionReviewed(section) {
+			continue
+		}
+		section := section
+		steps = append(steps, reviewStep{
+			title:  "Review the " + section,
+			show:   func() error { return output.FormatSectionTerminal(result, section, console(os.Stderr)) },
+			record: func(note string) { progress.RecordSection(section, note, time.Now()) },
+		})
+	}
+	calls := core.ReviewableCalls(result)
+	for i, call := range calls {
+		if call.Review != nil {
+			continue
+		}
+		call := call
+		steps = append(steps, reviewStep{
+			title:  fmt.Sprintf("Review call %d of %d", i+1, len(calls)),
+			show:   func() error { return output.FormatCallTerminal(*call, console(os.Stderr)) },
+			record: func(note string) { progress.Record(call, note, time.Now()) },
+		})
+	}
+	if len(steps) == 0 {
+		return nil
+	}
+
+	if len(progress.Reviews) > 0 || len(progress.Sections) > 0 || len(progress.Acknowledged) > 0 {
+		fmt.Fprintf(os.Stderr, "Resuming review from %s: %d step(s) left\n", path, len(steps))
+	}
+	for i, step := range steps {
+		if err := step.show(); err != nil {
+			return err
+		}
+		choice, err := selectOption(fmt.Sprintf("%s (step %d of %d)", step.title, i+1, len(steps)), []string{"Done", "Done, with a note", "Stop and resume later"})
+		if errors.Is(err, errPromptCancelled) || (err == nil && choice == 2) {
+			return fmt.Errorf("review stopped with %d step(s) left; run again with --session %s to resume", len(steps)-i, path)
+		}
+		if err != nil {
+			return err
+		}
+
+		note := ""
+		if choice == 1 {
+			if note, err = promptLine("Note"); err != nil {
+				return err
+			}
+		}
+		step.record(note)
+		if err := session.SaveReviewSessionFile(path); err != nil {
+			return err
+		}
+	}
+	fmt.Fprintln(os.Stderr, "Review complete.")
+	fmt.Fprintln(os.Stderr, "")
+	return nil
+}
+
+// sessionSource describes where a reviewed transaction came from, so a session file shared by
+// several transactions says which is which
+func sessionSource(c *cli.Context, result *core.VerificationResult) string {
+	if path := c.String("tx"); path != "" {
+		return path
+	}
+	tx := result.Transaction
+	return fmt.Sprintf("Safe %s nonce %d on chain %d", core.ChecksumAddress(tx.Safe), tx.Nonce, tx.Chain)
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/ethereum-optimism/op-txverify/core"
+	"github.com/ethereum-optimism/op-txverify/output"
+	cli "github.com/urfave/cli/v2"
+)
+
+// shareCommand returns the command that makes a link carrying a transaction for the url command
+// and the QR site
+func shareCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "share",
+		Usage: "Make a link that carries a transaction, with a checksum, for op-txverify url and the QR site",
+		Description: "The link carries the hashed fields of the transaction in their binary RLP encoding, compressed and\n" +
+			"in base64, in its tx parameter, and the keccak256 hash of that encoding in its sum parameter.\n" +
+			"op-txverify url and qr --url refuse a link whose transaction does not match its sum, as after it\n" +
+			"was truncated or altered in transit. The transaction is read from --tx, or else fetched from the\n" +
+			"Safe service.",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "tx",
+				Usage: "Path to a transaction file to share instead of fetching one",
+			},
+			&cli.StringFlag{
+				Name:    "network",
+				Aliases: []string{"n"},
+				Usage:   "Network name: " + core.NetworkNames + " (prompted for if omitted)",
+			},
+			&cli.StringFlag{
+				Name:    "safe",
+				Aliases: []string{"a"},
+				Usage:   "Safe address (prompted for if omitted, offering recently verified Safes)",
+			},
+			profileFlag(),
+			&cli.Uint64Flag{
+				Name:  "nonce",
+				Usage: "Transaction nonce (prompted for if omitted, offering the Safe's pending transactions)",
+			},
+			safeTxHashFlag(),
+			&cli.StringFlag{
+				Name:  "site",
+				Usage: "Page the link points at",
+				Value: output.DefaultQRSiteURL,
+			},
+			&cli.StringFlag{
+				Name:  "shortener",
+				Usage: "HTTPS endpoint of a self-hosted link shortener to also print a short link from (POST {\"url\": ...}, answering {\"url\": ...})",
+			},
+		},
+		Action: shareAction,
+	}
+}
+
+func shareAction(c *cli.Context) error {
+	var tx core.SafeTransaction
+	if path := c.String("tx"); path != "" {
+		parsed, err := parseTransactionFile(path)
+		if err != nil {
+			return err
+		}
+		tx = parsed
+	} else {
+		network, address, nonce, err := transactionTarget(c)
+		if err != nil {
+			return err
+		}
+		if err := core.ValidateNetwork(network); err != nil {
+			return err
+		}
+		generated, err := generateTransaction(c.Context, network, address, nonce, c.String("safe-tx-hash"))
+		if err != nil {
+			return fmt.Errorf("error generating transaction: %w", err)
+		}
+		rememberSafe(network, address)
+		tx = *generated
+	}
+
+	// The hash lets the recipient check they verified the transaction that was shared
+	result, err := core.VerifyTransaction(tx, core.VerifyOptions{})
+	if err != nil {
+		return fmt.Errorf("error verifying transaction: %w", err)
+	}
+
+	link, err := core.ShareLink(tx, c.String("site"))
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Safe tx hash: %s\n", result.ApproveHash)
+	fmt.Println(link)
+
+	if service := c.String("shortener"); service != "" {
+		short, err := core.ShortenLink(c.Context, service, link)
+		if err != nil {
+			return err
+		}
+		fmt.Println(short)
+	}
+	return nil
+}
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/ethereum-optimism/op-txverify/core"
+	"github.com/ethereum-optimism/op-txverify/output"
+	"github.com/ethereum-optimism/op-txverify/qr"
+	cli "github.com/urfave/cli/v2"
+)
+
+// signatureCommands returns the commands that carry owner signatures across the air gap
+func signatureCommands() []*cli.Command {
+	return []*cli.Command{
+		{
+			Name:  "export-signature",
+			Usage: "Show a signature made on this offline machine as QR codes for the online machine to scan",
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:     "tx",
+					Usage:    "Path to the transaction JSON file that was signed (required)",
+					Required: true,
+				},
+				&cli.StringFlag{
+					Name:     "signature",
+					Usage:    "65-byte owner signature of the Safe tx hash, hex encoded (required)",
+					Required: true,
+				},
+				&cli.StringFlag{
+					Name:  "hash",
+					Usage: "Safe tx hash that was signed (required for nested approvals, to choose the child or parent hash)",
+				},
+				&cli.StringFlag{
+					Name:    "output",
+					Aliases: []string{"o"},
+					Usage:   "Output format: qr, json",
+					Value:   "qr",
+				},
+			},
+			Action: exportSignatureAction,
+		},
+		{
+			Name:  "import-signature",
+			Usage: "Scan a signature exported by export-signature and check it against the transaction",
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:     "tx",
+					Usage:    "Path to the transaction JSON file (required)",
+					Required: true,
+				},
+				&cli.StringFlag{
+					Name:    "device",
+					Aliases: []string{"d"},
+					Usage:   "Camera device to use (defaults to system default)",
+				},
+				&cli.StringFlag{
+					Name:  "data",
+					Usage: "Signature payload JSON (skips the scanner)",
+				},
+				&cli.StringFlag{
+					Name:    "output",
+					Aliases: []string{"o"},
+					Usage:   "Output format: terminal, json",
+					Value:   "terminal",
+				},
+				pagerFlag(),
+			},
+			Action: importSignatureAction,
+		},
+	}
+}
+
+// parseTransactionFile reads a transaction JSON file, accepting the field spellings of other
+// tools, and reports on stderr every change made to read it
+func parseTransactionFile(path string) (core.SafeTransaction, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return core.SafeTransaction{}, fmt.Errorf("failed to read transaction file: %w", err)
+	}
+	tx, notes, err := core.ParseSafeTransaction(data)
+	if err != nil {
+		return core.SafeTransaction{}, fmt.Errorf("failed to parse transaction: %w", err)
+	}
+	for _, note := range notes {
+		fmt.Fprintf(os.Stderr, "Normalized %s: %s\n", path, note)
+	}
+	return tx, nil
+}
+
+// parseOfflineTransactionFile parses the transaction file of the offline command, which may also
+// be a forge script broadcast artifact given with the Safe nonce
+func parseOfflineTransactionFile(c *cli.Context) (core.SafeTransaction, error) {
+	path := c.String("tx")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return core.SafeTransaction{}, fmt.Errorf("failed to read transaction file: %w", err)
+	}
+	if !core.IsForgeBroadcast(data) {
+		return parseTransactionFile(path)
+	}
+	if !c.IsSet("nonce") {
+		return core.SafeTransaction{}, fmt.Errorf("%s is a forge broadcast artifact, which does not record the Safe nonce; give it with --nonce", path)
+	}
+	tx, notes, err := core.ParseForgeBroadcast(data, c.Int("nonce"))
+	if err != nil {
+		return core.SafeTransaction{}, fmt.Errorf("failed to parse transaction: %w", err)
+	}
+	for _, note := range notes {
+		fmt.Fprintf(os.Stderr, "Normalized %s: %s\n", path, note)
+	}
+	return tx, nil
+}
+
+// readTransactionFile reads and verifies a transaction JSON file
+func readTransactionFile(path string, options core.VerifyOptions) (*core.VerificationResult, error) {
+	tx, err := parseTransactionFile(path)
+	if err != nil {
+		return nil, err
+	}
+	result, err := core.VerifyTransaction(tx, options)
+	if err != nil {
+		return nil, fmt.Errorf("error verifying transaction: %w", err)
+	}
+	return result, nil
+}
+
+func exportSignatureAction(c *cli.Context) error {
+	result, err := readTransactionFile(c.String("tx"), core.VerifyOptions{})
+	if err != nil {
+		return err
+	}
+
+	export, err := core.NewSignatureExport(result, c.String("hash"), c.String("signature"))
+	if err != nil {
+		return err
+	}
+	payload, err := json.Marshal(export)
+	if err != nil {
+		return fmt.Errorf("failed to encode signature: %w", err)
+	}
+
+	switch c.String("output") {
+	case "json":
+		return output.FormatJSON(export, os.Stdout)
+	case "qr":
+		if err := output.FormatSignatureTerminal(*export, "", console(os.Stdout)); err != nil {
+			return err
+		}
+		return qr.DisplayQRCode(c.Context, payload, qrOptions(c))
+	default:
+		return fmt.Errorf("unknown output format: %s", c.String("output"))
+	}
+}
+
+func importSignatureAction(c *cli.Context) error {
+	result, err := readTransactionFile(c.String("tx"), core.VerifyOptions{})
+	if err != nil {
+		return err
+	}
+
+	data := c.String("data")
+	if data == "" {
+		data, err = qr.ScanQRCode(c.Context, c.String("device"), qrOptions(c))
+		if err != nil {
+			return fmt.Errorf("failed to scan QR code: %w", err)
+		}
+	}
+
+	check, err := core.CheckSignatureExport(data, result)
+	if err != nil {
+		return err
+	}
+
+	switch c.String("output") {
+	case "json":
+		return output.FormatJSON(check, os.Stdout)
+	case "terminal":
+		return writeTerminalOutput(c, func(w io.Writer) error {
+			return output.FormatSignatureTerminal(check.Export, check.Target, w)
+		})
+	default:
+		return fmt.Errorf("unknown output format: %s", c.String("output"))
+	}
+}
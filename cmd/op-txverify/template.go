@@ -0,0 +1,39 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/ethereum-optimism/op-txverify/core"
+	"github.com/ethereum-optimism/op-txverify/output"
+	cli "github.com/urfave/cli/v2"
+)
+
+// templateFlag returns the flag that renders the result with a report template
+func templateFlag() cli.Flag {
+	return &cli.StringFlag{
+		Name:  "template",
+		Usage: "Render the result with this Go text/template file instead of the terminal output (critical warnings are also printed to stderr)",
+	}
+}
+
+// writeTemplate renders a result with the --template file. Critical warnings are printed to
+// stderr as well, since the template may leave them out.
+func writeTemplate(c *cli.Context, result *core.VerificationResult) error {
+	if c.IsSet("output") && c.String("output") != "terminal" {
+		return fmt.Errorf("--template replaces the terminal output and cannot be used with --output %s", c.String("output"))
+	}
+	tmpl, err := output.LoadTemplate(c.String("template"))
+	if err != nil {
+		return err
+	}
+	if err := output.FormatTemplate(result, tmpl, os.Stdout); err != nil {
+		return err
+	}
+	for _, warning := range result.Warnings {
+		if warning.Severity == core.SeverityCritical {
+			fmt.Fprintf(os.Stderr, "CRITICAL [%s]: %s\n", warning.Code, warning.Message)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,9 @@
+package main
+
+import "golang.org/x/sys/unix"
+
+// Terminal attribute ioctls used to hide passphrase input
+const (
+	ioctlReadTermios  = unix.TCGETS
+	ioctlWriteTermios = unix.TCSETS
+)
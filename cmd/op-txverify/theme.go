@@ -0,0 +1,37 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ethereum-optimism/op-txverify/core"
+	"github.com/ethereum-optimism/op-txverify/output"
+	cli "github.com/urfave/cli/v2"
+)
+
+// themeFlag returns the global flag that selects the color theme of the terminal output
+func themeFlag() cli.Flag {
+	return &cli.StringFlag{
+		Name:  "theme",
+		Usage: "Color theme of the terminal output: " + strings.Join(output.ThemeNames(), ", ") + " (overrides the \"theme\" of the configuration file)",
+	}
+}
+
+// applyTheme selects the --theme, or else the theme of the default configuration file
+func applyTheme(c *cli.Context) error {
+	if theme := c.String("theme"); theme != "" {
+		return output.SetTheme(theme)
+	}
+	path, err := core.DefaultConfigPath()
+	if err != nil {
+		return nil
+	}
+	config, err := core.LoadConfigFile(path, true)
+	if err != nil {
+		return err
+	}
+	if err := output.SetTheme(config.Theme); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/ethereum-optimism/op-txverify/core"
+	cli "github.com/urfave/cli/v2"
+)
+
+// urlCommand returns the command that verifies the transaction a link refers to
+func urlCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "url",
+		Usage:     "Verify the transaction a link refers to: a Safe UI link, a share link, or its base64 payload",
+		ArgsUsage: "<link>",
+		Description: "Accepts a Safe UI transaction link (https://app.safe.global/transactions/tx?safe=...&id=...),\n" +
+			"whose transaction is fetched from the Safe service, a share link that carries the transaction\n" +
+			"as a base64 tx parameter (https://op-txverify.optimism.io/?tx=...), or the base64 payload on its own.",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    "output",
+				Aliases: []string{"o"},
+				Usage:   "Output format: terminal, json, mermaid (call graph)",
+				Value:   "terminal",
+			},
+			pagerFlag(),
+			verboseFlag(),
+			showFlag(),
+			&cli.BoolFlag{
+				Name:  "expand-all",
+				Usage: "Show every subcall in full instead of grouping identical calls",
+			},
+			copyFlag(),
+			&cli.BoolFlag{
+				Name:  "phonetic",
+				Usage: "Spell out the Safe tx hash in NATO alphabet groups for comparing by phone",
+			},
+			denylistFlag(),
+			addressBookFlag(),
+			independentDecodeFlag(),
+			&cli.BoolFlag{
+				Name:  "annotate-calldata",
+				Usage: "Print the raw calldata word by word with byte offsets, labelled with the decoded fields (terminal output)",
+			},
+			roleFlag(),
+			graphFlag(),
+			templateFlag(),
+			redactFlag(),
+			complianceFlag(),
+			annotationsFlag(),
+			grantsFlag(),
+			scheduleFlag(),
+			deadlineFlag(),
+			notBeforeFlag(),
+			sessionFlag(),
+			failOnFlag(),
+			rpcURLFlag(),
+			configFlag(),
+		},
+		Action: urlAction,
+	}
+}
+
+func urlAction(c *cli.Context) error {
+	if c.NArg() != 1 {
+		return fmt.Errorf("expected one link, got %d arguments", c.NArg())
+	}
+	link := c.Args().First()
+
+	if core.IsQueueItemLink(link) {
+		return safeUILinkAction(c, link)
+	}
+	tx, linkWarnings, err := core.DecodeTransactionLink(link)
+	if err != nil {
+		return err
+	}
+
+	options, err := verifyOptions(c)
+	if err != nil {
+		return err
+	}
+	result, err := core.VerifyTransaction(*tx, options)
+	if err != nil {
+		return fmt.Errorf("error verifying transaction: %w", err)
+	}
+	result.Warnings = append(result.Warnings, linkWarnings...)
+	if err := checkSchedule(c, result); err != nil {
+		return err
+	}
+	return renderResult(c, result)
+}
@@ -0,0 +1,172 @@
+package core
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// addressBookManifest is the JSON form of an address book
+type addressBookManifest struct {
+	Entries []AddressEntry `json:"entries"`
+}
+
+// DefaultAddressBookPath returns the address book file that is loaded automatically when present
+func DefaultAddressBookPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "op-txverify", "addressbook.txt"), nil
+}
+
+// ParseAddressBook adds the entries of an address book file to the registry. Two formats are
+// accepted: a JSON manifest ({"entries": [{"name": "...", "address": "0x..."}, {"name": "...",
+// "factory": "0x...", "salt": "0x...", "initCodeHash": "0x..."}]}, with an optional "chainId" per
+// entry) or plain text with one entry per line, either "<address> <name>" or "create2 <factory>
+// <salt> <init code hash> <name>", where "#" starts a comment. Entries apply on every chain unless
+// given a chain: a chainId in JSON, or a chain prefix such as "oeth:" on the address or the
+// factory in text. An address named differently by two entries on the same chain is an error.
+func (r *AddressRegistry) ParseAddressBook(source string, data []byte) error {
+	if trimmed := strings.TrimSpace(string(data)); strings.HasPrefix(trimmed, "{") {
+		var manifest addressBookManifest
+		if err := json.Unmarshal(data, &manifest); err != nil {
+			return fmt.Errorf("invalid address book manifest %s: %w", source, err)
+		}
+		for _, entry := range manifest.Entries {
+			entry.Source = source
+			if err := r.Register(entry); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(string(data)))
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if idx := strings.Index(text, "#"); idx != -1 {
+			text = strings.TrimSpace(text[:idx])
+		}
+		if text == "" {
+			continue
+		}
+		fields := strings.Fields(text)
+		entry := AddressEntry{Source: fmt.Sprintf("%s:%d", source, line)}
+		scoped := fields[0]
+		if strings.EqualFold(fields[0], "create2") {
+			if len(fields) < 5 {
+				return fmt.Errorf("%s: create2 entries need a factory, salt, init code hash, and name", entry.Source)
+			}
+			scoped = fields[1]
+			entry.Factory, entry.Salt, entry.InitCodeHash = StripChainPrefix(fields[1]), fields[2], fields[3]
+			entry.Name = strings.Join(fields[4:], " ")
+		} else {
+			entry.Address = StripChainPrefix(fields[0])
+			entry.Name = strings.Join(fields[1:], " ")
+		}
+		if strings.Contains(scoped, ":") {
+			chainID, err := ChainIDFromPrefix(scoped)
+			if err != nil {
+				return fmt.Errorf("%s: %w", entry.Source, err)
+			}
+			entry.ChainID = chainID
+		}
+		if err := r.Register(entry); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// LoadAddressBookFile adds the entries of a local address book file
+func (r *AddressRegistry) LoadAddressBookFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read address book: %w", err)
+	}
+	return r.ParseAddressBook(path, data)
+}
+
+// LabelAddresses labels the targets and address arguments of a call and its subcalls that are
+// not known contracts: with the names the address book has for them on the chain, and, for
+// contracts the call deploys, with the subcall that deploys them. Deployments must already be
+// predicted with PredictDeployments.
+func LabelAddresses(call *CallData, chainID uint64, book *AddressRegistry) {
+	labels := map[string]string{}
+	for _, entry := range book.EntriesOn(chainID) {
+		address := strings.ToLower(entry.Address)
+		labels[address] = entry.Name + " 📒"
+		if entry.Counterfactual() {
+			labels[address] += " counterfactual"
+		}
+	}
+
+	var collect func(call *CallData)
+	collect = func(call *CallData) {
+		if call.Deployment != nil && call.Deployment.Address != "" {
+			deployedBy := "deployed by this transaction"
+			if call.Index != "" {
+				deployedBy = "deployed by subcall #" + call.Index
+			}
+			address := strings.ToLower(call.Deployment.Address)
+			if label, ok := labels[address]; ok {
+				labels[address] = strings.TrimSuffix(label, " counterfactual") + ", " + deployedBy
+			} else {
+				labels[address] = deployedBy
+			}
+		}
+		for i := range call.SubCalls {
+			collect(&call.SubCalls[i])
+		}
+	}
+	collect(call)
+	if len(labels) == 0 {
+		return
+	}
+
+	var label func(call *CallData)
+	label = func(call *CallData) {
+		if call.TargetName == "" {
+			call.TargetLabel = labels[strings.ToLower(call.Target)]
+		}
+		for i, arg := range call.ParsedData {
+			if display, ok := labelValue(arg.Value, labels); ok && arg.Display == "" {
+				call.ParsedData[i].Display = display
+			}
+		}
+		for i := range call.SubCalls {
+			label(&call.SubCalls[i])
+		}
+	}
+	label(call)
+}
+
+// labelValue renders an address argument, or list of them, with its label when it has one
+func labelValue(value interface{}, labels map[string]string) (string, bool) {
+	switch v := value.(type) {
+	case common.Address:
+		if label, ok := labels[strings.ToLower(v.Hex())]; ok {
+			return fmt.Sprintf("%s (%s)", v.Hex(), label), true
+		}
+	case []common.Address:
+		labeled := make([]string, len(v))
+		found := false
+		for i, address := range v {
+			labeled[i] = address.Hex()
+			if label, ok := labels[strings.ToLower(address.Hex())]; ok {
+				labeled[i] = fmt.Sprintf("%s (%s)", address.Hex(), label)
+				found = true
+			}
+		}
+		if found {
+			return "[" + strings.Join(labeled, ", ") + "]", true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,99 @@
+package core
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestParseAddressBook(t *testing.T) {
+	initCodeHash := crypto.Keccak256Hash(deploymentInitCode)
+	predicted := crypto.CreateAddress2(common.HexToAddress(DeterministicDeployProxy), deploymentSalt, initCodeHash.Bytes())
+
+	text := "# team addresses\n" +
+		"0x1111111111111111111111111111111111111111 Ops multisig\n" +
+		"create2 " + DeterministicDeployProxy + " " + deploymentSalt.Hex() + " " + initCodeHash.Hex() + " New portal impl  # not deployed yet\n"
+	book := NewAddressRegistry()
+	if err := book.ParseAddressBook("book.txt", []byte(text)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if entry, ok := book.Lookup("oeth:0x1111111111111111111111111111111111111111", OPMainnetChainID); !ok || entry.Name != "Ops multisig" || entry.Counterfactual() {
+		t.Errorf("unexpected plain entry: %+v", entry)
+	}
+	entry, ok := book.Lookup(predicted.Hex(), MainnetChainID)
+	if !ok || entry.Name != "New portal impl" || !entry.Counterfactual() || entry.Source != "book.txt:3" {
+		t.Errorf("unexpected counterfactual entry: %+v", entry)
+	}
+
+	manifest := `{"entries": [{"name": "New portal impl", "address": "` + predicted.Hex() + `", "factory": "` + DeterministicDeployProxy +
+		`", "salt": "` + deploymentSalt.Hex() + `", "initCodeHash": "` + initCodeHash.Hex() + `"}]}`
+	if err := NewAddressRegistry().ParseAddressBook("book.json", []byte(manifest)); err != nil {
+		t.Fatalf("unexpected error parsing manifest: %v", err)
+	}
+}
+
+func TestParseAddressBookRejectsInvalidEntries(t *testing.T) {
+	initCodeHash := crypto.Keccak256Hash(deploymentInitCode).Hex()
+	for name, data := range map[string]string{
+		"no name":            "0x1111111111111111111111111111111111111111",
+		"short address":      "0x1111 Ops",
+		"short salt":         "create2 " + DeterministicDeployProxy + " 0x01 " + initCodeHash + " Impl",
+		"missing fields":     "create2 " + DeterministicDeployProxy + " " + deploymentSalt.Hex() + " Impl",
+		"mismatched address": `{"entries": [{"name": "Impl", "address": "0x1111111111111111111111111111111111111111", "factory": "` + DeterministicDeployProxy + `", "salt": "` + deploymentSalt.Hex() + `", "initCodeHash": "` + initCodeHash + `"}]}`,
+	} {
+		if err := NewAddressRegistry().ParseAddressBook("book", []byte(data)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestVerifyTransactionLabelsAddresses(t *testing.T) {
+	initCodeHash := crypto.Keccak256Hash(deploymentInitCode)
+	deployed := crypto.CreateAddress2(common.HexToAddress(DeterministicDeployProxy), deploymentSalt, initCodeHash.Bytes()).Hex()
+	otherSalt := common.HexToHash("0xbb")
+	counterfactual := crypto.CreateAddress2(common.HexToAddress(DeterministicDeployProxy), otherSalt, initCodeHash.Bytes()).Hex()
+
+	book := NewAddressRegistry()
+	entries := "0x1111111111111111111111111111111111111111 Ops multisig\n" +
+		"create2 " + DeterministicDeployProxy + " " + otherSalt.Hex() + " " + initCodeHash.Hex() + " Future vault\n"
+	if err := book.ParseAddressBook("book.txt", []byte(entries)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tx := multiSendTx(t,
+		multiSendTransaction{To: common.HexToAddress(DeterministicDeployProxy), Value: big.NewInt(0), Data: append(deploymentSalt.Bytes(), deploymentInitCode...)},
+		approveCall(USDCMainnetAddress, deployed, big.NewInt(1)),
+		erc20Transfer(deployed, counterfactual, big.NewInt(1)),
+		erc20Transfer(USDCMainnetAddress, airdropAlice, big.NewInt(1)),
+		erc20Transfer(USDCMainnetAddress, airdropBob, big.NewInt(1)),
+	)
+	tx.Safe = effectsSafe
+	tx.SafeVersion = "1.3.0"
+	result, err := VerifyTransaction(tx, VerifyOptions{AddressBook: book})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	calls := result.Call.SubCalls
+	if got, _ := calls[1].Argument("spender"); got.Display != deployed+" (deployed by subcall #1)" {
+		t.Errorf("spender = %v", got)
+	}
+	if calls[2].TargetLabel != "deployed by subcall #1" {
+		t.Errorf("target label = %q", calls[2].TargetLabel)
+	}
+	if got, _ := calls[2].Argument("to"); got.Display != counterfactual+" (Future vault 📒 counterfactual)" {
+		t.Errorf("to = %v", got)
+	}
+	if got, _ := calls[3].Argument("to"); got.Display != common.HexToAddress(airdropAlice).Hex()+" (Ops multisig 📒)" {
+		t.Errorf("to = %v", got)
+	}
+	if got, _ := calls[4].Argument("to"); got.Value != common.HexToAddress(airdropBob) || got.Display != "" {
+		t.Errorf("unlisted address was labeled: %v", got)
+	}
+	if calls[3].TargetLabel != "" || !strings.Contains(calls[3].TargetName, "USDC") {
+		t.Errorf("known contract was relabeled: %+v", calls[3])
+	}
+}
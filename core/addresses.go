@@ -0,0 +1,231 @@
+package core
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// AnyChain is the chain ID of address entries that apply on every chain
+const AnyChain uint64 = 0
+
+// Sources of the built-in and synced address entries
+const (
+	SourceBuiltIn  = "built-in"
+	SourceRegistry = "registry"
+)
+
+// AddressEntry names an address on a chain, or on every chain when ChainID is AnyChain. A
+// counterfactual entry names a contract that is not deployed yet by its CREATE2 inputs; its
+// address is derived from them.
+type AddressEntry struct {
+	Address  string `json:"address,omitempty"`
+	ChainID  uint64 `json:"chainId,omitempty"`
+	Name     string `json:"name"`
+	Decimals int    `json:"decimals,omitempty"`
+
+	Factory      string `json:"factory,omitempty"`
+	Salt         string `json:"salt,omitempty"`
+	InitCodeHash string `json:"initCodeHash,omitempty"`
+
+	// Source is where the entry comes from: SourceBuiltIn, SourceRegistry, or the file and line
+	// of an address book entry
+	Source string `json:"source,omitempty"`
+}
+
+// Counterfactual reports whether the entry's address is derived from CREATE2 inputs
+func (e AddressEntry) Counterfactual() bool {
+	return e.Factory != ""
+}
+
+// AddressConflict is an address registered twice under different names on the same chain
+type AddressConflict struct {
+	Existing    AddressEntry
+	Conflicting AddressEntry
+}
+
+func (c *AddressConflict) Error() string {
+	return fmt.Sprintf("%s: %s is already named %q by %s, not %q", c.Conflicting.Source, c.Existing.Address, c.Existing.Name, c.Existing.Source, c.Conflicting.Name)
+}
+
+// AddressRegistry is a set of named addresses, each on one chain or on every chain. It holds the
+// built-in contracts (KnownAddresses) as well as the reviewer's address book, and library users
+// can register their own entries.
+type AddressRegistry struct {
+	// entries maps chain IDs, with AnyChain for chain-agnostic entries, to lowercase addresses
+	entries   map[uint64]map[string]AddressEntry
+	conflicts []AddressConflict
+}
+
+// NewAddressRegistry creates an empty registry
+func NewAddressRegistry() *AddressRegistry {
+	return &AddressRegistry{entries: map[uint64]map[string]AddressEntry{}}
+}
+
+// KnownAddresses is the registry of the built-in contracts, extended by the synced registry
+var KnownAddresses = builtinAddresses()
+
+// builtinAddresses registers the built-in contracts of every supported chain
+func builtinAddresses() *AddressRegistry {
+	registry := NewAddressRegistry()
+	for chainID, contracts := range builtinContracts {
+		for address, info := range contracts {
+			if err := registry.Register(AddressEntry{Address: address, ChainID: chainID, Name: info.Name, Decimals: info.Decimals, Source: SourceBuiltIn}); err != nil {
+				panic(err)
+			}
+		}
+	}
+	return registry
+}
+
+// Register validates an entry, derives the address of a counterfactual one, and adds it. An
+// address registered again under the same name is ignored. One registered under a different name
+// on an overlapping chain keeps the existing entry and returns an *AddressConflict, which is also
+// kept for Conflicts.
+func (r *AddressRegistry) Register(entry AddressEntry) error {
+	if entry.Name == "" {
+		return fmt.Errorf("%s: address entry has no name", entry.Source)
+	}
+
+	if entry.Counterfactual() {
+		if !strings.HasPrefix(entry.Factory, "0x") || ValidateFullAddress("factory", entry.Factory) != nil {
+			return fmt.Errorf("%s: invalid factory address %q", entry.Source, entry.Factory)
+		}
+		salt, err := decodeHexDigits(entry.Salt)
+		if err != nil || len(salt) != 32 {
+			return fmt.Errorf("%s: salt must be 32 bytes of hex, got %q", entry.Source, entry.Salt)
+		}
+		initCodeHash, err := decodeHexDigits(entry.InitCodeHash)
+		if err != nil || len(initCodeHash) != 32 {
+			return fmt.Errorf("%s: init code hash must be 32 bytes of hex, got %q", entry.Source, entry.InitCodeHash)
+		}
+		address := crypto.CreateAddress2(common.HexToAddress(entry.Factory), common.BytesToHash(salt), initCodeHash).Hex()
+		if entry.Address != "" && !strings.EqualFold(entry.Address, address) {
+			return fmt.Errorf("%s: %s is given as %s but its CREATE2 inputs derive %s", entry.Source, entry.Name, entry.Address, address)
+		}
+		entry.Address = address
+		entry.Factory = ChecksumAddress(entry.Factory)
+	}
+
+	if !strings.HasPrefix(entry.Address, "0x") || ValidateFullAddress("address", entry.Address) != nil {
+		return fmt.Errorf("%s: invalid address %q", entry.Source, entry.Address)
+	}
+	entry.Address = ChecksumAddress(entry.Address)
+	key := strings.ToLower(entry.Address)
+
+	for _, existing := range r.overlapping(key, entry.ChainID) {
+		if strings.EqualFold(existing.Name, entry.Name) {
+			if existing.ChainID == entry.ChainID {
+				return nil
+			}
+			continue
+		}
+		conflict := AddressConflict{Existing: existing, Conflicting: entry}
+		r.conflicts = append(r.conflicts, conflict)
+		return &conflict
+	}
+
+	if r.entries[entry.ChainID] == nil {
+		r.entries[entry.ChainID] = map[string]AddressEntry{}
+	}
+	r.entries[entry.ChainID][key] = entry
+	return nil
+}
+
+// overlapping returns the entries for an address that apply on a chain wherever an entry for
+// chainID would: all of them for a chain-agnostic entry, else those on the chain or every chain
+func (r *AddressRegistry) overlapping(key string, chainID uint64) []AddressEntry {
+	var entries []AddressEntry
+	for _, id := range r.Chains() {
+		if chainID != AnyChain && id != chainID && id != AnyChain {
+			continue
+		}
+		if existing, ok := r.entries[id][key]; ok {
+			entries = append(entries, existing)
+		}
+	}
+	return entries
+}
+
+// Remove deletes the entry for an address on a chain, if any
+func (r *AddressRegistry) Remove(address string, chainID uint64) {
+	delete(r.entries[chainID], strings.ToLower(StripChainPrefix(address)))
+}
+
+// Lookup returns the entry for an address on a chain, preferring one registered for the chain
+// over a chain-agnostic one
+func (r *AddressRegistry) Lookup(address string, chainID uint64) (AddressEntry, bool) {
+	if r == nil {
+		return AddressEntry{}, false
+	}
+	key := strings.ToLower(StripChainPrefix(address))
+	if entry, ok := r.entries[chainID][key]; ok {
+		return entry, true
+	}
+	entry, ok := r.entries[AnyChain][key]
+	return entry, ok
+}
+
+// EntriesOn returns the entries that apply on a chain, ordered by address
+func (r *AddressRegistry) EntriesOn(chainID uint64) []AddressEntry {
+	if r == nil {
+		return nil
+	}
+	byAddress := map[string]AddressEntry{}
+	for key, entry := range r.entries[AnyChain] {
+		byAddress[key] = entry
+	}
+	if chainID != AnyChain {
+		for key, entry := range r.entries[chainID] {
+			byAddress[key] = entry
+		}
+	}
+	entries := make([]AddressEntry, 0, len(byAddress))
+	for _, entry := range byAddress {
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool { return strings.ToLower(entries[i].Address) < strings.ToLower(entries[j].Address) })
+	return entries
+}
+
+// Chains returns the chain IDs the registry has entries for, in order, starting with AnyChain
+// when it has chain-agnostic entries
+func (r *AddressRegistry) Chains() []uint64 {
+	chains := make([]uint64, 0, len(r.entries))
+	for chainID, entries := range r.entries {
+		if len(entries) > 0 {
+			chains = append(chains, chainID)
+		}
+	}
+	sort.Slice(chains, func(i, j int) bool { return chains[i] < chains[j] })
+	return chains
+}
+
+// Conflicts returns the entries that were not registered because their address already had
+// another name, in the order they were registered
+func (r *AddressRegistry) Conflicts() []AddressConflict {
+	return r.conflicts
+}
+
+// checkLabelConflicts warns about addresses in a result that the address book names differently
+// from the known contracts, since one of the two names is wrong
+func checkLabelConflicts(result *VerificationResult, book *AddressRegistry) []Warning {
+	if book == nil {
+		return nil
+	}
+	chainID := uint64(result.Transaction.Chain)
+	var warnings []Warning
+	for _, address := range collectResultAddresses(result) {
+		known, isKnown := KnownAddresses.Lookup(address, chainID)
+		entry, inBook := book.Lookup(address, chainID)
+		if isKnown && inBook && !strings.EqualFold(known.Name, entry.Name) {
+			warnings = append(warnings, newWarning(warnLabelConflict,
+				"%s is the known contract %s (%s) but the address book (%s) names it %q. Check which name is right.",
+				ChecksumAddress(address), known.Name, known.Source, entry.Source, entry.Name))
+		}
+	}
+	return warnings
+}
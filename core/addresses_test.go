@@ -0,0 +1,108 @@
+package core
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+)
+
+func TestAddressRegistry(t *testing.T) {
+	registry := NewAddressRegistry()
+	for _, entry := range []AddressEntry{
+		{Address: airdropAlice, Name: "Treasury", Source: "ops"},
+		{Address: airdropBob, ChainID: OPMainnetChainID, Name: "OP Payroll", Source: "ops"},
+		{Address: airdropBob, ChainID: BaseMainnetChainID, Name: "Base Payroll", Source: "ops"},
+		{Address: airdropAlice, Name: "treasury", Source: "again"},
+	} {
+		if err := registry.Register(entry); err != nil {
+			t.Fatalf("unexpected error registering %+v: %v", entry, err)
+		}
+	}
+
+	if entry, ok := registry.Lookup("oeth:"+strings.ToLower(airdropAlice), OPMainnetChainID); !ok || entry.Name != "Treasury" || entry.Source != "ops" {
+		t.Errorf("chain-agnostic entry = %+v, %v", entry, ok)
+	}
+	if entry, ok := registry.Lookup(airdropBob, BaseMainnetChainID); !ok || entry.Name != "Base Payroll" {
+		t.Errorf("chain-scoped entry = %+v, %v", entry, ok)
+	}
+	if _, ok := registry.Lookup(airdropBob, MainnetChainID); ok {
+		t.Error("a chain-scoped entry applies on another chain")
+	}
+	if entries := registry.EntriesOn(OPMainnetChainID); len(entries) != 2 || entries[1].Name != "OP Payroll" {
+		t.Errorf("entries on OP Mainnet = %+v", entries)
+	}
+
+	// The same address named differently where both apply is a conflict; the first name is kept
+	err := registry.Register(AddressEntry{Address: airdropBob, Name: "Impostor", Source: "book.txt:3"})
+	var conflict *AddressConflict
+	if !errors.As(err, &conflict) || conflict.Existing.Name != "OP Payroll" || !strings.Contains(err.Error(), "book.txt:3") {
+		t.Fatalf("expected a conflict, got %v", err)
+	}
+	if err := registry.Register(AddressEntry{Address: airdropAlice, ChainID: OPMainnetChainID, Name: "OP Treasury"}); err == nil {
+		t.Error("expected a chain-scoped entry to conflict with a chain-agnostic one")
+	}
+	if len(registry.Conflicts()) != 2 {
+		t.Errorf("conflicts = %+v", registry.Conflicts())
+	}
+	if entry, _ := registry.Lookup(airdropBob, OPMainnetChainID); entry.Name != "OP Payroll" {
+		t.Errorf("conflicting entry replaced the first one: %+v", entry)
+	}
+
+	registry.Remove(airdropBob, BaseMainnetChainID)
+	if _, ok := registry.Lookup(airdropBob, BaseMainnetChainID); ok {
+		t.Error("removed entry still found")
+	}
+}
+
+func TestKnownAddresses(t *testing.T) {
+	entry, ok := KnownAddresses.Lookup(OPTokenAddress, OPMainnetChainID)
+	if !ok || entry.Source != SourceBuiltIn || entry.Decimals != 18 {
+		t.Fatalf("unexpected built-in entry: %+v", entry)
+	}
+	if _, ok := KnownAddresses.Lookup(DeterministicDeployProxy, BaseMainnetChainID); !ok {
+		t.Error("deployers are not registered on every chain")
+	}
+	if _, ok := KnownAddresses.Lookup(DeterministicDeployProxy, ZkSyncEraChainID); ok {
+		t.Error("deployers are registered on zkSync Era")
+	}
+}
+
+func TestParseAddressBookChainScoped(t *testing.T) {
+	book := NewAddressRegistry()
+	text := "oeth:" + airdropAlice + " OP Treasury\n" + "base:" + airdropAlice + " Base Treasury\n"
+	if err := book.ParseAddressBook("book.txt", []byte(text)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if entry, _ := book.Lookup(airdropAlice, BaseMainnetChainID); entry.Name != "Base Treasury" || entry.Source != "book.txt:2" {
+		t.Errorf("unexpected entry: %+v", entry)
+	}
+	if _, ok := book.Lookup(airdropAlice, MainnetChainID); ok {
+		t.Error("a chain-scoped entry applies on another chain")
+	}
+
+	if err := book.ParseAddressBook("more.txt", []byte(airdropAlice+" Treasury")); err == nil || !strings.Contains(err.Error(), "already named") {
+		t.Errorf("expected a conflict, got %v", err)
+	}
+	if err := NewAddressRegistry().ParseAddressBook("book.txt", []byte("xyz:"+airdropAlice+" Treasury")); err == nil {
+		t.Error("expected an error for an unknown chain prefix")
+	}
+}
+
+func TestCheckLabelConflicts(t *testing.T) {
+	book := NewAddressRegistry()
+	if err := book.ParseAddressBook("book.txt", []byte(USDCMainnetAddress+" Tether\n"+airdropAlice+" Payroll")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	tx := multiSendTx(t, erc20Transfer(USDCMainnetAddress, airdropAlice, big.NewInt(1)))
+	tx.Safe = effectsSafe
+	tx.SafeVersion = "1.3.0"
+	result, err := VerifyTransaction(tx, VerifyOptions{AddressBook: book})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	warnings := warningsContaining(result.Warnings, SeverityWarning, "names it")
+	if len(warnings) != 1 || !strings.Contains(warnings[0].Message, `"Tether"`) || !strings.Contains(warnings[0].Message, "book.txt:1") {
+		t.Fatalf("expected a warning about the USDC label, got %+v", result.Warnings)
+	}
+}
@@ -0,0 +1,456 @@
+package core
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math/big"
+	"sort"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Airdrop token types, as used in the token_type column of Safe CSV Airdrop files
+const (
+	AirdropNative  = "native"
+	AirdropERC20   = "erc20"
+	AirdropERC721  = "erc721"
+	AirdropERC1155 = "erc1155"
+)
+
+// Token transfer selectors recognized in airdrop batches
+var (
+	multiSendSelector          = crypto.Keccak256([]byte(SafeMultisendSig))[:4]
+	erc20TransferSelector      = crypto.Keccak256([]byte("transfer(address,uint256)"))[:4]
+	erc721TransferFromSelector = crypto.Keccak256([]byte("transferFrom(address,address,uint256)"))[:4]
+	erc721SafeTransferSelector = crypto.Keccak256([]byte("safeTransferFrom(address,address,uint256)"))[:4]
+	erc1155TransferSelector    = crypto.Keccak256([]byte("safeTransferFrom(address,address,uint256,uint256,bytes)"))[:4]
+)
+
+// AirdropRow is a row of a Safe CSV Airdrop file
+type AirdropRow struct {
+	Line      int    `json:"line"`
+	TokenType string `json:"tokenType"`
+	Token     string `json:"token,omitempty"`
+	Receiver  string `json:"receiver"`
+	Amount    string `json:"amount,omitempty"`
+	ID        string `json:"id,omitempty"`
+}
+
+// AirdropTransfer is a transfer made by a batch transaction
+type AirdropTransfer struct {
+	// Index is the position of the call in the batch, starting at 1
+	Index     int      `json:"index"`
+	TokenType string   `json:"tokenType"`
+	Token     string   `json:"token,omitempty"`
+	Receiver  string   `json:"receiver"`
+	Amount    *big.Int `json:"amount,omitempty"`
+	ID        *big.Int `json:"id,omitempty"`
+}
+
+// Airdrop divergence kinds
+const (
+	AirdropMissing    = "missing"
+	AirdropExtra      = "extra"
+	AirdropMismatch   = "mismatch"
+	AirdropUnverified = "unverified"
+)
+
+// AirdropDivergence is a difference between the CSV file and the transaction
+type AirdropDivergence struct {
+	Kind     string           `json:"kind"`
+	Row      *AirdropRow      `json:"row,omitempty"`
+	Transfer *AirdropTransfer `json:"transfer,omitempty"`
+	Message  string           `json:"message"`
+}
+
+// AirdropTotal is the total a batch sends of one token
+type AirdropTotal struct {
+	TokenType string `json:"tokenType"`
+	Token     string `json:"token,omitempty"`
+	Transfers int    `json:"transfers"`
+
+	// Amount is scaled by the token's decimals when they are known, and raw otherwise
+	Amount string   `json:"amount"`
+	Raw    *big.Int `json:"raw"`
+}
+
+// AirdropCheck is the result of cross-checking a CSV airdrop file against a batch transaction
+type AirdropCheck struct {
+	Rows        int                 `json:"rows"`
+	Transfers   int                 `json:"transfers"`
+	Matched     int                 `json:"matched"`
+	Totals      []AirdropTotal      `json:"totals"`
+	Divergences []AirdropDivergence `json:"divergences,omitempty"`
+}
+
+// OK reports whether every row matched a transfer and nothing else was sent
+func (c *AirdropCheck) OK() bool {
+	return len(c.Divergences) == 0
+}
+
+// ParseAirdropCSV parses a Safe CSV Airdrop file. Both the current token_type,token_address,
+// receiver,amount,id layout and the older token_address,receiver,amount layout are accepted.
+func ParseAirdropCSV(r io.Reader) ([]AirdropRow, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	if _, ok := columns["receiver"]; !ok {
+		return nil, fmt.Errorf("CSV header has no receiver column")
+	}
+	field := func(record []string, name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	var rows []AirdropRow
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CSV: %w", err)
+		}
+		line, _ := reader.FieldPos(0)
+		row := AirdropRow{
+			Line:      line,
+			TokenType: strings.ToLower(field(record, "token_type")),
+			Token:     field(record, "token_address"),
+			Receiver:  field(record, "receiver"),
+			Amount:    field(record, "amount"),
+			ID:        field(record, "id"),
+		}
+		if row.Receiver == "" && row.Token == "" && row.Amount == "" {
+			continue
+		}
+
+		switch row.TokenType {
+		case "":
+			row.TokenType = AirdropERC20
+			if row.Token == "" {
+				row.TokenType = AirdropNative
+			}
+		case "nft":
+			// The airdrop app uses one type for both NFT standards; an amount means ERC-1155
+			row.TokenType = AirdropERC721
+			if row.Amount != "" {
+				row.TokenType = AirdropERC1155
+			}
+		case AirdropNative, AirdropERC20, AirdropERC721, AirdropERC1155:
+		default:
+			return nil, fmt.Errorf("line %d: unknown token type %q", row.Line, row.TokenType)
+		}
+
+		if !common.IsHexAddress(row.Receiver) {
+			return nil, fmt.Errorf("line %d: receiver %q is not a full address (ENS names cannot be checked offline)", row.Line, row.Receiver)
+		}
+		if row.TokenType != AirdropNative && !common.IsHexAddress(row.Token) {
+			return nil, fmt.Errorf("line %d: token address %q is not a full address", row.Line, row.Token)
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// AirdropTransfers decodes the transfers a transaction makes: each call of a MultiSend batch, or
+// the transaction itself when it is a single transfer. Calls that are not plain transfers are
+// returned with an empty token type.
+func AirdropTransfers(tx SafeTransaction) ([]AirdropTransfer, error) {
+	calls, err := batchCalls(tx)
+	if err != nil {
+		return nil, err
+	}
+	transfers := make([]AirdropTransfer, 0, len(calls))
+	for i, call := range calls {
+		transfers = append(transfers, airdropTransfer(i+1, call))
+	}
+	return transfers, nil
+}
+
+// batchCalls returns the calls of a MultiSend batch, or the transaction itself as a single call
+func batchCalls(tx SafeTransaction) ([]multiSendTransaction, error) {
+	data, err := decodeHexDigits(tx.Data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid transaction data: %w", err)
+	}
+	value := tx.Value
+	if value == nil {
+		value = new(big.Int)
+	}
+
+	calls := []multiSendTransaction{{Operation: uint8(tx.Operation), To: common.HexToAddress(StripChainPrefix(tx.To)), Value: value, Data: data}}
+	if SafeMultisendAddresses[strings.ToLower(StripChainPrefix(tx.To))] && len(data) >= 4 && bytes.Equal(data[:4], multiSendSelector) {
+		args, err := abi.Arguments{{Type: mustABIType("bytes")}}.Unpack(data[4:])
+		if err != nil {
+			return nil, fmt.Errorf("invalid multiSend data: %w", err)
+		}
+		if calls, err = decodeMultiSendTransactions(args[0].([]byte)); err != nil {
+			return nil, err
+		}
+	}
+	return calls, nil
+}
+
+// airdropTransfer classifies a single call as a transfer
+func airdropTransfer(index int, call multiSendTransaction) AirdropTransfer {
+	transfer := AirdropTransfer{Index: index, Token: call.To.Hex(), Receiver: call.To.Hex()}
+	if call.Operation != 0 {
+		return transfer
+	}
+	word := func(i int) []byte { return call.Data[4+32*i : 4+32*(i+1)] }
+
+	switch {
+	case len(call.Data) == 0:
+		transfer.TokenType = AirdropNative
+		transfer.Token = ""
+		transfer.Amount = call.Value
+	case call.Value.Sign() != 0 || len(call.Data) < 4:
+	case bytes.Equal(call.Data[:4], erc20TransferSelector) && len(call.Data) == 4+64:
+		transfer.TokenType = AirdropERC20
+		transfer.Receiver = common.BytesToAddress(word(0)).Hex()
+		transfer.Amount = new(big.Int).SetBytes(word(1))
+	case (bytes.Equal(call.Data[:4], erc721TransferFromSelector) || bytes.Equal(call.Data[:4], erc721SafeTransferSelector)) && len(call.Data) == 4+96:
+		transfer.TokenType = AirdropERC721
+		transfer.Receiver = common.BytesToAddress(word(1)).Hex()
+		transfer.ID = new(big.Int).SetBytes(word(2))
+	case bytes.Equal(call.Data[:4], erc1155TransferSelector) && len(call.Data) >= 4+160:
+		transfer.TokenType = AirdropERC1155
+		transfer.Receiver = common.BytesToAddress(word(1)).Hex()
+		transfer.ID = new(big.Int).SetBytes(word(2))
+		transfer.Amount = new(big.Int).SetBytes(word(3))
+	}
+	return transfer
+}
+
+// CheckAirdrop cross-checks the rows of an airdrop CSV against the transfers a transaction makes.
+// Rows and transfers are matched regardless of order. ERC-20 amounts in the CSV are in whole
+// tokens, so decimals (keyed by lowercase token address) supply the scale for tokens that are
+// not known contracts.
+func CheckAirdrop(tx SafeTransaction, rows []AirdropRow, decimals map[string]int) (*AirdropCheck, error) {
+	transfers, err := AirdropTransfers(tx)
+	if err != nil {
+		return nil, err
+	}
+	tokenDecimals := airdropTokenDecimals(uint64(tx.Chain), decimals)
+
+	check := &AirdropCheck{Rows: len(rows), Transfers: len(transfers)}
+
+	// Expected raw amounts per row; nil when the token's decimals are unknown
+	expected := make([]*big.Int, len(rows))
+	for i, row := range rows {
+		if row.TokenType == AirdropERC721 {
+			continue
+		}
+		d, ok := tokenDecimals(row.TokenType, row.Token)
+		if !ok {
+			continue
+		}
+		amount, err := parseTokenAmount(row.Amount, d)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", row.Line, err)
+		}
+		expected[i] = amount
+	}
+
+	sameRecipient := func(row AirdropRow, transfer AirdropTransfer) bool {
+		if row.TokenType != transfer.TokenType || !strings.EqualFold(row.Receiver, transfer.Receiver) {
+			return false
+		}
+		if row.TokenType != AirdropNative && !strings.EqualFold(row.Token, transfer.Token) {
+			return false
+		}
+		if row.TokenType == AirdropERC721 || row.TokenType == AirdropERC1155 {
+			id, ok := new(big.Int).SetString(row.ID, 10)
+			return ok && transfer.ID != nil && id.Cmp(transfer.ID) == 0
+		}
+		return true
+	}
+
+	rowMatched := make([]bool, len(rows))
+	transferMatched := make([]bool, len(transfers))
+
+	// First pass: exact matches
+	for i, row := range rows {
+		for j, transfer := range transfers {
+			if transferMatched[j] || !sameRecipient(row, transfer) {
+				continue
+			}
+			if row.TokenType != AirdropERC721 && (expected[i] == nil || expected[i].Cmp(transfer.Amount) != 0) {
+				continue
+			}
+			rowMatched[i], transferMatched[j] = true, true
+			check.Matched++
+			break
+		}
+	}
+
+	// Second pass: same token and receiver but a different or uncheckable amount
+	for i, row := range rows {
+		if rowMatched[i] {
+			continue
+		}
+		for j, transfer := range transfers {
+			if transferMatched[j] || !sameRecipient(row, transfer) {
+				continue
+			}
+			rowMatched[i], transferMatched[j] = true, true
+			row, transfer := row, transfer
+			if expected[i] == nil {
+				check.Divergences = append(check.Divergences, AirdropDivergence{
+					Kind: AirdropUnverified, Row: &row, Transfer: &transfer,
+					Message: fmt.Sprintf("line %d: decimals of token %s are unknown; the transaction sends %s raw units for CSV amount %s",
+						row.Line, ChecksumAddress(row.Token), transfer.Amount, row.Amount),
+				})
+			} else {
+				check.Divergences = append(check.Divergences, AirdropDivergence{
+					Kind: AirdropMismatch, Row: &row, Transfer: &transfer,
+					Message: fmt.Sprintf("line %d: CSV sends %s to %s but call %d sends %s",
+						row.Line, row.Amount, ChecksumAddress(row.Receiver), transfer.Index, formatAirdropAmount(transfer, tokenDecimals)),
+				})
+			}
+			break
+		}
+	}
+
+	for i, row := range rows {
+		if rowMatched[i] {
+			continue
+		}
+		row := row
+		check.Divergences = append(check.Divergences, AirdropDivergence{
+			Kind: AirdropMissing, Row: &row,
+			Message: fmt.Sprintf("line %d: no transfer of %s to %s in the transaction", row.Line, describeAirdropRow(row), ChecksumAddress(row.Receiver)),
+		})
+	}
+	for j, transfer := range transfers {
+		if transferMatched[j] {
+			continue
+		}
+		transfer := transfer
+		message := fmt.Sprintf("call %d: sends %s to %s, which is not in the CSV", transfer.Index, formatAirdropAmount(transfer, tokenDecimals), transfer.Receiver)
+		if transfer.TokenType == "" {
+			message = fmt.Sprintf("call %d: calls %s and is not a plain transfer", transfer.Index, transfer.Receiver)
+		}
+		check.Divergences = append(check.Divergences, AirdropDivergence{Kind: AirdropExtra, Transfer: &transfer, Message: message})
+	}
+
+	check.Totals = airdropTotals(transfers, tokenDecimals)
+	return check, nil
+}
+
+// airdropTokenDecimals looks up the decimals of a transferred token, preferring those given
+// (keyed by lowercase token address) over known contracts
+func airdropTokenDecimals(chainID uint64, decimals map[string]int) func(tokenType, token string) (int, bool) {
+	return func(tokenType, token string) (int, bool) {
+		switch tokenType {
+		case AirdropNative:
+			return 18, true
+		case AirdropERC721, AirdropERC1155:
+			return 0, true
+		}
+		if d, ok := decimals[strings.ToLower(token)]; ok {
+			return d, true
+		}
+		if info, ok := GetKnownContract(token, chainID); ok && info.Decimals > 0 {
+			return info.Decimals, true
+		}
+		return 0, false
+	}
+}
+
+// airdropTotals sums the transfers per token
+func airdropTotals(transfers []AirdropTransfer, tokenDecimals func(tokenType, token string) (int, bool)) []AirdropTotal {
+	type key struct{ tokenType, token string }
+	sums := map[key]*big.Int{}
+	counts := map[key]int{}
+	var order []key
+	for _, transfer := range transfers {
+		if transfer.TokenType == "" {
+			continue
+		}
+		k := key{transfer.TokenType, transfer.Token}
+		if _, ok := sums[k]; !ok {
+			sums[k] = new(big.Int)
+			order = append(order, k)
+		}
+		counts[k]++
+		if transfer.Amount != nil {
+			sums[k].Add(sums[k], transfer.Amount)
+		} else {
+			sums[k].Add(sums[k], big.NewInt(1))
+		}
+	}
+	sort.SliceStable(order, func(i, j int) bool { return order[i].tokenType == AirdropNative && order[j].tokenType != AirdropNative })
+
+	totals := make([]AirdropTotal, 0, len(order))
+	for _, k := range order {
+		amount := sums[k].String()
+		if d, ok := tokenDecimals(k.tokenType, k.token); ok && d > 0 {
+			amount = ParseDecimals(sums[k], d)
+		} else if !ok {
+			amount += " (raw, unknown token decimals)"
+		}
+		totals = append(totals, AirdropTotal{TokenType: k.tokenType, Token: k.token, Transfers: counts[k], Amount: amount, Raw: sums[k]})
+	}
+	return totals
+}
+
+// formatAirdropAmount describes what a transfer sends
+func formatAirdropAmount(transfer AirdropTransfer, tokenDecimals func(tokenType, token string) (int, bool)) string {
+	switch transfer.TokenType {
+	case AirdropNative:
+		return ParseDecimals(transfer.Amount, 18) + " ETH"
+	case AirdropERC721:
+		return fmt.Sprintf("NFT %s #%s", transfer.Token, transfer.ID)
+	case AirdropERC1155:
+		return fmt.Sprintf("%s of token %s #%s", transfer.Amount, transfer.Token, transfer.ID)
+	}
+	if d, ok := tokenDecimals(transfer.TokenType, transfer.Token); ok {
+		return fmt.Sprintf("%s of token %s", ParseDecimals(transfer.Amount, d), transfer.Token)
+	}
+	return fmt.Sprintf("%s raw units of token %s", transfer.Amount, transfer.Token)
+}
+
+// describeAirdropRow describes what a CSV row sends
+func describeAirdropRow(row AirdropRow) string {
+	switch row.TokenType {
+	case AirdropNative:
+		return row.Amount + " ETH"
+	case AirdropERC721:
+		return fmt.Sprintf("NFT %s #%s", ChecksumAddress(row.Token), row.ID)
+	case AirdropERC1155:
+		return fmt.Sprintf("%s of token %s #%s", row.Amount, ChecksumAddress(row.Token), row.ID)
+	}
+	return fmt.Sprintf("%s of token %s", row.Amount, ChecksumAddress(row.Token))
+}
+
+// parseTokenAmount converts a decimal amount in whole tokens to raw units
+func parseTokenAmount(amount string, decimals int) (*big.Int, error) {
+	amount = strings.ReplaceAll(amount, ",", "")
+	whole, fraction, _ := strings.Cut(amount, ".")
+	if len(fraction) > decimals {
+		return nil, fmt.Errorf("amount %s has more than %d decimal places", amount, decimals)
+	}
+	raw, ok := new(big.Int).SetString(whole+fraction+strings.Repeat("0", decimals-len(fraction)), 10)
+	if !ok || raw.Sign() < 0 {
+		return nil, fmt.Errorf("invalid amount %q", amount)
+	}
+	return raw, nil
+}
@@ -0,0 +1,155 @@
+package core
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+)
+
+const (
+	airdropAlice = "0x1111111111111111111111111111111111111111"
+	airdropBob   = "0x2222222222222222222222222222222222222222"
+	airdropToken = "0x3333333333333333333333333333333333333333"
+)
+
+// multiSendTx packs calls into a MultiSend batch transaction on mainnet
+func multiSendTx(t *testing.T, calls ...multiSendTransaction) SafeTransaction {
+	t.Helper()
+	var packed []byte
+	for _, call := range calls {
+		packed = append(packed, call.Operation)
+		packed = append(packed, call.To.Bytes()...)
+		packed = append(packed, math.U256Bytes(new(big.Int).Set(call.Value))...)
+		packed = append(packed, math.U256Bytes(big.NewInt(int64(len(call.Data))))...)
+		packed = append(packed, call.Data...)
+	}
+	args, err := abi.Arguments{{Type: mustABIType("bytes")}}.Pack(packed)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return SafeTransaction{
+		Chain:     MainnetChainID,
+		To:        SafeMultisendAddress,
+		Value:     big.NewInt(0),
+		Data:      hexBytes(append(append([]byte{}, multiSendSelector...), args...)),
+		Operation: 1,
+	}
+}
+
+// erc20Transfer is a transfer(to, amount) call on a token
+func erc20Transfer(token, to string, amount *big.Int) multiSendTransaction {
+	data := append(append([]byte{}, erc20TransferSelector...), common.LeftPadBytes(common.HexToAddress(to).Bytes(), 32)...)
+	data = append(data, math.U256Bytes(new(big.Int).Set(amount))...)
+	return multiSendTransaction{To: common.HexToAddress(token), Value: big.NewInt(0), Data: data}
+}
+
+// nativeTransfer is a plain ETH transfer
+func nativeTransfer(to string, wei *big.Int) multiSendTransaction {
+	return multiSendTransaction{To: common.HexToAddress(to), Value: wei}
+}
+
+func parseAirdrop(t *testing.T, csv string) []AirdropRow {
+	t.Helper()
+	rows, err := ParseAirdropCSV(strings.NewReader(csv))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return rows
+}
+
+func TestCheckAirdropMatches(t *testing.T) {
+	tx := multiSendTx(t,
+		erc20Transfer(USDCMainnetAddress, airdropBob, big.NewInt(2_500_000)),
+		nativeTransfer(airdropAlice, big.NewInt(1e18)),
+		erc20Transfer(airdropToken, airdropAlice, new(big.Int).Mul(big.NewInt(3), big.NewInt(1e17))),
+	)
+	rows := parseAirdrop(t, "token_type,token_address,receiver,amount,id\n"+
+		"native,,"+airdropAlice+",1,\n"+
+		"erc20,"+USDCMainnetAddress+","+airdropBob+",2.5,\n"+
+		"erc20,"+airdropToken+","+airdropAlice+",0.3,\n")
+
+	check, err := CheckAirdrop(tx, rows, map[string]int{airdropToken: 18})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !check.OK() || check.Matched != 3 {
+		t.Fatalf("expected every row to match, got %+v", check)
+	}
+	if len(check.Totals) != 3 || check.Totals[0].TokenType != AirdropNative || check.Totals[0].Amount != "1.00" {
+		t.Errorf("unexpected totals: %+v", check.Totals)
+	}
+}
+
+func TestCheckAirdropDivergences(t *testing.T) {
+	tx := multiSendTx(t,
+		nativeTransfer(airdropAlice, big.NewInt(1e18)),
+		erc20Transfer(USDCMainnetAddress, airdropBob, big.NewInt(25_000_000)),
+		nativeTransfer(airdropBob, big.NewInt(5e17)),
+		erc20Transfer(airdropToken, airdropAlice, big.NewInt(7)),
+		multiSendTransaction{To: common.HexToAddress(airdropToken), Value: big.NewInt(0), Data: []byte{0xde, 0xad, 0xbe, 0xef}},
+	)
+	// Old layout: token_address,receiver,amount
+	rows := parseAirdrop(t, "token_address,receiver,amount\n"+
+		","+airdropAlice+",1\n"+
+		USDCMainnetAddress+","+airdropBob+",2.5\n"+
+		airdropToken+","+airdropAlice+",7\n"+
+		","+airdropAlice+",2\n")
+
+	check, err := CheckAirdrop(tx, rows, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	kinds := map[string]int{}
+	for _, divergence := range check.Divergences {
+		kinds[divergence.Kind]++
+	}
+	want := map[string]int{AirdropMismatch: 1, AirdropUnverified: 1, AirdropMissing: 1, AirdropExtra: 2}
+	for kind, n := range want {
+		if kinds[kind] != n {
+			t.Errorf("%s divergences = %d, want %d: %+v", kind, kinds[kind], n, check.Divergences)
+		}
+	}
+	if check.Matched != 1 || check.OK() {
+		t.Errorf("matched = %d, ok = %v", check.Matched, check.OK())
+	}
+}
+
+func TestCheckAirdropSingleTransfer(t *testing.T) {
+	tx := SafeTransaction{Chain: MainnetChainID, To: airdropAlice, Value: big.NewInt(1e18), Data: "0x"}
+	check, err := CheckAirdrop(tx, parseAirdrop(t, "token_type,token_address,receiver,amount,id\nnative,,"+airdropAlice+",1,\n"), nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !check.OK() {
+		t.Errorf("expected a single transfer to match: %+v", check.Divergences)
+	}
+}
+
+func TestParseAirdropCSVRejectsInvalidRows(t *testing.T) {
+	for _, csv := range []string{
+		"token_type,token_address,receiver,amount,id\nnative,,vitalik.eth,1,\n",
+		"token_type,token_address,receiver,amount,id\nerc20,0x1234,0x1111111111111111111111111111111111111111,1,\n",
+		"token_type,token_address,receiver,amount,id\ncoin,,0x1111111111111111111111111111111111111111,1,\n",
+		"address,amount\n0x1111111111111111111111111111111111111111,1\n",
+	} {
+		if _, err := ParseAirdropCSV(strings.NewReader(csv)); err == nil {
+			t.Errorf("expected an error for %q", csv)
+		}
+	}
+}
+
+func TestParseTokenAmount(t *testing.T) {
+	for amount, want := range map[string]string{"1": "1000000", "2.5": "2500000", "1,000.000001": "1000000001", "0.1": "100000"} {
+		got, err := parseTokenAmount(amount, 6)
+		if err != nil || got.String() != want {
+			t.Errorf("parseTokenAmount(%q) = %v, %v; want %s", amount, got, err, want)
+		}
+	}
+	if _, err := parseTokenAmount("0.0000001", 6); err == nil {
+		t.Error("expected an error for too many decimal places")
+	}
+}
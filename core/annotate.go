@@ -0,0 +1,274 @@
+package core
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// CalldataWord is one annotated piece of calldata: the 4-byte selector or a 32-byte ABI word
+type CalldataWord struct {
+	Offset int    `json:"offset"`
+	Data   string `json:"data"`
+	Label  string `json:"label"`
+}
+
+// AnnotateCalldata walks the ABI encoding of calldata for the function it calls on a target and
+// labels every word: the selector, each head slot, offsets and lengths of dynamic values, and
+// their tail data. Words that no part of the encoding refers to are labelled as unreferenced,
+// since a faithful encoding should not contain any.
+func AnnotateCalldata(to string, chainID uint64, data string) ([]CalldataWord, error) {
+	raw, err := hex.DecodeString(strings.TrimPrefix(data, "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid calldata: %w", err)
+	}
+	if len(raw) < 4 {
+		return nil, errors.New("calldata is shorter than a function selector")
+	}
+
+	functionInfo, ok := lookupFunction(to, chainID, hex.EncodeToString(raw[:4]))
+	if !ok {
+		return nil, fmt.Errorf("unknown function selector 0x%x", raw[:4])
+	}
+
+	a := &annotator{data: raw[4:]}
+	var types []abi.Type
+	var names []string
+	for i, input := range functionInfo.ABI.Inputs {
+		types = append(types, input.Type)
+		name := input.Name
+		if name == "" {
+			name = fmt.Sprintf("arg%d", i)
+		}
+		names = append(names, name)
+	}
+	a.walkTuple(0, types, names)
+
+	words := []CalldataWord{{Offset: 0, Data: hex.EncodeToString(raw[:4]), Label: "selector: " + functionInfo.Signature}}
+	for _, word := range a.annotated() {
+		word.Offset += 4
+		words = append(words, word)
+	}
+	if a.err != nil {
+		return words, a.err
+	}
+	return words, nil
+}
+
+// annotator accumulates labels for the 32-byte words of an ABI encoding
+type annotator struct {
+	data   []byte
+	labels map[int][]string
+	err    error
+}
+
+// label attaches a label to the word at pos
+func (a *annotator) label(pos int, text string) bool {
+	if pos < 0 || pos+32 > len(a.data) {
+		if a.err == nil {
+			a.err = fmt.Errorf("%s: word at byte %d is past the end of the calldata", text, pos+4)
+		}
+		return false
+	}
+	if a.labels == nil {
+		a.labels = map[int][]string{}
+	}
+	a.labels[pos] = append(a.labels[pos], text)
+	return true
+}
+
+// word returns the 32-byte word at pos
+func (a *annotator) word(pos int) []byte {
+	return a.data[pos : pos+32]
+}
+
+// annotated returns every word in order, including unreferenced ones. A word with several labels
+// is referenced by more than one part of the encoding.
+func (a *annotator) annotated() []CalldataWord {
+	var words []CalldataWord
+	for pos := 0; pos < len(a.data); pos += 32 {
+		end := min(pos+32, len(a.data))
+		labels := a.labels[pos]
+		text := strings.Join(labels, "; ")
+		if len(labels) == 0 {
+			text = "⚠️ unreferenced data (not part of the decoded arguments)"
+		}
+		words = append(words, CalldataWord{Offset: pos, Data: hex.EncodeToString(a.data[pos:end]), Label: text})
+	}
+	return words
+}
+
+// isDynamicType reports whether an ABI type is encoded in the tail
+func isDynamicType(t abi.Type) bool {
+	switch t.T {
+	case abi.StringTy, abi.BytesTy, abi.SliceTy:
+		return true
+	case abi.ArrayTy:
+		return isDynamicType(*t.Elem)
+	case abi.TupleTy:
+		for _, elem := range t.TupleElems {
+			if isDynamicType(*elem) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// staticSize returns the encoded size in bytes of a static ABI type
+func staticSize(t abi.Type) int {
+	switch t.T {
+	case abi.ArrayTy:
+		return t.Size * staticSize(*t.Elem)
+	case abi.TupleTy:
+		size := 0
+		for _, elem := range t.TupleElems {
+			size += staticSize(*elem)
+		}
+		return size
+	}
+	return 32
+}
+
+// walkTuple annotates a sequence of values encoded head-first starting at base
+func (a *annotator) walkTuple(base int, types []abi.Type, names []string) {
+	pos := base
+	for i, t := range types {
+		if a.err != nil {
+			return
+		}
+		if isDynamicType(t) {
+			if !a.label(pos, fmt.Sprintf("%s (%s): offset", names[i], t.String())) {
+				return
+			}
+			offset, ok := a.wordInt(pos)
+			if !ok || base+offset > len(a.data) {
+				a.err = fmt.Errorf("%s: offset %s is out of range", names[i], new(big.Int).SetBytes(a.word(pos)))
+				return
+			}
+			a.labels[pos][len(a.labels[pos])-1] += fmt.Sprintf(" → byte %d", base+offset+4)
+			a.walkTail(base+offset, t, names[i])
+			pos += 32
+			continue
+		}
+		a.walkStatic(pos, t, names[i])
+		pos += staticSize(t)
+	}
+}
+
+// walkStatic annotates a static value encoded in place at pos
+func (a *annotator) walkStatic(pos int, t abi.Type, name string) {
+	switch t.T {
+	case abi.TupleTy:
+		a.walkTuple(pos, tupleTypes(t), tupleNames(t, name))
+	case abi.ArrayTy:
+		types, names := repeated(*t.Elem, name, t.Size)
+		a.walkTuple(pos, types, names)
+	default:
+		if pos >= 0 && pos+32 <= len(a.data) {
+			a.label(pos, fmt.Sprintf("%s (%s) = %s", name, t.String(), formatWord(t, a.word(pos))))
+		} else {
+			a.label(pos, name)
+		}
+	}
+}
+
+// walkTail annotates a dynamic value encoded at pos
+func (a *annotator) walkTail(pos int, t abi.Type, name string) {
+	switch t.T {
+	case abi.StringTy, abi.BytesTy:
+		if !a.label(pos, fmt.Sprintf("%s: length", name)) {
+			return
+		}
+		length, ok := a.wordInt(pos)
+		if !ok || pos+32+length > len(a.data) {
+			a.err = fmt.Errorf("%s: length %s is out of range", name, new(big.Int).SetBytes(a.word(pos)))
+			return
+		}
+		a.labels[pos][len(a.labels[pos])-1] += fmt.Sprintf(" = %d bytes", length)
+		for i := 0; i*32 < length; i++ {
+			a.label(pos+32+i*32, fmt.Sprintf("%s: data [%d/%d]", name, i+1, (length+31)/32))
+		}
+	case abi.SliceTy:
+		if !a.label(pos, fmt.Sprintf("%s: length", name)) {
+			return
+		}
+		length, ok := a.wordInt(pos)
+		if !ok || length > len(a.data) {
+			a.err = fmt.Errorf("%s: length %s is out of range", name, new(big.Int).SetBytes(a.word(pos)))
+			return
+		}
+		a.labels[pos][len(a.labels[pos])-1] += fmt.Sprintf(" = %d", length)
+		types, names := repeated(*t.Elem, name, length)
+		a.walkTuple(pos+32, types, names)
+	case abi.ArrayTy:
+		types, names := repeated(*t.Elem, name, t.Size)
+		a.walkTuple(pos, types, names)
+	case abi.TupleTy:
+		a.walkTuple(pos, tupleTypes(t), tupleNames(t, name))
+	}
+}
+
+// wordInt reads the word at pos as a non-negative int offset or length
+func (a *annotator) wordInt(pos int) (int, bool) {
+	n := new(big.Int).SetBytes(a.word(pos))
+	if !n.IsInt64() || n.Int64() > int64(len(a.data)) {
+		return 0, false
+	}
+	return int(n.Int64()), true
+}
+
+// tupleTypes returns the component types of a tuple
+func tupleTypes(t abi.Type) []abi.Type {
+	types := make([]abi.Type, len(t.TupleElems))
+	for i, elem := range t.TupleElems {
+		types[i] = *elem
+	}
+	return types
+}
+
+// tupleNames returns the dotted component names of a tuple
+func tupleNames(t abi.Type, name string) []string {
+	names := make([]string, len(t.TupleRawNames))
+	for i, raw := range t.TupleRawNames {
+		names[i] = name + "." + raw
+	}
+	return names
+}
+
+// repeated returns n copies of an element type with indexed names
+func repeated(elem abi.Type, name string, n int) ([]abi.Type, []string) {
+	types := make([]abi.Type, n)
+	names := make([]string, n)
+	for i := range types {
+		types[i] = elem
+		names[i] = fmt.Sprintf("%s[%d]", name, i)
+	}
+	return types, names
+}
+
+// formatWord renders the value of a static word of the given type
+func formatWord(t abi.Type, word []byte) string {
+	switch t.T {
+	case abi.AddressTy:
+		return common.BytesToAddress(word).Hex()
+	case abi.BoolTy:
+		return fmt.Sprintf("%t", word[31] == 1)
+	case abi.UintTy:
+		return new(big.Int).SetBytes(word).String()
+	case abi.IntTy:
+		n := new(big.Int).SetBytes(word)
+		if word[0]&0x80 != 0 {
+			n.Sub(n, new(big.Int).Lsh(big.NewInt(1), 256))
+		}
+		return n.String()
+	case abi.FixedBytesTy:
+		return "0x" + hex.EncodeToString(word[:t.Size])
+	}
+	return "0x" + hex.EncodeToString(word)
+}
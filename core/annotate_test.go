@@ -0,0 +1,126 @@
+package core
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// expectLabels checks that the annotation contains each label (by prefix) and no unreferenced words
+func expectLabels(t *testing.T, words []CalldataWord, labels ...string) {
+	t.Helper()
+	for _, word := range words {
+		if strings.Contains(word.Label, "unreferenced") {
+			t.Errorf("unexpected unreferenced word at %d", word.Offset)
+		}
+	}
+	for _, want := range labels {
+		found := false
+		for _, word := range words {
+			if strings.HasPrefix(word.Label, want) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			t.Errorf("no word labelled %q in %+v", want, words)
+		}
+	}
+}
+
+func TestAnnotateCalldataStatic(t *testing.T) {
+	words, err := AnnotateCalldata(OPTokenAddress, OPMainnetChainID, grantsTransferTx().Data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(words) != 3 {
+		t.Fatalf("expected selector and two words, got %d", len(words))
+	}
+	if words[0].Data != "a9059cbb" || words[1].Offset != 4 || words[2].Offset != 36 {
+		t.Errorf("unexpected layout %+v", words)
+	}
+	expectLabels(t, words,
+		"selector: transfer(address,uint256)",
+		"to (address) = "+grantsRecipient,
+		"amount (uint256) = 4000000000000000000000000",
+	)
+}
+
+func TestAnnotateCalldataDynamic(t *testing.T) {
+	data := encodeKnownCall(t, "propose(address[],uint256[],bytes[],string)",
+		[]common.Address{common.HexToAddress(OPTokenAddress)}, []*big.Int{big.NewInt(5)}, [][]byte{grantsTransferCalldata(t)}, "Fund grants")
+
+	words, err := AnnotateCalldata(OptimismGovernor, OPMainnetChainID, data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectLabels(t, words,
+		"targets (address[]): offset → byte 132",
+		"targets: length = 1",
+		"targets[0] (address) = "+OPTokenAddress,
+		"values[0] (uint256) = 5",
+		"calldatas[0] (bytes): offset",
+		"calldatas[0]: length = 68 bytes",
+		"calldatas[0]: data [3/3]",
+		"description: length = 11 bytes",
+		"description: data [1/1]",
+	)
+}
+
+func TestAnnotateCalldataTuple(t *testing.T) {
+	type call3 struct {
+		Target       common.Address
+		AllowFailure bool
+		CallData     []byte
+	}
+	data := encodeKnownCall(t, "aggregate3((address,bool,bytes)[])",
+		[]call3{{Target: common.HexToAddress(OPTokenAddress), CallData: []byte{0xaa}}})
+
+	words, err := AnnotateCalldata(Multicall3Address, OPMainnetChainID, data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectLabels(t, words,
+		"calls[0] ((address,bool,bytes)): offset",
+		"calls[0].target (address) = "+OPTokenAddress,
+		"calls[0].allowFailure (bool) = false",
+		"calls[0].callData: length = 1 bytes",
+	)
+}
+
+func TestAnnotateCalldataUsesTargetFunctions(t *testing.T) {
+	// cancel(uint256) names its argument after the contract it is called on
+	data := encodeKnownCall(t, "cancel(uint256)", big.NewInt(42))
+
+	words, err := AnnotateCalldata(CompoundGovernorBravo, MainnetChainID, data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectLabels(t, words, "proposalId (uint256) = 42")
+	if _, err := AnnotateCalldata(OPGrants1, MainnetChainID, data); err == nil {
+		t.Fatal("expected error for a selector the target does not have")
+	}
+}
+
+func TestAnnotateCalldataFlagsUnreferencedWords(t *testing.T) {
+	words, err := AnnotateCalldata(OPTokenAddress, OPMainnetChainID, grantsTransferTx().Data+strings.Repeat("ff", 32))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if last := words[len(words)-1]; !strings.Contains(last.Label, "unreferenced") {
+		t.Errorf("expected trailing word to be unreferenced, got %q", last.Label)
+	}
+}
+
+func TestAnnotateCalldataRejectsBadOffsets(t *testing.T) {
+	// bytes argument whose offset points past the end of the calldata
+	data := "0x8d80ff0a" + strings.Repeat("0", 62) + "ff"
+	if _, err := AnnotateCalldata(SafeMultisendAddress, OPMainnetChainID, data); err == nil {
+		t.Fatal("expected error for out-of-range offset")
+	}
+	if _, err := AnnotateCalldata(SafeMultisendAddress, OPMainnetChainID, "0xdeadbeef"); err == nil {
+		t.Fatal("expected error for unknown selector")
+	}
+}
@@ -0,0 +1,271 @@
+package core
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ArgumentAnnotations are explanations of decoded argument values that a facilitator ships with
+// a transaction for review, keyed by argument path. A path names an argument of the root call,
+// such as "amount", or of a subcall when prefixed with its index, such as "2:amount" or
+// "1.3:request.data.recipient". Tuple fields are separated by dots and array elements are
+// indexed, as in "calls[0].target".
+type ArgumentAnnotations struct {
+	Entries map[string]string
+}
+
+// argumentAnnotationsManifest is the JSON form of an annotations file
+type argumentAnnotationsManifest struct {
+	Annotations map[string]string `json:"annotations"`
+}
+
+// NewArgumentAnnotations creates an empty set of annotations
+func NewArgumentAnnotations() *ArgumentAnnotations {
+	return &ArgumentAnnotations{Entries: map[string]string{}}
+}
+
+// ParseArgumentAnnotations adds the annotations in data. Two formats are accepted: a JSON
+// manifest ({"annotations": {"<path>": "<explanation>"}}) or plain text with one
+// "<path> = <explanation>" per line, where lines starting with "#" are comments.
+func (a *ArgumentAnnotations) ParseArgumentAnnotations(source string, data []byte) error {
+	if trimmed := strings.TrimSpace(string(data)); strings.HasPrefix(trimmed, "{") {
+		var manifest argumentAnnotationsManifest
+		if err := json.Unmarshal(data, &manifest); err != nil {
+			return fmt.Errorf("invalid annotations file %s: %w", source, err)
+		}
+		for path, note := range manifest.Annotations {
+			if err := a.add(source, path, note); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(string(data)))
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		path, note, ok := strings.Cut(text, "=")
+		if !ok {
+			return fmt.Errorf("%s:%d: expected \"<path> = <explanation>\"", source, line)
+		}
+		if err := a.add(fmt.Sprintf("%s:%d", source, line), strings.TrimSpace(path), strings.TrimSpace(note)); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// add validates and inserts an annotation
+func (a *ArgumentAnnotations) add(source, path, note string) error {
+	_, argument := splitAnnotationPath(path)
+	if _, err := parseArgumentPath(argument); err != nil {
+		return fmt.Errorf("%s: invalid argument path %q: %w", source, path, err)
+	}
+	if note == "" {
+		return fmt.Errorf("%s: annotation for %q has no explanation", source, path)
+	}
+	if existing, ok := a.Entries[path]; ok && existing != note {
+		return fmt.Errorf("%s: %q is annotated twice with different explanations", source, path)
+	}
+	a.Entries[path] = note
+	return nil
+}
+
+// LoadArgumentAnnotationsFile adds the annotations of a local annotations file
+func (a *ArgumentAnnotations) LoadArgumentAnnotationsFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read annotations file: %w", err)
+	}
+	return a.ParseArgumentAnnotations(path, data)
+}
+
+// splitAnnotationPath splits an annotation path into the subcall index and the argument path
+func splitAnnotationPath(path string) (index, argument string) {
+	if index, argument, ok := strings.Cut(path, ":"); ok {
+		return strings.TrimPrefix(strings.TrimSpace(index), "#"), strings.TrimSpace(argument)
+	}
+	return "", strings.TrimSpace(path)
+}
+
+// argumentPathSegment is a tuple field name or, when Name is empty, an array index
+type argumentPathSegment struct {
+	Name  string
+	Index int
+}
+
+// parseArgumentPath splits an argument path like "calls[0].target" into segments
+func parseArgumentPath(path string) ([]argumentPathSegment, error) {
+	if path == "" {
+		return nil, fmt.Errorf("empty path")
+	}
+	var segments []argumentPathSegment
+	for _, part := range strings.Split(path, ".") {
+		name, rest, _ := strings.Cut(part, "[")
+		if name == "" {
+			return nil, fmt.Errorf("empty field name")
+		}
+		segments = append(segments, argumentPathSegment{Name: name})
+		for rest != "" {
+			digits, after, ok := strings.Cut(rest, "]")
+			index, err := strconv.Atoi(digits)
+			if !ok || err != nil || index < 0 {
+				return nil, fmt.Errorf("invalid array index in %q", part)
+			}
+			segments = append(segments, argumentPathSegment{Index: index})
+			if after != "" && !strings.HasPrefix(after, "[") {
+				return nil, fmt.Errorf("unexpected %q after an array index", after)
+			}
+			rest = strings.TrimPrefix(after, "[")
+		}
+	}
+	return segments, nil
+}
+
+// resolveArgumentPath finds the value an argument path names among the decoded arguments of a
+// call. Names match regardless of case. It returns the path with the names as decoded, which is
+// how the terminal output looks annotations up.
+func resolveArgumentPath(args []Argument, path string) (string, bool) {
+	segments, err := parseArgumentPath(path)
+	if err != nil {
+		return "", false
+	}
+
+	var resolved strings.Builder
+	var value reflect.Value
+	for i, segment := range segments {
+		if i == 0 {
+			arg, ok := lookupArgument(args, segment.Name)
+			if !ok {
+				return "", false
+			}
+			resolved.WriteString(arg.Name)
+			value = reflect.ValueOf(arg.Value)
+			continue
+		}
+		for value.IsValid() && (value.Kind() == reflect.Interface || value.Kind() == reflect.Pointer) {
+			value = value.Elem()
+		}
+		if !value.IsValid() {
+			return "", false
+		}
+
+		switch {
+		case segment.Name == "":
+			// Byte strings are shown as hex, so their bytes are not annotated one by one
+			if (value.Kind() != reflect.Slice && value.Kind() != reflect.Array) || value.Type().Elem().Kind() == reflect.Uint8 || segment.Index >= value.Len() {
+				return "", false
+			}
+			fmt.Fprintf(&resolved, "[%d]", segment.Index)
+			value = value.Index(segment.Index)
+		case value.Kind() == reflect.Struct:
+			field, ok := tupleField(value.Type(), segment.Name)
+			if !ok {
+				return "", false
+			}
+			resolved.WriteString("." + TupleFieldName(field))
+			value = value.FieldByIndex(field.Index)
+		case value.Kind() == reflect.Map && value.Type().Key().Kind() == reflect.String:
+			key, ok := lookupKey(value, segment.Name)
+			if !ok {
+				return "", false
+			}
+			resolved.WriteString("." + key)
+			value = value.MapIndex(reflect.ValueOf(key).Convert(value.Type().Key()))
+		default:
+			return "", false
+		}
+	}
+	return resolved.String(), true
+}
+
+// lookupArgument finds an argument by name, preferring an exact match over one that differs in case
+func lookupArgument(args []Argument, name string) (Argument, bool) {
+	for _, arg := range args {
+		if arg.Name == name {
+			return arg, true
+		}
+	}
+	for _, arg := range args {
+		if strings.EqualFold(arg.Name, name) {
+			return arg, true
+		}
+	}
+	return Argument{}, false
+}
+
+// lookupKey finds a string map key, preferring an exact match over one that differs in case
+func lookupKey(m reflect.Value, name string) (string, bool) {
+	if m.MapIndex(reflect.ValueOf(name).Convert(m.Type().Key())).IsValid() {
+		return name, true
+	}
+	for _, key := range m.MapKeys() {
+		if strings.EqualFold(key.String(), name) {
+			return key.String(), true
+		}
+	}
+	return "", false
+}
+
+// annotateArguments attaches the annotations to the decoded arguments of the calls they name,
+// in the transaction and in an approved child transaction. An annotation that names no decoded
+// argument is a warning, since its explanation is then shown nowhere.
+func annotateArguments(result *VerificationResult, annotations *ArgumentAnnotations) []Warning {
+	if annotations == nil || len(annotations.Entries) == 0 {
+		return nil
+	}
+
+	paths := make([]string, 0, len(annotations.Entries))
+	for path := range annotations.Entries {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	var warnings []Warning
+	for _, path := range paths {
+		index, argument := splitAnnotationPath(path)
+		matched := false
+		for _, r := range []*VerificationResult{result, result.NestedResult} {
+			if r == nil {
+				continue
+			}
+			call := findCall(&r.Call, index)
+			if call == nil {
+				continue
+			}
+			if resolved, ok := resolveArgumentPath(call.ParsedData, argument); ok {
+				if call.Annotations == nil {
+					call.Annotations = map[string]string{}
+				}
+				call.Annotations[resolved] = annotations.Entries[path]
+				matched = true
+			}
+		}
+		if !matched {
+			warnings = append(warnings, newWarning(warnUnmatchedAnnotation, "The annotation for %q names no decoded argument of this transaction, so its explanation is not shown: %q", path, annotations.Entries[path]))
+		}
+	}
+	return warnings
+}
+
+// findCall returns the call with a subcall index, or the root call for an empty index
+func findCall(call *CallData, index string) *CallData {
+	if call.Index == index {
+		return call
+	}
+	for i := range call.SubCalls {
+		if found := findCall(&call.SubCalls[i], index); found != nil {
+			return found
+		}
+	}
+	return nil
+}
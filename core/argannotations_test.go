@@ -0,0 +1,87 @@
+package core
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+)
+
+func TestParseArgumentAnnotations(t *testing.T) {
+	annotations := NewArgumentAnnotations()
+	text := "# Q3 grants\namount = Q3 grant tranche per proposal #112\n2:calls[0].target = the grants multisig\n"
+	if err := annotations.ParseArgumentAnnotations("notes.txt", []byte(text)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := annotations.ParseArgumentAnnotations("notes.json", []byte(`{"annotations": {"#1.2:request.data.recipient": "the attester"}}`)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(annotations.Entries) != 3 || annotations.Entries["amount"] != "Q3 grant tranche per proposal #112" {
+		t.Fatalf("unexpected annotations: %v", annotations.Entries)
+	}
+
+	for _, bad := range []string{"amount Q3 grant", "amount =", "calls[x] = bad index", "amount = a different explanation"} {
+		if err := annotations.ParseArgumentAnnotations("bad.txt", []byte(bad)); err == nil {
+			t.Errorf("expected an error for %q", bad)
+		}
+	}
+}
+
+func TestAnnotateArguments(t *testing.T) {
+	tx := multiSendTx(t,
+		erc20Transfer(airdropToken, airdropAlice, big.NewInt(100)),
+		erc20Transfer(airdropToken, airdropBob, big.NewInt(200)))
+	tx.Safe, tx.SafeVersion = effectsSafe, "1.3.0"
+
+	annotations := NewArgumentAnnotations()
+	annotations.Entries["2:AMOUNT"] = "second tranche"
+	annotations.Entries["1:to"] = "Alice's grant wallet"
+	annotations.Entries["3:amount"] = "a subcall that does not exist"
+
+	result, err := VerifyTransaction(tx, VerifyOptions{Annotations: annotations})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	subcalls := result.Call.SubCalls
+	if len(subcalls) != 2 {
+		t.Fatalf("expected 2 subcalls, got %d", len(subcalls))
+	}
+	if subcalls[0].Annotations["to"] != "Alice's grant wallet" || subcalls[1].Annotations["amount"] != "second tranche" {
+		t.Errorf("unexpected annotations: %v, %v", subcalls[0].Annotations, subcalls[1].Annotations)
+	}
+
+	unmatched := 0
+	for _, warning := range result.Warnings {
+		if strings.Contains(warning.Message, `"3:amount"`) {
+			unmatched++
+		}
+	}
+	if unmatched != 1 {
+		t.Errorf("expected a warning for the unmatched annotation, got %v", result.Warnings)
+	}
+}
+
+func TestResolveArgumentPath(t *testing.T) {
+	type data struct {
+		Recipient string
+		Value     *big.Int
+	}
+	args := []Argument{
+		{Name: "request", Value: struct{ Data data }{Data: data{Recipient: airdropAlice}}},
+		{Name: "calls", Value: []data{{Recipient: airdropBob}}},
+		{Name: "payload", Value: []byte{1, 2}},
+	}
+	for _, tc := range []struct{ path, want string }{
+		{"request.data.recipient", "request.Data.Recipient"},
+		{"calls[0].value", "calls[0].Value"},
+		{"calls", "calls"},
+	} {
+		if got, ok := resolveArgumentPath(args, tc.path); !ok || got != tc.want {
+			t.Errorf("resolveArgumentPath(%q) = %q, %v, want %q", tc.path, got, ok, tc.want)
+		}
+	}
+	for _, path := range []string{"calls[1]", "request.missing", "payload[0]", "missing"} {
+		if got, ok := resolveArgumentPath(args, path); ok {
+			t.Errorf("resolveArgumentPath(%q) = %q, want no match", path, got)
+		}
+	}
+}
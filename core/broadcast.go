@@ -0,0 +1,261 @@
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// forgeBroadcast is a broadcast artifact written by forge script (broadcast/<script>/<chain>/run-*.json)
+type forgeBroadcast struct {
+	Transactions []forgeBroadcastTransaction `json:"transactions"`
+	Chain        uint64                      `json:"chain"`
+}
+
+// forgeBroadcastTransaction is a call recorded in a forge broadcast artifact
+type forgeBroadcastTransaction struct {
+	Transaction struct {
+		From    string `json:"from"`
+		To      string `json:"to"`
+		Value   string `json:"value"`
+		Input   string `json:"input"`
+		Data    string `json:"data"`
+		ChainID string `json:"chainId"`
+	} `json:"transaction"`
+}
+
+var (
+	execTransactionSelector = crypto.Keccak256([]byte("execTransaction(address,uint256,bytes,uint8,uint256,uint256,uint256,address,address,bytes)"))[:4]
+	approveHashSelector     = crypto.Keccak256([]byte("approveHash(bytes32)"))[:4]
+
+	execTransactionArguments = abi.Arguments{
+		{Name: "to", Type: mustABIType("address")},
+		{Name: "value", Type: mustABIType("uint256")},
+		{Name: "data", Type: mustABIType("bytes")},
+		{Name: "operation", Type: mustABIType("uint8")},
+		{Name: "safeTxGas", Type: mustABIType("uint256")},
+		{Name: "baseGas", Type: mustABIType("uint256")},
+		{Name: "gasPrice", Type: mustABIType("uint256")},
+		{Name: "gasToken", Type: mustABIType("address")},
+		{Name: "refundReceiver", Type: mustABIType("address")},
+		{Name: "signatures", Type: mustABIType("bytes")},
+	}
+)
+
+// forgeBatchMultisends are the MultiSendCallOnly deployments a batch of recorded calls is
+// rebuilt against, in order of preference, where the chain trusts them
+var forgeBatchMultisends = []string{SafeMultisendCallOnly141, SafeMultisendCallOnly130, ZkSyncSafeMultisendCallOnly130}
+
+// IsForgeBroadcast reports whether a transaction file is a forge script broadcast artifact
+func IsForgeBroadcast(data []byte) bool {
+	var fields map[string]json.RawMessage
+	if json.Unmarshal(data, &fields) != nil {
+		return false
+	}
+	_, hasTransactions := fields["transactions"]
+	_, hasReceipts := fields["receipts"]
+	return hasTransactions && hasReceipts
+}
+
+// ParseForgeBroadcast reconstructs the Safe transaction recorded in a forge script broadcast
+// artifact. A recorded execTransaction call gives the Safe transaction directly. Otherwise the
+// recorded calls are taken to be made by the Safe itself, as when a script broadcasts as the
+// Safe, and are batched through MultiSendCallOnly when there is more than one. The artifact does
+// not record the Safe nonce or version, so the nonce is given and the version is left unset. Like
+// ParseSafeTransaction, it returns a description of every assumption made to read the artifact.
+func ParseForgeBroadcast(data []byte, nonce int) (SafeTransaction, []string, error) {
+	var broadcast forgeBroadcast
+	if err := json.Unmarshal(data, &broadcast); err != nil {
+		return SafeTransaction{}, nil, fmt.Errorf("invalid forge broadcast artifact: %w", err)
+	}
+	if len(broadcast.Transactions) == 0 {
+		return SafeTransaction{}, nil, fmt.Errorf("the forge broadcast artifact records no transactions")
+	}
+
+	calls := make([]multiSendTransaction, 0, len(broadcast.Transactions))
+	var execIndex []int
+	approvals := 0
+	for i, recorded := range broadcast.Transactions {
+		call, err := recorded.call(i)
+		if err != nil {
+			return SafeTransaction{}, nil, err
+		}
+		switch {
+		case len(call.Data) >= 4 && bytes.Equal(call.Data[:4], execTransactionSelector):
+			execIndex = append(execIndex, i)
+		case len(call.Data) >= 4 && bytes.Equal(call.Data[:4], approveHashSelector):
+			approvals++
+		}
+		calls = append(calls, call)
+	}
+
+	chainID, err := broadcast.chainID()
+	if err != nil {
+		return SafeTransaction{}, nil, err
+	}
+	tx := SafeTransaction{
+		Chain:          int(chainID),
+		GasToken:       ZeroAddress,
+		RefundReceiver: ZeroAddress,
+		Nonce:          nonce,
+	}
+	notes := []string{"read a forge script broadcast artifact, which does not record the Safe nonce or version"}
+
+	switch {
+	case len(execIndex) > 1:
+		return SafeTransaction{}, nil, fmt.Errorf("the forge broadcast artifact records %d execTransaction calls; verify one Safe transaction at a time", len(execIndex))
+	case len(execIndex) == 1:
+		i := execIndex[0]
+		if err := tx.fillFromExecTransaction(calls[i]); err != nil {
+			return SafeTransaction{}, nil, fmt.Errorf("transaction %d: %w", i+1, err)
+		}
+		notes = append(notes, fmt.Sprintf("read the Safe transaction from the execTransaction call to %s (transaction %d)", tx.Safe, i+1))
+		if ignored := len(calls) - 1; ignored > 0 {
+			notes = append(notes, fmt.Sprintf("ignored the %d other recorded calls", ignored))
+		}
+	case approvals == len(calls):
+		return SafeTransaction{}, nil, fmt.Errorf("the forge broadcast artifact only records approveHash calls, which do not contain the approved transaction")
+	default:
+		if err := tx.fillFromSafeCalls(broadcast.Transactions, calls, chainID); err != nil {
+			return SafeTransaction{}, nil, err
+		}
+		if len(calls) == 1 {
+			notes = append(notes, fmt.Sprintf("read the recorded call from %s as the Safe transaction", tx.Safe))
+		} else {
+			notes = append(notes, fmt.Sprintf("batched the %d recorded calls from %s through MultiSendCallOnly at %s", len(calls), tx.Safe, tx.To))
+		}
+	}
+	return tx, notes, nil
+}
+
+// call returns the recorded call as a batch entry
+func (t forgeBroadcastTransaction) call(index int) (multiSendTransaction, error) {
+	if t.Transaction.To == "" {
+		return multiSendTransaction{}, fmt.Errorf("transaction %d creates a contract, which a Safe transaction cannot do", index+1)
+	}
+	if !common.IsHexAddress(t.Transaction.To) {
+		return multiSendTransaction{}, fmt.Errorf("transaction %d has an invalid to address %q", index+1, t.Transaction.To)
+	}
+	input := t.Transaction.Input
+	if input == "" {
+		// Older forge versions record the calldata as "data"
+		input = t.Transaction.Data
+	}
+	data, err := decodeHexDigits(input)
+	if err != nil {
+		return multiSendTransaction{}, fmt.Errorf("transaction %d has invalid calldata: %w", index+1, err)
+	}
+	value := new(big.Int)
+	if t.Transaction.Value != "" {
+		if value, err = parseIntegerString(t.Transaction.Value); err != nil {
+			return multiSendTransaction{}, fmt.Errorf("transaction %d has an invalid value %q: %w", index+1, t.Transaction.Value, err)
+		}
+	}
+	return multiSendTransaction{To: common.HexToAddress(t.Transaction.To), Value: value, Data: data}, nil
+}
+
+// chainID returns the chain of the artifact, checking that every recorded call agrees with it
+func (b forgeBroadcast) chainID() (uint64, error) {
+	chainID := b.Chain
+	for i, recorded := range b.Transactions {
+		if recorded.Transaction.ChainID == "" {
+			continue
+		}
+		n, err := parseIntegerString(recorded.Transaction.ChainID)
+		if err != nil || !n.IsUint64() {
+			return 0, fmt.Errorf("transaction %d has an invalid chainId %q", i+1, recorded.Transaction.ChainID)
+		}
+		switch {
+		case chainID == 0:
+			chainID = n.Uint64()
+		case chainID != n.Uint64():
+			return 0, fmt.Errorf("transaction %d is for chain %d, not chain %d", i+1, n.Uint64(), chainID)
+		}
+	}
+	if chainID == 0 {
+		return 0, fmt.Errorf("the forge broadcast artifact does not record its chain")
+	}
+	return chainID, nil
+}
+
+// fillFromExecTransaction sets the Safe and the hashed fields from a recorded execTransaction call
+func (tx *SafeTransaction) fillFromExecTransaction(call multiSendTransaction) error {
+	args, err := execTransactionArguments.Unpack(call.Data[4:])
+	if err != nil {
+		return fmt.Errorf("invalid execTransaction calldata: %w", err)
+	}
+	gas := make([]int, 3)
+	for i, arg := range args[4:7] {
+		n := arg.(*big.Int)
+		if !n.IsInt64() || n.Int64() > int64(^uint32(0)) {
+			return fmt.Errorf("execTransaction %s %s is out of range", execTransactionArguments[4+i].Name, n)
+		}
+		gas[i] = int(n.Int64())
+	}
+
+	tx.Safe = call.To.Hex()
+	tx.To = args[0].(common.Address).Hex()
+	tx.Value = args[1].(*big.Int)
+	tx.Data = hexBytes(args[2].([]byte))
+	tx.Operation = int(args[3].(uint8))
+	tx.SafeTxGas, tx.BaseGas, tx.GasPrice = gas[0], gas[1], gas[2]
+	tx.GasToken = args[7].(common.Address).Hex()
+	tx.RefundReceiver = args[8].(common.Address).Hex()
+	return nil
+}
+
+// fillFromSafeCalls sets the Safe and the hashed fields from calls recorded as made by the Safe
+func (tx *SafeTransaction) fillFromSafeCalls(recorded []forgeBroadcastTransaction, calls []multiSendTransaction, chainID uint64) error {
+	for i, t := range recorded {
+		from := t.Transaction.From
+		if !common.IsHexAddress(from) {
+			return fmt.Errorf("transaction %d has an invalid from address %q", i+1, from)
+		}
+		if i > 0 && !strings.EqualFold(from, recorded[0].Transaction.From) {
+			return fmt.Errorf("transaction %d is sent from %s, not %s; a Safe transaction makes every call from the Safe", i+1, from, recorded[0].Transaction.From)
+		}
+	}
+	tx.Safe = common.HexToAddress(recorded[0].Transaction.From).Hex()
+
+	if len(calls) == 1 {
+		tx.To = calls[0].To.Hex()
+		tx.Value = calls[0].Value
+		tx.Data = hexBytes(calls[0].Data)
+		return nil
+	}
+
+	multisend := ""
+	for _, address := range forgeBatchMultisends {
+		if MulticallAddresses[chainID][strings.ToLower(address)] {
+			multisend = address
+			break
+		}
+	}
+	if multisend == "" {
+		return withKind(ErrUnsupportedChain, fmt.Errorf("no trusted MultiSendCallOnly deployment is known on chain %d to batch the %d recorded calls", chainID, len(calls)))
+	}
+
+	var packed []byte
+	for _, call := range calls {
+		packed = append(packed, 0)
+		packed = append(packed, call.To.Bytes()...)
+		packed = append(packed, common.LeftPadBytes(call.Value.Bytes(), 32)...)
+		packed = append(packed, common.LeftPadBytes(big.NewInt(int64(len(call.Data))).Bytes(), 32)...)
+		packed = append(packed, call.Data...)
+	}
+	encoded, err := abi.Arguments{{Type: mustABIType("bytes")}}.Pack(packed)
+	if err != nil {
+		return err
+	}
+	tx.To = multisend
+	tx.Value = new(big.Int)
+	tx.Data = hexBytes(append(append([]byte{}, multiSendSelector...), encoded...))
+	tx.Operation = 1
+	return nil
+}
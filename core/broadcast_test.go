@@ -0,0 +1,116 @@
+package core
+
+import (
+	"encoding/json"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// forgeBroadcastJSON is a forge broadcast artifact recording the given calls on OP Mainnet
+func forgeBroadcastJSON(t *testing.T, calls ...map[string]string) []byte {
+	t.Helper()
+	transactions := make([]map[string]interface{}, 0, len(calls))
+	for _, call := range calls {
+		transaction := map[string]string{"value": "0x0", "chainId": "0xa"}
+		for k, v := range call {
+			transaction[k] = v
+		}
+		transactions = append(transactions, map[string]interface{}{
+			"hash": nil, "transactionType": "CALL", "contractName": nil, "transaction": transaction,
+		})
+	}
+	data, err := json.Marshal(map[string]interface{}{
+		"transactions": transactions, "receipts": []interface{}{}, "libraries": []interface{}{},
+		"pending": []interface{}{}, "chain": 10, "commit": "abc1234",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestParseForgeBroadcastExecTransaction(t *testing.T) {
+	calldata, err := execTransactionArguments.Pack(
+		common.HexToAddress(airdropAlice), big.NewInt(5), []byte{0xde, 0xad}, uint8(0),
+		big.NewInt(0), big.NewInt(0), big.NewInt(0), common.Address{}, common.Address{}, []byte{1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := forgeBroadcastJSON(t,
+		map[string]string{"from": airdropBob, "to": effectsSafe, "input": hexBytes(append(append([]byte{}, execTransactionSelector...), calldata...))})
+
+	tx, notes, err := ParseForgeBroadcast(data, 7)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if tx.Safe != effectsSafe || tx.Chain != 10 || tx.Nonce != 7 || tx.To != airdropAlice || tx.Value.Int64() != 5 || tx.Data != "0xdead" || tx.Operation != 0 {
+		t.Fatalf("unexpected transaction: %+v", tx)
+	}
+	if !strings.Contains(strings.Join(notes, "\n"), "execTransaction") {
+		t.Errorf("the execTransaction call is not noted: %v", notes)
+	}
+}
+
+func TestParseForgeBroadcastSafeCalls(t *testing.T) {
+	transfer := hexBytes(erc20Transfer(airdropToken, airdropAlice, big.NewInt(100)).Data)
+	data := forgeBroadcastJSON(t,
+		map[string]string{"from": effectsSafe, "to": airdropToken, "input": transfer},
+		map[string]string{"from": strings.ToLower(effectsSafe), "to": airdropBob, "value": "0x3", "input": "0x"})
+
+	tx, _, err := ParseForgeBroadcast(data, 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if tx.Safe != effectsSafe || tx.To != SafeMultisendCallOnly141 || tx.Operation != 1 {
+		t.Fatalf("unexpected transaction: %+v", tx)
+	}
+	calls, err := batchCalls(tx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(calls) != 2 || calls[0].To.Hex() != airdropToken || hexBytes(calls[0].Data) != transfer ||
+		calls[1].To.Hex() != airdropBob || calls[1].Value.Int64() != 3 || len(calls[1].Data) != 0 {
+		t.Fatalf("unexpected batch: %+v", calls)
+	}
+
+	// A single call is the Safe transaction itself
+	tx, _, err = ParseForgeBroadcast(forgeBroadcastJSON(t, map[string]string{"from": effectsSafe, "to": airdropToken, "data": transfer}), 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if tx.To != airdropToken || tx.Data != transfer || tx.Operation != 0 {
+		t.Fatalf("unexpected transaction: %+v", tx)
+	}
+}
+
+func TestParseForgeBroadcastErrors(t *testing.T) {
+	approve := hexBytes(append(append([]byte{}, approveHashSelector...), make([]byte, 32)...))
+	for _, tc := range []struct {
+		name string
+		data []byte
+		want string
+	}{
+		{"different senders", forgeBroadcastJSON(t,
+			map[string]string{"from": effectsSafe, "to": airdropToken, "input": "0x"},
+			map[string]string{"from": airdropBob, "to": airdropToken, "input": "0x"}), "is sent from"},
+		{"creation", forgeBroadcastJSON(t, map[string]string{"from": effectsSafe, "input": "0x6080"}), "creates a contract"},
+		{"approvals only", forgeBroadcastJSON(t, map[string]string{"from": airdropBob, "to": effectsSafe, "input": approve}), "approveHash"},
+		{"chain mismatch", forgeBroadcastJSON(t, map[string]string{"from": effectsSafe, "to": airdropToken, "input": "0x", "chainId": "0x1"}), "chain 1"},
+	} {
+		if _, _, err := ParseForgeBroadcast(tc.data, 0); err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%s: expected an error containing %q, got %v", tc.name, tc.want, err)
+		}
+	}
+
+	// Transaction files are not read as broadcasts, and broadcasts are not read as transaction files
+	data := forgeBroadcastJSON(t, map[string]string{"from": effectsSafe, "to": airdropToken, "input": "0x"})
+	if !IsForgeBroadcast(data) || IsForgeBroadcast([]byte(`{"safe": "`+effectsSafe+`", "to": "`+airdropToken+`"}`)) {
+		t.Error("forge broadcast artifacts are not recognized")
+	}
+	if _, _, err := ParseSafeTransaction(data); err == nil || !strings.Contains(err.Error(), "forge broadcast") {
+		t.Errorf("expected a forge broadcast error, got %v", err)
+	}
+}
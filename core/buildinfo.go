@@ -0,0 +1,160 @@
+package core
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"runtime/debug"
+	"sort"
+	"strings"
+)
+
+// BuildModule is a Go module compiled into the binary
+type BuildModule struct {
+	Path    string `json:"path"`
+	Version string `json:"version"`
+	Sum     string `json:"sum,omitempty"`
+	Replace string `json:"replace,omitempty"`
+}
+
+// BuildProvenance describes how the running binary was built, so that signers can check the
+// verifier itself before trusting what it tells them
+type BuildProvenance struct {
+	Version   string            `json:"version"`
+	Commit    string            `json:"commit"`
+	GoVersion string            `json:"goVersion"`
+	Module    BuildModule       `json:"module"`
+	Settings  map[string]string `json:"settings"`
+	Deps      []BuildModule     `json:"deps"`
+
+	// InputsDigest is a SHA-256 over the build inputs recorded in the binary. Two binaries with the
+	// same digest were built from the same source, dependencies, toolchain, and flags.
+	InputsDigest string `json:"inputsDigest"`
+
+	// ExecutableDigest is the SHA-256 of the running executable, comparable with the release
+	// SHA256SUMS file or with a local reproducible build
+	ExecutableDigest string `json:"executableDigest,omitempty"`
+
+	// Warnings explain anything about the build that makes it harder to trust or reproduce
+	Warnings []Warning `json:"warnings,omitempty"`
+}
+
+// reproducibleSettings are the build settings that must hold for a bit-for-bit reproducible build
+var reproducibleSettings = map[string]string{
+	"-trimpath":   "true",
+	"CGO_ENABLED": "0",
+}
+
+// ReadBuildProvenance returns the provenance of the running binary. version and commit are the
+// values stamped in with -ldflags by the release build.
+func ReadBuildProvenance(version, commit string) (*BuildProvenance, error) {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return nil, fmt.Errorf("binary was built without module support; no build information is embedded")
+	}
+	provenance := NewBuildProvenance(info, version, commit)
+
+	if path, err := os.Executable(); err == nil {
+		if digest, err := fileDigest(path); err == nil {
+			provenance.ExecutableDigest = digest
+		}
+	}
+	return provenance, nil
+}
+
+// NewBuildProvenance summarizes embedded build information and checks it for anything that would
+// prevent the build from being reproduced
+func NewBuildProvenance(info *debug.BuildInfo, version, commit string) *BuildProvenance {
+	provenance := &BuildProvenance{
+		Version:   version,
+		Commit:    commit,
+		GoVersion: info.GoVersion,
+		Module:    buildModule(&info.Main),
+		Settings:  map[string]string{},
+	}
+	for _, setting := range info.Settings {
+		provenance.Settings[setting.Key] = setting.Value
+	}
+	for _, dep := range info.Deps {
+		provenance.Deps = append(provenance.Deps, buildModule(dep))
+	}
+	sort.Slice(provenance.Deps, func(i, j int) bool { return provenance.Deps[i].Path < provenance.Deps[j].Path })
+
+	provenance.InputsDigest = buildInputsDigest(provenance)
+
+	settings := make([]string, 0, len(reproducibleSettings))
+	for key := range reproducibleSettings {
+		settings = append(settings, key)
+	}
+	sort.Strings(settings)
+	for _, key := range settings {
+		if provenance.Settings[key] != reproducibleSettings[key] {
+			provenance.Warnings = append(provenance.Warnings, newWarning(warnUnreproducibleBuild,
+				"Build setting %s is %q, not %q; this binary cannot be reproduced bit for bit.", key, provenance.Settings[key], reproducibleSettings[key]))
+		}
+	}
+	if provenance.Settings["vcs.modified"] == "true" {
+		provenance.Warnings = append(provenance.Warnings, newWarning(warnDirtyBuild,
+			"Built from a working tree with uncommitted changes; the source does not match any commit."))
+	}
+	revision := provenance.Settings["vcs.revision"]
+	if revision == "" {
+		provenance.Warnings = append(provenance.Warnings, newWarning(warnNoRevision,
+			"No VCS revision is embedded; the source commit cannot be checked."))
+	} else if commit != "" && commit != "unknown" && !strings.HasPrefix(revision, commit) {
+		provenance.Warnings = append(provenance.Warnings, newWarning(warnCommitMismatch,
+			"Stamped commit %s does not match the embedded VCS revision %s. Do not trust this binary.", commit, revision))
+	}
+
+	return provenance
+}
+
+// buildModule converts a debug.Module, following replacements
+func buildModule(m *debug.Module) BuildModule {
+	module := BuildModule{Path: m.Path, Version: m.Version, Sum: m.Sum}
+	if m.Replace != nil {
+		module.Replace = m.Replace.Path + "@" + m.Replace.Version
+		if m.Replace.Sum != "" {
+			module.Sum = m.Replace.Sum
+		}
+	}
+	return module
+}
+
+// buildInputsDigest hashes a canonical, line-based rendering of the build inputs
+func buildInputsDigest(provenance *BuildProvenance) string {
+	var lines []string
+	lines = append(lines, "go "+provenance.GoVersion)
+	lines = append(lines, fmt.Sprintf("mod %s %s %s", provenance.Module.Path, provenance.Module.Version, provenance.Module.Sum))
+	for _, dep := range provenance.Deps {
+		lines = append(lines, fmt.Sprintf("dep %s %s %s %s", dep.Path, dep.Version, dep.Sum, dep.Replace))
+	}
+	keys := make([]string, 0, len(provenance.Settings))
+	for key := range provenance.Settings {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		lines = append(lines, fmt.Sprintf("build %s=%s", key, provenance.Settings[key]))
+	}
+
+	sum := sha256.Sum256([]byte(strings.Join(lines, "\n") + "\n"))
+	return hex.EncodeToString(sum[:])
+}
+
+// fileDigest returns the hex SHA-256 of a file
+func fileDigest(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
@@ -0,0 +1,67 @@
+package core
+
+import (
+	"runtime/debug"
+	"testing"
+)
+
+func reproducibleBuildInfo() *debug.BuildInfo {
+	return &debug.BuildInfo{
+		GoVersion: "go1.23.7",
+		Main:      debug.Module{Path: "github.com/ethereum-optimism/op-txverify", Version: "v1.2.0"},
+		Deps: []*debug.Module{
+			{Path: "github.com/urfave/cli/v2", Version: "v2.27.5", Sum: "h1:cli"},
+			{Path: "github.com/ethereum/go-ethereum", Version: "v1.15.5", Sum: "h1:geth"},
+		},
+		Settings: []debug.BuildSetting{
+			{Key: "-trimpath", Value: "true"},
+			{Key: "CGO_ENABLED", Value: "0"},
+			{Key: "vcs.revision", Value: "182433e0f1a2b3c4d5e6f708192a3b4c5d6e7f80"},
+			{Key: "vcs.modified", Value: "false"},
+		},
+	}
+}
+
+func TestNewBuildProvenanceReproducible(t *testing.T) {
+	provenance := NewBuildProvenance(reproducibleBuildInfo(), "v1.2.0", "182433e0")
+	if len(provenance.Warnings) != 0 {
+		t.Fatalf("expected no warnings, got %+v", provenance.Warnings)
+	}
+	if provenance.Deps[0].Path != "github.com/ethereum/go-ethereum" {
+		t.Errorf("expected deps sorted by path, got %+v", provenance.Deps)
+	}
+	if len(provenance.InputsDigest) != 64 {
+		t.Errorf("unexpected inputs digest %q", provenance.InputsDigest)
+	}
+
+	// The digest depends only on the inputs, not on the order they were recorded in
+	info := reproducibleBuildInfo()
+	info.Deps[0], info.Deps[1] = info.Deps[1], info.Deps[0]
+	info.Settings[0], info.Settings[3] = info.Settings[3], info.Settings[0]
+	if digest := NewBuildProvenance(info, "v1.2.0", "182433e0").InputsDigest; digest != provenance.InputsDigest {
+		t.Errorf("digest changed with input order: %s != %s", digest, provenance.InputsDigest)
+	}
+
+	info = reproducibleBuildInfo()
+	info.Deps[1].Sum = "h1:other"
+	if digest := NewBuildProvenance(info, "v1.2.0", "182433e0").InputsDigest; digest == provenance.InputsDigest {
+		t.Error("expected the digest to change with a dependency checksum")
+	}
+}
+
+func TestNewBuildProvenanceWarnings(t *testing.T) {
+	info := reproducibleBuildInfo()
+	info.Settings = []debug.BuildSetting{
+		{Key: "CGO_ENABLED", Value: "1"},
+		{Key: "vcs.revision", Value: "182433e0f1a2b3c4d5e6f708192a3b4c5d6e7f80"},
+		{Key: "vcs.modified", Value: "true"},
+	}
+
+	provenance := NewBuildProvenance(info, "dev", "deadbeef")
+	if len(provenance.Warnings) != 4 {
+		t.Fatalf("expected four warnings, got %+v", provenance.Warnings)
+	}
+	if !HasCritical(provenance.Warnings) {
+		t.Error("expected a commit mismatch to be critical")
+	}
+}
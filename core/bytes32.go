@@ -0,0 +1,88 @@
+package core
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// KnownHashes names well-known bytes32 values, such as access control roles and proxy storage
+// slots, keyed by lowercase hex. Roles are added by RegisterRole. Hashes from the registry, such as fault proof prestates, are
+// added by Registry.Apply.
+var KnownHashes = map[string]string{}
+
+func init() {
+	// EIP-1967 slots are the hash of their name minus one
+	for _, slot := range []struct{ name, preimage string }{
+		{"EIP-1967 implementation slot", "eip1967.proxy.implementation"},
+		{"EIP-1967 admin slot", "eip1967.proxy.admin"},
+		{"EIP-1967 beacon slot", "eip1967.proxy.beacon"},
+	} {
+		hash := new(big.Int).SetBytes(crypto.Keccak256([]byte(slot.preimage)))
+		registerKnownHash(common.BigToHash(hash.Sub(hash, big.NewInt(1))), slot.name)
+	}
+}
+
+// registerKnownHash names a bytes32 value unless it already has a name, and reports whether it
+// was added
+func registerKnownHash(hash common.Hash, name string) bool {
+	key := strings.ToLower(hash.Hex())
+	if _, exists := KnownHashes[key]; exists {
+		return false
+	}
+	KnownHashes[key] = name
+	return true
+}
+
+// bytes32Address returns the address a bytes32 value holds when it is a left-padded address.
+// Values whose first four address bytes are zero are more likely small numbers and are not
+// taken as addresses.
+func bytes32Address(value common.Hash) (common.Address, bool) {
+	if !bytes.Equal(value[:12], make([]byte, 12)) || bytes.Equal(value[12:16], make([]byte, 4)) {
+		return common.Address{}, false
+	}
+	return common.BytesToAddress(value[12:]), true
+}
+
+// bytes32Text returns the text a bytes32 value holds when it is printable UTF-8 padded with
+// zero bytes on the right, as Solidity stores short strings
+func bytes32Text(value common.Hash) (string, bool) {
+	text := bytes.TrimRight(value[:], "\x00")
+	if len(text) == 0 || !utf8.Valid(text) {
+		return "", false
+	}
+	for _, r := range string(text) {
+		if !unicode.IsPrint(r) {
+			return "", false
+		}
+	}
+	return string(text), true
+}
+
+// InterpretBytes32 describes what a bytes32 value likely is: zero, a known hash, a left-padded
+// address, or short text. It returns an empty string for a value that looks like any other hash.
+// Addresses are named when they are known on the chain.
+func InterpretBytes32(value common.Hash, chainID uint64) string {
+	if value == (common.Hash{}) {
+		return "zero, which is DEFAULT_ADMIN_ROLE as a role"
+	}
+	if name, ok := KnownHashes[strings.ToLower(value.Hex())]; ok {
+		return name
+	}
+	if address, ok := bytes32Address(value); ok {
+		if entry, known := KnownAddresses.Lookup(address.Hex(), chainID); known {
+			return fmt.Sprintf("address %s, %s", address.Hex(), entry.Name)
+		}
+		return "address " + address.Hex()
+	}
+	if text, ok := bytes32Text(value); ok {
+		return fmt.Sprintf("text %q", text)
+	}
+	return ""
+}
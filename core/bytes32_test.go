@@ -0,0 +1,53 @@
+package core
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestInterpretBytes32(t *testing.T) {
+	var text common.Hash
+	copy(text[:], "op-mainnet")
+
+	tests := []struct {
+		name  string
+		value common.Hash
+		want  string
+	}{
+		{"zero", common.Hash{}, "zero, which is DEFAULT_ADMIN_ROLE as a role"},
+		{"role", crypto.Keccak256Hash([]byte("PAUSER_ROLE")), "PAUSER_ROLE"},
+		{"proxy slot", common.HexToHash("0x360894a13ba1a3210667c828492db98dca3e2076cc3735a920a3ca505d382bbc"), "EIP-1967 implementation slot"},
+		{"known address", common.BytesToHash(common.HexToAddress(OPTokenAddress).Bytes()), "address " + OPTokenAddress + ", OP TOKEN"},
+		{"address", common.BytesToHash(common.HexToAddress(airdropAlice).Bytes()), "address " + ChecksumAddress(airdropAlice)},
+		{"small number", common.BigToHash(common.Big3), ""},
+		{"text", text, `text "op-mainnet"`},
+		{"hash", crypto.Keccak256Hash([]byte("anything")), ""},
+	}
+	for _, tt := range tests {
+		if got := InterpretBytes32(tt.value, OPMainnetChainID); got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestParseTransactionDataBytes32Display(t *testing.T) {
+	role := crypto.Keccak256Hash([]byte("MINTER_ROLE"))
+	call, err := ParseTransactionData(airdropToken, encodeKnownCall(t, "approveHash(bytes32)", role), OPMainnetChainID, VerifyOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(call.ParsedData) != 1 || !strings.HasSuffix(call.ParsedData[0].Display, "(MINTER_ROLE)") {
+		t.Errorf("expected the role to be named beside the value, got %+v", call.ParsedData)
+	}
+
+	call, err = ParseTransactionData(airdropToken, encodeKnownCall(t, "approveHash(bytes32)", crypto.Keccak256Hash([]byte("tx"))), OPMainnetChainID, VerifyOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if call.ParsedData[0].Display != "" {
+		t.Errorf("expected no interpretation of an unknown hash, got %q", call.ParsedData[0].Display)
+	}
+}
@@ -0,0 +1,322 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Ceremony is one logical change queued as several Safe transactions, usually the chain-specific
+// variants of an upgrade on each chain, and who signs each of them
+type Ceremony struct {
+	Name         string                `json:"name"`
+	Transactions []CeremonyTransaction `json:"transactions"`
+}
+
+// CeremonyTransaction is a Safe transaction of a ceremony, identified by its network, Safe, and
+// nonce, with the call it is intended to make
+type CeremonyTransaction struct {
+	Name    string   `json:"name"`
+	Network string   `json:"network"`
+	Safe    string   `json:"safe"`
+	Nonce   uint64   `json:"nonce"`
+	Signers []string `json:"signers"`
+	Expect  Expected `json:"expect"`
+}
+
+// Expected is the call a ceremony transaction must make. For a nested approval it is the call of
+// the approved child transaction, made by Safe. Calldata is given in full or, when it is long, by
+// its keccak256 hash.
+type Expected struct {
+	Safe      string `json:"safe,omitempty"`
+	To        string `json:"to"`
+	Value     string `json:"value,omitempty"`
+	Operation int    `json:"operation"`
+	Data      string `json:"data,omitempty"`
+	DataHash  string `json:"dataHash,omitempty"`
+}
+
+// CeremonyCheck is the verification of one ceremony transaction
+type CeremonyCheck struct {
+	Name    string   `json:"name"`
+	Network string   `json:"network"`
+	Safe    string   `json:"safe"`
+	Nonce   uint64   `json:"nonce"`
+	Signers []string `json:"signers"`
+
+	DomainHash  string `json:"domainHash"`
+	MessageHash string `json:"messageHash"`
+	SafeTxHash  string `json:"safeTxHash"`
+
+	// ChildSafeTxHash is the hash of the approved child transaction of a nested approval
+	ChildSafeTxHash string `json:"childSafeTxHash,omitempty"`
+
+	// Problems are the differences from the intended call and the critical warnings of the
+	// verification
+	Problems []string `json:"problems,omitempty"`
+}
+
+// CeremonySignature is a transaction a signer signs, with the hashes their wallet shows
+type CeremonySignature struct {
+	Transaction string `json:"transaction"`
+	Network     string `json:"network"`
+	Safe        string `json:"safe"`
+	Nonce       uint64 `json:"nonce"`
+	DomainHash  string `json:"domainHash"`
+	MessageHash string `json:"messageHash"`
+	SafeTxHash  string `json:"safeTxHash"`
+}
+
+// CeremonySigner is everything one signer signs in a ceremony
+type CeremonySigner struct {
+	Signer     string              `json:"signer"`
+	Signatures []CeremonySignature `json:"signatures"`
+}
+
+// CeremonyReport is the result of checking every transaction of a ceremony
+type CeremonyReport struct {
+	Name         string           `json:"name"`
+	Transactions []CeremonyCheck  `json:"transactions"`
+	Signers      []CeremonySigner `json:"signers"`
+
+	// Problems are inconsistencies between transactions, such as two approvals of the same child
+	// transaction that approve different hashes
+	Problems []string `json:"problems,omitempty"`
+}
+
+// OK reports whether every transaction makes its intended call without critical warnings and
+// the transactions are consistent with each other
+func (r *CeremonyReport) OK() bool {
+	if len(r.Problems) > 0 {
+		return false
+	}
+	for _, check := range r.Transactions {
+		if len(check.Problems) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// LoadCeremonyFile reads and validates a ceremony file
+func LoadCeremonyFile(path string) (*Ceremony, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ceremony file: %w", err)
+	}
+	var ceremony Ceremony
+	if err := json.Unmarshal(data, &ceremony); err != nil {
+		return nil, fmt.Errorf("invalid ceremony file %s: %w", path, err)
+	}
+	if err := ceremony.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid ceremony file %s: %w", path, err)
+	}
+	return &ceremony, nil
+}
+
+// Validate checks that every transaction of a ceremony is named once, targets a known network
+// and a fully given Safe, has signers, and says what call it must make
+func (c *Ceremony) Validate() error {
+	if len(c.Transactions) == 0 {
+		return fmt.Errorf("the ceremony has no transactions")
+	}
+	names := map[string]bool{}
+	targets := map[string]string{}
+	for i, tx := range c.Transactions {
+		if tx.Name == "" {
+			return fmt.Errorf("transaction %d has no name", i+1)
+		}
+		if names[tx.Name] {
+			return fmt.Errorf("transaction %q is listed twice", tx.Name)
+		}
+		names[tx.Name] = true
+
+		if err := ValidateNetwork(tx.Network); err != nil {
+			return fmt.Errorf("transaction %q: %w", tx.Name, err)
+		}
+		if err := ValidateFullAddress("safe", StripChainPrefix(tx.Safe)); err != nil {
+			return fmt.Errorf("transaction %q: %w", tx.Name, err)
+		}
+		target := fmt.Sprintf("%s/%s/%d", tx.Network, strings.ToLower(StripChainPrefix(tx.Safe)), tx.Nonce)
+		if other, ok := targets[target]; ok {
+			return fmt.Errorf("transactions %q and %q are the same Safe transaction", other, tx.Name)
+		}
+		targets[target] = tx.Name
+
+		if len(tx.Signers) == 0 {
+			return fmt.Errorf("transaction %q has no signers", tx.Name)
+		}
+		if err := tx.Expect.validate(); err != nil {
+			return fmt.Errorf("transaction %q: %w", tx.Name, err)
+		}
+	}
+	return nil
+}
+
+// validate checks that an expected call gives a target and its calldata
+func (e Expected) validate() error {
+	if e.Safe != "" {
+		if err := ValidateFullAddress("expect.safe", StripChainPrefix(e.Safe)); err != nil {
+			return err
+		}
+	}
+	if err := ValidateFullAddress("expect.to", StripChainPrefix(e.To)); err != nil {
+		return err
+	}
+	if e.Value != "" {
+		if _, ok := new(big.Int).SetString(e.Value, 10); !ok {
+			return fmt.Errorf("invalid expect.value %q: must be a decimal amount of wei", e.Value)
+		}
+	}
+	if e.Operation != 0 && e.Operation != 1 {
+		return fmt.Errorf("invalid expect.operation %d: must be 0 (call) or 1 (delegatecall)", e.Operation)
+	}
+	if e.Data == "" && e.DataHash == "" {
+		return fmt.Errorf("expect needs data or dataHash")
+	}
+	if e.Data != "" {
+		if _, err := hexutil.Decode(e.Data); err != nil {
+			return fmt.Errorf("invalid expect.data: %w", err)
+		}
+	}
+	if e.DataHash != "" {
+		if hash, err := hexutil.Decode(e.DataHash); err != nil || len(hash) != 32 {
+			return fmt.Errorf("invalid expect.dataHash %q: must be a 32-byte hex hash", e.DataHash)
+		}
+	}
+	return nil
+}
+
+// CheckCeremony checks the verification results of a ceremony's transactions, given in the
+// order of the ceremony file. Each transaction must be on its network and make its intended
+// call. Approvals of the same child transaction must approve the same hash. The hashes each
+// signer signs are collected into one list per signer, so every signer can check their
+// hardware wallet against a single place.
+func CheckCeremony(ceremony *Ceremony, results []*VerificationResult) (*CeremonyReport, error) {
+	if len(results) != len(ceremony.Transactions) {
+		return nil, fmt.Errorf("expected %d verification results, got %d", len(ceremony.Transactions), len(results))
+	}
+
+	report := &CeremonyReport{Name: ceremony.Name}
+	signers := map[string]int{}
+	approvals := map[string]CeremonyCheck{}
+	for i, tx := range ceremony.Transactions {
+		result := results[i]
+		check := CeremonyCheck{
+			Name:        tx.Name,
+			Network:     tx.Network,
+			Safe:        ChecksumAddress(StripChainPrefix(tx.Safe)),
+			Nonce:       tx.Nonce,
+			Signers:     tx.Signers,
+			DomainHash:  result.DomainHash,
+			MessageHash: result.MessageHash,
+			SafeTxHash:  result.ApproveHash,
+		}
+
+		_, chainID, err := getNetworkInfo(tx.Network)
+		if err != nil {
+			return nil, err
+		}
+		if uint64(result.Transaction.Chain) != chainID {
+			check.Problems = append(check.Problems, fmt.Sprintf("the transaction is on chain %d, not %s (chain %d)", result.Transaction.Chain, tx.Network, chainID))
+		}
+		if !strings.EqualFold(StripChainPrefix(result.Transaction.Safe), check.Safe) || uint64(result.Transaction.Nonce) != tx.Nonce {
+			check.Problems = append(check.Problems, fmt.Sprintf("the transaction is nonce %d of %s, not nonce %d of %s",
+				result.Transaction.Nonce, result.Transaction.Safe, tx.Nonce, check.Safe))
+		}
+
+		// The call that matters is the one the approved child transaction makes
+		call := result
+		if result.NestedResult != nil {
+			call = result.NestedResult
+			check.ChildSafeTxHash = call.ApproveHash
+
+			child := fmt.Sprintf("%d/%s/%d", call.Transaction.Chain, strings.ToLower(StripChainPrefix(call.Transaction.Safe)), call.Transaction.Nonce)
+			if other, ok := approvals[child]; ok && !strings.EqualFold(other.ChildSafeTxHash, check.ChildSafeTxHash) {
+				report.Problems = append(report.Problems, fmt.Sprintf("%q and %q approve different versions of nonce %d of %s: %s and %s",
+					other.Name, tx.Name, call.Transaction.Nonce, call.Transaction.Safe, other.ChildSafeTxHash, check.ChildSafeTxHash))
+			} else if !ok {
+				approvals[child] = check
+			}
+		}
+		check.Problems = append(check.Problems, compareExpectedCall(tx.Expect, call.Transaction)...)
+
+		for _, warning := range result.Warnings {
+			if warning.Severity == SeverityCritical {
+				check.Problems = append(check.Problems, warning.Message)
+			}
+		}
+		report.Transactions = append(report.Transactions, check)
+
+		for _, signer := range tx.Signers {
+			n, ok := signers[signer]
+			if !ok {
+				n = len(report.Signers)
+				signers[signer] = n
+				report.Signers = append(report.Signers, CeremonySigner{Signer: signer})
+			}
+			report.Signers[n].Signatures = append(report.Signers[n].Signatures, CeremonySignature{
+				Transaction: tx.Name,
+				Network:     tx.Network,
+				Safe:        check.Safe,
+				Nonce:       tx.Nonce,
+				DomainHash:  check.DomainHash,
+				MessageHash: check.MessageHash,
+				SafeTxHash:  check.SafeTxHash,
+			})
+		}
+	}
+	return report, nil
+}
+
+// compareExpectedCall lists how a transaction differs from the call it is intended to make
+func compareExpectedCall(expect Expected, tx SafeTransaction) []string {
+	var problems []string
+	if expect.Safe != "" && !strings.EqualFold(StripChainPrefix(expect.Safe), StripChainPrefix(tx.Safe)) {
+		problems = append(problems, fmt.Sprintf("the call is made by %s, not %s", tx.Safe, ChecksumAddress(StripChainPrefix(expect.Safe))))
+	}
+	if !strings.EqualFold(StripChainPrefix(expect.To), StripChainPrefix(tx.To)) {
+		problems = append(problems, fmt.Sprintf("the call is to %s, not %s", tx.To, ChecksumAddress(StripChainPrefix(expect.To))))
+	}
+
+	value := new(big.Int)
+	if expect.Value != "" {
+		value.SetString(expect.Value, 10)
+	}
+	actual := tx.Value
+	if actual == nil {
+		actual = new(big.Int)
+	}
+	if actual.Cmp(value) != 0 {
+		problems = append(problems, fmt.Sprintf("the call sends %s wei, not %s", actual, value))
+	}
+
+	if tx.Operation != expect.Operation {
+		problems = append(problems, fmt.Sprintf("the call is %s, not %s", operationName(tx.Operation), operationName(expect.Operation)))
+	}
+
+	data := common.FromHex(tx.Data)
+	if expect.Data != "" && !strings.EqualFold(hexutil.Encode(data), hexutil.Encode(common.FromHex(expect.Data))) {
+		problems = append(problems, fmt.Sprintf("the calldata differs from the intended calldata (%d bytes instead of %d)", len(data), len(common.FromHex(expect.Data))))
+	}
+	if expect.DataHash != "" {
+		if hash := crypto.Keccak256Hash(data).Hex(); !strings.EqualFold(hash, expect.DataHash) {
+			problems = append(problems, fmt.Sprintf("the calldata hashes to %s, not %s", hash, strings.ToLower(expect.DataHash)))
+		}
+	}
+	return problems
+}
+
+// operationName names a Safe operation
+func operationName(operation int) string {
+	if operation == 1 {
+		return "a delegatecall"
+	}
+	return "a call"
+}
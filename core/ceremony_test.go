@@ -0,0 +1,181 @@
+package core
+
+import (
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// ceremonyUpgrade is the batch an upgrade ceremony queues on a chain
+func ceremonyUpgrade(t *testing.T, chainID int, nonce int) SafeTransaction {
+	t.Helper()
+	tx := multiSendTx(t, nativeTransfer(airdropAlice, big.NewInt(1)), nativeTransfer(airdropBob, big.NewInt(int64(chainID))))
+	tx.Chain = chainID
+	tx.Safe = effectsSafe
+	tx.SafeVersion = "1.3.0"
+	tx.Nonce = nonce
+	return tx
+}
+
+// ceremonyApproval nests a child transaction under an approveHash call by fixtureParentSafe
+func ceremonyApproval(t *testing.T, child SafeTransaction, nonce int) SafeTransaction {
+	t.Helper()
+	childResult, err := VerifyTransaction(child, VerifyOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	child.Nested = &Nested{
+		Safe:        fixtureParentSafe,
+		SafeVersion: "1.3.0",
+		Nonce:       nonce,
+		Data:        "0xd4d9bdcd" + strings.TrimPrefix(childResult.ApproveHash, "0x"),
+		To:          effectsSafe,
+	}
+	return child
+}
+
+// ceremonyExpect is the intended call of a ceremony transaction
+func ceremonyExpect(tx SafeTransaction) Expected {
+	return Expected{To: tx.To, Operation: tx.Operation, DataHash: crypto.Keccak256Hash(common.FromHex(tx.Data)).Hex()}
+}
+
+func verifyCeremony(t *testing.T, txs ...SafeTransaction) []*VerificationResult {
+	t.Helper()
+	var results []*VerificationResult
+	for _, tx := range txs {
+		result, err := VerifyTransaction(tx, VerifyOptions{})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+func TestCheckCeremony(t *testing.T) {
+	mainnet := ceremonyUpgrade(t, int(MainnetChainID), 7)
+	op := ceremonyUpgrade(t, 10, 3)
+	approval := ceremonyApproval(t, mainnet, 12)
+
+	ceremony := &Ceremony{Name: "Upgrade 14", Transactions: []CeremonyTransaction{
+		{Name: "ethereum", Network: "ethereum", Safe: effectsSafe, Nonce: 7, Signers: []string{"Foundation"}, Expect: ceremonyExpect(mainnet)},
+		{Name: "op", Network: "op", Safe: effectsSafe, Nonce: 3, Signers: []string{"Foundation"}, Expect: ceremonyExpect(op)},
+		{Name: "ethereum council", Network: "ethereum", Safe: fixtureParentSafe, Nonce: 12, Signers: []string{"Security Council"}, Expect: ceremonyExpect(mainnet)},
+	}}
+	if err := ceremony.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	results := verifyCeremony(t, mainnet, op, approval)
+
+	report, err := CheckCeremony(ceremony, results)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !report.OK() {
+		t.Fatalf("unexpected problems: %+v", report)
+	}
+	if report.Transactions[2].ChildSafeTxHash != results[0].ApproveHash {
+		t.Errorf("child hash = %s, want %s", report.Transactions[2].ChildSafeTxHash, results[0].ApproveHash)
+	}
+
+	if len(report.Signers) != 2 || report.Signers[0].Signer != "Foundation" || report.Signers[1].Signer != "Security Council" {
+		t.Fatalf("unexpected signers: %+v", report.Signers)
+	}
+	foundation := report.Signers[0].Signatures
+	if len(foundation) != 2 || foundation[0].SafeTxHash != results[0].ApproveHash || foundation[1].SafeTxHash != results[1].ApproveHash {
+		t.Errorf("unexpected Foundation signatures: %+v", foundation)
+	}
+	if council := report.Signers[1].Signatures; len(council) != 1 || council[0].SafeTxHash != results[2].ApproveHash || council[0].DomainHash != results[2].DomainHash {
+		t.Errorf("unexpected Security Council signatures: %+v", council)
+	}
+}
+
+func TestCheckCeremonyWrongVariant(t *testing.T) {
+	mainnet := ceremonyUpgrade(t, int(MainnetChainID), 7)
+	op := ceremonyUpgrade(t, 10, 3)
+
+	// The OP Mainnet transaction carries the Ethereum variant of the calldata
+	ceremony := &Ceremony{Name: "Upgrade 14", Transactions: []CeremonyTransaction{
+		{Name: "op", Network: "op", Safe: effectsSafe, Nonce: 3, Signers: []string{"Foundation"}, Expect: ceremonyExpect(mainnet)},
+	}}
+	report, err := CheckCeremony(ceremony, verifyCeremony(t, op))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if report.OK() || len(report.Transactions[0].Problems) != 1 || !strings.Contains(report.Transactions[0].Problems[0], "calldata hashes to") {
+		t.Fatalf("unexpected problems: %+v", report.Transactions[0].Problems)
+	}
+
+	// The wrong network, target, and operation are reported too
+	expect := ceremonyExpect(op)
+	expect.To, expect.Operation = airdropBob, 0
+	ceremony.Transactions[0].Network, ceremony.Transactions[0].Expect = "base", expect
+	report, err = CheckCeremony(ceremony, verifyCeremony(t, op))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	problems := strings.Join(report.Transactions[0].Problems, "\n")
+	for _, want := range []string{"not base (chain 8453)", "not " + common.HexToAddress(airdropBob).Hex(), "a delegatecall, not a call"} {
+		if !strings.Contains(problems, want) {
+			t.Errorf("problems do not mention %q:\n%s", want, problems)
+		}
+	}
+}
+
+func TestCheckCeremonyInconsistentApprovals(t *testing.T) {
+	mainnet := ceremonyUpgrade(t, int(MainnetChainID), 7)
+	other := ceremonyUpgrade(t, int(MainnetChainID), 7)
+	other.Value = big.NewInt(1)
+
+	ceremony := &Ceremony{Name: "Upgrade 14", Transactions: []CeremonyTransaction{
+		{Name: "council", Network: "ethereum", Safe: fixtureParentSafe, Nonce: 12, Signers: []string{"Security Council"}, Expect: ceremonyExpect(mainnet)},
+		{Name: "council again", Network: "ethereum", Safe: fixtureParentSafe, Nonce: 13, Signers: []string{"Security Council"}, Expect: ceremonyExpect(mainnet)},
+	}}
+	report, err := CheckCeremony(ceremony, verifyCeremony(t, ceremonyApproval(t, mainnet, 12), ceremonyApproval(t, other, 13)))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(report.Problems) != 1 || !strings.Contains(report.Problems[0], "approve different versions of nonce 7") {
+		t.Fatalf("unexpected ceremony problems: %+v", report.Problems)
+	}
+	if len(report.Transactions[1].Problems) != 1 || !strings.Contains(report.Transactions[1].Problems[0], "sends 1 wei, not 0") {
+		t.Errorf("unexpected problems: %+v", report.Transactions[1].Problems)
+	}
+}
+
+func TestLoadCeremonyFile(t *testing.T) {
+	valid := `{"name": "%s", "network": "op", "safe": "` + effectsSafe + `", "nonce": 1, "signers": ["Foundation"], "expect": {"to": "` + airdropBob + `", "data": "0x"}}`
+	path := filepath.Join(t.TempDir(), "ceremony.json")
+	if err := os.WriteFile(path, []byte(`{"name": "Upgrade 14", "transactions": [`+strings.ReplaceAll(valid, "%s", "a")+`]}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if ceremony, err := LoadCeremonyFile(path); err != nil || len(ceremony.Transactions) != 1 {
+		t.Fatalf("unexpected result loading a valid file: %+v, %v", ceremony, err)
+	}
+
+	for name, transactions := range map[string]string{
+		"no transactions":    ``,
+		"duplicate name":     strings.ReplaceAll(valid, "%s", "a") + `,` + strings.Replace(strings.ReplaceAll(valid, "%s", "a"), `"nonce": 1`, `"nonce": 2`, 1),
+		"same transaction":   strings.ReplaceAll(valid, "%s", "a") + `,` + strings.ReplaceAll(valid, "%s", "b"),
+		"no signers":         strings.Replace(strings.ReplaceAll(valid, "%s", "a"), `["Foundation"]`, `[]`, 1),
+		"no calldata":        strings.Replace(strings.ReplaceAll(valid, "%s", "a"), `, "data": "0x"`, ``, 1),
+		"short data hash":    strings.Replace(strings.ReplaceAll(valid, "%s", "a"), `"data": "0x"`, `"dataHash": "0x1234"`, 1),
+		"truncated safe":     strings.Replace(strings.ReplaceAll(valid, "%s", "a"), effectsSafe, "0x4444…4444", 1),
+		"unknown network":    strings.Replace(strings.ReplaceAll(valid, "%s", "a"), `"op"`, `"arbitrum"`, 1),
+		"invalid operation":  strings.Replace(strings.ReplaceAll(valid, "%s", "a"), `"data": "0x"`, `"data": "0x", "operation": 2`, 1),
+		"non-decimal amount": strings.Replace(strings.ReplaceAll(valid, "%s", "a"), `"data": "0x"`, `"data": "0x", "value": "0x10"`, 1),
+	} {
+		path := filepath.Join(t.TempDir(), "ceremony.json")
+		if err := os.WriteFile(path, []byte(`{"name": "Upgrade 14", "transactions": [`+transactions+`]}`), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadCeremonyFile(path); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
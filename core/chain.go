@@ -0,0 +1,101 @@
+package core
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ChainPrefixes maps EIP-3770 short names (as used by the Safe UI, e.g. "oeth:0x...") to chain IDs
+var ChainPrefixes = map[string]uint64{
+	"eth":     MainnetChainID,
+	"oeth":    OPMainnetChainID,
+	"base":    BaseMainnetChainID,
+	"sep":     SepoliaChainID,
+	"opsep":   OPSepoliaChainID,
+	"basesep": BaseSepoliaChainID,
+	"zksync":  ZkSyncEraChainID,
+}
+
+// ChainSource records a chain ID together with where it was inferred from
+type ChainSource struct {
+	Source  string
+	ChainID uint64
+}
+
+// ChainIDFromPrefix returns the chain ID implied by an EIP-3770 prefixed address, or zero when the
+// address has no prefix. A prefix that is not in ChainPrefixes is an error rather than ignored,
+// since dropping it would let an address of another chain, such as "arb1:0x...", pass the chain
+// cross-check.
+func ChainIDFromPrefix(address string) (uint64, error) {
+	idx := strings.Index(address, ":")
+	if idx == -1 {
+		return 0, nil
+	}
+	chainID, ok := ChainPrefixes[strings.ToLower(address[:idx])]
+	if !ok {
+		return 0, fmt.Errorf("unknown chain prefix %q in %q; op-txverify knows %s", address[:idx], address, knownChainPrefixes())
+	}
+	return chainID, nil
+}
+
+// knownChainPrefixes lists the prefixes of ChainPrefixes in order
+func knownChainPrefixes() string {
+	prefixes := make([]string, 0, len(ChainPrefixes))
+	for prefix := range ChainPrefixes {
+		prefixes = append(prefixes, prefix)
+	}
+	sort.Strings(prefixes)
+	return strings.Join(prefixes, ", ")
+}
+
+// PrefixSource builds a ChainSource from a prefixed address. An address without a prefix gives a
+// source with a zero chain ID, which ResolveChainID treats as absent.
+func PrefixSource(source, address string) (ChainSource, error) {
+	chainID, err := ChainIDFromPrefix(address)
+	if err != nil {
+		return ChainSource{}, fmt.Errorf("%s: %w", source, err)
+	}
+	if chainID == 0 {
+		return ChainSource{}, nil
+	}
+	return ChainSource{Source: fmt.Sprintf("%s prefix (%s)", source, address[:strings.Index(address, ":")]), ChainID: chainID}, nil
+}
+
+// ResolveChainID cross-checks every source that names a chain and returns the agreed chain ID.
+// Sources with a zero chain ID are treated as absent. Any disagreement is an error, since
+// trusting whichever source happened to be read first is how a signer ends up on the wrong chain.
+func ResolveChainID(sources ...ChainSource) (uint64, error) {
+	var resolved ChainSource
+	for _, source := range sources {
+		if source.ChainID == 0 {
+			continue
+		}
+		if resolved.ChainID == 0 {
+			resolved = source
+			continue
+		}
+		if source.ChainID != resolved.ChainID {
+			return 0, fmt.Errorf("chain ID conflict: %s says %d but %s says %d", resolved.Source, resolved.ChainID, source.Source, source.ChainID)
+		}
+	}
+	return resolved.ChainID, nil
+}
+
+// transactionChainSources collects all chain hints embedded in a transaction
+func transactionChainSources(tx SafeTransaction) ([]ChainSource, error) {
+	sources := []ChainSource{{Source: "chain field", ChainID: uint64(tx.Chain)}}
+	// Each field is its name and its address
+	fields := [][2]string{{"safe", tx.Safe}, {"to", tx.To}}
+	if tx.Nested != nil {
+		fields = append(fields, [2]string{"nested safe", tx.Nested.Safe}, [2]string{"nested to", tx.Nested.To})
+	}
+	for _, field := range fields {
+		source, err := PrefixSource(field[0], field[1])
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, source)
+	}
+	return sources, nil
+}
@@ -0,0 +1,74 @@
+package core
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestChainIDFromPrefix(t *testing.T) {
+	tests := []struct {
+		in   string
+		want uint64
+		err  bool
+	}{
+		{"eth:0xabc", MainnetChainID, false},
+		{"oeth:0xabc", OPMainnetChainID, false},
+		{"OETH:0xabc", OPMainnetChainID, false},
+		{"base:0xabc", BaseMainnetChainID, false},
+		{"0xabc", 0, false},
+		{"unknown:0xabc", 0, true},
+		{"arb1:0xabc", 0, true},
+		{"gno:0xabc", 0, true},
+		{":0xabc", 0, true},
+	}
+	for _, tc := range tests {
+		got, err := ChainIDFromPrefix(tc.in)
+		if got != tc.want || (err != nil) != tc.err {
+			t.Fatalf("ChainIDFromPrefix(%q) = (%d, %v), want (%d, error %v)", tc.in, got, err, tc.want, tc.err)
+		}
+	}
+}
+
+func TestResolveChainID(t *testing.T) {
+	got, err := ResolveChainID(
+		ChainSource{Source: "chain field", ChainID: 0},
+		ChainSource{Source: "safe prefix", ChainID: OPMainnetChainID},
+		ChainSource{Source: "network flag", ChainID: OPMainnetChainID},
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != OPMainnetChainID {
+		t.Fatalf("ResolveChainID = %d, want %d", got, OPMainnetChainID)
+	}
+
+	if _, err := ResolveChainID(
+		ChainSource{Source: "chain field", ChainID: MainnetChainID},
+		ChainSource{Source: "safe prefix", ChainID: OPMainnetChainID},
+	); err == nil {
+		t.Fatalf("expected conflict error")
+	}
+}
+
+func TestVerifyTransactionRejectsChainConflict(t *testing.T) {
+	tx := SafeTransaction{
+		Safe:        "eth:0x847B5c174615B1B7fDF770882256e2D3E95b9D92",
+		SafeVersion: "1.3.0",
+		Chain:       OPMainnetChainID,
+		To:          "0xcA11bde05977b3631167028862bE2a173976CA11",
+	}
+	if _, err := VerifyTransaction(tx, VerifyOptions{}); err == nil {
+		t.Fatalf("expected chain conflict between chain field and safe prefix")
+	}
+}
+
+func TestVerifyTransactionRejectsUnknownPrefix(t *testing.T) {
+	for _, tx := range []SafeTransaction{
+		{Safe: "arb1:0x847B5c174615B1B7fDF770882256e2D3E95b9D92", SafeVersion: "1.3.0", Chain: MainnetChainID, To: "0xcA11bde05977b3631167028862bE2a173976CA11"},
+		{Safe: "0x847B5c174615B1B7fDF770882256e2D3E95b9D92", SafeVersion: "1.3.0", Chain: MainnetChainID, To: "matic:0xcA11bde05977b3631167028862bE2a173976CA11"},
+	} {
+		if _, err := VerifyTransaction(tx, VerifyOptions{}); err == nil || !strings.Contains(err.Error(), "unknown chain prefix") {
+			t.Errorf("expected an unknown prefix to be refused, got %v", err)
+		}
+	}
+}
@@ -0,0 +1,281 @@
+package core
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// CheckManifestSchema identifies the layout of a CheckManifest. It changes whenever a field is
+// renamed or removed, so tools reading manifests can tell layouts apart.
+const CheckManifestSchema = "op-txverify/checks/v1"
+
+// Conditions under which an analyzer runs
+const (
+	RunsAlways          = "always"
+	RunsWithService     = "when the transaction is loaded from the Safe service"
+	RunsWithRPC         = "with an RPC endpoint"
+	RunsWithOption      = "when its option is given"
+	RunsOnExecuted      = "on executed transactions, with an RPC endpoint"
+	RunsOnUserOperation = "on ERC-4337 UserOperations"
+	RunsOnSigning       = "when signing"
+	RunsOnProvenance    = "when the provenance command runs"
+	RunsOnLink          = "when the transaction is read from a link"
+)
+
+// Analyzer is one of the checks op-txverify runs on a transaction. Its version increases
+// whenever what it checks or how changes, so that a manifest states exactly which logic ran.
+type Analyzer struct {
+	Name        string `json:"name"`
+	Version     int    `json:"version"`
+	Description string `json:"description"`
+	RunsWhen    string `json:"runsWhen"`
+
+	// Option is the flag that enables an analyzer that runs when its option is given
+	Option string `json:"option,omitempty"`
+
+	// Parameters are the thresholds and lists the analyzer uses, as built into this binary
+	Parameters map[string]string `json:"parameters,omitempty"`
+
+	// Warnings are the codes of the warnings the analyzer raises. Analyzers without warnings fail
+	// verification or only mark the output.
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// CheckManifest lists every analyzer of a build and the warnings they raise, so auditors and
+// runbooks can state which protections were in force during a ceremony
+type CheckManifest struct {
+	Schema    string         `json:"schema"`
+	Version   string         `json:"version"`
+	Commit    string         `json:"commit"`
+	Analyzers []Analyzer     `json:"analyzers"`
+	Checks    []WarningCheck `json:"warningChecks"`
+
+	// Digest is the SHA-256 of the analyzers and warning checks. Two builds with the same digest
+	// run the same checks with the same parameters.
+	Digest string `json:"digest"`
+}
+
+// NewCheckManifest lists the analyzers of this build, with the parameters of the current
+// configuration, such as the Safe service fallbacks
+func NewCheckManifest(version, commit string) (*CheckManifest, error) {
+	manifest := &CheckManifest{
+		Schema:    CheckManifestSchema,
+		Version:   version,
+		Commit:    commit,
+		Analyzers: analyzers(),
+		Checks:    WarningChecks(),
+	}
+	data, err := json.Marshal(struct {
+		Analyzers []Analyzer     `json:"analyzers"`
+		Checks    []WarningCheck `json:"warningChecks"`
+	}{manifest.Analyzers, manifest.Checks})
+	if err != nil {
+		return nil, fmt.Errorf("failed to digest check manifest: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	manifest.Digest = "sha256:" + hex.EncodeToString(sum[:])
+	return manifest, nil
+}
+
+// analyzers lists the analyzers in the order they run. Parameters are read from the values the
+// checks use, so the manifest cannot drift from the code.
+func analyzers() []Analyzer {
+	var versions, variants []string
+	for version := range SupportedSafeVersions {
+		versions = append(versions, version)
+	}
+	for variant := range SupportedSafeVersionMetadata {
+		if variant != "" {
+			variants = append(variants, variant)
+		}
+	}
+	sort.Strings(versions)
+	sort.Strings(variants)
+
+	return []Analyzer{
+		{
+			Name: "chain-consistency", Version: 1, RunsWhen: RunsAlways,
+			Description: "Fails verification when the chain field and the chain prefixes of the addresses disagree.",
+		},
+		{
+			Name: "safe-hashing", Version: 1, RunsWhen: RunsAlways,
+			Description: "Computes the domain, message, and Safe tx hashes, and fails verification for a Safe version whose hashing has not been checked.",
+			Parameters: map[string]string{
+				"supportedVersions": strings.Join(versions, ", "),
+				"supportedVariants": strings.Join(variants, ", "),
+			},
+		},
+		{
+			Name: "service-hash", Version: 1, RunsWhen: RunsWithService,
+			Description: "Compares the safeTxHash the Safe service reports with the computed one.",
+			Warnings:    codes(warnServiceHashMismatch),
+		},
+		{
+			Name: "nested-approval", Version: 1, RunsWhen: RunsAlways,
+			Description: "Checks that a parent transaction approves the hash of the child transaction shown.",
+			Warnings:    codes(warnApprovedHashMismatch),
+		},
+		{
+			Name: "replacements", Version: 1, RunsWhen: RunsWithService,
+			Description: "Lists other transactions queued for the same nonce.",
+			Warnings:    codes(warnReplacements),
+		},
+		{
+			Name: "service-decoding", Version: 1, RunsWhen: RunsWithService,
+			Description: "Compares the Safe service's decoding of the calldata with op-txverify's.",
+			Parameters:  map[string]string{"maxMismatchesReported": fmt.Sprint(maxDecodingMismatches)},
+			Warnings:    codes(warnDecodingMismatch),
+		},
+		{
+			Name: "service-fallback", Version: 1, RunsWhen: RunsWithService,
+			Description: "Labels transactions loaded from a mirror, cached responses, or the chain when the Safe service fails.",
+			Parameters: map[string]string{
+				"attempts":        fmt.Sprint(max(serviceFallback.Attempts, 1)),
+				"backoff":         serviceFallback.Backoff.String(),
+				"mirrors":         fmt.Sprint(countURLs(serviceFallback.Mirrors)),
+				"cache":           fmt.Sprint(serviceFallback.Cache != nil),
+				"onchainLookback": fmt.Sprint(DefaultOnchainLookback),
+			},
+			Warnings: codes(warnServiceFallback),
+		},
+		{
+			Name: "proposer", Version: 1, RunsWhen: RunsWithService,
+			Description: "Flags proposals by non-owners and from apps that are not known.",
+			Parameters:  map[string]string{"knownOrigins": strings.Join(KnownProposalOrigins, ", ")},
+			Warnings:    codes(warnProposerNotOwner, warnUnknownOrigin),
+		},
+		{
+			Name: "address-similarity", Version: 1, RunsWhen: RunsAlways,
+			Description: "Flags address-poisoning lookalikes among the transaction's addresses and of known contracts.",
+			Parameters: map[string]string{
+				"affixHexDigits":       fmt.Sprint(similarAffixLength),
+				"maxEditDistance":      fmt.Sprint(similarEditDistance),
+				"minSignificantDigits": fmt.Sprint(minSignificantDigits),
+			},
+			Warnings: codes(warnSimilarAddresses, warnLookalikeContract),
+		},
+		{
+			Name: "self-calls", Version: 1, RunsWhen: RunsAlways,
+			Description: "Flags calls from the Safe to itself, which change its configuration.",
+			Warnings:    codes(warnSelfCall),
+		},
+		{
+			Name: "role-changes", Version: 1, RunsWhen: RunsAlways,
+			Description: "Flags AccessControl grants, revocations, and renunciations of the admin role and of unknown roles.",
+			Warnings:    codes(warnAdminGranted, warnAdminRemoved, warnUnknownRole),
+		},
+		{
+			Name: "ownership-transfers", Version: 1, RunsWhen: RunsAlways,
+			Description: "Flags Ownable transfers, renunciations, and acceptances of contract ownership.",
+			Warnings:    codes(warnOwnershipToZero, warnOwnershipToSafe, warnOwnershipTransfer, warnOwnershipRenounced, warnOwnershipAccepted),
+		},
+		{
+			Name: "emergency-functions", Version: 1, RunsWhen: RunsAlways,
+			Description: "Marks calls of emergency functions, such as pause and blacklist, as emergency actions.",
+			Parameters:  map[string]string{"selectors": fmt.Sprint(len(EmergencyFunctions))},
+		},
+		{
+			Name: "label-conflicts", Version: 1, RunsWhen: RunsWithOption, Option: "--address-book",
+			Description: "Flags addresses the address book names differently from the known contracts.",
+			Warnings:    codes(warnLabelConflict),
+		},
+		{
+			Name: "denylist", Version: 1, RunsWhen: RunsWithOption, Option: "--denylist",
+			Description: "Flags every denylisted address in the transaction.",
+			Warnings:    codes(warnDenylisted),
+		},
+		{
+			Name: "argument-annotations", Version: 1, RunsWhen: RunsWithOption, Option: "--annotations",
+			Description: "Attaches explanations to decoded arguments and flags annotations that match nothing.",
+			Warnings:    codes(warnUnmatchedAnnotation),
+		},
+		{
+			Name: "grants", Version: 1, RunsWhen: RunsWithOption, Option: "--grants",
+			Description: "Compares the vesting schedules a transaction creates with the grants manifest.",
+			Warnings:    codes(warnGrantsUnchecked, warnNoGrantsManifest, warnNoGrant, warnGrantMatched, warnGrantMismatch),
+		},
+		{
+			Name: "independent-decode", Version: 1, RunsWhen: RunsWithOption, Option: "--independent-decode",
+			Description: "Decodes the calldata a second time with an independent decoder, and fails verification if the two disagree.",
+		},
+		{
+			Name: "review-session", Version: 1, RunsWhen: RunsWithOption, Option: "--session",
+			Description: "Flags calls a review session records as reviewed that the transaction no longer makes.",
+			Warnings:    codes(warnStaleReview),
+		},
+		{
+			Name: "signing-window", Version: 1, RunsWhen: RunsWithOption, Option: "--schedule",
+			Description: "Flags signing outside the transaction's signing window, and deadlines that are close.",
+			Parameters:  map[string]string{"deadlineWarningPeriod": DeadlineWarningPeriod.String()},
+			Warnings:    codes(warnWindowNotOpen, warnDeadlinePassed, warnDeadlineSoon),
+		},
+		{
+			Name: "eth-recipients", Version: 1, RunsWhen: RunsWithRPC,
+			Description: "Simulates plain ETH transfers to contracts and flags the ones that revert.",
+			Warnings:    codes(warnETHUnchecked, warnETHRejected, warnETHCheckFailed),
+		},
+		{
+			Name: "owner-changes", Version: 1, RunsWhen: RunsWithRPC,
+			Description: "Applies owner and threshold changes to the Safe's current owners and flags changes that revert.",
+			Warnings:    codes(warnOwnersUnchecked, warnOwnerChangeReverts),
+		},
+		{
+			Name: "finality", Version: 1, RunsWhen: RunsOnExecuted,
+			Description: "Checks that the execution's block is canonical and finalized.",
+			Warnings:    codes(warnFinalityUnchecked, warnExecutionNotFound, warnExecutionReverted, warnExecutionReorged, warnNonCanonicalBlock, warnFinalityUnknown, warnNotFinalized),
+		},
+		{
+			Name: "execution-effects", Version: 1, RunsWhen: RunsOnExecuted,
+			Description: "Compares the transfers and approvals the calldata makes with the events of the execution.",
+			Warnings:    codes(warnExecutionFailure, warnEventMismatch, warnWrongExecution, warnMissingEffect, warnUnexpectedEffect),
+		},
+		{
+			Name: "userop", Version: 1, RunsWhen: RunsOnUserOperation,
+			Description: "Checks the module, call, paymaster, fees, and validity window of a UserOperation.",
+			Warnings: codes(warnNonCanonicalModule, warnDenylisted, warnNotExecuteUserOp, warnUserOpDelegatecall, warnUnknownOperation,
+				warnFactoryDeploy, warnPaymaster, warnNoPaymaster, warnPriorityFee, warnNeverExpires, warnNeverValid, warnValidityMismatch),
+		},
+		{
+			Name: "keystore", Version: 1, RunsWhen: RunsOnSigning,
+			Description: "Flags keystores whose key derivation is cheaper to brute force than the standard.",
+			Parameters:  map[string]string{"standardScryptN": fmt.Sprint(standardScryptN)},
+			Warnings:    codes(warnKeystoreKDF, warnKeystoreScrypt),
+		},
+		{
+			Name: "critical-refusal", Version: 1, RunsWhen: RunsOnSigning,
+			Description: "Refuses to sign when verification raised a critical warning.",
+		},
+		{
+			Name: "build-provenance", Version: 1, RunsWhen: RunsOnProvenance,
+			Description: "Checks that the binary can be reproduced from a clean commit that matches its stamped one.",
+			Warnings:    codes(warnUnreproducibleBuild, warnDirtyBuild, warnNoRevision, warnCommitMismatch),
+		},
+		{
+			Name: "link-sum", Version: 1, RunsWhen: RunsOnLink,
+			Description: "Checks the transaction a link carries against the link's keccak256 sum, and flags links without one.",
+			Warnings:    codes(warnUncheckedLink),
+		},
+	}
+}
+
+// codes returns the codes of checks
+func codes(checks ...*WarningCheck) []string {
+	codes := make([]string, len(checks))
+	for i, check := range checks {
+		codes[i] = check.Code
+	}
+	return codes
+}
+
+// countURLs counts the URLs of every chain
+func countURLs(urls map[uint64][]string) int {
+	count := 0
+	for _, list := range urls {
+		count += len(list)
+	}
+	return count
+}
@@ -0,0 +1,67 @@
+package core
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCheckManifest(t *testing.T) {
+	manifest, err := NewCheckManifest("v1.2.3", "abc123")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if manifest.Schema != CheckManifestSchema || manifest.Version != "v1.2.3" || manifest.Commit != "abc123" {
+		t.Errorf("unexpected manifest header: %+v", manifest)
+	}
+	if !strings.HasPrefix(manifest.Digest, "sha256:") || len(manifest.Digest) != len("sha256:")+64 {
+		t.Errorf("unexpected digest %q", manifest.Digest)
+	}
+
+	// Every warning is raised by an analyzer that names it, and every analyzer is unique
+	raisedBy := map[string]string{}
+	names := map[string]bool{}
+	for _, analyzer := range manifest.Analyzers {
+		if names[analyzer.Name] {
+			t.Errorf("analyzer %s is listed twice", analyzer.Name)
+		}
+		names[analyzer.Name] = true
+		if analyzer.Version < 1 || analyzer.Description == "" || analyzer.RunsWhen == "" {
+			t.Errorf("analyzer %s is incomplete: %+v", analyzer.Name, analyzer)
+		}
+		if (analyzer.RunsWhen == RunsWithOption) != (analyzer.Option != "") {
+			t.Errorf("analyzer %s runs %q with option %q", analyzer.Name, analyzer.RunsWhen, analyzer.Option)
+		}
+		for _, code := range analyzer.Warnings {
+			if _, err := LookupWarningCheck(code); err != nil {
+				t.Errorf("analyzer %s names %v", analyzer.Name, err)
+			}
+			raisedBy[code] = analyzer.Name
+		}
+	}
+	for _, check := range manifest.Checks {
+		if raisedBy[check.Code] == "" {
+			t.Errorf("no analyzer raises %s", check.Code)
+		}
+	}
+
+	hashing := manifest.Analyzers[1]
+	if hashing.Name != "safe-hashing" || !strings.Contains(hashing.Parameters["supportedVersions"], "1.4.1") || hashing.Parameters["supportedVariants"] != "L2" {
+		t.Errorf("unexpected hashing analyzer %+v", hashing)
+	}
+}
+
+func TestCheckManifestDigest(t *testing.T) {
+	first, _ := NewCheckManifest("v1", "a")
+	second, _ := NewCheckManifest("v2", "b")
+	if first.Digest != second.Digest {
+		t.Errorf("expected the digest to depend only on the checks, got %s and %s", first.Digest, second.Digest)
+	}
+
+	// A changed parameter changes the digest
+	defer SetServiceFallback(serviceFallback)
+	SetServiceFallback(ServiceFallback{Attempts: 5})
+	changed, _ := NewCheckManifest("v1", "a")
+	if changed.Digest == first.Digest {
+		t.Error("expected a different digest for different fallback attempts")
+	}
+}
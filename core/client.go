@@ -0,0 +1,358 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// APIValue is a scalar field from the Safe service. Depending on the endpoint and service
+// deployment it may be encoded as a JSON string or number, be null, or be omitted entirely.
+type APIValue struct {
+	Raw     string
+	Present bool
+	Null    bool
+}
+
+// UnmarshalJSON accepts strings, numbers, and null
+func (v *APIValue) UnmarshalJSON(data []byte) error {
+	v.Present = true
+	if string(data) == "null" {
+		v.Null = true
+		return nil
+	}
+
+	var str string
+	if err := json.Unmarshal(data, &str); err == nil {
+		v.Raw = str
+		return nil
+	}
+
+	var num json.Number
+	if err := json.Unmarshal(data, &num); err != nil {
+		return fmt.Errorf("expected string, number, or null, got %s", string(data))
+	}
+	v.Raw = num.String()
+	return nil
+}
+
+// MarshalJSON writes the raw value back as a string (or null when absent)
+func (v APIValue) MarshalJSON() ([]byte, error) {
+	if !v.Present || v.Null {
+		return []byte("null"), nil
+	}
+	return json.Marshal(v.Raw)
+}
+
+// APITransaction represents a multisig transaction as returned by the Safe service.
+// The v1 list endpoint and the v2 single-transaction endpoint differ in whether numeric
+// fields are encoded as numbers or strings, which APIValue absorbs.
+type APITransaction struct {
+	Safe           string      `json:"safe"`
+	To             APIValue    `json:"to"`
+	Value          APIValue    `json:"value"`
+	Data           APIValue    `json:"data"`
+	Operation      APIValue    `json:"operation"`
+	SafeTxGas      APIValue    `json:"safeTxGas"`
+	BaseGas        APIValue    `json:"baseGas"`
+	GasPrice       APIValue    `json:"gasPrice"`
+	GasToken       APIValue    `json:"gasToken"`
+	RefundReceiver APIValue    `json:"refundReceiver"`
+	Nonce          APIValue    `json:"nonce"`
+	SafeTxHash     string      `json:"safeTxHash"`
+	DataDecoded    interface{} `json:"dataDecoded"`
+
+	// Proposer is the address that proposed the transaction to the service, SubmissionDate when,
+	// and Origin with which app. They tell apart the transactions proposed for the same nonce.
+	Proposer       string          `json:"proposer"`
+	SubmissionDate string          `json:"submissionDate"`
+	Origin         json.RawMessage `json:"origin"`
+
+	ConfirmationsRequired APIValue          `json:"confirmationsRequired"`
+	Confirmations         []APIConfirmation `json:"confirmations"`
+
+	IsExecuted      bool   `json:"isExecuted"`
+	ExecutionDate   string `json:"executionDate"`
+	BlockNumber     uint64 `json:"blockNumber"`
+	TransactionHash string `json:"transactionHash"`
+}
+
+// APIConfirmation is an owner's signature on a multisig transaction, as reported by the Safe service
+type APIConfirmation struct {
+	Owner          string `json:"owner"`
+	SubmissionDate string `json:"submissionDate"`
+}
+
+// APIResponse represents the response from the Safe API
+type APIResponse struct {
+	Count   int              `json:"count"`
+	Next    string           `json:"next,omitempty"`
+	Results []APITransaction `json:"results"`
+}
+
+// maxAPIPages bounds how many pages of a list are fetched, in case a service keeps returning a
+// next page
+const maxAPIPages = 50
+
+// SafeInfoResponse represents the response from the Safe info API
+type SafeInfoResponse struct {
+	Version   string   `json:"version"`
+	Nonce     APIValue `json:"nonce"`
+	Threshold int      `json:"threshold"`
+	Owners    []string `json:"owners"`
+}
+
+// SafeClient is the set of Safe Transaction Service calls used by op-txverify
+type SafeClient interface {
+	// GetSafeInfo returns the Safe info (including its version) for a Safe address
+	GetSafeInfo(ctx context.Context, safeAddress string) (*SafeInfoResponse, error)
+
+	// GetMultisigTransactions returns the multisig transactions for a Safe at a given nonce
+	GetMultisigTransactions(ctx context.Context, safeAddress string, nonce uint64) (*APIResponse, error)
+
+	// GetPendingTransactions returns the unexecuted multisig transactions for a Safe from a nonce
+	// onwards, in nonce order
+	GetPendingTransactions(ctx context.Context, safeAddress string, fromNonce uint64) (*APIResponse, error)
+
+	// GetMultisigTransaction returns a single multisig transaction by its safeTxHash
+	GetMultisigTransaction(ctx context.Context, safeTxHash string) (*APITransaction, error)
+
+	// GetTransfer returns a single native or token transfer by its transfer ID
+	GetTransfer(ctx context.Context, transferID string) (*Transfer, error)
+
+	// GetModuleTransactions returns the most recent module transactions executed on a Safe
+	GetModuleTransactions(ctx context.Context, safeAddress string, limit int) (*ModuleTransactionsResponse, error)
+
+	// GetModuleTransaction returns a single module transaction by its module transaction ID
+	GetModuleTransaction(ctx context.Context, id string) (*ModuleTransaction, error)
+
+	// GetSafeMessages returns the off-chain messages proposed for a Safe
+	GetSafeMessages(ctx context.Context, safeAddress string) (*SafeMessagesResponse, error)
+}
+
+// HTTPSafeClient is a SafeClient backed by a Safe Transaction Service HTTP API
+type HTTPSafeClient struct {
+	BaseURL    string
+	HTTPClient *http.Client
+
+	// Cache, when set, keeps every response of the service
+	Cache *ServiceCache
+
+	// cacheOnly serves responses from Cache instead of the service, and oldest is when the
+	// oldest of the responses served was fetched
+	cacheOnly bool
+	oldest    time.Time
+}
+
+// NewHTTPSafeClient creates a SafeClient for the Safe Transaction Service at baseURL
+func NewHTTPSafeClient(baseURL string) *HTTPSafeClient {
+	return &HTTPSafeClient{
+		BaseURL:    strings.TrimSuffix(baseURL, "/"),
+		HTTPClient: http.DefaultClient,
+	}
+}
+
+// NewCachedSafeClient creates a SafeClient that answers from the responses the Safe Transaction
+// Service at baseURL gave earlier, as kept in cache, without contacting the service
+func NewCachedSafeClient(baseURL string, cache *ServiceCache) *HTTPSafeClient {
+	return &HTTPSafeClient{
+		BaseURL:   strings.TrimSuffix(baseURL, "/"),
+		Cache:     cache,
+		cacheOnly: true,
+	}
+}
+
+// OldestCached returns when the oldest of the cached responses a client served was fetched, or
+// the zero time when it served none
+func (c *HTTPSafeClient) OldestCached() time.Time {
+	return c.oldest
+}
+
+// GetSafeInfo fetches /api/v1/safes/{address}/
+func (c *HTTPSafeClient) GetSafeInfo(ctx context.Context, safeAddress string) (*SafeInfoResponse, error) {
+	var safeInfo SafeInfoResponse
+	if err := c.getJSON(ctx, fmt.Sprintf("/api/v1/safes/%s/", safeAddress), &safeInfo); err != nil {
+		return nil, err
+	}
+	return &safeInfo, nil
+}
+
+// GetMultisigTransactions fetches /api/v1/safes/{address}/multisig-transactions/?nonce={nonce}
+func (c *HTTPSafeClient) GetMultisigTransactions(ctx context.Context, safeAddress string, nonce uint64) (*APIResponse, error) {
+	return c.getPages(ctx, fmt.Sprintf("/api/v1/safes/%s/multisig-transactions/?nonce=%d", safeAddress, nonce))
+}
+
+// GetPendingTransactions fetches
+// /api/v1/safes/{address}/multisig-transactions/?executed=false&nonce__gte={nonce}&ordering=nonce
+func (c *HTTPSafeClient) GetPendingTransactions(ctx context.Context, safeAddress string, fromNonce uint64) (*APIResponse, error) {
+	return c.getPages(ctx, fmt.Sprintf("/api/v1/safes/%s/multisig-transactions/?executed=false&nonce__gte=%d&ordering=nonce", safeAddress, fromNonce))
+}
+
+// getPages fetches a list of multisig transactions and the pages that follow it. The service
+// links each page to the next by an absolute URL; only its path and query are used, so every
+// page is fetched from the configured service whatever host the link names.
+func (c *HTTPSafeClient) getPages(ctx context.Context, path string) (*APIResponse, error) {
+	var all APIResponse
+	for page := 0; path != ""; page++ {
+		if page == maxAPIPages {
+			return nil, fmt.Errorf("the Safe service returned more than %d pages of transactions", maxAPIPages)
+		}
+		var apiResp APIResponse
+		if err := c.getJSON(ctx, path, &apiResp); err != nil {
+			return nil, err
+		}
+		all.Count = apiResp.Count
+		all.Results = append(all.Results, apiResp.Results...)
+
+		var err error
+		if path, err = c.nextPage(apiResp.Next); err != nil {
+			return nil, err
+		}
+	}
+	return &all, nil
+}
+
+// nextPage returns the path of the page a next link points at, or "" on the last page
+func (c *HTTPSafeClient) nextPage(link string) (string, error) {
+	if link == "" {
+		return "", nil
+	}
+	next, err := url.Parse(link)
+	if err != nil {
+		return "", fmt.Errorf("invalid next page link %q: %w", link, err)
+	}
+	path := next.RequestURI()
+	if base, err := url.Parse(c.BaseURL); err == nil && base.Path != "" {
+		path = strings.TrimPrefix(path, strings.TrimSuffix(base.Path, "/"))
+	}
+	return path, nil
+}
+
+// GetMultisigTransaction fetches /api/v2/multisig-transactions/{safeTxHash}/
+func (c *HTTPSafeClient) GetMultisigTransaction(ctx context.Context, safeTxHash string) (*APITransaction, error) {
+	var tx APITransaction
+	if err := c.getJSON(ctx, fmt.Sprintf("/api/v2/multisig-transactions/%s/", safeTxHash), &tx); err != nil {
+		return nil, txNotFound(err)
+	}
+	return &tx, nil
+}
+
+// GetTransfer fetches /api/v1/transfer/{transferId}
+func (c *HTTPSafeClient) GetTransfer(ctx context.Context, transferID string) (*Transfer, error) {
+	var transfer Transfer
+	if err := c.getJSON(ctx, fmt.Sprintf("/api/v1/transfer/%s", transferID), &transfer); err != nil {
+		return nil, txNotFound(err)
+	}
+	return &transfer, nil
+}
+
+// GetModuleTransactions fetches /api/v1/safes/{address}/module-transactions/?limit={limit}
+func (c *HTTPSafeClient) GetModuleTransactions(ctx context.Context, safeAddress string, limit int) (*ModuleTransactionsResponse, error) {
+	var resp ModuleTransactionsResponse
+	if err := c.getJSON(ctx, fmt.Sprintf("/api/v1/safes/%s/module-transactions/?limit=%d", safeAddress, limit), &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// GetModuleTransaction fetches /api/v1/module-transaction/{moduleTransactionId}
+func (c *HTTPSafeClient) GetModuleTransaction(ctx context.Context, id string) (*ModuleTransaction, error) {
+	var tx ModuleTransaction
+	if err := c.getJSON(ctx, fmt.Sprintf("/api/v1/module-transaction/%s", id), &tx); err != nil {
+		return nil, txNotFound(err)
+	}
+	return &tx, nil
+}
+
+// GetSafeMessages fetches /api/v1/safes/{address}/messages/, following next links to collect
+// every page
+func (c *HTTPSafeClient) GetSafeMessages(ctx context.Context, safeAddress string) (*SafeMessagesResponse, error) {
+	var all SafeMessagesResponse
+	path := fmt.Sprintf("/api/v1/safes/%s/messages/", safeAddress)
+	for page := 0; path != ""; page++ {
+		if page == maxAPIPages {
+			return nil, fmt.Errorf("the Safe service returned more than %d pages of messages", maxAPIPages)
+		}
+		var resp SafeMessagesResponse
+		if err := c.getJSON(ctx, path, &resp); err != nil {
+			return nil, err
+		}
+		all.Count = resp.Count
+		all.Results = append(all.Results, resp.Results...)
+
+		var err error
+		if path, err = c.nextPage(resp.Next); err != nil {
+			return nil, err
+		}
+	}
+	return &all, nil
+}
+
+// errServiceNotFound marks a 404 response of the service
+var errServiceNotFound = errors.New("not found")
+
+// txNotFound marks a 404 response to a transaction lookup as ErrTxNotFound
+func txNotFound(err error) error {
+	if errors.Is(err, errServiceNotFound) {
+		return withKind(ErrTxNotFound, err)
+	}
+	return err
+}
+
+// getJSON performs a GET request against the service and decodes the JSON response into out
+func (c *HTTPSafeClient) getJSON(ctx context.Context, path string, out interface{}) error {
+	endpoint := c.BaseURL + path
+
+	if c.cacheOnly {
+		body, fetched, err := c.Cache.Load(endpoint)
+		if err != nil {
+			return err
+		}
+		if c.oldest.IsZero() || fetched.Before(c.oldest) {
+			c.oldest = fetched
+		}
+		if err := json.Unmarshal(body, out); err != nil {
+			return fmt.Errorf("error parsing cached API response: %w", err)
+		}
+		return nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return fmt.Errorf("error creating request for %s: %w", endpoint, err)
+	}
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("error fetching %s: %w", endpoint, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return withKind(errServiceNotFound, fmt.Errorf("API request to %s failed with status: %s", endpoint, resp.Status))
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("API request to %s failed with status: %s", endpoint, resp.Status)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("error reading response body: %w", err)
+	}
+
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("error parsing API response: %w", err)
+	}
+
+	// Caching is best effort: a cache that cannot be written only loses the fallback
+	if c.Cache != nil {
+		c.Cache.Store(endpoint, body, time.Now())
+	}
+	return nil
+}
@@ -0,0 +1,423 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const (
+	fixtureGrantsSafe = "0x2501c477D0A35545a387Aa4A3EEe4292A9a8B3F0"
+	fixtureParentSafe = "0xE2Ed962948005AB01F2cEfE8326a0730B7D268af"
+	fixtureGrantsHash = "0x19767d264966e39d532d998c5354f76ad5102407124b1885d69bf23f791b6f4c"
+
+	fixtureTransferID = "e3c9b5bd8e0f2a1c45d6e7f8091a2b3c4d5e6f708192a3b4c5d6e7f8091a2b3c412"
+	fixtureModuleTxID = "i8f3a4e7c2b1d09e6f5a4b3c2d1e0f9a8b7c6d5e4f3a2b1c0d9e8f7a6b5c4d3e20,0"
+)
+
+// newFixtureServer serves recorded Safe Transaction Service responses from testdata/safe-api.
+// Routes map a request URI (path and query) to a fixture file; unknown routes return 404.
+func newFixtureServer(t *testing.T, routes map[string]string) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fixture, ok := routes[r.URL.RequestURI()]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+
+		data, err := os.ReadFile(filepath.Join("testdata", "safe-api", fixture))
+		if err != nil {
+			t.Errorf("failed to read fixture %s: %v", fixture, err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(data)
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+// defaultFixtureRoutes covers a plain transaction on the grants Safe and a parent Safe approving it
+func defaultFixtureRoutes() map[string]string {
+	return map[string]string{
+		"/api/v1/safes/" + fixtureGrantsSafe + "/":                                 "safe-info-grants.json",
+		"/api/v1/safes/" + fixtureParentSafe + "/":                                 "safe-info-parent.json",
+		"/api/v1/safes/" + fixtureGrantsSafe + "/multisig-transactions/?nonce=155": "multisig-transactions-grants-155.json",
+		"/api/v1/safes/" + fixtureGrantsSafe + "/multisig-transactions/?nonce=156": "multisig-transactions-grants-156-sparse.json",
+		"/api/v1/safes/" + fixtureGrantsSafe + "/multisig-transactions/?nonce=157": "multisig-transactions-grants-157-replaced.json",
+		"/api/v1/safes/" + fixtureGrantsSafe + "/multisig-transactions/?nonce=999": "multisig-transactions-empty.json",
+		"/api/v1/safes/" + fixtureParentSafe + "/multisig-transactions/?nonce=42":  "multisig-transactions-parent-42.json",
+		"/api/v2/multisig-transactions/" + fixtureGrantsHash + "/":                 "multisig-transaction-grants-155.json",
+		"/api/v1/transfer/" + fixtureTransferID:                                    "transfer-grants-incoming.json",
+		"/api/v1/safes/" + fixtureGrantsSafe + "/module-transactions/?limit=10":    "module-transactions-grants.json",
+		"/api/v1/safes/" + fixtureGrantsSafe + "/messages/":                        "messages-grants.json",
+		"/api/v1/safes/" + fixtureGrantsSafe + "/messages/?limit=2&offset=2":       "messages-grants-page-2.json",
+		"/api/v1/module-transaction/" + fixtureModuleTxID:                          "module-transaction-grants.json",
+	}
+}
+
+func TestHTTPSafeClientNotFound(t *testing.T) {
+	server := newFixtureServer(t, defaultFixtureRoutes())
+	client := NewHTTPSafeClient(server.URL)
+
+	if _, err := client.GetMultisigTransaction(context.Background(), "0xdead"); err == nil {
+		t.Fatalf("expected error for unknown transaction")
+	}
+}
+
+func TestGenerateTransactionWithClient(t *testing.T) {
+	server := newFixtureServer(t, defaultFixtureRoutes())
+	client := NewHTTPSafeClient(server.URL)
+
+	tx, err := GenerateTransactionWithClient(context.Background(), client, OPMainnetChainID, fixtureGrantsSafe, 155, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if tx.Nested != nil {
+		t.Fatalf("did not expect a nested transaction")
+	}
+	if tx.Chain != OPMainnetChainID || tx.Nonce != 155 || tx.SafeVersion != "1.3.0+L2" {
+		t.Fatalf("unexpected transaction: %+v", tx)
+	}
+	if tx.SignerProgress == nil || tx.SignerProgress.Required != 2 || len(tx.SignerProgress.Confirmations) != 1 {
+		t.Fatalf("unexpected signer progress: %+v", tx.SignerProgress)
+	}
+
+	hash, err := CalculateApproveHash(*tx)
+	if err != nil {
+		t.Fatalf("failed to calculate safe tx hash: %v", err)
+	}
+	if hash != fixtureGrantsHash {
+		t.Fatalf("safe tx hash = %s, want %s", hash, fixtureGrantsHash)
+	}
+}
+
+func TestGenerateTransactionWithClientNotFound(t *testing.T) {
+	server := newFixtureServer(t, defaultFixtureRoutes())
+	client := NewHTTPSafeClient(server.URL)
+
+	if _, err := GenerateTransactionWithClient(context.Background(), client, OPMainnetChainID, fixtureGrantsSafe, 999, ""); err == nil {
+		t.Fatalf("expected error for nonce without transactions")
+	}
+}
+
+func TestGenerateTransactionWithClientNested(t *testing.T) {
+	server := newFixtureServer(t, defaultFixtureRoutes())
+	client := NewHTTPSafeClient(server.URL)
+
+	tx, err := GenerateTransactionWithClient(context.Background(), client, OPMainnetChainID, fixtureParentSafe, 42, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if tx.Nested == nil {
+		t.Fatalf("expected a nested transaction")
+	}
+	if tx.Nested.Safe != fixtureParentSafe || tx.Nested.Nonce != 42 {
+		t.Fatalf("unexpected nested parent: %+v", tx.Nested)
+	}
+	if tx.Nested.SignerProgress == nil || tx.Nested.SignerProgress.Required != 1 {
+		t.Fatalf("expected the parent's signer progress, got %+v", tx.Nested.SignerProgress)
+	}
+	if tx.SignerProgress == nil || tx.SignerProgress.Required != 2 {
+		t.Fatalf("expected the child's signer progress, got %+v", tx.SignerProgress)
+	}
+	if tx.Safe != fixtureGrantsSafe || tx.Nonce != 155 {
+		t.Fatalf("expected child transaction content, got safe %s nonce %d", tx.Safe, tx.Nonce)
+	}
+}
+
+func TestGenerateTransactionWithClientNestedUsesChildNonce(t *testing.T) {
+	server := newFixtureServer(t, defaultFixtureRoutes())
+	client := NewHTTPSafeClient(server.URL)
+
+	tx, err := GenerateTransactionWithClient(context.Background(), client, OPMainnetChainID, fixtureParentSafe, 42, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if tx.Nonce == tx.Nested.Nonce {
+		t.Fatalf("the fixture needs different nonces for the parent and child, both are %d", tx.Nonce)
+	}
+
+	// The parent approves the child's safeTxHash, which only the child's nonce reproduces
+	approved := "0x" + tx.Nested.Data[10:74]
+	hash, err := CalculateApproveHash(*tx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.EqualFold(hash, approved) {
+		t.Fatalf("child hash = %s, want the approved %s", hash, approved)
+	}
+
+	parentNonce := *tx
+	parentNonce.Nonce = tx.Nested.Nonce
+	if hash, _ := CalculateApproveHash(parentNonce); strings.EqualFold(hash, approved) {
+		t.Fatalf("the parent's nonce should not reproduce the approved hash")
+	}
+}
+
+// lookupFailingClient is a SafeClient whose transaction lookups by hash fail
+type lookupFailingClient struct {
+	SafeClient
+	err error
+}
+
+func (c lookupFailingClient) GetMultisigTransaction(ctx context.Context, safeTxHash string) (*APITransaction, error) {
+	return nil, c.err
+}
+
+func TestGenerateTransactionWithClientNestedLookupFails(t *testing.T) {
+	server := newFixtureServer(t, defaultFixtureRoutes())
+	service := NewHTTPSafeClient(server.URL)
+
+	// A confirmed not found means the approved hash is not a transaction the service knows
+	notFound := lookupFailingClient{SafeClient: service, err: withKind(ErrTxNotFound, errors.New("404"))}
+	tx, err := GenerateTransactionWithClient(context.Background(), notFound, OPMainnetChainID, fixtureParentSafe, 42, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if tx.Nested != nil || tx.Safe != fixtureParentSafe {
+		t.Fatalf("expected the approval itself, got safe %s nested %+v", tx.Safe, tx.Nested)
+	}
+
+	// Any other failure must not be mistaken for a transaction that is not nested
+	unavailable := lookupFailingClient{SafeClient: service, err: errors.New("503 Service Unavailable")}
+	if _, err := GenerateTransactionWithClient(context.Background(), unavailable, OPMainnetChainID, fixtureParentSafe, 42, ""); err == nil || !strings.Contains(err.Error(), "503") {
+		t.Fatalf("expected the lookup error, got %v", err)
+	}
+}
+
+func TestFetchTransactionByHashWithClient(t *testing.T) {
+	server := newFixtureServer(t, defaultFixtureRoutes())
+	client := NewHTTPSafeClient(server.URL)
+
+	tx, err := FetchTransactionByHashWithClient(context.Background(), client, OPMainnetChainID, fixtureGrantsHash)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if tx.Safe != fixtureGrantsSafe || tx.Nonce != 155 {
+		t.Fatalf("unexpected transaction: %+v", tx)
+	}
+}
+
+func TestGenerateTransactionWithClientCancelled(t *testing.T) {
+	server := newFixtureServer(t, defaultFixtureRoutes())
+	client := NewHTTPSafeClient(server.URL)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := GenerateTransactionWithClient(ctx, client, OPMainnetChainID, fixtureGrantsSafe, 155, ""); !errors.Is(err, context.Canceled) {
+		t.Fatalf("GenerateTransactionWithClient error = %v, want context.Canceled", err)
+	}
+}
+
+func TestGenerateTransactionWithClientReplaced(t *testing.T) {
+	const (
+		rejectionHash = "0x72fffece9936d1d571eb46782721e25d99b81299613e5eb02da751b15bbca0fe"
+		transferHash  = "0x68706b75b7a067c2dfdc83c584fae78244ed87973892b97c568ad2bcae5e9750"
+	)
+	server := newFixtureServer(t, defaultFixtureRoutes())
+	client := NewHTTPSafeClient(server.URL)
+
+	// Without a safeTxHash, neither transaction is picked
+	_, err := GenerateTransactionWithClient(context.Background(), client, OPMainnetChainID, fixtureGrantsSafe, 157, "")
+	var conflict *NonceConflictError
+	if !errors.As(err, &conflict) {
+		t.Fatalf("expected a NonceConflictError, got %v", err)
+	}
+	if len(conflict.Candidates) != 2 || conflict.Candidates[0].SafeTxHash != rejectionHash || conflict.Candidates[1].SafeTxHash != transferHash {
+		t.Fatalf("unexpected candidates: %+v", conflict.Candidates)
+	}
+	// Each candidate is listed with who proposed it and when
+	if !strings.Contains(err.Error(), rejectionHash+" proposed by 0x9a69d97a451643a0Bb4462476942D2bC844431cE at 2025-03-12T09:30:02.000000Z") {
+		t.Errorf("candidates are not described: %v", err)
+	}
+
+	// The hash picks one regardless of case
+	tx, err := GenerateTransactionWithClient(context.Background(), client, OPMainnetChainID, fixtureGrantsSafe, 157, "0x"+strings.ToUpper(transferHash[2:]))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if tx.To != "0x4200000000000000000000000000000000000042" || len(tx.Replacements) != 1 || tx.Replacements[0].SafeTxHash != rejectionHash {
+		t.Fatalf("unexpected transaction: %+v", tx)
+	}
+
+	// Signers are warned that only one of the queued transactions can execute
+	result, err := VerifyTransaction(*tx, VerifyOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.ApproveHash != transferHash {
+		t.Fatalf("safe tx hash = %s, want %s", result.ApproveHash, transferHash)
+	}
+	found := false
+	for _, warning := range result.Warnings {
+		if warning.Severity == SeverityWarning && strings.Contains(warning.Message, rejectionHash) {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("expected a warning about the replacement, got %+v", result.Warnings)
+	}
+
+	if _, err := GenerateTransactionWithClient(context.Background(), client, OPMainnetChainID, fixtureGrantsSafe, 157, fixtureGrantsHash); err == nil {
+		t.Fatalf("expected an error for a hash not queued for the nonce")
+	}
+}
+
+func TestHTTPSafeClientPagination(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("testdata", "safe-api", "multisig-transactions-grants-157-replaced.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var all APIResponse
+	if err := json.Unmarshal(data, &all); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.ReadFile(filepath.Join("testdata", "safe-api", "safe-info-grants.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The service splits the candidates over two pages; the link to the second names another
+	// host, which is not followed
+	count := len(all.Results)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		list := "/service/api/v1/safes/" + fixtureGrantsSafe + "/multisig-transactions/"
+		var page APIResponse
+		switch r.URL.RequestURI() {
+		case "/service/api/v1/safes/" + fixtureGrantsSafe + "/":
+			w.Write(info)
+			return
+		case list + "?nonce=157":
+			page = APIResponse{Count: count, Next: "https://elsewhere.example" + list + "?limit=1&nonce=157&offset=1", Results: all.Results[:1]}
+		case list + "?limit=1&nonce=157&offset=1":
+			page = APIResponse{Count: count, Results: all.Results[1:]}
+		default:
+			http.NotFound(w, r)
+			return
+		}
+		json.NewEncoder(w).Encode(page)
+	}))
+	defer server.Close()
+	client := NewHTTPSafeClient(server.URL + "/service")
+
+	_, err = GenerateTransactionWithClient(context.Background(), client, OPMainnetChainID, fixtureGrantsSafe, 157, "")
+	var conflict *NonceConflictError
+	if !errors.As(err, &conflict) {
+		t.Fatalf("expected a NonceConflictError, got %v", err)
+	}
+	if len(conflict.Candidates) != 2 || conflict.Candidates[1].Proposer == "" || conflict.Candidates[1].Submitted == "" {
+		t.Fatalf("expected both pages of candidates, got %+v", conflict.Candidates)
+	}
+
+	// A list shorter than the service's count may be missing a candidate
+	count++
+	if _, err := GenerateTransactionWithClient(context.Background(), client, OPMainnetChainID, fixtureGrantsSafe, 157, ""); err == nil || !strings.Contains(err.Error(), "counts 3 transactions") {
+		t.Errorf("expected an incomplete list to be refused, got %v", err)
+	}
+}
+
+func TestAPIValueUnmarshal(t *testing.T) {
+	var v struct {
+		Number  APIValue `json:"number"`
+		String  APIValue `json:"string"`
+		Null    APIValue `json:"null"`
+		Missing APIValue `json:"missing"`
+	}
+	if err := json.Unmarshal([]byte(`{"number": 42, "string": "43", "null": null}`), &v); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if v.Number.Raw != "42" || !v.Number.Present || v.Number.Null {
+		t.Fatalf("unexpected number: %+v", v.Number)
+	}
+	if v.String.Raw != "43" || !v.String.Present {
+		t.Fatalf("unexpected string: %+v", v.String)
+	}
+	if !v.Null.Present || !v.Null.Null {
+		t.Fatalf("unexpected null: %+v", v.Null)
+	}
+	if v.Missing.Present {
+		t.Fatalf("unexpected missing: %+v", v.Missing)
+	}
+
+	if err := json.Unmarshal([]byte(`{"number": {"nested": true}}`), &v); err == nil {
+		t.Fatalf("expected error for object value")
+	}
+}
+
+func TestGenerateTransactionWithClientSparseResponse(t *testing.T) {
+	server := newFixtureServer(t, defaultFixtureRoutes())
+	client := NewHTTPSafeClient(server.URL)
+
+	tx, err := GenerateTransactionWithClient(context.Background(), client, OPMainnetChainID, fixtureGrantsSafe, 156, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if tx.Data != "0x" || tx.SafeTxGas != 0 || tx.BaseGas != 0 || tx.GasToken != ZeroAddress {
+		t.Fatalf("unexpected defaults: %+v", tx)
+	}
+
+	want := map[string]string{
+		"data":           FieldSourceNull,
+		"safeTxGas":      FieldSourceMissing,
+		"baseGas":        FieldSourceMissing,
+		"gasToken":       FieldSourceNull,
+		"gasPrice":       FieldSourceService,
+		"refundReceiver": FieldSourceService,
+		"value":          FieldSourceService,
+	}
+	for field, source := range want {
+		if tx.Provenance[field] != source {
+			t.Errorf("provenance[%s] = %q, want %q", field, tx.Provenance[field], source)
+		}
+	}
+}
+
+func TestBuildTransactionRequiresValue(t *testing.T) {
+	tx := APITransaction{
+		Safe:      fixtureGrantsSafe,
+		To:        APIValue{Raw: OPTokenAddress, Present: true},
+		Operation: APIValue{Raw: "0", Present: true},
+		Nonce:     APIValue{Raw: "1", Present: true},
+	}
+	if _, err := buildTransaction(context.Background(), nil, OPMainnetChainID, tx, &SafeInfoResponse{Version: "1.3.0"}); err == nil {
+		t.Fatalf("expected error for missing value")
+	}
+}
+
+func TestGeneratedNestedTransactionMatchesServiceHashes(t *testing.T) {
+	server := newFixtureServer(t, defaultFixtureRoutes())
+	client := NewHTTPSafeClient(server.URL)
+
+	tx, err := GenerateTransactionWithClient(context.Background(), client, OPMainnetChainID, fixtureParentSafe, 42, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	result, err := VerifyTransaction(*tx, VerifyOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Warnings) != 0 {
+		t.Fatalf("expected generated transaction to match service hashes, got %+v", result.Warnings)
+	}
+	if tx.Nested.Proposal == nil || tx.Nested.Proposal.Proposer == "" {
+		t.Fatalf("expected the child transaction's proposer, got %+v", tx.Nested.Proposal)
+	}
+	if isOwner, ok := tx.Nested.Proposal.ProposerIsOwner(); !ok || !isOwner {
+		t.Errorf("expected the fixture proposer to be an owner of the child Safe, got %v %v", isOwner, ok)
+	}
+}
@@ -0,0 +1,169 @@
+package core
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// BatchCall is a call of a batch transaction with its Merkle leaf
+type BatchCall struct {
+	// Index is the position of the call in the batch, starting at 1
+	Index       int             `json:"index"`
+	Transfer    AirdropTransfer `json:"transfer"`
+	Description string          `json:"description"`
+	Leaf        string          `json:"leaf"`
+}
+
+// BatchChunk is a range of calls that one reviewer checks on their own
+type BatchChunk struct {
+	Number int            `json:"number"`
+	First  int            `json:"first"`
+	Last   int            `json:"last"`
+	Root   string         `json:"root"`
+	Totals []AirdropTotal `json:"totals"`
+}
+
+// BatchCommitment commits to every call of a large batch transaction, so that reviewers who
+// split the calls between them can confirm they reviewed the same batch by comparing a single
+// value at the end.
+//
+// Each call is a Merkle leaf keccak256(0x00 || operation || to || value || keccak256(data)) and
+// each inner node is keccak256(0x01 || left || right), with an unpaired node carried up a level
+// unchanged. Chunk sizes are powers of two, so every chunk root is a subtree of the full tree.
+type BatchCommitment struct {
+	CallCount  int            `json:"callCount"`
+	OtherCalls int            `json:"otherCalls"`
+	ChunkSize  int            `json:"chunkSize"`
+	Root       string         `json:"root"`
+	Totals     []AirdropTotal `json:"totals"`
+	TotalsHash string         `json:"totalsHash"`
+
+	// Commitment is keccak256(root || totalsHash || uint256 callCount)
+	Commitment string       `json:"commitment"`
+	Chunks     []BatchChunk `json:"chunks"`
+	Calls      []BatchCall  `json:"calls"`
+}
+
+// Chunk returns the chunk with the given number, starting at 1
+func (c *BatchCommitment) Chunk(number int) (*BatchChunk, []BatchCall, error) {
+	if number < 1 || number > len(c.Chunks) {
+		return nil, nil, fmt.Errorf("chunk %d does not exist: the batch has %d chunks of %d calls", number, len(c.Chunks), c.ChunkSize)
+	}
+	chunk := &c.Chunks[number-1]
+	return chunk, c.Calls[chunk.First-1 : chunk.Last], nil
+}
+
+// CommitBatch computes the Merkle root and per-token totals of the calls a transaction makes.
+// chunkSize must be a power of two. Decimals (keyed by lowercase token address) scale the
+// displayed totals of tokens that are not known contracts; the commitment uses raw amounts.
+func CommitBatch(tx SafeTransaction, chunkSize int, decimals map[string]int) (*BatchCommitment, error) {
+	if chunkSize < 1 || chunkSize&(chunkSize-1) != 0 {
+		return nil, fmt.Errorf("chunk size must be a power of two, got %d", chunkSize)
+	}
+	calls, err := batchCalls(tx)
+	if err != nil {
+		return nil, err
+	}
+	tokenDecimals := airdropTokenDecimals(uint64(tx.Chain), decimals)
+
+	commitment := &BatchCommitment{CallCount: len(calls), ChunkSize: chunkSize}
+	leaves := make([][]byte, len(calls))
+	transfers := make([]AirdropTransfer, len(calls))
+	for i, call := range calls {
+		leaves[i] = batchLeaf(call)
+		transfers[i] = airdropTransfer(i+1, call)
+
+		description := fmt.Sprintf("call to %s with %s ETH (not a transfer)", call.To.Hex(), ParseDecimals(call.Value, 18))
+		if transfers[i].TokenType != "" {
+			description = fmt.Sprintf("%s to %s", formatAirdropAmount(transfers[i], tokenDecimals), transfers[i].Receiver)
+		} else {
+			commitment.OtherCalls++
+		}
+		commitment.Calls = append(commitment.Calls, BatchCall{
+			Index:       i + 1,
+			Transfer:    transfers[i],
+			Description: description,
+			Leaf:        common.BytesToHash(leaves[i]).Hex(),
+		})
+	}
+
+	for first := 0; first < len(calls); first += chunkSize {
+		last := first + chunkSize
+		if last > len(calls) {
+			last = len(calls)
+		}
+		commitment.Chunks = append(commitment.Chunks, BatchChunk{
+			Number: len(commitment.Chunks) + 1,
+			First:  first + 1,
+			Last:   last,
+			Root:   common.BytesToHash(merkleRoot(leaves[first:last])).Hex(),
+			Totals: airdropTotals(transfers[first:last], tokenDecimals),
+		})
+	}
+
+	root := merkleRoot(leaves)
+	commitment.Totals = airdropTotals(transfers, tokenDecimals)
+	totalsHash := hashBatchTotals(commitment.Totals)
+	commitment.Root = common.BytesToHash(root).Hex()
+	commitment.TotalsHash = common.BytesToHash(totalsHash).Hex()
+	commitment.Commitment = crypto.Keccak256Hash(root, totalsHash, math.U256Bytes(big.NewInt(int64(len(calls))))).Hex()
+	return commitment, nil
+}
+
+// batchLeaf hashes a call as a Merkle leaf
+func batchLeaf(call multiSendTransaction) []byte {
+	return crypto.Keccak256(
+		[]byte{0x00, call.Operation},
+		call.To.Bytes(),
+		math.U256Bytes(new(big.Int).Set(call.Value)),
+		crypto.Keccak256(call.Data),
+	)
+}
+
+// merkleRoot computes the root of a tree over leaves, carrying an unpaired node up unchanged.
+// The root of no leaves is the zero hash.
+func merkleRoot(leaves [][]byte) []byte {
+	if len(leaves) == 0 {
+		return make([]byte, 32)
+	}
+	level := leaves
+	for len(level) > 1 {
+		next := make([][]byte, 0, (len(level)+1)/2)
+		for i := 0; i < len(level); i += 2 {
+			if i+1 == len(level) {
+				next = append(next, level[i])
+				continue
+			}
+			next = append(next, crypto.Keccak256([]byte{0x01}, level[i], level[i+1]))
+		}
+		level = next
+	}
+	return level[0]
+}
+
+// hashBatchTotals hashes per-token raw totals in a canonical order: by token type, then by
+// lowercase token address
+func hashBatchTotals(totals []AirdropTotal) []byte {
+	sorted := append([]AirdropTotal{}, totals...)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].TokenType != sorted[j].TokenType {
+			return sorted[i].TokenType < sorted[j].TokenType
+		}
+		return strings.ToLower(sorted[i].Token) < strings.ToLower(sorted[j].Token)
+	})
+
+	var encoded []byte
+	for _, total := range sorted {
+		encoded = append(encoded, crypto.Keccak256([]byte(total.TokenType))...)
+		encoded = append(encoded, common.LeftPadBytes(common.HexToAddress(total.Token).Bytes(), 32)...)
+		encoded = append(encoded, math.U256Bytes(big.NewInt(int64(total.Transfers)))...)
+		encoded = append(encoded, math.U256Bytes(new(big.Int).Set(total.Raw))...)
+	}
+	return crypto.Keccak256(encoded)
+}
@@ -0,0 +1,102 @@
+package core
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func commitmentBatch(t *testing.T, lastAmount int64) SafeTransaction {
+	return multiSendTx(t,
+		nativeTransfer(airdropAlice, big.NewInt(1e18)),
+		erc20Transfer(USDCMainnetAddress, airdropBob, big.NewInt(2_500_000)),
+		nativeTransfer(airdropBob, big.NewInt(5e17)),
+		erc20Transfer(USDCMainnetAddress, airdropAlice, big.NewInt(500_000)),
+		multiSendTransaction{To: common.HexToAddress(airdropToken), Value: big.NewInt(0), Data: []byte{0xde, 0xad, 0xbe, 0xef}},
+		erc20Transfer(USDCMainnetAddress, airdropBob, big.NewInt(lastAmount)),
+	)
+}
+
+func TestCommitBatchChunkRootsComposeIntoRoot(t *testing.T) {
+	for _, chunkSize := range []int{1, 2, 4, 8} {
+		commitment, err := CommitBatch(commitmentBatch(t, 1), chunkSize, nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		var roots [][]byte
+		for _, chunk := range commitment.Chunks {
+			roots = append(roots, common.FromHex(chunk.Root))
+		}
+		if got := common.BytesToHash(merkleRoot(roots)).Hex(); got != commitment.Root {
+			t.Errorf("chunk size %d: chunk roots combine to %s, want %s", chunkSize, got, commitment.Root)
+		}
+		if want := (6 + chunkSize - 1) / chunkSize; len(commitment.Chunks) != want {
+			t.Errorf("chunk size %d: got %d chunks, want %d", chunkSize, len(commitment.Chunks), want)
+		}
+	}
+}
+
+func TestCommitBatchTotals(t *testing.T) {
+	commitment, err := CommitBatch(commitmentBatch(t, 1_000_000), 4, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if commitment.CallCount != 6 || commitment.OtherCalls != 1 {
+		t.Errorf("calls = %d, other calls = %d", commitment.CallCount, commitment.OtherCalls)
+	}
+	if len(commitment.Totals) != 2 || commitment.Totals[0].Raw.String() != "1500000000000000000" || commitment.Totals[1].Amount != "4.00" {
+		t.Errorf("unexpected totals: %+v", commitment.Totals)
+	}
+
+	chunk, calls, err := commitment.Chunk(2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if chunk.First != 5 || chunk.Last != 6 || len(calls) != 2 || calls[0].Index != 5 {
+		t.Errorf("chunk 2 = %+v with %d calls", chunk, len(calls))
+	}
+	if len(chunk.Totals) != 1 || chunk.Totals[0].Raw.String() != "1000000" {
+		t.Errorf("unexpected chunk totals: %+v", chunk.Totals)
+	}
+	if _, _, err := commitment.Chunk(3); err == nil {
+		t.Error("expected an error for a chunk past the end")
+	}
+}
+
+func TestCommitBatchDetectsChanges(t *testing.T) {
+	a, err := CommitBatch(commitmentBatch(t, 1), 2, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	b, err := CommitBatch(commitmentBatch(t, 2), 2, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if a.Root == b.Root || a.TotalsHash == b.TotalsHash || a.Commitment == b.Commitment {
+		t.Error("changing an amount should change the commitment")
+	}
+	// Only the chunk holding the changed call differs
+	for i := range a.Chunks {
+		if (a.Chunks[i].Root == b.Chunks[i].Root) == (i == 2) {
+			t.Errorf("chunk %d root changed = %v", i+1, a.Chunks[i].Root != b.Chunks[i].Root)
+		}
+	}
+
+	// The chunk size does not change the commitment
+	c, err := CommitBatch(commitmentBatch(t, 1), 8, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if c.Commitment != a.Commitment {
+		t.Error("the commitment should not depend on the chunk size")
+	}
+}
+
+func TestCommitBatchRejectsInvalidChunkSize(t *testing.T) {
+	for _, size := range []int{0, 3, 100} {
+		if _, err := CommitBatch(commitmentBatch(t, 1), size, nil); err == nil {
+			t.Errorf("expected an error for chunk size %d", size)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package core
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Names of the hashes a signer compares, in the order they are displayed
+const (
+	HashDomain  = "domain"
+	HashMessage = "message"
+	HashSafeTx  = "safeTx"
+)
+
+// HashNames lists the comparable hashes in display order
+var HashNames = []string{HashDomain, HashMessage, HashSafeTx}
+
+// hashLabels maps normalized labels (as printed by the terminal and JSON outputs) to hash names
+var hashLabels = map[string]string{
+	"domainhash":  HashDomain,
+	"messagehash": HashMessage,
+	"safetxhash":  HashSafeTx,
+	"approvehash": HashSafeTx,
+}
+
+var (
+	hashPattern = regexp.MustCompile(`(?i)\b(?:0x)?([0-9a-f]{64})\b`)
+	ansiPattern = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+	labelStrip  = strings.NewReplacer(" ", "", "_", "", "\"", "", "'", "", "*", "", "-", "")
+)
+
+// HashSet is the set of hashes found in one signer's output
+type HashSet struct {
+	Source string            `json:"source"`
+	Hashes map[string]string `json:"hashes"`
+}
+
+// ParseHashSet extracts hashes from pasted tool output. Labeled hashes ("Domain Hash: 0x...",
+// "safeTxHash": "0x...") are used when present, taking the first occurrence of each label so that
+// nested results are ignored. Otherwise bare hashes are assigned by position: three hashes are
+// domain, message, and Safe tx hash; two are domain and message (as shown by hardware wallets);
+// one is the Safe tx hash. Hashes are normalized to lowercase with a 0x prefix.
+func ParseHashSet(source, text string) (HashSet, error) {
+	set := HashSet{Source: source, Hashes: map[string]string{}}
+	var unlabeled []string
+
+	for _, line := range strings.Split(ansiPattern.ReplaceAllString(text, ""), "\n") {
+		matches := hashPattern.FindAllStringSubmatchIndex(line, -1)
+		if len(matches) == 0 {
+			continue
+		}
+
+		label := strings.ToLower(labelStrip.Replace(line[:matches[0][0]]))
+		if name, ok := matchHashLabel(label); ok {
+			if _, seen := set.Hashes[name]; !seen {
+				set.Hashes[name] = "0x" + strings.ToLower(line[matches[0][2]:matches[0][3]])
+			}
+			continue
+		}
+		if strings.TrimSpace(label) == "" {
+			for _, m := range matches {
+				unlabeled = append(unlabeled, "0x"+strings.ToLower(line[m[2]:m[3]]))
+			}
+		}
+	}
+
+	if len(set.Hashes) > 0 {
+		return set, nil
+	}
+
+	switch len(unlabeled) {
+	case 1:
+		set.Hashes[HashSafeTx] = unlabeled[0]
+	case 2:
+		set.Hashes[HashDomain] = unlabeled[0]
+		set.Hashes[HashMessage] = unlabeled[1]
+	case 3:
+		set.Hashes[HashDomain] = unlabeled[0]
+		set.Hashes[HashMessage] = unlabeled[1]
+		set.Hashes[HashSafeTx] = unlabeled[2]
+	case 0:
+		return set, fmt.Errorf("%s: no hashes found", source)
+	default:
+		return set, fmt.Errorf("%s: found %d unlabeled hashes; label them (e.g. \"Domain Hash: 0x...\") or paste at most three", source, len(unlabeled))
+	}
+	return set, nil
+}
+
+// matchHashLabel returns the hash name for a normalized line prefix such as "domainhash:"
+func matchHashLabel(prefix string) (string, bool) {
+	prefix = strings.TrimRight(prefix, ":=,{")
+	for label, name := range hashLabels {
+		if prefix == label {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// HashComparison is the result of comparing one hash across several sources
+type HashComparison struct {
+	Name   string            `json:"name"`
+	Match  bool              `json:"match"`
+	Values map[string]string `json:"values"`
+
+	// Missing lists the sources that did not report this hash
+	Missing []string `json:"missing,omitempty"`
+}
+
+// CompareHashSets compares every hash reported by at least two sources. A hash matches when all
+// sources that report it agree.
+func CompareHashSets(sets []HashSet) []HashComparison {
+	var comparisons []HashComparison
+	for _, name := range HashNames {
+		comparison := HashComparison{Name: name, Match: true, Values: map[string]string{}}
+		var first string
+		for _, set := range sets {
+			value, ok := set.Hashes[name]
+			if !ok {
+				comparison.Missing = append(comparison.Missing, set.Source)
+				continue
+			}
+			comparison.Values[set.Source] = value
+			if first == "" {
+				first = value
+			} else if value != first {
+				comparison.Match = false
+			}
+		}
+		if len(comparison.Values) >= 2 {
+			comparisons = append(comparisons, comparison)
+		}
+	}
+	return comparisons
+}
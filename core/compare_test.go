@@ -0,0 +1,81 @@
+package core
+
+import (
+	"strings"
+	"testing"
+)
+
+const (
+	testDomainHash  = "0xb34978142f4478f3e5633915597a756daa58a1a59a3e0234f9acd5444f1ca70e"
+	testMessageHash = "0xc1613ba7c92e30a3facf5747b8cc8f8a5c8f135b5291c0d78b2fcffc838cc7e5"
+	testSafeTxHash  = "0x19767d264966e39d532d998c5354f76ad5102407124b1885d69bf23f791b6f4c"
+)
+
+func TestParseHashSetLabeled(t *testing.T) {
+	terminal := "\x1b[35m\x1b[1mDomain Hash\x1b[0m:  " + strings.ToUpper(testDomainHash[2:]) + "\n" +
+		"Message Hash: " + testMessageHash + "\n" +
+		"Safe Tx Hash: " + testSafeTxHash + "\n" +
+		"Child Hash: 0x" + strings.Repeat("11", 32) + "\n"
+
+	json := `{
+  "transaction": {"service_safe_tx_hash": "0x` + strings.Repeat("22", 32) + `"},
+  "domainHash": "` + testDomainHash + `",
+  "messageHash": "` + testMessageHash + `",
+  "approveHash": "` + testSafeTxHash + `",
+  "nestedResult": {"domainHash": "0x` + strings.Repeat("33", 32) + `"}
+}`
+
+	for name, text := range map[string]string{"terminal": terminal, "json": json} {
+		set, err := ParseHashSet(name, text)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", name, err)
+		}
+		if set.Hashes[HashDomain] != testDomainHash || set.Hashes[HashMessage] != testMessageHash || set.Hashes[HashSafeTx] != testSafeTxHash {
+			t.Errorf("%s: unexpected hashes %+v", name, set.Hashes)
+		}
+	}
+}
+
+func TestParseHashSetPositional(t *testing.T) {
+	set, err := ParseHashSet("block", testDomainHash+"\n"+testMessageHash+"\n"+testSafeTxHash)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if set.Hashes[HashDomain] != testDomainHash || set.Hashes[HashSafeTx] != testSafeTxHash {
+		t.Errorf("unexpected hashes %+v", set.Hashes)
+	}
+
+	set, err = ParseHashSet("single", "  "+testSafeTxHash[2:]+"  ")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(set.Hashes) != 1 || set.Hashes[HashSafeTx] != testSafeTxHash {
+		t.Errorf("single hash should be the Safe tx hash, got %+v", set.Hashes)
+	}
+
+	if _, err := ParseHashSet("empty", "nothing here"); err == nil {
+		t.Errorf("expected error for text without hashes")
+	}
+	if _, err := ParseHashSet("many", strings.Repeat(testSafeTxHash+"\n", 4)); err == nil {
+		t.Errorf("expected error for too many unlabeled hashes")
+	}
+}
+
+func TestCompareHashSets(t *testing.T) {
+	sets := []HashSet{
+		{Source: "alice", Hashes: map[string]string{HashDomain: testDomainHash, HashMessage: testMessageHash, HashSafeTx: testSafeTxHash}},
+		{Source: "bob", Hashes: map[string]string{HashDomain: testDomainHash, HashMessage: testMessageHash}},
+		{Source: "carol", Hashes: map[string]string{HashDomain: testDomainHash, HashMessage: testDomainHash}},
+	}
+
+	comparisons := CompareHashSets(sets)
+	if len(comparisons) != 2 {
+		t.Fatalf("expected domain and message comparisons only, got %+v", comparisons)
+	}
+	if !comparisons[0].Match || comparisons[0].Name != HashDomain {
+		t.Errorf("domain hashes should match: %+v", comparisons[0])
+	}
+	if comparisons[1].Match || comparisons[1].Name != HashMessage {
+		t.Errorf("message hashes should not match: %+v", comparisons[1])
+	}
+}
@@ -0,0 +1,146 @@
+package core
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// ComplianceSchema identifies the layout of a ComplianceSummary. It changes whenever a field is
+// added, removed, or changes meaning, so archived summaries can always be read back.
+const ComplianceSchema = "op-txverify/compliance/v1"
+
+// ValueFlow is an asset a transaction sends or allows to be spent, in a fixed layout for
+// compliance review. Every field is always present; Decimals is null for tokens whose decimals
+// are not known, and Amount is then in raw units.
+type ValueFlow struct {
+	ChainID      uint64 `json:"chainId"`
+	Network      string `json:"network"`
+	From         string `json:"from"`
+	Type         string `json:"type"`
+	Asset        string `json:"asset"`
+	AssetAddress string `json:"assetAddress"`
+	Decimals     *int   `json:"decimals"`
+	Amount       string `json:"amount"`
+	RawAmount    string `json:"rawAmount"`
+	Counterparty string `json:"counterparty"`
+	Label        string `json:"label"`
+}
+
+// ComplianceSummary lists the external value flows of a transaction, separately from the
+// technical verification detail, for compliance teams to archive
+type ComplianceSummary struct {
+	Schema     string      `json:"schema"`
+	ChainID    uint64      `json:"chainId"`
+	Network    string      `json:"network"`
+	Safe       string      `json:"safe"`
+	Nonce      int         `json:"nonce"`
+	SafeTxHash string      `json:"safeTxHash"`
+	Flows      []ValueFlow `json:"flows"`
+
+	// Notes name the calls whose value flows could not be determined from the calldata, such as
+	// delegatecalls into unknown code, so an empty list of flows is never mistaken for none
+	Notes []string `json:"notes"`
+}
+
+// SummarizeValueFlows summarizes the native transfers, token transfers, and token approvals a
+// transaction makes, including those of an approved child transaction. Counterparties are
+// labelled with known contract and address book names.
+func SummarizeValueFlows(result *VerificationResult, book *AddressRegistry) (*ComplianceSummary, error) {
+	tx := result.Transaction
+	summary := &ComplianceSummary{
+		Schema:     ComplianceSchema,
+		ChainID:    uint64(tx.Chain),
+		Network:    ChainNames[uint64(tx.Chain)],
+		Safe:       ChecksumAddress(tx.Safe),
+		Nonce:      tx.Nonce,
+		SafeTxHash: result.ApproveHash,
+		Flows:      []ValueFlow{},
+		Notes:      []string{},
+	}
+
+	for _, r := range []*VerificationResult{result, result.NestedResult} {
+		if r == nil {
+			continue
+		}
+		flows, notes, err := valueFlows(r.Transaction, book)
+		if err != nil {
+			return nil, err
+		}
+		summary.Flows = append(summary.Flows, flows...)
+		summary.Notes = append(summary.Notes, notes...)
+	}
+	return summary, nil
+}
+
+// valueFlows returns the value flows of the calls a single Safe transaction makes
+func valueFlows(tx SafeTransaction, book *AddressRegistry) ([]ValueFlow, []string, error) {
+	chainID := uint64(tx.Chain)
+	effects, err := expectedEffects(tx)
+	if err != nil {
+		return nil, nil, err
+	}
+	calls, err := batchCalls(tx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var notes []string
+	for i, call := range calls {
+		if call.Operation == 0 {
+			continue
+		}
+		target := call.To.Hex()
+		if name := complianceLabel(target, chainID, book); name != "" {
+			target = fmt.Sprintf("%s (%s)", target, name)
+		}
+		subcall := ""
+		if len(calls) > 1 {
+			subcall = fmt.Sprintf(" #%d", i+1)
+		}
+		notes = append(notes, fmt.Sprintf("%s%s delegatecalls %s; any value it moves is not listed", ChecksumAddress(tx.Safe), subcall, target))
+	}
+
+	flows := make([]ValueFlow, 0, len(effects))
+	for _, effect := range effects {
+		flow := ValueFlow{
+			ChainID:      chainID,
+			Network:      ChainNames[chainID],
+			From:         effect.From,
+			Type:         effect.Kind,
+			Amount:       effect.Amount.String(),
+			RawAmount:    effect.Amount.String(),
+			Counterparty: ChecksumAddress(effect.To),
+			Label:        complianceLabel(effect.To, chainID, book),
+		}
+		if effect.Kind == EffectNative {
+			decimals := 18
+			flow.Asset, flow.Decimals = "ETH", &decimals
+		} else {
+			flow.Asset, flow.AssetAddress = ChecksumAddress(effect.Token), ChecksumAddress(effect.Token)
+			if info, ok := GetKnownContract(effect.Token, chainID); ok {
+				flow.Asset = info.Name
+				if info.Decimals > 0 {
+					decimals := info.Decimals
+					flow.Decimals = &decimals
+				}
+			}
+		}
+		if flow.Decimals != nil {
+			flow.Amount = ParseDecimals(new(big.Int).Set(effect.Amount), *flow.Decimals)
+		}
+		flows = append(flows, flow)
+	}
+	return flows, notes, nil
+}
+
+// complianceLabel names an address from the known contracts or the address book
+func complianceLabel(address string, chainID uint64, book *AddressRegistry) string {
+	if info, ok := GetKnownContract(strings.ToLower(address), chainID); ok {
+		return info.Name
+	}
+	if entry, ok := book.Lookup(address, chainID); ok {
+		return entry.Name
+	}
+	return ""
+}
@@ -0,0 +1,77 @@
+package core
+
+import (
+	"encoding/json"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+)
+
+func TestSummarizeValueFlows(t *testing.T) {
+	approve := append(append([]byte{}, erc20ApproveSelector...), common.LeftPadBytes(common.HexToAddress(airdropBob).Bytes(), 32)...)
+	approve = append(approve, math.U256Bytes(big.NewInt(500))...)
+	tx := multiSendTx(t,
+		erc20Transfer(USDCMainnetAddress, airdropAlice, big.NewInt(2_500_000)),
+		nativeTransfer(airdropBob, big.NewInt(1e18)),
+		multiSendTransaction{To: common.HexToAddress(airdropToken), Value: big.NewInt(0), Data: approve},
+		multiSendTransaction{Operation: 1, To: common.HexToAddress(airdropToken), Value: big.NewInt(0), Data: common.FromHex("0xdeadbeef")},
+	)
+	tx.Safe, tx.SafeVersion, tx.Nonce = effectsSafe, "1.3.0", 4
+
+	book := NewAddressRegistry()
+	if err := book.ParseAddressBook("book", []byte(airdropBob+" Payroll")); err != nil {
+		t.Fatal(err)
+	}
+	result, err := VerifyTransaction(tx, VerifyOptions{AddressBook: book, Compliance: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	summary := result.Compliance
+	if summary == nil || summary.Schema != ComplianceSchema || summary.Network != "Ethereum" || summary.Nonce != 4 || summary.SafeTxHash != result.ApproveHash {
+		t.Fatalf("unexpected summary: %+v", summary)
+	}
+	if len(summary.Flows) != 3 {
+		t.Fatalf("unexpected flows: %+v", summary.Flows)
+	}
+	usdc, eth, approval := summary.Flows[0], summary.Flows[1], summary.Flows[2]
+	if usdc.Type != EffectTransfer || usdc.Asset != "USDC" || usdc.Amount != "2.5" || usdc.RawAmount != "2500000" || usdc.Counterparty != common.HexToAddress(airdropAlice).Hex() {
+		t.Errorf("unexpected USDC flow: %+v", usdc)
+	}
+	if eth.Type != EffectNative || eth.Asset != "ETH" || eth.Amount != "1.00" || eth.Label != "Payroll" {
+		t.Errorf("unexpected ETH flow: %+v", eth)
+	}
+	if approval.Type != EffectApproval || approval.Decimals != nil || approval.Amount != "500" || approval.AssetAddress != common.HexToAddress(airdropToken).Hex() {
+		t.Errorf("unexpected approval flow: %+v", approval)
+	}
+	if len(summary.Notes) != 1 || !strings.Contains(summary.Notes[0], "#4 delegatecalls") {
+		t.Errorf("unexpected notes: %v", summary.Notes)
+	}
+
+	// Every field of the fixed schema is present, even when empty
+	data, err := json.Marshal(approval)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, field := range []string{`"decimals":null`, `"label":"Payroll"`, `"network":"Ethereum"`} {
+		if !strings.Contains(string(data), field) {
+			t.Errorf("flow JSON missing %s: %s", field, data)
+		}
+	}
+
+	// The value flows of an approved child transaction are included
+	parent, err := VerifyTransaction(ceremonyApproval(t, tx, 12), VerifyOptions{Compliance: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if flows := parent.Compliance.Flows; len(flows) != 3 || flows[0].From != common.HexToAddress(effectsSafe).Hex() || parent.Compliance.Safe != common.HexToAddress(fixtureParentSafe).Hex() {
+		t.Errorf("unexpected child flows: %+v", parent.Compliance)
+	}
+
+	if result, _ := VerifyTransaction(tx, VerifyOptions{}); result.Compliance != nil {
+		t.Error("compliance summary added without the option")
+	}
+}
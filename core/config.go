@@ -0,0 +1,106 @@
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Config is the op-txverify configuration file
+type Config struct {
+	// RPC maps chain IDs to the JSON-RPC endpoints used for on-chain checks, in order of
+	// preference
+	RPC map[uint64][]string `json:"rpc"`
+
+	// Registry lists the signed label, token, ABI, and hash files that `registry sync` downloads
+	Registry *RegistrySources `json:"registry,omitempty"`
+
+	// Roles are AccessControl role names, such as "TREASURY_ROLE", that name their role hashes
+	// alongside the common ones
+	Roles []string `json:"roles,omitempty"`
+
+	// ServiceMirrors maps chain IDs to other deployments of the Safe Transaction Service, such as
+	// a self-hosted one, that are asked in order when the service fails
+	ServiceMirrors map[uint64][]string `json:"serviceMirrors,omitempty"`
+
+	// Theme is the color theme of the terminal output, such as "high-contrast"
+	Theme string `json:"theme,omitempty"`
+
+	// NoExec forbids starting other programs, such as the browser, pager, and clipboard, for
+	// signer machines whose sandbox does not let processes spawn others
+	NoExec bool `json:"noExec,omitempty"`
+}
+
+// DefaultConfigPath returns the configuration file that is loaded automatically when present
+func DefaultConfigPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "op-txverify", "config.json"), nil
+}
+
+// ParseConfig parses and validates a configuration file, such as
+// {"rpc": {"1": ["https://eth.example"], "10": ["https://op.example", "https://op-backup.example"]},
+// "registry": {"labels": "https://.../labels.json", "signers": ["0x..."]}, "roles": ["TREASURY_ROLE"],
+// "serviceMirrors": {"10": ["https://safe-mirror.example"]}, "theme": "high-contrast",
+// "noExec": true}
+func ParseConfig(source string, data []byte) (*Config, error) {
+	var config Config
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("invalid config %s: %w", source, err)
+	}
+	for chainID, urls := range config.RPC {
+		if len(urls) == 0 {
+			return nil, fmt.Errorf("%s: no RPC URLs for chain %d", source, chainID)
+		}
+		for _, url := range urls {
+			if !strings.HasPrefix(url, "https://") && !strings.HasPrefix(url, "http://") {
+				return nil, fmt.Errorf("%s: RPC URL for chain %d must use http or https: %s", source, chainID, url)
+			}
+		}
+	}
+	for chainID, urls := range config.ServiceMirrors {
+		for _, url := range urls {
+			if !strings.HasPrefix(url, "https://") && !strings.HasPrefix(url, "http://") {
+				return nil, fmt.Errorf("%s: Safe service mirror for chain %d must use http or https: %s", source, chainID, url)
+			}
+		}
+	}
+	if config.Registry != nil {
+		if err := config.Registry.Validate(); err != nil {
+			return nil, fmt.Errorf("%s: %w", source, err)
+		}
+	}
+	for _, role := range config.Roles {
+		if role == "" || strings.TrimSpace(role) != role {
+			return nil, fmt.Errorf("%s: invalid role name %q", source, role)
+		}
+	}
+	return &config, nil
+}
+
+// LoadConfigFile loads a configuration file. When optional, as for the default path, a missing
+// file is an empty configuration.
+func LoadConfigFile(path string, optional bool) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if optional && errors.Is(err, fs.ErrNotExist) {
+			return &Config{}, nil
+		}
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+	return ParseConfig(path, data)
+}
+
+// RPCURLs returns the configured RPC endpoints of a chain
+func (c *Config) RPCURLs(chainID uint64) []string {
+	if c == nil {
+		return nil
+	}
+	return c.RPC[chainID]
+}
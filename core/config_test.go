@@ -0,0 +1,52 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseConfig(t *testing.T) {
+	config, err := ParseConfig("config.json", []byte(`{"rpc": {"1": ["https://eth.example"], "10": ["https://op.example", "http://localhost:8545"]}}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if urls := config.RPCURLs(OPMainnetChainID); len(urls) != 2 || urls[0] != "https://op.example" {
+		t.Errorf("unexpected OP Mainnet URLs: %v", urls)
+	}
+	if urls := config.RPCURLs(BaseMainnetChainID); urls != nil {
+		t.Errorf("unexpected Base URLs: %v", urls)
+	}
+
+	for name, data := range map[string]string{
+		"not json":        `rpc = 1`,
+		"bad chain ID":    `{"rpc": {"op": ["https://op.example"]}}`,
+		"no URLs":         `{"rpc": {"10": []}}`,
+		"not http":        `{"rpc": {"10": ["ws://op.example"]}}`,
+		"not http mirror": `{"serviceMirrors": {"10": ["ftp://safe.example"]}}`,
+		"http registry":   `{"registry": {"labels": "http://labels.example", "signers": ["` + airdropAlice + `"]}}`,
+		"no signers":      `{"registry": {"labels": "https://labels.example"}}`,
+		"empty role":      `{"roles": [""]}`,
+	} {
+		if _, err := ParseConfig("config.json", []byte(data)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestLoadConfigFileMissing(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if config, err := LoadConfigFile(path, true); err != nil || len(config.RPC) != 0 {
+		t.Fatalf("optional missing config: %+v, %v", config, err)
+	}
+	if _, err := LoadConfigFile(path, false); err == nil {
+		t.Fatal("expected an error for a missing --config file")
+	}
+
+	if err := os.WriteFile(path, []byte(`{"rpc": {"10": ["https://op.example"]}}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if config, err := LoadConfigFile(path, true); err != nil || len(config.RPCURLs(OPMainnetChainID)) != 1 {
+		t.Fatalf("unexpected config: %+v, %v", config, err)
+	}
+}
@@ -14,6 +14,7 @@ const (
 	DomainSeparatorTypehashOld = "0x035aff83d86937d35b32e04f0ddc6ff469290eef2f1b692d8a815c89404d4749"
 	SafeTxTypehash             = "0xbb8310d486368db6bd6f849402fdd73ad53d316b5a4b2644ad6efe0f941286d8"
 	SafeTxTypehashOld          = "0x14d461bc7412367e924637b363c7bf29b8f47e2f84869f4426e5633d8af47b20"
+	SafeMessageTypehash        = "0x60b3cbf8b4a223d68d641b3b6ddf9a298e7f33710cf3d3a9d1146b5a6150fbca"
 
 	// Function signatures needed for parseMulticall
 	SafeMultisendSig   = "multiSend(bytes)"
@@ -23,34 +24,77 @@ const (
 
 // Known contract addresses
 const (
-	SafeMultisendAddress     = "0xA1dabEF33b3B82c7814B6D82A79e50F4AC44102B"
-	SafeMultisendCallOnly130 = "0x40A2aCCbd92BCA938b02010E17A5b8929b49130D"
-	SafeMultisendCallOnly141 = "0x9641d764fc13c8B624c04430C7356C1C7C8102e2"
-	Multicall3Address        = "0xcA11bde05977b3631167028862bE2a173976CA11"
-	Multicall3Delegatecall   = "0x93dc480940585D9961bfcEab58124fFD3d60f76a"
-	USDCMainnetAddress       = "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48"
-	OPTokenAddress           = "0x4200000000000000000000000000000000000042"
-	SuperfluidOP             = "0x1828Bff08BD244F7990edDCd9B19cc654b33cDB4"
-	OptimismGovernor         = "0xcDF27F107725988f2261Ce2256bDfCdE8B382B10"
-	OPGrants1                = "0x2501c477D0A35545a387Aa4A3EEe4292A9a8B3F0"
-	OPGrants2                = "0x19793c7824Be70ec58BB673CA42D2779d12581BE"
-	ProxyAdminOwner          = "0x5a0Aae59D09fccBdDb6C6CcEB07B7279367C3d2A"
-	OptimismPortal           = "0xbEb5Fc579115071764c7423A4f12eDde41f106Ed"
-	OPCMv220Mainnet          = "0x1C7BFA38a25ad22caFC556A9BD827E1da7eC1791"
-	OPCMv300Mainnet          = "0x3A1f523a4bc09cd344A2745a108Bb0398288094F"
-	OPCMv410Mainnet          = "0x8123739C1368C2DEDc8C564255bc417FEEeBFF9D"
-	OPCMv500Mainnet          = "0xFa1Ef97fb02B0dA2Ee2346b8e310907ab5519449"
-	OPCMv220Sepolia          = "0x6b6f9129efb1b7a48f84e3b787333d1dca02ee34"
-	OPCMv300Sepolia          = "0xfBceeD4DE885645fBdED164910E10F52fEBFAB35"
-	OPCMv410Sepolia          = "0x3bb6437aba031afbf9cb3538fa064161e2bf2d78"
-	OPCMv500Sepolia          = "0xC69e4c24Db479191676611a25D977203c3BDca62"
-	CCTPv2                   = "0x28b5a0e9C621a5BadaA536219b3a228C8168cf5d"
-	OPL1StandardBridge       = "0x99C9fc46f92E8a1c0deC1b1747d010903E884bE1"
-	OPL2StandardBridge       = "0x4200000000000000000000000000000000000010"
-	SaferSafes               = "0xA8447329e52F64AED2bFc9E7a2506F7D369f483a"
-	SafeMigration141         = "0x526643F69b81B008F46d95CD5ced5eC0edFFDaC6"
-	SafeMasterCopy141        = "0x41675c099f32341bf84bfc5382af534df5c7461a"
-	SafeFallbackHandler141   = "0xfd0732dc9e303f09fcef3a7388ad10a83459ec99"
+	SafeMultisendAddress      = "0xA1dabEF33b3B82c7814B6D82A79e50F4AC44102B"
+	SafeMultisendCallOnly130  = "0x40A2aCCbd92BCA938b02010E17A5b8929b49130D"
+	SafeMultisendCallOnly141  = "0x9641d764fc13c8B624c04430C7356C1C7C8102e2"
+	Multicall3Address         = "0xcA11bde05977b3631167028862bE2a173976CA11"
+	Multicall3Delegatecall    = "0x93dc480940585D9961bfcEab58124fFD3d60f76a"
+	USDCMainnetAddress        = "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48"
+	OPTokenAddress            = "0x4200000000000000000000000000000000000042"
+	SuperfluidOP              = "0x1828Bff08BD244F7990edDCd9B19cc654b33cDB4"
+	OptimismGovernor          = "0xcDF27F107725988f2261Ce2256bDfCdE8B382B10"
+	OPGrants1                 = "0x2501c477D0A35545a387Aa4A3EEe4292A9a8B3F0"
+	OPGrants2                 = "0x19793c7824Be70ec58BB673CA42D2779d12581BE"
+	ProxyAdminOwner           = "0x5a0Aae59D09fccBdDb6C6CcEB07B7279367C3d2A"
+	OptimismPortal            = "0xbEb5Fc579115071764c7423A4f12eDde41f106Ed"
+	BaseOptimismPortal        = "0x49048044D57e1C92A77f79988d21Fa8fAF74E97e"
+	OPSepoliaOptimismPortal   = "0x16Fc5058F25648194471939df75CF27A2fdC48BC"
+	BaseSepoliaOptimismPortal = "0x49f53e41452C74589E85cA1677426Ba426459e85"
+	OPCMv220Mainnet           = "0x1C7BFA38a25ad22caFC556A9BD827E1da7eC1791"
+	OPCMv300Mainnet           = "0x3A1f523a4bc09cd344A2745a108Bb0398288094F"
+	OPCMv410Mainnet           = "0x8123739C1368C2DEDc8C564255bc417FEEeBFF9D"
+	OPCMv500Mainnet           = "0xFa1Ef97fb02B0dA2Ee2346b8e310907ab5519449"
+	OPCMv220Sepolia           = "0x6b6f9129efb1b7a48f84e3b787333d1dca02ee34"
+	OPCMv300Sepolia           = "0xfBceeD4DE885645fBdED164910E10F52fEBFAB35"
+	OPCMv410Sepolia           = "0x3bb6437aba031afbf9cb3538fa064161e2bf2d78"
+	OPCMv500Sepolia           = "0xC69e4c24Db479191676611a25D977203c3BDca62"
+	CCTPv2                    = "0x28b5a0e9C621a5BadaA536219b3a228C8168cf5d"
+	OPL1StandardBridge        = "0x99C9fc46f92E8a1c0deC1b1747d010903E884bE1"
+	OPL2StandardBridge        = "0x4200000000000000000000000000000000000010"
+	SaferSafes                = "0xA8447329e52F64AED2bFc9E7a2506F7D369f483a"
+	SafeMigration141          = "0x526643F69b81B008F46d95CD5ced5eC0edFFDaC6"
+	SafeMasterCopy141         = "0x41675c099f32341bf84bfc5382af534df5c7461a"
+	SafeFallbackHandler141    = "0xfd0732dc9e303f09fcef3a7388ad10a83459ec99"
+)
+
+// Known DEX routers and the tokens treasury swaps commonly trade
+const (
+	UniswapV3SwapRouter        = "0xE592427A0AEce92De3Edee1F18E0157C05861564"
+	UniswapSwapRouter02        = "0x68b3465833fb72A70ecDF485E0e4C7bD8665Fc45"
+	UniswapSwapRouter02Base    = "0x2626664c2603336E57B271c5C0b26F421741e481"
+	UniswapUniversalRouter     = "0x66a9893cC07D91D95644AEDD05D03f95e1dBA8Af"
+	UniswapUniversalRouterOP   = "0x851116D9223fabED8E56C0E6b8Ad0c31d98B3507"
+	UniswapUniversalRouterBase = "0x6fF5693b99212Da76ad316178A184AB56D299b43"
+	OneInchRouterV5            = "0x1111111254EEB25477B68fb85Ed929f73A960582"
+	OneInchRouterV6            = "0x111111125421cA6dc452d289314280a0f8842A65"
+	CowSwapSettlement          = "0x9008D19f58AAbD9eD0D60971565AA8510560ab41"
+	WETHMainnetAddress         = "0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2"
+	WETHPredeployAddress       = "0x4200000000000000000000000000000000000006"
+	USDCOPMainnetAddress       = "0x0b2C639c533813f4Aa9D7837CAf62653d097Ff85"
+	USDCBaseMainnetAddress     = "0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913"
+	NativeTokenPlaceholder     = "0xEeeeeEeeeEeEeeEeEeEeeEEEeeeeEeeeeeeeEEeE"
+)
+
+// Known governance and token streaming contracts, whose calls are decoded only on them
+const (
+	CompoundGovernorBravo   = "0xc0Da02939E1441F497fd74F78cE7Decb17B66529"
+	UniswapGovernorBravo    = "0x408ED6354d4973f66138C91495F2f2FCbd8724C3"
+	ENSGovernor             = "0x323A76393544d5ecca80cd6ef2A560C6a395b7E3"
+	LidoAragonVoting        = "0x2e59A20f205bB85a89C53f1936454680651E618e"
+	SablierLockupLinear     = "0xAFb979d9afAd1aD27C5eFf4E27226E3AB9e5dCC9"
+	SablierLockupLinearOP   = "0x4b45090152a5731b5bc71b5baF71E60e05B33867"
+	SablierLockupLinearBase = "0xFCF737582d167c7D20A336532eb8BCcA8CF8e350"
+)
+
+// Known contract addresses on zkSync Era. The zkSync VM derives CREATE2 addresses
+// differently, so the canonical Safe deployments live at chain-specific addresses.
+const (
+	ZkSyncSafeSingleton130         = "0xB00ce5CCcdEf57e539ddcEd01DF43a13855d9910"
+	ZkSyncSafeL2Singleton130       = "0x1727c2c531cf966f902E5927b98490fDFb3b2b70"
+	ZkSyncSafeMultisend130         = "0x0dFcccB95225ffB03c6FBB2559B530C2B7C8A912"
+	ZkSyncSafeMultisendCallOnly130 = "0xf220D3b4DFb23C4ade8C88E526C1353AbAcbC38F"
+	ZkSyncSafeFallbackHandler130   = "0x2f870a80647BbC554F3a0EBD093f11B4d2a7492A"
+	ZkSyncMulticall3Address        = "0xF9cda624FBC7e059355ce98a31693d299FACd963"
 )
 
 // Functions on ERC20 tokens that require decimal adjustment
@@ -83,6 +127,7 @@ const (
 	SepoliaChainID     = 11155111
 	OPSepoliaChainID   = 11155420
 	BaseSepoliaChainID = 84532
+	ZkSyncEraChainID   = 324
 )
 
 // ChainNames maps chain IDs to their names
@@ -93,10 +138,12 @@ var ChainNames = map[uint64]string{
 	OPSepoliaChainID:   "OP Sepolia",
 	BaseMainnetChainID: "Base Mainnet",
 	BaseSepoliaChainID: "Base Sepolia",
+	ZkSyncEraChainID:   "zkSync Era",
 }
 
-// KnownContracts maps chain IDs to a map of addresses to contract info
-var KnownContracts = map[uint64]map[string]ContractInfo{
+// builtinContracts maps chain IDs to a map of addresses to contract info. They are registered in
+// KnownAddresses.
+var builtinContracts = map[uint64]map[string]ContractInfo{
 	MainnetChainID: {
 		strings.ToLower(SafeMultisendAddress):     {Name: "GNOSIS SAFE MULTISEND", Decimals: 0},
 		strings.ToLower(SafeMultisendCallOnly130): {Name: "GNOSIS SAFE MULTISEND (v1.3.0)", Decimals: 0},
@@ -105,6 +152,7 @@ var KnownContracts = map[uint64]map[string]ContractInfo{
 		strings.ToLower(USDCMainnetAddress):       {Name: "USDC", Decimals: 6},
 		strings.ToLower(ProxyAdminOwner):          {Name: "SUPERCHAIN PROXY ADMIN OWNER", Decimals: 0},
 		strings.ToLower(OptimismPortal):           {Name: "OPTIMISM PORTAL", Decimals: 0},
+		strings.ToLower(BaseOptimismPortal):       {Name: "BASE OPTIMISM PORTAL", Decimals: 0},
 		strings.ToLower(OPCMv220Mainnet):          {Name: "OPContractsManager V2.2.0", Decimals: 0},
 		strings.ToLower(OPCMv300Mainnet):          {Name: "OPContractsManager V3.0.0", Decimals: 0},
 		strings.ToLower(OPCMv410Mainnet):          {Name: "OPContractsManager V4.1.0", Decimals: 0},
@@ -115,6 +163,18 @@ var KnownContracts = map[uint64]map[string]ContractInfo{
 		strings.ToLower(SafeMigration141):         {Name: "Safe Migration Contract (v1.4.1)", Decimals: 0},
 		strings.ToLower(SafeMasterCopy141):        {Name: "Safe Master Copy (v1.4.1)", Decimals: 0},
 		strings.ToLower(SafeFallbackHandler141):   {Name: "Safe Fallback Handler (v1.4.1)", Decimals: 0},
+		strings.ToLower(UniswapV3SwapRouter):      {Name: "UNISWAP V3 SWAP ROUTER", Decimals: 0},
+		strings.ToLower(UniswapSwapRouter02):      {Name: "UNISWAP SWAP ROUTER 02", Decimals: 0},
+		strings.ToLower(UniswapUniversalRouter):   {Name: "UNISWAP UNIVERSAL ROUTER", Decimals: 0},
+		strings.ToLower(OneInchRouterV5):          {Name: "1INCH AGGREGATION ROUTER V5", Decimals: 0},
+		strings.ToLower(OneInchRouterV6):          {Name: "1INCH AGGREGATION ROUTER V6", Decimals: 0},
+		strings.ToLower(CowSwapSettlement):        {Name: "COWSWAP SETTLEMENT", Decimals: 0},
+		strings.ToLower(WETHMainnetAddress):       {Name: "WETH", Decimals: 18},
+		strings.ToLower(CompoundGovernorBravo):    {Name: "COMPOUND GOVERNOR BRAVO", Decimals: 0},
+		strings.ToLower(UniswapGovernorBravo):     {Name: "UNISWAP GOVERNOR BRAVO", Decimals: 0},
+		strings.ToLower(ENSGovernor):              {Name: "ENS GOVERNOR", Decimals: 0},
+		strings.ToLower(LidoAragonVoting):         {Name: "LIDO ARAGON VOTING", Decimals: 0},
+		strings.ToLower(SablierLockupLinear):      {Name: "SABLIER V2 LOCKUP LINEAR", Decimals: 0},
 	},
 	OPMainnetChainID: {
 		strings.ToLower(SafeMultisendAddress):     {Name: "GNOSIS SAFE MULTISEND", Decimals: 0},
@@ -124,35 +184,66 @@ var KnownContracts = map[uint64]map[string]ContractInfo{
 		strings.ToLower(Multicall3Delegatecall):   {Name: "MULTICALL3 DELEGATECALL", Decimals: 0},
 		strings.ToLower(OPTokenAddress):           {Name: "OP TOKEN", Decimals: 18},
 		strings.ToLower(SuperfluidOP):             {Name: "SUPERFLUID OP", Decimals: 18},
+		strings.ToLower(SablierLockupLinearOP):    {Name: "SABLIER V2 LOCKUP LINEAR", Decimals: 0},
 		strings.ToLower(OptimismGovernor):         {Name: "OPTIMISM GOVERNOR", Decimals: 0},
 		strings.ToLower(OPGrants1):                {Name: "OP GRANTS 1 (3F0)", Decimals: 0},
 		strings.ToLower(OPGrants2):                {Name: "OP GRANTS 2 (1BE)", Decimals: 0},
 		strings.ToLower(OPL2StandardBridge):       {Name: "OP L2StandardBridge", Decimals: 0},
 		strings.ToLower(SaferSafes):               {Name: "SaferSafes", Decimals: 0},
+		strings.ToLower(UniswapV3SwapRouter):      {Name: "UNISWAP V3 SWAP ROUTER", Decimals: 0},
+		strings.ToLower(UniswapSwapRouter02):      {Name: "UNISWAP SWAP ROUTER 02", Decimals: 0},
+		strings.ToLower(UniswapUniversalRouterOP): {Name: "UNISWAP UNIVERSAL ROUTER", Decimals: 0},
+		strings.ToLower(OneInchRouterV5):          {Name: "1INCH AGGREGATION ROUTER V5", Decimals: 0},
+		strings.ToLower(OneInchRouterV6):          {Name: "1INCH AGGREGATION ROUTER V6", Decimals: 0},
+		strings.ToLower(WETHPredeployAddress):     {Name: "WETH", Decimals: 18},
+		strings.ToLower(USDCOPMainnetAddress):     {Name: "USDC", Decimals: 6},
 	},
 	BaseMainnetChainID: {
-		strings.ToLower(SafeMultisendAddress):     {Name: "GNOSIS SAFE MULTISEND", Decimals: 0},
-		strings.ToLower(SafeMultisendCallOnly141): {Name: "GNOSIS SAFE MULTISEND", Decimals: 0},
-		strings.ToLower(SafeMultisendCallOnly130): {Name: "GNOSIS SAFE MULTISEND", Decimals: 0},
-		strings.ToLower(Multicall3Address):        {Name: "MULTICALL3", Decimals: 0},
-		strings.ToLower(Multicall3Delegatecall):   {Name: "MULTICALL3 DELEGATECALL", Decimals: 0},
-		strings.ToLower(OPL2StandardBridge):       {Name: "Base L2StandardBridge", Decimals: 0},
+		strings.ToLower(SafeMultisendAddress):       {Name: "GNOSIS SAFE MULTISEND", Decimals: 0},
+		strings.ToLower(SafeMultisendCallOnly141):   {Name: "GNOSIS SAFE MULTISEND", Decimals: 0},
+		strings.ToLower(SafeMultisendCallOnly130):   {Name: "GNOSIS SAFE MULTISEND", Decimals: 0},
+		strings.ToLower(Multicall3Address):          {Name: "MULTICALL3", Decimals: 0},
+		strings.ToLower(Multicall3Delegatecall):     {Name: "MULTICALL3 DELEGATECALL", Decimals: 0},
+		strings.ToLower(OPL2StandardBridge):         {Name: "Base L2StandardBridge", Decimals: 0},
+		strings.ToLower(UniswapSwapRouter02Base):    {Name: "UNISWAP SWAP ROUTER 02", Decimals: 0},
+		strings.ToLower(UniswapUniversalRouterBase): {Name: "UNISWAP UNIVERSAL ROUTER", Decimals: 0},
+		strings.ToLower(OneInchRouterV5):            {Name: "1INCH AGGREGATION ROUTER V5", Decimals: 0},
+		strings.ToLower(OneInchRouterV6):            {Name: "1INCH AGGREGATION ROUTER V6", Decimals: 0},
+		strings.ToLower(CowSwapSettlement):          {Name: "COWSWAP SETTLEMENT", Decimals: 0},
+		strings.ToLower(WETHPredeployAddress):       {Name: "WETH", Decimals: 18},
+		strings.ToLower(USDCBaseMainnetAddress):     {Name: "USDC", Decimals: 6},
+		strings.ToLower(SablierLockupLinearBase):    {Name: "SABLIER V2 LOCKUP LINEAR", Decimals: 0},
 	},
 	SepoliaChainID: {
+		strings.ToLower(SafeMultisendAddress):      {Name: "GNOSIS SAFE MULTISEND", Decimals: 0},
+		strings.ToLower(Multicall3Address):         {Name: "MULTICALL3", Decimals: 0},
+		strings.ToLower(Multicall3Delegatecall):    {Name: "MULTICALL3 DELEGATECALL", Decimals: 0},
+		strings.ToLower(OPCMv220Sepolia):           {Name: "OPContractsManager V2.2.0", Decimals: 0},
+		strings.ToLower(OPCMv300Sepolia):           {Name: "OPContractsManager V3.0.0", Decimals: 0},
+		strings.ToLower(OPCMv410Sepolia):           {Name: "OPContractsManager V4.1.0", Decimals: 0},
+		strings.ToLower(OPCMv500Sepolia):           {Name: "OPContractsManager V5.0.0", Decimals: 0},
+		strings.ToLower(SaferSafes):                {Name: "SaferSafes", Decimals: 0},
+		strings.ToLower(OPSepoliaOptimismPortal):   {Name: "OP SEPOLIA OPTIMISM PORTAL", Decimals: 0},
+		strings.ToLower(BaseSepoliaOptimismPortal): {Name: "BASE SEPOLIA OPTIMISM PORTAL", Decimals: 0},
+	},
+	OPSepoliaChainID: {
 		strings.ToLower(SafeMultisendAddress):   {Name: "GNOSIS SAFE MULTISEND", Decimals: 0},
 		strings.ToLower(Multicall3Address):      {Name: "MULTICALL3", Decimals: 0},
 		strings.ToLower(Multicall3Delegatecall): {Name: "MULTICALL3 DELEGATECALL", Decimals: 0},
-		strings.ToLower(OPCMv220Sepolia):        {Name: "OPContractsManager V2.2.0", Decimals: 0},
-		strings.ToLower(OPCMv300Sepolia):        {Name: "OPContractsManager V3.0.0", Decimals: 0},
-		strings.ToLower(OPCMv410Sepolia):        {Name: "OPContractsManager V4.1.0", Decimals: 0},
-		strings.ToLower(OPCMv500Sepolia):        {Name: "OPContractsManager V5.0.0", Decimals: 0},
-		strings.ToLower(SaferSafes):             {Name: "SaferSafes", Decimals: 0},
 	},
-	OPSepoliaChainID: {
+	BaseSepoliaChainID: {
 		strings.ToLower(SafeMultisendAddress):   {Name: "GNOSIS SAFE MULTISEND", Decimals: 0},
 		strings.ToLower(Multicall3Address):      {Name: "MULTICALL3", Decimals: 0},
 		strings.ToLower(Multicall3Delegatecall): {Name: "MULTICALL3 DELEGATECALL", Decimals: 0},
 	},
+	ZkSyncEraChainID: {
+		strings.ToLower(ZkSyncSafeSingleton130):         {Name: "Safe Master Copy (v1.3.0 zkSync)", Decimals: 0},
+		strings.ToLower(ZkSyncSafeL2Singleton130):       {Name: "Safe L2 Master Copy (v1.3.0 zkSync)", Decimals: 0},
+		strings.ToLower(ZkSyncSafeMultisend130):         {Name: "GNOSIS SAFE MULTISEND (v1.3.0 zkSync)", Decimals: 0},
+		strings.ToLower(ZkSyncSafeMultisendCallOnly130): {Name: "GNOSIS SAFE MULTISEND CALL ONLY (v1.3.0 zkSync)", Decimals: 0},
+		strings.ToLower(ZkSyncSafeFallbackHandler130):   {Name: "Safe Fallback Handler (v1.3.0 zkSync)", Decimals: 0},
+		strings.ToLower(ZkSyncMulticall3Address):        {Name: "MULTICALL3", Decimals: 0},
+	},
 }
 
 // MulticallAddresses maps chain IDs to a set of addresses known to be multicall contracts
@@ -179,6 +270,33 @@ var MulticallAddresses = map[uint64]map[string]bool{
 		strings.ToLower(Multicall3Address):      true,
 		strings.ToLower(Multicall3Delegatecall): true,
 	},
+	BaseSepoliaChainID: {
+		strings.ToLower(SafeMultisendAddress):   true,
+		strings.ToLower(Multicall3Address):      true,
+		strings.ToLower(Multicall3Delegatecall): true,
+	},
+	ZkSyncEraChainID: {
+		strings.ToLower(ZkSyncSafeMultisend130):         true,
+		strings.ToLower(ZkSyncSafeMultisendCallOnly130): true,
+		strings.ToLower(ZkSyncMulticall3Address):        true,
+	},
+}
+
+// SafeMultisendAddresses is the set of Safe MultiSend / MultiSendCallOnly deployments
+// across all supported chains, which share the packed multiSend(bytes) encoding
+var SafeMultisendAddresses = map[string]bool{
+	strings.ToLower(SafeMultisendAddress):           true,
+	strings.ToLower(SafeMultisendCallOnly130):       true,
+	strings.ToLower(SafeMultisendCallOnly141):       true,
+	strings.ToLower(ZkSyncSafeMultisend130):         true,
+	strings.ToLower(ZkSyncSafeMultisendCallOnly130): true,
+}
+
+// Multicall3Addresses is the set of Multicall3-compatible deployments across all supported chains
+var Multicall3Addresses = map[string]bool{
+	strings.ToLower(Multicall3Address):       true,
+	strings.ToLower(Multicall3Delegatecall):  true,
+	strings.ToLower(ZkSyncMulticall3Address): true,
 }
 
 // KnownFunctions maps function selectors to function info
@@ -205,6 +323,7 @@ var KnownABIJSON = []string{
 	`[{"inputs":[{"name":"to","type":"address"},{"name":"value","type":"uint256"},{"name":"data","type":"bytes"},{"name":"operation","type":"uint8"},{"name":"safeTxGas","type":"uint256"},{"name":"baseGas","type":"uint256"},{"name":"gasPrice","type":"uint256"},{"name":"gasToken","type":"address"},{"name":"refundReceiver","type":"address"},{"name":"signatures","type":"bytes"}],"name":"execTransaction","type":"function"}]`,
 	`[{"inputs":[{"internalType":"address","name":"to","type":"address"},{"internalType":"uint256","name":"value","type":"uint256"},{"internalType":"uint64","name":"gasLimit","type":"uint64"},{"internalType":"bool","name":"isCreation","type":"bool"},{"internalType":"bytes","name":"data","type":"bytes"}],"name":"depositTransaction","outputs":[],"stateMutability":"payable","type":"function"}]`,
 	`[{"inputs":[{"name":"prevOwner","type":"address"},{"name":"oldOwner","type":"address"},{"name":"newOwner","type":"address"}],"name":"swapOwner","type":"function"}]`,
+	`[{"inputs":[{"name":"threshold","type":"uint256"}],"name":"changeThreshold","type":"function"}]`,
 	`[{"inputs":[{"components":[{"name":"systemConfigProxy","type":"address"},{"name":"proxyAdmin","type":"address"},{"name":"absolutePrestate","type":"bytes32"}],"name":"prestateUpdateInputs","type":"tuple[]"}],"name":"updatePrestate","outputs":[],"stateMutability":"nonpayable","type":"function"}]`,
 	`[{"inputs":[{"name":"amount","type":"uint256"},{"name":"destinationDomain","type":"uint32"},{"name":"mintRecipient","type":"bytes32"},{"name":"burnToken","type":"address"},{"name":"destinationCaller","type":"bytes32"},{"name":"maxFee","type":"uint256"},{"name":"minFinalityThreshold","type":"uint32"}],"name":"depositForBurn","outputs":[],"stateMutability":"nonpayable","type":"function"}]`,
 	`[{"inputs":[{"internalType":"address","name":"to","type":"address"},{"internalType":"uint32","name":"minGasLimit","type":"uint32"},{"internalType":"bytes","name":"extraData","type":"bytes"}],"name":"bridgeETHTo","outputs":[],"stateMutability":"payable","type":"function"}]`,
@@ -220,8 +339,12 @@ var KnownABIJSON = []string{
 
 // Initialize known functions
 func init() {
-	// Parse each ABI JSON and add to KnownFunctions
-	for _, abiJSON := range KnownABIJSON {
+	registerKnownABIs(KnownABIJSON)
+}
+
+// registerKnownABIs parses each single-method ABI JSON and adds it to KnownFunctions
+func registerKnownABIs(abiJSONs []string) {
+	for _, abiJSON := range abiJSONs {
 		parsedABI, err := abi.JSON(strings.NewReader(abiJSON))
 		if err != nil {
 			// Log error but continue
@@ -243,11 +366,11 @@ func init() {
 	}
 }
 
+// GetKnownContract returns the name and decimals KnownAddresses has for an address on a chain
 func GetKnownContract(address string, chainID uint64) (ContractInfo, bool) {
-	normalizedAddr := strings.ToLower(address)
-	if chainContracts, exists := KnownContracts[chainID]; exists {
-		contractInfo, isKnown := chainContracts[normalizedAddr]
-		return contractInfo, isKnown
+	entry, ok := KnownAddresses.Lookup(address, chainID)
+	if !ok {
+		return ContractInfo{}, false
 	}
-	return ContractInfo{}, false
+	return ContractInfo{Name: entry.Name, Decimals: entry.Decimals}, true
 }
@@ -1,6 +1,9 @@
 package core
 
-import "testing"
+import (
+	"strings"
+	"testing"
+)
 
 func TestGetKnownContract(t *testing.T) {
 	// Known on OP Mainnet
@@ -17,3 +20,16 @@ func TestGetKnownContract(t *testing.T) {
 		t.Fatalf("did not expect OP token to be known on Ethereum Mainnet")
 	}
 }
+
+func TestZkSyncMultisendIsMulticall(t *testing.T) {
+	addr := strings.ToLower(ZkSyncSafeMultisendCallOnly130)
+	if !MulticallAddresses[ZkSyncEraChainID][addr] {
+		t.Fatalf("expected zkSync MultiSendCallOnly to be a multicall address on zkSync Era")
+	}
+	if !SafeMultisendAddresses[addr] {
+		t.Fatalf("expected zkSync MultiSendCallOnly to use the Safe multiSend encoding")
+	}
+	if MulticallAddresses[MainnetChainID][addr] {
+		t.Fatalf("did not expect zkSync MultiSendCallOnly to be a multicall address on Ethereum Mainnet")
+	}
+}
@@ -0,0 +1,146 @@
+package core
+
+import (
+	"sort"
+	"strings"
+)
+
+// Decoding coverage of a call
+const (
+	CoverageFull       = "full"
+	CoveragePartial    = "partial"
+	CoverageUnknown    = "unknown"
+	CoverageNoCalldata = "no calldata"
+)
+
+// explorerURLs are the block explorers whose verified source shows the ABI of a contract
+var explorerURLs = map[uint64]string{
+	MainnetChainID:     "https://etherscan.io",
+	OPMainnetChainID:   "https://optimistic.etherscan.io",
+	BaseMainnetChainID: "https://basescan.org",
+	SepoliaChainID:     "https://sepolia.etherscan.io",
+	OPSepoliaChainID:   "https://sepolia-optimism.etherscan.io",
+	BaseSepoliaChainID: "https://sepolia.basescan.org",
+	ZkSyncEraChainID:   "https://era.zksync.network",
+}
+
+// SelectorCoverage is a function selector that was not fully decoded, with where to find its ABI
+type SelectorCoverage struct {
+	Selector string `json:"selector"`
+
+	// Kind is CoverageUnknown when the selector is not known, or CoveragePartial when it is known
+	// but its arguments did not decode
+	Kind     string   `json:"kind"`
+	Function string   `json:"function,omitempty"`
+	Calls    int      `json:"calls"`
+	Targets  []string `json:"targets"`
+
+	// Links are signature databases for the selector and the verified source of each target
+	Links []string `json:"links"`
+}
+
+// CoverageReport counts how the calls of a transaction decoded
+type CoverageReport struct {
+	Calls      int `json:"calls"`
+	Full       int `json:"full"`
+	Partial    int `json:"partial"`
+	Unknown    int `json:"unknown"`
+	NoCalldata int `json:"noCalldata"`
+
+	// Undecoded lists the selectors that did not decode fully, most frequent first
+	Undecoded []SelectorCoverage `json:"undecoded,omitempty"`
+}
+
+// CallCoverage classifies how a single call decoded, ignoring its subcalls
+func CallCoverage(call CallData) string {
+	switch {
+	case call.RawData == "":
+		return CoverageFull
+	case call.RawData == "0x":
+		return CoverageNoCalldata
+	case call.FunctionName == "unknown":
+		return CoverageUnknown
+	default:
+		return CoveragePartial
+	}
+}
+
+// Coverage reports how every call of a result decoded, including the batched subcalls and the
+// calls of an approved child transaction, so maintainers can see which ABIs are missing
+func Coverage(result *VerificationResult) *CoverageReport {
+	report := &CoverageReport{}
+	undecoded := map[string]*SelectorCoverage{}
+
+	var visit func(call CallData, chainID uint64)
+	visit = func(call CallData, chainID uint64) {
+		report.Calls++
+		switch kind := CallCoverage(call); kind {
+		case CoverageFull:
+			report.Full++
+		case CoverageNoCalldata:
+			report.NoCalldata++
+		default:
+			if kind == CoverageUnknown {
+				report.Unknown++
+			} else {
+				report.Partial++
+			}
+
+			selector := "0x" + strings.ToLower(strings.TrimPrefix(call.RawData, "0x"))
+			if len(selector) > 10 {
+				selector = selector[:10]
+			}
+			entry, ok := undecoded[selector]
+			if !ok {
+				entry = &SelectorCoverage{Selector: selector, Kind: kind}
+				if kind == CoveragePartial {
+					entry.Function = call.FunctionName
+				}
+				if len(selector) == 10 {
+					entry.Links = append(entry.Links,
+						"https://www.4byte.directory/signatures/?bytes4_signature="+selector,
+						"https://openchain.xyz/signatures?query="+selector)
+				}
+				undecoded[selector] = entry
+			}
+			entry.Calls++
+			target := ChecksumAddress(call.Target)
+			if !containsString(entry.Targets, target) {
+				entry.Targets = append(entry.Targets, target)
+				if explorer, ok := explorerURLs[chainID]; ok {
+					entry.Links = append(entry.Links, explorer+"/address/"+target+"#code")
+				}
+			}
+		}
+		for _, subcall := range call.SubCalls {
+			visit(subcall, chainID)
+		}
+	}
+
+	visit(result.Call, uint64(result.Transaction.Chain))
+	if result.NestedResult != nil {
+		visit(result.NestedResult.Call, uint64(result.NestedResult.Transaction.Chain))
+	}
+
+	for _, entry := range undecoded {
+		report.Undecoded = append(report.Undecoded, *entry)
+	}
+	sort.Slice(report.Undecoded, func(i, j int) bool {
+		a, b := report.Undecoded[i], report.Undecoded[j]
+		if a.Calls != b.Calls {
+			return a.Calls > b.Calls
+		}
+		return a.Selector < b.Selector
+	})
+	return report
+}
+
+// containsString reports whether a slice contains a string
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,58 @@
+package core
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestCoverage(t *testing.T) {
+	unknown := func(to string) multiSendTransaction {
+		return multiSendTransaction{To: common.HexToAddress(to), Value: big.NewInt(0), Data: common.FromHex("0xDEADBEEF0000")}
+	}
+	// A transfer whose arguments are cut short has a known selector but does not decode
+	truncated := multiSendTransaction{To: common.HexToAddress(airdropToken), Value: big.NewInt(0), Data: append(append([]byte{}, erc20TransferSelector...), 1, 2, 3)}
+
+	tx := multiSendTx(t,
+		erc20Transfer(airdropToken, airdropAlice, big.NewInt(1)),
+		nativeTransfer(airdropBob, big.NewInt(1)),
+		unknown(airdropAlice),
+		unknown(airdropBob),
+		unknown(airdropAlice),
+		truncated,
+	)
+	tx.Safe, tx.SafeVersion = effectsSafe, "1.3.0"
+	result, err := VerifyTransaction(tx, VerifyOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	report := Coverage(result)
+	if report.Calls != 7 || report.Full != 2 || report.NoCalldata != 1 || report.Unknown != 3 || report.Partial != 1 {
+		t.Fatalf("unexpected counts: %+v", report)
+	}
+	if len(report.Undecoded) != 2 {
+		t.Fatalf("unexpected undecoded selectors: %+v", report.Undecoded)
+	}
+
+	missing := report.Undecoded[0]
+	if missing.Selector != "0xdeadbeef" || missing.Kind != CoverageUnknown || missing.Calls != 3 || len(missing.Targets) != 2 {
+		t.Errorf("unexpected unknown selector: %+v", missing)
+	}
+	links := strings.Join(missing.Links, "\n")
+	for _, want := range []string{
+		"https://www.4byte.directory/signatures/?bytes4_signature=0xdeadbeef",
+		"https://etherscan.io/address/" + common.HexToAddress(airdropAlice).Hex() + "#code",
+		"https://etherscan.io/address/" + common.HexToAddress(airdropBob).Hex() + "#code",
+	} {
+		if !strings.Contains(links, want) {
+			t.Errorf("links do not include %s:\n%s", want, links)
+		}
+	}
+
+	if partial := report.Undecoded[1]; partial.Selector != "0x"+common.Bytes2Hex(erc20TransferSelector) || partial.Kind != CoveragePartial || partial.Function != "transfer" {
+		t.Errorf("unexpected partial selector: %+v", partial)
+	}
+}
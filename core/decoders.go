@@ -0,0 +1,211 @@
+package core
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// CallDecoder turns the raw decoded arguments of a well-known call into a readable summary
+// (tokens, amounts, deadlines, recipients), which is shown next to the raw arguments.
+// Returning an error leaves the call without a summary.
+type CallDecoder func(args map[string]interface{}, chainID uint64) (map[string]interface{}, error)
+
+// CallDecoders maps function signatures to decoders that summarize their arguments on any
+// target. Decoder packs register themselves here, together with their ABIs, from an init
+// function.
+var CallDecoders = map[string]CallDecoder{}
+
+// contractKey identifies a contract on a chain; the address is lowercase
+type contractKey struct {
+	chainID uint64
+	address string
+}
+
+// contractDecoders maps known contracts to decoders, by function signature, for calls whose
+// meaning depends on the contract they are made to, such as a router's swap. The same call to
+// any other address is not summarized.
+var contractDecoders = map[contractKey]map[string]CallDecoder{}
+
+// contractFunctions maps known contracts to functions, by selector, that are decoded only on
+// them and with the argument names they give them. A selector can mean different things on
+// different contracts: cancel(uint256) cancels a Sablier stream or a Governor Bravo proposal.
+var contractFunctions = map[contractKey]map[string]FunctionInfo{}
+
+// builtinContractKeys returns the contract at an address on every chain where the built-in
+// contracts list it. Addresses added by registries or address books are never included, so they
+// cannot make a call decode as something it is not.
+func builtinContractKeys(address string) []contractKey {
+	var keys []contractKey
+	for chainID, contracts := range builtinContracts {
+		if _, ok := contracts[strings.ToLower(address)]; ok {
+			keys = append(keys, contractKey{chainID: chainID, address: strings.ToLower(address)})
+		}
+	}
+	if len(keys) == 0 {
+		panic(fmt.Sprintf("%s is not a built-in contract", address))
+	}
+	return keys
+}
+
+// registerContractDecoder registers a decoder for a function of a built-in contract
+func registerContractDecoder(address, signature string, decoder CallDecoder) {
+	for _, key := range builtinContractKeys(address) {
+		if contractDecoders[key] == nil {
+			contractDecoders[key] = map[string]CallDecoder{}
+		}
+		contractDecoders[key][signature] = decoder
+	}
+}
+
+// registerContractFunction registers the function of a single-method ABI JSON on a built-in
+// contract, together with a decoder for its summary when decoder is not nil
+func registerContractFunction(address, abiJSON string, decoder CallDecoder) {
+	parsedABI, err := abi.JSON(strings.NewReader(abiJSON))
+	if err != nil || len(parsedABI.Methods) != 1 {
+		panic(fmt.Sprintf("invalid single-method ABI %s: %v", abiJSON, err))
+	}
+	for name, method := range parsedABI.Methods {
+		for _, key := range builtinContractKeys(address) {
+			if contractFunctions[key] == nil {
+				contractFunctions[key] = map[string]FunctionInfo{}
+			}
+			contractFunctions[key][hex.EncodeToString(method.ID)] = FunctionInfo{Name: name, Signature: method.Sig, ABI: method}
+		}
+		if decoder != nil {
+			registerContractDecoder(address, method.Sig, decoder)
+		}
+	}
+}
+
+// lookupFunction returns the function a selector calls on a target: the target's own function
+// when it is a built-in contract that registers one, or else the known function with the selector
+func lookupFunction(target string, chainID uint64, selector string) (FunctionInfo, bool) {
+	key := contractKey{chainID: chainID, address: strings.ToLower(StripChainPrefix(target))}
+	if info, ok := contractFunctions[key][selector]; ok {
+		return info, true
+	}
+	info, ok := KnownFunctions[selector]
+	return info, ok
+}
+
+// decodeCall applies the decoder registered for a function on the target, or else for the
+// function on any target, and returns the summary's fields in name order. It returns nil when
+// no decoder applies or the decoder fails.
+func decodeCall(target string, functionInfo FunctionInfo, args map[string]interface{}, chainID uint64) []Argument {
+	decoder, ok := contractDecoders[contractKey{chainID: chainID, address: strings.ToLower(target)}][functionInfo.Signature]
+	if !ok {
+		decoder, ok = CallDecoders[functionInfo.Signature]
+	}
+	if !ok {
+		return nil
+	}
+	summary, err := decoder(args, chainID)
+	if err != nil {
+		return nil
+	}
+	names := make([]string, 0, len(summary))
+	for name := range summary {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	fields := make([]Argument, 0, len(names))
+	for _, name := range names {
+		fields = append(fields, Argument{Name: name, Value: summary[name]})
+	}
+	return fields
+}
+
+// describeAddress renders an address in full, labelled with its name when it is a known contract
+func describeAddress(address common.Address, chainID uint64) string {
+	if info, ok := GetKnownContract(address.Hex(), chainID); ok {
+		return fmt.Sprintf("%s (%s 🔍)", address.Hex(), info.Name)
+	}
+	return address.Hex()
+}
+
+// formatTokenAmount renders a raw token amount, scaled by the token's decimals when the token is known
+func formatTokenAmount(amount *big.Int, token common.Address, chainID uint64) string {
+	if info, ok := GetKnownContract(token.Hex(), chainID); ok && info.Decimals > 0 {
+		return fmt.Sprintf("%s %s (%s raw)", ParseDecimals(amount, info.Decimals), info.Name, amount)
+	}
+	return fmt.Sprintf("%s (raw, unknown token decimals)", amount)
+}
+
+// formatMinimumAmount renders a minimum output amount, calling out a zero minimum since such a
+// trade accepts any price and can be sandwiched for its full value
+func formatMinimumAmount(amount *big.Int, token common.Address, chainID uint64) string {
+	if amount.Sign() == 0 {
+		return "0 ⚠️ NO MINIMUM (no slippage protection)"
+	}
+	return formatTokenAmount(amount, token, chainID)
+}
+
+// formatDeadline renders a unix timestamp deadline with its UTC date
+func formatDeadline(deadline *big.Int) string {
+	if !deadline.IsInt64() {
+		return fmt.Sprintf("%s (never expires)", deadline)
+	}
+	return fmt.Sprintf("%s (%s)", deadline, time.Unix(deadline.Int64(), 0).UTC().Format("2006-01-02 15:04:05 UTC"))
+}
+
+// structField returns a field of an ABI-decoded tuple, matching the ABI component name
+func structField(value interface{}, name string) (interface{}, error) {
+	v := reflect.ValueOf(value)
+	if v.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("expected tuple, got %T", value)
+	}
+	field, ok := tupleField(v.Type(), name)
+	if !ok {
+		return nil, fmt.Errorf("missing tuple field %s", name)
+	}
+	return v.FieldByIndex(field.Index).Interface(), nil
+}
+
+// tupleFields extracts typed fields from an ABI-decoded tuple. Each target must be a pointer to
+// a variable of the field's exact decoded type.
+func tupleFields(value interface{}, targets map[string]interface{}) error {
+	for name, target := range targets {
+		field, err := structField(value, name)
+		if err != nil {
+			return err
+		}
+		out := reflect.ValueOf(target).Elem()
+		in := reflect.ValueOf(field)
+		if !in.Type().AssignableTo(out.Type()) {
+			return fmt.Errorf("tuple field %s has type %s, expected %s", name, in.Type(), out.Type())
+		}
+		out.Set(in)
+	}
+	return nil
+}
+
+// argValue returns a decoded argument with the expected type
+func argValue[T any](args map[string]interface{}, name string) (T, error) {
+	value, ok := args[name].(T)
+	if !ok {
+		var zero T
+		return zero, fmt.Errorf("argument %s has type %T, expected %T", name, args[name], zero)
+	}
+	return value, nil
+}
+
+// toInterfaceSlice converts a decoded ABI array of any element type to a slice of its elements
+func toInterfaceSlice(value interface{}) []interface{} {
+	v := reflect.ValueOf(value)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return nil
+	}
+	out := make([]interface{}, v.Len())
+	for i := range out {
+		out[i] = v.Index(i).Interface()
+	}
+	return out
+}
@@ -0,0 +1,160 @@
+package core
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DenylistEntry is a single known-malicious or sanctioned address
+type DenylistEntry struct {
+	Address string `json:"address"`
+	Reason  string `json:"reason,omitempty"`
+	Source  string `json:"-"`
+}
+
+// Denylist is a set of addresses that must never be interacted with, keyed by lowercase address
+type Denylist struct {
+	Entries map[string]DenylistEntry
+}
+
+// denylistManifest is the JSON form of a denylist
+type denylistManifest struct {
+	Addresses []DenylistEntry `json:"addresses"`
+}
+
+// NewDenylist creates an empty denylist
+func NewDenylist() *Denylist {
+	return &Denylist{Entries: map[string]DenylistEntry{}}
+}
+
+// DefaultDenylistPath returns the denylist file that is loaded automatically when present
+func DefaultDenylistPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "op-txverify", "denylist.txt"), nil
+}
+
+// ParseDenylist adds the entries in data to the denylist. Two formats are accepted: a JSON
+// manifest ({"addresses": [{"address": "0x...", "reason": "..."}]}) or plain text with one
+// address per line followed by an optional reason, where "#" starts a comment.
+func (d *Denylist) ParseDenylist(source string, data []byte) error {
+	if trimmed := strings.TrimSpace(string(data)); strings.HasPrefix(trimmed, "{") {
+		var manifest denylistManifest
+		if err := json.Unmarshal(data, &manifest); err != nil {
+			return fmt.Errorf("invalid denylist manifest %s: %w", source, err)
+		}
+		for _, entry := range manifest.Addresses {
+			if err := d.add(source, entry.Address, entry.Reason); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(string(data)))
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if idx := strings.Index(text, "#"); idx != -1 {
+			text = strings.TrimSpace(text[:idx])
+		}
+		if text == "" {
+			continue
+		}
+		fields := strings.Fields(text)
+		if err := d.add(fmt.Sprintf("%s:%d", source, line), fields[0], strings.Join(fields[1:], " ")); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// add validates and inserts a single entry
+func (d *Denylist) add(source, address, reason string) error {
+	if !strings.HasPrefix(address, "0x") || ValidateFullAddress("denylist", address) != nil {
+		return fmt.Errorf("%s: invalid denylist address %q", source, address)
+	}
+	d.Entries[strings.ToLower(address)] = DenylistEntry{Address: ChecksumAddress(address), Reason: reason, Source: source}
+	return nil
+}
+
+// Lookup returns the denylist entry for an address, if any
+func (d *Denylist) Lookup(address string) (DenylistEntry, bool) {
+	if d == nil {
+		return DenylistEntry{}, false
+	}
+	entry, ok := d.Entries[strings.ToLower(StripChainPrefix(address))]
+	return entry, ok
+}
+
+// LoadDenylistFile adds the entries of a local denylist file
+func (d *Denylist) LoadDenylistFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read denylist: %w", err)
+	}
+	return d.ParseDenylist(path, data)
+}
+
+// FetchDenylistManifest downloads a denylist manifest. Only HTTPS URLs are accepted, since a
+// tampered denylist could hide a malicious address.
+func FetchDenylistManifest(ctx context.Context, url string) ([]byte, error) {
+	if !strings.HasPrefix(url, "https://") {
+		return nil, fmt.Errorf("denylist manifest URL must use https: %s", url)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error creating request for %s: %w", url, err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("denylist request to %s failed with status: %s", url, resp.Status)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading denylist: %w", err)
+	}
+
+	// Make sure the manifest parses before anyone relies on it
+	if err := NewDenylist().ParseDenylist(url, data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// checkDenylist raises a critical warning for every denylisted address in a result, including
+// subcall targets and decoded address arguments
+func checkDenylist(result *VerificationResult, denylist *Denylist) []Warning {
+	if denylist == nil || len(denylist.Entries) == 0 {
+		return nil
+	}
+
+	var warnings []Warning
+	for _, address := range collectResultAddresses(result) {
+		entry, ok := denylist.Lookup(address)
+		if !ok {
+			continue
+		}
+		reason := entry.Reason
+		if reason == "" {
+			reason = "no reason given"
+		}
+		warnings = append(warnings, newWarning(warnDenylisted,
+			"Address %s is on the denylist (%s; from %s). DO NOT SIGN.", entry.Address, reason, entry.Source))
+	}
+	return warnings
+}
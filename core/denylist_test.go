@@ -0,0 +1,106 @@
+package core
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// grantsRecipient is the token recipient decoded from grantsTransferTx
+const grantsRecipient = "0x8b8B2F214D92527BF1b1148DC2e609a4C1c2Fd69"
+
+func TestParseDenylistText(t *testing.T) {
+	denylist := NewDenylist()
+	data := "# known drainers\n\n0x8b8b2f214d92527bf1b1148dc2e609a4c1c2fd69 Drainer kit  # reported 2025\n0x2501c477D0A35545a387Aa4A3EEe4292A9a8B3F0\n"
+	if err := denylist.ParseDenylist("test.txt", []byte(data)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(denylist.Entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(denylist.Entries))
+	}
+	entry, ok := denylist.Lookup("oeth:" + grantsRecipient)
+	if !ok {
+		t.Fatal("expected prefixed address to be found")
+	}
+	if entry.Reason != "Drainer kit" || entry.Source != "test.txt:3" || entry.Address != grantsRecipient {
+		t.Errorf("unexpected entry %+v", entry)
+	}
+}
+
+func TestParseDenylistManifest(t *testing.T) {
+	denylist := NewDenylist()
+	data := `{"addresses": [{"address": "0x8b8b2f214d92527bf1b1148dc2e609a4c1c2fd69", "reason": "OFAC SDN"}]}`
+	if err := denylist.ParseDenylist("manifest.json", []byte(data)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if entry, ok := denylist.Lookup(grantsRecipient); !ok || entry.Reason != "OFAC SDN" {
+		t.Errorf("unexpected entry %+v (found=%v)", entry, ok)
+	}
+}
+
+func TestParseDenylistRejectsInvalidAddresses(t *testing.T) {
+	for _, data := range []string{
+		"0x8b8b2f21…c1c2fd69 truncated\n",
+		"8b8b2f214d92527bf1b1148dc2e609a4c1c2fd69 missing prefix\n",
+		`{"addresses": [{"address": "0x1234"}]}`,
+	} {
+		if err := NewDenylist().ParseDenylist("bad", []byte(data)); err == nil {
+			t.Errorf("expected error for %q", data)
+		}
+	}
+}
+
+func TestLoadDenylistFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "denylist.txt")
+	if err := os.WriteFile(path, []byte(grantsRecipient+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	denylist := NewDenylist()
+	if err := denylist.LoadDenylistFile(path); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := denylist.Lookup(grantsRecipient); !ok {
+		t.Error("expected address from file")
+	}
+}
+
+func TestFetchDenylistManifestRequiresHTTPS(t *testing.T) {
+	if _, err := FetchDenylistManifest(context.Background(), "http://example.com/denylist.json"); err == nil {
+		t.Fatal("expected error for non-https manifest URL")
+	}
+}
+
+func TestVerifyTransactionDenylist(t *testing.T) {
+	tx := grantsTransferTx()
+
+	// The recipient only appears as a decoded argument of the transfer call
+	denylist := NewDenylist()
+	if err := denylist.ParseDenylist("test", []byte(grantsRecipient+" OFAC SDN\n")); err != nil {
+		t.Fatal(err)
+	}
+	result, err := VerifyTransaction(tx, VerifyOptions{Denylist: denylist})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !HasCritical(result.Warnings) {
+		t.Fatalf("expected a critical warning, got %+v", result.Warnings)
+	}
+	if !strings.Contains(result.Warnings[0].Message, "OFAC SDN") {
+		t.Errorf("expected reason in warning, got %q", result.Warnings[0].Message)
+	}
+
+	// The call target is checked too
+	denylist = NewDenylist()
+	if err := denylist.ParseDenylist("test", []byte(OPTokenAddress+"\n")); err != nil {
+		t.Fatal(err)
+	}
+	result, err = VerifyTransaction(tx, VerifyOptions{Denylist: denylist})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !HasCritical(result.Warnings) {
+		t.Fatalf("expected a critical warning for denylisted target, got %+v", result.Warnings)
+	}
+}
@@ -0,0 +1,175 @@
+package core
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Contract deployers whose calls are decoded as deployments. Each has the same address on every
+// supported chain except zkSync Era.
+const (
+	SafeCreateCall130        = "0x7cbB62EaA69F79e6873cD1ecB2392971036cFAa4"
+	SafeCreateCall130EIP155  = "0xB19D6FFc2182150F8Eb585b79D4ABcd7C5640A9d"
+	SafeCreateCall141        = "0x9b35Af71d77eaf8d7e40252370304687390A1A52"
+	DeterministicDeployProxy = "0x4e59b44847b379578588920cA78FbF26c0B4956C"
+	Create2Deployer          = "0x13b0D85CcB8bf860b6b79AF3029fCA081AE9beF2"
+)
+
+// Deployment methods
+const (
+	DeploymentCreate  = "CREATE"
+	DeploymentCreate2 = "CREATE2"
+)
+
+// deploymentABIJSON contains the entry points of the Safe CreateCall library and create2deployer.
+// The deterministic deployment proxy takes salt || init code without a selector.
+var deploymentABIJSON = []string{
+	`[{"inputs":[{"name":"value","type":"uint256"},{"name":"deploymentData","type":"bytes"},{"name":"salt","type":"bytes32"}],"name":"performCreate2","type":"function"}]`,
+	`[{"inputs":[{"name":"value","type":"uint256"},{"name":"deploymentData","type":"bytes"}],"name":"performCreate","type":"function"}]`,
+	`[{"inputs":[{"name":"value","type":"uint256"},{"name":"salt","type":"bytes32"},{"name":"code","type":"bytes"}],"name":"deploy","type":"function"}]`,
+}
+
+// deployers names the deployer contracts
+var deployers = map[string]string{
+	strings.ToLower(SafeCreateCall130):        "SAFE CREATECALL 1.3.0",
+	strings.ToLower(SafeCreateCall130EIP155):  "SAFE CREATECALL 1.3.0",
+	strings.ToLower(SafeCreateCall141):        "SAFE CREATECALL 1.4.1",
+	strings.ToLower(DeterministicDeployProxy): "DETERMINISTIC DEPLOYMENT PROXY",
+	strings.ToLower(Create2Deployer):          "CREATE2DEPLOYER",
+}
+
+func init() {
+	registerKnownABIs(deploymentABIJSON)
+
+	for _, chainID := range KnownAddresses.Chains() {
+		if chainID == ZkSyncEraChainID || chainID == AnyChain {
+			continue
+		}
+		for address, name := range deployers {
+			if _, exists := KnownAddresses.Lookup(address, chainID); !exists {
+				KnownAddresses.Register(AddressEntry{Address: address, ChainID: chainID, Name: name, Source: SourceBuiltIn})
+			}
+		}
+	}
+
+	CallDecoders["performCreate2(uint256,bytes,bytes32)"] = decodeDeploymentCall
+	CallDecoders["performCreate(uint256,bytes)"] = decodeDeploymentCall
+	CallDecoders["deploy(uint256,bytes32,bytes)"] = decodeDeploymentCall
+}
+
+// Deployment is a contract creation made by a call to a known deployer. Deployer and Address
+// are resolved once the context the call executes in is known: a deployer that is
+// DELEGATECALLed, like Safe's CreateCall, deploys from the Safe itself.
+type Deployment struct {
+	Method       string   `json:"method"`
+	Deployer     string   `json:"deployer,omitempty"`
+	Value        *big.Int `json:"value"`
+	Salt         string   `json:"salt,omitempty"`
+	InitCodeHash string   `json:"initCodeHash"`
+	InitCodeSize int      `json:"initCodeSize"`
+
+	// Address is the predicted address of a CREATE2 deployment; CREATE addresses depend on the
+	// deployer's nonce at execution and are not predicted
+	Address string `json:"address,omitempty"`
+}
+
+// decodeDeployment decodes the deployment made by a call to a known deployer, or returns nil
+func decodeDeployment(to string, data []byte) *Deployment {
+	normalizedTo := strings.ToLower(to)
+	if _, ok := deployers[normalizedTo]; !ok {
+		return nil
+	}
+
+	deployment := func(method string, value *big.Int, salt []byte, initCode []byte) *Deployment {
+		d := &Deployment{
+			Method:       method,
+			Value:        value,
+			InitCodeHash: crypto.Keccak256Hash(initCode).Hex(),
+			InitCodeSize: len(initCode),
+		}
+		if salt != nil {
+			d.Salt = common.BytesToHash(salt).Hex()
+		}
+		return d
+	}
+
+	if normalizedTo == strings.ToLower(DeterministicDeployProxy) {
+		if len(data) < 32 {
+			return nil
+		}
+		return deployment(DeploymentCreate2, new(big.Int), data[:32], data[32:])
+	}
+
+	if len(data) < 4 {
+		return nil
+	}
+	functionInfo, ok := KnownFunctions[hex.EncodeToString(data[:4])]
+	if !ok {
+		return nil
+	}
+	values, err := functionInfo.ABI.Inputs.Unpack(data[4:])
+	if err != nil {
+		return nil
+	}
+	switch functionInfo.Signature {
+	case "performCreate2(uint256,bytes,bytes32)":
+		salt := values[2].([32]byte)
+		return deployment(DeploymentCreate2, values[0].(*big.Int), salt[:], values[1].([]byte))
+	case "performCreate(uint256,bytes)":
+		return deployment(DeploymentCreate, values[0].(*big.Int), nil, values[1].([]byte))
+	case "deploy(uint256,bytes32,bytes)":
+		if normalizedTo != strings.ToLower(Create2Deployer) {
+			return nil
+		}
+		salt := values[1].([32]byte)
+		return deployment(DeploymentCreate2, values[0].(*big.Int), salt[:], values[2].([]byte))
+	}
+	return nil
+}
+
+// decodeDeploymentCall summarizes the init code argument of a deployment, which is shown by its
+// size and hash next to the predicted address instead of as raw bytes
+func decodeDeploymentCall(args map[string]interface{}, chainID uint64) (map[string]interface{}, error) {
+	for _, key := range []string{"deploymentData", "code"} {
+		initCode, ok := args[key].(string)
+		if !ok {
+			continue
+		}
+		code, err := decodeHexDigits(initCode)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{
+			key: fmt.Sprintf("%d bytes of init code (keccak256 %s)", len(code), crypto.Keccak256Hash(code).Hex()),
+		}, nil
+	}
+	return nil, fmt.Errorf("no init code argument")
+}
+
+// PredictDeployments resolves the deployer and CREATE2 address of every deployment in a call and
+// its subcalls. sender is the account the call is made from and delegate is whether the call is
+// a DELEGATECALL, which runs the target's code as the sender.
+func PredictDeployments(call *CallData, sender string, delegate bool) {
+	executor := call.Target
+	if delegate {
+		executor = sender
+	}
+	if call.Deployment != nil {
+		call.Deployment.Deployer = common.HexToAddress(executor).Hex()
+		if call.Deployment.Method == DeploymentCreate2 {
+			call.Deployment.Address = crypto.CreateAddress2(
+				common.HexToAddress(executor),
+				common.HexToHash(call.Deployment.Salt),
+				common.FromHex(call.Deployment.InitCodeHash),
+			).Hex()
+		}
+	}
+	for i := range call.SubCalls {
+		PredictDeployments(&call.SubCalls[i], executor, call.SubCalls[i].IsDelegateCall)
+	}
+}
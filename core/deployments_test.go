@@ -0,0 +1,103 @@
+package core
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+var (
+	deploymentSalt     = common.HexToHash("0x00000000000000000000000000000000000000000000000000000000000000aa")
+	deploymentInitCode = []byte{0x60, 0x80, 0x60, 0x40, 0x52, 0x00}
+)
+
+// deploymentCall encodes a call to a known deployer function
+func deploymentCall(t *testing.T, deployer, sig string, operation uint8, args ...interface{}) multiSendTransaction {
+	t.Helper()
+	return multiSendTransaction{
+		Operation: operation,
+		To:        common.HexToAddress(deployer),
+		Value:     big.NewInt(0),
+		Data:      common.FromHex(encodeKnownCall(t, sig, args...)),
+	}
+}
+
+func TestVerifyTransactionPredictsDeployments(t *testing.T) {
+	proxyData := append(deploymentSalt.Bytes(), deploymentInitCode...)
+	result := verifiedBatch(t,
+		// Delegatecalled, CreateCall deploys from the Safe itself
+		deploymentCall(t, SafeCreateCall141, "performCreate2(uint256,bytes,bytes32)", 1, big.NewInt(0), deploymentInitCode, deploymentSalt),
+		// Called, it deploys from its own address
+		deploymentCall(t, SafeCreateCall130, "performCreate2(uint256,bytes,bytes32)", 0, big.NewInt(0), deploymentInitCode, deploymentSalt),
+		deploymentCall(t, SafeCreateCall141, "performCreate(uint256,bytes)", 1, big.NewInt(5), deploymentInitCode),
+		multiSendTransaction{To: common.HexToAddress(DeterministicDeployProxy), Value: big.NewInt(0), Data: proxyData},
+		deploymentCall(t, Create2Deployer, "deploy(uint256,bytes32,bytes)", 0, big.NewInt(7), deploymentSalt, deploymentInitCode),
+	)
+
+	initCodeHash := crypto.Keccak256(deploymentInitCode)
+	create2 := func(deployer string) string {
+		return crypto.CreateAddress2(common.HexToAddress(deployer), deploymentSalt, initCodeHash).Hex()
+	}
+	tests := []struct {
+		method, deployer, address string
+		value                     int64
+	}{
+		{DeploymentCreate2, effectsSafe, create2(effectsSafe), 0},
+		{DeploymentCreate2, SafeCreateCall130, create2(SafeCreateCall130), 0},
+		{DeploymentCreate, effectsSafe, "", 5},
+		{DeploymentCreate2, DeterministicDeployProxy, create2(DeterministicDeployProxy), 0},
+		{DeploymentCreate2, Create2Deployer, create2(Create2Deployer), 7},
+	}
+	if len(result.Call.SubCalls) != len(tests) {
+		t.Fatalf("got %d subcalls, want %d", len(result.Call.SubCalls), len(tests))
+	}
+	for i, tt := range tests {
+		deployment := result.Call.SubCalls[i].Deployment
+		if deployment == nil {
+			t.Errorf("subcall %d: no deployment decoded", i+1)
+			continue
+		}
+		if deployment.Method != tt.method || !strings.EqualFold(deployment.Deployer, tt.deployer) || deployment.Address != tt.address {
+			t.Errorf("subcall %d: got %s from %s to %q, want %s from %s to %q",
+				i+1, deployment.Method, deployment.Deployer, deployment.Address, tt.method, tt.deployer, tt.address)
+		}
+		if deployment.Value.Int64() != tt.value || deployment.InitCodeSize != len(deploymentInitCode) ||
+			deployment.InitCodeHash != common.BytesToHash(initCodeHash).Hex() {
+			t.Errorf("subcall %d: unexpected deployment %+v", i+1, deployment)
+		}
+	}
+
+	summary := result.Call.SubCalls[0].Summary
+	if want := "6 bytes of init code (keccak256 " + common.BytesToHash(initCodeHash).Hex() + ")"; len(summary) != 1 || summary[0].Value != want {
+		t.Errorf("summary = %+v, want deploymentData %s", summary, want)
+	}
+}
+
+func TestPredictDeploymentsEIP1014(t *testing.T) {
+	// Example 0 of EIP-1014
+	call := &CallData{
+		Target: "0x0000000000000000000000000000000000000000",
+		Deployment: &Deployment{
+			Method:       DeploymentCreate2,
+			Salt:         common.Hash{}.Hex(),
+			InitCodeHash: crypto.Keccak256Hash([]byte{0x00}).Hex(),
+		},
+	}
+	PredictDeployments(call, effectsSafe, false)
+	if want := "0x4D1A2e2bB4F88F0250f26Ffff098B0b30B26BF38"; call.Deployment.Address != want {
+		t.Fatalf("predicted %s, want %s", call.Deployment.Address, want)
+	}
+}
+
+func TestDecodeDeploymentIgnoresUnknownTargets(t *testing.T) {
+	data := common.FromHex(encodeKnownCall(t, "deploy(uint256,bytes32,bytes)", big.NewInt(0), deploymentSalt, deploymentInitCode))
+	if deployment := decodeDeployment(OPTokenAddress, data); deployment != nil {
+		t.Fatalf("decoded a deployment on an unknown target: %+v", deployment)
+	}
+	if deployment := decodeDeployment(DeterministicDeployProxy, []byte{0x01}); deployment != nil {
+		t.Fatalf("decoded a deployment from calldata shorter than a salt: %+v", deployment)
+	}
+}
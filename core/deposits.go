@@ -0,0 +1,108 @@
+package core
+
+import (
+	"encoding/hex"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// l1ToL2AliasOffset is added to the address of a contract that deposits through an
+// OptimismPortal to give the sender of the L2 transaction, so L1 contracts cannot impersonate
+// L2 contracts at the same address
+var l1ToL2AliasOffset = new(big.Int).SetBytes(common.FromHex("0x1111000000000000000000000000000000001111"))
+
+// Deposit is the L2 transaction an OptimismPortal depositTransaction call creates
+type Deposit struct {
+	// L2ChainID is the chain of the portal
+	L2ChainID uint64 `json:"l2ChainId"`
+
+	// L1Sender is the account that calls the portal and From its alias, the sender on L2. Both
+	// are resolved by PredictDeposits.
+	L1Sender string `json:"l1Sender,omitempty"`
+	From     string `json:"from,omitempty"`
+
+	// To is empty for a contract creation, whose init code is given by its size and hash
+	To           string `json:"to,omitempty"`
+	IsCreation   bool   `json:"isCreation"`
+	InitCodeHash string `json:"initCodeHash,omitempty"`
+	InitCodeSize int    `json:"initCodeSize,omitempty"`
+
+	// Mint is the ETH sent to the portal, which is minted to the sender on L2, and Value the
+	// ETH the L2 transaction sends to To
+	Mint     *big.Int `json:"mint,omitempty"`
+	Value    *big.Int `json:"value"`
+	GasLimit uint64   `json:"gasLimit"`
+
+	// Call is the L2 calldata decoded against the L2 chain, with subcalls indexed "L2.1", ...
+	Call *CallData `json:"call,omitempty"`
+}
+
+// decodeDeposit decodes the L2 transaction of a depositTransaction call to a known
+// OptimismPortal, or returns nil. Calls to other contracts are not deposits, whatever their
+// selector.
+func decodeDeposit(to string, data []byte, chainID uint64, options VerifyOptions) *Deposit {
+	l2ChainID, ok := portalL2Chain(to, chainID)
+	if !ok || len(data) < 4 {
+		return nil
+	}
+	functionInfo, ok := KnownFunctions[hex.EncodeToString(data[:4])]
+	if !ok || functionInfo.Signature != "depositTransaction(address,uint256,uint64,bool,bytes)" {
+		return nil
+	}
+	values, err := functionInfo.ABI.Inputs.Unpack(data[4:])
+	if err != nil {
+		return nil
+	}
+	deposit := &Deposit{
+		L2ChainID:  l2ChainID,
+		Value:      values[1].(*big.Int),
+		GasLimit:   values[2].(uint64),
+		IsCreation: values[3].(bool),
+	}
+	l2Data := values[4].([]byte)
+	if deposit.IsCreation {
+		deposit.InitCodeHash = crypto.Keccak256Hash(l2Data).Hex()
+		deposit.InitCodeSize = len(l2Data)
+		return deposit
+	}
+
+	deposit.To = values[0].(common.Address).Hex()
+	call, err := ParseTransactionData(deposit.To, "0x"+hex.EncodeToString(l2Data), deposit.L2ChainID, options)
+	if err != nil {
+		return deposit
+	}
+	assignCallIndices(call, "L2.")
+	deposit.Call = call
+	return deposit
+}
+
+// PredictDeposits resolves the L1 sender, L2 sender, and minted ETH of every deposit in a call
+// and its subcalls. sender is the account the call is made from, value the ETH it sends, and
+// delegate whether the call is a DELEGATECALL, which runs the target's code as the sender.
+func PredictDeposits(call *CallData, sender string, value *big.Int, delegate bool) {
+	executor := call.Target
+	if delegate {
+		executor = sender
+	}
+	// A delegatecall to the portal would run its code as the sender, which does not deposit
+	if call.Deposit != nil && !delegate {
+		call.Deposit.L1Sender = common.HexToAddress(sender).Hex()
+		// Every sender here is a contract, the Safe or a batching contract, so it is aliased
+		call.Deposit.From = AliasL1Address(sender)
+		call.Deposit.Mint = new(big.Int)
+		if value != nil {
+			call.Deposit.Mint.Set(value)
+		}
+	}
+	for i := range call.SubCalls {
+		PredictDeposits(&call.SubCalls[i], executor, call.SubCalls[i].Value, call.SubCalls[i].IsDelegateCall)
+	}
+}
+
+// AliasL1Address returns the address an L1 contract sends deposits from on L2
+func AliasL1Address(address string) string {
+	aliased := new(big.Int).Add(new(big.Int).SetBytes(common.HexToAddress(address).Bytes()), l1ToL2AliasOffset)
+	return common.BytesToAddress(aliased.Bytes()).Hex()
+}
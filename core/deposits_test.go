@@ -0,0 +1,106 @@
+package core
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const depositSig = "depositTransaction(address,uint256,uint64,bool,bytes)"
+
+// depositCall is a depositTransaction call on the OP Mainnet portal that mints wei on L2
+func depositCall(t *testing.T, wei *big.Int, to string, value *big.Int, isCreation bool, data string) multiSendTransaction {
+	t.Helper()
+	return multiSendTransaction{
+		To:    common.HexToAddress(OptimismPortal),
+		Value: wei,
+		Data:  common.FromHex(encodeKnownCall(t, depositSig, common.HexToAddress(to), value, uint64(200000), isCreation, common.FromHex(data))),
+	}
+}
+
+func TestDecodeDeposits(t *testing.T) {
+	l2Transfer := encodeKnownCall(t, "transfer(address,uint256)", common.HexToAddress(airdropBob), big.NewInt(25e17))
+	result := ownerResult(t,
+		depositCall(t, big.NewInt(1e18), OPTokenAddress, big.NewInt(0), false, l2Transfer),
+		depositCall(t, big.NewInt(0), ZeroAddress, big.NewInt(0), true, "0x6080"),
+	)
+
+	calls := result.Call.SubCalls
+	if len(calls) != 2 || calls[0].Deposit == nil || calls[1].Deposit == nil {
+		t.Fatalf("expected two deposits, got %+v", calls)
+	}
+	deposit := calls[0].Deposit
+	if deposit.L2ChainID != OPMainnetChainID || deposit.L1Sender != common.HexToAddress(effectsSafe).Hex() || deposit.From != AliasL1Address(effectsSafe) {
+		t.Errorf("expected the aliased Safe to send on OP Mainnet, got %+v", deposit)
+	}
+	if deposit.Mint.Cmp(big.NewInt(1e18)) != 0 || deposit.GasLimit != 200000 {
+		t.Errorf("expected 1 ETH minted with 200000 gas, got %v and %d", deposit.Mint, deposit.GasLimit)
+	}
+	if deposit.Call == nil || deposit.Call.FunctionName != "transfer" || deposit.Call.TargetName != "OP TOKEN" {
+		t.Fatalf("expected the L2 call to be an OP transfer, got %+v", deposit.Call)
+	}
+	if amount, ok := deposit.Call.Argument("amount"); !ok || amount.Display != "2.5" {
+		t.Errorf("expected the amount to be scaled on L2, got %+v", amount)
+	}
+
+	creation := calls[1].Deposit
+	if !creation.IsCreation || creation.To != "" || creation.Call != nil || creation.InitCodeSize != 2 || creation.Mint.Sign() != 0 {
+		t.Errorf("expected a contract creation with 2 bytes of init code, got %+v", creation)
+	}
+
+	// The L2 call is compared like any other decoded value
+	changed := ownerResult(t,
+		depositCall(t, big.NewInt(1e18), OPTokenAddress, big.NewInt(0), false, encodeKnownCall(t, "transfer(address,uint256)", common.HexToAddress(airdropAlice), big.NewInt(25e17))),
+		depositCall(t, big.NewInt(0), ZeroAddress, big.NewInt(0), true, "0x6080"),
+	)
+	diff := DiffTransactions(result, changed)
+	if changes := diff.DecodedValueChanges; len(changes) != 2 || changes[1].Field != "L2 call to" || changes[1].Current != common.HexToAddress(airdropAlice).Hex() {
+		t.Errorf("expected the deposit data and the L2 recipient to change, got %+v", diff.DecodedValueChanges)
+	}
+}
+
+func TestAliasL1Address(t *testing.T) {
+	for address, want := range map[string]string{
+		ZeroAddress: "0x1111000000000000000000000000000000001111",
+		"0xffffffffffffffffffffffffffffffffffffffff": "0x1111000000000000000000000000000000001110",
+	} {
+		if got := AliasL1Address(address); !strings.EqualFold(got, want) {
+			t.Errorf("AliasL1Address(%s) = %s, want %s", address, got, want)
+		}
+	}
+}
+
+func TestDecodeDepositOnlyOnKnownPortals(t *testing.T) {
+	data := common.FromHex(encodeKnownCall(t, depositSig, common.HexToAddress(airdropBob), big.NewInt(0), uint64(100000), false, []byte{}))
+
+	tests := []struct {
+		name    string
+		portal  string
+		chainID uint64
+		want    uint64
+	}{
+		{"OP Mainnet", OptimismPortal, MainnetChainID, OPMainnetChainID},
+		{"Base", BaseOptimismPortal, MainnetChainID, BaseMainnetChainID},
+		{"OP Sepolia", OPSepoliaOptimismPortal, SepoliaChainID, OPSepoliaChainID},
+		{"Base Sepolia", BaseSepoliaOptimismPortal, SepoliaChainID, BaseSepoliaChainID},
+		{"unknown contract", airdropAlice, MainnetChainID, 0},
+		{"portal on the wrong L1", OPSepoliaOptimismPortal, MainnetChainID, 0},
+		{"portal address on L2", OptimismPortal, OPMainnetChainID, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deposit := decodeDeposit(tt.portal, data, tt.chainID, VerifyOptions{})
+			if tt.want == 0 {
+				if deposit != nil {
+					t.Errorf("expected no deposit, got %+v", deposit)
+				}
+				return
+			}
+			if deposit == nil || deposit.L2ChainID != tt.want || deposit.To != common.HexToAddress(airdropBob).Hex() {
+				t.Errorf("expected a deposit to %s on chain %d, got %+v", airdropBob, tt.want, deposit)
+			}
+		})
+	}
+}
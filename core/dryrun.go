@@ -0,0 +1,69 @@
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// ErrDryRun is the error of every request a DryRunTransport does not send
+var ErrDryRun = errors.New("not sent in a dry run")
+
+// DryRunTransport is an http.RoundTripper that prints each request instead of sending it, so the
+// network use of a command can be audited before it is run on a sensitive machine. Since no
+// response comes back, a command stops at the first request whose answer it needs; lookups with
+// fallbacks still print every source they would try.
+type DryRunTransport struct {
+	Out io.Writer
+
+	mu       sync.Mutex
+	requests int
+}
+
+// RoundTrip prints the method and URL of a request, and the JSON-RPC method of an RPC call, and
+// fails it with ErrDryRun
+func (t *DryRunTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	line := fmt.Sprintf("%s %s", req.Method, req.URL.Redacted())
+	if method := rpcMethod(req); method != "" {
+		line += " " + method
+	}
+	if req.Body != nil {
+		req.Body.Close()
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.requests++
+	fmt.Fprintf(t.Out, "[dry run] %s\n", line)
+	return nil, ErrDryRun
+}
+
+// Requests returns how many requests were printed instead of sent
+func (t *DryRunTransport) Requests() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.requests
+}
+
+// rpcMethod returns the JSON-RPC method a request calls, or "" when it is not a JSON-RPC call
+func rpcMethod(req *http.Request) string {
+	if req.Method != http.MethodPost || req.GetBody == nil {
+		return ""
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return ""
+	}
+	defer body.Close()
+	var call struct {
+		JSONRPC string `json:"jsonrpc"`
+		Method  string `json:"method"`
+	}
+	if err := json.NewDecoder(body).Decode(&call); err != nil || call.JSONRPC == "" {
+		return ""
+	}
+	return call.Method
+}
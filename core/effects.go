@@ -0,0 +1,325 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Event topics decoded from execution receipts
+var (
+	executionSuccessTopic        = crypto.Keccak256Hash([]byte("ExecutionSuccess(bytes32,uint256)"))
+	executionFailureTopic        = crypto.Keccak256Hash([]byte("ExecutionFailure(bytes32,uint256)"))
+	safeMultiSigTransactionTopic = crypto.Keccak256Hash([]byte("SafeMultiSigTransaction(address,uint256,bytes,uint8,uint256,uint256,uint256,address,address,bytes,bytes)"))
+	transferTopic                = crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)"))
+	approvalTopic                = crypto.Keccak256Hash([]byte("Approval(address,address,uint256)"))
+
+	erc20ApproveSelector = crypto.Keccak256([]byte("approve(address,uint256)"))[:4]
+)
+
+// safeMultiSigTransactionArgs are the fields of the SafeMultiSigTransaction event SafeL2 emits
+var safeMultiSigTransactionArgs = abi.Arguments{
+	{Name: "to", Type: mustABIType("address")},
+	{Name: "value", Type: mustABIType("uint256")},
+	{Name: "data", Type: mustABIType("bytes")},
+	{Name: "operation", Type: mustABIType("uint8")},
+	{Name: "safeTxGas", Type: mustABIType("uint256")},
+	{Name: "baseGas", Type: mustABIType("uint256")},
+	{Name: "gasPrice", Type: mustABIType("uint256")},
+	{Name: "gasToken", Type: mustABIType("address")},
+	{Name: "refundReceiver", Type: mustABIType("address")},
+	{Name: "signatures", Type: mustABIType("bytes")},
+	{Name: "additionalInfo", Type: mustABIType("bytes")},
+}
+
+// Effect kinds
+const (
+	EffectNative   = "native"
+	EffectTransfer = "transfer"
+	EffectApproval = "approval"
+)
+
+// Effect is a token movement or allowance a transaction makes. For approvals, From is the owner
+// and To the spender.
+type Effect struct {
+	Kind        string   `json:"kind"`
+	Token       string   `json:"token,omitempty"`
+	From        string   `json:"from"`
+	To          string   `json:"to"`
+	Amount      *big.Int `json:"amount"`
+	Description string   `json:"description"`
+}
+
+// DecodedEvent is an event log emitted by an execution transaction
+type DecodedEvent struct {
+	LogIndex    uint64 `json:"logIndex"`
+	Address     string `json:"address"`
+	Contract    string `json:"contract,omitempty"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// ExecutionEffects compares the effects a transaction's calldata says it has with the events its
+// execution emitted
+type ExecutionEffects struct {
+	// Succeeded is whether the Safe emitted ExecutionSuccess for the verified safeTxHash
+	Succeeded bool           `json:"succeeded"`
+	Events    []DecodedEvent `json:"events"`
+
+	// Expected are decoded from the calldata; native transfers among them emit no events and
+	// are not compared
+	Expected []Effect `json:"expected"`
+	// Actual are the outgoing transfers and approvals of the Safe in the receipt
+	Actual []Effect `json:"actual"`
+	// Received are transfers into the Safe, such as the proceeds of a swap
+	Received   []Effect `json:"received,omitempty"`
+	Matched    int      `json:"matched"`
+	Missing    []Effect `json:"missing,omitempty"`
+	Unexpected []Effect `json:"unexpected,omitempty"`
+}
+
+// CheckExecutionEffects fetches the receipts of the already executed transactions of a result,
+// decodes their Safe and token events, and compares them with the transfers and approvals in
+// the verified calldata. The comparison is recorded on each execution and differences are added
+// to the result's warnings.
+func CheckExecutionEffects(ctx context.Context, client *RPCClient, result *VerificationResult) error {
+	for _, e := range executedTransactions(result) {
+		receipt, err := client.TransactionReceipt(ctx, e.execution().TransactionHash)
+		if err != nil {
+			return fmt.Errorf("error fetching receipt of %s: %w", e.execution().TransactionHash, err)
+		}
+		if receipt == nil {
+			// CheckFinality reports receipts the node does not have
+			continue
+		}
+		effects, warnings, err := executionEffects(e.result, receipt)
+		if err != nil {
+			return err
+		}
+		e.execution().Effects = effects
+		for _, warning := range warnings {
+			warning.Message = e.name + " " + e.execution().TransactionHash + ": " + warning.Message
+			result.Warnings = append(result.Warnings, warning)
+		}
+	}
+	return nil
+}
+
+// executionEffects decodes a receipt against the verified transaction it executed
+func executionEffects(result *VerificationResult, receipt *RPCReceipt) (*ExecutionEffects, []Warning, error) {
+	tx := result.Transaction
+	chainID := uint64(tx.Chain)
+	safe := common.HexToAddress(StripChainPrefix(tx.Safe))
+	safeTxHash := common.HexToHash(result.ApproveHash)
+
+	expected, err := expectedEffects(tx)
+	if err != nil {
+		return nil, nil, err
+	}
+	effects := &ExecutionEffects{Expected: expected}
+
+	var warnings []Warning
+	executions := 0
+	for _, log := range receipt.Logs {
+		event := DecodedEvent{LogIndex: uint64(log.LogIndex), Address: log.Address.Hex()}
+		if info, ok := GetKnownContract(log.Address.Hex(), chainID); ok {
+			event.Contract = info.Name
+		}
+		if len(log.Topics) == 0 {
+			event.Name = "anonymous event"
+			effects.Events = append(effects.Events, event)
+			continue
+		}
+
+		fromSafe := log.Address == safe
+		switch {
+		case fromSafe && (log.Topics[0] == executionSuccessTopic || log.Topics[0] == executionFailureTopic):
+			// Safe 1.4.1 indexes the hash; earlier versions log it in the data
+			var hash common.Hash
+			if len(log.Topics) > 1 {
+				hash = log.Topics[1]
+			} else if len(log.Data) >= 32 {
+				hash = common.BytesToHash(log.Data[:32])
+			}
+			event.Name = "ExecutionSuccess"
+			if log.Topics[0] == executionFailureTopic {
+				event.Name = "ExecutionFailure"
+			}
+			event.Description = "safeTxHash " + hash.Hex()
+			if hash != safeTxHash {
+				break
+			}
+			executions++
+			if event.Name == "ExecutionSuccess" {
+				effects.Succeeded = true
+			} else {
+				warnings = append(warnings, newWarning(warnExecutionFailure, "the Safe emitted ExecutionFailure: the transaction was executed but its call reverted"))
+			}
+
+		case fromSafe && log.Topics[0] == safeMultiSigTransactionTopic:
+			event.Name = "SafeMultiSigTransaction"
+			values, err := safeMultiSigTransactionArgs.Unpack(log.Data)
+			if err != nil {
+				event.Description = "undecodable: " + err.Error()
+				break
+			}
+			to, value, data, operation := values[0].(common.Address), values[1].(*big.Int), values[2].([]byte), values[3].(uint8)
+			event.Description = fmt.Sprintf("to %s, value %s, %d bytes of data, operation %d", to.Hex(), value, len(data), operation)
+			txData, _ := decodeHexDigits(tx.Data)
+			txValue := tx.Value
+			if txValue == nil {
+				txValue = new(big.Int)
+			}
+			if to != common.HexToAddress(StripChainPrefix(tx.To)) || value.Cmp(txValue) != 0 || !bytes.Equal(data, txData) || int(operation) != tx.Operation {
+				warnings = append(warnings, newWarning(warnEventMismatch,
+					"the SafeMultiSigTransaction event (%s) does not match the verified transaction", event.Description))
+			}
+
+		case log.Topics[0] == transferTopic && len(log.Topics) == 3 && len(log.Data) == 32:
+			effect := Effect{
+				Kind:   EffectTransfer,
+				Token:  log.Address.Hex(),
+				From:   common.BytesToAddress(log.Topics[1].Bytes()).Hex(),
+				To:     common.BytesToAddress(log.Topics[2].Bytes()).Hex(),
+				Amount: new(big.Int).SetBytes(log.Data),
+			}
+			effect.Description = describeEffect(effect, chainID)
+			event.Name = "Transfer"
+			event.Description = effect.Description
+			switch {
+			case effect.From == safe.Hex():
+				effects.Actual = append(effects.Actual, effect)
+			case effect.To == safe.Hex():
+				effects.Received = append(effects.Received, effect)
+			}
+
+		case log.Topics[0] == transferTopic && len(log.Topics) == 4:
+			event.Name = "Transfer"
+			event.Description = fmt.Sprintf("NFT #%s from %s to %s", log.Topics[3].Big(),
+				common.BytesToAddress(log.Topics[1].Bytes()).Hex(), common.BytesToAddress(log.Topics[2].Bytes()).Hex())
+
+		case log.Topics[0] == approvalTopic && len(log.Topics) == 3 && len(log.Data) == 32:
+			effect := Effect{
+				Kind:   EffectApproval,
+				Token:  log.Address.Hex(),
+				From:   common.BytesToAddress(log.Topics[1].Bytes()).Hex(),
+				To:     common.BytesToAddress(log.Topics[2].Bytes()).Hex(),
+				Amount: new(big.Int).SetBytes(log.Data),
+			}
+			effect.Description = describeEffect(effect, chainID)
+			event.Name = "Approval"
+			event.Description = effect.Description
+			if effect.From == safe.Hex() {
+				effects.Actual = append(effects.Actual, effect)
+			}
+
+		default:
+			event.Name = "unknown event " + log.Topics[0].Hex()
+		}
+		effects.Events = append(effects.Events, event)
+	}
+
+	if executions == 0 {
+		warnings = append(warnings, newWarning(warnWrongExecution,
+			"the receipt has no ExecutionSuccess or ExecutionFailure event from %s for safeTxHash %s; it executed a different transaction",
+			safe.Hex(), safeTxHash.Hex()))
+	}
+
+	// Match expected and actual effects regardless of order
+	used := make([]bool, len(effects.Actual))
+	for _, want := range effects.Expected {
+		if want.Kind == EffectNative {
+			continue
+		}
+		found := false
+		for i, got := range effects.Actual {
+			if !used[i] && sameEffect(want, got) {
+				used[i], found = true, true
+				break
+			}
+		}
+		if found {
+			effects.Matched++
+			continue
+		}
+		effects.Missing = append(effects.Missing, want)
+		warnings = append(warnings, newWarning(warnMissingEffect, "the calldata makes a %s that no event shows: %s", want.Kind, want.Description))
+	}
+	for i, got := range effects.Actual {
+		if !used[i] {
+			effects.Unexpected = append(effects.Unexpected, got)
+			warnings = append(warnings, newWarning(warnUnexpectedEffect, "an event shows a %s the calldata does not make: %s", got.Kind, got.Description))
+		}
+	}
+
+	return effects, warnings, nil
+}
+
+// expectedEffects decodes the transfers and approvals a transaction's calls make from the Safe
+func expectedEffects(tx SafeTransaction) ([]Effect, error) {
+	calls, err := batchCalls(tx)
+	if err != nil {
+		return nil, err
+	}
+	safe := common.HexToAddress(StripChainPrefix(tx.Safe)).Hex()
+	chainID := uint64(tx.Chain)
+
+	var effects []Effect
+	for i, call := range calls {
+		if call.Operation != 0 {
+			continue
+		}
+		effect := Effect{From: safe}
+		transfer := airdropTransfer(i+1, call)
+		switch {
+		case transfer.TokenType == AirdropERC20:
+			effect.Kind, effect.Token, effect.To, effect.Amount = EffectTransfer, transfer.Token, transfer.Receiver, transfer.Amount
+		case len(call.Data) == 4+64 && bytes.Equal(call.Data[:4], erc20ApproveSelector):
+			effect.Kind, effect.Token = EffectApproval, call.To.Hex()
+			effect.To = common.BytesToAddress(call.Data[4:36]).Hex()
+			effect.Amount = new(big.Int).SetBytes(call.Data[36:68])
+		case call.Value != nil && call.Value.Sign() > 0:
+			effect.Kind, effect.To, effect.Amount = EffectNative, call.To.Hex(), call.Value
+		default:
+			continue
+		}
+		effect.Description = describeEffect(effect, chainID)
+		effects = append(effects, effect)
+	}
+	return effects, nil
+}
+
+// sameEffect reports whether two effects move the same amount of the same token between the
+// same addresses
+func sameEffect(a, b Effect) bool {
+	return a.Kind == b.Kind && strings.EqualFold(a.Token, b.Token) && strings.EqualFold(a.From, b.From) &&
+		strings.EqualFold(a.To, b.To) && a.Amount.Cmp(b.Amount) == 0
+}
+
+// describeEffect describes an effect, using the decimals and names of known contracts
+func describeEffect(effect Effect, chainID uint64) string {
+	label := func(address string) string {
+		if info, ok := GetKnownContract(address, chainID); ok {
+			return fmt.Sprintf("%s (%s)", address, info.Name)
+		}
+		return address
+	}
+	amount := fmt.Sprintf("%s raw units of %s", effect.Amount, label(effect.Token))
+	if info, ok := GetKnownContract(effect.Token, chainID); ok && info.Decimals > 0 {
+		amount = fmt.Sprintf("%s %s", ParseDecimals(effect.Amount, info.Decimals), info.Name)
+	}
+
+	switch effect.Kind {
+	case EffectNative:
+		return fmt.Sprintf("%s ETH to %s", ParseDecimals(effect.Amount, 18), label(effect.To))
+	case EffectApproval:
+		return fmt.Sprintf("%s approves %s to spend %s", label(effect.From), label(effect.To), amount)
+	}
+	return fmt.Sprintf("%s from %s to %s", amount, label(effect.From), label(effect.To))
+}
@@ -0,0 +1,169 @@
+package core
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+)
+
+const effectsSafe = "0x4444444444444444444444444444444444444444"
+
+// approveCall is an approve(spender, amount) call on a token
+func approveCall(token, spender string, amount *big.Int) multiSendTransaction {
+	data := append(append([]byte{}, erc20ApproveSelector...), common.LeftPadBytes(common.HexToAddress(spender).Bytes(), 32)...)
+	data = append(data, math.U256Bytes(new(big.Int).Set(amount))...)
+	return multiSendTransaction{To: common.HexToAddress(token), Value: big.NewInt(0), Data: data}
+}
+
+// verifiedBatch verifies a MultiSend batch from effectsSafe
+func verifiedBatch(t *testing.T, calls ...multiSendTransaction) *VerificationResult {
+	t.Helper()
+	tx := multiSendTx(t, calls...)
+	tx.Safe = effectsSafe
+	tx.SafeVersion = "1.3.0"
+	result, err := VerifyTransaction(tx, VerifyOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return result
+}
+
+func addressTopic(address string) common.Hash {
+	return common.BytesToHash(common.HexToAddress(address).Bytes())
+}
+
+func tokenLog(topic common.Hash, token, from, to string, amount int64) RPCLog {
+	return RPCLog{
+		Address: common.HexToAddress(token),
+		Topics:  []common.Hash{topic, addressTopic(from), addressTopic(to)},
+		Data:    math.U256Bytes(big.NewInt(amount)),
+	}
+}
+
+func executionSuccessLog(safeTxHash string) RPCLog {
+	return RPCLog{
+		Address: common.HexToAddress(effectsSafe),
+		Topics:  []common.Hash{executionSuccessTopic},
+		Data:    append(common.HexToHash(safeTxHash).Bytes(), make([]byte, 32)...),
+	}
+}
+
+func TestExecutionEffectsMatch(t *testing.T) {
+	result := verifiedBatch(t,
+		erc20Transfer(USDCMainnetAddress, airdropBob, big.NewInt(2_500_000)),
+		approveCall(airdropToken, airdropAlice, big.NewInt(7)),
+		nativeTransfer(airdropAlice, big.NewInt(1e18)),
+	)
+	receipt := &RPCReceipt{Status: 1, Logs: []RPCLog{
+		tokenLog(approvalTopic, airdropToken, effectsSafe, airdropAlice, 7),
+		tokenLog(transferTopic, USDCMainnetAddress, effectsSafe, airdropBob, 2_500_000),
+		tokenLog(transferTopic, airdropToken, airdropBob, effectsSafe, 3),
+		executionSuccessLog(result.ApproveHash),
+	}}
+
+	effects, warnings, err := executionEffects(result, receipt)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !effects.Succeeded || effects.Matched != 2 || len(warnings) != 0 {
+		t.Fatalf("expected every effect to match, got %+v with warnings %+v", effects, warnings)
+	}
+	if len(effects.Expected) != 3 || effects.Expected[2].Kind != EffectNative {
+		t.Errorf("unexpected expected effects: %+v", effects.Expected)
+	}
+	if len(effects.Received) != 1 || len(effects.Events) != 4 {
+		t.Errorf("received = %+v, events = %+v", effects.Received, effects.Events)
+	}
+	if effects.Events[1].Name != "Transfer" || effects.Events[1].Description != "2.5 USDC from "+effectsSafe+" to "+common.HexToAddress(airdropBob).Hex() {
+		t.Errorf("unexpected transfer event: %+v", effects.Events[1])
+	}
+}
+
+func TestExecutionEffectsDivergences(t *testing.T) {
+	result := verifiedBatch(t,
+		erc20Transfer(USDCMainnetAddress, airdropBob, big.NewInt(2_500_000)),
+		nativeTransfer(airdropAlice, big.NewInt(1e18)),
+	)
+	receipt := &RPCReceipt{Status: 1, Logs: []RPCLog{
+		tokenLog(transferTopic, USDCMainnetAddress, effectsSafe, airdropAlice, 2_500_000),
+		executionSuccessLog(result.ApproveHash),
+	}}
+
+	effects, warnings, err := executionEffects(result, receipt)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if effects.Matched != 0 || len(effects.Missing) != 1 || len(effects.Unexpected) != 1 {
+		t.Errorf("missing = %+v, unexpected = %+v", effects.Missing, effects.Unexpected)
+	}
+	if !hasWarningContaining(warnings, "no event shows") || !hasWarningContaining(warnings, "the calldata does not make") {
+		t.Errorf("unexpected warnings: %+v", warnings)
+	}
+}
+
+func TestExecutionEffectsWrongExecution(t *testing.T) {
+	result := verifiedBatch(t, nativeTransfer(airdropAlice, big.NewInt(1e18)))
+
+	// A different safeTxHash was executed
+	other := &RPCReceipt{Status: 1, Logs: []RPCLog{executionSuccessLog("0x1234")}}
+	if _, warnings, _ := executionEffects(result, other); !HasCritical(warnings) || !hasWarningContaining(warnings, "executed a different transaction") {
+		t.Errorf("expected a critical warning, got %+v", warnings)
+	}
+
+	// The call reverted inside the Safe (Safe 1.4.1 indexes the hash)
+	failed := &RPCReceipt{Status: 1, Logs: []RPCLog{{
+		Address: common.HexToAddress(effectsSafe),
+		Topics:  []common.Hash{executionFailureTopic, common.HexToHash(result.ApproveHash)},
+		Data:    make([]byte, 32),
+	}}}
+	effects, warnings, _ := executionEffects(result, failed)
+	if effects.Succeeded || !hasWarningContaining(warnings, "ExecutionFailure") {
+		t.Errorf("expected an ExecutionFailure warning, got %+v", warnings)
+	}
+}
+
+func TestExecutionEffectsSafeMultiSigTransaction(t *testing.T) {
+	result := verifiedBatch(t, nativeTransfer(airdropAlice, big.NewInt(1e18)))
+	tx := result.Transaction
+	data, _ := decodeHexDigits(tx.Data)
+
+	event := func(value int64) RPCLog {
+		packed, err := safeMultiSigTransactionArgs.Pack(common.HexToAddress(tx.To), big.NewInt(value), data, uint8(tx.Operation),
+			big.NewInt(0), big.NewInt(0), big.NewInt(0), common.Address{}, common.Address{}, []byte{}, []byte{})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return RPCLog{Address: common.HexToAddress(effectsSafe), Topics: []common.Hash{safeMultiSigTransactionTopic}, Data: packed}
+	}
+
+	matching := &RPCReceipt{Status: 1, Logs: []RPCLog{event(0), executionSuccessLog(result.ApproveHash)}}
+	if _, warnings, _ := executionEffects(result, matching); len(warnings) != 0 {
+		t.Errorf("unexpected warnings: %+v", warnings)
+	}
+	tampered := &RPCReceipt{Status: 1, Logs: []RPCLog{event(5), executionSuccessLog(result.ApproveHash)}}
+	if _, warnings, _ := executionEffects(result, tampered); !hasWarningContaining(warnings, "does not match the verified transaction") {
+		t.Errorf("expected a mismatch warning, got %+v", warnings)
+	}
+}
+
+func TestCheckExecutionEffects(t *testing.T) {
+	result := verifiedBatch(t, erc20Transfer(USDCMainnetAddress, airdropBob, big.NewInt(1)))
+	result.Transaction.Execution = &Execution{TransactionHash: finalityTxHash, BlockNumber: 100}
+	node := executedNode(200, 150)
+	node.chainID = MainnetChainID
+	node.receipts[finalityTxHash].Logs = []RPCLog{executionSuccessLog(result.ApproveHash)}
+
+	if err := CheckExecutionEffects(context.Background(), newFakeNode(t, node), result); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	effects := result.Transaction.Execution.Effects
+	if effects == nil || !effects.Succeeded || len(effects.Missing) != 1 {
+		t.Fatalf("unexpected effects: %+v", effects)
+	}
+	if !hasWarningContaining(result.Warnings, "Execution transaction "+finalityTxHash+": the calldata makes a transfer") {
+		t.Errorf("expected a prefixed warning, got %+v", result.Warnings)
+	}
+}
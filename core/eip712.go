@@ -0,0 +1,107 @@
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+)
+
+// HashTypedData returns the EIP-712 signing hash
+// keccak256("\x19\x01" || domainSeparator || hashStruct(message)), as eth_signTypedData_v4 signs it
+func HashTypedData(td apitypes.TypedData) (common.Hash, error) {
+	hash, _, err := apitypes.TypedDataAndHash(td)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return common.BytesToHash(hash), nil
+}
+
+// DecodeTypedData decodes an eth_signTypedData_v4 payload. Integers in the message are decoded
+// as big integers, so values beyond 2^53 are hashed exactly instead of as rounded floats.
+func DecodeTypedData(data []byte) (apitypes.TypedData, error) {
+	var td apitypes.TypedData
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&td); err != nil {
+		return apitypes.TypedData{}, err
+	}
+	exactNumbers(td.Message)
+	return td, nil
+}
+
+// exactNumbers replaces every integer json.Number in a decoded JSON value with a *big.Int. Other
+// numbers are left as they are, for the encoder to reject.
+func exactNumbers(value interface{}) interface{} {
+	switch v := value.(type) {
+	case json.Number:
+		if n, ok := new(big.Int).SetString(v.String(), 10); ok {
+			return n
+		}
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = exactNumbers(item)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = exactNumbers(item)
+		}
+	}
+	return value
+}
+
+// typedBytes decodes a 0x-prefixed hex string into bytes
+func typedBytes(value interface{}) ([]byte, error) {
+	str, ok := value.(string)
+	if !ok || !strings.HasPrefix(str, "0x") {
+		return nil, fmt.Errorf("expected 0x-prefixed hex, got %v", value)
+	}
+	raw, err := decodeHexDigits(str)
+	if err != nil {
+		return nil, err
+	}
+	return raw, nil
+}
+
+// decodeHexDigits decodes hex strings, allowing an odd number of digits
+func decodeHexDigits(str string) ([]byte, error) {
+	digits := strings.TrimPrefix(str, "0x")
+	if len(digits)%2 == 1 {
+		digits = "0" + digits
+	}
+	raw := common.FromHex(digits)
+	if len(raw)*2 != len(digits) {
+		return nil, fmt.Errorf("invalid hex %q", str)
+	}
+	return raw, nil
+}
+
+// typedInteger parses an integer given as a JSON number or a decimal/hex string
+func typedInteger(value interface{}) (*big.Int, error) {
+	switch v := value.(type) {
+	case json.Number:
+		return typedInteger(v.String())
+	case float64:
+		n, accuracy := big.NewFloat(v).Int(nil)
+		if accuracy != big.Exact {
+			return nil, fmt.Errorf("non-integer number %v", v)
+		}
+		return n, nil
+	case string:
+		n, ok := math.ParseBig256(v)
+		if !ok {
+			// ParseBig256 rejects negatives; fall back to signed decimal parsing
+			n, ok = new(big.Int).SetString(v, 10)
+			if !ok {
+				return nil, fmt.Errorf("invalid integer %q", v)
+			}
+		}
+		return n, nil
+	}
+	return nil, fmt.Errorf("expected integer, got %T", value)
+}
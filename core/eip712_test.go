@@ -0,0 +1,114 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// mailTypedData is the example from the EIP-712 specification
+const mailTypedData = `{
+  "types": {
+    "EIP712Domain": [
+      {"name": "name", "type": "string"},
+      {"name": "version", "type": "string"},
+      {"name": "chainId", "type": "uint256"},
+      {"name": "verifyingContract", "type": "address"}
+    ],
+    "Person": [
+      {"name": "name", "type": "string"},
+      {"name": "wallet", "type": "address"}
+    ],
+    "Mail": [
+      {"name": "from", "type": "Person"},
+      {"name": "to", "type": "Person"},
+      {"name": "contents", "type": "string"}
+    ]
+  },
+  "primaryType": "Mail",
+  "domain": {
+    "name": "Ether Mail",
+    "version": "1",
+    "chainId": 1,
+    "verifyingContract": "0xCcCCccccCCCCcCCCCCCcCcCccCcCCCcCcccccccC"
+  },
+  "message": {
+    "from": {"name": "Cow", "wallet": "0xCD2a3d9F938E13CD947Ec05AbC7FE734Df8DD826"},
+    "to": {"name": "Bob", "wallet": "0xbBbBBBBbbBBBbbbBbbBbbbbBBbBbbbbBbBbbBBbB"},
+    "contents": "Hello, Bob!"
+  }
+}`
+
+func TestTypedDataMailExample(t *testing.T) {
+	td, err := DecodeTypedData([]byte(mailTypedData))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	hash, err := HashTypedData(td)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "0xbe609aee343fb3c4b28e1df9e632fca64fcfaede20f02e86244efddf30957bd2"; hash.Hex() != want {
+		t.Errorf("hash = %s, want %s", hash.Hex(), want)
+	}
+}
+
+func TestDecodeTypedDataKeepsLargeIntegers(t *testing.T) {
+	raw := `{
+		"types": {
+			"EIP712Domain": [{"name": "chainId", "type": "uint256"}],
+			"Transfer": [{"name": "amount", "type": "uint256"}]
+		},
+		"primaryType": "Transfer",
+		"domain": {"chainId": 10},
+		"message": {"amount": %s}
+	}`
+	number, err := DecodeTypedData([]byte(fmt.Sprintf(raw, "123456789012345678901")))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	text, err := DecodeTypedData([]byte(fmt.Sprintf(raw, `"123456789012345678901"`)))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	numberHash, err := HashTypedData(number)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	textHash, err := HashTypedData(text)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if numberHash != textHash {
+		t.Errorf("a JSON number hashes to %s, its decimal string to %s", numberHash.Hex(), textHash.Hex())
+	}
+}
+
+func TestTypedDataRejectsInvalidValues(t *testing.T) {
+	tests := []struct {
+		name string
+		old  string
+		new  string
+	}{
+		{"bad address", `"0xCD2a3d9F938E13CD947Ec05AbC7FE734Df8DD826"`, `"0x1234"`},
+		{"number as string field", `"name": "Cow"`, `"name": 1`},
+		{"missing field", `"to": {"name": "Bob", "wallet": "0xbBbBBBBbbBBBbbbBbbBbbbbBBbBbbbbBbBbbBBbB"},`, ``},
+		{"extra field", `"contents": "Hello, Bob!"`, `"contents": "Hello, Bob!", "cc": "Alice"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			raw := strings.Replace(mailTypedData, tt.old, tt.new, 1)
+			if raw == mailTypedData {
+				t.Fatalf("%s is not in the example", tt.old)
+			}
+			td, err := DecodeTypedData([]byte(raw))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if _, err := HashTypedData(td); err == nil {
+				t.Fatalf("expected error")
+			}
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package core
+
+import (
+	"encoding/hex"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+)
+
+// emergencyABIJSON are functions that halt or restrict a contract: pausing, including the
+// SuperchainConfig's pause with and without an identifier, and blacklisting or freezing accounts
+var emergencyABIJSON = []string{
+	`[{"inputs":[],"name":"pause","outputs":[],"stateMutability":"nonpayable","type":"function"}]`,
+	`[{"inputs":[],"name":"unpause","outputs":[],"stateMutability":"nonpayable","type":"function"}]`,
+	`[{"inputs":[{"name":"_identifier","type":"string"}],"name":"pause","outputs":[],"stateMutability":"nonpayable","type":"function"}]`,
+	`[{"inputs":[{"name":"_identifier","type":"address"}],"name":"pause","outputs":[],"stateMutability":"nonpayable","type":"function"}]`,
+	`[{"inputs":[{"name":"_identifier","type":"address"}],"name":"unpause","outputs":[],"stateMutability":"nonpayable","type":"function"}]`,
+	`[{"inputs":[{"name":"paused","type":"bool"}],"name":"setPaused","outputs":[],"stateMutability":"nonpayable","type":"function"}]`,
+	`[{"inputs":[{"name":"account","type":"address"}],"name":"blacklist","outputs":[],"stateMutability":"nonpayable","type":"function"}]`,
+	`[{"inputs":[{"name":"account","type":"address"}],"name":"unBlacklist","outputs":[],"stateMutability":"nonpayable","type":"function"}]`,
+	`[{"inputs":[{"name":"account","type":"address"}],"name":"freeze","outputs":[],"stateMutability":"nonpayable","type":"function"}]`,
+	`[{"inputs":[{"name":"account","type":"address"}],"name":"unfreeze","outputs":[],"stateMutability":"nonpayable","type":"function"}]`,
+}
+
+// EmergencyFunctions are the selectors of emergency functions, such as pause and blacklist. Their
+// calls are marked as emergency actions so that they stand out in a long batch.
+var EmergencyFunctions = map[string]string{}
+
+func init() {
+	registerKnownABIs(emergencyABIJSON)
+	for _, abiJSON := range emergencyABIJSON {
+		parsed, err := abi.JSON(strings.NewReader(abiJSON))
+		if err != nil {
+			continue
+		}
+		for _, method := range parsed.Methods {
+			EmergencyFunctions[hex.EncodeToString(method.ID)] = method.Sig
+		}
+	}
+}
+
+// isEmergencySelector reports whether calldata calls an emergency function
+func isEmergencySelector(selector string) bool {
+	_, ok := EmergencyFunctions[strings.ToLower(selector)]
+	return ok
+}
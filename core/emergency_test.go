@@ -0,0 +1,48 @@
+package core
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestEmergencyCalls(t *testing.T) {
+	result := ownerResult(t,
+		erc20Transfer(airdropToken, airdropBob, big.NewInt(1)),
+		ownableCall(t, "pause()"),
+		ownableCall(t, "blacklist(address)", common.HexToAddress(airdropAlice)),
+		ownableCall(t, "setPaused(bool)", false),
+	)
+
+	calls := result.Call.SubCalls
+	if calls[0].Emergency {
+		t.Error("a transfer is not an emergency action")
+	}
+	for _, call := range calls[1:] {
+		if !call.Emergency {
+			t.Errorf("expected %s to be an emergency action", call.FunctionName)
+		}
+	}
+
+	// Emergency calls are marked even when their arguments do not decode
+	call, err := ParseTransactionData(airdropToken, "0x"+selectorOf(t, "blacklist(address)")+"00", OPMainnetChainID, VerifyOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if call.RawData == "" || !call.Emergency {
+		t.Errorf("expected undecodable blacklist calldata to be marked, got %+v", call)
+	}
+}
+
+// selectorOf returns the selector of the emergency function with a signature
+func selectorOf(t *testing.T, sig string) string {
+	t.Helper()
+	for selector, signature := range EmergencyFunctions {
+		if signature == sig {
+			return selector
+		}
+	}
+	t.Fatalf("no emergency function %s", sig)
+	return ""
+}
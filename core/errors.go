@@ -0,0 +1,52 @@
+package core
+
+import "errors"
+
+// Kinds of errors returned by this package. Errors wrap one of them, when one applies, so that
+// callers can tell them apart with errors.Is instead of matching messages; the message of the
+// wrapping error says what exactly went wrong.
+var (
+	// ErrTxNotFound is returned when the Safe service has no transaction, transfer, or module
+	// transaction at the nonce, hash, or ID asked for
+	ErrTxNotFound = errors.New("transaction not found")
+
+	// ErrUnsupportedChain is returned for a network or chain op-txverify has no Safe service,
+	// contracts, or other support for
+	ErrUnsupportedChain = errors.New("unsupported chain")
+
+	// ErrUnsupportedSafeVersion is returned for a Safe version whose transaction hashing has not
+	// been verified
+	ErrUnsupportedSafeVersion = errors.New("unsupported Safe version")
+
+	// ErrDecodeFailure is returned when calldata, a transaction encoding, or another payload
+	// cannot be decoded
+	ErrDecodeFailure = errors.New("decode failure")
+
+	// ErrPolicyViolation is returned when an action is refused because the transaction breaks a
+	// rule, such as signing a transaction that raised a critical warning
+	ErrPolicyViolation = errors.New("policy violation")
+)
+
+// kindError marks an error as of one of the kinds above without changing its message
+type kindError struct {
+	kind error
+	err  error
+}
+
+func (e *kindError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap lets errors.Is and errors.As match both the kind and the underlying error
+func (e *kindError) Unwrap() []error {
+	return []error{e.kind, e.err}
+}
+
+// withKind marks err as of kind. The message is kept as it is, since it already says what went
+// wrong and the kind only serves to branch on.
+func withKind(kind, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &kindError{kind: kind, err: err}
+}
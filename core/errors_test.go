@@ -0,0 +1,84 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestErrorKinds(t *testing.T) {
+	server := newFixtureServer(t, defaultFixtureRoutes())
+	client := NewHTTPSafeClient(server.URL)
+	ctx := context.Background()
+
+	for _, test := range []struct {
+		name string
+		kind error
+		call func() error
+	}{
+		{"missing transaction hash", ErrTxNotFound, func() error {
+			_, err := client.GetMultisigTransaction(ctx, "0x"+strings.Repeat("ab", 32))
+			return err
+		}},
+		{"missing transfer", ErrTxNotFound, func() error {
+			_, err := client.GetTransfer(ctx, "missing")
+			return err
+		}},
+		{"safeTxHash not queued at the nonce", ErrTxNotFound, func() error {
+			_, err := GenerateTransactionWithClient(ctx, client, OPMainnetChainID, fixtureGrantsSafe, 155, "0x"+strings.Repeat("ab", 32))
+			return err
+		}},
+		{"unknown network", ErrUnsupportedChain, func() error { return ValidateNetwork("goerli") }},
+		{"chain without a Safe service", ErrUnsupportedChain, func() error {
+			_, err := getServiceURL(999999)
+			return err
+		}},
+		{"unverified Safe version", ErrUnsupportedSafeVersion, func() error {
+			_, err := HashingStrategyFor("9.0.0")
+			return err
+		}},
+		{"pre-release Safe version", ErrUnsupportedSafeVersion, func() error {
+			_, err := HashingStrategyFor("1.4.1-rc.1")
+			return err
+		}},
+		{"truncated binary encoding", ErrDecodeFailure, func() error {
+			_, err := DecodeTransaction([]byte{TransactionEncodingVersion, 0xc0})
+			return err
+		}},
+		{"payload that is neither JSON nor binary", ErrDecodeFailure, func() error {
+			_, err := DecodeTransactionPayload([]byte("not a transaction"))
+			return err
+		}},
+		{"undecodable link", ErrDecodeFailure, func() error {
+			_, _, err := DecodeTransactionLink("https://op-txverify.optimism.io/?tx=!!!!")
+			return err
+		}},
+		{"signing with a critical warning", ErrPolicyViolation, func() error {
+			return RefuseCritical("sign", []Warning{newWarning(warnDenylisted, "bad")})
+		}},
+	} {
+		err := test.call()
+		if !errors.Is(err, test.kind) {
+			t.Errorf("%s: expected %v, got %v", test.name, test.kind, err)
+		}
+	}
+}
+
+func TestErrorKindsKeepMessages(t *testing.T) {
+	_, err := HashingStrategyFor("9.0.0")
+	if err == nil || err.Error() != `unsupported Safe version "9.0.0": hashing has not been verified for this release` {
+		t.Errorf("expected the message to be unchanged, got %v", err)
+	}
+
+	// A Safe the service does not know is not a missing transaction
+	server := newFixtureServer(t, defaultFixtureRoutes())
+	_, err = NewHTTPSafeClient(server.URL).GetSafeInfo(context.Background(), "0x0000000000000000000000000000000000000001")
+	if err == nil || errors.Is(err, ErrTxNotFound) {
+		t.Errorf("expected a plain error for an unknown Safe, got %v", err)
+	}
+
+	if err := RefuseCritical("sign", []Warning{newWarning(warnSelfCall, "minor")}); err != nil {
+		t.Errorf("expected no error without critical warnings, got %v", err)
+	}
+}
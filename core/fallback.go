@@ -0,0 +1,238 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ServiceFallback configures where lookups turn when the Safe Transaction Service fails, so that
+// an outage of the public service does not block a signing ceremony
+type ServiceFallback struct {
+	// Attempts is how many times the service is asked before falling back, and Backoff how long
+	// to wait before the first retry, doubling for each retry after it
+	Attempts int
+	Backoff  time.Duration
+
+	// Mirrors maps chain IDs to other deployments of the Safe Transaction Service, such as a
+	// self-hosted one, asked in order after the service
+	Mirrors map[uint64][]string
+
+	// Cache keeps every response of the service, and answers from them after the mirrors
+	Cache *ServiceCache
+
+	// RPC maps chain IDs to JSON-RPC endpoints; the first one reconstructs executed
+	// transactions from the chain when nothing else answers
+	RPC map[uint64][]string
+}
+
+// serviceFallback is the fallback of the Safe service lookups, none until SetServiceFallback
+var serviceFallback ServiceFallback
+
+// SetServiceFallback configures where the Safe service lookups of this package fall back to
+func SetServiceFallback(fallback ServiceFallback) {
+	serviceFallback = fallback
+}
+
+// serviceClient returns the client for the Safe Transaction Service of a chain, falling back as
+// configured by SetServiceFallback
+func serviceClient(chainID uint64, apiURL string) SafeClient {
+	primary := NewHTTPSafeClient(apiURL)
+	primary.Cache = serviceFallback.Cache
+	sources := []ServiceSource{{Name: "the Safe Transaction Service at " + apiURL, Client: primary}}
+	for _, mirror := range serviceFallback.Mirrors[chainID] {
+		sources = append(sources, ServiceSource{Name: "the Safe service mirror at " + mirror, Client: NewHTTPSafeClient(mirror)})
+	}
+	if serviceFallback.Cache != nil {
+		sources = append(sources, ServiceSource{Name: "cached responses of " + apiURL, Client: NewCachedSafeClient(apiURL, serviceFallback.Cache)})
+	}
+	if urls := serviceFallback.RPC[chainID]; len(urls) > 0 {
+		sources = append(sources, ServiceSource{
+			Name:   "SafeMultiSigTransaction events read from " + urls[0],
+			Client: &OnchainSafeClient{RPC: NewRPCClient(urls[0]), ChainID: chainID},
+		})
+	}
+	if len(sources) == 1 && serviceFallback.Attempts <= 1 {
+		return primary
+	}
+	return &FallbackSafeClient{Sources: sources, Attempts: serviceFallback.Attempts, Backoff: serviceFallback.Backoff}
+}
+
+// ServiceSource is a source of Safe service data: the service, a mirror, cached responses, or
+// the chain
+type ServiceSource struct {
+	Name   string
+	Client SafeClient
+}
+
+// FallbackSafeClient is a SafeClient that asks its sources in order until one answers. The first
+// source is retried on failure before moving on. A source that answers that there is no such
+// transaction is believed, since a source that is up and disagrees would be the odd one out.
+type FallbackSafeClient struct {
+	Sources []ServiceSource
+
+	// Attempts is how many times the first source is asked, and Backoff the wait before the
+	// first retry, doubling for each retry after it
+	Attempts int
+	Backoff  time.Duration
+
+	// used records which sources after the first answered
+	used map[int]bool
+}
+
+// Fallbacks names the sources other than the first that answered a lookup, with when the
+// oldest cached response served was fetched
+func (c *FallbackSafeClient) Fallbacks() []string {
+	var names []string
+	for i, source := range c.Sources {
+		if !c.used[i] {
+			continue
+		}
+		name := source.Name
+		if cached, ok := source.Client.(*HTTPSafeClient); ok && !cached.OldestCached().IsZero() {
+			name += " fetched " + cached.OldestCached().UTC().Format(time.RFC3339)
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
+// try performs a lookup against each source in turn until one answers
+func (c *FallbackSafeClient) try(ctx context.Context, lookup func(SafeClient) error) error {
+	var failures []string
+	for i, source := range c.Sources {
+		attempts := 1
+		if i == 0 && c.Attempts > 1 {
+			attempts = c.Attempts
+		}
+		backoff := c.Backoff
+		var err error
+		for attempt := 0; attempt < attempts; attempt++ {
+			if attempt > 0 {
+				select {
+				case <-ctx.Done():
+					return ctx.Err()
+				case <-time.After(backoff):
+				}
+				backoff *= 2
+			}
+			if err = lookup(source.Client); err == nil || !serviceUnavailable(ctx, err) {
+				break
+			}
+		}
+		if err == nil {
+			if i > 0 {
+				if c.used == nil {
+					c.used = map[int]bool{}
+				}
+				c.used[i] = true
+			}
+			return nil
+		}
+		if !serviceUnavailable(ctx, err) {
+			return err
+		}
+		failures = append(failures, fmt.Sprintf("%s: %v", source.Name, err))
+	}
+	return fmt.Errorf("the Safe service and its fallbacks failed: %s", strings.Join(failures, "; "))
+}
+
+// serviceUnavailable reports whether a lookup failed because its source could not answer, rather
+// than because it answered that there is no such transaction or the lookup was cancelled
+func serviceUnavailable(ctx context.Context, err error) bool {
+	return ctx.Err() == nil && !errors.Is(err, errServiceNotFound) && !errors.Is(err, context.Canceled)
+}
+
+// GetSafeInfo asks each source in turn
+func (c *FallbackSafeClient) GetSafeInfo(ctx context.Context, safeAddress string) (info *SafeInfoResponse, err error) {
+	err = c.try(ctx, func(client SafeClient) error {
+		info, err = client.GetSafeInfo(ctx, safeAddress)
+		return err
+	})
+	return info, err
+}
+
+// GetMultisigTransactions asks each source in turn
+func (c *FallbackSafeClient) GetMultisigTransactions(ctx context.Context, safeAddress string, nonce uint64) (resp *APIResponse, err error) {
+	err = c.try(ctx, func(client SafeClient) error {
+		resp, err = client.GetMultisigTransactions(ctx, safeAddress, nonce)
+		return err
+	})
+	return resp, err
+}
+
+// GetPendingTransactions asks each source in turn
+func (c *FallbackSafeClient) GetPendingTransactions(ctx context.Context, safeAddress string, fromNonce uint64) (resp *APIResponse, err error) {
+	err = c.try(ctx, func(client SafeClient) error {
+		resp, err = client.GetPendingTransactions(ctx, safeAddress, fromNonce)
+		return err
+	})
+	return resp, err
+}
+
+// GetMultisigTransaction asks each source in turn
+func (c *FallbackSafeClient) GetMultisigTransaction(ctx context.Context, safeTxHash string) (tx *APITransaction, err error) {
+	err = c.try(ctx, func(client SafeClient) error {
+		tx, err = client.GetMultisigTransaction(ctx, safeTxHash)
+		return err
+	})
+	return tx, err
+}
+
+// GetTransfer asks each source in turn
+func (c *FallbackSafeClient) GetTransfer(ctx context.Context, transferID string) (transfer *Transfer, err error) {
+	err = c.try(ctx, func(client SafeClient) error {
+		transfer, err = client.GetTransfer(ctx, transferID)
+		return err
+	})
+	return transfer, err
+}
+
+// GetModuleTransactions asks each source in turn
+func (c *FallbackSafeClient) GetModuleTransactions(ctx context.Context, safeAddress string, limit int) (resp *ModuleTransactionsResponse, err error) {
+	err = c.try(ctx, func(client SafeClient) error {
+		resp, err = client.GetModuleTransactions(ctx, safeAddress, limit)
+		return err
+	})
+	return resp, err
+}
+
+// GetModuleTransaction asks each source in turn
+func (c *FallbackSafeClient) GetModuleTransaction(ctx context.Context, id string) (tx *ModuleTransaction, err error) {
+	err = c.try(ctx, func(client SafeClient) error {
+		tx, err = client.GetModuleTransaction(ctx, id)
+		return err
+	})
+	return tx, err
+}
+
+// GetSafeMessages asks each source in turn
+func (c *FallbackSafeClient) GetSafeMessages(ctx context.Context, safeAddress string) (resp *SafeMessagesResponse, err error) {
+	err = c.try(ctx, func(client SafeClient) error {
+		resp, err = client.GetSafeMessages(ctx, safeAddress)
+		return err
+	})
+	return resp, err
+}
+
+// checkFallbackSources warns when the transaction was not loaded from the Safe Transaction
+// Service. The hashes are computed here either way, but what only the service knows, such as
+// signatures, proposers, and replacements, may be stale or missing.
+func checkFallbackSources(sources []string) []Warning {
+	if len(sources) == 0 {
+		return nil
+	}
+	return []Warning{newWarning(warnServiceFallback,
+		"The Safe Transaction Service failed, so the transaction was loaded from %s. Signer progress, the proposer, and other transactions queued for the nonce may be stale or missing; confirm the Safe tx hash with another signer before signing.",
+		strings.Join(sources, " and "))}
+}
+
+// fallbackSources names the fallbacks a client used, if it falls back at all
+func fallbackSources(client SafeClient) []string {
+	if fallback, ok := client.(*FallbackSafeClient); ok {
+		return fallback.Fallbacks()
+	}
+	return nil
+}
@@ -0,0 +1,208 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// downServer is a Safe service that fails every request, counting them
+func downServer(t *testing.T, requests *int32) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(requests, 1)
+		http.Error(w, "upstream unavailable", http.StatusServiceUnavailable)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestFallbackSafeClientMirror(t *testing.T) {
+	var requests int32
+	primary := downServer(t, &requests)
+	mirror := newFixtureServer(t, defaultFixtureRoutes())
+	client := &FallbackSafeClient{
+		Sources: []ServiceSource{
+			{Name: "the service", Client: NewHTTPSafeClient(primary.URL)},
+			{Name: "the mirror", Client: NewHTTPSafeClient(mirror.URL)},
+		},
+		Attempts: 2,
+		Backoff:  time.Millisecond,
+	}
+
+	tx, err := GenerateTransactionWithClient(context.Background(), client, OPMainnetChainID, fixtureGrantsSafe, 155, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(tx.FallbackSources) != 1 || tx.FallbackSources[0] != "the mirror" {
+		t.Errorf("expected the mirror to be named, got %v", tx.FallbackSources)
+	}
+	if requests < 2 || requests%2 != 0 {
+		t.Errorf("expected the service to be asked twice per lookup, got %d requests", requests)
+	}
+	if hash, err := CalculateApproveHash(*tx); err != nil || hash != fixtureGrantsHash {
+		t.Errorf("safe tx hash = %s, %v", hash, err)
+	}
+
+	result, err := VerifyTransaction(*tx, VerifyOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !hasWarningContaining(result.Warnings, "loaded from the mirror") {
+		t.Errorf("expected a fallback warning, got %+v", result.Warnings)
+	}
+}
+
+func TestFallbackSafeClientBelievesNotFound(t *testing.T) {
+	var requests int32
+	primary := newFixtureServer(t, defaultFixtureRoutes())
+	mirror := downServer(t, &requests)
+	client := &FallbackSafeClient{Sources: []ServiceSource{
+		{Name: "the service", Client: NewHTTPSafeClient(primary.URL)},
+		{Name: "the mirror", Client: NewHTTPSafeClient(mirror.URL)},
+	}}
+
+	_, err := client.GetMultisigTransaction(context.Background(), "0x"+strings.Repeat("ab", 32))
+	if !errors.Is(err, ErrTxNotFound) {
+		t.Errorf("expected the service's not found, got %v", err)
+	}
+	if requests != 0 || len(client.Fallbacks()) != 0 {
+		t.Errorf("expected no fallback, got %d requests", requests)
+	}
+}
+
+func TestFallbackSafeClientAllFail(t *testing.T) {
+	var requests int32
+	server := downServer(t, &requests)
+	client := &FallbackSafeClient{Sources: []ServiceSource{
+		{Name: "the service", Client: NewHTTPSafeClient(server.URL)},
+		{Name: "the cache", Client: NewCachedSafeClient(server.URL, &ServiceCache{Dir: t.TempDir()})},
+	}}
+
+	_, err := client.GetSafeInfo(context.Background(), fixtureGrantsSafe)
+	if err == nil || !strings.Contains(err.Error(), "the service:") || !strings.Contains(err.Error(), "the cache: no cached response") {
+		t.Errorf("expected every source's failure, got %v", err)
+	}
+}
+
+func TestServiceCacheFallback(t *testing.T) {
+	server := newFixtureServer(t, defaultFixtureRoutes())
+	cache := &ServiceCache{Dir: t.TempDir()}
+	primary := NewHTTPSafeClient(server.URL)
+	primary.Cache = cache
+	if _, err := GenerateTransactionWithClient(context.Background(), primary, OPMainnetChainID, fixtureGrantsSafe, 155, ""); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// The service goes down after the transaction was fetched once
+	var requests int32
+	down := downServer(t, &requests)
+	client := &FallbackSafeClient{Sources: []ServiceSource{
+		{Name: "the service", Client: NewHTTPSafeClient(down.URL)},
+		{Name: "cached responses", Client: NewCachedSafeClient(server.URL, cache)},
+	}}
+	tx, err := GenerateTransactionWithClient(context.Background(), client, OPMainnetChainID, fixtureGrantsSafe, 155, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(tx.FallbackSources) != 1 || !strings.HasPrefix(tx.FallbackSources[0], "cached responses fetched ") {
+		t.Errorf("expected the cache and its fetch time to be named, got %v", tx.FallbackSources)
+	}
+	if hash, err := CalculateApproveHash(*tx); err != nil || hash != fixtureGrantsHash {
+		t.Errorf("safe tx hash = %s, %v", hash, err)
+	}
+}
+
+func TestServiceCacheRejectsOtherURL(t *testing.T) {
+	cache := &ServiceCache{Dir: t.TempDir()}
+	fetched := time.Date(2025, 3, 11, 17, 0, 0, 0, time.UTC)
+	if err := cache.Store("https://a.example/x", []byte(`{"ok":true}`), fetched); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	body, at, err := cache.Load("https://a.example/x")
+	if err != nil || string(body) != `{"ok":true}` || !at.Equal(fetched) {
+		t.Errorf("unexpected cached response %s at %s, %v", body, at, err)
+	}
+	if _, _, err := cache.Load("https://b.example/x"); err == nil {
+		t.Error("expected no response for another URL")
+	}
+	if err := cache.Store("https://a.example/y", []byte("<html>"), fetched); err == nil {
+		t.Error("expected a non-JSON response not to be cached")
+	}
+}
+
+// onchainGrantsNode is a node on which the grants Safe executed its fixture transaction at nonce
+// 155 in block 25_000, reported in a SafeMultiSigTransaction event
+func onchainGrantsNode(t *testing.T, nonce int64) *RPCClient {
+	t.Helper()
+	pack := func(args abi.Arguments, values ...interface{}) hexutil.Bytes {
+		packed, err := args.Pack(values...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return packed
+	}
+	proposer := common.HexToAddress("0x9A69d97a451643a0Bb4462476942D2bC844431cE")
+	info := pack(safeAdditionalInfoArgs, big.NewInt(155), proposer, big.NewInt(2))
+	data := common.FromHex("0xa9059cbb0000000000000000000000008b8b2f214d92527bf1b1148dc2e609a4c1c2fd69000000000000000000000000000000000000000000034f086f3b33b684000000")
+	event := pack(safeMultiSigTransactionArgs, common.HexToAddress("0x4200000000000000000000000000000000000042"), big.NewInt(0), data, uint8(0),
+		big.NewInt(0), big.NewInt(0), big.NewInt(0), common.Address{}, common.Address{}, []byte{}, []byte(info))
+
+	safe := strings.ToLower(fixtureGrantsSafe)
+	node := &fakeNode{
+		chainID: OPMainnetChainID,
+		latest:  40_000,
+		calls: map[string]hexutil.Bytes{
+			safe + ":" + hexutil.Encode(safeVersionSelector):  pack(abi.Arguments{{Type: mustABIType("string")}}, "1.3.0"),
+			safe + ":" + hexutil.Encode(safeNonceSelector):    pack(abi.Arguments{{Type: mustABIType("uint256")}}, big.NewInt(nonce)),
+			safe + ":" + hexutil.Encode(getOwnersSelector):    pack(abi.Arguments{{Type: mustABIType("address[]")}}, []common.Address{proposer}),
+			safe + ":" + hexutil.Encode(getThresholdSelector): pack(abi.Arguments{{Type: mustABIType("uint256")}}, big.NewInt(2)),
+		},
+		logs: []RPCLog{{
+			Address:         common.HexToAddress(fixtureGrantsSafe),
+			Topics:          []common.Hash{safeMultiSigTransactionTopic},
+			Data:            event,
+			BlockNumber:     25_000,
+			TransactionHash: common.HexToHash("0x01"),
+		}},
+	}
+	return newFakeNode(t, node)
+}
+
+func TestOnchainSafeClient(t *testing.T) {
+	client := &OnchainSafeClient{RPC: onchainGrantsNode(t, 156), ChainID: OPMainnetChainID}
+	tx, err := GenerateTransactionWithClient(context.Background(), client, OPMainnetChainID, fixtureGrantsSafe, 155, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if hash, err := CalculateApproveHash(*tx); err != nil || hash != fixtureGrantsHash {
+		t.Errorf("safe tx hash = %s, %v", hash, err)
+	}
+
+	// Nonce 155 is not executed yet, so the chain knows nothing of it
+	client = &OnchainSafeClient{RPC: onchainGrantsNode(t, 155), ChainID: OPMainnetChainID}
+	if _, err := client.GetMultisigTransactions(context.Background(), fixtureGrantsSafe, 155); err == nil || !strings.Contains(err.Error(), "has not been executed") {
+		t.Errorf("expected a pending transaction not to be reconstructed, got %v", err)
+	}
+
+	// The event lies outside a lookback of 10,000 blocks
+	client = &OnchainSafeClient{RPC: onchainGrantsNode(t, 156), ChainID: OPMainnetChainID, Lookback: 10_000}
+	if _, err := client.GetMultisigTransactions(context.Background(), fixtureGrantsSafe, 155); err == nil || !strings.Contains(err.Error(), "no SafeMultiSigTransaction event") {
+		t.Errorf("expected no event within the lookback, got %v", err)
+	}
+
+	client = &OnchainSafeClient{RPC: onchainGrantsNode(t, 156), ChainID: MainnetChainID}
+	if _, err := client.GetSafeInfo(context.Background(), fixtureGrantsSafe); err == nil || !strings.Contains(err.Error(), "not chain 1") {
+		t.Errorf("expected a chain mismatch, got %v", err)
+	}
+}
@@ -0,0 +1,180 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// Execution records where the Safe service says a transaction was executed
+type Execution struct {
+	TransactionHash string `json:"transactionHash"`
+	BlockNumber     uint64 `json:"blockNumber"`
+	ExecutionDate   string `json:"executionDate,omitempty"`
+
+	// Finality is the state of the execution on a node, when one was asked
+	Finality *Finality `json:"finality,omitempty"`
+
+	// Effects compares the execution's events with the calldata, when a node was asked
+	Effects *ExecutionEffects `json:"effects,omitempty"`
+}
+
+// Finality is the state of an execution transaction's block on a node
+type Finality struct {
+	Found         bool   `json:"found"`
+	BlockNumber   uint64 `json:"blockNumber,omitempty"`
+	BlockHash     string `json:"blockHash,omitempty"`
+	Reverted      bool   `json:"reverted,omitempty"`
+	Canonical     bool   `json:"canonical"`
+	LatestBlock   uint64 `json:"latestBlock"`
+	Confirmations uint64 `json:"confirmations"`
+
+	// FinalizedBlock is zero when the node does not report finalized blocks
+	FinalizedBlock uint64 `json:"finalizedBlock"`
+	Finalized      bool   `json:"finalized"`
+}
+
+// CheckFinality asks a node whether the already executed transactions of a result are in
+// finalized blocks. The outcome is recorded on each execution and problems are added to the
+// result's warnings, so attestations are not made against state a re-org could still undo.
+// Without a client, executed transactions only get a note that their finality was not checked.
+func CheckFinality(ctx context.Context, client *RPCClient, result *VerificationResult) error {
+	executions := executedTransactions(result)
+	if len(executions) == 0 {
+		return nil
+	}
+
+	if client == nil {
+		for _, e := range executions {
+			result.Warnings = append(result.Warnings, newWarning(warnFinalityUnchecked,
+				"%s %s was already executed in block %d; its finality was not checked because no RPC endpoint was given",
+				e.name, e.execution().TransactionHash, e.execution().BlockNumber))
+		}
+		return nil
+	}
+
+	chainID, err := client.ChainID(ctx)
+	if err != nil {
+		return fmt.Errorf("error checking finality: %w", err)
+	}
+	if chainID != uint64(result.Transaction.Chain) {
+		return fmt.Errorf("RPC endpoint is on chain %d but the transaction is on chain %d", chainID, result.Transaction.Chain)
+	}
+
+	for _, e := range executions {
+		warnings, err := checkExecutionFinality(ctx, client, e.name, e.execution())
+		if err != nil {
+			return fmt.Errorf("error checking finality of %s: %w", e.execution().TransactionHash, err)
+		}
+		result.Warnings = append(result.Warnings, warnings...)
+	}
+	return nil
+}
+
+// Executed reports whether a result, or the child of a nested approval, was already executed
+func Executed(result *VerificationResult) bool {
+	return len(executedTransactions(result)) > 0
+}
+
+// executedTransaction is a verified transaction that was already executed
+type executedTransaction struct {
+	// name describes the execution transaction in warnings
+	name   string
+	result *VerificationResult
+}
+
+func (e executedTransaction) execution() *Execution {
+	return e.result.Transaction.Execution
+}
+
+// executedTransactions returns the transactions of a result that were already executed, the child
+// of a nested approval first
+func executedTransactions(result *VerificationResult) []executedTransaction {
+	var executed []executedTransaction
+	if result.NestedResult != nil && result.NestedResult.Transaction.Execution != nil {
+		executed = append(executed, executedTransaction{"Child execution transaction", result.NestedResult})
+	}
+	if result.Transaction.Execution != nil {
+		name := "Execution transaction"
+		if result.NestedResult != nil {
+			name = "Parent execution transaction"
+		}
+		executed = append(executed, executedTransaction{name, result})
+	}
+	return executed
+}
+
+// checkExecutionFinality looks up an execution transaction's receipt and compares its block with
+// the canonical chain and the node's finalized block
+func checkExecutionFinality(ctx context.Context, client *RPCClient, name string, execution *Execution) ([]Warning, error) {
+	finality := &Finality{}
+	execution.Finality = finality
+
+	receipt, err := client.TransactionReceipt(ctx, execution.TransactionHash)
+	if err != nil {
+		return nil, err
+	}
+	if receipt == nil {
+		return []Warning{newWarning(warnExecutionNotFound,
+			"%s %s was not found by the node; it may have been re-orged out, or the node is not synced",
+			name, execution.TransactionHash)}, nil
+	}
+	finality.Found = true
+	finality.BlockNumber = uint64(receipt.BlockNumber)
+	finality.BlockHash = receipt.BlockHash.Hex()
+	finality.Reverted = receipt.Status == 0
+
+	var warnings []Warning
+	if finality.Reverted {
+		warnings = append(warnings, newWarning(warnExecutionReverted, "%s %s reverted", name, execution.TransactionHash))
+	}
+	if execution.BlockNumber != 0 && execution.BlockNumber != finality.BlockNumber {
+		warnings = append(warnings, newWarning(warnExecutionReorged,
+			"%s %s is in block %d but the Safe service recorded block %d; the chain re-orged after the service indexed it",
+			name, execution.TransactionHash, finality.BlockNumber, execution.BlockNumber))
+	}
+
+	block, err := client.BlockByNumber(ctx, hexutil.EncodeUint64(finality.BlockNumber))
+	if err != nil {
+		return nil, err
+	}
+	finality.Canonical = block.Hash == receipt.BlockHash
+	if !finality.Canonical {
+		return append(warnings, newWarning(warnNonCanonicalBlock,
+			"%s %s was included in block %s, which is no longer on the canonical chain",
+			name, execution.TransactionHash, finality.BlockHash)), nil
+	}
+
+	latest, err := client.BlockByNumber(ctx, "latest")
+	if err != nil {
+		return nil, err
+	}
+	finality.LatestBlock = uint64(latest.Number)
+	if finality.LatestBlock >= finality.BlockNumber {
+		finality.Confirmations = finality.LatestBlock - finality.BlockNumber + 1
+	}
+
+	finalized, err := client.BlockByNumber(ctx, "finalized")
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if !errors.Is(err, ErrBlockNotFound) {
+			return append(warnings, newWarning(warnFinalityUnknown,
+				"%s %s has %d confirmations but the node does not report finalized blocks, so its finality is unknown",
+				name, execution.TransactionHash, finality.Confirmations)), nil
+		}
+		// No block is finalized yet, as on a fresh devnet
+		finalized = &RPCBlock{}
+	}
+	finality.FinalizedBlock = uint64(finalized.Number)
+	finality.Finalized = finality.FinalizedBlock >= finality.BlockNumber
+	if !finality.Finalized {
+		warnings = append(warnings, newWarning(warnNotFinalized,
+			"%s %s is in block %d with %d confirmations but is not finalized yet (finalized block is %d); a re-org could still undo its effects",
+			name, execution.TransactionHash, finality.BlockNumber, finality.Confirmations, finality.FinalizedBlock))
+	}
+	return warnings, nil
+}
@@ -0,0 +1,131 @@
+package core
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+const finalityTxHash = "0x00000000000000000000000000000000000000000000000000000000000000aa"
+
+var finalityBlockHash = common.HexToHash("0xb10c")
+
+// executedResult is a verification result for a transaction the Safe service says was executed
+func executedResult(block uint64) *VerificationResult {
+	return &VerificationResult{Transaction: SafeTransaction{
+		Chain:     OPMainnetChainID,
+		Execution: &Execution{TransactionHash: finalityTxHash, BlockNumber: block},
+	}}
+}
+
+// executedNode is a node that mined the execution transaction in block 100
+func executedNode(latest, finalized uint64) *fakeNode {
+	return &fakeNode{
+		chainID:   OPMainnetChainID,
+		latest:    latest,
+		finalized: finalized,
+		blocks:    map[uint64]common.Hash{100: finalityBlockHash},
+		receipts: map[string]*RPCReceipt{finalityTxHash: {
+			BlockHash:   finalityBlockHash,
+			BlockNumber: hexutil.Uint64(100),
+			Status:      1,
+		}},
+	}
+}
+
+func TestCheckFinality(t *testing.T) {
+	tests := []struct {
+		name      string
+		node      *fakeNode
+		block     uint64
+		finalized bool
+		warning   string
+		critical  bool
+	}{
+		{name: "finalized", node: executedNode(200, 150), block: 100, finalized: true},
+		{name: "not finalized", node: executedNode(110, 90), block: 100, warning: "not finalized yet"},
+		{name: "service block differs", node: executedNode(200, 150), block: 99, finalized: true, warning: "the chain re-orged"},
+		{name: "re-orged out", node: func() *fakeNode {
+			node := executedNode(200, 150)
+			node.receipts = nil
+			return node
+		}(), block: 100, warning: "was not found by the node", critical: true},
+		{name: "block no longer canonical", node: func() *fakeNode {
+			node := executedNode(200, 150)
+			node.blocks[100] = common.HexToHash("0xf0")
+			return node
+		}(), block: 100, warning: "no longer on the canonical chain", critical: true},
+		{name: "no finalized tag", node: func() *fakeNode {
+			node := executedNode(200, 0)
+			node.noFinalized = true
+			return node
+		}(), block: 100, warning: "does not report finalized blocks"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := executedResult(tt.block)
+			if err := CheckFinality(context.Background(), newFakeNode(t, tt.node), result); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			finality := result.Transaction.Execution.Finality
+			if finality == nil || finality.Finalized != tt.finalized {
+				t.Fatalf("finality = %+v, want finalized = %v", finality, tt.finalized)
+			}
+			if tt.warning == "" && len(result.Warnings) != 0 {
+				t.Errorf("unexpected warnings: %+v", result.Warnings)
+			}
+			if tt.warning != "" && !hasWarningContaining(result.Warnings, tt.warning) {
+				t.Errorf("expected a warning containing %q, got %+v", tt.warning, result.Warnings)
+			}
+			if HasCritical(result.Warnings) != tt.critical {
+				t.Errorf("critical = %v, want %v: %+v", HasCritical(result.Warnings), tt.critical, result.Warnings)
+			}
+		})
+	}
+}
+
+func TestCheckFinalityConfirmations(t *testing.T) {
+	result := executedResult(100)
+	if err := CheckFinality(context.Background(), newFakeNode(t, executedNode(109, 50)), result); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if finality := result.Transaction.Execution.Finality; finality.Confirmations != 10 || finality.FinalizedBlock != 50 || !finality.Canonical {
+		t.Errorf("unexpected finality: %+v", finality)
+	}
+}
+
+func TestCheckFinalityWithoutClient(t *testing.T) {
+	result := executedResult(100)
+	if err := CheckFinality(context.Background(), nil, result); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !hasWarningContaining(result.Warnings, "finality was not checked") || HasCritical(result.Warnings) {
+		t.Errorf("expected an informational note, got %+v", result.Warnings)
+	}
+
+	// Transactions that were not executed need no check
+	pending := &VerificationResult{Transaction: SafeTransaction{Chain: OPMainnetChainID}}
+	if err := CheckFinality(context.Background(), nil, pending); err != nil || len(pending.Warnings) != 0 {
+		t.Errorf("unexpected warnings for a pending transaction: %+v, %v", pending.Warnings, err)
+	}
+}
+
+func TestCheckFinalityRejectsWrongChain(t *testing.T) {
+	node := executedNode(200, 150)
+	node.chainID = MainnetChainID
+	if err := CheckFinality(context.Background(), newFakeNode(t, node), executedResult(100)); err == nil {
+		t.Error("expected an error for an RPC endpoint on another chain")
+	}
+}
+
+func TestExecutionFromService(t *testing.T) {
+	if execution(APITransaction{IsExecuted: false}) != nil {
+		t.Error("expected no execution for a pending transaction")
+	}
+	got := execution(APITransaction{IsExecuted: true, TransactionHash: finalityTxHash, BlockNumber: 100, ExecutionDate: "2024-01-01T00:00:00Z"})
+	if got == nil || got.TransactionHash != finalityTxHash || got.BlockNumber != 100 {
+		t.Errorf("unexpected execution: %+v", got)
+	}
+}
@@ -0,0 +1,222 @@
+//go:build fork
+
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// forkFixture is an executed Safe transaction, verified end to end against its chain
+type forkFixture struct {
+	Source      string `json:"source"`
+	Description string `json:"description"`
+	Chain       uint64 `json:"chain"`
+	Safe        string `json:"safe"`
+	Nonce       uint64 `json:"nonce"`
+	SafeTxHash  string `json:"safeTxHash"`
+
+	// Functions are the decoded functions of the call and its subcalls, depth first
+	Functions []string `json:"functions"`
+}
+
+// forkSafeABI is the part of the Safe ABI the fork tests call
+var forkSafeABI = func() abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(`[
+		{"inputs":[],"name":"getOwners","outputs":[{"name":"","type":"address[]"}],"stateMutability":"view","type":"function"},
+		{"inputs":[{"name":"to","type":"address"},{"name":"value","type":"uint256"},{"name":"data","type":"bytes"},{"name":"operation","type":"uint8"},{"name":"safeTxGas","type":"uint256"},{"name":"baseGas","type":"uint256"},{"name":"gasPrice","type":"uint256"},{"name":"gasToken","type":"address"},{"name":"refundReceiver","type":"address"},{"name":"_nonce","type":"uint256"}],"name":"getTransactionHash","outputs":[{"name":"","type":"bytes32"}],"stateMutability":"view","type":"function"},
+		{"inputs":[{"name":"to","type":"address"},{"name":"value","type":"uint256"},{"name":"data","type":"bytes"},{"name":"operation","type":"uint8"},{"name":"safeTxGas","type":"uint256"},{"name":"baseGas","type":"uint256"},{"name":"gasPrice","type":"uint256"},{"name":"gasToken","type":"address"},{"name":"refundReceiver","type":"address"},{"name":"signatures","type":"bytes"}],"name":"execTransaction","outputs":[{"name":"success","type":"bool"}],"stateMutability":"payable","type":"function"}
+	]`))
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}()
+
+// safeThresholdSlot is the storage slot of a Safe's threshold (singleton, modules, owners, and
+// ownerCount come before it)
+const safeThresholdSlot = "0x0000000000000000000000000000000000000000000000000000000000000004"
+
+// TestForkedTransactions fetches every recorded transaction from the Safe service and checks it
+// against its chain: the locally computed hash against the service and the Safe contract, the
+// decoded functions against the fixture, a simulated execution in the block before the real one,
+// and the real execution's receipt against the calldata. Each chain needs a node with archive
+// state, such as an anvil fork, in OP_TXVERIFY_FORK_RPC_<chain ID>; fixtures for chains without
+// one are skipped. Run with `just fork` or `go test -tags fork ./core -run TestForkedTransactions`.
+func TestForkedTransactions(t *testing.T) {
+	paths, err := filepath.Glob(filepath.Join("testdata", "fork", "*.json"))
+	if err != nil {
+		t.Fatalf("failed to list fixtures: %v", err)
+	}
+	if len(paths) == 0 {
+		t.Fatalf("no fork fixtures found")
+	}
+
+	for _, path := range paths {
+		path := path
+		t.Run(filepath.Base(path), func(t *testing.T) {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("failed to read fixture: %v", err)
+			}
+			var fixture forkFixture
+			if err := json.Unmarshal(data, &fixture); err != nil {
+				t.Fatalf("failed to parse fixture: %v", err)
+			}
+
+			variable := fmt.Sprintf("OP_TXVERIFY_FORK_RPC_%d", fixture.Chain)
+			url := os.Getenv(variable)
+			if url == "" {
+				t.Skipf("%s is not set", variable)
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+			defer cancel()
+
+			client := NewRPCClient(url)
+			chainID, err := client.ChainID(ctx)
+			if err != nil {
+				t.Fatalf("failed to reach the node: %v", err)
+			}
+			if chainID != fixture.Chain {
+				t.Fatalf("%s is on chain %d, not %d", variable, chainID, fixture.Chain)
+			}
+
+			// Fetch
+			tx, err := GenerateTransactionWithClient(ctx, NewHTTPSafeClient(SafeServiceURLs[fixture.Chain]), fixture.Chain, fixture.Safe, fixture.Nonce, fixture.SafeTxHash)
+			if err != nil {
+				t.Fatalf("failed to fetch the transaction: %v", err)
+			}
+			if tx.Execution == nil {
+				t.Fatalf("the Safe service does not list the transaction as executed")
+			}
+
+			// Hash
+			hash, err := CalculateApproveHash(*tx)
+			if err != nil {
+				t.Fatalf("failed to hash: %v", err)
+			}
+			if !strings.EqualFold(hash, fixture.SafeTxHash) {
+				t.Errorf("safeTxHash = %s, want %s", hash, fixture.SafeTxHash)
+			}
+
+			// Decode
+			result, err := VerifyTransaction(*tx, VerifyOptions{})
+			if err != nil {
+				t.Fatalf("failed to verify: %v", err)
+			}
+			var functions []string
+			var walk func(call CallData)
+			walk = func(call CallData) {
+				functions = append(functions, call.FunctionName)
+				for _, subcall := range call.SubCalls {
+					walk(subcall)
+				}
+			}
+			walk(result.Call)
+			if strings.Join(functions, ",") != strings.Join(fixture.Functions, ",") {
+				t.Errorf("decoded functions = %v, want %v", functions, fixture.Functions)
+			}
+
+			// Simulate, in the state the real execution started from
+			block := hexutil.EncodeUint64(tx.Execution.BlockNumber - 1)
+			if onchain := forkTransactionHash(ctx, t, client, *tx, block); !strings.EqualFold(onchain, hash) {
+				t.Errorf("the Safe contract hashes the transaction to %s, not %s", onchain, hash)
+			}
+			forkSimulateExecution(ctx, t, client, *tx, block)
+
+			// Execution
+			if err := CheckExecutionEffects(ctx, client, result); err != nil {
+				t.Fatalf("failed to check the execution: %v", err)
+			}
+			if effects := result.Transaction.Execution.Effects; effects == nil || !effects.Succeeded {
+				t.Errorf("the receipt of %s has no ExecutionSuccess for %s", tx.Execution.TransactionHash, hash)
+			}
+			for _, warning := range result.Warnings {
+				if warning.Severity == SeverityCritical {
+					t.Errorf("critical warning: %s", warning.Message)
+				}
+			}
+		})
+	}
+}
+
+// forkSafeArguments returns the hashed fields of a transaction in the order the Safe takes them
+func forkSafeArguments(tx SafeTransaction) []interface{} {
+	value := tx.Value
+	if value == nil {
+		value = new(big.Int)
+	}
+	return []interface{}{
+		common.HexToAddress(tx.To), value, common.FromHex(tx.Data), uint8(tx.Operation),
+		big.NewInt(int64(tx.SafeTxGas)), big.NewInt(int64(tx.BaseGas)), big.NewInt(int64(tx.GasPrice)),
+		common.HexToAddress(tx.GasToken), common.HexToAddress(tx.RefundReceiver),
+	}
+}
+
+// forkCall calls a Safe function at a block, with optional state overrides, and unpacks its result
+func forkCall(ctx context.Context, t *testing.T, client *RPCClient, from string, tx SafeTransaction, block string, overrides map[string]interface{}, method string, args ...interface{}) []interface{} {
+	t.Helper()
+	data, err := forkSafeABI.Pack(method, args...)
+	if err != nil {
+		t.Fatalf("failed to pack %s: %v", method, err)
+	}
+	call := map[string]interface{}{"to": StripChainPrefix(tx.Safe), "data": hexutil.Bytes(data)}
+	if from != "" {
+		call["from"] = from
+	}
+	params := []interface{}{call, block}
+	if overrides != nil {
+		params = append(params, overrides)
+	}
+	var output hexutil.Bytes
+	if err := client.call(ctx, &output, "eth_call", params...); err != nil {
+		t.Fatalf("%s failed: %v", method, err)
+	}
+	values, err := forkSafeABI.Unpack(method, output)
+	if err != nil {
+		t.Fatalf("failed to unpack %s: %v", method, err)
+	}
+	return values
+}
+
+// forkTransactionHash asks the Safe contract for the safeTxHash of a transaction
+func forkTransactionHash(ctx context.Context, t *testing.T, client *RPCClient, tx SafeTransaction, block string) string {
+	t.Helper()
+	args := append(forkSafeArguments(tx), big.NewInt(int64(tx.Nonce)))
+	hash := forkCall(ctx, t, client, "", tx, block, nil, "getTransactionHash", args...)[0].([32]byte)
+	return common.BytesToHash(hash[:]).Hex()
+}
+
+// forkSimulateExecution executes a transaction in a call, as its first owner with the Safe's
+// threshold overridden to one. The owner's signature is pre-validated (v = 1), which the Safe
+// accepts from the owner itself.
+func forkSimulateExecution(ctx context.Context, t *testing.T, client *RPCClient, tx SafeTransaction, block string) {
+	t.Helper()
+	owners := forkCall(ctx, t, client, "", tx, block, nil, "getOwners")[0].([]common.Address)
+	if len(owners) == 0 {
+		t.Fatalf("the Safe has no owners")
+	}
+	owner := owners[0]
+	signature := append(common.LeftPadBytes(owner.Bytes(), 32), append(make([]byte, 32), 1)...)
+
+	overrides := map[string]interface{}{
+		StripChainPrefix(tx.Safe): map[string]interface{}{
+			"stateDiff": map[string]string{safeThresholdSlot: common.BigToHash(big.NewInt(1)).Hex()},
+		},
+	}
+	args := append(forkSafeArguments(tx), signature)
+	if success := forkCall(ctx, t, client, owner.Hex(), tx, block, overrides, "execTransaction", args...)[0].(bool); !success {
+		t.Errorf("the simulated execution by %s failed", owner.Hex())
+	}
+}
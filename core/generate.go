@@ -1,252 +1,457 @@
 package core
 
 import (
-	"encoding/json"
+	"context"
+	"errors"
 	"fmt"
-	"io"
 	"math/big"
-	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/ethereum/go-ethereum/common"
 )
 
-// APIResponse represents the response from the Safe API
-type APIResponse struct {
-	Count   int `json:"count"`
-	Results []struct {
-		To             string      `json:"to"`
-		Value          string      `json:"value"`
-		Data           string      `json:"data"`
-		Operation      int         `json:"operation"`
-		SafeTxGas      int         `json:"safeTxGas"`
-		BaseGas        int         `json:"baseGas"`
-		GasPrice       string      `json:"gasPrice"`
-		GasToken       string      `json:"gasToken"`
-		RefundReceiver string      `json:"refundReceiver"`
-		DataDecoded    interface{} `json:"dataDecoded"`
-	} `json:"results"`
+// GenerateTransaction fetches transaction data from the Safe API and returns a SafeTransaction.
+// When several transactions are queued for the nonce, safeTxHash picks one of them; without it,
+// a *NonceConflictError lists them.
+func GenerateTransaction(ctx context.Context, network string, safeAddress string, nonce uint64, safeTxHash string) (*SafeTransaction, error) {
+	// Get network info
+	apiURL, chainID, err := getNetworkInfo(network)
+	if err != nil {
+		return nil, err
+	}
+
+	// Make sure a prefixed safe address (e.g. "oeth:0x...") agrees with the requested network
+	sources := []ChainSource{{Source: fmt.Sprintf("network %q", network), ChainID: chainID}}
+	source, err := PrefixSource("safe", safeAddress)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := ResolveChainID(append(sources, source)...); err != nil {
+		return nil, err
+	}
+
+	return GenerateTransactionWithClient(ctx, serviceClient(chainID, apiURL), chainID, StripChainPrefix(safeAddress), nonce, safeTxHash)
 }
 
-// SafeInfoResponse represents the response from the Safe info API
-type SafeInfoResponse struct {
-	Version string `json:"version"`
+// NonceConflictError is returned when several transactions are queued for the same nonce, as
+// when a replacement or cancellation has been proposed, and none was picked by its safeTxHash.
+// Only one of them can ever execute, so verifying one and signing another must be ruled out.
+type NonceConflictError struct {
+	Safe       string
+	Nonce      uint64
+	Candidates []PendingTransaction
 }
 
-// fetchSafeVersion fetches the Safe version for a given safe address
-func fetchSafeVersion(apiURL, safeAddress string) (string, error) {
-	endpoint := fmt.Sprintf("%s/api/v1/safes/%s/", apiURL, safeAddress)
+func (e *NonceConflictError) Error() string {
+	candidates := make([]string, 0, len(e.Candidates))
+	for _, candidate := range e.Candidates {
+		candidates = append(candidates, "\n  "+DescribeCandidate(candidate))
+	}
+	return fmt.Sprintf("%d transactions are queued for nonce %d of %s; pick the one to verify by its safeTxHash:%s",
+		len(e.Candidates), e.Nonce, e.Safe, strings.Join(candidates, ""))
+}
 
-	resp, err := http.Get(endpoint)
-	if err != nil {
-		return "", fmt.Errorf("error fetching safe version: %w", err)
+// DescribeCandidate describes one of several transactions queued for a nonce by what tells
+// them apart: its safeTxHash, who proposed it and when, and how many owners signed it
+func DescribeCandidate(tx PendingTransaction) string {
+	description := tx.SafeTxHash
+	if tx.Proposer != "" {
+		description += " proposed by " + ChecksumAddress(tx.Proposer)
+	}
+	if tx.Submitted != "" {
+		description += " at " + tx.Submitted
 	}
-	defer resp.Body.Close()
+	return fmt.Sprintf("%s, %d/%d confirmations", description, tx.Confirmations, tx.Required)
+}
+
+// GenerateTransactionWithClient fetches the transaction for a Safe and nonce using the given
+// client. When several transactions are queued for the nonce, safeTxHash picks one of them.
+func GenerateTransactionWithClient(ctx context.Context, client SafeClient, chainID uint64, safeAddress string, nonce uint64, safeTxHash string) (*SafeTransaction, error) {
+	// Normalize safe address
+	safeAddress = common.HexToAddress(safeAddress).Hex()
 
-	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("failed to fetch safe version: %s", resp.Status)
+	// Fetch Safe version
+	safeInfo, err := client.GetSafeInfo(ctx, safeAddress)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching safe version: %w", err)
 	}
 
-	body, err := io.ReadAll(resp.Body)
+	// Fetch the transactions at this nonce
+	apiResp, err := client.GetMultisigTransactions(ctx, safeAddress, nonce)
 	if err != nil {
-		return "", fmt.Errorf("error reading safe info response body: %w", err)
+		return nil, fmt.Errorf("error fetching transaction data: %w", err)
 	}
 
-	var safeInfo SafeInfoResponse
-	if err := json.Unmarshal(body, &safeInfo); err != nil {
-		return "", fmt.Errorf("error parsing safe info response: %w", err)
+	// Check if transaction exists
+	if apiResp.Count == 0 || len(apiResp.Results) == 0 {
+		return nil, withKind(ErrTxNotFound, fmt.Errorf("no transaction found for safe %s with nonce %d", safeAddress, nonce))
+	}
+	// A candidate left out of the list could be the one that executes instead
+	if apiResp.Count > len(apiResp.Results) {
+		return nil, fmt.Errorf("the Safe service counts %d transactions for nonce %d of %s but returned %d; try again", apiResp.Count, nonce, safeAddress, len(apiResp.Results))
 	}
 
-	return safeInfo.Version, nil
-}
+	tx, replacements, err := pickNonceCandidate(apiResp.Results, safeAddress, nonce, safeTxHash)
+	if err != nil {
+		return nil, err
+	}
+	tx.Safe = safeAddress
+	tx.Nonce = APIValue{Raw: strconv.FormatUint(nonce, 10), Present: true}
 
-// GenerateTransaction fetches transaction data from the Safe API and returns a SafeTransaction
-func GenerateTransaction(network string, safeAddress string, nonce uint64) (*SafeTransaction, error) {
-	// Get network info
-	apiURL, chainID, err := getNetworkInfo(network)
+	generated, err := buildTransaction(ctx, client, chainID, tx, safeInfo)
 	if err != nil {
 		return nil, err
 	}
+	generated.Replacements = replacements
+	generated.FallbackSources = fallbackSources(client)
+	return generated, nil
+}
 
-	// Normalize safe address
-	safeAddress = common.HexToAddress(safeAddress).Hex()
+// pickNonceCandidate picks the transaction to verify among those the service has for a nonce,
+// returning the others. Once one has executed the others can never execute, so it is picked
+// unless another is asked for.
+func pickNonceCandidate(results []APITransaction, safeAddress string, nonce uint64, safeTxHash string) (APITransaction, []PendingTransaction, error) {
+	if safeTxHash != "" {
+		for i, tx := range results {
+			if strings.EqualFold(tx.SafeTxHash, safeTxHash) {
+				return tx, otherCandidates(results, i, nonce), nil
+			}
+		}
+		return APITransaction{}, nil, withKind(ErrTxNotFound, fmt.Errorf("no transaction with safeTxHash %s is queued for nonce %d of %s", safeTxHash, nonce, safeAddress))
+	}
+	if len(results) == 1 {
+		return results[0], nil, nil
+	}
+	for _, tx := range results {
+		if tx.IsExecuted {
+			return tx, nil, nil
+		}
+	}
 
-	// Fetch Safe version
-	safeVersion, err := fetchSafeVersion(apiURL, safeAddress)
+	candidates := make([]PendingTransaction, 0, len(results))
+	for _, tx := range results {
+		candidates = append(candidates, summarizePendingTransaction(tx, nonce))
+	}
+	return APITransaction{}, nil, &NonceConflictError{Safe: safeAddress, Nonce: nonce, Candidates: candidates}
+}
+
+// otherCandidates summarizes the unexecuted transactions for a nonce other than the picked one
+func otherCandidates(results []APITransaction, picked int, nonce uint64) []PendingTransaction {
+	var others []PendingTransaction
+	for i, tx := range results {
+		if i != picked && !tx.IsExecuted {
+			others = append(others, summarizePendingTransaction(tx, nonce))
+		}
+	}
+	return others
+}
+
+// FetchTransactionByHash fetches a transaction by its safeTxHash from the Safe API
+func FetchTransactionByHash(ctx context.Context, network string, safeTxHash string) (*SafeTransaction, error) {
+	apiURL, chainID, err := getNetworkInfo(network)
 	if err != nil {
-		return nil, fmt.Errorf("error fetching safe version: %w", err)
+		return nil, err
 	}
 
-	// Construct API endpoint
-	endpoint := fmt.Sprintf("%s/api/v1/safes/%s/multisig-transactions/?nonce=%d", apiURL, safeAddress, nonce)
+	return FetchTransactionByHashWithClient(ctx, serviceClient(chainID, apiURL), chainID, safeTxHash)
+}
 
-	// Make HTTP request
-	resp, err := http.Get(endpoint)
+// FetchTransactionByHashWithClient fetches a transaction by its safeTxHash using the given client
+func FetchTransactionByHashWithClient(ctx context.Context, client SafeClient, chainID uint64, safeTxHash string) (*SafeTransaction, error) {
+	tx, err := client.GetMultisigTransaction(ctx, safeTxHash)
 	if err != nil {
-		return nil, fmt.Errorf("error fetching transaction data: %w", err)
+		return nil, fmt.Errorf("error fetching transaction %s: %w", safeTxHash, err)
 	}
-	defer resp.Body.Close()
 
-	// Check response status
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API request failed with status: %s", resp.Status)
+	safeInfo, err := client.GetSafeInfo(ctx, tx.Safe)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching safe version: %w", err)
 	}
 
-	// Read response body
-	body, err := io.ReadAll(resp.Body)
+	generated, err := buildTransaction(ctx, client, chainID, *tx, safeInfo)
 	if err != nil {
-		return nil, fmt.Errorf("error reading response body: %w", err)
+		return nil, err
 	}
+	generated.FallbackSources = fallbackSources(client)
+	return generated, nil
+}
 
-	// Parse response
-	var apiResp APIResponse
-	if err := json.Unmarshal(body, &apiResp); err != nil {
-		return nil, fmt.Errorf("error parsing API response: %w", err)
+// Field provenance values recorded in SafeTransaction.Provenance
+const (
+	FieldSourceService = "service"
+	FieldSourceMissing = "default (missing from service response)"
+	FieldSourceNull    = "default (null in service response)"
+)
+
+// ZeroAddress is the default gas token and refund receiver
+const ZeroAddress = "0x0000000000000000000000000000000000000000"
+
+// resolveField returns the raw value of a service field, applying an explicit default for
+// optional fields that are null or missing and recording where the value came from.
+// Required fields that are null or missing are an error rather than a silent zero.
+func resolveField(name string, v APIValue, def string, required bool, provenance map[string]string) (string, error) {
+	switch {
+	case v.Present && !v.Null:
+		provenance[name] = FieldSourceService
+		return v.Raw, nil
+	case required:
+		return "", fmt.Errorf("service response is missing required field %q", name)
+	case v.Null:
+		provenance[name] = FieldSourceNull
+	default:
+		provenance[name] = FieldSourceMissing
 	}
+	return def, nil
+}
 
-	// Check if transaction exists
-	if apiResp.Count == 0 {
-		return nil, fmt.Errorf("no transaction found for safe %s with nonce %d", safeAddress, nonce)
+// parseIntField parses a decimal integer field, reporting the field name on failure
+func parseIntField(name, raw string) (int, error) {
+	n, err := strconv.Atoi(raw)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s %q in service response: %w", name, raw, err)
 	}
+	return n, nil
+}
 
-	// Use the first transaction in the results
-	tx := apiResp.Results[0]
+// buildTransaction converts a service transaction into a SafeTransaction. If the transaction is an
+// approveHash call, the approved child transaction becomes the main content and the approving
+// transaction is recorded as the nested parent.
+func buildTransaction(ctx context.Context, client SafeClient, chainID uint64, tx APITransaction, safeInfo *SafeInfoResponse) (*SafeTransaction, error) {
+	safeAddress := tx.Safe
+	safeVersion, owners := safeInfo.Version, safeInfo.Owners
+	nonce, err := parseIntField("nonce", tx.Nonce.Raw)
+	if err != nil {
+		return nil, err
+	}
 
 	var nested *Nested
 	content := tx
 
 	// Check if this is an approveHash transaction
-	if tx.Data != "" && strings.HasPrefix(tx.Data, "0xd4d9bdcd") {
+	if strings.HasPrefix(tx.Data.Raw, "0xd4d9bdcd") && len(tx.Data.Raw) >= 74 {
 		// Extract the hash from the data (skip first 10 chars for function signature, take next 64)
-		if len(tx.Data) >= 74 {
-			innerHash := "0x" + tx.Data[10:74]
+		innerHash := "0x" + tx.Data.Raw[10:74]
+
+		// Only treat the transaction as nested if the service knows the approved transaction. Only
+		// a confirmed not found means it does not: a failed lookup would otherwise hide the
+		// approved transaction and verify the approval as a plain call.
+		innerTx, err := client.GetMultisigTransaction(ctx, innerHash)
+		if err != nil && !errors.Is(err, ErrTxNotFound) {
+			return nil, fmt.Errorf("error fetching approved transaction %s: %w", innerHash, err)
+		}
+		if err == nil {
+			outerOperation, err := parseIntField("operation", tx.Operation.Raw)
+			if err != nil {
+				return nil, err
+			}
 
-			// Fetch the inner transaction using v2 API
-			innerEndpoint := fmt.Sprintf("%s/api/v2/multisig-transactions/%s/", apiURL, innerHash)
-			innerResp, err := http.Get(innerEndpoint)
+			// Create nested data from outer transaction (using OUTER safe's info)
+			nested = &Nested{
+				Safe:        safeAddress,
+				SafeVersion: safeVersion,
+				Nonce:       nonce,
+				Data:        tx.Data.Raw,
+				Operation:   outerOperation,
+				To:          tx.To.Raw,
+
+				ServiceSafeTxHash: tx.SafeTxHash,
+			}
+			nested.SignerProgress, err = signerProgress(tx)
 			if err != nil {
-				return nil, fmt.Errorf("error fetching inner transaction data: %w", err)
+				return nil, err
 			}
-			defer innerResp.Body.Close()
-
-			if innerResp.StatusCode == http.StatusOK {
-				innerBody, err := io.ReadAll(innerResp.Body)
-				if err != nil {
-					return nil, fmt.Errorf("error reading inner transaction response body: %w", err)
-				}
-
-				// Parse inner transaction as a single transaction (not wrapped in APIResponse)
-				var innerTx struct {
-					To             string `json:"to"`
-					Value          string `json:"value"`
-					Data           string `json:"data"`
-					Operation      int    `json:"operation"`
-					SafeTxGas      string `json:"safeTxGas"` // API returns as string
-					BaseGas        string `json:"baseGas"`   // API returns as string
-					GasPrice       string `json:"gasPrice"`
-					GasToken       string `json:"gasToken"`
-					RefundReceiver string `json:"refundReceiver"`
-					Nonce          string `json:"nonce"`
-					Safe           string `json:"safe"`
-				}
-
-				if err := json.Unmarshal(innerBody, &innerTx); err != nil {
-					return nil, fmt.Errorf("error parsing inner transaction response: %w", err)
-				}
-
-				// Convert string values to integers for inner transaction
-				var innerSafeTxGas, innerBaseGas int
-				fmt.Sscanf(innerTx.SafeTxGas, "%d", &innerSafeTxGas)
-				fmt.Sscanf(innerTx.BaseGas, "%d", &innerBaseGas)
-
-				// Create nested data from outer transaction (using OUTER safe's info)
-				nested = &Nested{
-					Safe:        safeAddress,
-					SafeVersion: safeVersion,
-					Nonce:       int(nonce),
-					Data:        tx.Data,
-					Operation:   tx.Operation,
-					To:          tx.To,
-				}
-
-				// Use inner transaction data as the main content
-				content.To = innerTx.To
-				content.Value = innerTx.Value
-				content.Data = innerTx.Data
-				content.Operation = innerTx.Operation
-				content.SafeTxGas = innerSafeTxGas
-				content.BaseGas = innerBaseGas
-				content.GasPrice = innerTx.GasPrice
-				content.GasToken = innerTx.GasToken
-				content.RefundReceiver = innerTx.RefundReceiver
-
-				// For the main transaction, we need the INNER safe's info
-				safeAddress = innerTx.Safe // Update to use inner safe address
-
-				// Fetch the inner safe's version for the main transaction
-				safeVersion, err = fetchSafeVersion(apiURL, innerTx.Safe)
-				if err != nil {
-					return nil, fmt.Errorf("error fetching inner safe version: %w", err)
-				}
+			nested.Execution = execution(tx)
+			nested.Proposal = newProposal(tx, owners)
+			nested.ServiceDecoded = newServiceDecodedCall(tx.DataDecoded)
+
+			// Use inner transaction data as the main content
+			content = *innerTx
+
+			// For the main transaction, we need the INNER safe's info and nonce. The approved hash
+			// is the inner Safe's safeTxHash, which covers the inner Safe's own nonce; hashing with
+			// the approving Safe's nonce gives a hash nobody approved.
+			safeAddress = innerTx.Safe
+			nonce, err = parseIntField("nonce", innerTx.Nonce.Raw)
+			if err != nil {
+				return nil, err
 			}
+
+			// Fetch the inner safe's version for the main transaction
+			innerSafeInfo, err := client.GetSafeInfo(ctx, innerTx.Safe)
+			if err != nil {
+				return nil, fmt.Errorf("error fetching inner safe version: %w", err)
+			}
+			safeVersion, owners = innerSafeInfo.Version, innerSafeInfo.Owners
 		}
 	}
 
+	// Resolve every hashed field, recording whether it came from the service or a default
+	provenance := map[string]string{"nonce": FieldSourceService}
+	to, err := resolveField("to", content.To, "", true, provenance)
+	if err != nil {
+		return nil, err
+	}
+	value, err := resolveField("value", content.Value, "", true, provenance)
+	if err != nil {
+		return nil, err
+	}
+	operation, err := resolveField("operation", content.Operation, "", true, provenance)
+	if err != nil {
+		return nil, err
+	}
+	data, err := resolveField("data", content.Data, "0x", false, provenance)
+	if err != nil {
+		return nil, err
+	}
+	safeTxGas, err := resolveField("safeTxGas", content.SafeTxGas, "0", false, provenance)
+	if err != nil {
+		return nil, err
+	}
+	baseGas, err := resolveField("baseGas", content.BaseGas, "0", false, provenance)
+	if err != nil {
+		return nil, err
+	}
+	gasPrice, err := resolveField("gasPrice", content.GasPrice, "0", false, provenance)
+	if err != nil {
+		return nil, err
+	}
+	gasToken, err := resolveField("gasToken", content.GasToken, ZeroAddress, false, provenance)
+	if err != nil {
+		return nil, err
+	}
+	refundReceiver, err := resolveField("refundReceiver", content.RefundReceiver, ZeroAddress, false, provenance)
+	if err != nil {
+		return nil, err
+	}
+
 	// Convert string values to appropriate types
 	// Value can exceed 64-bit range; parse into big.Int
 	valueBig := new(big.Int)
-	if _, ok := valueBig.SetString(content.Value, 10); !ok {
-		return nil, fmt.Errorf("invalid value: %s", content.Value)
+	if _, ok := valueBig.SetString(value, 10); !ok {
+		return nil, fmt.Errorf("invalid value: %s", value)
 	}
 
-	// GasPrice may be large but typically fits; keep as int for now
-	var gasPrice int
-	fmt.Sscanf(content.GasPrice, "%d", &gasPrice)
+	operationInt, err := parseIntField("operation", operation)
+	if err != nil {
+		return nil, err
+	}
+	safeTxGasInt, err := parseIntField("safeTxGas", safeTxGas)
+	if err != nil {
+		return nil, err
+	}
+	baseGasInt, err := parseIntField("baseGas", baseGas)
+	if err != nil {
+		return nil, err
+	}
+	gasPriceInt, err := parseIntField("gasPrice", gasPrice)
+	if err != nil {
+		return nil, err
+	}
+
+	progress, err := signerProgress(content)
+	if err != nil {
+		return nil, err
+	}
 
 	// Create SafeTransaction
 	safeTx := &SafeTransaction{
 		Safe:           safeAddress,
 		SafeVersion:    safeVersion,
 		Chain:          int(chainID),
-		To:             content.To,
+		To:             to,
 		Value:          valueBig,
-		Data:           content.Data,
-		Operation:      content.Operation,
-		SafeTxGas:      content.SafeTxGas,
-		BaseGas:        content.BaseGas,
-		GasPrice:       gasPrice,
-		GasToken:       content.GasToken,
-		RefundReceiver: content.RefundReceiver,
-		Nonce:          int(nonce),
+		Data:           data,
+		Operation:      operationInt,
+		SafeTxGas:      safeTxGasInt,
+		BaseGas:        baseGasInt,
+		GasPrice:       gasPriceInt,
+		GasToken:       gasToken,
+		RefundReceiver: refundReceiver,
+		Nonce:          nonce,
 		Nested:         nested,
+
+		ServiceSafeTxHash: content.SafeTxHash,
+		ServiceDecoded:    newServiceDecodedCall(content.DataDecoded),
+		Provenance:        provenance,
+		SignerProgress:    progress,
+		Execution:         execution(content),
+		Proposal:          newProposal(content, owners),
 	}
 
 	return safeTx, nil
 }
 
+// signerProgress returns the owner signatures collected for a service transaction, or nil when
+// the service did not report a threshold
+func signerProgress(tx APITransaction) (*SignerProgress, error) {
+	if !tx.ConfirmationsRequired.Present || tx.ConfirmationsRequired.Null {
+		return nil, nil
+	}
+	required, err := parseIntField("confirmationsRequired", tx.ConfirmationsRequired.Raw)
+	if err != nil {
+		return nil, err
+	}
+	return &SignerProgress{Required: required, Confirmations: tx.Confirmations}, nil
+}
+
+// execution returns where a service transaction was executed, or nil when it has not been
+func execution(tx APITransaction) *Execution {
+	if !tx.IsExecuted || tx.TransactionHash == "" {
+		return nil
+	}
+	return &Execution{TransactionHash: tx.TransactionHash, BlockNumber: tx.BlockNumber, ExecutionDate: tx.ExecutionDate}
+}
+
+// SafeServiceURLs maps chain IDs to their Safe Transaction Service base URLs
+var SafeServiceURLs = map[uint64]string{
+	MainnetChainID:     "https://safe-transaction-mainnet.safe.global",
+	OPMainnetChainID:   "https://safe-transaction-optimism.safe.global",
+	BaseMainnetChainID: "https://safe-transaction-base.safe.global",
+	SepoliaChainID:     "https://safe-transaction-sepolia.safe.global",
+	OPSepoliaChainID:   "https://safe-transaction-optimism-sepolia.safe.global",
+	BaseSepoliaChainID: "https://safe-transaction-base-sepolia.safe.global",
+	ZkSyncEraChainID:   "https://safe-transaction-zksync.safe.global",
+}
+
+// Networks maps the network names accepted on the command line to chain IDs
+var Networks = map[string]uint64{
+	"ethereum":     MainnetChainID,
+	"op":           OPMainnetChainID,
+	"optimism":     OPMainnetChainID,
+	"base":         BaseMainnetChainID,
+	"sepolia":      SepoliaChainID,
+	"op-sepolia":   OPSepoliaChainID,
+	"base-sepolia": BaseSepoliaChainID,
+	"zksync":       ZkSyncEraChainID,
+	"zksync-era":   ZkSyncEraChainID,
+}
+
+// NetworkNames lists the network names for usage and error messages
+const NetworkNames = "ethereum, op, base, zksync, sepolia, op-sepolia, base-sepolia"
+
+// ValidateNetwork checks that a network name is supported
+func ValidateNetwork(network string) error {
+	if _, ok := Networks[strings.ToLower(network)]; !ok {
+		return withKind(ErrUnsupportedChain, fmt.Errorf("unsupported network: %s (must be one of %s)", network, NetworkNames))
+	}
+	return nil
+}
+
 // getNetworkInfo returns the API URL and chain ID for a network
 func getNetworkInfo(network string) (string, uint64, error) {
-	network = strings.ToLower(network)
-
-	var apiURL string
-	var chainID uint64
-
-	switch network {
-	case "ethereum":
-		apiURL = "https://safe-transaction-mainnet.safe.global"
-		chainID = MainnetChainID
-	case "op", "optimism":
-		apiURL = "https://safe-transaction-optimism.safe.global"
-		chainID = OPMainnetChainID
-	case "base":
-		apiURL = "https://safe-transaction-base.safe.global"
-		chainID = BaseMainnetChainID
-	case "sepolia":
-		apiURL = "https://safe-transaction-sepolia.safe.global"
-		chainID = SepoliaChainID
-	default:
-		return "", 0, fmt.Errorf("unsupported network: %s (must be ethereum, op, or base)", network)
+	if err := ValidateNetwork(network); err != nil {
+		return "", 0, err
 	}
+	chainID := Networks[strings.ToLower(network)]
+	return SafeServiceURLs[chainID], chainID, nil
+}
 
-	return apiURL, chainID, nil
+// getServiceURL returns the Safe Transaction Service URL for a chain ID
+func getServiceURL(chainID uint64) (string, error) {
+	apiURL, ok := SafeServiceURLs[chainID]
+	if !ok {
+		return "", withKind(ErrUnsupportedChain, fmt.Errorf("no Safe Transaction Service known for chain %d", chainID))
+	}
+	return apiURL, nil
 }
@@ -1,6 +1,9 @@
 package core
 
-import "testing"
+import (
+	"strings"
+	"testing"
+)
 
 func TestGetNetworkInfo(t *testing.T) {
 	url, chain, err := getNetworkInfo("ethereum")
@@ -26,4 +29,38 @@ func TestGetNetworkInfo(t *testing.T) {
 	if url == "" || chain != BaseMainnetChainID {
 		t.Fatalf("base: got (%q, %d), want (non-empty, %d)", url, chain, BaseMainnetChainID)
 	}
+
+	url, chain, err = getNetworkInfo("zksync")
+	if err != nil {
+		t.Fatalf("zksync: unexpected error: %v", err)
+	}
+	if url == "" || chain != ZkSyncEraChainID {
+		t.Fatalf("zksync: got (%q, %d), want (non-empty, %d)", url, chain, ZkSyncEraChainID)
+	}
+}
+
+func TestGetNetworkInfoTestnets(t *testing.T) {
+	for network, want := range map[string]uint64{
+		"sepolia":      SepoliaChainID,
+		"op-sepolia":   OPSepoliaChainID,
+		"base-sepolia": BaseSepoliaChainID,
+	} {
+		url, chain, err := getNetworkInfo(network)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", network, err)
+		}
+		if url == "" || chain != want {
+			t.Fatalf("%s: got (%q, %d), want (non-empty, %d)", network, url, chain, want)
+		}
+		if _, ok := ChainNames[chain]; !ok {
+			t.Errorf("%s: chain %d has no name", network, chain)
+		}
+		if len(MulticallAddresses[chain]) == 0 {
+			t.Errorf("%s: chain %d has no multicall contracts", network, chain)
+		}
+	}
+
+	if _, _, err := getNetworkInfo("goerli"); err == nil || !strings.Contains(err.Error(), NetworkNames) {
+		t.Fatalf("goerli: expected an unsupported network error listing the networks, got %v", err)
+	}
 }
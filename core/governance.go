@@ -0,0 +1,251 @@
+package core
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// openZeppelinGovernorABIJSON contains the proposal entry points of the OpenZeppelin Governor,
+// which the Optimism Governor also implements. The Optimism Governor propose with a proposal type
+// is already in KnownABIJSON.
+var openZeppelinGovernorABIJSON = []string{
+	`[{"inputs":[{"name":"targets","type":"address[]"},{"name":"values","type":"uint256[]"},{"name":"calldatas","type":"bytes[]"},{"name":"description","type":"string"}],"name":"propose","type":"function"}]`,
+	`[{"inputs":[{"name":"targets","type":"address[]"},{"name":"values","type":"uint256[]"},{"name":"calldatas","type":"bytes[]"},{"name":"descriptionHash","type":"bytes32"}],"name":"queue","type":"function"}]`,
+	`[{"inputs":[{"name":"targets","type":"address[]"},{"name":"values","type":"uint256[]"},{"name":"calldatas","type":"bytes[]"},{"name":"descriptionHash","type":"bytes32"}],"name":"execute","type":"function"}]`,
+	`[{"inputs":[{"name":"targets","type":"address[]"},{"name":"values","type":"uint256[]"},{"name":"calldatas","type":"bytes[]"},{"name":"descriptionHash","type":"bytes32"}],"name":"cancel","type":"function"}]`,
+}
+
+// governorVoteABIJSON contains the voting entry points of the OpenZeppelin Governor; Governor
+// Bravo has the first two
+var governorVoteABIJSON = []string{
+	`[{"inputs":[{"name":"proposalId","type":"uint256"},{"name":"support","type":"uint8"}],"name":"castVote","type":"function"}]`,
+	`[{"inputs":[{"name":"proposalId","type":"uint256"},{"name":"support","type":"uint8"},{"name":"reason","type":"string"}],"name":"castVoteWithReason","type":"function"}]`,
+	`[{"inputs":[{"name":"proposalId","type":"uint256"},{"name":"support","type":"uint8"},{"name":"reason","type":"string"},{"name":"params","type":"bytes"}],"name":"castVoteWithReasonAndParams","type":"function"}]`,
+}
+
+// governorBravoABIJSON contains the proposal entry points of Compound Governor Bravo
+var governorBravoABIJSON = []string{
+	`[{"inputs":[{"name":"proposalId","type":"uint256"}],"name":"queue","type":"function"}]`,
+	`[{"inputs":[{"name":"proposalId","type":"uint256"}],"name":"execute","type":"function"}]`,
+	`[{"inputs":[{"name":"proposalId","type":"uint256"}],"name":"cancel","type":"function"}]`,
+}
+
+// governorBravoProposeABIJSON is Governor Bravo's propose, which passes the function signatures
+// separately from the encoded arguments
+const governorBravoProposeABIJSON = `[{"inputs":[{"name":"targets","type":"address[]"},{"name":"values","type":"uint256[]"},{"name":"signatures","type":"string[]"},{"name":"calldatas","type":"bytes[]"},{"name":"description","type":"string"}],"name":"propose","type":"function"}]`
+
+// aragonVotingABIJSON contains the entry points of the aragonOS Voting app
+var aragonVotingABIJSON = []string{
+	`[{"inputs":[{"name":"voteId","type":"uint256"},{"name":"supports","type":"bool"},{"name":"executesIfDecided","type":"bool"}],"name":"vote","type":"function"}]`,
+	`[{"inputs":[{"name":"voteId","type":"uint256"}],"name":"executeVote","type":"function"}]`,
+}
+
+// aragonNewVoteABIJSON is the aragonOS Voting app's newVote, whose EVM script holds the actions
+const aragonNewVoteABIJSON = `[{"inputs":[{"name":"executionScript","type":"bytes"},{"name":"metadata","type":"string"}],"name":"newVote","type":"function"}]`
+
+// The governance functions are decoded only on the governors themselves: the same selectors on
+// another contract may do something else entirely, as cancel(uint256) does on a Sablier stream
+func init() {
+	for _, governor := range []string{OptimismGovernor, ENSGovernor} {
+		for _, abiJSON := range openZeppelinGovernorABIJSON {
+			registerContractFunction(governor, abiJSON, decodeGovernorActions)
+		}
+		for _, abiJSON := range governorVoteABIJSON {
+			registerContractFunction(governor, abiJSON, decodeGovernorVote)
+		}
+	}
+	registerContractDecoder(OptimismGovernor, "propose(address[],uint256[],bytes[],string,uint8)", decodeGovernorActions)
+
+	for _, governor := range []string{CompoundGovernorBravo, UniswapGovernorBravo} {
+		registerContractFunction(governor, governorBravoProposeABIJSON, decodeGovernorActions)
+		for _, abiJSON := range governorBravoABIJSON {
+			registerContractFunction(governor, abiJSON, nil)
+		}
+		for _, abiJSON := range governorVoteABIJSON[:2] {
+			registerContractFunction(governor, abiJSON, decodeGovernorVote)
+		}
+	}
+
+	registerContractFunction(LidoAragonVoting, aragonNewVoteABIJSON, decodeAragonNewVote)
+	for _, abiJSON := range aragonVotingABIJSON {
+		registerContractFunction(LidoAragonVoting, abiJSON, nil)
+	}
+}
+
+// governorSupport names the OpenZeppelin / Governor Bravo vote types
+var governorSupport = map[uint8]string{0: "Against", 1: "For", 2: "Abstain"}
+
+// formatVoteOption renders a numeric vote option with its name
+func formatVoteOption(option uint8, names map[uint8]string) string {
+	name, ok := names[option]
+	if !ok {
+		name = "INVALID"
+	}
+	return fmt.Sprintf("%d (%s)", option, name)
+}
+
+// governanceAction is a single call that a proposal will make
+type governanceAction struct {
+	Target   common.Address
+	Value    *big.Int
+	Calldata []byte
+}
+
+// describeActions decodes each proposal action into a readable summary
+func describeActions(actions []governanceAction, chainID uint64) []map[string]interface{} {
+	var out []map[string]interface{}
+	for _, action := range actions {
+		summary := map[string]interface{}{
+			"target": describeAddress(action.Target, chainID),
+			"value":  fmt.Sprintf("%s wei", action.Value),
+		}
+		if len(action.Calldata) == 0 {
+			summary["function"] = "none (plain value transfer)"
+			out = append(out, summary)
+			continue
+		}
+
+		call, err := ParseTransactionData(action.Target.Hex(), "0x"+hex.EncodeToString(action.Calldata), chainID, VerifyOptions{})
+		if err != nil {
+			summary["function"] = "unknown"
+			summary["calldata"] = "0x" + hex.EncodeToString(action.Calldata)
+			out = append(out, summary)
+			continue
+		}
+		for key, value := range callSummary(*call) {
+			summary[key] = value
+		}
+		out = append(out, summary)
+	}
+	return out
+}
+
+// callSummary renders a decoded call (and any subcalls) as a nested map
+func callSummary(call CallData) map[string]interface{} {
+	summary := map[string]interface{}{"function": call.FunctionName}
+	if call.RawData != "" {
+		summary["calldata"] = call.RawData
+	}
+	if call.ParsedData != nil {
+		arguments := map[string]interface{}{}
+		for _, arg := range call.ParsedData {
+			arguments[arg.Name] = arg.shown()
+		}
+		summary["arguments"] = arguments
+	}
+	if call.Summary != nil {
+		fields := map[string]interface{}{}
+		for _, field := range call.Summary {
+			fields[field.Name] = field.Value
+		}
+		summary["summary"] = fields
+	}
+	if len(call.SubCalls) > 0 {
+		var subcalls []map[string]interface{}
+		for _, sub := range call.SubCalls {
+			entry := callSummary(sub)
+			entry["target"] = sub.Target
+			subcalls = append(subcalls, entry)
+		}
+		summary["subCalls"] = subcalls
+	}
+	return summary
+}
+
+// decodeGovernorActions decodes the actions of OpenZeppelin / Governor Bravo / Optimism Governor
+// propose, queue, execute, and cancel calls
+func decodeGovernorActions(args map[string]interface{}, chainID uint64) (map[string]interface{}, error) {
+	targets, err := argValue[[]common.Address](args, "targets")
+	if err != nil {
+		return nil, err
+	}
+	values, err := argValue[[]*big.Int](args, "values")
+	if err != nil {
+		return nil, err
+	}
+	calldatas, err := argValue[[][]byte](args, "calldatas")
+	if err != nil {
+		return nil, err
+	}
+	if len(targets) != len(values) || len(targets) != len(calldatas) {
+		return nil, fmt.Errorf("proposal has %d targets, %d values, and %d calldatas", len(targets), len(values), len(calldatas))
+	}
+
+	// Governor Bravo passes the function signature separately from the encoded arguments
+	signatures, hasSignatures := args["signatures"].([]string)
+	if hasSignatures && len(signatures) != len(targets) {
+		return nil, fmt.Errorf("proposal has %d targets but %d signatures", len(targets), len(signatures))
+	}
+
+	actions := make([]governanceAction, len(targets))
+	for i := range targets {
+		calldata := calldatas[i]
+		if hasSignatures && signatures[i] != "" {
+			calldata = append(crypto.Keccak256([]byte(signatures[i]))[:4], calldata...)
+		}
+		actions[i] = governanceAction{Target: targets[i], Value: values[i], Calldata: calldata}
+	}
+
+	return map[string]interface{}{"actions": describeActions(actions, chainID)}, nil
+}
+
+// decodeGovernorVote names the vote type of an OpenZeppelin / Governor Bravo vote
+func decodeGovernorVote(args map[string]interface{}, chainID uint64) (map[string]interface{}, error) {
+	support, err := argValue[uint8](args, "support")
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{"support": formatVoteOption(support, governorSupport)}, nil
+}
+
+// decodeAragonNewVote decodes the EVM script of an aragonOS vote into its actions
+func decodeAragonNewVote(args map[string]interface{}, chainID uint64) (map[string]interface{}, error) {
+	script, err := argValue[string](args, "executionScript")
+	if err != nil {
+		return nil, err
+	}
+	raw, err := hex.DecodeString(strings.TrimPrefix(script, "0x"))
+	if err != nil {
+		return nil, err
+	}
+	actions, err := decodeEVMScript(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{"actions": describeActions(actions, chainID)}, nil
+}
+
+// decodeEVMScript decodes an aragonOS CallsScript (spec ID 1): a 4-byte spec ID followed by
+// entries of target (20 bytes), calldata length (4 bytes), and calldata. An empty script has no actions.
+func decodeEVMScript(script []byte) ([]governanceAction, error) {
+	if len(script) == 0 {
+		return nil, nil
+	}
+	if len(script) < 4 || binary.BigEndian.Uint32(script[:4]) != 1 {
+		return nil, errors.New("unsupported EVM script spec")
+	}
+
+	var actions []governanceAction
+	for pos := 4; pos < len(script); {
+		if len(script)-pos < 24 {
+			return nil, errors.New("truncated EVM script")
+		}
+		target := common.BytesToAddress(script[pos : pos+20])
+		length := int(binary.BigEndian.Uint32(script[pos+20 : pos+24]))
+		pos += 24
+		if length > len(script)-pos {
+			return nil, errors.New("truncated EVM script calldata")
+		}
+		actions = append(actions, governanceAction{Target: target, Value: big.NewInt(0), Calldata: script[pos : pos+length]})
+		pos += length
+	}
+	return actions, nil
+}
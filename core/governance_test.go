@@ -0,0 +1,109 @@
+package core
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// grantsTransferCalldata is a transfer of 1,000 tokens with 18 decimals to the grants recipient
+func grantsTransferCalldata(t *testing.T) []byte {
+	t.Helper()
+	data := encodeKnownCall(t, "transfer(address,uint256)", common.HexToAddress(grantsRecipient), tokens(1000))
+	raw, err := hex.DecodeString(strings.TrimPrefix(data, "0x"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return raw
+}
+
+// expectTransferAction checks that a decoded proposal action is the grants transfer of a token
+func expectTransferAction(t *testing.T, summary map[string]interface{}, token string) {
+	t.Helper()
+	actions, ok := summary["actions"].([]map[string]interface{})
+	if !ok || len(actions) != 1 {
+		t.Fatalf("expected one action, got %+v", summary["actions"])
+	}
+	expectFields(t, actions[0], map[string]string{
+		"target":   token,
+		"function": "transfer",
+		"value":    "0 wei",
+	})
+	args, ok := actions[0]["arguments"].(map[string]interface{})
+	if !ok || args["to"] == nil {
+		t.Errorf("expected decoded transfer arguments, got %+v", actions[0]["arguments"])
+	}
+}
+
+func TestDecodeGovernorPropose(t *testing.T) {
+	data := encodeKnownCall(t, "propose(address[],uint256[],bytes[],string)",
+		[]common.Address{common.HexToAddress(OPTokenAddress)}, []*big.Int{big.NewInt(0)}, [][]byte{grantsTransferCalldata(t)}, "Fund grants")
+
+	summary := parseKnownCall(t, OptimismGovernor, OPMainnetChainID, data)
+	expectTransferAction(t, summary, "(OP TOKEN 🔍)")
+	if len(summary) != 1 {
+		t.Errorf("expected the summary to hold only the actions, got %+v", summary)
+	}
+	args, ok := summary["actions"].([]map[string]interface{})[0]["arguments"].(map[string]interface{})
+	if !ok || args["amount"] != "1,000.00" {
+		t.Errorf("expected the transfer amount scaled by the OP token's decimals, got %+v", args)
+	}
+}
+
+func TestDecodeGovernorBravoProposeWithSignatures(t *testing.T) {
+	// Bravo encodes only the arguments and passes the signature separately
+	data := encodeKnownCall(t, "propose(address[],uint256[],string[],bytes[],string)",
+		[]common.Address{common.HexToAddress(USDCMainnetAddress)}, []*big.Int{big.NewInt(0)},
+		[]string{"transfer(address,uint256)"}, [][]byte{grantsTransferCalldata(t)[4:]}, "Fund grants")
+
+	summary := parseKnownCall(t, UniswapGovernorBravo, MainnetChainID, data)
+	expectTransferAction(t, summary, "(USDC 🔍)")
+}
+
+func TestDecodeGovernorCastVote(t *testing.T) {
+	data := encodeKnownCall(t, "castVoteWithReason(uint256,uint8,string)", big.NewInt(7), uint8(1), "LGTM")
+
+	summary := parseKnownCall(t, ENSGovernor, MainnetChainID, data)
+	expectFields(t, summary, map[string]string{"support": "1 (For)"})
+}
+
+func TestDecodeAragonNewVote(t *testing.T) {
+	calldata := grantsTransferCalldata(t)
+	script := []byte{0, 0, 0, 1}
+	script = append(script, common.HexToAddress(USDCMainnetAddress).Bytes()...)
+	script = binary.BigEndian.AppendUint32(script, uint32(len(calldata)))
+	script = append(script, calldata...)
+	data := encodeKnownCall(t, "newVote(bytes,string)", script, "Fund grants")
+
+	summary := parseKnownCall(t, LidoAragonVoting, MainnetChainID, data)
+	expectTransferAction(t, summary, "(USDC 🔍)")
+}
+
+func TestGovernanceCallsOnUnknownTargetsStayUndecoded(t *testing.T) {
+	data := encodeKnownCall(t, "propose(address[],uint256[],bytes[],string)",
+		[]common.Address{common.HexToAddress(OPTokenAddress)}, []*big.Int{big.NewInt(0)}, [][]byte{grantsTransferCalldata(t)}, "Fund grants")
+
+	call, err := ParseTransactionData(OPGrants1, data, OPMainnetChainID, VerifyOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if call.FunctionName != "unknown" || call.Summary != nil {
+		t.Errorf("expected propose on an unknown target to stay undecoded, got %s with summary %+v", call.FunctionName, call.Summary)
+	}
+}
+
+func TestDecodeEVMScriptRejectsMalformedScripts(t *testing.T) {
+	for _, script := range [][]byte{
+		{0, 0, 0, 2},
+		{0, 0, 0, 1, 0xaa},
+		append(append([]byte{0, 0, 0, 1}, make([]byte, 20)...), 0, 0, 0, 9),
+	} {
+		if _, err := decodeEVMScript(script); err == nil {
+			t.Errorf("expected error for script %x", script)
+		}
+	}
+}
@@ -0,0 +1,341 @@
+package core
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// OPGrantsSafes are the Safes the grants council pays grants from, keyed by lowercase address
+var OPGrantsSafes = map[string]bool{
+	strings.ToLower(OPGrants1): true,
+	strings.ToLower(OPGrants2): true,
+}
+
+// Grant is a grant the grants council published: who receives it, how many tokens in total, and
+// over how long they vest. Total is in whole tokens, scaled by Decimals (18, as for OP, unless
+// given). Duration is a number of seconds or of minutes, hours, days, or weeks, such as "365d".
+// When Token is given, the vested token must be that one.
+type Grant struct {
+	ID        string `json:"id"`
+	Name      string `json:"name,omitempty"`
+	Recipient string `json:"recipient"`
+	Token     string `json:"token,omitempty"`
+	Total     string `json:"total"`
+	Decimals  *int   `json:"decimals,omitempty"`
+	Duration  string `json:"duration"`
+
+	total    *big.Int
+	duration uint64
+}
+
+// GrantsManifest is a set of published grants that the vesting schedules a transaction creates
+// are checked against
+type GrantsManifest struct {
+	Grants []Grant
+}
+
+// grantsManifestFile is the JSON form of a grants manifest
+type grantsManifestFile struct {
+	Grants []Grant `json:"grants"`
+}
+
+// NewGrantsManifest creates an empty grants manifest
+func NewGrantsManifest() *GrantsManifest {
+	return &GrantsManifest{}
+}
+
+// ParseGrantsManifest adds the grants in data, a JSON manifest:
+// {"grants": [{"id": "...", "recipient": "0x...", "total": "50000", "duration": "365d"}]}
+func (m *GrantsManifest) ParseGrantsManifest(source string, data []byte) error {
+	var manifest grantsManifestFile
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return fmt.Errorf("invalid grants manifest %s: %w", source, err)
+	}
+	for i, grant := range manifest.Grants {
+		name := grant.ID
+		if name == "" {
+			name = fmt.Sprintf("grant #%d", i+1)
+		}
+		if !common.IsHexAddress(grant.Recipient) {
+			return fmt.Errorf("invalid grants manifest %s: %s: invalid recipient %q", source, name, grant.Recipient)
+		}
+		if grant.Token != "" && !common.IsHexAddress(grant.Token) {
+			return fmt.Errorf("invalid grants manifest %s: %s: invalid token %q", source, name, grant.Token)
+		}
+		decimals := 18
+		if grant.Decimals != nil {
+			decimals = *grant.Decimals
+		}
+		total, err := parseTokenAmount(grant.Total, decimals)
+		if err != nil {
+			return fmt.Errorf("invalid grants manifest %s: %s: %w", source, name, err)
+		}
+		duration, err := parseGrantDuration(grant.Duration)
+		if err != nil {
+			return fmt.Errorf("invalid grants manifest %s: %s: %w", source, name, err)
+		}
+		grant.ID, grant.total, grant.duration = name, total, duration
+		m.Grants = append(m.Grants, grant)
+	}
+	return nil
+}
+
+// LoadGrantsManifestFile adds the grants of a local grants manifest
+func (m *GrantsManifest) LoadGrantsManifestFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read grants manifest: %w", err)
+	}
+	return m.ParseGrantsManifest(path, data)
+}
+
+// parseGrantDuration parses a number of seconds, or of minutes, hours, days, or weeks when
+// followed by m, h, d, or w
+func parseGrantDuration(text string) (uint64, error) {
+	text = strings.TrimSpace(text)
+	units := map[byte]uint64{'s': 1, 'm': 60, 'h': 3600, 'd': 86400, 'w': 7 * 86400}
+	unit := uint64(1)
+	number := text
+	if n := len(text); n > 0 {
+		if u, ok := units[text[n-1]]; ok {
+			unit, number = u, text[:n-1]
+		}
+	}
+	value, err := strconv.ParseUint(number, 10, 32)
+	if err != nil || value == 0 {
+		return 0, fmt.Errorf("invalid duration %q (use seconds, or a number of m, h, d, or w)", text)
+	}
+	return value * unit, nil
+}
+
+// vestingSchedule is a vesting schedule or stream a transaction creates
+type vestingSchedule struct {
+	name      string
+	function  string
+	recipient common.Address
+	token     common.Address
+	total     *big.Int
+	duration  uint64
+}
+
+// vestingFunctions are the functions that create vesting schedules, by signature
+var vestingFunctions = map[string]bool{
+	"createVestingSchedule(address,address,uint32,uint32,int96,uint256,uint32,bytes)":                       true,
+	"createVestingSchedule(address,address,uint32,uint32,int96,uint256,uint32,uint32)":                      true,
+	"createVestingSchedule(address,address,uint32,uint32,int96,uint256,uint32,uint32,bytes)":                true,
+	"createVestingScheduleFromAmountAndDuration(address,address,uint256,uint32,uint32,uint32,uint32)":       true,
+	"createVestingScheduleFromAmountAndDuration(address,address,uint256,uint32)":                            true,
+	"createVestingScheduleFromAmountAndDuration(address,address,uint256,uint32,uint32,uint32,uint32,bytes)": true,
+	"createAndExecuteVestingScheduleFromAmountAndDuration(address,address,uint256,uint32)":                  true,
+	"createWithDurations((address,address,uint128,address,bool,bool,(uint40,uint40),(address,uint256)))":    true,
+}
+
+// vestingSchedules decodes the Superfluid vesting schedules and Sablier V2 linear streams a
+// transaction creates, with the total each vests and over how long
+func vestingSchedules(tx SafeTransaction) ([]vestingSchedule, error) {
+	calls, err := batchCalls(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	var schedules []vestingSchedule
+	for i, call := range calls {
+		if call.Operation != 0 || len(call.Data) < 4 {
+			continue
+		}
+		functionInfo, ok := lookupFunction(call.To.Hex(), uint64(tx.Chain), hex.EncodeToString(call.Data[:4]))
+		if !ok || !vestingFunctions[functionInfo.Signature] {
+			continue
+		}
+		values, err := functionInfo.ABI.Inputs.Unpack(call.Data[4:])
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s call: %w", functionInfo.Name, err)
+		}
+		args := make(map[string]interface{}, len(values))
+		for j, value := range values {
+			args[functionInfo.ABI.Inputs[j].Name] = value
+		}
+
+		schedule := vestingSchedule{name: "The transaction", function: functionInfo.Name}
+		if len(calls) > 1 {
+			schedule.name = fmt.Sprintf("Call #%d", i+1)
+		}
+		if functionInfo.Name == "createWithDurations" {
+			err = sablierSchedule(args, &schedule)
+		} else {
+			err = superfluidSchedule(args, &schedule)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s call: %w", functionInfo.Name, err)
+		}
+		schedules = append(schedules, schedule)
+	}
+	return schedules, nil
+}
+
+// superfluidSchedule reads the recipient, total, and duration of a Superfluid vesting schedule.
+// A schedule given by its flow rate vests the cliff amount plus the flow from the cliff (or the
+// start) until the end date.
+func superfluidSchedule(args map[string]interface{}, schedule *vestingSchedule) error {
+	var err error
+	if schedule.token, err = argValue[common.Address](args, "superToken"); err != nil {
+		return err
+	}
+	if schedule.recipient, err = argValue[common.Address](args, "receiver"); err != nil {
+		return err
+	}
+	if total, ok := args["totalAmount"].(*big.Int); ok {
+		duration, err := argValue[uint32](args, "totalDuration")
+		if err != nil {
+			return err
+		}
+		schedule.total, schedule.duration = total, uint64(duration)
+		return nil
+	}
+
+	startDate, err := argValue[uint32](args, "startDate")
+	if err != nil {
+		return err
+	}
+	cliffDate, err := argValue[uint32](args, "cliffDate")
+	if err != nil {
+		return err
+	}
+	endDate, err := argValue[uint32](args, "endDate")
+	if err != nil {
+		return err
+	}
+	flowRate, err := argValue[*big.Int](args, "flowRate")
+	if err != nil {
+		return err
+	}
+	cliffAmount, err := argValue[*big.Int](args, "cliffAmount")
+	if err != nil {
+		return err
+	}
+	flowStart := startDate
+	if cliffDate != 0 {
+		flowStart = cliffDate
+	}
+	if endDate <= flowStart || startDate == 0 {
+		return fmt.Errorf("the end date is not after the start date")
+	}
+	total := new(big.Int).Mul(flowRate, big.NewInt(int64(endDate-flowStart)))
+	schedule.total, schedule.duration = total.Add(total, cliffAmount), uint64(endDate-startDate)
+	return nil
+}
+
+// sablierSchedule reads the recipient, total, and duration of a Sablier V2 linear stream
+func sablierSchedule(args map[string]interface{}, schedule *vestingSchedule) error {
+	params := args["params"]
+	if err := tupleFields(params, map[string]interface{}{
+		"recipient": &schedule.recipient, "asset": &schedule.token,
+	}); err != nil {
+		return err
+	}
+	var total *big.Int
+	if err := tupleFields(params, map[string]interface{}{"totalAmount": &total}); err != nil {
+		return err
+	}
+	durations, err := structField(params, "durations")
+	if err != nil {
+		return err
+	}
+	var duration *big.Int
+	if err := tupleFields(durations, map[string]interface{}{"total": &duration}); err != nil {
+		return err
+	}
+	schedule.total, schedule.duration = total, duration.Uint64()
+	return nil
+}
+
+// checkGrants checks every vesting schedule a transaction, or an approved child transaction,
+// creates against the grants manifest. A schedule for a recipient without a grant, or whose
+// token, total, or duration differs from the grant, is critical. Without a manifest, schedules
+// created by the OP Grants Safes are noted as not checked.
+func checkGrants(result *VerificationResult, manifest *GrantsManifest) []Warning {
+	var warnings []Warning
+	for _, r := range []*VerificationResult{result.NestedResult, result} {
+		if r == nil {
+			continue
+		}
+		schedules, err := vestingSchedules(r.Transaction)
+		if err != nil {
+			warnings = append(warnings, newWarning(warnGrantsUnchecked, "could not check the vesting schedules of %s: %v", ChecksumAddress(r.Transaction.Safe), err))
+			continue
+		}
+		if len(schedules) == 0 {
+			continue
+		}
+		if manifest == nil || len(manifest.Grants) == 0 {
+			if OPGrantsSafes[strings.ToLower(StripChainPrefix(r.Transaction.Safe))] {
+				warnings = append(warnings, newWarning(warnNoGrantsManifest,
+					"%s creates %d vesting schedule(s) that were not checked against a grants manifest", ChecksumAddress(r.Transaction.Safe), len(schedules)))
+			}
+			continue
+		}
+		for _, schedule := range schedules {
+			warnings = append(warnings, manifest.check(schedule, uint64(r.Transaction.Chain)))
+		}
+	}
+	return warnings
+}
+
+// check compares a vesting schedule with the grants of its recipient
+func (m *GrantsManifest) check(schedule vestingSchedule, chainID uint64) Warning {
+	vests := fmt.Sprintf("%s over %s", formatTokenAmount(schedule.total, schedule.token, chainID), formatDuration(schedule.duration))
+
+	var candidates []Grant
+	for _, grant := range m.Grants {
+		if strings.EqualFold(grant.Recipient, schedule.recipient.Hex()) {
+			candidates = append(candidates, grant)
+		}
+	}
+	if len(candidates) == 0 {
+		return newWarning(warnNoGrant, "%s (%s) vests %s to %s, who has no grant in the grants manifest",
+			schedule.name, schedule.function, vests, schedule.recipient.Hex())
+	}
+
+	var differences []string
+	for _, grant := range candidates {
+		differences = grantDifferences(grant, schedule, chainID)
+		if len(differences) == 0 {
+			return newWarning(warnGrantMatched, "%s (%s) vests %s to %s, matching grant %s%s",
+				schedule.name, schedule.function, vests, schedule.recipient.Hex(), grant.ID, grantName(grant))
+		}
+	}
+	// With several grants for the recipient, the differences from the last one are reported
+	grant := candidates[len(candidates)-1]
+	return newWarning(warnGrantMismatch, "%s (%s) vests %s to %s, which does not match grant %s%s: %s",
+		schedule.name, schedule.function, vests, schedule.recipient.Hex(), grant.ID, grantName(grant), strings.Join(differences, "; "))
+}
+
+// grantDifferences lists how a vesting schedule differs from a grant
+func grantDifferences(grant Grant, schedule vestingSchedule, chainID uint64) []string {
+	var differences []string
+	if grant.Token != "" && !strings.EqualFold(grant.Token, schedule.token.Hex()) {
+		differences = append(differences, fmt.Sprintf("the token is %s, the grant's is %s", describeAddress(schedule.token, chainID), ChecksumAddress(grant.Token)))
+	}
+	if schedule.total.Cmp(grant.total) != 0 {
+		differences = append(differences, fmt.Sprintf("the total is %s raw units, the grant's is %s (%s raw units)", schedule.total, grant.Total, grant.total))
+	}
+	if schedule.duration != grant.duration {
+		differences = append(differences, fmt.Sprintf("the duration is %s, the grant's is %s", formatDuration(schedule.duration), formatDuration(grant.duration)))
+	}
+	return differences
+}
+
+// grantName returns the name of a grant in parentheses, when it has one
+func grantName(grant Grant) string {
+	if grant.Name == "" {
+		return ""
+	}
+	return " (" + grant.Name + ")"
+}
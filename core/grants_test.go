@@ -0,0 +1,126 @@
+package core
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const grantsVestingScheduler = "0x5555555555555555555555555555555555555555"
+
+// vestingCall is a call to the vesting scheduler with a known function
+func vestingCall(t *testing.T, sig string, args ...interface{}) multiSendTransaction {
+	t.Helper()
+	return multiSendTransaction{To: common.HexToAddress(grantsVestingScheduler), Value: big.NewInt(0), Data: common.FromHex(encodeKnownCall(t, sig, args...))}
+}
+
+// grantsResult verifies a batch of calls made by the first OP Grants Safe
+func grantsResult(t *testing.T, grants *GrantsManifest, calls ...multiSendTransaction) *VerificationResult {
+	t.Helper()
+	tx := multiSendTx(t, calls...)
+	tx.Safe = OPGrants1
+	tx.SafeVersion = "1.3.0"
+	tx.GasToken = ZeroAddress
+	tx.RefundReceiver = ZeroAddress
+	result, err := VerifyTransaction(tx, VerifyOptions{Grants: grants})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return result
+}
+
+func TestCheckGrants(t *testing.T) {
+	grants := NewGrantsManifest()
+	manifest := `{"grants": [
+		{"id": "S6-1", "name": "Alice Labs", "recipient": "` + airdropAlice + `", "token": "` + SuperfluidOP + `", "total": "50,000", "duration": "365d"},
+		{"id": "S6-2", "recipient": "` + airdropBob + `", "total": "1000.5", "duration": "4w"}
+	]}`
+	if err := grants.ParseGrantsManifest("grants.json", []byte(manifest)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	type durations struct {
+		Cliff *big.Int
+		Total *big.Int
+	}
+	type broker struct {
+		Account common.Address
+		Fee     *big.Int
+	}
+	stream := struct {
+		Sender       common.Address
+		Recipient    common.Address
+		TotalAmount  *big.Int
+		Asset        common.Address
+		Cancelable   bool
+		Transferable bool
+		Durations    durations
+		Broker       broker
+	}{
+		Sender:      common.HexToAddress(OPGrants1),
+		Recipient:   common.HexToAddress(effectsSafe),
+		TotalAmount: tokens(10),
+		Asset:       common.HexToAddress(SuperfluidOP),
+		Durations:   durations{Cliff: big.NewInt(0), Total: big.NewInt(86400)},
+		Broker:      broker{Fee: big.NewInt(0)},
+	}
+
+	result := grantsResult(t, grants,
+		vestingCall(t, "createVestingScheduleFromAmountAndDuration(address,address,uint256,uint32)",
+			common.HexToAddress(SuperfluidOP), common.HexToAddress(airdropAlice), tokens(50000), uint32(365*86400)),
+		// 100 tokens at the cliff, then a flow over the 4 weeks of the grant
+		vestingCall(t, "createVestingSchedule(address,address,uint32,uint32,int96,uint256,uint32,uint32)",
+			common.HexToAddress(SuperfluidOP), common.HexToAddress(airdropBob), uint32(1e9), uint32(1e9), big.NewInt(1e12),
+			tokens(100), uint32(1e9+28*86400), uint32(0)),
+		multiSendTransaction{To: common.HexToAddress(SablierLockupLinear), Value: big.NewInt(0),
+			Data: common.FromHex(encodeKnownCall(t, "createWithDurations((address,address,uint128,address,bool,bool,(uint40,uint40),(address,uint256)))", stream))},
+	)
+
+	matched := warningsContaining(result.Warnings, SeverityInfo, "matching grant S6-1 (Alice Labs)")
+	if len(matched) != 1 || !strings.Contains(matched[0].Message, "Call #1") {
+		t.Errorf("expected call #1 to match grant S6-1, got %+v", result.Warnings)
+	}
+	mismatched := warningsContaining(result.Warnings, SeverityCritical, "does not match grant S6-2")
+	if len(mismatched) != 1 || !strings.Contains(mismatched[0].Message, "the grant's is 1000.5 (1000500000000000000000 raw units)") || strings.Contains(mismatched[0].Message, "duration") {
+		t.Errorf("expected call #2 to differ from grant S6-2 in its total only, got %+v", result.Warnings)
+	}
+	unknown := warningsContaining(result.Warnings, SeverityCritical, "has no grant in the grants manifest")
+	if len(unknown) != 1 || !strings.Contains(unknown[0].Message, "Call #3 (createWithDurations)") || !strings.Contains(unknown[0].Message, effectsSafe) {
+		t.Errorf("expected call #3 to have no grant, got %+v", result.Warnings)
+	}
+
+	// Without a manifest, the schedules of an OP Grants Safe are noted as not checked
+	result = grantsResult(t, nil, vestingCall(t, "createVestingScheduleFromAmountAndDuration(address,address,uint256,uint32)",
+		common.HexToAddress(SuperfluidOP), common.HexToAddress(airdropAlice), tokens(50000), uint32(365*86400)))
+	if len(warningsContaining(result.Warnings, SeverityInfo, "1 vesting schedule(s) that were not checked")) != 1 {
+		t.Errorf("expected a note that the schedule was not checked, got %+v", result.Warnings)
+	}
+}
+
+func TestParseGrantsManifestErrors(t *testing.T) {
+	for name, manifest := range map[string]string{
+		"recipient": `{"grants": [{"id": "a", "recipient": "0x1234", "total": "1", "duration": "1d"}]}`,
+		"token":     `{"grants": [{"id": "a", "recipient": "` + airdropAlice + `", "token": "OP", "total": "1", "duration": "1d"}]}`,
+		"total":     `{"grants": [{"id": "a", "recipient": "` + airdropAlice + `", "total": "one", "duration": "1d"}]}`,
+		"duration":  `{"grants": [{"id": "a", "recipient": "` + airdropAlice + `", "total": "1", "duration": "1y"}]}`,
+	} {
+		if err := NewGrantsManifest().ParseGrantsManifest("grants.json", []byte(manifest)); err == nil || !strings.Contains(err.Error(), "grants.json: a:") {
+			t.Errorf("%s: expected an error naming the grant, got %v", name, err)
+		}
+	}
+}
+
+func TestParseGrantDuration(t *testing.T) {
+	for text, want := range map[string]uint64{"3600": 3600, "90m": 5400, "12h": 43200, "365d": 365 * 86400, "4w": 28 * 86400} {
+		if got, err := parseGrantDuration(text); err != nil || got != want {
+			t.Errorf("%s: expected %d, got %d (%v)", text, want, got, err)
+		}
+	}
+	for _, text := range []string{"", "0d", "d", "1.5d", "-1"} {
+		if _, err := parseGrantDuration(text); err == nil {
+			t.Errorf("%q: expected an error", text)
+		}
+	}
+}
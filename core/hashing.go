@@ -1,7 +1,9 @@
 package core
 
 import (
+	"fmt"
 	"math/big"
+	"strings"
 
 	semver "github.com/Masterminds/semver/v3"
 	"github.com/ethereum/go-ethereum/accounts/abi"
@@ -9,182 +11,202 @@ import (
 	"github.com/ethereum/go-ethereum/crypto"
 )
 
-// Define version constraints for Safe versions where behavior changed
-var (
-	version120Constraint, _ = semver.NewConstraint("<= 1.2.0")
-	version100Constraint, _ = semver.NewConstraint("< 1.0.0")
-)
+// SupportedSafeVersions lists every Safe release the hashing code has been checked against.
+// Anything else is rejected instead of silently falling through to the current-version path.
+var SupportedSafeVersions = map[string]bool{
+	"0.1.0": true,
+	"1.0.0": true,
+	"1.1.0": true,
+	"1.1.1": true,
+	"1.2.0": true,
+	"1.3.0": true,
+	"1.4.0": true,
+	"1.4.1": true,
+}
 
-// CalculateDomainHash calculates the EIP-712 domain hash for a Safe transaction
-func CalculateDomainHash(tx SafeTransaction) (string, error) {
-	currentVersion, err := semver.NewVersion(tx.SafeVersion)
+// SupportedSafeVersionMetadata lists build metadata suffixes reported by the Safe service
+// that do not change hashing (e.g. "1.3.0+L2" for the L2 singleton which only adds events)
+var SupportedSafeVersionMetadata = map[string]bool{
+	"":   true,
+	"L2": true,
+}
+
+// ParseSafeVersion parses a Safe version string and rejects versions that the hashing code
+// has no explicit support for
+func ParseSafeVersion(version string) (*semver.Version, error) {
+	parsed, err := semver.StrictNewVersion(strings.TrimPrefix(version, "v"))
 	if err != nil {
-		return "", err
+		return nil, fmt.Errorf("invalid Safe version %q: %w", version, err)
 	}
 
-	var domainType abi.Type
-	var arguments abi.Arguments
-	var packed []byte
+	if parsed.Prerelease() != "" {
+		return nil, withKind(ErrUnsupportedSafeVersion, fmt.Errorf("unsupported Safe version %q: pre-release versions are not supported", version))
+	}
 
-	if version120Constraint.Check(currentVersion) {
-		// Legacy domain hash calculation (<= 1.2.0)
-		domainType, err = abi.NewType("tuple", "", []abi.ArgumentMarshaling{
-			{Name: "typehash", Type: "bytes32"},
-			// chainId omitted for versions <= 1.2.0
-			{Name: "verifyingContract", Type: "address"},
-		})
-		if err != nil {
-			return "", err
-		}
+	if !SupportedSafeVersionMetadata[parsed.Metadata()] {
+		return nil, withKind(ErrUnsupportedSafeVersion, fmt.Errorf("unsupported Safe version %q: unknown variant %q (supported variants: L2)", version, parsed.Metadata()))
+	}
 
-		arguments = abi.Arguments{
-			abi.Argument{Type: domainType, Name: "domain"},
-		}
+	base := fmt.Sprintf("%d.%d.%d", parsed.Major(), parsed.Minor(), parsed.Patch())
+	if !SupportedSafeVersions[base] {
+		return nil, withKind(ErrUnsupportedSafeVersion, fmt.Errorf("unsupported Safe version %q: hashing has not been verified for this release", version))
+	}
 
-		typehash := common.HexToHash(DomainSeparatorTypehashOld)
-		safeAddress := common.HexToAddress(tx.Safe)
-
-		packed, err = arguments.Pack(
-			struct {
-				Typehash          [32]byte
-				VerifyingContract common.Address
-			}{
-				Typehash:          typehash,
-				VerifyingContract: safeAddress,
-			},
-		)
-		if err != nil {
-			return "", err
-		}
-	} else {
-		// Current domain hash calculation (> 1.2.0)
-		domainType, err = abi.NewType("tuple", "", []abi.ArgumentMarshaling{
-			{Name: "typehash", Type: "bytes32"},
-			{Name: "chainId", Type: "uint256"},
-			{Name: "verifyingContract", Type: "address"},
-		})
-		if err != nil {
-			return "", err
-		}
+	return parsed, nil
+}
 
-		arguments = abi.Arguments{
-			abi.Argument{Type: domainType, Name: "domain"},
-		}
+// HashingStrategy computes the EIP-712 domain and message hashes of Safe transactions for the
+// Safe versions it supports. A Safe release that changes the typehashes or the hashed fields is
+// added as a new strategy rather than as another branch of the existing ones.
+type HashingStrategy interface {
+	// Name identifies the strategy in errors and notes
+	Name() string
 
-		typehash := common.HexToHash(DomainSeparatorTypehash)
-		chainID := big.NewInt(int64(tx.Chain))
-		safeAddress := common.HexToAddress(tx.Safe)
-
-		packed, err = arguments.Pack(
-			struct {
-				Typehash          [32]byte
-				ChainId           *big.Int
-				VerifyingContract common.Address
-			}{
-				Typehash:          typehash,
-				ChainId:           chainID,
-				VerifyingContract: safeAddress,
-			},
-		)
-		if err != nil {
-			return "", err
-		}
-	}
+	// Supports reports whether the strategy hashes transactions of a Safe version
+	Supports(version *semver.Version) bool
 
-	// Calculate hash
-	hash := crypto.Keccak256Hash(packed)
-	return hash.Hex(), nil
+	DomainHash(tx SafeTransaction) (common.Hash, error)
+	MessageHash(tx SafeTransaction) (common.Hash, error)
 }
 
-// CalculateMessageHash calculates the EIP-712 message hash for a Safe transaction
-func CalculateMessageHash(tx SafeTransaction) (string, error) {
-	currentVersion, err := semver.NewVersion(tx.SafeVersion)
-	if err != nil {
-		return "", err
-	}
+// HashingStrategies are the hashing strategies of every supported Safe version. Each supported
+// version is hashed by exactly one of them.
+var HashingStrategies = []HashingStrategy{
+	safeTxHashing{
+		name:           "Safe 0.1.0",
+		versions:       mustSemverConstraint("< 1.0.0"),
+		domainTypehash: DomainSeparatorTypehashOld,
+		safeTxTypehash: SafeTxTypehashOld,
+	},
+	safeTxHashing{
+		name:           "Safe 1.0.0 to 1.2.0",
+		versions:       mustSemverConstraint(">= 1.0.0, <= 1.2.0"),
+		domainTypehash: DomainSeparatorTypehashOld,
+		safeTxTypehash: SafeTxTypehash,
+	},
+	safeTxHashing{
+		name:           "Safe 1.3.0 and later",
+		versions:       mustSemverConstraint("> 1.2.0"),
+		domainTypehash: DomainSeparatorTypehash,
+		domainChainID:  true,
+		safeTxTypehash: SafeTxTypehash,
+	},
+}
 
-	// Parse the ABI types (structure is the same, only typehash might change)
-	messageType, err := abi.NewType("tuple", "", []abi.ArgumentMarshaling{
-		{Name: "typehash", Type: "bytes32"},
-		{Name: "to", Type: "address"},
-		{Name: "value", Type: "uint256"},
-		{Name: "dataHash", Type: "bytes32"},
-		{Name: "operation", Type: "uint8"},
-		{Name: "safeTxGas", Type: "uint256"},
-		{Name: "baseGas", Type: "uint256"},
-		{Name: "gasPrice", Type: "uint256"},
-		{Name: "gasToken", Type: "address"},
-		{Name: "refundReceiver", Type: "address"},
-		{Name: "nonce", Type: "uint256"},
-	})
+// HashingStrategyFor returns the hashing strategy of a Safe version, rejecting versions that the
+// hashing code has no explicit support for
+func HashingStrategyFor(version string) (HashingStrategy, error) {
+	parsed, err := ParseSafeVersion(version)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-
-	// Create the ABI arguments
-	arguments := abi.Arguments{
-		abi.Argument{Type: messageType, Name: "message"},
+	for _, strategy := range HashingStrategies {
+		if strategy.Supports(parsed) {
+			return strategy, nil
+		}
 	}
+	return nil, withKind(ErrUnsupportedSafeVersion, fmt.Errorf("unsupported Safe version %q: no hashing strategy supports it", version))
+}
 
-	// Determine the correct typehash based on version
-	var safeTxTypehashToUse string
-	if version100Constraint.Check(currentVersion) {
-		safeTxTypehashToUse = SafeTxTypehashOld
-	} else {
-		safeTxTypehashToUse = SafeTxTypehash
+// mustSemverConstraint parses a version constraint
+func mustSemverConstraint(constraint string) *semver.Constraints {
+	c, err := semver.NewConstraint(constraint)
+	if err != nil {
+		panic(err)
 	}
+	return c
+}
 
-	// Convert inputs to appropriate types
-	typehash := common.HexToHash(safeTxTypehashToUse)
-	toAddress := common.HexToAddress(tx.To)
+// safeTxHashing hashes the SafeTx struct of the Safe releases so far, which differ only in the
+// typehashes and in whether the domain includes the chain ID
+type safeTxHashing struct {
+	name           string
+	versions       *semver.Constraints
+	domainTypehash string
+	domainChainID  bool
+	safeTxTypehash string
+}
 
-	// Use value directly (already *big.Int)
-	value := tx.Value
+func (h safeTxHashing) Name() string {
+	return h.name
+}
 
-	// Calculate data hash
-	dataBytes := common.FromHex(tx.Data)
-	dataHash := crypto.Keccak256Hash(dataBytes)
+func (h safeTxHashing) Supports(version *semver.Version) bool {
+	return h.versions.Check(version)
+}
 
-	// Convert uint values to big.Int
-	safeTxGas := big.NewInt(int64(tx.SafeTxGas))
-	baseGas := big.NewInt(int64(tx.BaseGas))
-	gasPrice := big.NewInt(int64(tx.GasPrice))
-	nonce := big.NewInt(int64(tx.Nonce))
+func (h safeTxHashing) DomainHash(tx SafeTransaction) (common.Hash, error) {
+	arguments := abi.Arguments{{Name: "typehash", Type: mustABIType("bytes32")}}
+	values := []interface{}{common.HexToHash(h.domainTypehash)}
+	if h.domainChainID {
+		arguments = append(arguments, abi.Argument{Name: "chainId", Type: mustABIType("uint256")})
+		values = append(values, big.NewInt(int64(tx.Chain)))
+	}
+	arguments = append(arguments, abi.Argument{Name: "verifyingContract", Type: mustABIType("address")})
+	values = append(values, common.HexToAddress(tx.Safe))
+	packed, err := arguments.Pack(values...)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return crypto.Keccak256Hash(packed), nil
+}
 
-	// Pack the values
+func (h safeTxHashing) MessageHash(tx SafeTransaction) (common.Hash, error) {
+	arguments := abi.Arguments{
+		{Name: "typehash", Type: mustABIType("bytes32")},
+		{Name: "to", Type: mustABIType("address")},
+		{Name: "value", Type: mustABIType("uint256")},
+		{Name: "dataHash", Type: mustABIType("bytes32")},
+		{Name: "operation", Type: mustABIType("uint8")},
+		{Name: "safeTxGas", Type: mustABIType("uint256")},
+		{Name: "baseGas", Type: mustABIType("uint256")},
+		{Name: "gasPrice", Type: mustABIType("uint256")},
+		{Name: "gasToken", Type: mustABIType("address")},
+		{Name: "refundReceiver", Type: mustABIType("address")},
+		{Name: "nonce", Type: mustABIType("uint256")},
+	}
 	packed, err := arguments.Pack(
-		struct {
-			Typehash       [32]byte
-			To             common.Address
-			Value          *big.Int
-			DataHash       [32]byte
-			Operation      uint8
-			SafeTxGas      *big.Int
-			BaseGas        *big.Int
-			GasPrice       *big.Int
-			GasToken       common.Address
-			RefundReceiver common.Address
-			Nonce          *big.Int
-		}{
-			Typehash:       typehash,
-			To:             toAddress,
-			Value:          value,
-			DataHash:       dataHash,
-			Operation:      uint8(tx.Operation),
-			SafeTxGas:      safeTxGas,
-			BaseGas:        baseGas,
-			GasPrice:       gasPrice,
-			GasToken:       common.HexToAddress(tx.GasToken),
-			RefundReceiver: common.HexToAddress(tx.RefundReceiver),
-			Nonce:          nonce,
-		},
+		common.HexToHash(h.safeTxTypehash),
+		common.HexToAddress(tx.To),
+		tx.Value,
+		crypto.Keccak256Hash(common.FromHex(tx.Data)),
+		uint8(tx.Operation),
+		big.NewInt(int64(tx.SafeTxGas)),
+		big.NewInt(int64(tx.BaseGas)),
+		big.NewInt(int64(tx.GasPrice)),
+		common.HexToAddress(tx.GasToken),
+		common.HexToAddress(tx.RefundReceiver),
+		big.NewInt(int64(tx.Nonce)),
 	)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return crypto.Keccak256Hash(packed), nil
+}
+
+// CalculateDomainHash calculates the EIP-712 domain hash for a Safe transaction
+func CalculateDomainHash(tx SafeTransaction) (string, error) {
+	strategy, err := HashingStrategyFor(tx.SafeVersion)
+	if err != nil {
+		return "", err
+	}
+	hash, err := strategy.DomainHash(tx)
 	if err != nil {
 		return "", err
 	}
+	return hash.Hex(), nil
+}
 
-	// Calculate hash
-	hash := crypto.Keccak256Hash(packed)
+// CalculateMessageHash calculates the EIP-712 message hash for a Safe transaction
+func CalculateMessageHash(tx SafeTransaction) (string, error) {
+	strategy, err := HashingStrategyFor(tx.SafeVersion)
+	if err != nil {
+		return "", err
+	}
+	hash, err := strategy.MessageHash(tx)
+	if err != nil {
+		return "", err
+	}
 	return hash.Hex(), nil
 }
 
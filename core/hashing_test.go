@@ -179,3 +179,74 @@ func TestCalculateMessageHash(t *testing.T) {
 		})
 	}
 }
+
+func TestParseSafeVersion(t *testing.T) {
+	tests := []struct {
+		in string
+		ok bool
+	}{
+		{"1.3.0", true},
+		{"1.4.1", true},
+		{"1.3.0+L2", true},
+		{"1.0.0", true},
+		{"1.5.0", false},
+		{"2.0.0", false},
+		{"1.3.0+zksync", false},
+		{"1.4.1-rc.1", false},
+		{"", false},
+		{"not-a-version", false},
+	}
+	for _, tc := range tests {
+		_, err := ParseSafeVersion(tc.in)
+		if tc.ok != (err == nil) {
+			t.Fatalf("ParseSafeVersion(%q) error = %v, want ok=%v", tc.in, err, tc.ok)
+		}
+	}
+}
+
+func TestCalculateDomainHashRejectsUnknownVersion(t *testing.T) {
+	tx := SafeTransaction{
+		Safe:        "0x847B5c174615B1B7fDF770882256e2D3E95b9D92",
+		SafeVersion: "1.5.0",
+		Chain:       1,
+	}
+	if _, err := CalculateDomainHash(tx); err == nil {
+		t.Fatalf("expected error for unsupported Safe version")
+	}
+}
+
+func TestHashingStrategyFor(t *testing.T) {
+	// Every supported version is hashed by exactly one strategy
+	for version := range SupportedSafeVersions {
+		parsed, err := ParseSafeVersion(version)
+		if err != nil {
+			t.Fatalf("ParseSafeVersion(%q): %v", version, err)
+		}
+		matches := 0
+		for _, strategy := range HashingStrategies {
+			if strategy.Supports(parsed) {
+				matches++
+			}
+		}
+		if matches != 1 {
+			t.Errorf("Safe version %s is supported by %d hashing strategies, want 1", version, matches)
+		}
+	}
+
+	for _, tc := range []struct{ a, b string }{{"1.0.0", "1.2.0"}, {"1.3.0", "1.4.1+L2"}} {
+		a, err := HashingStrategyFor(tc.a)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := HashingStrategyFor(tc.b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if a.Name() != b.Name() {
+			t.Errorf("Safe versions %s and %s use the hashing strategies %q and %q, want the same", tc.a, tc.b, a.Name(), b.Name())
+		}
+	}
+	if _, err := HashingStrategyFor("1.5.0"); err == nil {
+		t.Error("expected an error for an unsupported Safe version")
+	}
+}
@@ -0,0 +1,107 @@
+package core
+
+import (
+	"errors"
+	"go/parser"
+	"go/token"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// coreModules are the only modules outside the standard library core may import, so that wallets
+// and dashboards can use it without the dependencies of the command line tool
+var coreModules = []string{
+	"github.com/Masterminds/semver/v3",
+	"github.com/ethereum/go-ethereum",
+}
+
+// coreDependencies are the modules the go-ethereum packages core imports depend on: the KZG and
+// Verkle code of its transaction types, the keystore, and the libraries under its crypto and math.
+// Other go-ethereum packages, such as trie, bring in gopsutil, tablewriter, fastcache, and flock,
+// which core must not depend on.
+var coreDependencies = []string{
+	"github.com/bits-and-blooms/bitset",
+	"github.com/consensys/bavard",
+	"github.com/consensys/gnark-crypto",
+	"github.com/crate-crypto/go-ipa",
+	"github.com/crate-crypto/go-kzg-4844",
+	"github.com/deckarep/golang-set/v2",
+	"github.com/ethereum/go-verkle",
+	"github.com/fsnotify/fsnotify",
+	"github.com/google/uuid",
+	"github.com/holiman/uint256",
+	"github.com/mmcloughlin/addchain",
+	"golang.org/x/crypto",
+	"golang.org/x/sync",
+	"golang.org/x/sys",
+	"rsc.io/tmplfunc",
+}
+
+// cliOnlyPackages are standard library packages for the camera, browser, and web pages, which
+// belong in the qr package
+var cliOnlyPackages = map[string]bool{
+	"embed":         true,
+	"html/template": true,
+	"os/exec":       true,
+}
+
+func TestCoreImports(t *testing.T) {
+	files, err := filepath.Glob("*.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fset := token.NewFileSet()
+	for _, file := range files {
+		if strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+		parsed, err := parser.ParseFile(fset, file, nil, parser.ImportsOnly)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, spec := range parsed.Imports {
+			path, _ := strconv.Unquote(spec.Path.Value)
+			if cliOnlyPackages[path] {
+				t.Errorf("%s imports %s, which belongs outside core", file, path)
+				continue
+			}
+			if !strings.Contains(strings.Split(path, "/")[0], ".") {
+				continue
+			}
+			if !inModules(path, coreModules) {
+				t.Errorf("%s imports %s; core only depends on %s", file, path, strings.Join(coreModules, ", "))
+			}
+		}
+	}
+}
+
+func TestCoreDependencies(t *testing.T) {
+	// Every package core builds with, not only the ones it imports, is listed with its module
+	out, err := exec.Command("go", "list", "-deps", "-f", "{{with .Module}}{{$.ImportPath}} {{.Path}}{{end}}", ".").Output()
+	if errors.Is(err, exec.ErrNotFound) {
+		t.Skip("go is not installed")
+	}
+	if err != nil {
+		t.Fatalf("go list failed: %v", err)
+	}
+	allowed := append(append([]string{"github.com/ethereum-optimism/op-txverify/core"}, coreModules...), coreDependencies...)
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		pkg, module, _ := strings.Cut(line, " ")
+		if !inModules(module, allowed) {
+			t.Errorf("core depends on %s from %s; add its dependency outside core", pkg, module)
+		}
+	}
+}
+
+// inModules reports whether a package path is in one of the modules
+func inModules(path string, modules []string) bool {
+	for _, module := range modules {
+		if path == module || strings.HasPrefix(path, module+"/") {
+			return true
+		}
+	}
+	return false
+}
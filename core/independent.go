@@ -0,0 +1,539 @@
+package core
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// DecodeMismatch is a call whose arguments the two decoders of CrossCheckDecoding disagree on
+type DecodeMismatch struct {
+	// Path is the hierarchical index of the call ("" for the top-level call, "2.1", ...)
+	Path     string `json:"path"`
+	Function string `json:"function"`
+	Message  string `json:"message"`
+}
+
+func (m DecodeMismatch) String() string {
+	call := "top-level call"
+	if m.Path != "" {
+		call = "call " + m.Path
+	}
+	return fmt.Sprintf("%s (%s): %s", call, m.Function, m.Message)
+}
+
+// CrossCheckDecoding decodes the calldata of a call, and of every MultiSend and Multicall3
+// subcall, along two separate code paths: go-ethereum's abi package and a strict decoder in this
+// file that works from the function signature alone. A bug in either that hides or alters an
+// argument shows up as a disagreement, which is returned.
+func CrossCheckDecoding(to, data string, chainID uint64) []DecodeMismatch {
+	raw, err := decodeHexDigits(data)
+	if err != nil {
+		return []DecodeMismatch{{Function: "unknown", Message: "calldata is not valid hex: " + err.Error()}}
+	}
+	return crossCheckCall(common.HexToAddress(StripChainPrefix(to)), raw, chainID, "")
+}
+
+// crossCheckCall compares both decodings of one call and recurses into its subcalls
+func crossCheckCall(to common.Address, data []byte, chainID uint64, path string) []DecodeMismatch {
+	if len(data) < 4 {
+		return nil
+	}
+	functionInfo, ok := lookupFunction(to.Hex(), chainID, hex.EncodeToString(data[:4]))
+	if !ok {
+		return nil
+	}
+	mismatch := func(format string, args ...interface{}) []DecodeMismatch {
+		return []DecodeMismatch{{Path: path, Function: functionInfo.Signature, Message: fmt.Sprintf(format, args...)}}
+	}
+
+	// The selector itself is recomputed from the signature rather than trusted
+	if selector := crypto.Keccak256([]byte(functionInfo.Signature))[:4]; !bytes.Equal(selector, data[:4]) {
+		return mismatch("selector %x does not belong to the signature, which hashes to %x", data[:4], selector)
+	}
+
+	params, err := parseSignatureParams(functionInfo.Signature)
+	if err != nil {
+		return mismatch("independent decoder cannot parse the signature: %v", err)
+	}
+	independent, independentErr := decodeParams(params, data[4:])
+	unpacked, abiErr := functionInfo.ABI.Inputs.Unpack(data[4:])
+	switch {
+	case abiErr != nil && independentErr != nil:
+		// Both decoders reject the calldata; it is shown raw
+		return nil
+	case abiErr != nil:
+		return mismatch("go-ethereum rejects the arguments (%v) but the independent decoder accepts them", abiErr)
+	case independentErr != nil:
+		return mismatch("the independent decoder rejects the arguments (%v) but go-ethereum accepts them", independentErr)
+	}
+
+	for i, input := range functionInfo.ABI.Inputs {
+		want := independent[i].canonical()
+		got, err := canonicalABIValue(input.Type, reflect.ValueOf(unpacked[i]))
+		if err != nil {
+			return mismatch("argument %d (%s): %v", i, input.Name, err)
+		}
+		if got != want {
+			return mismatch("argument %d (%s) decodes as %s with go-ethereum but %s independently", i, input.Name, got, want)
+		}
+	}
+
+	// Recurse into subcalls using the independently decoded values, now known to agree, on the
+	// same contracts ParseTransactionData recurses into
+	normalizedTo := strings.ToLower(to.Hex())
+	if !MulticallAddresses[chainID][normalizedTo] {
+		return nil
+	}
+	var subcalls []struct {
+		to   common.Address
+		data []byte
+	}
+	switch {
+	case SafeMultisendAddresses[normalizedTo] && functionInfo.Signature == SafeMultisendSig:
+		packed := independent[0].bytes
+		entries, err := decodePackedMultiSend(packed)
+		if err != nil {
+			return mismatch("the independent decoder rejects the MultiSend payload: %v", err)
+		}
+		reference, err := decodeMultiSendTransactions(packed)
+		if err != nil {
+			return mismatch("the MultiSend payload is rejected normally but not independently: %v", err)
+		}
+		if len(reference) != len(entries) {
+			return mismatch("the MultiSend payload decodes as %d calls normally but %d independently", len(reference), len(entries))
+		}
+		for i, entry := range entries {
+			ref := reference[i]
+			if ref.Operation != entry.Operation || ref.To != entry.To || ref.Value.Cmp(entry.Value) != 0 || !bytes.Equal(ref.Data, entry.Data) {
+				return mismatch("MultiSend call %d decodes differently: operation %d to %s value %s normally, operation %d to %s value %s independently",
+					i+1, ref.Operation, ref.To.Hex(), ref.Value, entry.Operation, entry.To.Hex(), entry.Value)
+			}
+			subcalls = append(subcalls, struct {
+				to   common.Address
+				data []byte
+			}{entry.To, entry.Data})
+		}
+	case Multicall3Addresses[normalizedTo] && (functionInfo.Name == "aggregate3" || functionInfo.Name == "aggregate3Value"):
+		for _, call := range independent[0].elems {
+			fields := call.elems
+			subcalls = append(subcalls, struct {
+				to   common.Address
+				data []byte
+			}{common.BytesToAddress(fields[0].bytes), fields[len(fields)-1].bytes})
+		}
+	}
+
+	var mismatches []DecodeMismatch
+	for i, subcall := range subcalls {
+		index := strconv.Itoa(i + 1)
+		if path != "" {
+			index = path + "." + index
+		}
+		mismatches = append(mismatches, crossCheckCall(subcall.to, subcall.data, chainID, index)...)
+	}
+	return mismatches
+}
+
+// decodePackedMultiSend decodes a packed MultiSend payload, rejecting anything but an exact
+// sequence of well-formed entries
+func decodePackedMultiSend(data []byte) ([]multiSendTransaction, error) {
+	var entries []multiSendTransaction
+	for pos := 0; pos < len(data); {
+		if len(data)-pos < 1+20+32+32 {
+			return nil, fmt.Errorf("%d trailing bytes after call %d", len(data)-pos, len(entries))
+		}
+		entry := multiSendTransaction{
+			Operation: data[pos],
+			To:        common.BytesToAddress(data[pos+1 : pos+21]),
+			Value:     new(big.Int).SetBytes(data[pos+21 : pos+53]),
+		}
+		length := new(big.Int).SetBytes(data[pos+53 : pos+85])
+		pos += 85
+		if !length.IsUint64() || length.Uint64() > uint64(len(data)-pos) {
+			return nil, fmt.Errorf("call %d claims %s bytes of data but only %d remain", len(entries)+1, length, len(data)-pos)
+		}
+		entry.Data = data[pos : pos+int(length.Uint64())]
+		pos += int(length.Uint64())
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// abiKind is the kind of an ABI type understood by the independent decoder
+type abiKind int
+
+const (
+	abiUint abiKind = iota
+	abiInt
+	abiAddress
+	abiBool
+	abiFixedBytes
+	abiBytes
+	abiString
+	abiSlice
+	abiArray
+	abiTuple
+)
+
+// abiParam is an ABI type parsed from a canonical signature. size is the bit size of integers,
+// the byte size of fixed bytes, and the length of fixed arrays.
+type abiParam struct {
+	kind   abiKind
+	size   int
+	elem   *abiParam
+	fields []*abiParam
+}
+
+// parseSignatureParams parses the parameter types of a canonical function signature such as
+// "aggregate3((address,bool,bytes)[])"
+func parseSignatureParams(signature string) ([]*abiParam, error) {
+	open := strings.Index(signature, "(")
+	if open < 1 || !strings.HasSuffix(signature, ")") {
+		return nil, fmt.Errorf("invalid signature %q", signature)
+	}
+	return parseTupleParams(signature[open+1 : len(signature)-1])
+}
+
+// parseTupleParams parses a comma separated list of types, splitting only at the top level
+func parseTupleParams(list string) ([]*abiParam, error) {
+	if list == "" {
+		return nil, nil
+	}
+	var params []*abiParam
+	depth, start := 0, 0
+	for i := 0; i <= len(list); i++ {
+		if i < len(list) {
+			switch list[i] {
+			case '(':
+				depth++
+			case ')':
+				depth--
+			}
+			if list[i] != ',' || depth != 0 {
+				continue
+			}
+		}
+		param, err := parseParam(list[start:i])
+		if err != nil {
+			return nil, err
+		}
+		params = append(params, param)
+		start = i + 1
+	}
+	if depth != 0 {
+		return nil, fmt.Errorf("unbalanced parentheses in %q", list)
+	}
+	return params, nil
+}
+
+// parseParam parses a single canonical type
+func parseParam(t string) (*abiParam, error) {
+	if strings.HasSuffix(t, "]") {
+		open := strings.LastIndex(t, "[")
+		if open < 0 {
+			return nil, fmt.Errorf("invalid array type %q", t)
+		}
+		elem, err := parseParam(t[:open])
+		if err != nil {
+			return nil, err
+		}
+		if open+1 == len(t)-1 {
+			return &abiParam{kind: abiSlice, elem: elem}, nil
+		}
+		length, err := strconv.Atoi(t[open+1 : len(t)-1])
+		if err != nil || length < 1 {
+			return nil, fmt.Errorf("invalid array length in %q", t)
+		}
+		return &abiParam{kind: abiArray, size: length, elem: elem}, nil
+	}
+	if strings.HasPrefix(t, "(") && strings.HasSuffix(t, ")") {
+		fields, err := parseTupleParams(t[1 : len(t)-1])
+		if err != nil {
+			return nil, err
+		}
+		return &abiParam{kind: abiTuple, fields: fields}, nil
+	}
+
+	sized := func(prefix string, kind abiKind, def, max, step int) (*abiParam, error) {
+		size := def
+		if rest := strings.TrimPrefix(t, prefix); rest != "" {
+			n, err := strconv.Atoi(rest)
+			if err != nil || n < step || n > max || n%step != 0 {
+				return nil, fmt.Errorf("invalid type %q", t)
+			}
+			size = n
+		}
+		return &abiParam{kind: kind, size: size}, nil
+	}
+	switch {
+	case t == "address":
+		return &abiParam{kind: abiAddress}, nil
+	case t == "bool":
+		return &abiParam{kind: abiBool}, nil
+	case t == "string":
+		return &abiParam{kind: abiString}, nil
+	case t == "bytes":
+		return &abiParam{kind: abiBytes}, nil
+	case strings.HasPrefix(t, "bytes"):
+		return sized("bytes", abiFixedBytes, 0, 32, 1)
+	case strings.HasPrefix(t, "uint"):
+		return sized("uint", abiUint, 256, 256, 8)
+	case strings.HasPrefix(t, "int"):
+		return sized("int", abiInt, 256, 256, 8)
+	}
+	return nil, fmt.Errorf("unsupported type %q", t)
+}
+
+// dynamic reports whether a type is encoded out of line
+func (p *abiParam) dynamic() bool {
+	switch p.kind {
+	case abiBytes, abiString, abiSlice:
+		return true
+	case abiArray:
+		return p.elem.dynamic()
+	case abiTuple:
+		for _, field := range p.fields {
+			if field.dynamic() {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// headSize is the number of bytes a type takes in the head of its enclosing tuple
+func (p *abiParam) headSize() int {
+	if p.dynamic() {
+		return 32
+	}
+	switch p.kind {
+	case abiArray:
+		return p.size * p.elem.headSize()
+	case abiTuple:
+		size := 0
+		for _, field := range p.fields {
+			size += field.headSize()
+		}
+		return size
+	}
+	return 32
+}
+
+// abiValue is a value decoded by the independent decoder
+type abiValue struct {
+	param  *abiParam
+	number *big.Int
+	bytes  []byte
+	text   string
+	elems  []abiValue
+}
+
+// canonical renders a value in the form shared with canonicalABIValue
+func (v abiValue) canonical() string {
+	switch v.param.kind {
+	case abiUint, abiInt:
+		return v.number.String()
+	case abiBool:
+		return strconv.FormatBool(v.number.Sign() != 0)
+	case abiAddress:
+		return strings.ToLower(common.BytesToAddress(v.bytes).Hex())
+	case abiFixedBytes, abiBytes:
+		return "0x" + hex.EncodeToString(v.bytes)
+	case abiString:
+		return strconv.Quote(v.text)
+	}
+	parts := make([]string, len(v.elems))
+	for i, elem := range v.elems {
+		parts[i] = elem.canonical()
+	}
+	if v.param.kind == abiTuple {
+		return "(" + strings.Join(parts, ",") + ")"
+	}
+	return "[" + strings.Join(parts, ",") + "]"
+}
+
+// decodeParams decodes a tuple of values whose encoding starts at data[0]
+func decodeParams(params []*abiParam, data []byte) ([]abiValue, error) {
+	values := make([]abiValue, len(params))
+	head := 0
+	for i, param := range params {
+		if head+param.headSize() > len(data) {
+			return nil, fmt.Errorf("argument %d runs past the end of the data", i)
+		}
+		start := head
+		if param.dynamic() {
+			offset, err := readLength(data[head : head+32])
+			if err != nil {
+				return nil, fmt.Errorf("argument %d offset: %w", i, err)
+			}
+			if offset > len(data) {
+				return nil, fmt.Errorf("argument %d offset %d is past the end of the data", i, offset)
+			}
+			start = offset
+		}
+		value, err := decodeValue(param, data[start:])
+		if err != nil {
+			return nil, fmt.Errorf("argument %d: %w", i, err)
+		}
+		values[i] = value
+		head += param.headSize()
+	}
+	return values, nil
+}
+
+// decodeValue decodes a single value whose encoding starts at data[0]
+func decodeValue(param *abiParam, data []byte) (abiValue, error) {
+	value := abiValue{param: param}
+	switch param.kind {
+	case abiTuple:
+		elems, err := decodeParams(param.fields, data)
+		value.elems = elems
+		return value, err
+	case abiArray:
+		elems, err := decodeParams(repeatParam(param.elem, param.size), data)
+		value.elems = elems
+		return value, err
+	}
+
+	if len(data) < 32 {
+		return value, errors.New("value runs past the end of the data")
+	}
+	word := data[:32]
+	switch param.kind {
+	case abiUint:
+		value.number = new(big.Int).SetBytes(word)
+		if value.number.BitLen() > param.size {
+			return value, fmt.Errorf("uint%d has dirty high bits", param.size)
+		}
+	case abiInt:
+		value.number = new(big.Int).SetBytes(word)
+		if word[0]&0x80 != 0 {
+			value.number.Sub(value.number, new(big.Int).Lsh(big.NewInt(1), 256))
+		}
+		limit := new(big.Int).Lsh(big.NewInt(1), uint(param.size-1))
+		if value.number.Cmp(limit) >= 0 || value.number.Cmp(new(big.Int).Neg(limit)) < 0 {
+			return value, fmt.Errorf("int%d is not sign-extended", param.size)
+		}
+	case abiBool:
+		value.number = new(big.Int).SetBytes(word)
+		if value.number.Cmp(big.NewInt(1)) > 0 {
+			return value, errors.New("bool is neither 0 nor 1")
+		}
+	case abiAddress:
+		if !isZero(word[:12]) {
+			return value, errors.New("address has dirty high bytes")
+		}
+		value.bytes = word[12:]
+	case abiFixedBytes:
+		if !isZero(word[param.size:]) {
+			return value, fmt.Errorf("bytes%d has dirty low bytes", param.size)
+		}
+		value.bytes = word[:param.size]
+	case abiBytes, abiString:
+		length, err := readLength(word)
+		if err != nil {
+			return value, err
+		}
+		if length > len(data)-32 {
+			return value, fmt.Errorf("length %d runs past the end of the data", length)
+		}
+		value.bytes = data[32 : 32+length]
+		value.text = string(value.bytes)
+	case abiSlice:
+		length, err := readLength(word)
+		if err != nil {
+			return value, err
+		}
+		if length*param.elem.headSize() > len(data)-32 {
+			return value, fmt.Errorf("array length %d runs past the end of the data", length)
+		}
+		elems, err := decodeParams(repeatParam(param.elem, length), data[32:])
+		value.elems = elems
+		return value, err
+	}
+	return value, nil
+}
+
+// readLength reads a word used as an offset or length
+func readLength(word []byte) (int, error) {
+	n := new(big.Int).SetBytes(word)
+	if !n.IsInt64() || n.Int64() > 1<<32 {
+		return 0, fmt.Errorf("offset or length %s is too large", n)
+	}
+	return int(n.Int64()), nil
+}
+
+func repeatParam(param *abiParam, n int) []*abiParam {
+	params := make([]*abiParam, n)
+	for i := range params {
+		params[i] = param
+	}
+	return params
+}
+
+func isZero(b []byte) bool {
+	for _, x := range b {
+		if x != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// canonicalABIValue renders a value unpacked by go-ethereum in the form of abiValue.canonical
+func canonicalABIValue(t abi.Type, v reflect.Value) (string, error) {
+	switch t.T {
+	case abi.UintTy, abi.IntTy:
+		switch n := v.Interface().(type) {
+		case *big.Int:
+			return n.String(), nil
+		}
+		if t.T == abi.UintTy {
+			return strconv.FormatUint(v.Uint(), 10), nil
+		}
+		return strconv.FormatInt(v.Int(), 10), nil
+	case abi.BoolTy:
+		return strconv.FormatBool(v.Bool()), nil
+	case abi.AddressTy:
+		return strings.ToLower(v.Interface().(common.Address).Hex()), nil
+	case abi.FixedBytesTy:
+		b := make([]byte, v.Len())
+		reflect.Copy(reflect.ValueOf(b), v)
+		return "0x" + hex.EncodeToString(b), nil
+	case abi.BytesTy:
+		return "0x" + hex.EncodeToString(v.Bytes()), nil
+	case abi.StringTy:
+		return strconv.Quote(v.String()), nil
+	case abi.SliceTy, abi.ArrayTy:
+		parts := make([]string, v.Len())
+		for i := range parts {
+			part, err := canonicalABIValue(*t.Elem, v.Index(i))
+			if err != nil {
+				return "", err
+			}
+			parts[i] = part
+		}
+		return "[" + strings.Join(parts, ",") + "]", nil
+	case abi.TupleTy:
+		parts := make([]string, len(t.TupleElems))
+		for i, elem := range t.TupleElems {
+			part, err := canonicalABIValue(*elem, v.Field(i))
+			if err != nil {
+				return "", err
+			}
+			parts[i] = part
+		}
+		return "(" + strings.Join(parts, ",") + ")", nil
+	}
+	return "", fmt.Errorf("unsupported type %s", t.String())
+}
@@ -0,0 +1,135 @@
+package core
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// aggregate3Call is an aggregate3 call on Multicall3 wrapping the given calls
+func aggregate3Call(t *testing.T, calls ...multiSendTransaction) multiSendTransaction {
+	t.Helper()
+	type call3 struct {
+		Target       common.Address
+		AllowFailure bool
+		CallData     []byte
+	}
+	var args []call3
+	for _, call := range calls {
+		args = append(args, call3{Target: call.To, CallData: call.Data})
+	}
+	data := common.FromHex(encodeKnownCall(t, Aggregate3Sig, args))
+	return multiSendTransaction{To: common.HexToAddress(Multicall3Address), Value: big.NewInt(0), Data: data}
+}
+
+func TestCrossCheckDecodingAgrees(t *testing.T) {
+	tx := multiSendTx(t,
+		erc20Transfer(USDCMainnetAddress, airdropAlice, big.NewInt(2_500_000)),
+		nativeTransfer(airdropBob, big.NewInt(1e18)),
+		aggregate3Call(t,
+			erc20Transfer(OPTokenAddress, airdropBob, big.NewInt(7)),
+			approveCall(OPTokenAddress, airdropAlice, big.NewInt(9)),
+		),
+	)
+	if mismatches := CrossCheckDecoding(tx.To, tx.Data, uint64(tx.Chain)); len(mismatches) != 0 {
+		t.Fatalf("unexpected mismatches: %v", mismatches)
+	}
+}
+
+func TestCrossCheckDecodingDirtyAddress(t *testing.T) {
+	// go-ethereum drops the upper 12 bytes of an address word; the independent decoder rejects them
+	transfer := erc20Transfer(USDCMainnetAddress, airdropAlice, big.NewInt(1))
+	transfer.Data[4] = 0xff
+	tx := multiSendTx(t, nativeTransfer(airdropBob, big.NewInt(1)), aggregate3Call(t, transfer))
+
+	mismatches := CrossCheckDecoding(tx.To, tx.Data, uint64(tx.Chain))
+	if len(mismatches) != 1 {
+		t.Fatalf("got %d mismatches, want 1: %v", len(mismatches), mismatches)
+	}
+	if mismatches[0].Path != "2.1" || mismatches[0].Function != "transfer(address,uint256)" {
+		t.Errorf("unexpected mismatch location: %+v", mismatches[0])
+	}
+	if !strings.Contains(mismatches[0].Message, "dirty high bytes") {
+		t.Errorf("unexpected mismatch message: %s", mismatches[0].Message)
+	}
+}
+
+func TestCrossCheckDecodingTrailingMultiSendBytes(t *testing.T) {
+	// The MultiSend decoder stops at an entry that does not fit, hiding whatever follows it
+	entries := encodeMultiSendEntry(0, common.HexToAddress(OPTokenAddress), big.NewInt(0), nil)
+	entries = append(entries, 0x00, 0x01, 0x02)
+	data := hexBytes(encodeMultiSendCall(t, entries))
+
+	mismatches := CrossCheckDecoding(SafeMultisendAddress, data, MainnetChainID)
+	if len(mismatches) != 1 || mismatches[0].Path != "" || !strings.Contains(mismatches[0].Message, "3 trailing bytes") {
+		t.Fatalf("unexpected mismatches: %v", mismatches)
+	}
+}
+
+func TestCrossCheckDecodingUnknownFunction(t *testing.T) {
+	if mismatches := CrossCheckDecoding(OPTokenAddress, "0xdeadbeef0000", MainnetChainID); len(mismatches) != 0 {
+		t.Fatalf("unexpected mismatches for unknown function: %v", mismatches)
+	}
+}
+
+func TestParseSignatureParams(t *testing.T) {
+	params, err := parseSignatureParams("f(uint8,int256[2],bytes4,(address,bool,uint256,bytes)[],string)")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(params) != 5 {
+		t.Fatalf("got %d params, want 5", len(params))
+	}
+	if params[0].kind != abiUint || params[0].size != 8 {
+		t.Errorf("param 0: got %+v, want uint8", params[0])
+	}
+	if params[1].kind != abiArray || params[1].size != 2 || params[1].headSize() != 64 || params[1].dynamic() {
+		t.Errorf("param 1: got %+v, want static int256[2]", params[1])
+	}
+	if params[2].kind != abiFixedBytes || params[2].size != 4 {
+		t.Errorf("param 2: got %+v, want bytes4", params[2])
+	}
+	if params[3].kind != abiSlice || params[3].elem.kind != abiTuple || len(params[3].elem.fields) != 4 || !params[3].elem.dynamic() {
+		t.Errorf("param 3: got %+v, want dynamic tuple slice", params[3])
+	}
+	if params[4].kind != abiString {
+		t.Errorf("param 4: got %+v, want string", params[4])
+	}
+
+	for _, signature := range []string{"f(uint7)", "f(bytes33)", "f((address)", "f(address[0])", "f(fixed128x18)"} {
+		if _, err := parseSignatureParams(signature); err == nil {
+			t.Errorf("expected an error parsing %s", signature)
+		}
+	}
+}
+
+func TestVerifyTransactionIndependentDecode(t *testing.T) {
+	transfer := erc20Transfer(USDCMainnetAddress, airdropAlice, big.NewInt(1))
+	transfer.Data[4] = 0xff
+	tx := multiSendTx(t, transfer)
+	tx.Safe = effectsSafe
+	tx.SafeVersion = "1.3.0"
+
+	if _, err := VerifyTransaction(tx, VerifyOptions{}); err != nil {
+		t.Fatalf("unexpected error without independent decoding: %v", err)
+	}
+	_, err := VerifyTransaction(tx, VerifyOptions{IndependentDecode: true})
+	if err == nil || !strings.Contains(err.Error(), "independent decoders disagree") {
+		t.Fatalf("expected independent decoders to disagree, got %v", err)
+	}
+}
+
+func TestParseSignatureParamsKnownFunctions(t *testing.T) {
+	for selector, info := range KnownFunctions {
+		params, err := parseSignatureParams(info.Signature)
+		if err != nil {
+			t.Errorf("%s (%s): %v", info.Signature, selector, err)
+			continue
+		}
+		if len(params) != len(info.ABI.Inputs) {
+			t.Errorf("%s: got %d params, want %d", info.Signature, len(params), len(info.ABI.Inputs))
+		}
+	}
+}
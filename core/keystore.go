@@ -0,0 +1,131 @@
+package core
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/ecdsa"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// standardScryptN is the scrypt cost geth uses for keystores it creates by default. Keyfiles made
+// with lighter parameters are much cheaper to brute force if they leak.
+const standardScryptN = 1 << 18
+
+// KeystoreFile is a Web3 Secret Storage (version 3) encrypted keyfile, as written by geth, cast,
+// and most wallets
+type KeystoreFile struct {
+	Address string `json:"address"`
+	Crypto  struct {
+		Cipher       string `json:"cipher"`
+		CipherText   string `json:"ciphertext"`
+		CipherParams struct {
+			IV string `json:"iv"`
+		} `json:"cipherparams"`
+		KDF       string                 `json:"kdf"`
+		KDFParams map[string]interface{} `json:"kdfparams"`
+		MAC       string                 `json:"mac"`
+	} `json:"crypto"`
+	Version int `json:"version"`
+
+	// raw is the keyfile as read, which DecryptKey parses itself
+	raw []byte
+}
+
+// KeystoreSigner is a Signer backed by a decrypted keyfile
+type KeystoreSigner struct {
+	key     *ecdsa.PrivateKey
+	address string
+}
+
+// ParseKeystore parses an encrypted keyfile without decrypting it
+func ParseKeystore(data []byte) (*KeystoreFile, error) {
+	var file KeystoreFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("invalid keystore: %w", err)
+	}
+	if file.Version != 3 {
+		return nil, fmt.Errorf("unsupported keystore version %d (only version 3 is supported)", file.Version)
+	}
+	if file.Crypto.Cipher != "aes-128-ctr" {
+		return nil, fmt.Errorf("unsupported keystore cipher %q", file.Crypto.Cipher)
+	}
+	if file.Crypto.KDF != "scrypt" && file.Crypto.KDF != "pbkdf2" {
+		return nil, fmt.Errorf("unsupported keystore KDF %q", file.Crypto.KDF)
+	}
+	// DecryptKey panics on an IV that is not one AES block and on a derived key shorter than the
+	// 32 bytes it slices, so both are checked here
+	if iv, err := hex.DecodeString(file.Crypto.CipherParams.IV); err != nil || len(iv) != aes.BlockSize {
+		return nil, fmt.Errorf("invalid keystore IV %q", file.Crypto.CipherParams.IV)
+	}
+	dkLen, err := kdfInt(file.Crypto.KDFParams, "dklen")
+	if err != nil {
+		return nil, err
+	}
+	if dkLen < 32 {
+		return nil, fmt.Errorf("keystore derived key length %d is too short", dkLen)
+	}
+	file.raw = data
+	return &file, nil
+}
+
+// Warnings explains anything that makes the keyfile weaker than a default geth keystore
+func (k *KeystoreFile) Warnings() []Warning {
+	if k.Crypto.KDF != "scrypt" {
+		return []Warning{newWarning(warnKeystoreKDF, "Keystore uses %s rather than scrypt; it is cheaper to brute force if the file leaks.", k.Crypto.KDF)}
+	}
+	if n, _ := kdfInt(k.Crypto.KDFParams, "n"); n < standardScryptN {
+		return []Warning{newWarning(warnKeystoreScrypt, "Keystore uses light scrypt parameters (n = %d, standard is %d); it is cheaper to brute force if the file leaks.", n, standardScryptN)}
+	}
+	return nil
+}
+
+// Decrypt decrypts the keyfile with go-ethereum's keystore and returns a signer for the key
+func (k *KeystoreFile) Decrypt(passphrase string) (*KeystoreSigner, error) {
+	key, err := keystore.DecryptKey(k.raw, passphrase)
+	if errors.Is(err, keystore.ErrDecrypt) {
+		return nil, errors.New("could not decrypt keystore: wrong passphrase")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not decrypt keystore: %w", err)
+	}
+	address := key.Address.Hex()
+	if k.Address != "" && !strings.EqualFold(strings.TrimPrefix(k.Address, "0x"), strings.TrimPrefix(address, "0x")) {
+		return nil, fmt.Errorf("keystore says address %s but the key is for %s", k.Address, address)
+	}
+	return &KeystoreSigner{key: key.PrivateKey, address: address}, nil
+}
+
+// kdfInt reads an integer KDF parameter
+func kdfInt(params map[string]interface{}, name string) (int, error) {
+	value, ok := params[name].(float64)
+	if !ok || value <= 0 || value != float64(int(value)) {
+		return 0, fmt.Errorf("invalid keystore KDF parameter %s: %v", name, params[name])
+	}
+	return int(value), nil
+}
+
+// Address returns the owner address of the key
+func (s *KeystoreSigner) Address() string {
+	return s.address
+}
+
+// SignSafeTxHash signs a Safe tx hash directly, as Safe expects for v = 27/28 signatures
+func (s *KeystoreSigner) SignSafeTxHash(_ context.Context, safeTxHash string) (string, error) {
+	hash, err := decodeSafeTxHash(safeTxHash)
+	if err != nil {
+		return "", err
+	}
+	sig, err := crypto.Sign(hash, s.key)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign: %w", err)
+	}
+	sig[64] += 27
+	return "0x" + hex.EncodeToString(sig), nil
+}
@@ -0,0 +1,109 @@
+package core
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+// Test vectors from the Web3 Secret Storage definition; both encrypt the same key with the
+// passphrase "testpassword"
+const (
+	keystorePBKDF2 = `{
+  "crypto": {
+    "cipher": "aes-128-ctr",
+    "cipherparams": {"iv": "6087dab2f9fdbbfaddc31a909735c1e6"},
+    "ciphertext": "5318b4d5bcd28de64ee5559e671353e16f075ecae9f99c7a79a38af5f869aa46",
+    "kdf": "pbkdf2",
+    "kdfparams": {"c": 262144, "dklen": 32, "prf": "hmac-sha256", "salt": "ae3cd4e7013836a3df6bd7241b12db061dbe2c6785853cce422d148a624ce0bd"},
+    "mac": "517ead924a9d0dc3124507e3393d175ce3ff7c1e96529c6c555ce9e51205e9b2"
+  },
+  "id": "3198bc9c-6672-5ab3-d995-4942343ae5b6",
+  "version": 3
+}`
+	keystoreScrypt = `{
+  "crypto": {
+    "cipher": "aes-128-ctr",
+    "cipherparams": {"iv": "83dbcc02d8ccb40e466191a123791e0e"},
+    "ciphertext": "d172bf743a674da9cdad04534d56926ef8358534d458fffccd4e6ad2fbde479c",
+    "kdf": "scrypt",
+    "kdfparams": {"dklen": 32, "n": 262144, "p": 8, "r": 1, "salt": "ab0c7876052600dd703518d6fc3fe8984592145b591fc8fb5c6d43190334ba19"},
+    "mac": "2103ac29920d71da29f15d75b4a16dbe95cfd7ff8faea1056c33131d846e3097"
+  },
+  "id": "3198bc9c-6672-5ab3-d995-4942343ae5b6",
+  "version": 3
+}`
+	keystoreVectorAddress = "0x008AeEda4D805471dF9b2A5B0f38A0C3bCBA786b"
+)
+
+func TestKeystoreDecryptVectors(t *testing.T) {
+	for name, data := range map[string]string{"pbkdf2": keystorePBKDF2, "scrypt": keystoreScrypt} {
+		t.Run(name, func(t *testing.T) {
+			keystore, err := ParseKeystore([]byte(data))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			signer, err := keystore.Decrypt("testpassword")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if signer.Address() != keystoreVectorAddress {
+				t.Errorf("address = %s, want %s", signer.Address(), keystoreVectorAddress)
+			}
+
+			if _, err := keystore.Decrypt("wrongpassword"); err == nil || !strings.Contains(err.Error(), "wrong passphrase") {
+				t.Errorf("expected a wrong passphrase error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestKeystoreWarnings(t *testing.T) {
+	pbkdf2, _ := ParseKeystore([]byte(keystorePBKDF2))
+	if len(pbkdf2.Warnings()) != 1 {
+		t.Errorf("expected a warning for pbkdf2, got %+v", pbkdf2.Warnings())
+	}
+	scrypt, _ := ParseKeystore([]byte(keystoreScrypt))
+	if len(scrypt.Warnings()) != 0 {
+		t.Errorf("expected no warnings for standard scrypt parameters, got %+v", scrypt.Warnings())
+	}
+	scrypt.Crypto.KDFParams["n"] = float64(4096)
+	if warnings := scrypt.Warnings(); len(warnings) != 1 || !strings.Contains(warnings[0].Message, "light scrypt") {
+		t.Errorf("expected a light parameters warning, got %+v", warnings)
+	}
+}
+
+func TestKeystoreSignerSignsSafeTxHash(t *testing.T) {
+	keystore, _ := ParseKeystore([]byte(keystorePBKDF2))
+	signer, err := keystore.Decrypt("testpassword")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	hash := "0x19767d264966e39d532d998c5354f76ad5102407124b1885d69bf23f791b6f4c"
+	signature, err := signer.SignSafeTxHash(context.Background(), hash)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	recovered, err := RecoverSigner(hash, signature)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if recovered != signer.Address() {
+		t.Errorf("signature recovers to %s, want %s", recovered, signer.Address())
+	}
+}
+
+func TestParseKeystoreRejectsUnsupported(t *testing.T) {
+	for _, data := range []string{
+		`{"version": 1}`,
+		strings.Replace(keystorePBKDF2, "aes-128-ctr", "aes-128-cbc", 1),
+		strings.Replace(keystorePBKDF2, `"kdf": "pbkdf2"`, `"kdf": "argon2"`, 1),
+		strings.Replace(keystorePBKDF2, "6087dab2f9fdbbfaddc31a909735c1e6", "6087dab2f9fdbbfaddc31a90", 1),
+		strings.Replace(keystorePBKDF2, `"dklen": 32`, `"dklen": 16`, 1),
+	} {
+		if _, err := ParseKeystore([]byte(data)); err == nil {
+			t.Errorf("expected an error for %s", data)
+		}
+	}
+}
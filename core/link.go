@@ -0,0 +1,202 @@
+package core
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// maxLinkPayload is the most a link payload may decompress to, far more than any transaction
+const maxLinkPayload = 16 << 20
+
+// ShareLink returns a link to site that carries a transaction in its tx parameter, in the binary
+// encoding of EncodeTransaction compressed by EncodeLinkPayload, followed by a sum parameter
+// holding the keccak256 hash of the encoding. Only the hashed fields are carried; decoded and
+// service metadata that the receiving side recomputes or ignores is left out to keep the link,
+// and the QR codes made from it, small. The sum lets DecodeTransactionLink detect a payload that
+// was truncated or altered on its way, such as by a chat client or a bad copy and paste.
+func ShareLink(tx SafeTransaction, site string) (string, error) {
+	data, err := EncodeTransaction(tx)
+	if err != nil {
+		return "", err
+	}
+	payload, err := EncodeLinkPayload(data)
+	if err != nil {
+		return "", err
+	}
+	return site + "?tx=" + payload + "&sum=" + crypto.Keccak256Hash(data).Hex(), nil
+}
+
+// EncodeLinkPayload compresses a payload with zlib, as the QR pages compress the payload of
+// their QR codes, and encodes it as unpadded URL-safe base64, which a link carries unescaped.
+// Large batches, whose JSON repeats the same addresses and selectors, shrink several times.
+func EncodeLinkPayload(data []byte) (string, error) {
+	var compressed bytes.Buffer
+	writer, err := zlib.NewWriterLevel(&compressed, zlib.BestCompression)
+	if err != nil {
+		return "", err
+	}
+	if _, err := writer.Write(data); err != nil {
+		return "", fmt.Errorf("failed to compress payload: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return "", fmt.Errorf("failed to compress payload: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(compressed.Bytes()), nil
+}
+
+// DecodeLinkPayload decodes a link payload made by EncodeLinkPayload. The compression is
+// detected from the payload's header: zlib and gzip are decompressed, and anything else, such
+// as the uncompressed base64 JSON of older links, is returned as it is.
+func DecodeLinkPayload(payload string) ([]byte, error) {
+	data, err := decodeBase64Payload(payload)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base64 tx parameter: %w", err)
+	}
+
+	var reader io.ReadCloser
+	switch {
+	case len(data) >= 2 && data[0] == 0x1f && data[1] == 0x8b:
+		reader, err = gzip.NewReader(bytes.NewReader(data))
+	case len(data) >= 2 && data[0]&0x0f == 8 && (uint16(data[0])<<8|uint16(data[1]))%31 == 0:
+		reader, err = zlib.NewReader(bytes.NewReader(data))
+	default:
+		return data, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid compressed tx parameter: %w", err)
+	}
+	defer reader.Close()
+	decompressed, err := io.ReadAll(io.LimitReader(reader, maxLinkPayload+1))
+	if err != nil {
+		return nil, fmt.Errorf("invalid compressed tx parameter: %w", err)
+	}
+	if len(decompressed) > maxLinkPayload {
+		return nil, fmt.Errorf("the tx parameter decompresses to more than %d bytes", maxLinkPayload)
+	}
+	return decompressed, nil
+}
+
+// IsQueueItemLink reports whether a link points at an item of the Safe UI transaction list,
+// which must be fetched from the Safe service, rather than carrying the transaction itself
+func IsQueueItemLink(link string) bool {
+	parsed, err := url.Parse(strings.TrimSpace(link))
+	if err != nil {
+		return false
+	}
+	query := parsed.Query()
+	return query.Get("tx") == "" && query.Get("id") != ""
+}
+
+// DecodeTransactionLink decodes the transaction a link carries: an op-txverify link with the
+// transaction in its tx parameter, in the binary encoding or as JSON, compressed or not, or that
+// payload on its own. When the link has a sum parameter, as links made by ShareLink do, the
+// payload must match it; a link without one is decoded with a warning, since nothing shows that
+// it arrived intact. A chain prefix on the link's safe parameter, as Safe UI style links carry,
+// must agree with the transaction.
+func DecodeTransactionLink(link string) (*SafeTransaction, []Warning, error) {
+	link = strings.TrimSpace(link)
+	payload := link
+	var safeParam, sum string
+	if strings.Contains(link, "://") || strings.Contains(link, "?") {
+		parsed, err := url.Parse(link)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid url: %w", err)
+		}
+		payload = parsed.Query().Get("tx")
+		if payload == "" {
+			return nil, nil, fmt.Errorf("tx parameter not found in url")
+		}
+		safeParam = parsed.Query().Get("safe")
+		sum = parsed.Query().Get("sum")
+	}
+
+	decoded, err := DecodeLinkPayload(payload)
+	if err != nil {
+		return nil, nil, withKind(ErrDecodeFailure, err)
+	}
+	var warnings []Warning
+	if sum == "" {
+		warnings = append(warnings, newWarning(warnUncheckedLink,
+			"The link has no sum parameter, so a transaction truncated or altered in transit would not be detected; compare the Safe tx hash with the sender's before signing."))
+	} else if !strings.EqualFold(sum, crypto.Keccak256Hash(decoded).Hex()) {
+		return nil, nil, fmt.Errorf("the link's transaction does not match its sum parameter: it was truncated or altered after it was shared, so get the link again")
+	}
+	tx, err := DecodeTransactionPayload(decoded)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse transaction from url: %w", err)
+	}
+
+	sources := []ChainSource{{Source: "transaction chain field", ChainID: uint64(tx.Chain)}}
+	source, err := PrefixSource("url safe param", safeParam)
+	if err != nil {
+		return nil, nil, err
+	}
+	if _, err := ResolveChainID(append(sources, source)...); err != nil {
+		return nil, nil, err
+	}
+	return tx, warnings, nil
+}
+
+// decodeBase64Payload decodes standard or URL-safe base64, with or without padding. A "+" that
+// became a space because the link was pasted without escaping is restored.
+func decodeBase64Payload(payload string) ([]byte, error) {
+	payload = strings.ReplaceAll(payload, " ", "+")
+	decoded, err := base64.StdEncoding.DecodeString(payload)
+	if err == nil {
+		return decoded, nil
+	}
+	for _, encoding := range []*base64.Encoding{base64.RawStdEncoding, base64.URLEncoding, base64.RawURLEncoding} {
+		if decoded, err := encoding.DecodeString(payload); err == nil {
+			return decoded, nil
+		}
+	}
+	return nil, err
+}
+
+// ShortenLink asks a self-hosted link shortener for a short link that redirects to link. The
+// service is sent {"url": "<link>"} in a POST request and must answer with {"url": "<short link>"}.
+func ShortenLink(ctx context.Context, service, link string) (string, error) {
+	if !strings.HasPrefix(service, "https://") {
+		return "", fmt.Errorf("link shortener URL must use https: %s", service)
+	}
+	body, err := json.Marshal(map[string]string{"url": link})
+	if err != nil {
+		return "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, service, bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("error creating request for %s: %w", service, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("error shortening link with %s: %w", service, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		return "", fmt.Errorf("link shortener request to %s failed with status: %s", service, resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<16))
+	if err != nil {
+		return "", fmt.Errorf("error reading link shortener response: %w", err)
+	}
+	var shortened struct {
+		URL string `json:"url"`
+	}
+	if err := json.Unmarshal(data, &shortened); err != nil || shortened.URL == "" {
+		return "", fmt.Errorf("link shortener %s did not return a url", service)
+	}
+	return shortened.URL, nil
+}
@@ -0,0 +1,199 @@
+package core
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestDecodeTransactionLink(t *testing.T) {
+	tx := SafeTransaction{
+		Safe:  fixtureGrantsSafe,
+		Chain: OPMainnetChainID,
+		To:    OPTokenAddress,
+		Value: big.NewInt(0),
+		Data:  "0x",
+		Nonce: 7,
+	}
+	data, err := json.Marshal(tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload := base64.StdEncoding.EncodeToString(data)
+
+	for name, link := range map[string]string{
+		"share link":       "https://op-txverify.optimism.io/?tx=" + url.QueryEscape(payload),
+		"unescaped link":   "https://op-txverify.optimism.io/?tx=" + payload,
+		"safe param":       "https://op-txverify.optimism.io/?safe=oeth:" + fixtureGrantsSafe + "&tx=" + url.QueryEscape(payload),
+		"raw base64":       "  " + payload + "\n",
+		"url-safe base64":  base64.RawURLEncoding.EncodeToString(data),
+		"unpadded base64":  base64.RawStdEncoding.EncodeToString(data),
+		"relative tx link": "?tx=" + url.QueryEscape(payload),
+	} {
+		t.Run(name, func(t *testing.T) {
+			decoded, warnings, err := DecodeTransactionLink(link)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if decoded.Safe != tx.Safe || decoded.Nonce != tx.Nonce || decoded.Chain != tx.Chain {
+				t.Errorf("decoded %+v, want %+v", decoded, tx)
+			}
+			if len(warnings) != 1 || warnings[0].Code != warnUncheckedLink.Code {
+				t.Errorf("expected a link without a sum to be warned about, got %+v", warnings)
+			}
+		})
+	}
+
+	for name, link := range map[string]string{
+		"no tx param":     "https://op-txverify.optimism.io/?foo=bar",
+		"not base64":      "https://op-txverify.optimism.io/?tx=%%%",
+		"not json":        base64.StdEncoding.EncodeToString([]byte("hello")),
+		"chain mismatch":  "https://op-txverify.optimism.io/?safe=eth:" + fixtureGrantsSafe + "&tx=" + url.QueryEscape(payload),
+		"unknown prefix":  "https://op-txverify.optimism.io/?safe=arb1:" + fixtureGrantsSafe + "&tx=" + url.QueryEscape(payload),
+		"safe ui item id": "https://app.safe.global/transactions/tx?safe=oeth:" + fixtureGrantsSafe + "&id=multisig_" + fixtureGrantsSafe + "_" + fixtureGrantsHash,
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, _, err := DecodeTransactionLink(link); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestIsQueueItemLink(t *testing.T) {
+	item := "https://app.safe.global/transactions/tx?safe=oeth:" + fixtureGrantsSafe + "&id=multisig_" + fixtureGrantsSafe + "_" + fixtureGrantsHash
+	if !IsQueueItemLink(item) {
+		t.Errorf("expected a Safe UI link to be a queue item link")
+	}
+	for _, link := range []string{"https://op-txverify.optimism.io/?tx=e30%3D", "e30=", strings.Repeat("A", 8)} {
+		if IsQueueItemLink(link) {
+			t.Errorf("%q should not be a queue item link", link)
+		}
+	}
+}
+
+func TestShareLink(t *testing.T) {
+	tx := SafeTransaction{
+		Safe:       fixtureGrantsSafe,
+		Chain:      OPMainnetChainID,
+		To:         OPTokenAddress,
+		Value:      big.NewInt(0),
+		Data:       "0xa9059cbb",
+		Nonce:      7,
+		Provenance: map[string]string{"nonce": "service"},
+	}
+	link, err := ShareLink(tx, "https://example.com/")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(link, "https://example.com/?tx=") || !strings.Contains(link, "&sum=0x") {
+		t.Fatalf("unexpected link: %s", link)
+	}
+
+	decoded, warnings, err := DecodeTransactionLink(link)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if decoded.Data != tx.Data || decoded.Nonce != tx.Nonce || decoded.Provenance != nil {
+		t.Errorf("expected the transaction without its metadata, got %+v", decoded)
+	}
+	if len(warnings) != 0 {
+		t.Errorf("expected no warnings for a link with a matching sum, got %+v", warnings)
+	}
+
+	// A payload cut short or changed on the way no longer matches its sum
+	parsed, _ := url.Parse(link)
+	query := parsed.Query()
+	decoded.Nonce = 8
+	data, err := EncodeTransaction(*decoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	altered, err := EncodeLinkPayload(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	query.Set("tx", altered)
+	parsed.RawQuery = query.Encode()
+	if _, _, err := DecodeTransactionLink(parsed.String()); err == nil || !strings.Contains(err.Error(), "sum") {
+		t.Errorf("expected the altered payload to be refused, got %v", err)
+	}
+}
+
+func TestLinkPayloadCompression(t *testing.T) {
+	// A batch repeats the same addresses and selectors, which compresses well
+	data := []byte(`{"data":"` + strings.Repeat("a9059cbb000000000000000000000000420000000000000000000000000000000000004200", 50) + `"}`)
+	payload, err := EncodeLinkPayload(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(payload) >= base64.StdEncoding.EncodedLen(len(data))/2 {
+		t.Errorf("payload of %d characters is not compressed, the JSON encodes to %d", len(payload), base64.StdEncoding.EncodedLen(len(data)))
+	}
+	if strings.ContainsAny(payload, "+/=") {
+		t.Errorf("payload %q needs escaping in a link", payload)
+	}
+
+	var gzipped bytes.Buffer
+	writer := gzip.NewWriter(&gzipped)
+	writer.Write(data)
+	writer.Close()
+
+	for name, encoded := range map[string]string{
+		"zlib":         payload,
+		"gzip":         base64.StdEncoding.EncodeToString(gzipped.Bytes()),
+		"uncompressed": base64.StdEncoding.EncodeToString(data),
+	} {
+		t.Run(name, func(t *testing.T) {
+			decoded, err := DecodeLinkPayload(encoded)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !bytes.Equal(decoded, data) {
+				t.Errorf("decoded %q", decoded)
+			}
+		})
+	}
+
+	// A payload cut short fails to decompress instead of decoding to part of the transaction
+	if _, err := DecodeLinkPayload(payload[:len(payload)/2]); err == nil {
+		t.Error("expected a truncated payload to be refused")
+	}
+}
+
+func TestShortenLink(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			URL string `json:"url"`
+		}
+		if r.Method != http.MethodPost || json.NewDecoder(r.Body).Decode(&request) != nil || request.URL == "" {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		w.Write([]byte(`{"url": "https://s.example.com/abc"}`))
+	}))
+	defer server.Close()
+	defaultClient := http.DefaultClient
+	http.DefaultClient = server.Client()
+	defer func() { http.DefaultClient = defaultClient }()
+
+	short, err := ShortenLink(context.Background(), server.URL, "https://example.com/?tx=e30%3D")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if short != "https://s.example.com/abc" {
+		t.Errorf("short link = %s", short)
+	}
+
+	if _, err := ShortenLink(context.Background(), "http://s.example.com", "https://example.com/"); err == nil {
+		t.Error("expected a shortener without https to be refused")
+	}
+}
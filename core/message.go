@@ -0,0 +1,205 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Kinds of off-chain message a Safe can sign
+const (
+	MessageTypeText    = "text"
+	MessageTypeTypedV4 = "eip712"
+)
+
+// APISafeMessage is an off-chain SafeMessage as returned by the Safe service. Message is either
+// a JSON string (signed as an EIP-191 personal message) or an EIP-712 typed data object.
+type APISafeMessage struct {
+	Created       string          `json:"created"`
+	Safe          string          `json:"safe"`
+	MessageHash   string          `json:"messageHash"`
+	Message       json.RawMessage `json:"message"`
+	ProposedBy    string          `json:"proposedBy"`
+	SafeAppID     *int            `json:"safeAppId"`
+	Confirmations []interface{}   `json:"confirmations"`
+	Origin        string          `json:"origin"`
+}
+
+// SafeMessagesResponse represents a page of Safe messages from the Safe service
+type SafeMessagesResponse struct {
+	Count   int              `json:"count"`
+	Next    string           `json:"next,omitempty"`
+	Results []APISafeMessage `json:"results"`
+}
+
+// MessageResult is a Safe message together with its locally computed hashes
+type MessageResult struct {
+	Safe          string      `json:"safe"`
+	SafeVersion   string      `json:"safe_version"`
+	Chain         int         `json:"chain"`
+	Created       string      `json:"created"`
+	ProposedBy    string      `json:"proposedBy"`
+	Confirmations int         `json:"confirmations"`
+	Threshold     int         `json:"threshold"`
+	MessageType   string      `json:"messageType"`
+	Message       interface{} `json:"message"`
+	DomainHash    string      `json:"domainHash"`
+	DataHash      string      `json:"dataHash"`
+	MessageHash   string      `json:"messageHash"`
+	Warnings      []Warning   `json:"warnings,omitempty"`
+
+	// ServiceMessageHash is the message hash the Safe service reported; it is cross-checked
+	// against the locally computed MessageHash
+	ServiceMessageHash string `json:"service_message_hash,omitempty"`
+}
+
+// Pending reports whether the message has fewer confirmations than the Safe's threshold, so
+// owners still need to sign it
+func (r MessageResult) Pending() bool {
+	return r.Confirmations < r.Threshold
+}
+
+// MessageFilter selects which of a Safe's messages are fetched, in the order the Safe service
+// lists them, newest first
+type MessageFilter struct {
+	// Pending keeps only the messages that still need confirmations
+	Pending bool
+
+	// Offset skips that many of the selected messages, and Limit, when positive, keeps at most
+	// that many of the rest
+	Offset int
+	Limit  int
+}
+
+// CalculateMessageDataHash hashes the raw message the way the signer's wallet would: an
+// EIP-191 personal message hash for strings, and an EIP-712 hash for typed data
+func CalculateMessageDataHash(message json.RawMessage) (common.Hash, string, interface{}, error) {
+	var text string
+	if err := json.Unmarshal(message, &text); err == nil {
+		return crypto.Keccak256Hash([]byte(fmt.Sprintf("\x19Ethereum Signed Message:\n%d%s", len(text), text))), MessageTypeText, text, nil
+	}
+
+	typedData, err := DecodeTypedData(message)
+	if err != nil {
+		return common.Hash{}, "", nil, fmt.Errorf("message is neither a string nor EIP-712 typed data: %w", err)
+	}
+	hash, err := HashTypedData(typedData)
+	if err != nil {
+		return common.Hash{}, "", nil, fmt.Errorf("failed to hash EIP-712 typed data: %w", err)
+	}
+	return hash, MessageTypeTypedV4, typedData, nil
+}
+
+// CalculateSafeMessageHash calculates the EIP-712 SafeMessage hash that Safe owners sign for an
+// off-chain message, as computed by the Safe fallback handler's getMessageHashForSafe
+func CalculateSafeMessageHash(safe string, chainID uint64, safeVersion string, dataHash common.Hash) (string, string, error) {
+	domainHash, err := CalculateDomainHash(SafeTransaction{Safe: safe, Chain: int(chainID), SafeVersion: safeVersion})
+	if err != nil {
+		return "", "", err
+	}
+
+	// The signed message is abi.encode(dataHash), so its hash is keccak256(dataHash)
+	structHash := crypto.Keccak256(
+		common.HexToHash(SafeMessageTypehash).Bytes(),
+		crypto.Keccak256(dataHash.Bytes()),
+	)
+
+	hash := crypto.Keccak256Hash(
+		[]byte{0x19, 0x01},
+		common.HexToHash(domainHash).Bytes(),
+		structHash,
+	)
+	return domainHash, hash.Hex(), nil
+}
+
+// FetchMessages fetches and hashes the off-chain messages of a Safe that the filter selects
+func FetchMessages(ctx context.Context, network string, safeAddress string, filter MessageFilter) ([]MessageResult, error) {
+	apiURL, chainID, err := getNetworkInfo(network)
+	if err != nil {
+		return nil, err
+	}
+
+	sources := []ChainSource{{Source: fmt.Sprintf("network %q", network), ChainID: chainID}}
+	source, err := PrefixSource("safe", safeAddress)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := ResolveChainID(append(sources, source)...); err != nil {
+		return nil, err
+	}
+
+	return FetchMessagesWithClient(ctx, serviceClient(chainID, apiURL), chainID, StripChainPrefix(safeAddress), filter)
+}
+
+// FetchMessagesWithClient fetches and hashes the off-chain messages of a Safe that the filter
+// selects, using the given client
+func FetchMessagesWithClient(ctx context.Context, client SafeClient, chainID uint64, safeAddress string, filter MessageFilter) ([]MessageResult, error) {
+	if filter.Offset < 0 || filter.Limit < 0 {
+		return nil, fmt.Errorf("invalid offset %d or limit %d", filter.Offset, filter.Limit)
+	}
+	safeAddress = common.HexToAddress(safeAddress).Hex()
+
+	safeInfo, err := client.GetSafeInfo(ctx, safeAddress)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching safe version: %w", err)
+	}
+
+	resp, err := client.GetSafeMessages(ctx, safeAddress)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching messages: %w", err)
+	}
+
+	results := []MessageResult{}
+	skipped := 0
+	for _, msg := range resp.Results {
+		if filter.Limit > 0 && len(results) == filter.Limit {
+			break
+		}
+		if filter.Pending && len(msg.Confirmations) >= safeInfo.Threshold {
+			continue
+		}
+		if skipped < filter.Offset {
+			skipped++
+			continue
+		}
+		result, err := VerifyMessage(safeAddress, chainID, safeInfo.Version, msg)
+		if err != nil {
+			return nil, fmt.Errorf("message %s: %w", msg.MessageHash, err)
+		}
+		result.Threshold = safeInfo.Threshold
+		results = append(results, *result)
+	}
+	return results, nil
+}
+
+// VerifyMessage computes the hashes for a Safe message and cross-checks them against the service
+func VerifyMessage(safe string, chainID uint64, safeVersion string, msg APISafeMessage) (*MessageResult, error) {
+	dataHash, messageType, message, err := CalculateMessageDataHash(msg.Message)
+	if err != nil {
+		return nil, err
+	}
+
+	domainHash, messageHash, err := CalculateSafeMessageHash(safe, chainID, safeVersion, dataHash)
+	if err != nil {
+		return nil, err
+	}
+
+	return &MessageResult{
+		Safe:               safe,
+		SafeVersion:        safeVersion,
+		Chain:              int(chainID),
+		Created:            msg.Created,
+		ProposedBy:         msg.ProposedBy,
+		Confirmations:      len(msg.Confirmations),
+		MessageType:        messageType,
+		Message:            message,
+		DomainHash:         domainHash,
+		DataHash:           dataHash.Hex(),
+		MessageHash:        messageHash,
+		ServiceMessageHash: msg.MessageHash,
+		Warnings:           checkServiceHash("message", messageHash, msg.MessageHash),
+	}, nil
+}
@@ -0,0 +1,151 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+)
+
+func TestCalculateMessageDataHashText(t *testing.T) {
+	hash, kind, message, err := CalculateMessageDataHash(json.RawMessage(`"hello"`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if kind != MessageTypeText || message != "hello" {
+		t.Errorf("unexpected kind/message: %s %v", kind, message)
+	}
+	// keccak256("\x19Ethereum Signed Message:\n5hello")
+	if want := "0x50b2c43fd39106bafbba0da34fc430e1f91e3c96ea2acee2bc34119f92b37750"; hash.Hex() != want {
+		t.Errorf("hash = %s, want %s", hash.Hex(), want)
+	}
+}
+
+func TestCalculateMessageDataHashTypedData(t *testing.T) {
+	hash, kind, _, err := CalculateMessageDataHash(json.RawMessage(mailTypedData))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if kind != MessageTypeTypedV4 {
+		t.Errorf("kind = %s, want %s", kind, MessageTypeTypedV4)
+	}
+	if want := "0xbe609aee343fb3c4b28e1df9e632fca64fcfaede20f02e86244efddf30957bd2"; hash.Hex() != want {
+		t.Errorf("hash = %s, want %s", hash.Hex(), want)
+	}
+
+	if _, _, _, err := CalculateMessageDataHash(json.RawMessage(`42`)); err == nil {
+		t.Fatalf("expected error for non-message payload")
+	}
+}
+
+// TestCalculateSafeMessageHashMatchesTypedData computes the SafeMessage hash a second way, as a
+// generic EIP-712 payload, to make sure the hand-rolled encoding matches the standard
+func TestCalculateSafeMessageHashMatchesTypedData(t *testing.T) {
+	dataHash := common.HexToHash("0xbe609aee343fb3c4b28e1df9e632fca64fcfaede20f02e86244efddf30957bd2")
+
+	_, got, err := CalculateSafeMessageHash(fixtureGrantsSafe, OPMainnetChainID, "1.3.0+L2", dataHash)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	td := apitypes.TypedData{
+		Types: apitypes.Types{
+			"EIP712Domain": {{Name: "chainId", Type: "uint256"}, {Name: "verifyingContract", Type: "address"}},
+			"SafeMessage":  {{Name: "message", Type: "bytes"}},
+		},
+		PrimaryType: "SafeMessage",
+		Domain:      apitypes.TypedDataDomain{ChainId: math.NewHexOrDecimal256(10), VerifyingContract: fixtureGrantsSafe},
+		Message:     apitypes.TypedDataMessage{"message": dataHash.Hex()},
+	}
+	want, err := HashTypedData(td)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got != want.Hex() {
+		t.Errorf("safe message hash = %s, want %s", got, want.Hex())
+	}
+}
+
+func TestFetchMessagesWithClient(t *testing.T) {
+	server := newFixtureServer(t, defaultFixtureRoutes())
+	client := NewHTTPSafeClient(server.URL)
+
+	results, err := FetchMessagesWithClient(context.Background(), client, OPMainnetChainID, fixtureGrantsSafe, MessageFilter{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("expected the 3 messages of both pages, got %d", len(results))
+	}
+
+	if results[0].MessageType != MessageTypeText || results[1].MessageType != MessageTypeTypedV4 {
+		t.Errorf("unexpected message types: %s %s", results[0].MessageType, results[1].MessageType)
+	}
+	for _, result := range results {
+		if HasCritical(result.Warnings) {
+			t.Errorf("message %s: unexpected warnings %+v", result.MessageHash, result.Warnings)
+		}
+	}
+	if results[0].Confirmations != 1 || results[0].Threshold != 2 || !results[0].Pending() {
+		t.Errorf("confirmations = %d of %d, want a pending 1 of 2", results[0].Confirmations, results[0].Threshold)
+	}
+	if results[2].Pending() {
+		t.Errorf("expected the message with 2 of 2 confirmations not to be pending")
+	}
+}
+
+func TestFetchMessagesWithClientFilter(t *testing.T) {
+	server := newFixtureServer(t, defaultFixtureRoutes())
+	client := NewHTTPSafeClient(server.URL)
+
+	tests := []struct {
+		name   string
+		filter MessageFilter
+		want   []string
+	}{
+		{"pending", MessageFilter{Pending: true}, []string{"0xbeef", "0x6eb7"}},
+		{"limit", MessageFilter{Limit: 1}, []string{"0xbeef"}},
+		{"offset", MessageFilter{Offset: 1, Limit: 1}, []string{"0x6eb7"}},
+		{"pending offset", MessageFilter{Pending: true, Offset: 2}, nil},
+		{"offset past the end", MessageFilter{Offset: 5}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := FetchMessagesWithClient(context.Background(), client, OPMainnetChainID, fixtureGrantsSafe, tt.filter)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var got []string
+			for _, result := range results {
+				got = append(got, result.MessageHash[:6])
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("messages = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := FetchMessagesWithClient(context.Background(), client, OPMainnetChainID, fixtureGrantsSafe, MessageFilter{Limit: -1}); err == nil {
+		t.Errorf("expected an error for a negative limit")
+	}
+}
+
+func TestVerifyMessageServiceHashMismatch(t *testing.T) {
+	msg := APISafeMessage{
+		Message:     json.RawMessage(`"hello"`),
+		MessageHash: "0x" + strings.Repeat("ab", 32),
+	}
+
+	result, err := VerifyMessage(fixtureGrantsSafe, OPMainnetChainID, "1.3.0", msg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !HasCritical(result.Warnings) {
+		t.Fatalf("expected critical warning for mismatched service hash")
+	}
+}
@@ -0,0 +1,138 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// ModuleTransaction is a transaction executed on a Safe by one of its enabled modules, as
+// returned by the Safe service. Module transactions bypass owner signatures entirely.
+type ModuleTransaction struct {
+	Created             string      `json:"created"`
+	ExecutionDate       string      `json:"executionDate"`
+	BlockNumber         int         `json:"blockNumber"`
+	IsSuccessful        bool        `json:"isSuccessful"`
+	TransactionHash     string      `json:"transactionHash"`
+	Safe                string      `json:"safe"`
+	Module              string      `json:"module"`
+	To                  string      `json:"to"`
+	Value               APIValue    `json:"value"`
+	Data                APIValue    `json:"data"`
+	Operation           int         `json:"operation"`
+	DataDecoded         interface{} `json:"dataDecoded"`
+	ModuleTransactionID string      `json:"moduleTransactionId"`
+}
+
+// ModuleTransactionsResponse represents a page of module transactions from the Safe service
+type ModuleTransactionsResponse struct {
+	Count   int                 `json:"count"`
+	Results []ModuleTransaction `json:"results"`
+}
+
+// ModuleTransactionResult is a module transaction together with its locally decoded call
+type ModuleTransactionResult struct {
+	Safe            string   `json:"safe"`
+	Chain           int      `json:"chain"`
+	Module          string   `json:"module"`
+	To              string   `json:"to"`
+	Value           *big.Int `json:"value"`
+	Data            string   `json:"data"`
+	Operation       int      `json:"operation"`
+	IsSuccessful    bool     `json:"isSuccessful"`
+	ExecutionDate   string   `json:"executionDate"`
+	BlockNumber     int      `json:"blockNumber"`
+	TransactionHash string   `json:"transactionHash"`
+	ID              string   `json:"moduleTransactionId"`
+	Call            CallData `json:"call"`
+}
+
+// FetchModuleTransactions fetches and decodes the most recent module transactions for a Safe
+func FetchModuleTransactions(ctx context.Context, network string, safeAddress string, limit int, options VerifyOptions) ([]ModuleTransactionResult, error) {
+	apiURL, chainID, err := getNetworkInfo(network)
+	if err != nil {
+		return nil, err
+	}
+
+	sources := []ChainSource{{Source: fmt.Sprintf("network %q", network), ChainID: chainID}}
+	source, err := PrefixSource("safe", safeAddress)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := ResolveChainID(append(sources, source)...); err != nil {
+		return nil, err
+	}
+
+	return FetchModuleTransactionsWithClient(ctx, serviceClient(chainID, apiURL), chainID, StripChainPrefix(safeAddress), limit, options)
+}
+
+// FetchModuleTransactionsWithClient fetches and decodes module transactions using the given client
+func FetchModuleTransactionsWithClient(ctx context.Context, client SafeClient, chainID uint64, safeAddress string, limit int, options VerifyOptions) ([]ModuleTransactionResult, error) {
+	safeAddress = common.HexToAddress(safeAddress).Hex()
+
+	resp, err := client.GetModuleTransactions(ctx, safeAddress, limit)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching module transactions: %w", err)
+	}
+
+	results := make([]ModuleTransactionResult, 0, len(resp.Results))
+	for _, tx := range resp.Results {
+		result, err := DecodeModuleTransaction(chainID, tx, options)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, *result)
+	}
+	return results, nil
+}
+
+// FetchModuleTransactionWithClient fetches and decodes a single module transaction by its service ID
+func FetchModuleTransactionWithClient(ctx context.Context, client SafeClient, chainID uint64, id string, options VerifyOptions) (*ModuleTransactionResult, error) {
+	tx, err := client.GetModuleTransaction(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching module transaction %s: %w", id, err)
+	}
+	return DecodeModuleTransaction(chainID, *tx, options)
+}
+
+// DecodeModuleTransaction decodes the call made by a module transaction
+func DecodeModuleTransaction(chainID uint64, tx ModuleTransaction, options VerifyOptions) (*ModuleTransactionResult, error) {
+	data := "0x"
+	if tx.Data.Present && !tx.Data.Null {
+		data = tx.Data.Raw
+	}
+
+	value := new(big.Int)
+	if tx.Value.Present && !tx.Value.Null {
+		if _, ok := value.SetString(tx.Value.Raw, 10); !ok {
+			return nil, fmt.Errorf("invalid value %q in module transaction %s", tx.Value.Raw, tx.ModuleTransactionID)
+		}
+	}
+
+	call, err := ParseTransactionData(tx.To, data, chainID, options)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse module transaction %s: %w", tx.ModuleTransactionID, err)
+	}
+	call.IsDelegateCall = tx.Operation == 1
+	PredictDeployments(call, tx.Safe, call.IsDelegateCall)
+	PredictDeposits(call, tx.Safe, value, call.IsDelegateCall)
+	LabelAddresses(call, chainID, options.AddressBook)
+
+	return &ModuleTransactionResult{
+		Safe:            tx.Safe,
+		Chain:           int(chainID),
+		Module:          tx.Module,
+		To:              tx.To,
+		Value:           value,
+		Data:            data,
+		Operation:       tx.Operation,
+		IsSuccessful:    tx.IsSuccessful,
+		ExecutionDate:   tx.ExecutionDate,
+		BlockNumber:     tx.BlockNumber,
+		TransactionHash: tx.TransactionHash,
+		ID:              tx.ModuleTransactionID,
+		Call:            *call,
+	}, nil
+}
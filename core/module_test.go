@@ -0,0 +1,64 @@
+package core
+
+import (
+	"context"
+	"testing"
+)
+
+func TestFetchModuleTransactionsWithClient(t *testing.T) {
+	server := newFixtureServer(t, defaultFixtureRoutes())
+	client := NewHTTPSafeClient(server.URL)
+
+	results, err := FetchModuleTransactionsWithClient(context.Background(), client, OPMainnetChainID, fixtureGrantsSafe, 10, VerifyOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("expected 1 module transaction, got %d", len(results))
+	}
+
+	result := results[0]
+	if result.Module != "0xCFbFaC74C26F8647cBDb8c5caf80BB5b32E43134" || !result.IsSuccessful {
+		t.Errorf("unexpected module transaction: %+v", result)
+	}
+	if result.Call.FunctionName != "transfer" {
+		t.Errorf("expected decoded transfer call, got %q", result.Call.FunctionName)
+	}
+}
+
+func TestFetchModuleTransactionWithClient(t *testing.T) {
+	server := newFixtureServer(t, defaultFixtureRoutes())
+	client := NewHTTPSafeClient(server.URL)
+
+	result, err := FetchModuleTransactionWithClient(context.Background(), client, OPMainnetChainID, fixtureModuleTxID, VerifyOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.ID != fixtureModuleTxID || result.Chain != OPMainnetChainID {
+		t.Errorf("unexpected result: %+v", result)
+	}
+}
+
+func TestDecodeModuleTransactionDefaults(t *testing.T) {
+	tx := ModuleTransaction{
+		To:        fixtureGrantsSafe,
+		Data:      APIValue{Present: true, Null: true},
+		Operation: 1,
+	}
+
+	result, err := DecodeModuleTransaction(OPMainnetChainID, tx, VerifyOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Data != "0x" || result.Value.Sign() != 0 {
+		t.Errorf("expected empty data and zero value, got %s %s", result.Data, result.Value)
+	}
+	if !result.Call.IsDelegateCall {
+		t.Errorf("expected delegatecall to be flagged")
+	}
+
+	tx.Value = APIValue{Raw: "not-a-number", Present: true}
+	if _, err := DecodeModuleTransaction(OPMainnetChainID, tx, VerifyOptions{}); err == nil {
+		t.Fatalf("expected error for invalid value")
+	}
+}
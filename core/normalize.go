@@ -0,0 +1,176 @@
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// transactionFieldAliases maps each transaction file field to the spellings other tools use
+// for it, such as the camelCase of safe-cli, safe-tasks, and the Safe transaction service
+var transactionFieldAliases = []struct {
+	field   string
+	aliases []string
+}{
+	{"safe", []string{"safeAddress", "safe_address"}},
+	{"safe_version", []string{"safeVersion"}},
+	{"chain", []string{"chainId", "chain_id", "chainID"}},
+	{"safe_tx_gas", []string{"safeTxGas"}},
+	{"base_gas", []string{"baseGas", "dataGas"}},
+	{"gas_price", []string{"gasPrice"}},
+	{"gas_token", []string{"gasToken"}},
+	{"refund_receiver", []string{"refundReceiver"}},
+	{"service_safe_tx_hash", []string{"safeTxHash", "safe_tx_hash", "contractTransactionHash"}},
+}
+
+// integerTransactionFields are the transaction file fields that hold numbers
+var integerTransactionFields = []string{"chain", "value", "operation", "safe_tx_gas", "base_gas", "gas_price", "nonce"}
+
+// ParseSafeTransaction parses a transaction file, accepting the field spellings and value types
+// other tools write: camelCase names, numbers given as decimal or hex strings, the
+// {"safe": ..., "tx": {...}} layout of safe-tasks, Safe transaction service proposals as posted by
+// safe-eth-py and ape-safe, and EIP-712 SafeTx typed data. It returns the transaction and a description
+// of every change made to read it, so that nothing is normalized silently.
+func ParseSafeTransaction(data []byte) (SafeTransaction, []string, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return SafeTransaction{}, nil, describeJSONError(data, err)
+	}
+	var notes []string
+	// paths records where fields read from elsewhere in the file came from, for error messages
+	paths := map[string]string{}
+
+	if IsForgeBroadcast(data) {
+		return SafeTransaction{}, nil, fmt.Errorf("the file is a forge broadcast artifact, which does not record the Safe nonce")
+	}
+	if isSafeTxTypedData(fields) {
+		var err error
+		if fields, notes, err = safeTxTypedDataFields(data); err != nil {
+			return SafeTransaction{}, nil, err
+		}
+		for name := range fields {
+			paths[name] = "$.message." + name
+		}
+		paths["safe"], paths["chain"] = "$.domain.verifyingContract", "$.domain.chainId"
+	}
+
+	// safe-tasks nests the Safe transaction under "tx", next to the Safe and chain
+	if inner, ok := fields["tx"]; ok && fields["to"] == nil {
+		var innerFields map[string]json.RawMessage
+		if err := json.Unmarshal(inner, &innerFields); err != nil {
+			return SafeTransaction{}, nil, &TransactionFieldError{Path: "$.tx", Expected: "an object", Got: describeJSONValue(bytes.TrimSpace(inner))}
+		}
+		delete(fields, "tx")
+		for name, value := range innerFields {
+			if existing, ok := fields[name]; ok && !sameJSONValue(existing, value) {
+				return SafeTransaction{}, nil, fmt.Errorf("%q is given both in and outside of \"tx\" with different values", name)
+			}
+			fields[name] = value
+			paths[name] = "$.tx." + name
+		}
+		notes = append(notes, `read the transaction fields from the "tx" object`)
+	}
+
+	for _, alias := range transactionFieldAliases {
+		for _, name := range alias.aliases {
+			value, ok := fields[name]
+			if !ok {
+				continue
+			}
+			if existing, ok := fields[alias.field]; ok && !sameJSONValue(existing, value) {
+				return SafeTransaction{}, nil, fmt.Errorf("%q and %q are both given, with different values", alias.field, name)
+			}
+			delete(fields, name)
+			fields[alias.field] = value
+			paths[alias.field] = "$." + name
+			if original, ok := paths[name]; ok {
+				paths[alias.field] = original
+			}
+			notes = append(notes, fmt.Sprintf("read %q as %q", name, alias.field))
+		}
+	}
+
+	for _, name := range integerTransactionFields {
+		value, ok := fields[name]
+		if !ok {
+			continue
+		}
+		var text string
+		if string(value) == "null" || json.Unmarshal(value, &text) != nil {
+			continue
+		}
+		n, err := parseIntegerString(text)
+		if err != nil {
+			path := "$." + name
+			if original, ok := paths[name]; ok {
+				path = original
+			}
+			return SafeTransaction{}, nil, &TransactionFieldError{Path: path, Expected: "an integer", Got: describeJSONValue(value), Hint: integerStringHint(name, text)}
+		}
+		fields[name] = json.RawMessage(n.String())
+		notes = append(notes, fmt.Sprintf("converted %q from the string %q to the number %s", name, text, n))
+	}
+
+	if value, ok := fields["data"]; ok && string(value) == "null" {
+		fields["data"] = json.RawMessage(`"0x"`)
+		notes = append(notes, `read a null "data" as empty calldata (0x)`)
+	}
+	for _, name := range []string{"gas_token", "refund_receiver"} {
+		if value, ok := fields[name]; ok && string(value) == "null" {
+			fields[name] = json.RawMessage(`"` + ZeroAddress + `"`)
+			notes = append(notes, fmt.Sprintf("read a null %q as the zero address", name))
+		}
+	}
+
+	normalized, err := json.Marshal(fields)
+	if err != nil {
+		return SafeTransaction{}, nil, err
+	}
+	tx, err := decodeTransactionJSON(normalized, paths)
+	if err != nil {
+		return SafeTransaction{}, nil, err
+	}
+	return tx, notes, nil
+}
+
+// parseIntegerString parses a non-negative decimal or 0x-prefixed hex integer
+func parseIntegerString(text string) (*big.Int, error) {
+	text = strings.TrimSpace(text)
+	n, ok := new(big.Int), false
+	if strings.HasPrefix(text, "0x") || strings.HasPrefix(text, "0X") {
+		n, ok = n.SetString(text[2:], 16)
+	} else {
+		n, ok = n.SetString(text, 10)
+	}
+	if !ok || n.Sign() < 0 {
+		return nil, fmt.Errorf("not a decimal or hex integer")
+	}
+	return n, nil
+}
+
+// sameJSONValue reports whether two JSON values are equal, treating a number and a string
+// holding the same integer as equal
+func sameJSONValue(a, b json.RawMessage) bool {
+	if bytes.Equal(a, b) {
+		return true
+	}
+	integer := func(value json.RawMessage) *big.Int {
+		var text string
+		if json.Unmarshal(value, &text) != nil {
+			text = string(value)
+		}
+		n, err := parseIntegerString(text)
+		if err != nil {
+			return nil
+		}
+		return n
+	}
+	x, y := integer(a), integer(b)
+	if x != nil && y != nil {
+		return x.Cmp(y) == 0
+	}
+	var s, t string
+	return json.Unmarshal(a, &s) == nil && json.Unmarshal(b, &t) == nil && strings.EqualFold(s, t)
+}
@@ -0,0 +1,86 @@
+package core
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestParseSafeTransactionCanonical(t *testing.T) {
+	want := SafeTransaction{Safe: effectsSafe, SafeVersion: "1.3.0", Chain: 10, To: airdropAlice, Data: "0x", Nonce: 3, GasToken: ZeroAddress, RefundReceiver: ZeroAddress}
+	data, err := json.Marshal(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx, notes, err := ParseSafeTransaction(data)
+	if err != nil || len(notes) != 0 {
+		t.Fatalf("unexpected result: %v, %v", notes, err)
+	}
+	if tx.Safe != want.Safe || tx.Chain != 10 || tx.Nonce != 3 || tx.Value != nil {
+		t.Errorf("unexpected transaction: %+v", tx)
+	}
+}
+
+func TestParseSafeTransactionAliases(t *testing.T) {
+	// The camelCase layout of safe-cli and the Safe transaction service, with numbers as strings
+	data := `{"safe": "` + effectsSafe + `", "safeVersion": "1.3.0", "chainId": "0xa", "to": "` + airdropAlice + `",
+		"value": "1000000000000000000", "data": null, "operation": "1", "safeTxGas": "0", "baseGas": 0, "gasPrice": "0",
+		"gasToken": "` + ZeroAddress + `", "refundReceiver": "` + ZeroAddress + `", "nonce": "42", "safeTxHash": "0x1234"}`
+	tx, notes, err := ParseSafeTransaction([]byte(data))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if tx.SafeVersion != "1.3.0" || tx.Chain != 10 || tx.Value.String() != "1000000000000000000" || tx.Data != "0x" ||
+		tx.Operation != 1 || tx.Nonce != 42 || tx.GasToken != ZeroAddress || tx.ServiceSafeTxHash != "0x1234" {
+		t.Errorf("unexpected transaction: %+v", tx)
+	}
+	all := strings.Join(notes, "\n")
+	for _, want := range []string{
+		`read "chainId" as "chain"`,
+		`read "safeTxGas" as "safe_tx_gas"`,
+		`converted "chain" from the string "0xa" to the number 10`,
+		`converted "nonce" from the string "42" to the number 42`,
+		`read a null "data" as empty calldata (0x)`,
+	} {
+		if !strings.Contains(all, want) {
+			t.Errorf("notes missing %q:\n%s", want, all)
+		}
+	}
+	if strings.Contains(all, `"base_gas" from the string`) {
+		t.Errorf("a numeric base gas was reported as converted:\n%s", all)
+	}
+}
+
+func TestParseSafeTransactionSafeTasks(t *testing.T) {
+	// safe-tasks nests the transaction fields under "tx"
+	data := `{"safe": "` + effectsSafe + `", "chainId": 10, "safeTxHash": "0x1234", "tx": {"to": "` + airdropAlice + `",
+		"value": "0", "data": "0x", "operation": 0, "safeTxGas": 0, "baseGas": 0, "gasPrice": 0,
+		"gasToken": "` + ZeroAddress + `", "refundReceiver": "` + ZeroAddress + `", "nonce": 7}}`
+	tx, notes, err := ParseSafeTransaction([]byte(data))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if tx.To != airdropAlice || tx.Nonce != 7 || tx.Chain != 10 || !strings.Contains(strings.Join(notes, "\n"), `"tx" object`) {
+		t.Errorf("unexpected result: %+v, %v", tx, notes)
+	}
+}
+
+func TestParseSafeTransactionConflicts(t *testing.T) {
+	for name, data := range map[string]string{
+		"different chains":    `{"chain": 1, "chainId": 10}`,
+		"different hashes":    `{"service_safe_tx_hash": "0x12", "safeTxHash": "0x34"}`,
+		"tx object conflicts": `{"nonce": 1, "tx": {"nonce": 2}}`,
+		"non-integer nonce":   `{"nonce": "seven"}`,
+		"negative value":      `{"value": "-1"}`,
+		"not an object":       `[1, 2]`,
+	} {
+		if _, _, err := ParseSafeTransaction([]byte(data)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+
+	// The same value spelled twice is not a conflict
+	if tx, _, err := ParseSafeTransaction([]byte(`{"chain": 10, "chainId": "10"}`)); err != nil || tx.Chain != 10 {
+		t.Errorf("unexpected result for agreeing fields: %+v, %v", tx, err)
+	}
+}
@@ -0,0 +1,225 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// DefaultOnchainLookback is how many blocks back OnchainSafeClient searches for an executed
+// transaction: about 23 days on OP chains and four months on Ethereum
+const DefaultOnchainLookback = 1_000_000
+
+// onchainLogRange is how many blocks each eth_getLogs request spans, which most providers accept
+const onchainLogRange = 10_000
+
+var (
+	safeVersionSelector = crypto.Keccak256([]byte("VERSION()"))[:4]
+	safeNonceSelector   = crypto.Keccak256([]byte("nonce()"))[:4]
+)
+
+// safeAdditionalInfoArgs are the fields SafeL2 packs into the additionalInfo of its
+// SafeMultiSigTransaction event
+var safeAdditionalInfoArgs = abi.Arguments{
+	{Name: "nonce", Type: mustABIType("uint256")},
+	{Name: "sender", Type: mustABIType("address")},
+	{Name: "threshold", Type: mustABIType("uint256")},
+}
+
+// OnchainSafeClient is a SafeClient that reconstructs transactions from the chain, for when the
+// Safe Transaction Service is down. It can only reconstruct executed transactions of SafeL2
+// deployments, which emit every executed transaction in a SafeMultiSigTransaction event; the
+// chain records nothing of a transaction that is still waiting for signatures.
+type OnchainSafeClient struct {
+	RPC     *RPCClient
+	ChainID uint64
+
+	// Lookback is how many blocks before the latest one are searched, or DefaultOnchainLookback
+	Lookback uint64
+
+	chainChecked bool
+}
+
+// errNotOnchain is returned for the lookups the chain cannot answer
+var errNotOnchain = errors.New("not recorded on chain in a form op-txverify reads")
+
+// checkChain makes sure the node is on the chain whose transactions are reconstructed
+func (c *OnchainSafeClient) checkChain(ctx context.Context) error {
+	if c.chainChecked {
+		return nil
+	}
+	chainID, err := c.RPC.ChainID(ctx)
+	if err != nil {
+		return err
+	}
+	if chainID != c.ChainID {
+		return fmt.Errorf("RPC endpoint is on chain %d, not chain %d", chainID, c.ChainID)
+	}
+	c.chainChecked = true
+	return nil
+}
+
+// safeNonce reads the nonce a Safe will execute next
+func (c *OnchainSafeClient) safeNonce(ctx context.Context, safeAddress string) (*big.Int, error) {
+	data, err := c.RPC.Call(ctx, safeAddress, safeNonceSelector, "latest")
+	if err != nil {
+		return nil, err
+	}
+	values, err := abi.Arguments{{Type: mustABIType("uint256")}}.Unpack(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid nonce result: %w", err)
+	}
+	return values[0].(*big.Int), nil
+}
+
+// GetSafeInfo reads the version, nonce, and owners of a Safe
+func (c *OnchainSafeClient) GetSafeInfo(ctx context.Context, safeAddress string) (*SafeInfoResponse, error) {
+	if err := c.checkChain(ctx); err != nil {
+		return nil, err
+	}
+	data, err := c.RPC.Call(ctx, safeAddress, safeVersionSelector, "latest")
+	if err != nil {
+		return nil, err
+	}
+	values, err := abi.Arguments{{Type: mustABIType("string")}}.Unpack(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid VERSION result: %w", err)
+	}
+	nonce, err := c.safeNonce(ctx, safeAddress)
+	if err != nil {
+		return nil, err
+	}
+	owners, err := readOwnerSet(ctx, c.RPC, safeAddress, "latest")
+	if err != nil {
+		return nil, err
+	}
+	return &SafeInfoResponse{
+		Version:   values[0].(string),
+		Nonce:     APIValue{Raw: nonce.String(), Present: true},
+		Threshold: int(owners.Threshold),
+		Owners:    owners.Owners,
+	}, nil
+}
+
+// GetMultisigTransactions finds the executed transaction at a nonce in the SafeMultiSigTransaction
+// events of the Safe, searching back from the latest block
+func (c *OnchainSafeClient) GetMultisigTransactions(ctx context.Context, safeAddress string, nonce uint64) (*APIResponse, error) {
+	if err := c.checkChain(ctx); err != nil {
+		return nil, err
+	}
+	current, err := c.safeNonce(ctx, safeAddress)
+	if err != nil {
+		return nil, err
+	}
+	if new(big.Int).SetUint64(nonce).Cmp(current) >= 0 {
+		return nil, fmt.Errorf("nonce %d of %s has not been executed, and the chain records nothing of a transaction before it executes", nonce, safeAddress)
+	}
+
+	latest, err := c.RPC.BlockByNumber(ctx, "latest")
+	if err != nil {
+		return nil, err
+	}
+	lookback := c.Lookback
+	if lookback == 0 {
+		lookback = DefaultOnchainLookback
+	}
+	end := uint64(latest.Number)
+	for searched := uint64(0); searched < lookback; {
+		start := uint64(0)
+		if end >= onchainLogRange {
+			start = end - onchainLogRange + 1
+		}
+		logs, err := c.RPC.Logs(ctx, safeAddress, safeMultiSigTransactionTopic, start, end)
+		if err != nil {
+			return nil, err
+		}
+		for _, log := range logs {
+			tx, logNonce, err := decodeSafeMultiSigTransaction(safeAddress, log)
+			if err != nil {
+				return nil, err
+			}
+			if logNonce == nonce {
+				return &APIResponse{Count: 1, Results: []APITransaction{tx}}, nil
+			}
+		}
+		if start == 0 {
+			break
+		}
+		searched += end - start + 1
+		end = start - 1
+	}
+	return nil, fmt.Errorf("no SafeMultiSigTransaction event for nonce %d of %s in the last %d blocks (only SafeL2 deployments emit it)", nonce, safeAddress, lookback)
+}
+
+// decodeSafeMultiSigTransaction reads a transaction and its nonce from a SafeMultiSigTransaction
+// event, in the form the Safe service gives transactions
+func decodeSafeMultiSigTransaction(safeAddress string, log RPCLog) (APITransaction, uint64, error) {
+	values, err := safeMultiSigTransactionArgs.Unpack(log.Data)
+	if err != nil {
+		return APITransaction{}, 0, fmt.Errorf("invalid SafeMultiSigTransaction event: %w", err)
+	}
+	info, err := safeAdditionalInfoArgs.Unpack(values[10].([]byte))
+	if err != nil {
+		return APITransaction{}, 0, fmt.Errorf("invalid SafeMultiSigTransaction additionalInfo: %w", err)
+	}
+	nonce := info[0].(*big.Int)
+	if !nonce.IsUint64() {
+		return APITransaction{}, 0, fmt.Errorf("invalid nonce %s in SafeMultiSigTransaction event", nonce)
+	}
+
+	field := func(raw string) APIValue { return APIValue{Raw: raw, Present: true} }
+	tx := APITransaction{
+		Safe:                  common.HexToAddress(safeAddress).Hex(),
+		To:                    field(values[0].(common.Address).Hex()),
+		Value:                 field(values[1].(*big.Int).String()),
+		Data:                  field(hexutil.Encode(values[2].([]byte))),
+		Operation:             field(fmt.Sprint(values[3].(uint8))),
+		SafeTxGas:             field(values[4].(*big.Int).String()),
+		BaseGas:               field(values[5].(*big.Int).String()),
+		GasPrice:              field(values[6].(*big.Int).String()),
+		GasToken:              field(values[7].(common.Address).Hex()),
+		RefundReceiver:        field(values[8].(common.Address).Hex()),
+		Nonce:                 field(nonce.String()),
+		ConfirmationsRequired: field(info[2].(*big.Int).String()),
+		IsExecuted:            true,
+		BlockNumber:           uint64(log.BlockNumber),
+		TransactionHash:       log.TransactionHash.Hex(),
+	}
+	return tx, nonce.Uint64(), nil
+}
+
+// GetPendingTransactions is not answered from the chain, which records no pending transactions
+func (c *OnchainSafeClient) GetPendingTransactions(ctx context.Context, safeAddress string, fromNonce uint64) (*APIResponse, error) {
+	return nil, fmt.Errorf("pending transactions: %w", errNotOnchain)
+}
+
+// GetMultisigTransaction is not answered from the chain, whose events do not name the safeTxHash
+func (c *OnchainSafeClient) GetMultisigTransaction(ctx context.Context, safeTxHash string) (*APITransaction, error) {
+	return nil, fmt.Errorf("transaction %s: %w", safeTxHash, errNotOnchain)
+}
+
+// GetTransfer is not answered from the chain
+func (c *OnchainSafeClient) GetTransfer(ctx context.Context, transferID string) (*Transfer, error) {
+	return nil, fmt.Errorf("transfer %s: %w", transferID, errNotOnchain)
+}
+
+// GetModuleTransactions is not answered from the chain
+func (c *OnchainSafeClient) GetModuleTransactions(ctx context.Context, safeAddress string, limit int) (*ModuleTransactionsResponse, error) {
+	return nil, fmt.Errorf("module transactions: %w", errNotOnchain)
+}
+
+// GetModuleTransaction is not answered from the chain
+func (c *OnchainSafeClient) GetModuleTransaction(ctx context.Context, id string) (*ModuleTransaction, error) {
+	return nil, fmt.Errorf("module transaction %s: %w", id, errNotOnchain)
+}
+
+// GetSafeMessages is not answered from the chain, which does not record off-chain messages
+func (c *OnchainSafeClient) GetSafeMessages(ctx context.Context, safeAddress string) (*SafeMessagesResponse, error) {
+	return nil, fmt.Errorf("off-chain messages: %w", errNotOnchain)
+}
@@ -0,0 +1,79 @@
+package core
+
+import (
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// ownableABIJSON are the ownership functions of OpenZeppelin's Ownable and Ownable2Step, and of
+// AccessControlDefaultAdminRules, which moves DEFAULT_ADMIN_ROLE in two steps the same way
+var ownableABIJSON = []string{
+	`[{"inputs":[{"name":"newOwner","type":"address"}],"name":"transferOwnership","outputs":[],"stateMutability":"nonpayable","type":"function"}]`,
+	`[{"inputs":[],"name":"acceptOwnership","outputs":[],"stateMutability":"nonpayable","type":"function"}]`,
+	`[{"inputs":[],"name":"renounceOwnership","outputs":[],"stateMutability":"nonpayable","type":"function"}]`,
+	`[{"inputs":[{"name":"newAdmin","type":"address"}],"name":"beginDefaultAdminTransfer","outputs":[],"stateMutability":"nonpayable","type":"function"}]`,
+	`[{"inputs":[],"name":"acceptDefaultAdminTransfer","outputs":[],"stateMutability":"nonpayable","type":"function"}]`,
+	`[{"inputs":[],"name":"cancelDefaultAdminTransfer","outputs":[],"stateMutability":"nonpayable","type":"function"}]`,
+}
+
+func init() {
+	registerKnownABIs(ownableABIJSON)
+}
+
+// checkOwnershipTransfers warns about calls that hand over or give up the ownership of a
+// contract. Whoever owns a contract can usually upgrade, pause, or drain it, so these are among
+// the most consequential calls a Safe signs. Ownable2Step only records the new owner until they
+// accept, while plain Ownable transfers at once; the calldata is the same, so both are described.
+func checkOwnershipTransfers(result *VerificationResult) []Warning {
+	var warnings []Warning
+	for _, r := range []*VerificationResult{result.NestedResult, result} {
+		if r == nil {
+			continue
+		}
+		chainID := uint64(r.Transaction.Chain)
+		safe := ChecksumAddress(StripChainPrefix(r.Transaction.Safe))
+		for _, call := range safeCalls(r) {
+			name := "The transaction"
+			if call.Index != "" {
+				name = "Call #" + call.Index
+			}
+			target := describeAddress(common.HexToAddress(StripChainPrefix(call.Target)), chainID)
+
+			switch call.FunctionName {
+			case "transferOwnership", "beginDefaultAdminTransfer":
+				argName := "newOwner"
+				if call.FunctionName == "beginDefaultAdminTransfer" {
+					argName = "newAdmin"
+				}
+				arg, _ := call.Argument(argName)
+				newOwner, ok := arg.Value.(common.Address)
+				if !ok {
+					continue
+				}
+				switch {
+				case newOwner == (common.Address{}):
+					warnings = append(warnings, newWarning(warnOwnershipToZero,
+						"%s transfers the ownership of %s to the zero address. A contract with plain Ownable is left without an owner for good; with Ownable2Step this only cancels a pending transfer.",
+						name, target))
+				case strings.EqualFold(newOwner.Hex(), safe):
+					warnings = append(warnings, newWarning(warnOwnershipToSafe,
+						"%s transfers the ownership of %s to this Safe %s", name, target, safe))
+				default:
+					warnings = append(warnings, newWarning(warnOwnershipTransfer,
+						"%s hands the ownership of %s to %s. The new owner controls the contract once the transfer completes: at once with plain Ownable, or when they call acceptOwnership with Ownable2Step or AccessControlDefaultAdminRules. Make sure the new owner is correct.",
+						name, target, describeAddress(newOwner, chainID)))
+				}
+			case "renounceOwnership":
+				warnings = append(warnings, newWarning(warnOwnershipRenounced,
+					"%s renounces the ownership of %s. No one can call its owner-only functions afterwards, and this cannot be undone.",
+					name, target))
+			case "acceptOwnership", "acceptDefaultAdminTransfer":
+				warnings = append(warnings, newWarning(warnOwnershipAccepted,
+					"%s makes this Safe %s the owner of %s by accepting a pending transfer. Make sure the Safe is meant to control the contract.",
+					name, safe, target))
+			}
+		}
+	}
+	return warnings
+}
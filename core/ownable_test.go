@@ -0,0 +1,48 @@
+package core
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// ownableCall calls an ownership function of airdropToken
+func ownableCall(t *testing.T, sig string, args ...interface{}) multiSendTransaction {
+	t.Helper()
+	return multiSendTransaction{To: common.HexToAddress(airdropToken), Value: big.NewInt(0), Data: common.FromHex(encodeKnownCall(t, sig, args...))}
+}
+
+func TestCheckOwnershipTransfers(t *testing.T) {
+	result := ownerResult(t,
+		ownableCall(t, "transferOwnership(address)", common.HexToAddress(airdropAlice)),
+		ownableCall(t, "transferOwnership(address)", common.Address{}),
+		ownableCall(t, "renounceOwnership()"),
+		ownableCall(t, "acceptOwnership()"),
+		ownableCall(t, "beginDefaultAdminTransfer(address)", common.HexToAddress(effectsSafe)),
+		ownableCall(t, "cancelDefaultAdminTransfer()"),
+	)
+
+	for _, want := range []struct {
+		severity Severity
+		text     string
+	}{
+		{SeverityCritical, "Call #1 hands the ownership of " + ChecksumAddress(airdropToken) + " to " + ChecksumAddress(airdropAlice)},
+		{SeverityCritical, "Call #2 transfers the ownership of " + ChecksumAddress(airdropToken) + " to the zero address"},
+		{SeverityCritical, "Call #3 renounces the ownership"},
+		{SeverityWarning, "Call #4 makes this Safe " + effectsSafe + " the owner"},
+		{SeverityInfo, "Call #5 transfers the ownership of " + ChecksumAddress(airdropToken) + " to this Safe"},
+	} {
+		if len(warningsContaining(result.Warnings, want.severity, want.text)) != 1 {
+			t.Errorf("expected a %s warning containing %q, got %+v", want.severity, want.text, result.Warnings)
+		}
+	}
+	for _, severity := range []Severity{SeverityInfo, SeverityWarning, SeverityCritical} {
+		if len(warningsContaining(result.Warnings, severity, "Call #6")) != 0 {
+			t.Errorf("expected no warning for cancelling a transfer, got %+v", result.Warnings)
+		}
+	}
+	if result.Call.SubCalls[5].FunctionName != "cancelDefaultAdminTransfer" {
+		t.Errorf("expected the cancellation to be decoded, got %+v", result.Call.SubCalls[5])
+	}
+}
@@ -0,0 +1,341 @@
+package core
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// sentinelOwner heads the linked list a Safe keeps its owners in; the first owner's previous
+// owner is the sentinel
+var sentinelOwner = common.HexToAddress("0x0000000000000000000000000000000000000001")
+
+var (
+	getOwnersSelector    = crypto.Keccak256([]byte("getOwners()"))[:4]
+	getThresholdSelector = crypto.Keccak256([]byte("getThreshold()"))[:4]
+)
+
+// ownerManagementFunctions are the Safe functions that change its owners or threshold. A Safe
+// only accepts them from itself.
+var ownerManagementFunctions = map[string]bool{
+	"addOwnerWithThreshold(address,uint256)": true,
+	"removeOwner(address,address,uint256)":   true,
+	"swapOwner(address,address,address)":     true,
+	"changeThreshold(uint256)":               true,
+}
+
+// OwnerSet is the owners of a Safe, in the order the Safe returns them, and its threshold
+type OwnerSet struct {
+	Owners    []string `json:"owners"`
+	Threshold uint64   `json:"threshold"`
+}
+
+// OwnerChange is a call a transaction makes to change its Safe's owners or threshold
+type OwnerChange struct {
+	// Index is the position of the call in a MultiSend batch, or zero for a single call
+	Index       int    `json:"index,omitempty"`
+	Function    string `json:"function"`
+	Description string `json:"description"`
+
+	prevOwner, owner, newOwner common.Address
+	threshold                  *big.Int
+}
+
+// OwnerDiff is the owner set of a Safe before and after a transaction that changes it
+type OwnerDiff struct {
+	Changes []OwnerChange `json:"changes"`
+
+	// Before and After are only set when the owners were read from a node. After is not set
+	// when the changes would revert, which Revert explains.
+	Before  *OwnerSet `json:"before,omitempty"`
+	After   *OwnerSet `json:"after,omitempty"`
+	Block   string    `json:"block,omitempty"`
+	Added   []string  `json:"added,omitempty"`
+	Removed []string  `json:"removed,omitempty"`
+	Revert  string    `json:"revert,omitempty"`
+}
+
+// ChangesOwners reports whether a result, or the child of a nested approval, changes the owners
+// or threshold of its Safe
+func ChangesOwners(result *VerificationResult) bool {
+	for _, r := range []*VerificationResult{result, result.NestedResult} {
+		if r == nil {
+			continue
+		}
+		if changes, err := ownerChanges(r.Transaction); err == nil && len(changes) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckOwnerChanges reads the current owners and threshold of a Safe whose transaction changes
+// them and applies the changes, recording the end state on the result as an OwnerDiff.
+// Reviewers see who can sign afterwards instead of working it out from a sequence of add,
+// remove, and swap calls. Changes that would revert are critical warnings. Without a client
+// only the changes are recorded, with a note that the end state was not computed.
+func CheckOwnerChanges(ctx context.Context, client *RPCClient, result *VerificationResult) error {
+	checkedChain := false
+	for _, r := range []*VerificationResult{result, result.NestedResult} {
+		if r == nil {
+			continue
+		}
+		changes, err := ownerChanges(r.Transaction)
+		if err != nil {
+			return err
+		}
+		if len(changes) == 0 {
+			continue
+		}
+		diff := &OwnerDiff{Changes: changes}
+		r.OwnerDiff = diff
+		safe := ChecksumAddress(StripChainPrefix(r.Transaction.Safe))
+
+		if client == nil {
+			result.Warnings = append(result.Warnings, newWarning(warnOwnersUnchecked,
+				"the transaction changes the owners or threshold of %s; the resulting owners were not computed because no RPC endpoint was given", safe))
+			continue
+		}
+
+		if !checkedChain {
+			chainID, err := client.ChainID(ctx)
+			if err != nil {
+				return fmt.Errorf("error reading owners: %w", err)
+			}
+			if chainID != uint64(result.Transaction.Chain) {
+				return fmt.Errorf("RPC endpoint is on chain %d but the transaction is on chain %d", chainID, result.Transaction.Chain)
+			}
+			checkedChain = true
+		}
+
+		// An executed transaction already changed the owners, so start from the block before it
+		diff.Block = "latest"
+		if execution := r.Transaction.Execution; execution != nil && execution.BlockNumber > 0 {
+			diff.Block = hexutil.EncodeUint64(execution.BlockNumber - 1)
+		}
+		before, err := readOwnerSet(ctx, client, safe, diff.Block)
+		if err != nil {
+			return fmt.Errorf("error reading owners of %s: %w", safe, err)
+		}
+		diff.Before = before
+
+		after, err := applyOwnerChanges(*before, common.HexToAddress(safe), changes)
+		if err != nil {
+			diff.Revert = err.Error()
+			result.Warnings = append(result.Warnings, newWarning(warnOwnerChangeReverts,
+				"the owner changes to %s would revert: %s", safe, diff.Revert))
+			continue
+		}
+		diff.After = after
+		diff.Added, diff.Removed = ownerSetDifference(before.Owners, after.Owners)
+	}
+	return nil
+}
+
+// ownerChanges decodes the owner management calls a transaction makes on its own Safe
+func ownerChanges(tx SafeTransaction) ([]OwnerChange, error) {
+	calls, err := batchCalls(tx)
+	if err != nil {
+		return nil, err
+	}
+	safe := common.HexToAddress(StripChainPrefix(tx.Safe))
+
+	var changes []OwnerChange
+	for i, call := range calls {
+		if call.Operation != 0 || call.To != safe || len(call.Data) < 4 {
+			continue
+		}
+		functionInfo, ok := KnownFunctions[hex.EncodeToString(call.Data[:4])]
+		if !ok || !ownerManagementFunctions[functionInfo.Signature] {
+			continue
+		}
+		values, err := functionInfo.ABI.Inputs.Unpack(call.Data[4:])
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s call: %w", functionInfo.Name, err)
+		}
+
+		change := OwnerChange{Function: functionInfo.Name}
+		if len(calls) > 1 {
+			change.Index = i + 1
+		}
+		switch functionInfo.Name {
+		case "addOwnerWithThreshold":
+			change.owner, change.threshold = values[0].(common.Address), values[1].(*big.Int)
+			change.Description = fmt.Sprintf("add owner %s and set the threshold to %s", change.owner.Hex(), change.threshold)
+		case "removeOwner":
+			change.prevOwner, change.owner, change.threshold = values[0].(common.Address), values[1].(common.Address), values[2].(*big.Int)
+			change.Description = fmt.Sprintf("remove owner %s and set the threshold to %s", change.owner.Hex(), change.threshold)
+		case "swapOwner":
+			change.prevOwner, change.owner, change.newOwner = values[0].(common.Address), values[1].(common.Address), values[2].(common.Address)
+			change.Description = fmt.Sprintf("replace owner %s with %s", change.owner.Hex(), change.newOwner.Hex())
+		case "changeThreshold":
+			change.threshold = values[0].(*big.Int)
+			change.Description = fmt.Sprintf("set the threshold to %s", change.threshold)
+		}
+		changes = append(changes, change)
+	}
+	return changes, nil
+}
+
+// readOwnerSet reads the owners and threshold of a Safe at a block
+func readOwnerSet(ctx context.Context, client *RPCClient, safe string, block string) (*OwnerSet, error) {
+	data, err := client.Call(ctx, safe, getOwnersSelector, block)
+	if err != nil {
+		return nil, err
+	}
+	values, err := abi.Arguments{{Type: mustABIType("address[]")}}.Unpack(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid getOwners result: %w", err)
+	}
+	set := &OwnerSet{}
+	for _, owner := range values[0].([]common.Address) {
+		set.Owners = append(set.Owners, owner.Hex())
+	}
+
+	data, err = client.Call(ctx, safe, getThresholdSelector, block)
+	if err != nil {
+		return nil, err
+	}
+	values, err = abi.Arguments{{Type: mustABIType("uint256")}}.Unpack(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid getThreshold result: %w", err)
+	}
+	threshold := values[0].(*big.Int)
+	if !threshold.IsUint64() {
+		return nil, fmt.Errorf("invalid threshold %s", threshold)
+	}
+	set.Threshold = threshold.Uint64()
+	return set, nil
+}
+
+// applyOwnerChanges applies owner changes in order with the checks of the Safe's OwnerManager,
+// whose revert codes (GS2xx) are quoted. New owners are added at the head of the list, as the
+// Safe does.
+func applyOwnerChanges(set OwnerSet, safe common.Address, changes []OwnerChange) (*OwnerSet, error) {
+	owners := make([]common.Address, len(set.Owners))
+	for i, owner := range set.Owners {
+		owners[i] = common.HexToAddress(owner)
+	}
+	threshold := set.Threshold
+
+	indexOf := func(owner common.Address) int {
+		for i, o := range owners {
+			if o == owner {
+				return i
+			}
+		}
+		return -1
+	}
+	validNewOwner := func(owner common.Address) error {
+		if owner == (common.Address{}) || owner == sentinelOwner || owner == safe {
+			return fmt.Errorf("%s is not a valid owner (GS203)", owner.Hex())
+		}
+		if indexOf(owner) != -1 {
+			return fmt.Errorf("%s is already an owner (GS204)", owner.Hex())
+		}
+		return nil
+	}
+	// linkedOwner finds an owner and checks that prevOwner points to it in the linked list
+	linkedOwner := func(prevOwner, owner common.Address) (int, error) {
+		i := indexOf(owner)
+		if i == -1 {
+			return 0, fmt.Errorf("%s is not an owner (GS205)", owner.Hex())
+		}
+		expected := sentinelOwner
+		if i > 0 {
+			expected = owners[i-1]
+		}
+		if prevOwner != expected {
+			return 0, fmt.Errorf("the previous owner of %s is %s, not %s (GS205)", owner.Hex(), expected.Hex(), prevOwner.Hex())
+		}
+		return i, nil
+	}
+	setThreshold := func(t *big.Int) error {
+		if t.Sign() <= 0 {
+			return fmt.Errorf("the threshold must be at least 1 (GS202)")
+		}
+		if !t.IsUint64() || t.Uint64() > uint64(len(owners)) {
+			return fmt.Errorf("threshold %s exceeds the %d owners (GS201)", t, len(owners))
+		}
+		threshold = t.Uint64()
+		return nil
+	}
+
+	for _, change := range changes {
+		var err error
+		switch change.Function {
+		case "addOwnerWithThreshold":
+			if err = validNewOwner(change.owner); err == nil {
+				owners = append([]common.Address{change.owner}, owners...)
+				err = setThreshold(change.threshold)
+			}
+		case "removeOwner":
+			if big.NewInt(int64(len(owners)-1)).Cmp(change.threshold) < 0 {
+				err = fmt.Errorf("removing an owner leaves %d owners, fewer than threshold %s (GS201)", len(owners)-1, change.threshold)
+				break
+			}
+			var i int
+			if i, err = linkedOwner(change.prevOwner, change.owner); err == nil {
+				owners = append(owners[:i], owners[i+1:]...)
+				err = setThreshold(change.threshold)
+			}
+		case "swapOwner":
+			if err = validNewOwner(change.newOwner); err == nil {
+				var i int
+				if i, err = linkedOwner(change.prevOwner, change.owner); err == nil {
+					owners[i] = change.newOwner
+				}
+			}
+		case "changeThreshold":
+			err = setThreshold(change.threshold)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", describeOwnerChangeCall(change), err)
+		}
+	}
+
+	after := &OwnerSet{Threshold: threshold}
+	for _, owner := range owners {
+		after.Owners = append(after.Owners, owner.Hex())
+	}
+	return after, nil
+}
+
+// describeOwnerChangeCall names the call an owner change was made by
+func describeOwnerChangeCall(change OwnerChange) string {
+	if change.Index == 0 {
+		return change.Function
+	}
+	return "subcall #" + strconv.Itoa(change.Index) + " (" + change.Function + ")"
+}
+
+// ownerSetDifference returns the owners only in after and the owners only in before
+func ownerSetDifference(before, after []string) (added, removed []string) {
+	contains := func(owners []string, owner string) bool {
+		for _, o := range owners {
+			if strings.EqualFold(o, owner) {
+				return true
+			}
+		}
+		return false
+	}
+	for _, owner := range after {
+		if !contains(before, owner) {
+			added = append(added, owner)
+		}
+	}
+	for _, owner := range before {
+		if !contains(after, owner) {
+			removed = append(removed, owner)
+		}
+	}
+	return added, removed
+}
@@ -0,0 +1,157 @@
+package core
+
+import (
+	"context"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+var (
+	ownerA = common.HexToAddress("0x00000000000000000000000000000000000000a1")
+	ownerB = common.HexToAddress("0x00000000000000000000000000000000000000b2")
+	ownerC = common.HexToAddress("0x00000000000000000000000000000000000000c3")
+	ownerD = common.HexToAddress("0x00000000000000000000000000000000000000d4")
+	ownerE = common.HexToAddress("0x00000000000000000000000000000000000000e5")
+)
+
+// ownerCall encodes a call from a Safe to one of its own owner management functions
+func ownerCall(t *testing.T, sig string, args ...interface{}) multiSendTransaction {
+	t.Helper()
+	return multiSendTransaction{To: common.HexToAddress(effectsSafe), Value: big.NewInt(0), Data: common.FromHex(encodeKnownCall(t, sig, args...))}
+}
+
+// ownerNode is a node on which effectsSafe has the given owners and threshold
+func ownerNode(t *testing.T, owners []common.Address, threshold int64) (*RPCClient, *fakeNode) {
+	t.Helper()
+	ownersResult, err := abi.Arguments{{Type: mustABIType("address[]")}}.Pack(owners)
+	if err != nil {
+		t.Fatal(err)
+	}
+	thresholdResult, err := abi.Arguments{{Type: mustABIType("uint256")}}.Pack(big.NewInt(threshold))
+	if err != nil {
+		t.Fatal(err)
+	}
+	safe := strings.ToLower(effectsSafe)
+	node := &fakeNode{chainID: MainnetChainID, calls: map[string]hexutil.Bytes{
+		safe + ":" + hexutil.Encode(getOwnersSelector):    ownersResult,
+		safe + ":" + hexutil.Encode(getThresholdSelector): thresholdResult,
+	}}
+	return newFakeNode(t, node), node
+}
+
+// ownerResult verifies a batch of calls made by effectsSafe
+func ownerResult(t *testing.T, calls ...multiSendTransaction) *VerificationResult {
+	t.Helper()
+	tx := multiSendTx(t, calls...)
+	tx.Safe = effectsSafe
+	tx.SafeVersion = "1.3.0"
+	result, err := VerifyTransaction(tx, VerifyOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return result
+}
+
+func TestCheckOwnerChanges(t *testing.T) {
+	client, node := ownerNode(t, []common.Address{ownerA, ownerB, ownerC}, 2)
+	result := ownerResult(t,
+		ownerCall(t, "addOwnerWithThreshold(address,uint256)", ownerD, big.NewInt(2)),
+		ownerCall(t, "removeOwner(address,address,uint256)", ownerA, ownerB, big.NewInt(3)),
+		ownerCall(t, "swapOwner(address,address,address)", sentinelOwner, ownerD, ownerE),
+		ownerCall(t, "changeThreshold(uint256)", big.NewInt(2)),
+		nativeTransfer(airdropBob, big.NewInt(1)),
+	)
+	if !ChangesOwners(result) {
+		t.Fatalf("expected owner changes")
+	}
+
+	if err := CheckOwnerChanges(context.Background(), client, result); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	diff := result.OwnerDiff
+	if diff == nil || len(diff.Changes) != 4 || diff.Changes[2].Index != 3 {
+		t.Fatalf("unexpected changes: %+v", diff)
+	}
+	if diff.Before.Threshold != 2 || len(diff.Before.Owners) != 3 {
+		t.Errorf("unexpected owners before: %+v", diff.Before)
+	}
+	want := []string{ownerE.Hex(), ownerA.Hex(), ownerC.Hex()}
+	if diff.After == nil || diff.After.Threshold != 2 || strings.Join(diff.After.Owners, ",") != strings.Join(want, ",") {
+		t.Fatalf("owners after = %+v, want %v with threshold 2", diff.After, want)
+	}
+	if len(diff.Added) != 1 || diff.Added[0] != ownerE.Hex() || len(diff.Removed) != 1 || diff.Removed[0] != ownerB.Hex() {
+		t.Errorf("added %v, removed %v", diff.Added, diff.Removed)
+	}
+	if len(node.callBlocks) == 0 || node.callBlocks[0] != "latest" {
+		t.Errorf("owners read at %v, want latest", node.callBlocks)
+	}
+	if HasCritical(result.Warnings) {
+		t.Errorf("unexpected critical warnings: %+v", result.Warnings)
+	}
+}
+
+func TestCheckOwnerChangesRevert(t *testing.T) {
+	client, _ := ownerNode(t, []common.Address{ownerA, ownerB, ownerC}, 2)
+	tests := map[string]struct {
+		call multiSendTransaction
+		want string
+	}{
+		"wrong previous owner": {ownerCall(t, "removeOwner(address,address,uint256)", sentinelOwner, ownerB, big.NewInt(2)), "GS205"},
+		"not an owner":         {ownerCall(t, "swapOwner(address,address,address)", ownerA, ownerD, ownerE), "GS205"},
+		"existing owner":       {ownerCall(t, "addOwnerWithThreshold(address,uint256)", ownerC, big.NewInt(2)), "GS204"},
+		"threshold too high":   {ownerCall(t, "changeThreshold(uint256)", big.NewInt(4)), "GS201"},
+		"zero threshold":       {ownerCall(t, "changeThreshold(uint256)", big.NewInt(0)), "GS202"},
+	}
+	for name, tt := range tests {
+		result := ownerResult(t, tt.call, nativeTransfer(airdropBob, big.NewInt(1)))
+		if err := CheckOwnerChanges(context.Background(), client, result); err != nil {
+			t.Fatalf("%s: unexpected error: %v", name, err)
+		}
+		if result.OwnerDiff.After != nil || !strings.Contains(result.OwnerDiff.Revert, tt.want) {
+			t.Errorf("%s: revert = %q, want %s", name, result.OwnerDiff.Revert, tt.want)
+		}
+		if !HasCritical(result.Warnings) {
+			t.Errorf("%s: expected a critical warning", name)
+		}
+	}
+}
+
+func TestCheckOwnerChangesWithoutNode(t *testing.T) {
+	result := ownerResult(t, ownerCall(t, "changeThreshold(uint256)", big.NewInt(3)), nativeTransfer(airdropBob, big.NewInt(1)))
+	if err := CheckOwnerChanges(context.Background(), nil, result); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.OwnerDiff == nil || len(result.OwnerDiff.Changes) != 1 || result.OwnerDiff.Before != nil {
+		t.Fatalf("unexpected diff: %+v", result.OwnerDiff)
+	}
+	if len(result.Warnings) == 0 || !strings.Contains(result.Warnings[len(result.Warnings)-1].Message, "no RPC endpoint") {
+		t.Errorf("expected a note that the owners were not read: %+v", result.Warnings)
+	}
+}
+
+func TestCheckOwnerChangesExecuted(t *testing.T) {
+	client, node := ownerNode(t, []common.Address{ownerA, ownerB}, 1)
+	result := ownerResult(t, ownerCall(t, "changeThreshold(uint256)", big.NewInt(2)), nativeTransfer(airdropBob, big.NewInt(1)))
+	result.Transaction.Execution = &Execution{TransactionHash: "0xabc", BlockNumber: 100}
+
+	if err := CheckOwnerChanges(context.Background(), client, result); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if node.callBlocks[0] != "0x63" || result.OwnerDiff.After.Threshold != 2 {
+		t.Errorf("owners read at %v, after %+v", node.callBlocks, result.OwnerDiff.After)
+	}
+}
+
+func TestCheckOwnerChangesIgnoresOtherSafes(t *testing.T) {
+	other := ownerCall(t, "changeThreshold(uint256)", big.NewInt(3))
+	other.To = common.HexToAddress(fixtureParentSafe)
+	result := ownerResult(t, other, nativeTransfer(airdropBob, big.NewInt(1)))
+	if ChangesOwners(result) {
+		t.Fatalf("a call to another Safe does not change this Safe's owners")
+	}
+}
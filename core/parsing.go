@@ -10,6 +10,7 @@ import (
 
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
 )
 
 // ParseTransactionData parses the transaction data and identifies the function call
@@ -33,10 +34,22 @@ func ParseTransactionData(to string, data string, chainID uint64, options Verify
 			Target:       to,
 			TargetName:   targetName,
 			FunctionName: "unknown",
-			RawData:      "0x" + data,
+			RawData:      "0x" + cleanData,
 		}, nil
 	}
 
+	// The deterministic deployment proxy takes salt || init code without a selector
+	if normalizedTo == strings.ToLower(DeterministicDeployProxy) {
+		if deployment := decodeDeployment(to, common.FromHex(cleanData)); deployment != nil {
+			return &CallData{
+				Target:       to,
+				TargetName:   targetName,
+				FunctionName: "CREATE2 deployment",
+				Deployment:   deployment,
+			}, nil
+		}
+	}
+
 	// Extract function selector (first 4 bytes)
 	if len(cleanData) < 8 {
 		return &CallData{
@@ -49,8 +62,8 @@ func ParseTransactionData(to string, data string, chainID uint64, options Verify
 
 	functionSelector := cleanData[:8]
 
-	// Try to identify the function from known selectors
-	functionInfo, isKnownFunction := KnownFunctions[functionSelector]
+	// Try to identify the function from the target's own functions and the known selectors
+	functionInfo, isKnownFunction := lookupFunction(to, chainID, functionSelector)
 
 	if !isKnownFunction {
 		// If we can't identify the function, return the raw data
@@ -63,7 +76,7 @@ func ParseTransactionData(to string, data string, chainID uint64, options Verify
 	}
 
 	// Parse the function arguments
-	parsedArgs, err := parseArguments(functionInfo.ABI, "0x"+cleanData)
+	arguments, err := parseArguments(functionInfo.ABI, "0x"+cleanData)
 	if err != nil {
 		// If we can't parse the arguments, return the raw data
 		return &CallData{
@@ -71,20 +84,34 @@ func ParseTransactionData(to string, data string, chainID uint64, options Verify
 			TargetName:   targetName,
 			FunctionName: functionInfo.Name,
 			RawData:      data,
+			Emergency:    isEmergencySelector(functionSelector),
 		}, nil
 	}
 
-	// If this is a token function on a known contract where we have decimals, adjust the amount
-	if isKnownContract && TokenFunctions[functionInfo.Name] && contractInfo.Decimals > 0 && parsedArgs["amount"] != nil {
-		parsedArgs["amount"] = ParseDecimals(parsedArgs["amount"].(*big.Int), contractInfo.Decimals)
-	}
+	parsedArgs := argumentMap(arguments)
 
-	// Check if any of the arguments are known contracts
-	for key, value := range parsedArgs {
-		if value, ok := value.(common.Address); ok {
-			contractInfo, isKnownContract := GetKnownContract(value.Hex(), chainID)
-			if isKnownContract {
-				parsedArgs[key] = fmt.Sprintf("%s (%s 🔍)", value, contractInfo.Name)
+	for i, arg := range arguments {
+		switch value := arg.Value.(type) {
+		case *big.Int:
+			// If this is a token function on a known contract where we have decimals, scale the amount
+			if arg.Name == "amount" && isKnownContract && TokenFunctions[functionInfo.Name] && contractInfo.Decimals > 0 {
+				arguments[i].Display = ParseDecimals(value, contractInfo.Decimals)
+			}
+		case common.Address:
+			// Name the arguments that are known contracts
+			if contractInfo, isKnownContract := GetKnownContract(value.Hex(), chainID); isKnownContract {
+				arguments[i].Display = fmt.Sprintf("%s (%s 🔍)", value, contractInfo.Name)
+			}
+		case string:
+			// Say what a bytes32 value likely is, such as a role, a padded address, or text
+			if arg.Type == "bytes32" {
+				interpretation := InterpretBytes32(common.HexToHash(value), chainID)
+				if arg.Name == "role" && RoleName(common.HexToHash(value)) != "" {
+					interpretation = RoleName(common.HexToHash(value))
+				}
+				if interpretation != "" {
+					arguments[i].Display = fmt.Sprintf("%s (%s)", value, interpretation)
+				}
 			}
 		}
 	}
@@ -104,24 +131,58 @@ func ParseTransactionData(to string, data string, chainID uint64, options Verify
 			return nil, err
 		}
 
-		// Return with subcalls
-		return &CallData{
+		// Return with subcalls, numbered hierarchically (1, 1.1, 1.2, 2, ...)
+		call := &CallData{
 			Target:       to,
 			TargetName:   targetName,
 			FunctionName: functionInfo.Name,
 			SubCalls:     subcalls,
-		}, nil
+		}
+		AssignCallIndices(call)
+		return call, nil
 	}
 
-	// Regular function call
+	// Regular function call, which may deploy a contract through a known deployer or deposit
+	// an L2 transaction through an OptimismPortal. Well-known calls (e.g. DEX swaps) whose raw
+	// arguments are hard to review also get a summary.
 	return &CallData{
 		Target:       to,
 		TargetName:   targetName,
 		FunctionName: functionInfo.Name,
-		ParsedData:   parsedArgs,
+		ParsedData:   arguments,
+		Summary:      decodeCall(to, functionInfo, parsedArgs, chainID),
+		Emergency:    isEmergencySelector(functionSelector),
+		Deployment:   decodeDeployment(to, common.FromHex(cleanData)),
+		Deposit:      decodeDeposit(to, common.FromHex(cleanData), chainID, options),
 	}, nil
 }
 
+// SubcallDigest returns keccak256(operation ‖ target ‖ value ‖ calldata) of a batched call, with
+// the operation as one byte (0 for CALL, 1 for DELEGATECALL) and the value as a 32-byte word. It
+// depends only on what the call does, so reviewers on different machines can name one subcall of
+// a long batch by its digest and recheck just that call, for example with
+// `cast keccak $(cast concat-hex <operation> <target> $(cast to-uint256 <value>) <calldata>)`.
+func SubcallDigest(operation uint8, target common.Address, value *big.Int, data []byte) string {
+	if value == nil {
+		value = new(big.Int)
+	}
+	return crypto.Keccak256Hash([]byte{operation}, target.Bytes(), common.BigToHash(value).Bytes(), data).Hex()
+}
+
+// AssignCallIndices numbers the subcalls of a call hierarchically: the direct subcalls are
+// "1", "2", ... and the subcalls of "2" are "2.1", "2.2", .... The root call has no index.
+func AssignCallIndices(call *CallData) {
+	assignCallIndices(call, "")
+}
+
+func assignCallIndices(call *CallData, prefix string) {
+	for i := range call.SubCalls {
+		index := fmt.Sprintf("%s%d", prefix, i+1)
+		call.SubCalls[i].Index = index
+		assignCallIndices(&call.SubCalls[i], index+".")
+	}
+}
+
 // ParseDecimals parses the amount and returns it as a human-readable string
 // with the correct number of decimals and comma grouping in the integer portion.
 // If the decimal portion is all zeros, it will show only 2 decimal places.
@@ -202,8 +263,29 @@ func addCommas(s string) string {
 	return string(result)
 }
 
-// parseArguments decodes the function arguments from calldata
-func parseArguments(method abi.Method, calldata string) (map[string]interface{}, error) {
+// TupleFieldName returns the Solidity name of a field of an ABI-decoded tuple. The decoder names
+// struct fields in Go style (SystemConfigProxy for systemConfigProxy) and keeps the component
+// name in the json tag; fields of other structs keep their Go name.
+func TupleFieldName(field reflect.StructField) string {
+	if name, _, _ := strings.Cut(field.Tag.Get("json"), ","); name != "" && name != "-" {
+		return name
+	}
+	return field.Name
+}
+
+// tupleField finds a field of a struct by its Solidity or Go name, regardless of case
+func tupleField(t reflect.Type, name string) (reflect.StructField, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath == "" && (strings.EqualFold(TupleFieldName(field), name) || strings.EqualFold(field.Name, name)) {
+			return field, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+// parseArguments decodes the function arguments from calldata, in signature order
+func parseArguments(method abi.Method, calldata string) ([]Argument, error) {
 	// Remove 0x prefix if present
 	calldata = strings.TrimPrefix(calldata, "0x")
 
@@ -227,66 +309,95 @@ func parseArguments(method abi.Method, calldata string) (map[string]interface{},
 		return nil, err
 	}
 
-	// Convert to a map
-	result := make(map[string]interface{})
+	result := make([]Argument, 0, len(args))
 	for i, arg := range args {
 		name := method.Inputs[i].Name
 		if name == "" {
 			name = fmt.Sprintf("arg%d", i)
 		}
 
-		// Handle arrays by creating indexed entries in the map
-		if reflect.TypeOf(arg).Kind() == reflect.Slice &&
-			reflect.TypeOf(arg).Elem().Kind() != reflect.Uint8 {
-			// It's an array but not a byte array
-			arrayValue := reflect.ValueOf(arg)
+		// Byte strings and fixed-size byte arrays are given as hex for better readability
+		value := arg
+		switch v := reflect.ValueOf(arg); {
+		case v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8:
+			value = "0x" + hex.EncodeToString(v.Bytes())
+		case v.Kind() == reflect.Array && v.Type().Elem().Kind() == reflect.Uint8 && v.Type() != reflect.TypeOf(common.Address{}):
+			raw := make([]byte, v.Len())
+			reflect.Copy(reflect.ValueOf(raw), v)
+			value = "0x" + hex.EncodeToString(raw)
+		}
+		result = append(result, Argument{Name: name, Type: method.Inputs[i].Type.String(), Value: value})
+	}
+
+	return result, nil
+}
+
+// argumentMap returns decoded arguments by name, as call decoders take them
+func argumentMap(args []Argument) map[string]interface{} {
+	result := make(map[string]interface{}, len(args))
+	for _, arg := range args {
+		result[arg.Name] = arg.Value
+	}
+	return result
+}
 
-			// Add the full array under its original name
-			result[name] = arg
+// multiSendTransaction is a single entry of the packed multiSend(bytes) payload
+type multiSendTransaction struct {
+	Operation uint8
+	To        common.Address
+	Value     *big.Int
+	Data      []byte
+}
 
-			// Also add individual elements with indexed names for easier access
-			for j := 0; j < arrayValue.Len(); j++ {
-				indexedName := fmt.Sprintf("%s[%d]", name, j)
-				element := arrayValue.Index(j).Interface()
+// decodeMultiSendTransactions decodes the packed multiSend(bytes) payload.
+// Format per entry: operation (1 byte) + to (20 bytes) + value (32 bytes) + dataLength (32 bytes) + data (variable).
+// Every byte must belong to an entry: a truncated entry, a length past the end of the payload, or
+// an operation MultiSend does not know makes the whole payload invalid, since MultiSend reverts on it.
+func decodeMultiSendTransactions(data []byte) ([]multiSendTransaction, error) {
+	var txs []multiSendTransaction
+
+	pos := 0
+	for pos < len(data) {
+		// Ensure we have enough data for the fixed-size fields
+		if len(data)-pos < 85 {
+			return nil, fmt.Errorf("multiSend payload has %d bytes left after call %d, too few for another call", len(data)-pos, len(txs))
+		}
 
-				// Apply the same formatting we do for regular elements
-				if byteArray, ok := element.([]byte); ok {
-					result[indexedName] = "0x" + hex.EncodeToString(byteArray)
-				} else {
-					result[indexedName] = element
-				}
-			}
-		} else {
-			// Convert byte arrays and fixed-size uint8 arrays to hex strings for better readability
-			if byteArray, ok := arg.([]byte); ok {
-				result[name] = "0x" + hex.EncodeToString(byteArray)
-			} else {
-				// Check for fixed-size uint8 arrays using type string
-				argType := fmt.Sprintf("%T", arg)
-				if strings.HasPrefix(argType, "[") && strings.HasSuffix(argType, "]uint8") {
-					// Convert the fixed-size array to a byte slice
-					bytes := make([]byte, 0)
-
-					// Get string representation without brackets
-					str := fmt.Sprintf("%v", arg)
-					str = strings.Trim(str, "[]")
-
-					// Split by space (Go's array string representation uses spaces)
-					for _, numStr := range strings.Fields(str) {
-						var val uint8
-						fmt.Sscanf(numStr, "%d", &val)
-						bytes = append(bytes, val)
-					}
-
-					result[name] = "0x" + hex.EncodeToString(bytes)
-				} else {
-					result[name] = arg
-				}
-			}
+		// Extract operation
+		operation := data[pos]
+		if operation > 1 {
+			return nil, fmt.Errorf("multiSend call %d has unknown operation %d", len(txs)+1, operation)
+		}
+		pos++
+
+		// Extract to address
+		to := common.BytesToAddress(data[pos : pos+20])
+		pos += 20
+
+		// Extract value
+		value := new(big.Int).SetBytes(data[pos : pos+32])
+		pos += 32
+
+		// Extract data length, guarding against lengths that exceed the remaining data
+		// (including values too large to fit in an int, which would otherwise wrap negative)
+		length := new(big.Int).SetBytes(data[pos : pos+32])
+		pos += 32
+		if !length.IsUint64() || length.Uint64() > uint64(len(data)-pos) {
+			return nil, fmt.Errorf("multiSend call %d has a data length of %s but only %d bytes follow", len(txs)+1, length, len(data)-pos)
 		}
+
+		// Extract call data
+		end := pos + int(length.Uint64())
+		txs = append(txs, multiSendTransaction{
+			Operation: operation,
+			To:        to,
+			Value:     value,
+			Data:      data[pos:end],
+		})
+		pos = end
 	}
 
-	return result, nil
+	return txs, nil
 }
 
 // parseMulticall parses subcalls from a multicall function
@@ -297,7 +408,7 @@ func parseMulticall(contractAddress string, chainID uint64, functionInfo Functio
 	normalizedAddress := strings.ToLower(contractAddress)
 
 	// Handle Safe Multisend contracts
-	if normalizedAddress == strings.ToLower(SafeMultisendAddress) || normalizedAddress == strings.ToLower(SafeMultisendCallOnly141) || normalizedAddress == strings.ToLower(SafeMultisendCallOnly130) {
+	if SafeMultisendAddresses[normalizedAddress] {
 		// For Safe Multisend contract, check the function signature
 		if functionInfo.Signature == SafeMultisendSig {
 			// Parse multiSend calldata
@@ -312,53 +423,28 @@ func parseMulticall(contractAddress string, chainID uint64, functionInfo Functio
 				return nil, fmt.Errorf("invalid multiSend data: %v", err)
 			}
 
-			// multiSend data format: operation (1 byte) + to (20 bytes) + value (32 bytes) + dataLength (32 bytes) + data (variable)
-			pos := 0
-			for pos < len(data) {
-				// Ensure we have enough data for the fixed-size fields
-				if pos+85 > len(data) {
-					break
-				}
-
-				// Extract operation
-				// Can skip extracting the operation as it's always 0
-				pos++
-
-				// Extract to address
-				to := common.BytesToAddress(data[pos : pos+20])
-				pos += 20
-
-				// Skip extracting value for now, can implement later if needed
-				pos += 32
-
-				// Extract data length
-				dataLength := common.BytesToHash(data[pos : pos+32])
-				pos += 32
-
-				// Convert data length to int
-				length := new(big.Int).SetBytes(dataLength[:]).Uint64()
-
-				// Ensure we have enough data for the variable-size field
-				if pos+int(length) > len(data) {
-					break
-				}
-
-				// Extract call data
-				callData := data[pos : pos+int(length)]
-				pos += int(length)
-
+			txs, err := decodeMultiSendTransactions(data)
+			if err != nil {
+				return nil, err
+			}
+			for _, tx := range txs {
 				// Parse the subcall
-				subcall, err := ParseTransactionData(to.Hex(), "0x"+hex.EncodeToString(callData), chainID, options)
+				subcall, err := ParseTransactionData(tx.To.Hex(), "0x"+hex.EncodeToString(tx.Data), chainID, options)
 				if err != nil {
 					return nil, err
 				}
+				subcall.Digest = SubcallDigest(tx.Operation, tx.To, tx.Value, tx.Data)
+				subcall.IsDelegateCall = tx.Operation == 1
+				if tx.Value.Sign() > 0 {
+					subcall.Value = tx.Value
+				}
 
 				subcalls = append(subcalls, *subcall)
 			}
 		} else {
 			return nil, fmt.Errorf("unsupported function %s for Safe Multisend contract", functionInfo.Signature)
 		}
-	} else if normalizedAddress == strings.ToLower(Multicall3Address) || normalizedAddress == strings.ToLower(Multicall3Delegatecall) {
+	} else if Multicall3Addresses[normalizedAddress] {
 		// For Multicall3 contract, check the function signature
 		if functionInfo.Signature == Aggregate3Sig {
 			// Define the struct type for the calls
@@ -423,6 +509,7 @@ func parseMulticall(contractAddress string, chainID uint64, functionInfo Functio
 				if err != nil {
 					continue
 				}
+				subcall.Digest = SubcallDigest(0, call.Target, nil, call.CallData)
 
 				// If the multicall is via the delegatecall helper, mark subcalls as delegate
 				if normalizedAddress == strings.ToLower(Multicall3Delegatecall) {
@@ -505,6 +592,10 @@ func parseMulticall(contractAddress string, chainID uint64, functionInfo Functio
 				if err != nil {
 					continue
 				}
+				subcall.Digest = SubcallDigest(0, call.Target, call.Value, call.CallData)
+				if call.Value.Sign() > 0 {
+					subcall.Value = call.Value
+				}
 
 				// If the multicall is via the delegatecall helper, mark subcalls as delegate
 				if normalizedAddress == strings.ToLower(Multicall3Delegatecall) {
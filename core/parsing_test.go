@@ -1,8 +1,16 @@
 package core
 
 import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
 	"math/big"
+	"reflect"
+	"strings"
 	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
 )
 
 func TestStripChainPrefix(t *testing.T) {
@@ -81,3 +89,321 @@ func TestParseDecimals_NoDecimals(t *testing.T) {
 		t.Fatalf("ParseDecimals no decimals = %q, want %q", got, "1,234,567")
 	}
 }
+
+// encodeMultiSendEntry packs a single multiSend entry with an explicit (possibly lying) length word
+func encodeMultiSendEntry(operation byte, to common.Address, length *big.Int, data []byte) []byte {
+	entry := []byte{operation}
+	entry = append(entry, to.Bytes()...)
+	entry = append(entry, make([]byte, 32)...)
+	entry = append(entry, common.LeftPadBytes(length.Bytes(), 32)...)
+	return append(entry, data...)
+}
+
+func TestDecodeMultiSendTransactions(t *testing.T) {
+	to := common.HexToAddress(OPTokenAddress)
+	payload := []byte{0xde, 0xad, 0xbe, 0xef}
+
+	data := encodeMultiSendEntry(0, to, big.NewInt(int64(len(payload))), payload)
+	data = append(data, encodeMultiSendEntry(1, to, big.NewInt(0), nil)...)
+
+	txs, err := decodeMultiSendTransactions(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(txs) != 2 {
+		t.Fatalf("decoded %d transactions, want 2", len(txs))
+	}
+	if txs[0].To != to || !bytes.Equal(txs[0].Data, payload) {
+		t.Fatalf("unexpected first transaction: %+v", txs[0])
+	}
+	if txs[1].Operation != 1 || len(txs[1].Data) != 0 {
+		t.Fatalf("unexpected second transaction: %+v", txs[1])
+	}
+}
+
+func TestDecodeMultiSendTransactionsGiantLength(t *testing.T) {
+	to := common.HexToAddress(OPTokenAddress)
+
+	// A length that wraps negative when truncated to int must not panic
+	giant := new(big.Int).Lsh(big.NewInt(1), 63)
+	if _, err := decodeMultiSendTransactions(encodeMultiSendEntry(0, to, giant, []byte{0x01})); err == nil {
+		t.Fatal("expected an error for giant length")
+	}
+
+	// A length above 64 bits must not be silently truncated to its low bits
+	huge := new(big.Int).Add(new(big.Int).Lsh(big.NewInt(1), 64), big.NewInt(1))
+	if _, err := decodeMultiSendTransactions(encodeMultiSendEntry(0, to, huge, []byte{0x01})); err == nil {
+		t.Fatal("expected an error for >64-bit length")
+	}
+}
+
+func TestDecodeMultiSendTransactionsMalformed(t *testing.T) {
+	to := common.HexToAddress(OPTokenAddress)
+	valid := encodeMultiSendEntry(0, to, big.NewInt(4), []byte{0xa9, 0x05, 0x9c, 0xbb})
+
+	for name, data := range map[string][]byte{
+		"truncated trailing call": append(append([]byte{}, valid...), valid[:84]...),
+		"leftover byte":           append(append([]byte{}, valid...), 0x00),
+		"length past the end":     encodeMultiSendEntry(0, to, big.NewInt(5), []byte{0xa9, 0x05, 0x9c, 0xbb}),
+		"unknown operation":       append(append([]byte{}, valid...), encodeMultiSendEntry(2, to, big.NewInt(0), nil)...),
+	} {
+		if txs, err := decodeMultiSendTransactions(data); err == nil {
+			t.Errorf("%s: expected an error, decoded %d calls", name, len(txs))
+		}
+	}
+
+	// A batch whose tail does not decode fails verification instead of showing its first calls
+	tx := SafeTransaction{
+		Safe:        "0x2501c477d0a35545a387aa4a3eee4292a9a8b3f0",
+		SafeVersion: "1.3.0",
+		Chain:       int(OPMainnetChainID),
+		To:          SafeMultisendCallOnly141,
+		Value:       big.NewInt(0),
+		Data:        "0x" + hex.EncodeToString(encodeMultiSendCall(t, append(append([]byte{}, valid...), valid[:84]...))),
+		Operation:   1,
+	}
+	if _, err := VerifyTransaction(tx, VerifyOptions{}); err == nil {
+		t.Fatal("expected verification of a batch with a truncated call to fail")
+	}
+}
+
+// encodeMultiSendCall builds multiSend(bytes) calldata for packed entries
+func encodeMultiSendCall(t *testing.T, entries []byte) []byte {
+	t.Helper()
+	method := KnownFunctions["8d80ff0a"].ABI
+	packed, err := method.Inputs.Pack(entries)
+	if err != nil {
+		t.Fatalf("failed to pack multiSend: %v", err)
+	}
+	return append(append([]byte{}, method.ID...), packed...)
+}
+
+func TestParseTransactionDataHierarchicalIndices(t *testing.T) {
+	multisend := common.HexToAddress(SafeMultisendCallOnly141)
+	token := common.HexToAddress(OPTokenAddress)
+	transfer := []byte{0xa9, 0x05, 0x9c, 0xbb}
+
+	inner := encodeMultiSendEntry(0, token, big.NewInt(4), transfer)
+	inner = append(inner, encodeMultiSendEntry(0, token, big.NewInt(4), transfer)...)
+	innerCall := encodeMultiSendCall(t, inner)
+
+	outer := encodeMultiSendEntry(0, token, big.NewInt(4), transfer)
+	outer = append(outer, encodeMultiSendEntry(1, multisend, big.NewInt(int64(len(innerCall))), innerCall)...)
+	outer = append(outer, encodeMultiSendEntry(0, token, big.NewInt(4), transfer)...)
+
+	call, err := ParseTransactionData(multisend.Hex(), "0x"+hex.EncodeToString(encodeMultiSendCall(t, outer)), OPMainnetChainID, VerifyOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var got []string
+	var walk func(c CallData)
+	walk = func(c CallData) {
+		for _, sub := range c.SubCalls {
+			got = append(got, sub.Index)
+			walk(sub)
+		}
+	}
+	walk(*call)
+
+	want := []string{"1", "2", "2.1", "2.2", "3"}
+	if len(got) != len(want) {
+		t.Fatalf("indices = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("indices = %v, want %v", got, want)
+		}
+	}
+	if call.Index != "" {
+		t.Errorf("root call should have no index, got %q", call.Index)
+	}
+}
+
+func TestParseTransactionDataSubcallDigests(t *testing.T) {
+	multisend := common.HexToAddress(SafeMultisendCallOnly141)
+	token := common.HexToAddress(OPTokenAddress)
+	transfer := []byte{0xa9, 0x05, 0x9c, 0xbb}
+
+	batch := encodeMultiSendEntry(0, token, big.NewInt(4), transfer)
+	batch = append(batch, encodeMultiSendEntry(0, token, big.NewInt(4), transfer)...)
+	batch = append(batch, encodeMultiSendEntry(0, token, big.NewInt(4), []byte{0x09, 0x5e, 0xa7, 0xb3})...)
+
+	call, err := ParseTransactionData(multisend.Hex(), "0x"+hex.EncodeToString(encodeMultiSendCall(t, batch)), OPMainnetChainID, VerifyOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if call.Digest != "" {
+		t.Errorf("root call should have no digest, got %q", call.Digest)
+	}
+	if len(call.SubCalls) != 3 {
+		t.Fatalf("got %d subcalls, want 3", len(call.SubCalls))
+	}
+
+	want := SubcallDigest(0, token, big.NewInt(0), transfer)
+	if call.SubCalls[0].Digest != want {
+		t.Errorf("digest = %s, want %s", call.SubCalls[0].Digest, want)
+	}
+	if call.SubCalls[1].Digest != call.SubCalls[0].Digest {
+		t.Errorf("identical subcalls have different digests: %s and %s", call.SubCalls[0].Digest, call.SubCalls[1].Digest)
+	}
+	if call.SubCalls[2].Digest == call.SubCalls[0].Digest {
+		t.Errorf("subcalls with different calldata share digest %s", call.SubCalls[0].Digest)
+	}
+	if SubcallDigest(0, token, big.NewInt(1), transfer) == want {
+		t.Error("digest does not depend on the value")
+	}
+	if SubcallDigest(1, token, big.NewInt(0), transfer) == want {
+		t.Error("a CALL and a DELEGATECALL share a digest")
+	}
+
+	// The digest is the keccak256 of the packed operation, target, value, and calldata
+	packed := append([]byte{0}, token.Bytes()...)
+	packed = append(packed, common.BigToHash(big.NewInt(0)).Bytes()...)
+	if got := crypto.Keccak256Hash(append(packed, transfer...)).Hex(); got != want {
+		t.Errorf("digest = %s, want keccak256 of the packed fields %s", want, got)
+	}
+}
+
+func FuzzDecodeMultiSendTransactions(f *testing.F) {
+	to := common.HexToAddress(OPTokenAddress)
+	f.Add(encodeMultiSendEntry(0, to, big.NewInt(4), []byte{0xa9, 0x05, 0x9c, 0xbb}))
+	f.Add(encodeMultiSendEntry(0, to, new(big.Int).Lsh(big.NewInt(1), 63), nil))
+	f.Add([]byte{})
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		total := 0
+		txs, err := decodeMultiSendTransactions(data)
+		if err != nil {
+			return
+		}
+		for _, tx := range txs {
+			total += 85 + len(tx.Data)
+		}
+		if total != len(data) {
+			t.Fatalf("decoded %d bytes from %d bytes of input", total, len(data))
+		}
+	})
+}
+
+func FuzzParseTransactionData(f *testing.F) {
+	f.Add(OPTokenAddress, "0xa9059cbb000000000000000000000000000000000000000000000000000000000000dead0000000000000000000000000000000000000000000000000000000000000001", uint64(OPMainnetChainID))
+	f.Add(SafeMultisendCallOnly141, "0x8d80ff0a", uint64(OPMainnetChainID))
+	f.Add(Multicall3Address, "0x82ad56cb", uint64(MainnetChainID))
+	f.Add("0x0", "0x", uint64(0))
+
+	f.Fuzz(func(t *testing.T, to string, data string, chainID uint64) {
+		_, _ = ParseTransactionData(to, data, chainID, VerifyOptions{})
+	})
+}
+
+func FuzzParseMulticall(f *testing.F) {
+	multiSend := KnownFunctions["8d80ff0a"]
+	aggregate3 := KnownFunctions["82ad56cb"]
+	aggregate3Value := KnownFunctions["174dea71"]
+
+	f.Add([]byte{}, uint8(0))
+	f.Add(encodeMultiSendEntry(0, common.HexToAddress(OPTokenAddress), big.NewInt(4), []byte{0xa9, 0x05, 0x9c, 0xbb}), uint8(0))
+
+	f.Fuzz(func(t *testing.T, payload []byte, kind uint8) {
+		switch kind % 3 {
+		case 0:
+			args := map[string]interface{}{"transactions": "0x" + hex.EncodeToString(payload)}
+			_, _ = parseMulticall(SafeMultisendCallOnly141, OPMainnetChainID, multiSend, args, VerifyOptions{})
+		case 1:
+			data := append(append([]byte{}, aggregate3.ABI.ID...), payload...)
+			if args, err := parseArguments(aggregate3.ABI, "0x"+hex.EncodeToString(data)); err == nil {
+				_, _ = parseMulticall(Multicall3Address, MainnetChainID, aggregate3, argumentMap(args), VerifyOptions{})
+			}
+		case 2:
+			data := append(append([]byte{}, aggregate3Value.ABI.ID...), payload...)
+			if args, err := parseArguments(aggregate3Value.ABI, "0x"+hex.EncodeToString(data)); err == nil {
+				_, _ = parseMulticall(Multicall3Address, MainnetChainID, aggregate3Value, argumentMap(args), VerifyOptions{})
+			}
+		}
+	})
+}
+
+func TestParseTransactionDataTupleFieldNames(t *testing.T) {
+	var selector string
+	for s, fn := range KnownFunctions {
+		if fn.Name == "updatePrestate" {
+			selector = s
+		}
+	}
+	if selector == "" {
+		t.Fatal("updatePrestate is not a known function")
+	}
+	type input struct {
+		SystemConfigProxy common.Address
+		ProxyAdmin        common.Address
+		AbsolutePrestate  [32]byte
+	}
+	data, err := KnownFunctions[selector].ABI.Inputs.Pack([]input{{
+		SystemConfigProxy: common.HexToAddress(airdropAlice),
+		ProxyAdmin:        common.HexToAddress(airdropBob),
+		AbsolutePrestate:  [32]byte{0x03},
+	}})
+	if err != nil {
+		t.Fatalf("failed to pack: %v", err)
+	}
+
+	call, err := ParseTransactionData(airdropAlice, "0x"+selector+hex.EncodeToString(data), OPMainnetChainID, VerifyOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	args := call.ParsedData
+	inputs, _ := call.Argument("prestateUpdateInputs")
+	tuple := reflect.ValueOf(inputs.Value).Index(0).Type()
+	var names []string
+	for i := 0; i < tuple.NumField(); i++ {
+		names = append(names, TupleFieldName(tuple.Field(i)))
+	}
+	if strings.Join(names, ",") != "systemConfigProxy,proxyAdmin,absolutePrestate" {
+		t.Errorf("field names = %v, want the ABI component names in declared order", names)
+	}
+
+	// Annotation paths resolve to the Solidity names, with either spelling
+	for _, path := range []string{"prestateUpdateInputs[0].systemConfigProxy", "prestateUpdateInputs[0].SystemConfigProxy"} {
+		if resolved, ok := resolveArgumentPath(args, path); !ok || resolved != "prestateUpdateInputs[0].systemConfigProxy" {
+			t.Errorf("resolveArgumentPath(%q) = %q, %v", path, resolved, ok)
+		}
+	}
+	if value, err := structField(reflect.ValueOf(inputs.Value).Index(0).Interface(), "proxyAdmin"); err != nil || value != common.HexToAddress(airdropBob) {
+		t.Errorf("structField = %v, %v", value, err)
+	}
+}
+
+func TestParseTransactionDataArgumentOrder(t *testing.T) {
+	data := encodeKnownCall(t, "depositForBurn(uint256,uint32,bytes32,address,bytes32,uint256,uint32)",
+		big.NewInt(5), uint32(10), [32]byte{0xab}, common.HexToAddress(USDCMainnetAddress), [32]byte{}, big.NewInt(0), uint32(1000))
+	call, err := ParseTransactionData(airdropAlice, data, MainnetChainID, VerifyOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var names, types []string
+	for _, arg := range call.ParsedData {
+		names = append(names, arg.Name)
+		types = append(types, arg.Type)
+	}
+	if got := strings.Join(names, ","); got != "amount,destinationDomain,mintRecipient,burnToken,destinationCaller,maxFee,minFinalityThreshold" {
+		t.Errorf("argument names = %s, want signature order", got)
+	}
+	if got := strings.Join(types, ","); got != "uint256,uint32,bytes32,address,bytes32,uint256,uint32" {
+		t.Errorf("argument types = %s", got)
+	}
+
+	recipient, _ := call.Argument("mintRecipient")
+	if recipient.Value != "0xab"+strings.Repeat("00", 31) {
+		t.Errorf("mintRecipient = %v, want hex", recipient.Value)
+	}
+	token, _ := call.Argument("burnToken")
+	if token.Value != common.HexToAddress(USDCMainnetAddress) || !strings.Contains(token.Display, "🔍") {
+		t.Errorf("burnToken = %+v, want the address with its contract name", token)
+	}
+
+	encoded, err := json.Marshal(call.ParsedData[0])
+	if err != nil || string(encoded) != `{"name":"amount","type":"uint256","value":5}` {
+		t.Errorf("JSON = %s, %v", encoded, err)
+	}
+}
@@ -0,0 +1,101 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// PendingTransaction summarizes a multisig transaction that is queued on the Safe service but
+// not executed yet. Several may share a nonce when a replacement has been proposed.
+type PendingTransaction struct {
+	Nonce         uint64 `json:"nonce"`
+	SafeTxHash    string `json:"safeTxHash"`
+	To            string `json:"to"`
+	Method        string `json:"method,omitempty"`
+	Confirmations int    `json:"confirmations"`
+	Required      int    `json:"confirmationsRequired"`
+	Proposer      string `json:"proposer,omitempty"`
+	Submitted     string `json:"submissionDate,omitempty"`
+}
+
+// FetchPendingTransactions lists the queued transactions for a Safe, starting at its current nonce
+func FetchPendingTransactions(ctx context.Context, network string, safeAddress string) ([]PendingTransaction, error) {
+	apiURL, chainID, err := getNetworkInfo(network)
+	if err != nil {
+		return nil, err
+	}
+
+	sources := []ChainSource{{Source: fmt.Sprintf("network %q", network), ChainID: chainID}}
+	source, err := PrefixSource("safe", safeAddress)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := ResolveChainID(append(sources, source)...); err != nil {
+		return nil, err
+	}
+
+	return FetchPendingTransactionsWithClient(ctx, serviceClient(chainID, apiURL), StripChainPrefix(safeAddress))
+}
+
+// FetchPendingTransactionsWithClient lists the queued transactions for a Safe using the given client.
+// Transactions below the Safe's current nonce can no longer be executed and are left out.
+func FetchPendingTransactionsWithClient(ctx context.Context, client SafeClient, safeAddress string) ([]PendingTransaction, error) {
+	safeAddress = common.HexToAddress(safeAddress).Hex()
+
+	safeInfo, err := client.GetSafeInfo(ctx, safeAddress)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching safe nonce: %w", err)
+	}
+	if !safeInfo.Nonce.Present || safeInfo.Nonce.Null {
+		return nil, fmt.Errorf("service response is missing required field %q", "nonce")
+	}
+	current, err := strconv.ParseUint(safeInfo.Nonce.Raw, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid nonce %q in service response: %w", safeInfo.Nonce.Raw, err)
+	}
+
+	apiResp, err := client.GetPendingTransactions(ctx, safeAddress, current)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching pending transactions: %w", err)
+	}
+
+	pending := make([]PendingTransaction, 0, len(apiResp.Results))
+	for _, tx := range apiResp.Results {
+		if tx.IsExecuted {
+			continue
+		}
+		nonce, err := strconv.ParseUint(tx.Nonce.Raw, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid nonce %q in service response: %w", tx.Nonce.Raw, err)
+		}
+		if nonce < current {
+			continue
+		}
+		entry := summarizePendingTransaction(tx, nonce)
+		pending = append(pending, entry)
+	}
+	sort.SliceStable(pending, func(i, j int) bool { return pending[i].Nonce < pending[j].Nonce })
+	return pending, nil
+}
+
+// summarizePendingTransaction summarizes a multisig transaction from the Safe service
+func summarizePendingTransaction(tx APITransaction, nonce uint64) PendingTransaction {
+	required, _ := strconv.Atoi(tx.ConfirmationsRequired.Raw)
+	entry := PendingTransaction{
+		Nonce:         nonce,
+		SafeTxHash:    tx.SafeTxHash,
+		To:            tx.To.Raw,
+		Confirmations: len(tx.Confirmations),
+		Required:      required,
+		Proposer:      tx.Proposer,
+		Submitted:     tx.SubmissionDate,
+	}
+	if decoded, ok := tx.DataDecoded.(map[string]interface{}); ok {
+		entry.Method, _ = decoded["method"].(string)
+	}
+	return entry
+}
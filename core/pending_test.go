@@ -0,0 +1,39 @@
+package core
+
+import (
+	"context"
+	"testing"
+)
+
+func TestFetchPendingTransactionsWithClient(t *testing.T) {
+	routes := defaultFixtureRoutes()
+	routes["/api/v1/safes/"+fixtureGrantsSafe+"/multisig-transactions/?executed=false&nonce__gte=156&ordering=nonce"] = "multisig-transactions-grants-pending.json"
+	server := newFixtureServer(t, routes)
+
+	pending, err := FetchPendingTransactionsWithClient(context.Background(), NewHTTPSafeClient(server.URL), fixtureGrantsSafe)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []PendingTransaction{
+		{Nonce: 156, Method: "approve", Confirmations: 1, Required: 2},
+		{Nonce: 156, Method: "", Confirmations: 0, Required: 2},
+		{Nonce: 157, Method: "transfer", Confirmations: 0, Required: 2},
+	}
+	if len(pending) != len(want) {
+		t.Fatalf("got %d pending transactions, want %d: %+v", len(pending), len(want), pending)
+	}
+	for i, w := range want {
+		got := pending[i]
+		if got.Nonce != w.Nonce || got.Method != w.Method || got.Confirmations != w.Confirmations || got.Required != w.Required {
+			t.Errorf("pending[%d] = %+v, want %+v", i, got, w)
+		}
+	}
+}
+
+func TestFetchPendingTransactionsWithClientUnknownSafe(t *testing.T) {
+	server := newFixtureServer(t, defaultFixtureRoutes())
+
+	if _, err := FetchPendingTransactionsWithClient(context.Background(), NewHTTPSafeClient(server.URL), OPTokenAddress); err == nil {
+		t.Fatalf("expected an error for a Safe the service does not know")
+	}
+}
@@ -0,0 +1,186 @@
+package core
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Perturbable fields, in the order they are demonstrated
+const (
+	PerturbTo    = "to"
+	PerturbValue = "value"
+	PerturbNonce = "nonce"
+	PerturbData  = "data"
+	PerturbSafe  = "safe"
+	PerturbChain = "chain"
+)
+
+// PerturbFields lists every field PerturbTransaction can change
+var PerturbFields = []string{PerturbTo, PerturbValue, PerturbNonce, PerturbData, PerturbSafe, PerturbChain}
+
+// TransactionHashes are the three hashes a signer compares
+type TransactionHashes struct {
+	DomainHash  string `json:"domainHash"`
+	MessageHash string `json:"messageHash"`
+	ApproveHash string `json:"approveHash"`
+}
+
+// Perturbation is a single deliberate change to a transaction and the hashes that result
+type Perturbation struct {
+	Field       string            `json:"field"`
+	Description string            `json:"description"`
+	Original    string            `json:"original"`
+	Perturbed   string            `json:"perturbed"`
+	Hashes      TransactionHashes `json:"hashes"`
+}
+
+// PerturbationReport is the baseline hashes of a transaction and every perturbation of it
+type PerturbationReport struct {
+	Baseline      TransactionHashes `json:"baseline"`
+	Perturbations []Perturbation    `json:"perturbations"`
+}
+
+// CalculateHashes computes the domain, message, and Safe tx hashes of a transaction
+func CalculateHashes(tx SafeTransaction) (TransactionHashes, error) {
+	domainHash, err := CalculateDomainHash(tx)
+	if err != nil {
+		return TransactionHashes{}, fmt.Errorf("failed to calculate domain hash: %w", err)
+	}
+	messageHash, err := CalculateMessageHash(tx)
+	if err != nil {
+		return TransactionHashes{}, fmt.Errorf("failed to calculate message hash: %w", err)
+	}
+	approveHash, err := CalculateApproveHash(tx)
+	if err != nil {
+		return TransactionHashes{}, fmt.Errorf("failed to calculate approve hash: %w", err)
+	}
+	return TransactionHashes{DomainHash: domainHash, MessageHash: messageHash, ApproveHash: approveHash}, nil
+}
+
+// PerturbTransaction makes small, deliberate changes to the given fields of a transaction (all
+// fields when none are given) and computes the hashes of each modified copy. It is a teaching aid:
+// every change, however small, produces a completely different Safe tx hash.
+func PerturbTransaction(tx SafeTransaction, fields []string) (*PerturbationReport, error) {
+	tx.To = StripChainPrefix(tx.To)
+	tx.Safe = StripChainPrefix(tx.Safe)
+	if tx.Value == nil {
+		tx.Value = big.NewInt(0)
+	}
+	for _, field := range []struct{ name, value string }{{"safe", tx.Safe}, {"to", tx.To}} {
+		if err := ValidateFullAddress(field.name, field.value); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(fields) == 0 {
+		fields = PerturbFields
+	}
+
+	baseline, err := CalculateHashes(tx)
+	if err != nil {
+		return nil, err
+	}
+	report := &PerturbationReport{Baseline: baseline}
+
+	for _, field := range fields {
+		perturbed := tx
+		p := Perturbation{Field: field}
+		switch field {
+		case PerturbTo:
+			p.Description = "last byte of the target address flipped"
+			p.Original = ChecksumAddress(tx.To)
+			perturbed.To = flipLastAddressByte(tx.To)
+			p.Perturbed = perturbed.To
+		case PerturbValue:
+			p.Original = tx.Value.String()
+			perturbed.Value = new(big.Int).Mul(tx.Value, big.NewInt(10))
+			p.Description = "value multiplied by 10"
+			if tx.Value.Sign() == 0 {
+				perturbed.Value = big.NewInt(1)
+				p.Description = "value changed from 0 to 1 wei (0 × 10 is still 0)"
+			}
+			p.Perturbed = perturbed.Value.String()
+		case PerturbNonce:
+			p.Description = "nonce increased by 1"
+			p.Original = fmt.Sprint(tx.Nonce)
+			perturbed.Nonce = tx.Nonce + 1
+			p.Perturbed = fmt.Sprint(perturbed.Nonce)
+		case PerturbData:
+			if strings.TrimPrefix(tx.Data, "0x") == "" {
+				p.Description = "calldata extended from empty to a single zero byte"
+				perturbed.Data = "0x00"
+			} else {
+				p.Description = "last byte of the calldata flipped"
+				perturbed.Data = flipLastHexByte(tx.Data)
+			}
+			p.Original = lastBytes(tx.Data)
+			p.Perturbed = lastBytes(perturbed.Data)
+		case PerturbSafe:
+			p.Description = "last byte of the Safe address flipped"
+			p.Original = ChecksumAddress(tx.Safe)
+			perturbed.Safe = flipLastAddressByte(tx.Safe)
+			p.Perturbed = perturbed.Safe
+		case PerturbChain:
+			p.Description = "chain ID increased by 1"
+			p.Original = fmt.Sprint(tx.Chain)
+			perturbed.Chain = tx.Chain + 1
+			p.Perturbed = fmt.Sprint(perturbed.Chain)
+		default:
+			return nil, fmt.Errorf("unknown field %q (must be one of %s)", field, strings.Join(PerturbFields, ", "))
+		}
+
+		p.Hashes, err = CalculateHashes(perturbed)
+		if err != nil {
+			return nil, fmt.Errorf("failed to hash perturbed %s: %w", field, err)
+		}
+		report.Perturbations = append(report.Perturbations, p)
+
+		// Show a nonce decrease as well, since signers are most often confused about nonces
+		if field == PerturbNonce && tx.Nonce > 0 {
+			perturbed.Nonce = tx.Nonce - 1
+			hashes, err := CalculateHashes(perturbed)
+			if err != nil {
+				return nil, fmt.Errorf("failed to hash perturbed %s: %w", field, err)
+			}
+			report.Perturbations = append(report.Perturbations, Perturbation{
+				Field:       field,
+				Description: "nonce decreased by 1",
+				Original:    p.Original,
+				Perturbed:   fmt.Sprint(perturbed.Nonce),
+				Hashes:      hashes,
+			})
+		}
+	}
+
+	return report, nil
+}
+
+// flipLastAddressByte inverts the lowest bit of an address's last byte
+func flipLastAddressByte(address string) string {
+	addr := common.HexToAddress(address)
+	addr[len(addr)-1] ^= 0x01
+	return addr.Hex()
+}
+
+// flipLastHexByte inverts the lowest bit of the last byte of hex data
+func flipLastHexByte(data string) string {
+	raw, err := hex.DecodeString(strings.TrimPrefix(data, "0x"))
+	if err != nil || len(raw) == 0 {
+		return data
+	}
+	raw[len(raw)-1] ^= 0x01
+	return "0x" + hex.EncodeToString(raw)
+}
+
+// lastBytes shows the tail of hex data, which is where a data perturbation happens
+func lastBytes(data string) string {
+	clean := strings.TrimPrefix(data, "0x")
+	if len(clean) <= 16 {
+		return "0x" + clean
+	}
+	return fmt.Sprintf("(%d bytes) ending in %s", len(clean)/2, clean[len(clean)-16:])
+}
@@ -0,0 +1,70 @@
+package core
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPerturbTransactionChangesEveryHash(t *testing.T) {
+	report, err := PerturbTransaction(grantsTransferTx(), nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.EqualFold(report.Baseline.ApproveHash, "0x19767d264966e39d532d998c5354f76ad5102407124b1885d69bf23f791b6f4c") {
+		t.Fatalf("unexpected baseline hash %s", report.Baseline.ApproveHash)
+	}
+
+	// Every field plus the extra nonce decrease
+	if len(report.Perturbations) != len(PerturbFields)+1 {
+		t.Fatalf("expected %d perturbations, got %d", len(PerturbFields)+1, len(report.Perturbations))
+	}
+	for _, p := range report.Perturbations {
+		if p.Hashes.ApproveHash == report.Baseline.ApproveHash {
+			t.Errorf("%s (%s): Safe tx hash did not change", p.Field, p.Description)
+		}
+		if p.Original == p.Perturbed {
+			t.Errorf("%s (%s): original and perturbed values are both %s", p.Field, p.Description, p.Original)
+		}
+	}
+}
+
+func TestPerturbTransactionHashScope(t *testing.T) {
+	report, err := PerturbTransaction(grantsTransferTx(), []string{PerturbTo, PerturbChain})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	to, chain := report.Perturbations[0], report.Perturbations[1]
+
+	// The target is part of the message only; the chain is part of the domain only
+	if to.Hashes.DomainHash != report.Baseline.DomainHash || to.Hashes.MessageHash == report.Baseline.MessageHash {
+		t.Errorf("changing the target should change only the message hash: %+v", to.Hashes)
+	}
+	if chain.Hashes.DomainHash == report.Baseline.DomainHash || chain.Hashes.MessageHash != report.Baseline.MessageHash {
+		t.Errorf("changing the chain should change only the domain hash: %+v", chain.Hashes)
+	}
+	if to.Perturbed != "0x4200000000000000000000000000000000000043" {
+		t.Errorf("expected the last byte of the target to be flipped, got %s", to.Perturbed)
+	}
+}
+
+func TestPerturbTransactionZeroValueAndNonce(t *testing.T) {
+	tx := grantsTransferTx()
+	tx.Nonce = 0
+
+	report, err := PerturbTransaction(tx, []string{PerturbValue, PerturbNonce})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(report.Perturbations) != 2 {
+		t.Fatalf("expected no nonce decrease below zero, got %d perturbations", len(report.Perturbations))
+	}
+	if report.Perturbations[0].Perturbed != "1" {
+		t.Errorf("expected a zero value to become 1 wei, got %s", report.Perturbations[0].Perturbed)
+	}
+}
+
+func TestPerturbTransactionUnknownField(t *testing.T) {
+	if _, err := PerturbTransaction(grantsTransferTx(), []string{"gas"}); err == nil {
+		t.Fatal("expected an error for an unknown field")
+	}
+}
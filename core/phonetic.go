@@ -0,0 +1,40 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+)
+
+// phoneticDigits spells each hex digit using the NATO/ICAO alphabet, which is designed to
+// survive noisy voice channels ("niner" avoids confusion with the German "nein")
+var phoneticDigits = map[rune]string{
+	'0': "zero", '1': "one", '2': "two", '3': "three", '4': "four",
+	'5': "five", '6': "six", '7': "seven", '8': "eight", '9': "niner",
+	'a': "alfa", 'b': "bravo", 'c': "charlie", 'd': "delta", 'e': "echo", 'f': "foxtrot",
+}
+
+// phoneticChunkSize is the number of hex digits read out per group
+const phoneticChunkSize = 4
+
+// PhoneticHash spells a hash as groups of four NATO alphabet words so that signers comparing
+// hashes over a phone call can read and check it group by group
+func PhoneticHash(hash string) ([]string, error) {
+	digits := strings.ToLower(strings.TrimPrefix(strings.TrimPrefix(hash, "0x"), "0X"))
+	if len(digits) == 0 || len(digits)%phoneticChunkSize != 0 {
+		return nil, fmt.Errorf("hash %q must have a multiple of %d hex digits", hash, phoneticChunkSize)
+	}
+
+	chunks := make([]string, 0, len(digits)/phoneticChunkSize)
+	for i := 0; i < len(digits); i += phoneticChunkSize {
+		words := make([]string, 0, phoneticChunkSize)
+		for _, digit := range digits[i : i+phoneticChunkSize] {
+			word, ok := phoneticDigits[digit]
+			if !ok {
+				return nil, fmt.Errorf("hash %q contains non-hex character %q", hash, digit)
+			}
+			words = append(words, word)
+		}
+		chunks = append(chunks, strings.Join(words, " "))
+	}
+	return chunks, nil
+}
@@ -0,0 +1,22 @@
+package core
+
+import "testing"
+
+func TestPhoneticHash(t *testing.T) {
+	chunks, err := PhoneticHash("0x19767D264966e39d532d998c5354f76ad5102407124b1885d69bf23f791b6f4c")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(chunks) != 16 {
+		t.Fatalf("expected 16 chunks, got %d", len(chunks))
+	}
+	if chunks[0] != "one niner seven six" || chunks[1] != "seven delta two six" || chunks[15] != "six foxtrot four charlie" {
+		t.Errorf("unexpected chunks: %q", chunks)
+	}
+
+	for _, bad := range []string{"", "0x123", "0x12zz"} {
+		if _, err := PhoneticHash(bad); err == nil {
+			t.Errorf("expected error for %q", bad)
+		}
+	}
+}
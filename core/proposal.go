@@ -0,0 +1,102 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// safeTxTypedData is the EIP-712 typed data of a Safe transaction, as exported by safe-eth-py
+// (and so safe-cli) and ape-safe for signing
+type safeTxTypedData struct {
+	PrimaryType string `json:"primaryType"`
+	Domain      struct {
+		ChainID           json.RawMessage `json:"chainId"`
+		VerifyingContract string          `json:"verifyingContract"`
+	} `json:"domain"`
+	Message map[string]json.RawMessage `json:"message"`
+}
+
+// isSafeTxTypedData reports whether transaction file fields are EIP-712 typed data
+func isSafeTxTypedData(fields map[string]json.RawMessage) bool {
+	_, hasMessage := fields["message"]
+	return hasMessage && strings.Trim(string(fields["primaryType"]), `"`) == "SafeTx"
+}
+
+// safeTxTypedDataFields converts EIP-712 SafeTx typed data to transaction file fields. The typed
+// data does not name the Safe version, so the oldest version that hashes the same way is assumed
+// from the shape of the domain and message, and noted.
+func safeTxTypedDataFields(data []byte) (map[string]json.RawMessage, []string, error) {
+	var typed safeTxTypedData
+	if err := json.Unmarshal(data, &typed); err != nil {
+		return nil, nil, fmt.Errorf("invalid SafeTx typed data: %w", err)
+	}
+	if typed.Domain.VerifyingContract == "" {
+		return nil, nil, fmt.Errorf("invalid SafeTx typed data: the domain has no verifyingContract")
+	}
+
+	fields := map[string]json.RawMessage{}
+	for name, value := range typed.Message {
+		fields[name] = value
+	}
+	safe, err := json.Marshal(typed.Domain.VerifyingContract)
+	if err != nil {
+		return nil, nil, err
+	}
+	fields["safe"] = safe
+	notes := []string{"read EIP-712 SafeTx typed data (as exported by safe-eth-py, safe-cli, and ape-safe)"}
+
+	// Safe 1.3.0 added the chain ID to the domain, and Safe 1.0.0 renamed dataGas to baseGas.
+	// Every version in each range computes the same domain and transaction hashes.
+	version := "1.3.0"
+	switch {
+	case len(typed.Domain.ChainID) > 0 && string(typed.Domain.ChainID) != "null":
+		fields["chain"] = typed.Domain.ChainID
+		notes = append(notes, "assumed Safe version 1.3.0, since the domain has a chain ID; later versions hash the same way")
+	case typed.Message["dataGas"] != nil:
+		version = "0.1.0"
+		notes = append(notes, "assumed Safe version 0.1.0, since the message has dataGas")
+	default:
+		version = "1.2.0"
+		notes = append(notes, "assumed Safe version 1.2.0, since the domain has no chain ID; versions 1.0.0 to 1.2.0 hash the same way")
+	}
+	fields["safe_version"] = json.RawMessage(`"` + version + `"`)
+	return fields, notes, nil
+}
+
+// CompleteTransaction fills in the chain and Safe version of a transaction whose file does not
+// give them, such as a Safe transaction service proposal. A chain that contradicts the file is an
+// error, as is a Safe version that hashes differently from the file's. Empty arguments are
+// ignored.
+func CompleteTransaction(tx *SafeTransaction, network, safeVersion string) error {
+	if network != "" {
+		if err := ValidateNetwork(network); err != nil {
+			return err
+		}
+		chainID := Networks[strings.ToLower(network)]
+		switch {
+		case tx.Chain == 0:
+			tx.Chain = int(chainID)
+		case uint64(tx.Chain) != chainID:
+			return fmt.Errorf("the transaction file is for chain %d, not %s (chain %d)", tx.Chain, network, chainID)
+		}
+	}
+	if safeVersion != "" {
+		strategy, err := HashingStrategyFor(safeVersion)
+		if err != nil {
+			return err
+		}
+		if tx.SafeVersion != "" {
+			// Versions assumed from typed data stand for every version that hashes the same way
+			fileStrategy, err := HashingStrategyFor(tx.SafeVersion)
+			if err != nil {
+				return err
+			}
+			if strategy.Name() != fileStrategy.Name() {
+				return fmt.Errorf("the transaction file is for Safe version %s, which hashes differently from %s", tx.SafeVersion, safeVersion)
+			}
+		}
+		tx.SafeVersion = safeVersion
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package core
+
+import (
+	"strings"
+	"testing"
+)
+
+// safeTxTypedDataJSON is SafeTx typed data as safe-eth-py exports it, for a 1.3.0+ domain when
+// chainID is set and a pre-1.3.0 domain otherwise
+func safeTxTypedDataJSON(chainID string) string {
+	domainTypes, domain := `[{"name": "verifyingContract", "type": "address"}]`, `{"verifyingContract": "`+effectsSafe+`"}`
+	if chainID != "" {
+		domainTypes = `[{"name": "chainId", "type": "uint256"}, {"name": "verifyingContract", "type": "address"}]`
+		domain = `{"chainId": ` + chainID + `, "verifyingContract": "` + effectsSafe + `"}`
+	}
+	return `{"types": {"EIP712Domain": ` + domainTypes + `, "SafeTx": [
+		{"name": "to", "type": "address"}, {"name": "value", "type": "uint256"}, {"name": "data", "type": "bytes"},
+		{"name": "operation", "type": "uint8"}, {"name": "safeTxGas", "type": "uint256"}, {"name": "baseGas", "type": "uint256"},
+		{"name": "gasPrice", "type": "uint256"}, {"name": "gasToken", "type": "address"}, {"name": "refundReceiver", "type": "address"},
+		{"name": "nonce", "type": "uint256"}]},
+		"primaryType": "SafeTx", "domain": ` + domain + `,
+		"message": {"to": "` + airdropAlice + `", "value": 5, "data": "0xdeadbeef", "operation": 0, "safeTxGas": 0, "baseGas": 0,
+		"gasPrice": 0, "gasToken": "` + ZeroAddress + `", "refundReceiver": "` + ZeroAddress + `", "nonce": 9}}`
+}
+
+func TestParseSafeTransactionTypedData(t *testing.T) {
+	for _, tc := range []struct {
+		name, chainID, version string
+		chain                  int
+	}{
+		{"1.3.0 domain", "10", "1.3.0", 10},
+		{"hex chain ID", `"0xa"`, "1.3.0", 10},
+		{"pre-1.3.0 domain", "", "1.2.0", 0},
+	} {
+		data := safeTxTypedDataJSON(tc.chainID)
+		tx, notes, err := ParseSafeTransaction([]byte(data))
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tc.name, err)
+		}
+		if tx.Safe != effectsSafe || tx.Chain != tc.chain || tx.SafeVersion != tc.version || tx.Nonce != 9 || tx.Data != "0xdeadbeef" {
+			t.Fatalf("%s: unexpected transaction: %+v", tc.name, tx)
+		}
+		if !strings.Contains(strings.Join(notes, "\n"), "assumed Safe version "+tc.version) {
+			t.Errorf("%s: the assumed version is not noted: %v", tc.name, notes)
+		}
+
+		// The transaction hashes to what the typed data is signed as
+		if tx.Chain == 0 {
+			tx.Chain = int(OPMainnetChainID)
+		}
+		result, err := VerifyTransaction(tx, VerifyOptions{})
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tc.name, err)
+		}
+		typed, err := DecodeTypedData([]byte(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if hash, err := HashTypedData(typed); err != nil || hash.Hex() != result.ApproveHash {
+			t.Errorf("%s: typed data hashes to %s (%v), the transaction to %s", tc.name, hash.Hex(), err, result.ApproveHash)
+		}
+	}
+}
+
+func TestParseSafeTransactionProposal(t *testing.T) {
+	// The body safe-eth-py and ape-safe post to the Safe transaction service
+	data := `{"safe": "` + effectsSafe + `", "to": "` + airdropAlice + `", "value": "5", "data": "0x", "operation": 0,
+		"safeTxGas": 0, "baseGas": 0, "gasPrice": 0, "gasToken": "` + ZeroAddress + `", "refundReceiver": null, "nonce": 9,
+		"contractTransactionHash": "0x1234", "sender": "` + airdropBob + `", "signature": "0x", "origin": "ape-safe"}`
+	tx, notes, err := ParseSafeTransaction([]byte(data))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if tx.ServiceSafeTxHash != "0x1234" || tx.RefundReceiver != ZeroAddress || !strings.Contains(strings.Join(notes, "\n"), `read "contractTransactionHash" as "service_safe_tx_hash"`) {
+		t.Errorf("unexpected result: %+v, %v", tx, notes)
+	}
+
+	// The proposal names neither the chain nor the Safe version
+	if err := CompleteTransaction(&tx, "op", "1.4.1"); err != nil || tx.Chain != 10 || tx.SafeVersion != "1.4.1" {
+		t.Fatalf("unexpected result: %+v, %v", tx, err)
+	}
+	if err := CompleteTransaction(&tx, "base", ""); err == nil {
+		t.Error("expected an error for a contradicting network")
+	}
+}
+
+func TestCompleteTransactionSafeVersion(t *testing.T) {
+	// A version assumed from typed data may be narrowed to one that hashes the same way
+	tx := SafeTransaction{SafeVersion: "1.3.0"}
+	if err := CompleteTransaction(&tx, "", "1.4.1"); err != nil || tx.SafeVersion != "1.4.1" {
+		t.Errorf("unexpected result: %+v, %v", tx, err)
+	}
+	tx = SafeTransaction{SafeVersion: "1.2.0"}
+	if err := CompleteTransaction(&tx, "", "1.1.1"); err != nil || tx.SafeVersion != "1.1.1" {
+		t.Errorf("unexpected result: %+v, %v", tx, err)
+	}
+	tx = SafeTransaction{SafeVersion: "1.2.0"}
+	if err := CompleteTransaction(&tx, "", "1.3.0"); err == nil {
+		t.Error("expected an error for a version that hashes differently")
+	}
+	if err := CompleteTransaction(&SafeTransaction{}, "", "9.9.9"); err == nil {
+		t.Error("expected an error for an unsupported version")
+	}
+}
@@ -0,0 +1,124 @@
+package core
+
+import (
+	"encoding/json"
+	"net/url"
+	"strings"
+)
+
+// KnownProposalOrigins are the hosts of the apps whose proposals are not flagged. The Safe
+// service records the app a proposal was made with as its origin.
+var KnownProposalOrigins = []string{"app.safe.global"}
+
+// Proposal records who proposed a transaction to the Safe service, with which app, and when.
+// None of it is hashed, and the app can name itself anything, so it only helps to spot a
+// proposal that did not come from where the signers expect.
+type Proposal struct {
+	Proposer  string `json:"proposer,omitempty"`
+	Submitted string `json:"submissionDate,omitempty"`
+
+	// OriginName and OriginURL are the app the proposal was made with, as it named itself
+	OriginName string `json:"originName,omitempty"`
+	OriginURL  string `json:"originUrl,omitempty"`
+
+	// Owners are the owners of the Safe according to the service, when it reported them
+	Owners []string `json:"owners,omitempty"`
+}
+
+// newProposal returns the proposal details of a service transaction, or nil when the service
+// reported none of them
+func newProposal(tx APITransaction, owners []string) *Proposal {
+	name, originURL := parseOrigin(tx.Origin)
+	if tx.Proposer == "" && tx.SubmissionDate == "" && name == "" && originURL == "" {
+		return nil
+	}
+	return &Proposal{
+		Proposer:   tx.Proposer,
+		Submitted:  tx.SubmissionDate,
+		OriginName: name,
+		OriginURL:  originURL,
+		Owners:     owners,
+	}
+}
+
+// parseOrigin reads the origin of a service transaction. The service stores what the app sent,
+// which is usually JSON with a name and url, itself encoded as a JSON string, and sometimes a bare
+// name.
+func parseOrigin(raw json.RawMessage) (name, originURL string) {
+	var text string
+	if err := json.Unmarshal(raw, &text); err != nil {
+		text = string(raw)
+	}
+	text = strings.TrimSpace(text)
+	if text == "" || text == "null" {
+		return "", ""
+	}
+	var origin struct {
+		Name string `json:"name"`
+		URL  string `json:"url"`
+	}
+	if err := json.Unmarshal([]byte(text), &origin); err != nil {
+		return text, ""
+	}
+	return strings.TrimSpace(origin.Name), strings.TrimSpace(origin.URL)
+}
+
+// Origin describes the app a proposal was made with, or "" when it did not say
+func (p *Proposal) Origin() string {
+	switch {
+	case p.OriginName != "" && p.OriginURL != "":
+		return p.OriginName + " (" + p.OriginURL + ")"
+	case p.OriginName != "":
+		return p.OriginName
+	}
+	return p.OriginURL
+}
+
+// ProposerIsOwner reports whether the proposer is one of the owners. ok is false when the
+// proposer or the owners are not known.
+func (p *Proposal) ProposerIsOwner() (isOwner, ok bool) {
+	if p.Proposer == "" || len(p.Owners) == 0 {
+		return false, false
+	}
+	for _, owner := range p.Owners {
+		if strings.EqualFold(owner, p.Proposer) {
+			return true, true
+		}
+	}
+	return false, true
+}
+
+// KnownOrigin reports whether the proposal was made with one of KnownProposalOrigins
+func (p *Proposal) KnownOrigin() bool {
+	parsed, err := url.Parse(p.OriginURL)
+	if err != nil || parsed.Scheme != "https" {
+		return false
+	}
+	for _, host := range KnownProposalOrigins {
+		if strings.EqualFold(parsed.Hostname(), host) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkProposal flags a proposal made by an address that is not an owner of the Safe, such as a
+// delegate or a compromised proposer key, or with an app that is not known
+func checkProposal(label string, proposal *Proposal) []Warning {
+	if proposal == nil {
+		return nil
+	}
+	var warnings []Warning
+	if isOwner, ok := proposal.ProposerIsOwner(); ok && !isOwner {
+		warnings = append(warnings, newWarning(warnProposerNotOwner,
+			"The %s was proposed by %s, which is not an owner of the Safe according to the Safe service. It may be a delegate; confirm with the owners that the proposal is expected.",
+			label, ChecksumAddress(proposal.Proposer)))
+	}
+	// Scripts and command-line tools usually name no app, which the summary shows
+	if proposal.Origin() != "" && !proposal.KnownOrigin() {
+		warnings = append(warnings, newWarning(warnUnknownOrigin,
+			"The %s was proposed with %s, which is not a known app. The app names itself, so confirm with the proposer where the proposal came from.",
+			label, proposal.Origin()))
+	}
+	return warnings
+}
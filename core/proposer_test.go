@@ -0,0 +1,72 @@
+package core
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestParseOrigin(t *testing.T) {
+	for raw, want := range map[string][2]string{
+		`"{\"url\":\"https://app.safe.global\",\"name\":\"Safe{Wallet}\"}"`: {"Safe{Wallet}", "https://app.safe.global"},
+		`"{}"`:             {"", ""},
+		`"safe-cli"`:       {"safe-cli", ""},
+		`null`:             {"", ""},
+		`""`:               {"", ""},
+		`{"name": "Tool"}`: {"Tool", ""},
+	} {
+		name, originURL := parseOrigin(json.RawMessage(raw))
+		if name != want[0] || originURL != want[1] {
+			t.Errorf("parseOrigin(%s) = %q, %q, want %q, %q", raw, name, originURL, want[0], want[1])
+		}
+	}
+}
+
+func TestProposalOwnerAndOrigin(t *testing.T) {
+	proposal := Proposal{
+		Proposer:  "0x9a69d97a451643a0bb4462476942d2bc844431ce",
+		Owners:    []string{"0x9a69d97a451643a0Bb4462476942D2bC844431cE"},
+		OriginURL: "https://app.safe.global",
+	}
+	if isOwner, ok := proposal.ProposerIsOwner(); !ok || !isOwner {
+		t.Errorf("expected the proposer to be an owner regardless of case")
+	}
+	if !proposal.KnownOrigin() {
+		t.Errorf("expected %s to be a known app", proposal.OriginURL)
+	}
+	if warnings := checkProposal("transaction", &proposal); len(warnings) != 0 {
+		t.Errorf("expected no warnings, got %+v", warnings)
+	}
+
+	for _, originURL := range []string{"http://app.safe.global", "https://app.safe.global.example.com", "app.safe.global"} {
+		if (&Proposal{OriginURL: originURL}).KnownOrigin() {
+			t.Errorf("expected %s not to be a known app", originURL)
+		}
+	}
+	if _, ok := (&Proposal{Proposer: proposal.Proposer}).ProposerIsOwner(); ok {
+		t.Errorf("expected ownership to be unknown without the owners")
+	}
+}
+
+func TestCheckProposal(t *testing.T) {
+	warnings := checkProposal("child transaction", &Proposal{
+		Proposer:   "0x3041ba32f451f5850c147805f5521ac206421623",
+		Owners:     []string{"0x9a69d97a451643a0Bb4462476942D2bC844431cE"},
+		OriginName: "Drainer",
+		OriginURL:  "https://app.safe-global.example.com",
+	})
+	if len(warnings) != 2 {
+		t.Fatalf("expected a non-owner and an unknown app warning, got %+v", warnings)
+	}
+	if !strings.Contains(warnings[0].Message, "child transaction was proposed by 0x3041") || !strings.Contains(warnings[0].Message, "not an owner") {
+		t.Errorf("unexpected non-owner warning: %s", warnings[0].Message)
+	}
+	if !strings.Contains(warnings[1].Message, "Drainer (https://app.safe-global.example.com), which is not a known app") {
+		t.Errorf("unexpected unknown app warning: %s", warnings[1].Message)
+	}
+
+	// A script names no app, which is not flagged
+	if warnings := checkProposal("transaction", &Proposal{Submitted: "2024-01-01T00:00:00Z"}); len(warnings) != 0 {
+		t.Errorf("expected no warnings without an origin, got %+v", warnings)
+	}
+}
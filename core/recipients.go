@@ -0,0 +1,155 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// safeCalls returns the calls a Safe makes itself: the transaction's call, or the calls of a
+// MultiSend batch. Calls decoded further down are made by other contracts and are left out.
+func safeCalls(result *VerificationResult) []CallData {
+	if SafeMultisendAddresses[strings.ToLower(StripChainPrefix(result.Call.Target))] {
+		return result.Call.SubCalls
+	}
+	return []CallData{result.Call}
+}
+
+// checkSelfCalls warns about every call a Safe makes to itself with calldata. Such calls
+// administer the Safe, changing its owners, threshold, modules, guard, or fallback handler, and
+// are easy to miss inside a batch. A rejection makes an empty call to the Safe and is not warned
+// about.
+func checkSelfCalls(result *VerificationResult) []Warning {
+	var warnings []Warning
+	for _, r := range []*VerificationResult{result.NestedResult, result} {
+		if r == nil {
+			continue
+		}
+		safe := ChecksumAddress(StripChainPrefix(r.Transaction.Safe))
+		for _, call := range safeCalls(r) {
+			if !strings.EqualFold(StripChainPrefix(call.Target), safe) || call.RawData == "0x" || call.FunctionName == RejectionFunctionName {
+				continue
+			}
+			name := "The transaction"
+			if call.Index != "" {
+				name = "Call #" + call.Index
+			}
+			warnings = append(warnings, newWarning(warnSelfCall,
+				"%s calls the Safe %s itself (%s). Calls from a Safe to itself change its configuration, such as its owners, threshold, modules, guard, or fallback handler; make sure this change is intended.",
+				name, safe, call.FunctionName))
+		}
+	}
+	return warnings
+}
+
+// ethTransfer is ETH a Safe sends without calldata
+type ethTransfer struct {
+	name string
+	to   string
+	call multiSendTransaction
+}
+
+// ethTransfers returns the ETH a not yet executed transaction sends without calldata, which the
+// recipient only accepts if it is an account or a contract with a payable receive or fallback
+// function
+func ethTransfers(r *VerificationResult) ([]ethTransfer, error) {
+	if r.Transaction.Execution != nil {
+		return nil, nil
+	}
+	calls, err := batchCalls(r.Transaction)
+	if err != nil {
+		return nil, err
+	}
+	var transfers []ethTransfer
+	for i, call := range calls {
+		if call.Operation != 0 || call.Value == nil || call.Value.Sign() == 0 || len(call.Data) != 0 {
+			continue
+		}
+		name := "The transaction"
+		if len(calls) > 1 {
+			name = fmt.Sprintf("Call #%d", i+1)
+		}
+		transfers = append(transfers, ethTransfer{name: name, to: call.To.Hex(), call: call})
+	}
+	return transfers, nil
+}
+
+// SendsETH reports whether a result, or the child of a nested approval, sends ETH without
+// calldata and has not been executed yet
+func SendsETH(result *VerificationResult) bool {
+	for _, r := range []*VerificationResult{result, result.NestedResult} {
+		if r == nil {
+			continue
+		}
+		if transfers, err := ethTransfers(r); err == nil && len(transfers) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckETHRecipients simulates every plain ETH transfer of a transaction that sends it to a
+// contract, and warns about the recipients that reject it. Such a transfer reverts, failing the
+// whole transaction or leaving the ETH with the Safe, a frequent surprise when sending to
+// multisigs, bridges, or token contracts. Without a client the recipients are noted as not
+// checked.
+func CheckETHRecipients(ctx context.Context, client *RPCClient, result *VerificationResult) error {
+	checkedChain := false
+	for _, r := range []*VerificationResult{result.NestedResult, result} {
+		if r == nil {
+			continue
+		}
+		transfers, err := ethTransfers(r)
+		if err != nil {
+			return err
+		}
+		if len(transfers) == 0 {
+			continue
+		}
+		safe := ChecksumAddress(StripChainPrefix(r.Transaction.Safe))
+
+		if client == nil {
+			result.Warnings = append(result.Warnings, newWarning(warnETHUnchecked,
+				"%s sends ETH; whether the recipients accept it was not checked because no RPC endpoint was given", safe))
+			continue
+		}
+
+		if !checkedChain {
+			chainID, err := client.ChainID(ctx)
+			if err != nil {
+				return fmt.Errorf("error checking ETH recipients: %w", err)
+			}
+			if chainID != uint64(result.Transaction.Chain) {
+				return fmt.Errorf("RPC endpoint is on chain %d but the transaction is on chain %d", chainID, result.Transaction.Chain)
+			}
+			checkedChain = true
+		}
+
+		for _, transfer := range transfers {
+			code, err := client.Code(ctx, transfer.to, "latest")
+			if err != nil {
+				return fmt.Errorf("error reading the code of %s: %w", transfer.to, err)
+			}
+			if len(code) == 0 {
+				continue
+			}
+
+			_, err = client.SimulateCall(ctx, safe, transfer.to, transfer.call.Value, nil, "latest")
+			var rpcErr *RPCError
+			switch {
+			case err == nil:
+			case errors.As(err, &rpcErr) && rpcErr.Reverted():
+				result.Warnings = append(result.Warnings, newWarning(warnETHRejected,
+					"%s sends %s ETH from %s to the contract %s, which rejects plain ETH transfers (%s). It has no payable receive or fallback function, so the transfer reverts.",
+					transfer.name, ParseDecimals(transfer.call.Value, 18), safe, transfer.to, rpcErr.Message))
+			case ctx.Err() != nil:
+				return ctx.Err()
+			default:
+				result.Warnings = append(result.Warnings, newWarning(warnETHCheckFailed,
+					"could not check whether the contract %s accepts ETH: %v", transfer.to, err))
+			}
+		}
+	}
+	return nil
+}
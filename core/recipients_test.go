@@ -0,0 +1,96 @@
+package core
+
+import (
+	"context"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// warningsContaining returns the warnings of a severity whose message contains text
+func warningsContaining(warnings []Warning, severity Severity, text string) []Warning {
+	var matching []Warning
+	for _, warning := range warnings {
+		if warning.Severity == severity && strings.Contains(warning.Message, text) {
+			matching = append(matching, warning)
+		}
+	}
+	return matching
+}
+
+func TestCheckSelfCalls(t *testing.T) {
+	result := ownerResult(t,
+		nativeTransfer(airdropBob, big.NewInt(1)),
+		ownerCall(t, "changeThreshold(uint256)", big.NewInt(2)),
+		nativeTransfer(effectsSafe, big.NewInt(1)),
+	)
+	warnings := warningsContaining(result.Warnings, SeverityWarning, "itself")
+	if len(warnings) != 1 || !strings.Contains(warnings[0].Message, "Call #2") || !strings.Contains(warnings[0].Message, "changeThreshold") {
+		t.Fatalf("expected a warning about call #2 only, got %+v", result.Warnings)
+	}
+
+	// A rejection is an empty call to the Safe and is not self-administration
+	rejection, err := VerifyTransaction(SafeTransaction{
+		Safe: effectsSafe, SafeVersion: "1.3.0", Chain: MainnetChainID, To: effectsSafe, Value: big.NewInt(0),
+		Data: "0x", GasToken: ZeroAddress, RefundReceiver: ZeroAddress,
+	}, VerifyOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if warnings := warningsContaining(rejection.Warnings, SeverityWarning, "itself"); len(warnings) != 0 {
+		t.Errorf("unexpected warnings for a rejection: %+v", warnings)
+	}
+}
+
+func TestCheckETHRecipients(t *testing.T) {
+	const (
+		acceptingContract  = "0x5555555555555555555555555555555555555555"
+		rejectingContract  = "0x6666666666666666666666666666666666666666"
+		transferValue      = 1500000000000000000
+		rejectedTransferID = "Call #3"
+	)
+	node := &fakeNode{
+		chainID: MainnetChainID,
+		code: map[string]hexutil.Bytes{
+			acceptingContract: {0x60, 0x80},
+			rejectingContract: {0x60, 0x80},
+		},
+		calls: map[string]hexutil.Bytes{acceptingContract + ":0x": {}},
+	}
+	client := newFakeNode(t, node)
+
+	result := ownerResult(t,
+		nativeTransfer(airdropBob, big.NewInt(transferValue)),
+		nativeTransfer(acceptingContract, big.NewInt(transferValue)),
+		nativeTransfer(rejectingContract, big.NewInt(transferValue)),
+		erc20Transfer(airdropToken, rejectingContract, big.NewInt(5)),
+	)
+	if !SendsETH(result) {
+		t.Fatalf("expected the batch to send ETH")
+	}
+	if err := CheckETHRecipients(context.Background(), client, result); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	warnings := warningsContaining(result.Warnings, SeverityWarning, "rejects plain ETH transfers")
+	if len(warnings) != 1 || !strings.Contains(warnings[0].Message, rejectedTransferID) ||
+		!strings.Contains(warnings[0].Message, "1.5 ETH") || !strings.Contains(warnings[0].Message, "0x6666666666666666666666666666666666666666") {
+		t.Fatalf("expected a warning about the rejecting contract only, got %+v", result.Warnings)
+	}
+
+	// Without a node the recipients are only noted as not checked
+	result = ownerResult(t, nativeTransfer(rejectingContract, big.NewInt(transferValue)))
+	if err := CheckETHRecipients(context.Background(), nil, result); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(warningsContaining(result.Warnings, SeverityInfo, "not checked")) != 1 {
+		t.Errorf("expected a note that the recipients were not checked, got %+v", result.Warnings)
+	}
+
+	// Executed transactions are not simulated again
+	result.Transaction.Execution = &Execution{BlockNumber: 10}
+	if SendsETH(result) {
+		t.Errorf("did not expect an executed transaction to be checked")
+	}
+}
@@ -0,0 +1,352 @@
+package core
+
+import (
+	"math/big"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Placeholders for the values RedactResult masks
+const (
+	RedactedAddress = "[redacted address]"
+	RedactedAmount  = "[redacted amount]"
+	RedactedData    = "[redacted data]"
+)
+
+// addressPattern and amountPattern match addresses and amounts in free text, such as warning
+// messages and the fields of summarized calls
+var (
+	addressPattern = regexp.MustCompile(`0x[0-9a-fA-F]{40}\b`)
+	amountPattern  = regexp.MustCompile(`\b\d+(\.\d+)?\b`)
+)
+
+// RedactResult returns a copy of a result that can be shared publicly before a confidential
+// transaction executes. Recipient addresses, that is every address other than the Safes, the
+// zero address, and known contracts, are masked, as are ETH values, integer arguments wider than
+// 32 bits, byte strings, and raw calldata. The hashes, call structure, function names, and
+// known targets are kept so others can still compare them. The value flows, execution effects,
+// changes since an earlier nonce, and argument annotations name recipients and amounts and are
+// left out. The result itself is not modified.
+func RedactResult(result *VerificationResult) *VerificationResult {
+	if result == nil {
+		return nil
+	}
+	chainID := uint64(result.Transaction.Chain)
+	kept := map[string]bool{strings.ToLower(ZeroAddress): true}
+	for _, r := range []*VerificationResult{result, result.NestedResult} {
+		if r != nil {
+			kept[strings.ToLower(StripChainPrefix(r.Transaction.Safe))] = true
+		}
+	}
+	keep := func(address string) bool {
+		address = strings.ToLower(StripChainPrefix(address))
+		if kept[address] || SafeMultisendAddresses[address] {
+			return true
+		}
+		_, known := KnownAddresses.Lookup(address, chainID)
+		return known
+	}
+	return redactResult(result, keep)
+}
+
+// redactResult redacts a result and its nested result, keeping the addresses keep accepts
+func redactResult(result *VerificationResult, keep func(string) bool) *VerificationResult {
+	redacted := *result
+	redacted.Redacted = true
+	redacted.Transaction = redactTransaction(result.Transaction, keep)
+	redacted.Call = redactCall(result.Call, keep)
+	redacted.NestedResult = nil
+	if result.NestedResult != nil {
+		redacted.NestedResult = redactResult(result.NestedResult, keep)
+	}
+	redacted.Compliance = nil
+	redacted.PreviousDiff = nil
+	if result.Review != nil {
+		redacted.Review = redactReview(result.Review, keep)
+	}
+
+	redacted.Warnings = make([]Warning, len(result.Warnings))
+	for i, warning := range result.Warnings {
+		warning.Message = redactAddresses(warning.Message, keep)
+		redacted.Warnings[i] = warning
+	}
+	return &redacted
+}
+
+// redactAddresses masks the addresses in a text unless keep accepts them
+func redactAddresses(text string, keep func(string) bool) string {
+	return addressPattern.ReplaceAllStringFunc(text, func(address string) string {
+		return redactAddress(address, keep)
+	})
+}
+
+// redactSummaryText masks the addresses and amounts in a field of a summarized call, such as
+// "1.5 USDC (1500000 raw)". Numbers are masked after the addresses, so those that are kept are
+// not touched.
+func redactSummaryText(text string, keep func(string) bool) string {
+	text = redactAddresses(text, keep)
+	var redacted strings.Builder
+	last := 0
+	for _, match := range amountPattern.FindAllStringIndex(text, -1) {
+		// Digits within a kept address or hash follow other hex characters and never match
+		redacted.WriteString(text[last:match[0]])
+		redacted.WriteString(RedactedAmount)
+		last = match[1]
+	}
+	redacted.WriteString(text[last:])
+	return redacted.String()
+}
+
+// redactTransaction masks the recipient, value, and calldata of a transaction
+func redactTransaction(tx SafeTransaction, keep func(string) bool) SafeTransaction {
+	tx.To = redactAddress(tx.To, keep)
+	if tx.Value != nil && tx.Value.Sign() != 0 {
+		tx.Value = nil
+	}
+	tx.Data = redactData(tx.Data)
+	tx.Call = redactCall(tx.Call, keep)
+	if tx.Nested != nil {
+		nested := *tx.Nested
+		nested.To = redactAddress(nested.To, keep)
+		nested.Data = redactData(nested.Data)
+		tx.Nested = &nested
+	}
+	if tx.Execution != nil {
+		execution := *tx.Execution
+		execution.Effects = nil
+		tx.Execution = &execution
+	}
+	return tx
+}
+
+// redactCall masks the target, value, raw calldata, and arguments of a call, its subcalls, and
+// the L2 transaction it deposits
+func redactCall(call CallData, keep func(string) bool) CallData {
+	if !keep(call.Target) {
+		call.Target = redactAddress(call.Target, keep)
+		call.TargetLabel = ""
+	}
+	if call.Value != nil && call.Value.Sign() != 0 {
+		call.Value = nil
+	}
+	if call.RawData != "" {
+		call.RawData = redactData(call.RawData)
+	}
+	call.Annotations = nil
+	if call.Review != nil && call.Review.Note != "" {
+		review := *call.Review
+		review.Note = ""
+		call.Review = &review
+	}
+	if call.Deposit != nil {
+		deposit := *call.Deposit
+		deposit.To = redactAddress(deposit.To, keep)
+		if deposit.Mint != nil && deposit.Mint.Sign() != 0 {
+			deposit.Mint = nil
+		}
+		if deposit.Value != nil && deposit.Value.Sign() != 0 {
+			deposit.Value = nil
+		}
+		if deposit.Call != nil {
+			l2Call := redactCall(*deposit.Call, keep)
+			deposit.Call = &l2Call
+		}
+		call.Deposit = &deposit
+	}
+
+	if call.ParsedData != nil {
+		args := make([]Argument, len(call.ParsedData))
+		for i, arg := range call.ParsedData {
+			var changed bool
+			if data, ok := arg.Value.(string); ok && arg.Type == "bytes" {
+				arg.Value, changed = redactData(data), data != "0x"
+			} else if address, ok := bytes32ArgAddress(arg); ok && !keep(address.Hex()) {
+				// A bytes32 that holds a padded address names a recipient as much as an address does
+				arg.Value, changed = RedactedAddress, true
+			} else {
+				arg.Value, changed = redactValue(reflect.ValueOf(arg.Value), keep, false, 0)
+			}
+			if changed {
+				arg.Display = ""
+			}
+			args[i] = arg
+		}
+		call.ParsedData = args
+	}
+	if call.Summary != nil {
+		// The fields of a summary are text that names addresses
+		summary := make([]Argument, len(call.Summary))
+		for i, field := range call.Summary {
+			field.Value, _ = redactValue(reflect.ValueOf(field.Value), keep, true, 0)
+			summary[i] = field
+		}
+		call.Summary = summary
+	}
+	if call.SubCalls != nil {
+		subcalls := make([]CallData, len(call.SubCalls))
+		for i, subcall := range call.SubCalls {
+			subcalls[i] = redactCall(subcall, keep)
+		}
+		call.SubCalls = subcalls
+	}
+	return call
+}
+
+// bytes32ArgAddress returns the address a bytes32 argument holds when it is a left-padded address
+func bytes32ArgAddress(arg Argument) (common.Address, bool) {
+	value, ok := arg.Value.(string)
+	if !ok || arg.Type != "bytes32" {
+		return common.Address{}, false
+	}
+	return bytes32Address(common.HexToHash(value))
+}
+
+// redactAddress masks an address unless keep accepts it
+func redactAddress(address string, keep func(string) bool) string {
+	if address == "" || keep(address) {
+		return address
+	}
+	return RedactedAddress
+}
+
+// redactData masks calldata or a byte string, keeping an empty one as it is
+func redactData(data string) string {
+	if data == "" || data == "0x" {
+		return data
+	}
+	return RedactedData
+}
+
+// redactValue masks the recipients and amounts within a decoded value and reports whether it
+// changed. Arrays become slices and tuples structs of the same field names, so the shape of the
+// value is kept. The text of a summarized call also has its amounts masked.
+func redactValue(value reflect.Value, keep func(string) bool, summary bool, depth int) (interface{}, bool) {
+	for value.IsValid() && value.Kind() == reflect.Interface {
+		value = value.Elem()
+	}
+	if !value.IsValid() {
+		return nil, false
+	}
+	original := value.Interface()
+	if depth > 8 {
+		return RedactedData, true
+	}
+
+	switch v := original.(type) {
+	case common.Address:
+		if keep(v.Hex()) {
+			return v, false
+		}
+		return RedactedAddress, true
+	case *big.Int:
+		if v == nil {
+			return v, false
+		}
+		return RedactedAmount, true
+	case []byte:
+		return redactData("0x" + common.Bytes2Hex(v)), len(v) > 0
+	case string:
+		if summary {
+			redacted := redactSummaryText(v, keep)
+			return redacted, redacted != v
+		}
+		redacted := redactAddresses(v, keep)
+		return redacted, redacted != v
+	}
+
+	switch value.Kind() {
+	case reflect.Int64, reflect.Uint64, reflect.Int, reflect.Uint:
+		return RedactedAmount, true
+	case reflect.Pointer:
+		if value.IsNil() {
+			return original, false
+		}
+		return redactValue(value.Elem(), keep, summary, depth+1)
+	case reflect.Array, reflect.Slice:
+		// Fixed byte arrays, such as bytes32 identifiers, are kept
+		if value.Type().Elem().Kind() == reflect.Uint8 {
+			return original, false
+		}
+		items := make([]interface{}, value.Len())
+		changed := false
+		for i := range items {
+			var itemChanged bool
+			items[i], itemChanged = redactValue(value.Index(i), keep, summary, depth+1)
+			changed = changed || itemChanged
+		}
+		if !changed {
+			return original, false
+		}
+		return items, true
+	case reflect.Struct:
+		var fields []reflect.StructField
+		var values []interface{}
+		changed := false
+		for i := 0; i < value.NumField(); i++ {
+			field := value.Type().Field(i)
+			if field.PkgPath != "" {
+				continue
+			}
+			item, itemChanged := redactValue(value.Field(i), keep, summary, depth+1)
+			changed = changed || itemChanged
+			fields = append(fields, reflect.StructField{
+				Name: field.Name,
+				Type: reflect.TypeOf((*interface{})(nil)).Elem(),
+				Tag:  field.Tag,
+			})
+			values = append(values, item)
+		}
+		if !changed {
+			return original, false
+		}
+		redacted := reflect.New(reflect.StructOf(fields)).Elem()
+		for i, item := range values {
+			if item != nil {
+				redacted.Field(i).Set(reflect.ValueOf(item))
+			}
+		}
+		return redacted.Interface(), true
+	case reflect.Map:
+		if value.Type().Key().Kind() != reflect.String {
+			return original, false
+		}
+		items := make(map[string]interface{}, value.Len())
+		changed := false
+		for _, key := range value.MapKeys() {
+			var itemChanged bool
+			items[key.String()], itemChanged = redactValue(value.MapIndex(key), keep, summary, depth+1)
+			changed = changed || itemChanged
+		}
+		if !changed {
+			return original, false
+		}
+		return items, true
+	}
+	return original, false
+}
+
+// redactReview copies a review record without the reviewer's notes, which may name recipients
+// and amounts, and with the addresses in acknowledged warnings masked
+func redactReview(review *SessionTransaction, keep func(string) bool) *SessionTransaction {
+	redacted := *review
+	redacted.Reviews = make([]CallReview, len(review.Reviews))
+	for i, call := range review.Reviews {
+		call.Note = ""
+		redacted.Reviews[i] = call
+	}
+	redacted.Sections = make([]SectionReview, len(review.Sections))
+	for i, section := range review.Sections {
+		section.Note = ""
+		redacted.Sections[i] = section
+	}
+	redacted.Acknowledged = make([]Acknowledgement, len(review.Acknowledged))
+	for i, ack := range review.Acknowledged {
+		ack.Message = redactAddresses(ack.Message, keep)
+		ack.Note = ""
+		redacted.Acknowledged[i] = ack
+	}
+	return &redacted
+}
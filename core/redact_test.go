@@ -0,0 +1,135 @@
+package core
+
+import (
+	"encoding/json"
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestRedactResult(t *testing.T) {
+	result := ownerResult(t,
+		nativeTransfer(airdropAlice, big.NewInt(1e18)),
+		erc20Transfer(airdropToken, airdropBob, big.NewInt(3e17)),
+		ownerCall(t, "changeThreshold(uint256)", big.NewInt(2)),
+	)
+	result.Warnings = append(result.Warnings, newWarning(warnETHUnchecked, "%s has not received from this Safe before", airdropAlice))
+	original, err := json.Marshal(result)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	redacted := RedactResult(result)
+	if !redacted.Redacted || redacted.ApproveHash != result.ApproveHash || redacted.DomainHash != result.DomainHash || redacted.MessageHash != result.MessageHash {
+		t.Fatalf("expected a redacted copy with the same hashes, got %+v", redacted)
+	}
+	if after, _ := json.Marshal(result); string(after) != string(original) {
+		t.Error("redacting modified the original result")
+	}
+
+	data, err := json.Marshal(redacted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, leaked := range []string{airdropAlice[2:], airdropBob[2:], airdropToken[2:], "1000000000000000000", "300000000000000000"} {
+		if strings.Contains(strings.ToLower(string(data)), leaked) {
+			t.Errorf("redacted result contains %q:\n%s", leaked, data)
+		}
+	}
+	if redacted.Transaction.Data != RedactedData {
+		t.Errorf("expected the calldata to be masked, got %q", redacted.Transaction.Data)
+	}
+
+	// The structure, the Safe, and known contracts are kept
+	calls := redacted.Call.SubCalls
+	if len(calls) != 3 || calls[1].FunctionName != "transfer" || calls[2].FunctionName != "changeThreshold" {
+		t.Fatalf("expected the three calls to be kept, got %+v", calls)
+	}
+	if calls[0].Target != RedactedAddress || calls[0].Value != nil || calls[1].Target != RedactedAddress {
+		t.Errorf("expected the recipients and value to be masked, got %+v", calls[:2])
+	}
+	if calls[2].Target != result.Call.SubCalls[2].Target || !strings.EqualFold(redacted.Call.Target, result.Call.Target) {
+		t.Errorf("expected the Safe and MultiSend targets to be kept, got %s and %s", calls[2].Target, redacted.Call.Target)
+	}
+	if to, ok := calls[1].Argument("to"); !ok || to.Value != RedactedAddress {
+		t.Errorf("expected the token recipient to be masked, got %+v", to)
+	}
+	if amount, ok := calls[2].Argument("threshold"); !ok || amount.Value != RedactedAmount {
+		t.Errorf("expected the threshold to be masked as an amount, got %+v", amount)
+	}
+	if len(warningsContaining(redacted.Warnings, SeverityWarning, effectsSafe)) != 1 || len(warningsContaining(redacted.Warnings, SeverityInfo, RedactedAddress)) != 1 {
+		t.Errorf("expected the Safe to be kept and the recipient masked in warnings, got %+v", redacted.Warnings)
+	}
+}
+
+func TestRedactValue(t *testing.T) {
+	type transfer struct {
+		Recipient common.Address `json:"recipient"`
+		Amount    *big.Int       `json:"amount"`
+		Domain    uint32         `json:"domain"`
+	}
+	keep := func(address string) bool { return strings.EqualFold(address, airdropToken) }
+
+	value, changed := redactValue(reflect.ValueOf([]transfer{
+		{Recipient: common.HexToAddress(airdropAlice), Amount: big.NewInt(5), Domain: 6},
+		{Recipient: common.HexToAddress(airdropToken), Amount: big.NewInt(7), Domain: 8},
+	}), keep, false, 0)
+	if !changed {
+		t.Fatal("expected the value to change")
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `[{"recipient":"[redacted address]","amount":"[redacted amount]","domain":6},` +
+		`{"recipient":"` + common.HexToAddress(airdropToken).Hex() + `","amount":"[redacted amount]","domain":8}]`
+	if string(data) != want {
+		t.Errorf("expected %s, got %s", want, data)
+	}
+
+	// Tuple fields keep their Solidity names and order
+	if field := reflect.TypeOf(value.([]interface{})[0]).Field(0); TupleFieldName(field) != "recipient" {
+		t.Errorf("expected the first field to be recipient, got %s", TupleFieldName(field))
+	}
+
+	if value, changed := redactValue(reflect.ValueOf([]uint16{1, 2}), keep, false, 0); changed || !reflect.DeepEqual(value, []uint16{1, 2}) {
+		t.Errorf("expected a short integer array to be kept, got %v", value)
+	}
+}
+
+func TestRedactSummaryText(t *testing.T) {
+	keep := func(address string) bool { return strings.EqualFold(address, airdropToken) }
+	got := redactSummaryText("1.5 USDC (1500000 raw) from "+airdropToken+" to "+airdropAlice+" via Uniswap V3", keep)
+	want := "[redacted amount] USDC ([redacted amount] raw) from " + airdropToken + " to [redacted address] via Uniswap V3"
+	if got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
+
+func TestRedactCallBytes32(t *testing.T) {
+	keep := func(address string) bool { return strings.EqualFold(address, airdropToken) }
+	padded := func(address string) string { return common.BytesToHash(common.HexToAddress(address).Bytes()).Hex() }
+	role := crypto.Keccak256Hash([]byte("PAUSER_ROLE")).Hex()
+	call := CallData{
+		Target: airdropToken,
+		ParsedData: []Argument{
+			{Name: "mintRecipient", Type: "bytes32", Value: padded(airdropAlice), Display: padded(airdropAlice) + " (address " + airdropAlice + ")"},
+			{Name: "destinationCaller", Type: "bytes32", Value: padded(airdropToken), Display: padded(airdropToken) + " (address " + airdropToken + ")"},
+			{Name: "role", Type: "bytes32", Value: role, Display: role + " (PAUSER_ROLE)"},
+		},
+	}
+
+	redacted := redactCall(call, keep)
+	if arg := redacted.ParsedData[0]; arg.Value != RedactedAddress || arg.Display != "" {
+		t.Errorf("expected the padded recipient to be masked, got %+v", arg)
+	}
+	for i := 1; i < 3; i++ {
+		if redacted.ParsedData[i] != call.ParsedData[i] {
+			t.Errorf("expected %s to be kept, got %+v", call.ParsedData[i].Name, redacted.ParsedData[i])
+		}
+	}
+}
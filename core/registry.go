@@ -0,0 +1,221 @@
+package core
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// RegistrySources are the signed files a registry sync downloads. Each file is signed with an
+// Ethereum personal_sign signature over its contents, published next to it with a ".sig" suffix.
+type RegistrySources struct {
+	Labels string `json:"labels,omitempty"`
+	Tokens string `json:"tokens,omitempty"`
+	ABIs   string `json:"abis,omitempty"`
+	Hashes string `json:"hashes,omitempty"`
+
+	// Signers are the addresses trusted to sign the files
+	Signers []string `json:"signers"`
+}
+
+// Validate checks that the sources use https and that they have trusted signers
+func (s RegistrySources) Validate() error {
+	for _, url := range []string{s.Labels, s.Tokens, s.ABIs, s.Hashes} {
+		if url != "" && !strings.HasPrefix(url, "https://") {
+			return fmt.Errorf("registry source URL must use https: %s", url)
+		}
+	}
+	if len(s.Signers) == 0 {
+		return fmt.Errorf("registry sources need at least one trusted signer")
+	}
+	for _, signer := range s.Signers {
+		if !strings.HasPrefix(signer, "0x") || ValidateFullAddress("registry signer", signer) != nil {
+			return fmt.Errorf("invalid registry signer address %q", signer)
+		}
+	}
+	return nil
+}
+
+// RegistryLabel names a contract on a chain, such as a superchain-registry deployment
+type RegistryLabel struct {
+	ChainID uint64 `json:"chainId"`
+	Address string `json:"address"`
+	Name    string `json:"name"`
+}
+
+// RegistryToken is a token list entry
+type RegistryToken struct {
+	ChainID  uint64 `json:"chainId"`
+	Address  string `json:"address"`
+	Symbol   string `json:"symbol"`
+	Name     string `json:"name,omitempty"`
+	Decimals int    `json:"decimals"`
+}
+
+// RegistryHash names a bytes32 value, such as a fault proof absolute prestate
+type RegistryHash struct {
+	Hash string `json:"hash"`
+	Name string `json:"name"`
+}
+
+// Registry is the local cache of synced labels, tokens, ABIs, and hashes. Its entries extend the
+// built-in contracts, functions, and hashes and never replace them.
+type Registry struct {
+	Labels []RegistryLabel   `json:"labels,omitempty"`
+	Tokens []RegistryToken   `json:"tokens,omitempty"`
+	ABIs   []json.RawMessage `json:"abis,omitempty"`
+	Hashes []RegistryHash    `json:"hashes,omitempty"`
+}
+
+// LoadRegistryCache loads a registry cache. A missing cache is an empty registry.
+func LoadRegistryCache(path string) (*Registry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return &Registry{}, nil
+		}
+		return nil, fmt.Errorf("failed to read registry cache: %w", err)
+	}
+	var registry Registry
+	if err := json.Unmarshal(data, &registry); err != nil {
+		return nil, fmt.Errorf("invalid registry cache %s: %w", path, err)
+	}
+	return &registry, nil
+}
+
+// Save writes the registry cache
+func (r *Registry) Save(path string) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode registry: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create registry cache directory: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write registry cache: %w", err)
+	}
+	return nil
+}
+
+// ParseRegistryLabels parses a label file: {"labels": [{"chainId": 10, "address": "0x...", "name": "..."}]}
+func ParseRegistryLabels(source string, data []byte) ([]RegistryLabel, error) {
+	var manifest struct {
+		Labels []RegistryLabel `json:"labels"`
+	}
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("invalid label file %s: %w", source, err)
+	}
+	for i, label := range manifest.Labels {
+		if label.ChainID == 0 || label.Name == "" || !strings.HasPrefix(label.Address, "0x") || ValidateFullAddress("label", label.Address) != nil {
+			return nil, fmt.Errorf("%s: invalid label %d: %+v", source, i, label)
+		}
+		manifest.Labels[i].Address = ChecksumAddress(label.Address)
+	}
+	return manifest.Labels, nil
+}
+
+// ParseTokenList parses a token list in the Uniswap token list format:
+// {"name": "...", "tokens": [{"chainId": 1, "address": "0x...", "symbol": "...", "decimals": 18}]}
+func ParseTokenList(source string, data []byte) ([]RegistryToken, error) {
+	var list struct {
+		Tokens []RegistryToken `json:"tokens"`
+	}
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("invalid token list %s: %w", source, err)
+	}
+	for i, token := range list.Tokens {
+		if token.ChainID == 0 || token.Symbol == "" || token.Decimals < 0 || token.Decimals > 77 ||
+			!strings.HasPrefix(token.Address, "0x") || ValidateFullAddress("token", token.Address) != nil {
+			return nil, fmt.Errorf("%s: invalid token %d: %+v", source, i, token)
+		}
+		list.Tokens[i].Address = ChecksumAddress(token.Address)
+	}
+	return list.Tokens, nil
+}
+
+// ParseABIManifest parses an ABI manifest, a list of JSON ABIs: {"abis": [[{"type": "function", ...}]]}
+func ParseABIManifest(source string, data []byte) ([]json.RawMessage, error) {
+	var manifest struct {
+		ABIs []json.RawMessage `json:"abis"`
+	}
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("invalid ABI manifest %s: %w", source, err)
+	}
+	for i, abiJSON := range manifest.ABIs {
+		parsed, err := abi.JSON(strings.NewReader(string(abiJSON)))
+		if err != nil {
+			return nil, fmt.Errorf("%s: invalid ABI %d: %w", source, i, err)
+		}
+		if len(parsed.Methods) == 0 {
+			return nil, fmt.Errorf("%s: ABI %d has no functions", source, i)
+		}
+	}
+	return manifest.ABIs, nil
+}
+
+// ParseRegistryHashes parses a hash file: {"hashes": [{"hash": "0x...", "name": "..."}]}
+func ParseRegistryHashes(source string, data []byte) ([]RegistryHash, error) {
+	var manifest struct {
+		Hashes []RegistryHash `json:"hashes"`
+	}
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("invalid hash file %s: %w", source, err)
+	}
+	for i, hash := range manifest.Hashes {
+		decoded, err := hex.DecodeString(strings.TrimPrefix(hash.Hash, "0x"))
+		if hash.Name == "" || !strings.HasPrefix(hash.Hash, "0x") || err != nil || len(decoded) != 32 {
+			return nil, fmt.Errorf("%s: invalid hash %d: %+v", source, i, hash)
+		}
+		manifest.Hashes[i].Hash = strings.ToLower(hash.Hash)
+	}
+	return manifest.Hashes, nil
+}
+
+// Apply adds the registry's labels and tokens to KnownAddresses, its ABIs to the known
+// functions, and its hashes to KnownHashes. Built-in contracts, functions, and hashes are never
+// replaced: a label that names a known
+// address differently is returned as a conflict instead.
+func (r *Registry) Apply() []AddressConflict {
+	var conflicts []AddressConflict
+	add := func(entry AddressEntry) {
+		if _, supported := ChainNames[entry.ChainID]; !supported {
+			return
+		}
+		var conflict *AddressConflict
+		if err := KnownAddresses.Register(entry); errors.As(err, &conflict) {
+			conflicts = append(conflicts, *conflict)
+		}
+	}
+	for _, token := range r.Tokens {
+		add(AddressEntry{Address: token.Address, ChainID: token.ChainID, Name: strings.ToUpper(token.Symbol), Decimals: token.Decimals, Source: SourceRegistry})
+	}
+	for _, label := range r.Labels {
+		add(AddressEntry{Address: label.Address, ChainID: label.ChainID, Name: label.Name, Source: SourceRegistry})
+	}
+
+	for _, abiJSON := range r.ABIs {
+		parsed, err := abi.JSON(strings.NewReader(string(abiJSON)))
+		if err != nil {
+			continue
+		}
+		for _, method := range parsed.Methods {
+			selector := hex.EncodeToString(method.ID)
+			if _, exists := KnownFunctions[selector]; !exists {
+				KnownFunctions[selector] = FunctionInfo{Name: method.RawName, Signature: method.Sig, ABI: method}
+			}
+		}
+	}
+	for _, hash := range r.Hashes {
+		registerKnownHash(common.HexToHash(hash.Hash), hash.Name)
+	}
+	return conflicts
+}
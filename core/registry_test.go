@@ -0,0 +1,102 @@
+package core
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestParseRegistryHashes(t *testing.T) {
+	prestate := "0x03EE2917DA962EC266B091F4B62121DC9682BB0DB534633707325339F99EE405"
+	hashes, err := ParseRegistryHashes("hashes.json", []byte(`{"hashes": [{"hash": "`+prestate+`", "name": "op-program v1.6.0 prestate"}]}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(hashes) != 1 || hashes[0].Hash != strings.ToLower(prestate) {
+		t.Fatalf("unexpected hashes: %+v", hashes)
+	}
+	for _, invalid := range []string{
+		`{"hashes": [{"hash": "0x1234", "name": "short"}]}`,
+		`{"hashes": [{"hash": "` + prestate + `"}]}`,
+		`{"hashes": [{"hash": "` + prestate[2:] + `", "name": "no prefix"}]}`,
+	} {
+		if _, err := ParseRegistryHashes("hashes.json", []byte(invalid)); err == nil {
+			t.Errorf("expected an error for %s", invalid)
+		}
+	}
+
+	defer delete(KnownHashes, strings.ToLower(prestate))
+	registry := &Registry{Hashes: append(hashes, RegistryHash{Hash: crypto.Keccak256Hash([]byte("MINTER_ROLE")).Hex(), Name: "Impostor"})}
+	registry.Apply()
+	if got := InterpretBytes32(common.HexToHash(prestate), AnyChain); got != "op-program v1.6.0 prestate" {
+		t.Errorf("hash not applied: %q", got)
+	}
+	if got := InterpretBytes32(crypto.Keccak256Hash([]byte("MINTER_ROLE")), AnyChain); got != "MINTER_ROLE" {
+		t.Errorf("built-in hash replaced: %q", got)
+	}
+}
+
+func TestRegistryApply(t *testing.T) {
+	registry := &Registry{
+		Labels: []RegistryLabel{
+			{ChainID: OPMainnetChainID, Address: ChecksumAddress(airdropAlice), Name: "Registry Treasury"},
+			{ChainID: OPMainnetChainID, Address: OPTokenAddress, Name: "Impostor"},
+		},
+		Tokens: []RegistryToken{{ChainID: OPMainnetChainID, Address: ChecksumAddress(airdropToken), Symbol: "tkn", Decimals: 6}},
+		ABIs:   []json.RawMessage{json.RawMessage(`[{"inputs":[{"name":"id","type":"uint256"}],"name":"claimReward","type":"function"},{"inputs":[{"name":"to","type":"address"},{"name":"amount","type":"uint256"}],"name":"transfer","type":"function"}]`)},
+	}
+	defer func() {
+		KnownAddresses.Remove(airdropAlice, OPMainnetChainID)
+		KnownAddresses.Remove(airdropToken, OPMainnetChainID)
+		delete(KnownFunctions, "ae169a50")
+	}()
+	builtIn, _ := GetKnownContract(OPTokenAddress, OPMainnetChainID)
+	conflicts := registry.Apply()
+	if len(conflicts) != 1 || conflicts[0].Conflicting.Name != "Impostor" || conflicts[0].Existing.Source != SourceBuiltIn {
+		t.Errorf("expected the impostor label to conflict with the built-in one, got %+v", conflicts)
+	}
+	if entry, _ := KnownAddresses.Lookup(airdropAlice, OPMainnetChainID); entry.Source != SourceRegistry {
+		t.Errorf("registry label has source %q", entry.Source)
+	}
+
+	if info, ok := GetKnownContract(airdropAlice, OPMainnetChainID); !ok || info.Name != "Registry Treasury" {
+		t.Errorf("label not applied: %+v", info)
+	}
+	if info, ok := GetKnownContract(airdropToken, OPMainnetChainID); !ok || info.Name != "TKN" || info.Decimals != 6 {
+		t.Errorf("token not applied: %+v", info)
+	}
+	if info, _ := GetKnownContract(OPTokenAddress, OPMainnetChainID); info != builtIn {
+		t.Errorf("built-in contract replaced: %+v", info)
+	}
+	if fn, ok := KnownFunctions["ae169a50"]; !ok || fn.Signature != "claimReward(uint256)" {
+		t.Errorf("ABI not applied: %+v", fn)
+	}
+	if fn := KnownFunctions["a9059cbb"]; fn.ABI.Inputs[1].Name != "amount" {
+		t.Errorf("built-in function replaced: %+v", fn)
+	}
+}
+
+func TestLoadRegistryCache(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "registry.json")
+	if registry, err := LoadRegistryCache(path); err != nil || len(registry.Labels) != 0 {
+		t.Fatalf("missing cache: %+v, %v", registry, err)
+	}
+	saved := &Registry{Labels: []RegistryLabel{{ChainID: 10, Address: ChecksumAddress(airdropAlice), Name: "Treasury"}}}
+	if err := saved.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	if registry, err := LoadRegistryCache(path); err != nil || len(registry.Labels) != 1 || registry.Labels[0] != saved.Labels[0] {
+		t.Fatalf("unexpected cache: %+v, %v", registry, err)
+	}
+
+	if _, err := ParseTokenList("tokens.json", []byte(`{"tokens": [{"chainId": 1, "address": "0x1234", "symbol": "X", "decimals": 6}]}`)); err == nil {
+		t.Error("expected an error for a truncated token address")
+	}
+	if _, err := ParseABIManifest("abis.json", []byte(`{"abis": [[{"type": "event", "name": "E", "inputs": []}]]}`)); err == nil {
+		t.Error("expected an error for an ABI without functions")
+	}
+}
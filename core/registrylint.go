@@ -0,0 +1,331 @@
+package core
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+)
+
+// RegistryLintIssue is a problem LintRegistry found in a registry file. A critical issue makes the
+// file unfit to distribute: an entry sync would refuse, or one that would be ignored or shadowed
+// once applied. A warning is an entry that works but should be cleaned up.
+type RegistryLintIssue struct {
+	Severity Severity `json:"severity"`
+	File     string   `json:"file"`
+
+	// Entry locates the entry in the file, such as "labels[3]"
+	Entry   string `json:"entry,omitempty"`
+	Message string `json:"message"`
+}
+
+// RegistryChainCoverage counts the addresses the registry files name on a chain
+type RegistryChainCoverage struct {
+	ChainID uint64 `json:"chainId"`
+	Chain   string `json:"chain,omitempty"`
+	BuiltIn int    `json:"builtIn"`
+	Labels  int    `json:"labels"`
+	Tokens  int    `json:"tokens"`
+
+	// New is how many of the labelled and token addresses are not built in
+	New int `json:"new"`
+
+	// Supported is false for chains op-txverify does not verify on, whose entries are never used
+	Supported bool `json:"supported"`
+}
+
+// RegistryLintReport is the result of linting registry files
+type RegistryLintReport struct {
+	Issues []RegistryLintIssue     `json:"issues"`
+	Chains []RegistryChainCoverage `json:"chains"`
+
+	// Functions counts the functions of the ABI manifest, and NewFunctions those whose selector
+	// is not known yet
+	Functions    int `json:"functions"`
+	NewFunctions int `json:"newFunctions"`
+
+	// Hashes counts the named hashes, and NewHashes those not known yet
+	Hashes    int `json:"hashes"`
+	NewHashes int `json:"newHashes"`
+}
+
+// RegistryLintFiles are the contents of the registry files to lint, by kind. A nil file is not
+// linted.
+type RegistryLintFiles struct {
+	Labels, Tokens, ABIs, Hashes []byte
+
+	// Names are the file names issues are reported against, by kind ("labels", "tokens", ...)
+	Names map[string]string
+}
+
+// Count returns how many issues have a severity
+func (r *RegistryLintReport) Count(severity Severity) int {
+	count := 0
+	for _, issue := range r.Issues {
+		if issue.Severity == severity {
+			count++
+		}
+	}
+	return count
+}
+
+// LintRegistry checks label, token, ABI, and hash files before they are signed and distributed.
+// Unlike a sync, which stops at the first invalid entry, it reports every problem: invalid or
+// badly checksummed addresses, addresses listed twice or named differently from a built-in
+// contract, entries on chains op-txverify does not support, selectors that collide with another
+// function, and hashes named twice. It also counts what the files add to the built-in registry.
+func LintRegistry(files RegistryLintFiles) *RegistryLintReport {
+	lint := &registryLinter{
+		report:    &RegistryLintReport{Issues: []RegistryLintIssue{}},
+		names:     files.Names,
+		addresses: map[string]registryLintAddress{},
+		chains:    map[uint64]*RegistryChainCoverage{},
+	}
+	if files.Labels != nil {
+		lint.labels(files.Labels)
+	}
+	if files.Tokens != nil {
+		lint.tokens(files.Tokens)
+	}
+	if files.ABIs != nil {
+		lint.abis(files.ABIs)
+	}
+	if files.Hashes != nil {
+		lint.hashes(files.Hashes)
+	}
+
+	for _, coverage := range lint.chains {
+		lint.report.Chains = append(lint.report.Chains, *coverage)
+	}
+	sort.Slice(lint.report.Chains, func(i, j int) bool { return lint.report.Chains[i].ChainID < lint.report.Chains[j].ChainID })
+	sort.SliceStable(lint.report.Issues, func(i, j int) bool {
+		return lint.report.Issues[i].Severity == SeverityCritical && lint.report.Issues[j].Severity != SeverityCritical
+	})
+	return lint.report
+}
+
+// registryLintAddress is where an address was first listed, and under which name
+type registryLintAddress struct {
+	entry, name string
+}
+
+// registryLinter holds the state of a LintRegistry run
+type registryLinter struct {
+	report *RegistryLintReport
+	names  map[string]string
+
+	// addresses maps "<chain>:<lowercase address>" to where it was first listed
+	addresses map[string]registryLintAddress
+	chains    map[uint64]*RegistryChainCoverage
+}
+
+// issue records a problem with an entry of a file
+func (l *registryLinter) issue(severity Severity, kind, entry, format string, args ...interface{}) {
+	file := l.names[kind]
+	if file == "" {
+		file = kind
+	}
+	l.report.Issues = append(l.report.Issues, RegistryLintIssue{Severity: severity, File: file, Entry: entry, Message: fmt.Sprintf(format, args...)})
+}
+
+// chain returns the coverage of a chain
+func (l *registryLinter) chain(chainID uint64) *RegistryChainCoverage {
+	if coverage, ok := l.chains[chainID]; ok {
+		return coverage
+	}
+	name, supported := ChainNames[chainID]
+	coverage := &RegistryChainCoverage{ChainID: chainID, Chain: name, BuiltIn: len(builtinContracts[chainID]), Supported: supported}
+	l.chains[chainID] = coverage
+	return coverage
+}
+
+// address checks an address-naming entry and returns whether it is valid. name is the name it
+// is registered under, which is the upper-case symbol for tokens.
+func (l *registryLinter) address(kind, entry string, chainID uint64, address, name string, decimals int) bool {
+	switch {
+	case chainID == 0:
+		l.issue(SeverityCritical, kind, entry, "chainId is missing")
+		return false
+	case name == "":
+		l.issue(SeverityCritical, kind, entry, "%s has no name", address)
+		return false
+	case !strings.HasPrefix(address, "0x") || ValidateFullAddress("address", address) != nil:
+		l.issue(SeverityCritical, kind, entry, "invalid address %q", address)
+		return false
+	}
+	checksummed := ChecksumAddress(address)
+	switch digits := address[2:]; {
+	case digits == strings.ToLower(digits) || digits == strings.ToUpper(digits):
+		if address != checksummed {
+			l.issue(SeverityWarning, kind, entry, "%s is not checksummed; write %s", address, checksummed)
+		}
+	case address != checksummed:
+		l.issue(SeverityCritical, kind, entry, "%s has an invalid checksum; the checksummed address is %s. Check that the address was not mistyped.", address, checksummed)
+		return false
+	}
+
+	coverage := l.chain(chainID)
+	if !coverage.Supported {
+		l.issue(SeverityWarning, kind, entry, "chain %d is not supported by op-txverify, so %s is never used", chainID, name)
+		return true
+	}
+
+	key := fmt.Sprintf("%d:%s", chainID, strings.ToLower(address))
+	if first, listed := l.addresses[key]; listed {
+		if strings.EqualFold(first.name, name) {
+			l.issue(SeverityWarning, kind, entry, "%s is already listed at %s", checksummed, first.entry)
+		} else {
+			l.issue(SeverityCritical, kind, entry, "%s is named %q here but %q at %s", checksummed, name, first.name, first.entry)
+		}
+		return true
+	}
+	l.addresses[key] = registryLintAddress{entry: entry, name: name}
+
+	known, isKnown := KnownAddresses.Lookup(address, chainID)
+	if isKnown && known.Source == SourceBuiltIn {
+		switch {
+		case !strings.EqualFold(known.Name, name):
+			l.issue(SeverityCritical, kind, entry, "%s is the built-in contract %s, which keeps its name; %q is ignored", checksummed, known.Name, name)
+		case decimals > 0 && known.Decimals > 0 && decimals != known.Decimals:
+			l.issue(SeverityCritical, kind, entry, "%s has %d decimals here but %d in the built-in registry", checksummed, decimals, known.Decimals)
+		}
+		return true
+	}
+	coverage.New++
+	return true
+}
+
+// labels lints a label file
+func (l *registryLinter) labels(data []byte) {
+	var manifest struct {
+		Labels []RegistryLabel `json:"labels"`
+	}
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		l.issue(SeverityCritical, "labels", "", "invalid label file: %v", err)
+		return
+	}
+	for i, label := range manifest.Labels {
+		if l.address("labels", fmt.Sprintf("labels[%d]", i), label.ChainID, label.Address, label.Name, 0) {
+			l.chain(label.ChainID).Labels++
+		}
+	}
+}
+
+// tokens lints a token list
+func (l *registryLinter) tokens(data []byte) {
+	var list struct {
+		Tokens []RegistryToken `json:"tokens"`
+	}
+	if err := json.Unmarshal(data, &list); err != nil {
+		l.issue(SeverityCritical, "tokens", "", "invalid token list: %v", err)
+		return
+	}
+	for i, token := range list.Tokens {
+		entry := fmt.Sprintf("tokens[%d]", i)
+		if token.Decimals < 0 || token.Decimals > 77 {
+			l.issue(SeverityCritical, "tokens", entry, "%s has %d decimals; a token has between 0 and 77", token.Symbol, token.Decimals)
+			continue
+		}
+		if l.address("tokens", entry, token.ChainID, token.Address, strings.ToUpper(token.Symbol), token.Decimals) {
+			l.chain(token.ChainID).Tokens++
+		}
+	}
+}
+
+// abis lints an ABI manifest
+func (l *registryLinter) abis(data []byte) {
+	var manifest struct {
+		ABIs []json.RawMessage `json:"abis"`
+	}
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		l.issue(SeverityCritical, "abis", "", "invalid ABI manifest: %v", err)
+		return
+	}
+
+	// signatures maps each selector of the manifest to its signature and where it was first listed
+	signatures := map[string]registryLintAddress{}
+	for i, abiJSON := range manifest.ABIs {
+		entry := fmt.Sprintf("abis[%d]", i)
+		parsed, err := abi.JSON(strings.NewReader(string(abiJSON)))
+		if err != nil {
+			l.issue(SeverityCritical, "abis", entry, "invalid ABI: %v", err)
+			continue
+		}
+		if len(parsed.Methods) == 0 {
+			l.issue(SeverityCritical, "abis", entry, "ABI has no functions")
+			continue
+		}
+
+		methods := make([]string, 0, len(parsed.Methods))
+		for name := range parsed.Methods {
+			methods = append(methods, name)
+		}
+		sort.Strings(methods)
+		for _, name := range methods {
+			method := parsed.Methods[name]
+			selector := hex.EncodeToString(method.ID)
+			l.report.Functions++
+			if first, listed := signatures[selector]; listed {
+				if first.name != method.Sig {
+					l.issue(SeverityCritical, "abis", entry, "%s has selector 0x%s, as does %s at %s; only one of them can be decoded", method.Sig, selector, first.name, first.entry)
+				}
+				continue
+			}
+			signatures[selector] = registryLintAddress{entry: entry, name: method.Sig}
+
+			known, isKnown := KnownFunctions[selector]
+			switch {
+			case !isKnown:
+				l.report.NewFunctions++
+			case known.Signature != method.Sig:
+				l.issue(SeverityCritical, "abis", entry, "%s has selector 0x%s, as does the known function %s, which is kept; calls are decoded as %s", method.Sig, selector, known.Signature, known.Signature)
+			}
+		}
+	}
+}
+
+// hashes lints a hash file
+func (l *registryLinter) hashes(data []byte) {
+	var manifest struct {
+		Hashes []RegistryHash `json:"hashes"`
+	}
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		l.issue(SeverityCritical, "hashes", "", "invalid hash file: %v", err)
+		return
+	}
+	named := map[string]registryLintAddress{}
+	for i, hash := range manifest.Hashes {
+		entry := fmt.Sprintf("hashes[%d]", i)
+		decoded, err := hex.DecodeString(strings.TrimPrefix(hash.Hash, "0x"))
+		switch {
+		case !strings.HasPrefix(hash.Hash, "0x") || err != nil || len(decoded) != 32:
+			l.issue(SeverityCritical, "hashes", entry, "%q is not a 32-byte hex hash", hash.Hash)
+			continue
+		case hash.Name == "":
+			l.issue(SeverityCritical, "hashes", entry, "%s has no name", hash.Hash)
+			continue
+		}
+		l.report.Hashes++
+
+		key := strings.ToLower(hash.Hash)
+		if first, listed := named[key]; listed {
+			if first.name == hash.Name {
+				l.issue(SeverityWarning, "hashes", entry, "%s is already listed at %s", key, first.entry)
+			} else {
+				l.issue(SeverityCritical, "hashes", entry, "%s is named %q here but %q at %s", key, hash.Name, first.name, first.entry)
+			}
+			continue
+		}
+		named[key] = registryLintAddress{entry: entry, name: hash.Name}
+
+		switch known, isKnown := KnownHashes[key]; {
+		case !isKnown:
+			l.report.NewHashes++
+		case known != hash.Name:
+			l.issue(SeverityWarning, "hashes", entry, "%s is already known as %q, which is kept; %q is ignored", key, known, hash.Name)
+		}
+	}
+}
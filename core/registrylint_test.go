@@ -0,0 +1,101 @@
+package core
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLintRegistry(t *testing.T) {
+	labels := `{"labels": [
+		{"chainId": 10, "address": "0x8b8B2F214D92527BF1b1148DC2e609a4C1c2Fd69", "name": "Grants Recipient"},
+		{"chainId": 10, "address": "0x8b8b2f214d92527bf1b1148dc2e609a4c1c2fd69", "name": "Someone Else"},
+		{"chainId": 10, "address": "0x8B8B2F214D92527bf1b1148DC2E609a4C1c2fd69", "name": "Mistyped"},
+		{"chainId": 10, "address": "0x4200000000000000000000000000000000000042", "name": "Governance Token"},
+		{"chainId": 42161, "address": "0x912CE59144191C1204E64559FE8253a0e49E6548", "name": "ARB"},
+		{"chainId": 10, "address": "0x1234", "name": "Short"},
+		{"chainId": 1, "address": "0x3041ba32f451f5850c147805f5521ac206421623", "name": "Lowercase"}
+	]}`
+	tokens := `{"tokens": [
+		{"chainId": 10, "address": "0x4200000000000000000000000000000000000042", "symbol": "op", "decimals": 6},
+		{"chainId": 10, "address": "0x0b2C639c533813f4Aa9D7837CAf62653d097Ff85", "symbol": "USDC", "decimals": 6}
+	]}`
+	abis := `{"abis": [
+		[{"type": "function", "name": "burn", "inputs": [{"name": "amount", "type": "uint256"}]}],
+		[{"type": "function", "name": "collate_propagate_storage", "inputs": [{"name": "", "type": "bytes16"}]}],
+		[{"type": "function", "name": "transfer", "inputs": [{"name": "to", "type": "address"}, {"name": "amount", "type": "uint256"}]}],
+		"not an ABI"
+	]}`
+	hashes := `{"hashes": [
+		{"hash": "0x0000000000000000000000000000000000000000000000000000000000000001", "name": "one"},
+		{"hash": "0x0000000000000000000000000000000000000000000000000000000000000001", "name": "uno"},
+		{"hash": "0x01", "name": "short"}
+	]}`
+
+	report := LintRegistry(RegistryLintFiles{
+		Labels: []byte(labels), Tokens: []byte(tokens), ABIs: []byte(abis), Hashes: []byte(hashes),
+		Names: map[string]string{"labels": "labels.json"},
+	})
+
+	want := []struct {
+		severity Severity
+		entry    string
+		message  string
+	}{
+		{SeverityCritical, "labels[1]", `named "Someone Else" here but "Grants Recipient" at labels[0]`},
+		{SeverityWarning, "labels[1]", "is not checksummed"},
+		{SeverityCritical, "labels[2]", "invalid checksum"},
+		{SeverityCritical, "labels[3]", "is the built-in contract OP TOKEN"},
+		{SeverityWarning, "labels[4]", "chain 42161 is not supported"},
+		{SeverityCritical, "labels[5]", `invalid address "0x1234"`},
+		{SeverityWarning, "labels[6]", "is not checksummed; write 0x3041BA32f451F5850c147805F5521AC206421623"},
+		{SeverityCritical, "tokens[0]", `is named "OP" here but "Governance Token" at labels[3]`},
+		{SeverityCritical, "abis[1]", "collate_propagate_storage(bytes16) has selector 0x42966c68, as does burn(uint256) at abis[0]"},
+		{SeverityCritical, "abis[3]", "invalid ABI"},
+		{SeverityCritical, "hashes[1]", `named "uno" here but "one" at hashes[0]`},
+		{SeverityCritical, "hashes[2]", "is not a 32-byte hex hash"},
+	}
+	for _, w := range want {
+		found := false
+		for _, issue := range report.Issues {
+			if issue.Entry == w.entry && issue.Severity == w.severity && strings.Contains(issue.Message, w.message) {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("expected a %s issue at %s containing %q, got %+v", w.severity, w.entry, w.message, report.Issues)
+		}
+	}
+	if len(report.Issues) != len(want) {
+		t.Errorf("expected %d issues, got %d: %+v", len(want), len(report.Issues), report.Issues)
+	}
+	if report.Issues[0].File != "labels.json" || report.Issues[0].Severity != SeverityCritical {
+		t.Errorf("expected errors first, reported against the file name, got %+v", report.Issues[0])
+	}
+
+	var op *RegistryChainCoverage
+	for i := range report.Chains {
+		if report.Chains[i].ChainID == OPMainnetChainID {
+			op = &report.Chains[i]
+		}
+	}
+	if op == nil || op.Labels != 3 || op.Tokens != 2 || op.New != 1 || op.BuiltIn == 0 {
+		t.Errorf("unexpected OP Mainnet coverage: %+v", op)
+	}
+	if report.Functions != 3 || report.NewFunctions != 1 || report.Hashes != 2 || report.NewHashes != 1 {
+		t.Errorf("unexpected function and hash counts: %+v", report)
+	}
+}
+
+func TestLintRegistryClean(t *testing.T) {
+	report := LintRegistry(RegistryLintFiles{
+		Labels: []byte(`{"labels": [{"chainId": 10, "address": "0x8b8B2F214D92527BF1b1148DC2e609a4C1c2Fd69", "name": "Grants Recipient"}]}`),
+	})
+	if len(report.Issues) != 0 || report.Count(SeverityCritical) != 0 {
+		t.Errorf("expected no issues, got %+v", report.Issues)
+	}
+
+	report = LintRegistry(RegistryLintFiles{Tokens: []byte(`{"tokens": {}}`)})
+	if report.Count(SeverityCritical) != 1 || !strings.Contains(report.Issues[0].Message, "invalid token list") {
+		t.Errorf("expected the malformed token list to be reported, got %+v", report.Issues)
+	}
+}
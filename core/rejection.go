@@ -0,0 +1,23 @@
+package core
+
+import (
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// RejectionFunctionName is how a rejection transaction's call is labelled instead of as an
+// unknown empty call
+const RejectionFunctionName = "REJECTION / NONCE BURN"
+
+// IsRejection reports whether a transaction is a rejection as the Safe UI creates it to cancel a
+// queued transaction: a call to the Safe itself without value or data. It has no effect other
+// than using up its nonce, so no other transaction queued for that nonce can execute. A gas
+// refund would pay out of the Safe, so a transaction that pays one is not a rejection.
+func IsRejection(tx SafeTransaction) bool {
+	return strings.EqualFold(StripChainPrefix(tx.To), StripChainPrefix(tx.Safe)) &&
+		(tx.Value == nil || tx.Value.Sign() == 0) &&
+		len(common.FromHex(tx.Data)) == 0 &&
+		tx.Operation == 0 &&
+		tx.GasPrice == 0
+}
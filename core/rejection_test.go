@@ -0,0 +1,45 @@
+package core
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestIsRejection(t *testing.T) {
+	rejection := SafeTransaction{
+		Safe:           effectsSafe,
+		SafeVersion:    "1.3.0",
+		Chain:          OPMainnetChainID,
+		To:             "oeth:" + effectsSafe,
+		Value:          big.NewInt(0),
+		Data:           "0x",
+		GasToken:       ZeroAddress,
+		RefundReceiver: ZeroAddress,
+		Nonce:          12,
+	}
+	if !IsRejection(rejection) {
+		t.Fatal("expected an empty call to the Safe to be a rejection")
+	}
+
+	for name, change := range map[string]func(tx *SafeTransaction){
+		"other target": func(tx *SafeTransaction) { tx.To = airdropAlice },
+		"value":        func(tx *SafeTransaction) { tx.Value = big.NewInt(1) },
+		"data":         func(tx *SafeTransaction) { tx.Data = "0xdeadbeef" },
+		"delegatecall": func(tx *SafeTransaction) { tx.Operation = 1 },
+		"gas refund":   func(tx *SafeTransaction) { tx.GasPrice = 1 },
+	} {
+		tx := rejection
+		change(&tx)
+		if IsRejection(tx) {
+			t.Errorf("%s: did not expect a rejection", name)
+		}
+	}
+
+	result, err := VerifyTransaction(rejection, VerifyOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !result.Rejection || result.Call.FunctionName != RejectionFunctionName || result.Call.RawData != "" {
+		t.Fatalf("rejection is not labelled: %+v", result.Call)
+	}
+}
@@ -0,0 +1,104 @@
+package core
+
+import (
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// DefaultAdminRole is the AccessControl role that administers every other role, including itself
+const DefaultAdminRole = "DEFAULT_ADMIN_ROLE"
+
+// accessControlABIJSON are OpenZeppelin AccessControl's role management functions
+var accessControlABIJSON = []string{
+	`[{"inputs":[{"name":"role","type":"bytes32"},{"name":"account","type":"address"}],"name":"grantRole","outputs":[],"stateMutability":"nonpayable","type":"function"}]`,
+	`[{"inputs":[{"name":"role","type":"bytes32"},{"name":"account","type":"address"}],"name":"revokeRole","outputs":[],"stateMutability":"nonpayable","type":"function"}]`,
+	`[{"inputs":[{"name":"role","type":"bytes32"},{"name":"account","type":"address"}],"name":"renounceRole","outputs":[],"stateMutability":"nonpayable","type":"function"}]`,
+}
+
+// CommonRoles are role names in wide use. AccessControl roles are the keccak256 hash of their
+// name, so a role hash that matches one of these is named after it.
+var CommonRoles = []string{
+	"ADMIN_ROLE", "MINTER_ROLE", "BURNER_ROLE", "PAUSER_ROLE", "UNPAUSER_ROLE", "UPGRADER_ROLE",
+	"OPERATOR_ROLE", "MANAGER_ROLE", "GUARDIAN", "GUARDIAN_ROLE", "KEEPER_ROLE", "RELAYER_ROLE",
+	"ORACLE_ROLE", "BRIDGE_ROLE", "DEPOSITOR_ROLE", "WITHDRAWER_ROLE", "SNAPSHOT_ROLE", "FEE_MANAGER_ROLE",
+	"PROPOSER_ROLE", "EXECUTOR_ROLE", "CANCELLER_ROLE", "TIMELOCK_ADMIN_ROLE", "DISTRIBUTOR_ROLE",
+}
+
+func init() {
+	registerKnownABIs(accessControlABIJSON)
+	for _, role := range CommonRoles {
+		RegisterRole(role)
+	}
+}
+
+// RegisterRole names the role hash of a role name, unless the hash already has a name, and
+// returns the hash
+func RegisterRole(name string) common.Hash {
+	hash := crypto.Keccak256Hash([]byte(name))
+	registerKnownHash(hash, name)
+	return hash
+}
+
+// RoleName returns the name of an AccessControl role hash, or an empty string for an unknown one
+func RoleName(role common.Hash) string {
+	if role == (common.Hash{}) {
+		return DefaultAdminRole
+	}
+	return KnownHashes[strings.ToLower(role.Hex())]
+}
+
+// checkRoleChanges warns about the AccessControl roles a transaction grants, revokes, or
+// renounces. Granting DEFAULT_ADMIN_ROLE hands over control of every role of the contract, and
+// giving it up can leave the contract without an administrator. Roles that are not known by name
+// are pointed out, since their hash says nothing about what they allow.
+func checkRoleChanges(result *VerificationResult) []Warning {
+	var warnings []Warning
+	for _, r := range []*VerificationResult{result.NestedResult, result} {
+		if r == nil {
+			continue
+		}
+		chainID := uint64(r.Transaction.Chain)
+		for _, call := range safeCalls(r) {
+			if call.FunctionName != "grantRole" && call.FunctionName != "revokeRole" && call.FunctionName != "renounceRole" {
+				continue
+			}
+			roleArg, hasRole := call.Argument("role")
+			accountArg, hasAccount := call.Argument("account")
+			roleHex, _ := roleArg.Value.(string)
+			account, _ := accountArg.Value.(common.Address)
+			if !hasRole || !hasAccount || roleHex == "" {
+				continue
+			}
+			name := "The transaction"
+			if call.Index != "" {
+				name = "Call #" + call.Index
+			}
+			target := describeAddress(common.HexToAddress(StripChainPrefix(call.Target)), chainID)
+			role := common.HexToHash(roleHex)
+			roleName := RoleName(role)
+
+			switch {
+			case roleName == DefaultAdminRole && call.FunctionName == "grantRole":
+				warnings = append(warnings, newWarning(warnAdminGranted,
+					"%s grants %s on %s to %s. The admin role can grant and revoke every role of the contract, including its own; make sure this account is meant to control it.",
+					name, DefaultAdminRole, target, describeAddress(account, chainID)))
+			case roleName == DefaultAdminRole:
+				warnings = append(warnings, newWarning(warnAdminRemoved,
+					"%s %s %s on %s from %s. If no other account holds the admin role, no one can manage the contract's roles afterwards.",
+					name, roleVerb(call.FunctionName), DefaultAdminRole, target, describeAddress(account, chainID)))
+			case roleName == "":
+				warnings = append(warnings, newWarning(warnUnknownRole,
+					"%s %s an unknown role %s on %s. Check what the role allows in the contract's source.",
+					name, roleVerb(call.FunctionName), role.Hex(), target))
+			}
+		}
+	}
+	return warnings
+}
+
+// roleVerb describes what a role function does, as in "Call #1 grants ..."
+func roleVerb(function string) string {
+	return strings.TrimSuffix(function, "Role") + "s"
+}
@@ -0,0 +1,59 @@
+package core
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// roleCall calls an AccessControl role function of airdropToken
+func roleCall(t *testing.T, function string, role common.Hash, account string) multiSendTransaction {
+	t.Helper()
+	data := encodeKnownCall(t, function+"(bytes32,address)", role, common.HexToAddress(account))
+	return multiSendTransaction{To: common.HexToAddress(airdropToken), Value: big.NewInt(0), Data: common.FromHex(data)}
+}
+
+func TestCheckRoleChanges(t *testing.T) {
+	unknown := crypto.Keccak256Hash([]byte("SECRET_ROLE"))
+	result := ownerResult(t,
+		roleCall(t, "grantRole", common.Hash{}, airdropAlice),
+		roleCall(t, "grantRole", crypto.Keccak256Hash([]byte("MINTER_ROLE")), airdropBob),
+		roleCall(t, "renounceRole", common.Hash{}, effectsSafe),
+		roleCall(t, "revokeRole", unknown, airdropBob),
+	)
+
+	if warnings := warningsContaining(result.Warnings, SeverityCritical, "Call #1 grants DEFAULT_ADMIN_ROLE"); len(warnings) != 1 || !strings.Contains(warnings[0].Message, ChecksumAddress(airdropAlice)) {
+		t.Errorf("expected a critical warning for the admin grant, got %+v", result.Warnings)
+	}
+	if len(warningsContaining(result.Warnings, SeverityWarning, "Call #3 renounces DEFAULT_ADMIN_ROLE")) != 1 {
+		t.Errorf("expected a warning for renouncing the admin role, got %+v", result.Warnings)
+	}
+	if len(warningsContaining(result.Warnings, SeverityInfo, "Call #4 revokes an unknown role "+unknown.Hex())) != 1 {
+		t.Errorf("expected the unknown role to be pointed out, got %+v", result.Warnings)
+	}
+	if len(warningsContaining(result.Warnings, SeverityInfo, "Call #2")) != 0 || len(warningsContaining(result.Warnings, SeverityWarning, "Call #2")) != 0 {
+		t.Errorf("expected no warning for granting a known role, got %+v", result.Warnings)
+	}
+
+	calls := result.Call.SubCalls
+	if role, _ := calls[0].Argument("role"); !strings.HasSuffix(role.Display, "(DEFAULT_ADMIN_ROLE)") {
+		t.Errorf("expected the admin role to be named, got %q", role.Display)
+	}
+	if role, _ := calls[1].Argument("role"); !strings.HasSuffix(role.Display, "(MINTER_ROLE)") {
+		t.Errorf("expected the minter role to be named, got %q", role.Display)
+	}
+}
+
+func TestRegisterRole(t *testing.T) {
+	hash := RegisterRole("TREASURY_ROLE")
+	defer delete(KnownHashes, strings.ToLower(hash.Hex()))
+	if hash != crypto.Keccak256Hash([]byte("TREASURY_ROLE")) || RoleName(hash) != "TREASURY_ROLE" {
+		t.Errorf("expected the role to be named, got %q for %s", RoleName(hash), hash.Hex())
+	}
+	if RoleName(common.Hash{}) != DefaultAdminRole || RoleName(crypto.Keccak256Hash([]byte("GUARDIAN"))) != "GUARDIAN" {
+		t.Error("expected the built-in roles to be named")
+	}
+}
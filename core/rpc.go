@@ -0,0 +1,232 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync/atomic"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// ErrBlockNotFound is returned for a block the node does not have
+var ErrBlockNotFound = errors.New("block not found")
+
+// RPCClient is a minimal Ethereum JSON-RPC client for checking executed transactions
+type RPCClient struct {
+	URL        string
+	HTTPClient *http.Client
+
+	id atomic.Uint64
+}
+
+// NewRPCClient creates a JSON-RPC client for a node endpoint
+func NewRPCClient(url string) *RPCClient {
+	return &RPCClient{URL: url, HTTPClient: http.DefaultClient}
+}
+
+// RPCBlock is the header fields of a block
+type RPCBlock struct {
+	Number    hexutil.Uint64 `json:"number"`
+	Hash      common.Hash    `json:"hash"`
+	Timestamp hexutil.Uint64 `json:"timestamp"`
+}
+
+// RPCLog is an event log of a transaction receipt
+type RPCLog struct {
+	Address  common.Address `json:"address"`
+	Topics   []common.Hash  `json:"topics"`
+	Data     hexutil.Bytes  `json:"data"`
+	LogIndex hexutil.Uint64 `json:"logIndex"`
+
+	// BlockNumber and TransactionHash are where the log was emitted
+	BlockNumber     hexutil.Uint64 `json:"blockNumber"`
+	TransactionHash common.Hash    `json:"transactionHash"`
+}
+
+// RPCReceipt is a transaction receipt
+type RPCReceipt struct {
+	TransactionHash common.Hash    `json:"transactionHash"`
+	BlockHash       common.Hash    `json:"blockHash"`
+	BlockNumber     hexutil.Uint64 `json:"blockNumber"`
+	Status          hexutil.Uint64 `json:"status"`
+	Logs            []RPCLog       `json:"logs"`
+}
+
+// ChainID calls eth_chainId
+func (c *RPCClient) ChainID(ctx context.Context) (uint64, error) {
+	var chainID hexutil.Uint64
+	if err := c.call(ctx, &chainID, "eth_chainId"); err != nil {
+		return 0, err
+	}
+	return uint64(chainID), nil
+}
+
+// BlockByNumber returns the header of a block by number or tag ("latest", "finalized", ...)
+func (c *RPCClient) BlockByNumber(ctx context.Context, block string) (*RPCBlock, error) {
+	var result *RPCBlock
+	if err := c.call(ctx, &result, "eth_getBlockByNumber", block, false); err != nil {
+		return nil, err
+	}
+	if result == nil {
+		return nil, fmt.Errorf("%w: %s", ErrBlockNotFound, block)
+	}
+	return result, nil
+}
+
+// RPCSyncProgress is the progress of a node that is still syncing
+type RPCSyncProgress struct {
+	CurrentBlock hexutil.Uint64 `json:"currentBlock"`
+	HighestBlock hexutil.Uint64 `json:"highestBlock"`
+}
+
+// Syncing calls eth_syncing and returns the node's sync progress, or nil when it is synced
+func (c *RPCClient) Syncing(ctx context.Context) (*RPCSyncProgress, error) {
+	var result json.RawMessage
+	if err := c.call(ctx, &result, "eth_syncing"); err != nil {
+		return nil, err
+	}
+	if string(result) == "false" {
+		return nil, nil
+	}
+	var progress RPCSyncProgress
+	if err := json.Unmarshal(result, &progress); err != nil {
+		return nil, fmt.Errorf("error parsing eth_syncing result: %w", err)
+	}
+	return &progress, nil
+}
+
+// TransactionReceipt returns the receipt of a transaction, or nil when the node does not know it
+func (c *RPCClient) TransactionReceipt(ctx context.Context, hash string) (*RPCReceipt, error) {
+	var result *RPCReceipt
+	if err := c.call(ctx, &result, "eth_getTransactionReceipt", hash); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Logs returns the logs an address emitted with a first topic in a range of blocks, calling
+// eth_getLogs
+func (c *RPCClient) Logs(ctx context.Context, address string, topic common.Hash, fromBlock, toBlock uint64) ([]RPCLog, error) {
+	var result []RPCLog
+	filter := map[string]interface{}{
+		"address":   address,
+		"topics":    []common.Hash{topic},
+		"fromBlock": hexutil.Uint64(fromBlock),
+		"toBlock":   hexutil.Uint64(toBlock),
+	}
+	if err := c.call(ctx, &result, "eth_getLogs", filter); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Call executes a read-only call against the state at a block number or tag and returns its result
+func (c *RPCClient) Call(ctx context.Context, to string, data []byte, block string) ([]byte, error) {
+	var result hexutil.Bytes
+	args := map[string]interface{}{"to": to, "data": hexutil.Bytes(data)}
+	if err := c.call(ctx, &result, "eth_call", args, block); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Code returns the code deployed at an address at a block number or tag, which is empty for an
+// externally owned account
+func (c *RPCClient) Code(ctx context.Context, address string, block string) ([]byte, error) {
+	var result hexutil.Bytes
+	if err := c.call(ctx, &result, "eth_getCode", address, block); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// SimulateCall executes a call from an account sending value, as the account would make it at a
+// block number or tag. The account's balance is overridden so the call does not fail for lack of
+// funds, which leaves whether the recipient accepts the call.
+func (c *RPCClient) SimulateCall(ctx context.Context, from, to string, value *big.Int, data []byte, block string) ([]byte, error) {
+	var result hexutil.Bytes
+	args := map[string]interface{}{"from": from, "to": to, "value": (*hexutil.Big)(value), "data": hexutil.Bytes(data)}
+	balance := new(big.Int).Add(value, new(big.Int).Lsh(big.NewInt(1), 128))
+	overrides := map[string]interface{}{from: map[string]interface{}{"balance": (*hexutil.Big)(balance)}}
+	if err := c.call(ctx, &result, "eth_call", args, block, overrides); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// RPCError is an error a node returned for a JSON-RPC request
+type RPCError struct {
+	Method  string
+	Code    int
+	Message string
+}
+
+func (e *RPCError) Error() string {
+	return fmt.Sprintf("%s failed: %s (code %d)", e.Method, e.Message, e.Code)
+}
+
+// Reverted reports whether the error is a call reverting, rather than the node failing
+func (e *RPCError) Reverted() bool {
+	return e.Code == 3 || strings.Contains(strings.ToLower(e.Message), "revert")
+}
+
+// call performs a JSON-RPC request and decodes its result into out
+func (c *RPCClient) call(ctx context.Context, out interface{}, method string, params ...interface{}) error {
+	if params == nil {
+		params = []interface{}{}
+	}
+	body, err := json.Marshal(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      c.id.Add(1),
+		"method":  method,
+		"params":  params,
+	})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("error creating RPC request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("error calling %s: %w", method, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("error reading %s response: %w", method, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s failed with status %s: %s", method, resp.Status, strings.TrimSpace(string(data)))
+	}
+
+	var response struct {
+		Result json.RawMessage `json:"result"`
+		Error  *struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(data, &response); err != nil {
+		return fmt.Errorf("error parsing %s response: %w", method, err)
+	}
+	if response.Error != nil {
+		return &RPCError{Method: method, Code: response.Error.Code, Message: response.Error.Message}
+	}
+	if err := json.Unmarshal(response.Result, out); err != nil {
+		return fmt.Errorf("error parsing %s result: %w", method, err)
+	}
+	return nil
+}
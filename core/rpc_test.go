@@ -0,0 +1,156 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// fakeNode answers the JSON-RPC methods RPCClient uses from in-memory chain state
+type fakeNode struct {
+	chainID   uint64
+	latest    uint64
+	finalized uint64
+	// noFinalized makes the finalized tag an unknown-block-tag error, as on pre-merge nodes
+	noFinalized bool
+	// latestTime is the timestamp of the latest block
+	latestTime uint64
+	// syncing makes eth_syncing report progress instead of false
+	syncing  bool
+	blocks   map[uint64]common.Hash
+	receipts map[string]*RPCReceipt
+	// calls answers eth_call by lowercase target and hex calldata; callBlocks records the
+	// block each call was made at
+	calls      map[string]hexutil.Bytes
+	callBlocks []string
+	// code is the code deployed at each lowercase address
+	code map[string]hexutil.Bytes
+	// logs answers eth_getLogs, filtered by block range
+	logs []RPCLog
+}
+
+func newFakeNode(t *testing.T, node *fakeNode) *RPCClient {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     uint64            `json:"id"`
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		result, rpcErr := node.handle(req.Method, req.Params)
+		response := map[string]interface{}{"jsonrpc": "2.0", "id": req.ID}
+		if rpcErr != "" {
+			response["error"] = map[string]interface{}{"code": -32000, "message": rpcErr}
+		} else {
+			response["result"] = result
+		}
+		json.NewEncoder(w).Encode(response)
+	}))
+	t.Cleanup(server.Close)
+	return NewRPCClient(server.URL)
+}
+
+func (n *fakeNode) handle(method string, params []json.RawMessage) (interface{}, string) {
+	switch method {
+	case "eth_chainId":
+		return hexutil.Uint64(n.chainID), ""
+	case "eth_syncing":
+		if n.syncing {
+			return &RPCSyncProgress{CurrentBlock: hexutil.Uint64(n.latest), HighestBlock: hexutil.Uint64(n.latest + 100)}, ""
+		}
+		return false, ""
+	case "eth_call":
+		var args struct {
+			To   string        `json:"to"`
+			Data hexutil.Bytes `json:"data"`
+		}
+		var block string
+		json.Unmarshal(params[0], &args)
+		json.Unmarshal(params[1], &block)
+		n.callBlocks = append(n.callBlocks, block)
+		result, ok := n.calls[strings.ToLower(args.To)+":"+args.Data.String()]
+		if !ok {
+			return nil, "execution reverted"
+		}
+		return result, ""
+	case "eth_getCode":
+		var address string
+		json.Unmarshal(params[0], &address)
+		code, ok := n.code[strings.ToLower(address)]
+		if !ok {
+			code = hexutil.Bytes{}
+		}
+		return code, ""
+	case "eth_getLogs":
+		var filter struct {
+			FromBlock hexutil.Uint64 `json:"fromBlock"`
+			ToBlock   hexutil.Uint64 `json:"toBlock"`
+		}
+		json.Unmarshal(params[0], &filter)
+		logs := []RPCLog{}
+		for _, log := range n.logs {
+			if log.BlockNumber >= filter.FromBlock && log.BlockNumber <= filter.ToBlock {
+				logs = append(logs, log)
+			}
+		}
+		return logs, ""
+	case "eth_getTransactionReceipt":
+		var hash string
+		json.Unmarshal(params[0], &hash)
+		return n.receipts[strings.ToLower(hash)], ""
+	case "eth_getBlockByNumber":
+		var tag string
+		json.Unmarshal(params[0], &tag)
+		var number uint64
+		switch tag {
+		case "latest":
+			number = n.latest
+		case "finalized":
+			if n.noFinalized {
+				return nil, "unknown block tag finalized"
+			}
+			number = n.finalized
+		default:
+			decoded, err := hexutil.DecodeUint64(tag)
+			if err != nil {
+				return nil, err.Error()
+			}
+			number = decoded
+		}
+		hash, ok := n.blocks[number]
+		if !ok {
+			hash = common.BigToHash(common.Big1)
+		}
+		block := &RPCBlock{Number: hexutil.Uint64(number), Hash: hash}
+		if number == n.latest {
+			block.Timestamp = hexutil.Uint64(n.latestTime)
+		}
+		return block, ""
+	}
+	return nil, "method not found: " + method
+}
+
+func TestRPCClientErrors(t *testing.T) {
+	client := newFakeNode(t, &fakeNode{chainID: OPMainnetChainID})
+	if chainID, err := client.ChainID(context.Background()); err != nil || chainID != OPMainnetChainID {
+		t.Fatalf("chain ID = %d, %v", chainID, err)
+	}
+	err := client.call(context.Background(), new(string), "eth_unknown")
+	if err == nil || !strings.Contains(err.Error(), "method not found") {
+		t.Errorf("expected the node's error, got %v", err)
+	}
+	receipt, err := client.TransactionReceipt(context.Background(), "0xabc")
+	if err != nil || receipt != nil {
+		t.Errorf("expected no receipt for an unknown transaction, got %+v, %v", receipt, err)
+	}
+}
@@ -0,0 +1,97 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// DefaultMaxBlockAge is how old a node's latest block may be before the node is considered
+// behind. Every supported chain produces blocks far more often.
+const DefaultMaxBlockAge = 2 * time.Minute
+
+// RPCHealth is the outcome of checking that an RPC endpoint is usable for on-chain checks
+type RPCHealth struct {
+	URL             string        `json:"url"`
+	ExpectedChainID uint64        `json:"expectedChainId"`
+	ChainID         uint64        `json:"chainId"`
+	Latency         time.Duration `json:"latency"`
+	LatestBlock     uint64        `json:"latestBlock"`
+	BlockAge        time.Duration `json:"blockAge"`
+	Syncing         bool          `json:"syncing"`
+	Problems        []string      `json:"problems,omitempty"`
+}
+
+// Healthy reports whether the endpoint passed every check
+func (h RPCHealth) Healthy() bool {
+	return len(h.Problems) == 0
+}
+
+// CheckRPCHealth checks that an endpoint serves the expected chain, is synced, and has a recent
+// latest block. Latency is the round trip of eth_chainId.
+func CheckRPCHealth(ctx context.Context, client *RPCClient, chainID uint64, maxBlockAge time.Duration) RPCHealth {
+	health := RPCHealth{URL: client.URL, ExpectedChainID: chainID}
+	problem := func(format string, args ...interface{}) RPCHealth {
+		health.Problems = append(health.Problems, fmt.Sprintf(format, args...))
+		return health
+	}
+
+	start := time.Now()
+	actual, err := client.ChainID(ctx)
+	if err != nil {
+		return problem("unreachable: %v", err)
+	}
+	health.Latency = time.Since(start)
+	health.ChainID = actual
+	if actual != chainID {
+		return problem("serves chain %d, not chain %d", actual, chainID)
+	}
+
+	progress, err := client.Syncing(ctx)
+	if err != nil {
+		return problem("sync status unavailable: %v", err)
+	}
+	if progress != nil {
+		health.Syncing = true
+		problem("still syncing (block %d of %d)", progress.CurrentBlock, progress.HighestBlock)
+	}
+
+	latest, err := client.BlockByNumber(ctx, "latest")
+	if err != nil {
+		return problem("latest block unavailable: %v", err)
+	}
+	health.LatestBlock = uint64(latest.Number)
+	health.BlockAge = time.Since(time.Unix(int64(latest.Timestamp), 0)).Truncate(time.Second)
+	if health.BlockAge > maxBlockAge {
+		problem("latest block %d is %s old", health.LatestBlock, health.BlockAge)
+	}
+	return health
+}
+
+// HealthyRPCClient returns a client for the first endpoint that passes CheckRPCHealth, together
+// with the health of every endpoint checked. It errors when none pass.
+func HealthyRPCClient(ctx context.Context, urls []string, chainID uint64, maxBlockAge time.Duration) (*RPCClient, []RPCHealth, error) {
+	var checked []RPCHealth
+	for _, url := range urls {
+		client := NewRPCClient(url)
+		health := CheckRPCHealth(ctx, client, chainID, maxBlockAge)
+		checked = append(checked, health)
+		if health.Healthy() {
+			return client, checked, nil
+		}
+	}
+	if ctx.Err() != nil {
+		return nil, checked, ctx.Err()
+	}
+	return nil, checked, fmt.Errorf("no healthy RPC endpoint for chain %d: %s", chainID, describeRPCProblems(checked))
+}
+
+// describeRPCProblems summarizes why endpoints failed their health checks
+func describeRPCProblems(checked []RPCHealth) string {
+	descriptions := make([]string, len(checked))
+	for i, health := range checked {
+		descriptions[i] = health.URL + ": " + strings.Join(health.Problems, ", ")
+	}
+	return strings.Join(descriptions, "; ")
+}
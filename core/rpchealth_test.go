@@ -0,0 +1,68 @@
+package core
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+// healthyNode is a synced OP Mainnet node whose latest block is a few seconds old
+func healthyNode() *fakeNode {
+	return &fakeNode{chainID: OPMainnetChainID, latest: 1000, latestTime: uint64(time.Now().Add(-4 * time.Second).Unix())}
+}
+
+func TestCheckRPCHealth(t *testing.T) {
+	health := CheckRPCHealth(context.Background(), newFakeNode(t, healthyNode()), OPMainnetChainID, DefaultMaxBlockAge)
+	if !health.Healthy() || health.ChainID != OPMainnetChainID || health.LatestBlock != 1000 || health.BlockAge > time.Minute {
+		t.Fatalf("unexpected health: %+v", health)
+	}
+}
+
+func TestCheckRPCHealthProblems(t *testing.T) {
+	wrongChain := healthyNode()
+	wrongChain.chainID = MainnetChainID
+	syncing := healthyNode()
+	syncing.syncing = true
+	stale := healthyNode()
+	stale.latestTime = uint64(time.Now().Add(-time.Hour).Unix())
+
+	for name, tt := range map[string]struct {
+		node *fakeNode
+		want string
+	}{
+		"wrong chain": {wrongChain, "serves chain 1, not chain 10"},
+		"syncing":     {syncing, "still syncing (block 1000 of 1100)"},
+		"stale":       {stale, "latest block 1000 is 1h0m0s old"},
+	} {
+		health := CheckRPCHealth(context.Background(), newFakeNode(t, tt.node), OPMainnetChainID, DefaultMaxBlockAge)
+		if health.Healthy() || !strings.Contains(strings.Join(health.Problems, "; "), tt.want) {
+			t.Errorf("%s: got problems %v, want %q", name, health.Problems, tt.want)
+		}
+	}
+
+	unreachable := CheckRPCHealth(context.Background(), NewRPCClient("http://127.0.0.1:0"), OPMainnetChainID, DefaultMaxBlockAge)
+	if unreachable.Healthy() || !strings.HasPrefix(unreachable.Problems[0], "unreachable") {
+		t.Errorf("unexpected health of unreachable endpoint: %+v", unreachable)
+	}
+}
+
+func TestHealthyRPCClient(t *testing.T) {
+	wrongChain := healthyNode()
+	wrongChain.chainID = MainnetChainID
+	bad := newFakeNode(t, wrongChain)
+	good := newFakeNode(t, healthyNode())
+
+	client, checked, err := HealthyRPCClient(context.Background(), []string{bad.URL, good.URL}, OPMainnetChainID, DefaultMaxBlockAge)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if client.URL != good.URL || len(checked) != 2 || checked[0].Healthy() {
+		t.Fatalf("picked %s after %+v", client.URL, checked)
+	}
+
+	_, _, err = HealthyRPCClient(context.Background(), []string{bad.URL}, OPMainnetChainID, DefaultMaxBlockAge)
+	if err == nil || !strings.Contains(err.Error(), bad.URL+": serves chain 1, not chain 10") {
+		t.Fatalf("expected no healthy endpoint, got %v", err)
+	}
+}
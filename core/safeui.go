@@ -0,0 +1,238 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"net/url"
+	"strings"
+)
+
+// QueueItemKind identifies the kind of item a Safe UI transaction link points at
+type QueueItemKind string
+
+// Kinds of items that appear in the Safe UI transaction list
+const (
+	QueueItemMultisig QueueItemKind = "multisig"
+	QueueItemTransfer QueueItemKind = "transfer"
+	QueueItemModule   QueueItemKind = "module"
+)
+
+// QueueItem is a parsed Safe UI transaction link or item ID
+type QueueItem struct {
+	Kind QueueItemKind
+	// Safe is the Safe address, including its chain prefix when the link provided one
+	Safe string
+	// ID is the service identifier of the item: a safeTxHash for multisig items, or the
+	// transfer/module transaction ID for the other kinds
+	ID string
+}
+
+// ParseQueueItem parses a Safe UI link (https://app.safe.global/transactions/tx?safe=...&id=...),
+// a bare item ID (multisig_0xSafe_0xHash, transfer_0xSafe_<id>, module_0xSafe_<id>), or a bare safeTxHash
+func ParseQueueItem(input string) (*QueueItem, error) {
+	input = strings.TrimSpace(input)
+	item := &QueueItem{}
+
+	id := input
+	if strings.Contains(input, "://") {
+		parsed, err := url.Parse(input)
+		if err != nil {
+			return nil, fmt.Errorf("invalid Safe UI link: %w", err)
+		}
+		id = parsed.Query().Get("id")
+		item.Safe = parsed.Query().Get("safe")
+		if id == "" {
+			return nil, fmt.Errorf("Safe UI link has no id parameter")
+		}
+	}
+
+	parts := strings.Split(id, "_")
+	switch {
+	case len(parts) == 1:
+		item.Kind = QueueItemMultisig
+		item.ID = parts[0]
+	case len(parts) >= 3 && QueueItemKind(parts[0]) == QueueItemMultisig,
+		len(parts) >= 3 && QueueItemKind(parts[0]) == QueueItemTransfer,
+		len(parts) >= 3 && QueueItemKind(parts[0]) == QueueItemModule:
+		item.Kind = QueueItemKind(parts[0])
+		item.ID = parts[len(parts)-1]
+		if item.Safe == "" {
+			item.Safe = parts[1]
+		}
+	default:
+		return nil, fmt.Errorf("unrecognized Safe UI item id %q", id)
+	}
+
+	if item.Kind == QueueItemMultisig && (len(item.ID) != 66 || !strings.HasPrefix(item.ID, "0x")) {
+		return nil, fmt.Errorf("invalid safeTxHash %q", item.ID)
+	}
+
+	return item, nil
+}
+
+// SafeUILink returns the Safe UI link for a multisig transaction. The second return value is
+// false when the chain has no known Safe UI prefix.
+func SafeUILink(chainID uint64, safe, safeTxHash string) (string, bool) {
+	for prefix, id := range ChainPrefixes {
+		if id != chainID {
+			continue
+		}
+		address := ChecksumAddress(StripChainPrefix(safe))
+		return fmt.Sprintf("https://app.safe.global/transactions/tx?safe=%s:%s&id=multisig_%s_%s", prefix, address, address, strings.ToLower(safeTxHash)), true
+	}
+	return "", false
+}
+
+// Explain describes what a queue item kind is and whether it needs a signature
+func (k QueueItemKind) Explain() string {
+	switch k {
+	case QueueItemMultisig:
+		return "a multisig transaction that owners sign with their keys"
+	case QueueItemTransfer:
+		return "a record of assets already moved into or out of the Safe; there is nothing to sign"
+	case QueueItemModule:
+		return "a transaction executed by an enabled Safe module without owner signatures; there is nothing to sign"
+	default:
+		return "an unknown item type"
+	}
+}
+
+// TokenInfo describes the token involved in a transfer
+type TokenInfo struct {
+	Type     string `json:"type"`
+	Address  string `json:"address"`
+	Name     string `json:"name"`
+	Symbol   string `json:"symbol"`
+	Decimals int    `json:"decimals"`
+}
+
+// Transfer is a native or token transfer record from the Safe service
+type Transfer struct {
+	Type            string     `json:"type"`
+	ExecutionDate   string     `json:"executionDate"`
+	BlockNumber     int        `json:"blockNumber"`
+	TransactionHash string     `json:"transactionHash"`
+	From            string     `json:"from"`
+	To              string     `json:"to"`
+	Value           string     `json:"value"`
+	TokenID         string     `json:"tokenId"`
+	TokenAddress    string     `json:"tokenAddress"`
+	TransferID      string     `json:"transferId"`
+	TokenInfo       *TokenInfo `json:"tokenInfo"`
+}
+
+// TransferResult is a transfer record together with the context needed to render it
+type TransferResult struct {
+	Safe     string   `json:"safe"`
+	Chain    int      `json:"chain"`
+	Transfer Transfer `json:"transfer"`
+}
+
+// Asset returns a readable name of the transferred asset
+func (t Transfer) Asset() string {
+	if t.TokenInfo != nil && t.TokenInfo.Symbol != "" {
+		return t.TokenInfo.Symbol
+	}
+	if t.TokenAddress != "" {
+		return t.TokenAddress
+	}
+	return "ETH"
+}
+
+// DisplayAmount returns the transferred amount adjusted for token decimals
+func (t Transfer) DisplayAmount() string {
+	if t.TokenID != "" && t.Value == "" {
+		return "token #" + t.TokenID
+	}
+	value, ok := new(big.Int).SetString(t.Value, 10)
+	if !ok {
+		return t.Value
+	}
+	decimals := 18
+	if t.TokenInfo != nil {
+		decimals = t.TokenInfo.Decimals
+	} else if t.TokenAddress != "" {
+		decimals = 0
+	}
+	return ParseDecimals(value, decimals)
+}
+
+// Direction describes the transfer relative to the Safe
+func (r TransferResult) Direction() string {
+	switch {
+	case strings.EqualFold(r.Transfer.To, r.Safe):
+		return "INCOMING"
+	case strings.EqualFold(r.Transfer.From, r.Safe):
+		return "OUTGOING"
+	default:
+		return "UNRELATED"
+	}
+}
+
+// queueItemClient returns a client for the Safe service of the chain named by the item's Safe prefix
+func queueItemClient(item *QueueItem) (SafeClient, uint64, error) {
+	chainID, err := ChainIDFromPrefix(item.Safe)
+	if err != nil {
+		return nil, 0, err
+	}
+	if chainID == 0 {
+		return nil, 0, fmt.Errorf("cannot determine chain for Safe %q; use a Safe UI link with a chain prefix (e.g. oeth:0x...)", item.Safe)
+	}
+	apiURL, err := getServiceURL(chainID)
+	if err != nil {
+		return nil, 0, err
+	}
+	return serviceClient(chainID, apiURL), chainID, nil
+}
+
+// FetchQueueItemTransaction fetches the multisig transaction referenced by a Safe UI multisig item
+func FetchQueueItemTransaction(ctx context.Context, item *QueueItem) (*SafeTransaction, error) {
+	if item.Kind != QueueItemMultisig {
+		return nil, fmt.Errorf("item is a %s item, not a multisig transaction: %s", item.Kind, item.Kind.Explain())
+	}
+	client, chainID, err := queueItemClient(item)
+	if err != nil {
+		return nil, err
+	}
+	return FetchTransactionByHashWithClient(ctx, client, chainID, item.ID)
+}
+
+// FetchQueueItemModuleTransaction fetches and decodes the module transaction referenced by a Safe UI module item
+func FetchQueueItemModuleTransaction(ctx context.Context, item *QueueItem, options VerifyOptions) (*ModuleTransactionResult, error) {
+	if item.Kind != QueueItemModule {
+		return nil, fmt.Errorf("item is a %s item, not a module transaction", item.Kind)
+	}
+	client, chainID, err := queueItemClient(item)
+	if err != nil {
+		return nil, err
+	}
+	return FetchModuleTransactionWithClient(ctx, client, chainID, item.ID, options)
+}
+
+// FetchTransfer fetches a transfer record for a Safe UI transfer item
+func FetchTransfer(ctx context.Context, item *QueueItem) (*TransferResult, error) {
+	client, chainID, err := queueItemClient(item)
+	if err != nil {
+		return nil, err
+	}
+	return FetchTransferWithClient(ctx, client, chainID, item)
+}
+
+// FetchTransferWithClient fetches a transfer record for a Safe UI transfer item using the given client
+func FetchTransferWithClient(ctx context.Context, client SafeClient, chainID uint64, item *QueueItem) (*TransferResult, error) {
+	if item.Kind != QueueItemTransfer {
+		return nil, fmt.Errorf("item is a %s item, not a transfer", item.Kind)
+	}
+
+	transfer, err := client.GetTransfer(ctx, item.ID)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching transfer %s: %w", item.ID, err)
+	}
+
+	return &TransferResult{
+		Safe:     StripChainPrefix(item.Safe),
+		Chain:    int(chainID),
+		Transfer: *transfer,
+	}, nil
+}
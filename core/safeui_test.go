@@ -0,0 +1,151 @@
+package core
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestParseQueueItem(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		kind    QueueItemKind
+		safe    string
+		id      string
+		wantErr bool
+	}{
+		{
+			name:  "multisig link",
+			input: "https://app.safe.global/transactions/tx?safe=oeth:" + fixtureGrantsSafe + "&id=multisig_" + fixtureGrantsSafe + "_" + fixtureGrantsHash,
+			kind:  QueueItemMultisig,
+			safe:  "oeth:" + fixtureGrantsSafe,
+			id:    fixtureGrantsHash,
+		},
+		{
+			name:  "transfer link",
+			input: "https://app.safe.global/transactions/tx?safe=oeth:" + fixtureGrantsSafe + "&id=transfer_" + fixtureGrantsSafe + "_" + fixtureTransferID,
+			kind:  QueueItemTransfer,
+			safe:  "oeth:" + fixtureGrantsSafe,
+			id:    fixtureTransferID,
+		},
+		{
+			name:  "bare module id",
+			input: "module_" + fixtureGrantsSafe + "_i5a6754140f",
+			kind:  QueueItemModule,
+			safe:  fixtureGrantsSafe,
+			id:    "i5a6754140f",
+		},
+		{
+			name:  "bare hash",
+			input: fixtureGrantsHash,
+			kind:  QueueItemMultisig,
+			id:    fixtureGrantsHash,
+		},
+		{
+			name:    "link without id",
+			input:   "https://app.safe.global/transactions/queue?safe=oeth:" + fixtureGrantsSafe,
+			wantErr: true,
+		},
+		{
+			name:    "unknown item type",
+			input:   "swap_" + fixtureGrantsSafe + "_123",
+			wantErr: true,
+		},
+		{
+			name:    "malformed multisig hash",
+			input:   "multisig_" + fixtureGrantsSafe + "_0x1234",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			item, err := ParseQueueItem(tt.input)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got %+v", item)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if item.Kind != tt.kind || item.Safe != tt.safe || item.ID != tt.id {
+				t.Errorf("got %+v, want kind=%s safe=%s id=%s", item, tt.kind, tt.safe, tt.id)
+			}
+		})
+	}
+}
+
+func TestQueueItemKindExplainsTransfers(t *testing.T) {
+	item, err := ParseQueueItem("transfer_" + fixtureGrantsSafe + "_" + fixtureTransferID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if explanation := item.Kind.Explain(); !strings.Contains(explanation, "nothing to sign") {
+		t.Errorf("explanation should say there is nothing to sign, got: %s", explanation)
+	}
+}
+
+func TestFetchTransferWithClient(t *testing.T) {
+	server := newFixtureServer(t, defaultFixtureRoutes())
+	client := NewHTTPSafeClient(server.URL)
+
+	item := &QueueItem{Kind: QueueItemTransfer, Safe: "oeth:" + fixtureGrantsSafe, ID: fixtureTransferID}
+	result, err := FetchTransferWithClient(context.Background(), client, OPMainnetChainID, item)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if result.Safe != fixtureGrantsSafe || result.Chain != OPMainnetChainID {
+		t.Errorf("unexpected safe/chain: %s %d", result.Safe, result.Chain)
+	}
+	if result.Direction() != "INCOMING" {
+		t.Errorf("direction = %s, want INCOMING", result.Direction())
+	}
+	if result.Transfer.Asset() != "OP" {
+		t.Errorf("asset = %s, want OP", result.Transfer.Asset())
+	}
+	if amount := result.Transfer.DisplayAmount(); amount != "250,000.00" {
+		t.Errorf("amount = %s, want 250,000.00", amount)
+	}
+}
+
+func TestFetchTransferWithClientRejectsMultisig(t *testing.T) {
+	server := newFixtureServer(t, defaultFixtureRoutes())
+	client := NewHTTPSafeClient(server.URL)
+
+	item := &QueueItem{Kind: QueueItemMultisig, Safe: fixtureGrantsSafe, ID: fixtureGrantsHash}
+	if _, err := FetchTransferWithClient(context.Background(), client, OPMainnetChainID, item); err == nil {
+		t.Fatalf("expected error for multisig item")
+	}
+}
+
+func TestTransferDisplayAmountNative(t *testing.T) {
+	transfer := Transfer{Type: "ETHER_TRANSFER", Value: "1500000000000000000"}
+	if transfer.Asset() != "ETH" {
+		t.Errorf("asset = %s, want ETH", transfer.Asset())
+	}
+	if amount := transfer.DisplayAmount(); amount != "1.5" {
+		t.Errorf("amount = %s, want 1.5", amount)
+	}
+}
+
+func TestSafeUILinkRoundTrips(t *testing.T) {
+	link, ok := SafeUILink(OPMainnetChainID, fixtureGrantsSafe, fixtureGrantsHash)
+	if !ok {
+		t.Fatal("expected a link for OP Mainnet")
+	}
+	item, err := ParseQueueItem(link)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if item.Kind != QueueItemMultisig || item.Safe != "oeth:"+fixtureGrantsSafe || item.ID != fixtureGrantsHash {
+		t.Errorf("unexpected item %+v from %s", item, link)
+	}
+
+	if _, ok := SafeUILink(12345, fixtureGrantsSafe, fixtureGrantsHash); ok {
+		t.Error("expected no link for an unknown chain")
+	}
+}
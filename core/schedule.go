@@ -0,0 +1,156 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// DeadlineWarningPeriod is how close to its deadline a transaction is flagged as urgent
+const DeadlineWarningPeriod = 24 * time.Hour
+
+// SigningWindow is the time a transaction may be signed in, such as the period before a
+// Security Council veto deadline. Either end may be open.
+type SigningWindow struct {
+	SafeTxHash  string     `json:"safeTxHash,omitempty"`
+	Opens       *time.Time `json:"opens,omitempty"`
+	Closes      *time.Time `json:"closes,omitempty"`
+	Description string     `json:"description,omitempty"`
+}
+
+// Validate checks that a window has an end and does not close before it opens
+func (w SigningWindow) Validate() error {
+	if w.Opens == nil && w.Closes == nil {
+		return fmt.Errorf("a signing window needs an opening time, a deadline, or both")
+	}
+	if w.Opens != nil && w.Closes != nil && !w.Opens.Before(*w.Closes) {
+		return fmt.Errorf("the signing window closes at %s, before it opens at %s", w.Closes.Format(time.RFC3339), w.Opens.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// Schedule is a registry of signing windows, keyed by lowercase Safe tx hash
+type Schedule struct {
+	Windows map[string]SigningWindow
+}
+
+// scheduleManifest is the JSON form of a schedule file
+type scheduleManifest struct {
+	Windows []SigningWindow `json:"windows"`
+}
+
+// NewSchedule creates an empty schedule
+func NewSchedule() *Schedule {
+	return &Schedule{Windows: map[string]SigningWindow{}}
+}
+
+// DefaultSchedulePath returns the schedule file that is loaded automatically when present
+func DefaultSchedulePath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "op-txverify", "schedule.json"), nil
+}
+
+// LoadScheduleFile adds the windows of a schedule file:
+// {"windows": [{"safeTxHash": "0x...", "closes": "2025-06-01T12:00:00Z", "description": "..."}]}
+func (s *Schedule) LoadScheduleFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read schedule: %w", err)
+	}
+	var manifest scheduleManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return fmt.Errorf("invalid schedule %s: %w", path, err)
+	}
+	for _, window := range manifest.Windows {
+		if hash, err := hexutil.Decode(window.SafeTxHash); err != nil || len(hash) != 32 {
+			return fmt.Errorf("invalid schedule %s: invalid safeTxHash %q", path, window.SafeTxHash)
+		}
+		if err := window.Validate(); err != nil {
+			return fmt.Errorf("invalid schedule %s: %s: %w", path, window.SafeTxHash, err)
+		}
+		s.Windows[strings.ToLower(window.SafeTxHash)] = window
+	}
+	return nil
+}
+
+// Lookup returns the window of a result's Safe tx hash, or of its approved child transaction
+func (s *Schedule) Lookup(result *VerificationResult) (SigningWindow, bool) {
+	for _, r := range []*VerificationResult{result, result.NestedResult} {
+		if r == nil {
+			continue
+		}
+		if window, ok := s.Windows[strings.ToLower(r.ApproveHash)]; ok {
+			return window, true
+		}
+	}
+	return SigningWindow{}, false
+}
+
+// ScheduleStatus is where a transaction stood in its signing window when it was verified
+type ScheduleStatus struct {
+	SigningWindow
+	CheckedAt time.Time `json:"checkedAt"`
+
+	// Remaining is the time left until the deadline, when there is one and it has not passed
+	Remaining string `json:"remaining,omitempty"`
+}
+
+// CheckSchedule records a transaction's signing window on the result. Signing before the window
+// opens or after it closes is a critical warning, and a deadline less than
+// DeadlineWarningPeriod away is a warning.
+func CheckSchedule(result *VerificationResult, window SigningWindow, now time.Time) {
+	status := &ScheduleStatus{SigningWindow: window, CheckedAt: now}
+	result.Schedule = status
+
+	if window.Opens != nil && now.Before(*window.Opens) {
+		result.Warnings = append(result.Warnings, newWarning(warnWindowNotOpen,
+			"the signing window opens at %s, in %s; a signature made now is outside the allowed window",
+			window.Opens.UTC().Format(time.RFC3339), FormatCountdown(window.Opens.Sub(now))))
+	}
+	if window.Closes == nil {
+		return
+	}
+	remaining := window.Closes.Sub(now)
+	if remaining <= 0 {
+		result.Warnings = append(result.Warnings, newWarning(warnDeadlinePassed,
+			"the deadline passed at %s, %s ago; a signature made now is outside the allowed window",
+			window.Closes.UTC().Format(time.RFC3339), FormatCountdown(-remaining)))
+		return
+	}
+	status.Remaining = FormatCountdown(remaining)
+	if remaining < DeadlineWarningPeriod {
+		result.Warnings = append(result.Warnings, newWarning(warnDeadlineSoon,
+			"the deadline is at %s, %s from now", window.Closes.UTC().Format(time.RFC3339), status.Remaining))
+	}
+}
+
+// FormatCountdown renders a duration in days, hours, and minutes, such as "2d 4h 10m"
+func FormatCountdown(d time.Duration) string {
+	d = d.Round(time.Minute)
+	if d < time.Minute {
+		return "less than a minute"
+	}
+	days := d / (24 * time.Hour)
+	hours := (d % (24 * time.Hour)) / time.Hour
+	minutes := (d % time.Hour) / time.Minute
+
+	var parts []string
+	if days > 0 {
+		parts = append(parts, fmt.Sprintf("%dd", days))
+	}
+	if hours > 0 {
+		parts = append(parts, fmt.Sprintf("%dh", hours))
+	}
+	if minutes > 0 {
+		parts = append(parts, fmt.Sprintf("%dm", minutes))
+	}
+	return strings.Join(parts, " ")
+}
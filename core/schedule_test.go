@@ -0,0 +1,103 @@
+package core
+
+import (
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func scheduleResult(t *testing.T) *VerificationResult {
+	t.Helper()
+	tx := multiSendTx(t, nativeTransfer(airdropAlice, big.NewInt(1)), nativeTransfer(airdropBob, big.NewInt(2)))
+	tx.Safe = effectsSafe
+	tx.SafeVersion = "1.3.0"
+	result, err := VerifyTransaction(tx, VerifyOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return result
+}
+
+func TestCheckSchedule(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	at := func(d time.Duration) *time.Time {
+		t := now.Add(d)
+		return &t
+	}
+
+	tests := map[string]struct {
+		window    SigningWindow
+		severity  Severity
+		remaining string
+	}{
+		"inside the window":  {SigningWindow{Opens: at(-time.Hour), Closes: at(50*time.Hour + 10*time.Minute)}, "", "2d 2h 10m"},
+		"deadline is close":  {SigningWindow{Closes: at(3*time.Hour + 5*time.Minute)}, SeverityWarning, "3h 5m"},
+		"window not open":    {SigningWindow{Opens: at(90 * time.Minute), Closes: at(48 * time.Hour)}, SeverityCritical, "2d"},
+		"deadline passed":    {SigningWindow{Closes: at(-30 * time.Second)}, SeverityCritical, ""},
+		"no deadline at all": {SigningWindow{Opens: at(-time.Hour)}, "", ""},
+	}
+	for name, tt := range tests {
+		result := scheduleResult(t)
+		before := len(result.Warnings)
+		CheckSchedule(result, tt.window, now)
+
+		if result.Schedule == nil || result.Schedule.Remaining != tt.remaining || !result.Schedule.CheckedAt.Equal(now) {
+			t.Errorf("%s: unexpected status %+v", name, result.Schedule)
+		}
+		added := result.Warnings[before:]
+		switch {
+		case tt.severity == "" && len(added) != 0:
+			t.Errorf("%s: unexpected warnings %+v", name, added)
+		case tt.severity != "" && (len(added) != 1 || added[0].Severity != tt.severity):
+			t.Errorf("%s: warnings = %+v, want one %s", name, added, tt.severity)
+		}
+	}
+}
+
+func TestScheduleLookup(t *testing.T) {
+	result := scheduleResult(t)
+	path := filepath.Join(t.TempDir(), "schedule.json")
+	manifest := `{"windows": [{"safeTxHash": "0x` + strings.ToUpper(result.ApproveHash[2:]) + `", "closes": "2025-06-01T12:00:00Z", "description": "Veto period ends"}]}`
+	if err := os.WriteFile(path, []byte(manifest), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	schedule := NewSchedule()
+	if err := schedule.LoadScheduleFile(path); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	window, ok := schedule.Lookup(result)
+	if !ok || window.Description != "Veto period ends" || window.Closes == nil || window.Closes.Hour() != 12 {
+		t.Fatalf("unexpected window %+v, %v", window, ok)
+	}
+
+	// A nested approval is found by the hash of its child transaction
+	parent := &VerificationResult{ApproveHash: "0x" + strings.Repeat("11", 32), NestedResult: result}
+	if _, ok := schedule.Lookup(parent); !ok {
+		t.Errorf("window of the child transaction not found")
+	}
+	if _, ok := schedule.Lookup(&VerificationResult{ApproveHash: parent.ApproveHash}); ok {
+		t.Errorf("unexpected window for another transaction")
+	}
+}
+
+func TestLoadScheduleFileRejectsInvalidWindows(t *testing.T) {
+	hash := "0x" + strings.Repeat("ab", 32)
+	for name, window := range map[string]string{
+		"short hash":       `{"safeTxHash": "0x1234", "closes": "2025-06-01T12:00:00Z"}`,
+		"no times":         `{"safeTxHash": "` + hash + `"}`,
+		"closes too early": `{"safeTxHash": "` + hash + `", "opens": "2025-06-02T00:00:00Z", "closes": "2025-06-01T12:00:00Z"}`,
+		"invalid time":     `{"safeTxHash": "` + hash + `", "closes": "June 1st"}`,
+	} {
+		path := filepath.Join(t.TempDir(), "schedule.json")
+		if err := os.WriteFile(path, []byte(`{"windows": [`+window+`]}`), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if err := NewSchedule().LoadScheduleFile(path); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
@@ -0,0 +1,368 @@
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// TransactionFieldError describes a field of transaction JSON that does not match the
+// SafeTransaction schema, in place of the encoding/json error, which names neither the field's
+// full path nor what would have been accepted
+type TransactionFieldError struct {
+	// Path is the JSON path of the field, such as $.nested.nonce or $.tx.value
+	Path string
+
+	// Expected is the type the field must have; empty for an unknown field
+	Expected string
+
+	// Got describes the value that was given
+	Got string
+
+	// Hint says how to fix the field, if there is more to say than Expected
+	Hint string
+}
+
+func (e *TransactionFieldError) Error() string {
+	message := fmt.Sprintf("%s: expected %s, got %s", e.Path, e.Expected, e.Got)
+	if e.Expected == "" {
+		message = e.Path + ": unknown field"
+	}
+	if e.Hint != "" {
+		message += "; " + e.Hint
+	}
+	return message
+}
+
+var (
+	bigIntType      = reflect.TypeOf(big.Int{})
+	unmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+)
+
+// decodeTransactionJSON unmarshals transaction JSON, checking it against the SafeTransaction
+// schema first so that a mismatch is reported by checkTransactionSchema. paths maps top-level
+// fields that were read from elsewhere in the original file, such as from its "tx" object or
+// under another name, to their original path.
+func decodeTransactionJSON(data []byte, paths map[string]string) (SafeTransaction, error) {
+	var tx SafeTransaction
+	if err := checkTransactionSchema(data, paths); err != nil {
+		return tx, err
+	}
+	if err := json.Unmarshal(data, &tx); err != nil {
+		return tx, describeJSONError(data, err)
+	}
+	return tx, nil
+}
+
+// checkTransactionSchema checks transaction JSON against the fields and types of
+// SafeTransaction. Unknown fields are ignored, as other tools add their own, unless they are a
+// likely misspelling of a hashed field the JSON does not give, which would otherwise be read as
+// zero without notice.
+func checkTransactionSchema(data []byte, paths map[string]string) error {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return describeJSONError(data, err)
+	}
+	path := func(name string) string {
+		if original, ok := paths[name]; ok {
+			return original
+		}
+		return "$." + name
+	}
+	return checkObjectSchema(fields, reflect.TypeOf(SafeTransaction{}), path)
+}
+
+// misspellingCandidates are, per struct, the field spellings an unknown field is compared with
+// to find a misspelling, mapped to the field they fill. Only the fields that are hashed, and
+// the spellings ParseSafeTransaction reads them from, are compared: a misspelling of one of them
+// changes what is verified, while the other fields hold metadata, which tools write in many
+// shapes of their own.
+var misspellingCandidates = func() map[reflect.Type]map[string]string {
+	transaction := map[string]string{}
+	for _, field := range []string{"safe", "safe_version", "chain", "to", "value", "data", "operation", "safe_tx_gas", "base_gas", "gas_price", "gas_token", "refund_receiver", "nonce", "nested"} {
+		transaction[field] = field
+	}
+	for _, alias := range transactionFieldAliases {
+		if _, hashed := transaction[alias.field]; hashed {
+			for _, name := range alias.aliases {
+				transaction[name] = alias.field
+			}
+		}
+	}
+	nested := map[string]string{}
+	for _, field := range []string{"safe", "safe_version", "nonce", "data", "operation", "to"} {
+		nested[field] = field
+	}
+	return map[reflect.Type]map[string]string{
+		reflect.TypeOf(SafeTransaction{}): transaction,
+		reflect.TypeOf(Nested{}):          nested,
+	}
+}()
+
+// checkObjectSchema checks the fields of a JSON object against a struct type
+func checkObjectSchema(fields map[string]json.RawMessage, t reflect.Type, path func(string) string) error {
+	known := map[string]reflect.Type{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "-" || !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		known[name] = field.Type
+	}
+
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fieldType, ok := known[name]
+		if !ok {
+			// encoding/json matches field names regardless of case
+			for knownName, knownType := range known {
+				if strings.EqualFold(name, knownName) {
+					fieldType, ok = knownType, true
+				}
+			}
+		}
+		if !ok {
+			if suggestion := misspelledField(name, fields, misspellingCandidates[t]); suggestion != "" {
+				return &TransactionFieldError{Path: path(name), Hint: fmt.Sprintf("did you mean %q?", suggestion)}
+			}
+			continue
+		}
+		if err := checkValueSchema(fields[name], fieldType, path(name), name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// misspelledField returns the candidate spelling an unknown field name is a likely misspelling
+// of, or "" if there is none or the field it fills is given too
+func misspelledField(name string, fields map[string]json.RawMessage, candidates map[string]string) string {
+	spellings := make([]string, 0, len(candidates))
+	for spelling := range candidates {
+		spellings = append(spellings, spelling)
+	}
+	sort.Strings(spellings)
+
+	best, bestDistance := "", 3
+	for _, spelling := range spellings {
+		if _, given := fields[candidates[spelling]]; given {
+			continue
+		}
+		distance := editDistance(name, spelling)
+		if distance > 0 && distance < bestDistance && 2*distance < len(name) {
+			best, bestDistance = spelling, distance
+		}
+	}
+	return best
+}
+
+// checkValueSchema checks a JSON value against a Go type, descending into objects and arrays
+func checkValueSchema(value json.RawMessage, t reflect.Type, path, name string) error {
+	value = bytes.TrimSpace(value)
+	if string(value) == "null" {
+		return nil
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	mismatch := func(expected, hint string) error {
+		return &TransactionFieldError{Path: path, Expected: expected, Got: describeJSONValue(value), Hint: hint}
+	}
+
+	switch {
+	case t == bigIntType:
+		return checkIntegerSchema(value, path, name, "")
+	case t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8:
+		if value[0] != '"' {
+			return mismatch("a base64 string", "")
+		}
+		return nil
+	case reflect.PointerTo(t).Implements(unmarshalerType):
+		return nil
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		if value[0] != '"' {
+			hint := "write it in quotes, as a JSON string"
+			if name == "data" {
+				hint = "data is the calldata as a 0x-prefixed hex string, \"0x\" for none"
+			}
+			return mismatch("a string", hint)
+		}
+	case reflect.Bool:
+		if string(value) != "true" && string(value) != "false" {
+			return mismatch("true or false", "")
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return checkIntegerSchema(value, path, name, strconv.Itoa(t.Bits()))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return checkIntegerSchema(value, path, name, "u"+strconv.Itoa(t.Bits()))
+	case reflect.Float32, reflect.Float64:
+		if value[0] != '-' && (value[0] < '0' || value[0] > '9') {
+			return mismatch("a number", "")
+		}
+	case reflect.Struct:
+		var fields map[string]json.RawMessage
+		if value[0] != '{' || json.Unmarshal(value, &fields) != nil {
+			return mismatch("an object", "")
+		}
+		return checkObjectSchema(fields, t, func(field string) string { return path + "." + field })
+	case reflect.Map:
+		var fields map[string]json.RawMessage
+		if value[0] != '{' || json.Unmarshal(value, &fields) != nil {
+			return mismatch("an object", "")
+		}
+		for key, element := range fields {
+			if err := checkValueSchema(element, t.Elem(), path+"."+key, key); err != nil {
+				return err
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		var elements []json.RawMessage
+		if value[0] != '[' || json.Unmarshal(value, &elements) != nil {
+			return mismatch("an array", "")
+		}
+		for i, element := range elements {
+			if err := checkValueSchema(element, t.Elem(), fmt.Sprintf("%s[%d]", path, i), name); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// checkIntegerSchema checks that a JSON value is an integer. bits is the size of the Go integer
+// it is read into, such as "64" or "u8", or "" for a big.Int.
+func checkIntegerSchema(value json.RawMessage, path, name, bits string) error {
+	mismatch := func(expected, hint string) error {
+		return &TransactionFieldError{Path: path, Expected: expected, Got: describeJSONValue(value), Hint: hint}
+	}
+	if value[0] == '"' {
+		var text string
+		json.Unmarshal(value, &text)
+		return mismatch("an integer", integerStringHint(name, text))
+	}
+	if value[0] != '-' && (value[0] < '0' || value[0] > '9') {
+		return mismatch("an integer", "")
+	}
+
+	number, ok := new(big.Float).SetPrec(512).SetString(string(value))
+	if !ok {
+		return mismatch("an integer", "")
+	}
+	if !number.IsInt() {
+		return mismatch("a whole number", fractionHint(name))
+	}
+	if bytes.ContainsAny(value, ".eE") {
+		n, _ := number.Int(nil)
+		return mismatch("an integer written out in full", fmt.Sprintf("write %s instead", n))
+	}
+	if bits == "" {
+		return nil
+	}
+	var err error
+	if strings.HasPrefix(bits, "u") {
+		size, _ := strconv.Atoi(bits[1:])
+		_, err = strconv.ParseUint(string(value), 10, size)
+	} else {
+		size, _ := strconv.Atoi(bits)
+		_, err = strconv.ParseInt(string(value), 10, size)
+	}
+	if err != nil {
+		return mismatch("a "+bits+"-bit integer", "the number is out of range")
+	}
+	return nil
+}
+
+// integerStringHint explains why a string was not read as an integer
+func integerStringHint(name, text string) string {
+	trimmed := strings.TrimSpace(text)
+	switch {
+	case strings.HasPrefix(trimmed, "-"):
+		return name + " must not be negative"
+	case strings.HasPrefix(trimmed, "0x") || strings.HasPrefix(trimmed, "0X"):
+		return "the hex digits are not valid; give a decimal integer or 0x followed by hex digits"
+	case strings.ContainsAny(trimmed, ".eE") && trimmed != "":
+		if _, ok := new(big.Float).SetString(trimmed); ok {
+			return fractionHint(name)
+		}
+	}
+	return name + " must be a decimal or 0x-prefixed hex integer, as a number or a string"
+}
+
+// fractionHint explains that a field takes no fractions, pointing out the unit of the value
+func fractionHint(name string) string {
+	if name == "value" {
+		return "value is in wei, so convert an ether amount to wei (1 ether = 10^18 wei)"
+	}
+	return name + " takes no fractions"
+}
+
+// describeJSONValue describes a JSON value for an error message, by its type and, for
+// scalars, the value itself
+func describeJSONValue(value json.RawMessage) string {
+	switch value[0] {
+	case '{':
+		return "an object"
+	case '[':
+		return "an array"
+	case '"':
+		text := string(value)
+		if len(text) > 42 {
+			text = text[:41] + `…"`
+		}
+		if strings.HasPrefix(text, `"0x`) || strings.HasPrefix(text, `"0X`) {
+			return "the hex string " + text
+		}
+		return "the string " + text
+	case 't', 'f':
+		return "the boolean " + string(value)
+	}
+	return "the number " + string(value)
+}
+
+// describeJSONError turns an encoding/json error into one that gives the line and column of a
+// syntax error, or the path and expected type of a type mismatch
+func describeJSONError(data []byte, err error) error {
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		// Offset is just past the character that could not be read
+		line, column := 1, 1
+		for _, b := range data[:min(max(int(syntaxErr.Offset)-1, 0), len(data))] {
+			if b == '\n' {
+				line, column = line+1, 1
+			} else {
+				column++
+			}
+		}
+		return fmt.Errorf("invalid JSON at line %d, column %d: %w", line, column, err)
+	}
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		path := "$"
+		if typeErr.Field != "" {
+			path += "." + typeErr.Field
+		}
+		expected := typeErr.Type.String()
+		if typeErr.Type.Kind() == reflect.Map || typeErr.Type.Kind() == reflect.Struct {
+			expected = "an object"
+		}
+		return &TransactionFieldError{Path: path, Expected: expected, Got: "a JSON " + typeErr.Value}
+	}
+	return err
+}
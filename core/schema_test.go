@@ -0,0 +1,152 @@
+package core
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParseSafeTransactionFieldErrors(t *testing.T) {
+	for name, test := range map[string]struct {
+		data string
+		path string
+		want string
+	}{
+		"fractional value": {
+			data: `{"to": "0x01", "value": 1.5}`,
+			path: "$.value",
+			want: "expected a whole number, got the number 1.5; value is in wei",
+		},
+		"fractional value string": {
+			data: `{"value": "0.25"}`,
+			path: "$.value",
+			want: "convert an ether amount to wei",
+		},
+		"exponent": {
+			data: `{"value": 1e18}`,
+			path: "$.value",
+			want: "write 1000000000000000000 instead",
+		},
+		"bad hex": {
+			data: `{"nonce": "0xzz"}`,
+			path: "$.nonce",
+			want: "got the hex string \"0xzz\"; the hex digits are not valid",
+		},
+		"negative string": {
+			data: `{"value": "-1"}`,
+			path: "$.value",
+			want: "value must not be negative",
+		},
+		"word for a number": {
+			data: `{"nonce": "seven"}`,
+			path: "$.nonce",
+			want: "nonce must be a decimal or 0x-prefixed hex integer",
+		},
+		"boolean operation": {
+			data: `{"operation": true}`,
+			path: "$.operation",
+			want: "expected an integer, got the boolean true",
+		},
+		"out of range": {
+			data: `{"safe_tx_gas": 99999999999999999999999}`,
+			path: "$.safe_tx_gas",
+			want: "the number is out of range",
+		},
+		"number for data": {
+			data: `{"data": 0}`,
+			path: "$.data",
+			want: "data is the calldata as a 0x-prefixed hex string",
+		},
+		"array for an address": {
+			data: `{"to": ["0x01"]}`,
+			path: "$.to",
+			want: "expected a string, got an array",
+		},
+		"nested field": {
+			data: `{"nested": {"nonce": "3"}}`,
+			path: "$.nested.nonce",
+			want: "expected an integer, got the string \"3\"",
+		},
+		"nested not an object": {
+			data: `{"nested": "0x1234"}`,
+			path: "$.nested",
+			want: "expected an object",
+		},
+		"field of the tx object": {
+			data: `{"safe": "0x01", "tx": {"to": "0x02", "value": 0.1}}`,
+			path: "$.tx.value",
+			want: "expected a whole number",
+		},
+		"field under another name": {
+			data: `{"safeTxGas": "ten"}`,
+			path: "$.safeTxGas",
+			want: "expected an integer",
+		},
+		"typed data message": {
+			data: `{"types": {"SafeTx": []}, "primaryType": "SafeTx", "domain": {"verifyingContract": "0x01", "chainId": 10}, "message": {"to": "0x02", "value": "1", "data": "0x", "operation": [], "safeTxGas": 0, "baseGas": 0, "gasPrice": 0, "gasToken": "0x00", "refundReceiver": "0x00", "nonce": 1}}`,
+			path: "$.message.operation",
+			want: "expected an integer, got an array",
+		},
+		"misspelled field": {
+			data: `{"to": "0x01", "nonec": 5}`,
+			path: "$.nonec",
+			want: `unknown field; did you mean "nonce"?`,
+		},
+		"misspelled alias": {
+			data: `{"to": "0x01", "safeTxGass": 5}`,
+			path: "$.safeTxGass",
+			want: `did you mean "safeTxGas"?`,
+		},
+		"misspelled nested field": {
+			data: `{"nested": {"safe": "0x01", "opration": 1}}`,
+			path: "$.nested.opration",
+			want: `did you mean "operation"?`,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, _, err := ParseSafeTransaction([]byte(test.data))
+			var fieldErr *TransactionFieldError
+			if !errors.As(err, &fieldErr) {
+				t.Fatalf("expected a field error, got %v", err)
+			}
+			if fieldErr.Path != test.path || !strings.Contains(err.Error(), test.want) {
+				t.Errorf("error %q, want path %s and %q", err, test.path, test.want)
+			}
+		})
+	}
+}
+
+func TestParseSafeTransactionUnknownFields(t *testing.T) {
+	// Fields other tools add are ignored, as is a misspelling of a field that is given as well
+	for _, data := range []string{
+		`{"to": "0x01", "nonce": 1, "origin": "{}", "sender": "0x02", "executor": null, "dataDecoded": {}}`,
+		`{"to": "0x01", "nonce": 1, "nonec": 2}`,
+		`{"to": "0x01", "NONCE": 1}`,
+		`{"to": "0x01", "id": "multisig_0x01"}`,
+	} {
+		if _, _, err := ParseSafeTransaction([]byte(data)); err != nil {
+			t.Errorf("%s: unexpected error: %v", data, err)
+		}
+	}
+}
+
+func TestParseSafeTransactionSyntaxErrors(t *testing.T) {
+	_, _, err := ParseSafeTransaction([]byte("{\n  \"to\": \"0x01\",\n  \"nonce\": 1,\n}"))
+	if err == nil || !strings.Contains(err.Error(), "line 4, column 1") {
+		t.Errorf("expected the position of the trailing comma, got %v", err)
+	}
+
+	_, _, err = ParseSafeTransaction([]byte(`[1, 2]`))
+	var fieldErr *TransactionFieldError
+	if !errors.As(err, &fieldErr) || fieldErr.Path != "$" || fieldErr.Expected != "an object" {
+		t.Errorf("expected the file to be reported as not an object, got %v", err)
+	}
+}
+
+func TestDecodeTransactionPayloadFieldErrors(t *testing.T) {
+	_, err := DecodeTransactionPayload([]byte(`{"to": "0x01", "value": 0.5}`))
+	var fieldErr *TransactionFieldError
+	if !errors.As(err, &fieldErr) || fieldErr.Path != "$.value" {
+		t.Errorf("expected a field error for the value, got %v", err)
+	}
+}
@@ -0,0 +1,79 @@
+package core
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// ServiceCache keeps the responses of the Safe Transaction Service, one file per URL, so that a
+// transaction fetched before an outage can still be verified during it. Responses are public
+// data; nothing secret is cached.
+type ServiceCache struct {
+	Dir string
+}
+
+// cachedResponse is a service response as the cache keeps it
+type cachedResponse struct {
+	URL     string          `json:"url"`
+	Fetched time.Time       `json:"fetched"`
+	Body    json.RawMessage `json:"body"`
+}
+
+// DefaultServiceCacheDir returns the directory the service responses are cached in
+func DefaultServiceCacheDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "op-txverify", "safe-service"), nil
+}
+
+// path returns the file a URL's response is cached in
+func (c *ServiceCache) path(url string) string {
+	sum := sha256.Sum256([]byte(url))
+	return filepath.Join(c.Dir, hex.EncodeToString(sum[:])+".json")
+}
+
+// Store caches the response to a URL, replacing any earlier one
+func (c *ServiceCache) Store(url string, body []byte, fetched time.Time) error {
+	if !json.Valid(body) {
+		return fmt.Errorf("not caching the response of %s: it is not JSON", url)
+	}
+	data, err := json.Marshal(cachedResponse{URL: url, Fetched: fetched.UTC(), Body: body})
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(c.Dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create service cache: %w", err)
+	}
+	// Written through a temporary file so that a crash never leaves a truncated response
+	path := c.path(url)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write service cache: %w", err)
+	}
+	return os.Rename(tmp, path)
+}
+
+// Load returns the cached response to a URL and when it was fetched
+func (c *ServiceCache) Load(url string) ([]byte, time.Time, error) {
+	data, err := os.ReadFile(c.path(url))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, time.Time{}, fmt.Errorf("no cached response for %s", url)
+		}
+		return nil, time.Time{}, fmt.Errorf("failed to read service cache: %w", err)
+	}
+	var cached cachedResponse
+	if err := json.Unmarshal(data, &cached); err != nil || cached.URL != url {
+		return nil, time.Time{}, fmt.Errorf("invalid cached response for %s", url)
+	}
+	return cached.Body, cached.Fetched, nil
+}
@@ -0,0 +1,251 @@
+package core
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// ServiceDecodedCall is the Safe service's own decoding of a transaction's calldata, its
+// dataDecoded. It is not hashed; it is kept to compare with the local decoding, since the two
+// only disagree when one of the decoders has a bug or the service response was tampered with.
+type ServiceDecodedCall struct {
+	Method     string                    `json:"method"`
+	Parameters []ServiceDecodedParameter `json:"parameters"`
+}
+
+// ServiceDecodedParameter is an argument of a call decoded by the Safe service. Scalars are given
+// as strings, and arrays and tuples as JSON arrays.
+type ServiceDecodedParameter struct {
+	Name  string      `json:"name"`
+	Type  string      `json:"type"`
+	Value interface{} `json:"value"`
+
+	// ValueDecoded is the batched calls of a multiSend, as the service decodes them
+	ValueDecoded []ServiceDecodedSubcall `json:"valueDecoded,omitempty"`
+}
+
+// ServiceDecodedSubcall is a call of a multiSend batch, as decoded by the Safe service
+type ServiceDecodedSubcall struct {
+	Operation   APIValue            `json:"operation"`
+	To          string              `json:"to"`
+	Value       APIValue            `json:"value"`
+	DataDecoded *ServiceDecodedCall `json:"dataDecoded"`
+}
+
+// maxDecodingMismatches bounds how many divergences a warning lists
+const maxDecodingMismatches = 5
+
+// newServiceDecodedCall reads the dataDecoded of a service transaction, or returns nil when the
+// service did not decode the calldata or decoded it in a shape that is not understood
+func newServiceDecodedCall(dataDecoded interface{}) *ServiceDecodedCall {
+	if dataDecoded == nil {
+		return nil
+	}
+	raw, err := json.Marshal(dataDecoded)
+	if err != nil {
+		return nil
+	}
+	var decoded ServiceDecodedCall
+	if err := json.Unmarshal(raw, &decoded); err != nil || decoded.Method == "" {
+		return nil
+	}
+	return &decoded
+}
+
+// checkServiceDecoding compares the Safe service's decoding of a transaction with the local one
+// and warns when they diverge, in the method called or in the value of any argument
+func checkServiceDecoding(label string, service *ServiceDecodedCall, call CallData) []Warning {
+	mismatches := CompareServiceDecoding(service, call)
+	if len(mismatches) == 0 {
+		return nil
+	}
+	messages := make([]string, 0, maxDecodingMismatches+1)
+	for i, mismatch := range mismatches {
+		if i == maxDecodingMismatches {
+			messages = append(messages, fmt.Sprintf("and %d more", len(mismatches)-i))
+			break
+		}
+		messages = append(messages, mismatch.String())
+	}
+	return []Warning{newWarning(warnDecodingMismatch,
+		"The Safe service decodes the %s differently from op-txverify: %s. Either decoder may have a bug or the service response was tampered with; decode the calldata with another tool before signing.",
+		label, strings.Join(messages, "; "))}
+}
+
+// CompareServiceDecoding returns where the Safe service's decoding of a call diverges from the
+// local decoding. Calls that only one side decodes are not compared, nor are the arguments of a
+// call the local decoding summarizes, such as a swap.
+func CompareServiceDecoding(service *ServiceDecodedCall, call CallData) []DecodeMismatch {
+	if service == nil || call.FunctionName == "unknown" || call.FunctionName == RejectionFunctionName || call.Deployment != nil && call.FunctionName == "CREATE2 deployment" {
+		return nil
+	}
+	mismatch := func(format string, args ...interface{}) []DecodeMismatch {
+		return []DecodeMismatch{{Path: call.Index, Function: call.FunctionName, Message: fmt.Sprintf(format, args...)}}
+	}
+	if service.Method != call.FunctionName {
+		return mismatch("the service decodes it as a call to %s", service.Method)
+	}
+
+	if len(call.SubCalls) > 0 {
+		var batch []ServiceDecodedSubcall
+		for _, parameter := range service.Parameters {
+			if parameter.ValueDecoded != nil {
+				batch = parameter.ValueDecoded
+			}
+		}
+		if batch == nil || call.FunctionName != "multiSend" {
+			return nil
+		}
+		if len(batch) != len(call.SubCalls) {
+			return mismatch("the service decodes %d batched calls, op-txverify %d", len(batch), len(call.SubCalls))
+		}
+		var mismatches []DecodeMismatch
+		for i, subcall := range call.SubCalls {
+			mismatches = append(mismatches, compareServiceSubcall(batch[i], subcall)...)
+		}
+		return mismatches
+	}
+
+	if call.RawData != "" {
+		return nil
+	}
+	if len(service.Parameters) != len(call.ParsedData) {
+		return mismatch("the service decodes %d arguments, op-txverify %d", len(service.Parameters), len(call.ParsedData))
+	}
+	var mismatches []DecodeMismatch
+	for i, arg := range call.ParsedData {
+		serviceValue := canonicalServiceValue(service.Parameters[i].Value)
+		localValue := canonicalArgumentValue(reflect.ValueOf(arg.Value))
+		if serviceValue != localValue {
+			mismatches = append(mismatches, mismatch("argument %s is %s to the service but %s to op-txverify",
+				arg.Name, shortenDecodedValue(serviceValue), shortenDecodedValue(localValue))...)
+		}
+	}
+	return mismatches
+}
+
+// compareServiceSubcall compares a batched call as the service and op-txverify decode it
+func compareServiceSubcall(service ServiceDecodedSubcall, call CallData) []DecodeMismatch {
+	mismatch := func(format string, args ...interface{}) []DecodeMismatch {
+		return []DecodeMismatch{{Path: call.Index, Function: call.FunctionName, Message: fmt.Sprintf(format, args...)}}
+	}
+	if !strings.EqualFold(service.To, call.Target) {
+		return mismatch("the service decodes its target as %s, op-txverify as %s", service.To, call.Target)
+	}
+	localValue := "0"
+	if call.Value != nil {
+		localValue = call.Value.String()
+	}
+	if serviceValue, ok := new(big.Int).SetString(service.Value.Raw, 10); !ok || serviceValue.String() != localValue {
+		return mismatch("the service decodes its value as %q, op-txverify as %s", service.Value.Raw, localValue)
+	}
+	if delegate := service.Operation.Raw == "1"; delegate != call.IsDelegateCall {
+		operation := "call"
+		if call.IsDelegateCall {
+			operation = "delegatecall"
+		}
+		return mismatch("the service decodes its operation as %s, op-txverify as a %s", service.Operation.Raw, operation)
+	}
+	return CompareServiceDecoding(service.DataDecoded, call)
+}
+
+// canonicalServiceValue writes an argument value decoded by the service in the form
+// canonicalArgumentValue writes a locally decoded one
+func canonicalServiceValue(value interface{}) string {
+	switch value := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return canonicalString(value)
+	case bool:
+		return strconv.FormatBool(value)
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	case []interface{}:
+		elements := make([]string, len(value))
+		for i, element := range value {
+			elements[i] = canonicalServiceValue(element)
+		}
+		return "[" + strings.Join(elements, ",") + "]"
+	}
+	raw, _ := json.Marshal(value)
+	return string(raw)
+}
+
+// canonicalArgumentValue writes a locally decoded argument value as text that is equal for equal
+// values: integers in decimal, addresses and byte strings in lowercase hex, and arrays and tuples
+// as bracketed lists, which is how the service gives tuples
+func canonicalArgumentValue(v reflect.Value) string {
+	if !v.IsValid() || !v.CanInterface() {
+		return ""
+	}
+	switch value := v.Interface().(type) {
+	case *big.Int:
+		if value == nil {
+			return ""
+		}
+		return value.String()
+	case common.Address:
+		return strings.ToLower(value.Hex())
+	case string:
+		return canonicalString(value)
+	}
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return ""
+		}
+		return canonicalArgumentValue(v.Elem())
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10)
+	case reflect.Slice, reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			raw := make([]byte, v.Len())
+			reflect.Copy(reflect.ValueOf(raw), v)
+			return "0x" + hex.EncodeToString(raw)
+		}
+		elements := make([]string, v.Len())
+		for i := range elements {
+			elements[i] = canonicalArgumentValue(v.Index(i))
+		}
+		return "[" + strings.Join(elements, ",") + "]"
+	case reflect.Struct:
+		elements := make([]string, v.NumField())
+		for i := range elements {
+			elements[i] = canonicalArgumentValue(v.Field(i))
+		}
+		return "[" + strings.Join(elements, ",") + "]"
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+// canonicalString lowercases hex strings and booleans, which the service may write in either case
+func canonicalString(value string) string {
+	lower := strings.ToLower(value)
+	if strings.HasPrefix(lower, "0x") || lower == "true" || lower == "false" {
+		return lower
+	}
+	return value
+}
+
+// shortenDecodedValue shortens a long argument value for a warning
+func shortenDecodedValue(value string) string {
+	if value == "" {
+		return "empty"
+	}
+	if len(value) > 80 {
+		return value[:38] + "..." + value[len(value)-38:]
+	}
+	return value
+}
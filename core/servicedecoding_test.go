@@ -0,0 +1,108 @@
+package core
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestGeneratedTransactionServiceDecodingDiverges(t *testing.T) {
+	server := newFixtureServer(t, defaultFixtureRoutes())
+	client := NewHTTPSafeClient(server.URL)
+
+	tx, err := GenerateTransactionWithClient(context.Background(), client, OPMainnetChainID, fixtureGrantsSafe, 155, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if tx.ServiceDecoded == nil || tx.ServiceDecoded.Method != "transfer" {
+		t.Fatalf("expected the service's decoding of the transfer, got %+v", tx.ServiceDecoded)
+	}
+
+	// A service response claiming a smaller amount than the calldata transfers
+	tx.ServiceDecoded.Parameters[1].Value = "4000"
+	result, err := VerifyTransaction(*tx, VerifyOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Warnings) != 1 || !strings.Contains(result.Warnings[0].Message, "top-level call (transfer): argument") ||
+		!strings.Contains(result.Warnings[0].Message, "is 4000 to the service but 4000000000000000000000000 to op-txverify") {
+		t.Fatalf("expected a decoding divergence warning, got %+v", result.Warnings)
+	}
+
+	tx.ServiceDecoded = &ServiceDecodedCall{Method: "approve"}
+	result, err = VerifyTransaction(*tx, VerifyOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Warnings) != 1 || !strings.Contains(result.Warnings[0].Message, "decodes it as a call to approve") {
+		t.Fatalf("expected a method divergence warning, got %+v", result.Warnings)
+	}
+}
+
+func TestCompareServiceDecodingMultiSend(t *testing.T) {
+	multisend := common.HexToAddress(SafeMultisendCallOnly141)
+	token := common.HexToAddress(OPTokenAddress)
+	recipient := common.HexToAddress("0x8B8B2f214D92527BF1B1148DC2E609a4C1c2fd69")
+	transfer := append([]byte{0xa9, 0x05, 0x9c, 0xbb}, common.LeftPadBytes(recipient.Bytes(), 32)...)
+	transfer = append(transfer, common.LeftPadBytes(big.NewInt(5).Bytes(), 32)...)
+
+	batch := encodeMultiSendEntry(0, token, big.NewInt(int64(len(transfer))), transfer)
+	batch = append(batch, encodeMultiSendEntry(0, recipient, big.NewInt(0), nil)...)
+	call, err := ParseTransactionData(multisend.Hex(), "0x"+hex.EncodeToString(encodeMultiSendCall(t, batch)), OPMainnetChainID, VerifyOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	decode := func(dataDecoded string) *ServiceDecodedCall {
+		var raw interface{}
+		if err := json.Unmarshal([]byte(dataDecoded), &raw); err != nil {
+			t.Fatal(err)
+		}
+		return newServiceDecodedCall(raw)
+	}
+	service := `{"method": "multiSend", "parameters": [{"name": "transactions", "type": "bytes", "value": "0x", "valueDecoded": [
+		{"operation": 0, "to": "` + token.Hex() + `", "value": "0", "data": "0x", "dataDecoded": {"method": "transfer", "parameters": [
+			{"name": "to", "type": "address", "value": "` + strings.ToLower(recipient.Hex()) + `"}, {"name": "value", "type": "uint256", "value": "%s"}]}},
+		{"operation": 0, "to": "` + recipient.Hex() + `", "value": "0", "data": null, "dataDecoded": null}]}]}`
+
+	if mismatches := CompareServiceDecoding(decode(strings.Replace(service, "%s", "5", 1)), *call); len(mismatches) != 0 {
+		t.Fatalf("expected the decodings to agree, got %v", mismatches)
+	}
+
+	mismatches := CompareServiceDecoding(decode(strings.Replace(service, "%s", "6", 1)), *call)
+	if len(mismatches) != 1 || mismatches[0].Path != "1" || !strings.Contains(mismatches[0].Message, "is 6 to the service but 5") {
+		t.Fatalf("expected a divergence in the first batched call, got %v", mismatches)
+	}
+
+	dropped := `{"method": "multiSend", "parameters": [{"name": "transactions", "type": "bytes", "value": "0x", "valueDecoded": [
+		{"operation": 1, "to": "` + token.Hex() + `", "value": "0"}]}]}`
+	if mismatches := CompareServiceDecoding(decode(dropped), *call); len(mismatches) != 1 || !strings.Contains(mismatches[0].Message, "decodes 1 batched calls, op-txverify 2") {
+		t.Fatalf("expected a divergence in the number of calls, got %v", mismatches)
+	}
+}
+
+func TestCanonicalArgumentValue(t *testing.T) {
+	type pair struct {
+		Target common.Address
+		Amount *big.Int
+		Data   []byte
+	}
+	local := []pair{{Target: common.HexToAddress("0x8B8B2f214D92527BF1B1148DC2E609a4C1c2fd69"), Amount: big.NewInt(7), Data: []byte{0xab}}}
+	service := []interface{}{[]interface{}{"0x8B8B2f214D92527BF1B1148DC2E609a4C1c2fd69", "7", "0xAB"}}
+
+	if got, want := canonicalArgumentValue(reflect.ValueOf(local)), canonicalServiceValue(service); got != want {
+		t.Errorf("tuple arrays differ: %s, %s", got, want)
+	}
+	if got, want := canonicalArgumentValue(reflect.ValueOf(true)), canonicalServiceValue("True"); got != want {
+		t.Errorf("booleans differ: %s, %s", got, want)
+	}
+	if got, want := canonicalArgumentValue(reflect.ValueOf(uint8(3))), canonicalServiceValue(float64(3)); got != want {
+		t.Errorf("small integers differ: %s, %s", got, want)
+	}
+}
@@ -0,0 +1,230 @@
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ReviewSession records how far a signer got reviewing the calls of one or more transactions,
+// so that a review interrupted halfway through a long batch resumes where it stopped instead of
+// starting over. Transactions are keyed by their Safe tx hash and calls by their index and
+// digest, so progress is only carried over to exactly the calls that were reviewed.
+type ReviewSession struct {
+	Transactions []SessionTransaction `json:"transactions"`
+}
+
+// SessionTransaction is the review progress of one transaction
+type SessionTransaction struct {
+	SafeTxHash string `json:"safeTxHash"`
+
+	// Source is where the transaction was read from, such as a file or a Safe and nonce
+	Source  string       `json:"source,omitempty"`
+	Reviews []CallReview `json:"reviews"`
+
+	// Sections are the sections of the output, such as the summary, marked as reviewed
+	Sections []SectionReview `json:"sections,omitempty"`
+
+	// Acknowledged are the critical warnings the reviewer acknowledged
+	Acknowledged []Acknowledgement `json:"acknowledged,omitempty"`
+}
+
+// SectionReview records that a section of the output was reviewed, and the reviewer's note on it
+type SectionReview struct {
+	Section    string    `json:"section"`
+	ReviewedAt time.Time `json:"reviewedAt"`
+	Note       string    `json:"note,omitempty"`
+}
+
+// Acknowledgement records that the reviewer read a critical warning and chose to go on, and why
+type Acknowledgement struct {
+	Message        string    `json:"message"`
+	AcknowledgedAt time.Time `json:"acknowledgedAt"`
+	Note           string    `json:"note,omitempty"`
+}
+
+// CallReview records that a call was reviewed, and the reviewer's note on it
+type CallReview struct {
+	Index        string    `json:"index"`
+	Digest       string    `json:"digest,omitempty"`
+	FunctionName string    `json:"functionName"`
+	ReviewedAt   time.Time `json:"reviewedAt"`
+	Note         string    `json:"note,omitempty"`
+}
+
+// LoadReviewSessionFile reads the session file at path. A missing file is a new session.
+func LoadReviewSessionFile(path string) (*ReviewSession, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return &ReviewSession{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read session file: %w", err)
+	}
+
+	var session ReviewSession
+	if err := json.Unmarshal(data, &session); err != nil {
+		return nil, fmt.Errorf("invalid session file %s: %w", path, err)
+	}
+	return &session, nil
+}
+
+// SaveReviewSessionFile writes the session to path. The file is replaced in one step, so an
+// interruption while saving leaves the previous progress intact.
+func (s *ReviewSession) SaveReviewSessionFile(path string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0o700); err != nil {
+			return fmt.Errorf("failed to create session directory: %w", err)
+		}
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0o600); err != nil {
+		return fmt.Errorf("failed to write session file: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to write session file: %w", err)
+	}
+	return nil
+}
+
+// Transaction returns the progress of the transaction with a Safe tx hash, adding it to the
+// session if it has none yet
+func (s *ReviewSession) Transaction(safeTxHash, source string) *SessionTransaction {
+	for i := range s.Transactions {
+		if strings.EqualFold(s.Transactions[i].SafeTxHash, safeTxHash) {
+			return &s.Transactions[i]
+		}
+	}
+	s.Transactions = append(s.Transactions, SessionTransaction{SafeTxHash: safeTxHash, Source: source})
+	return &s.Transactions[len(s.Transactions)-1]
+}
+
+// Record marks a call as reviewed at now, with an optional note, replacing any earlier review of
+// the same call
+func (t *SessionTransaction) Record(call *CallData, note string, now time.Time) {
+	review := CallReview{
+		Index:        call.Index,
+		Digest:       call.Digest,
+		FunctionName: call.FunctionName,
+		ReviewedAt:   now.UTC(),
+		Note:         strings.TrimSpace(note),
+	}
+	call.Review = &review
+	for i := range t.Reviews {
+		if t.Reviews[i].Index == review.Index {
+			t.Reviews[i] = review
+			return
+		}
+	}
+	t.Reviews = append(t.Reviews, review)
+}
+
+// RecordSection marks a section of the output as reviewed at now, with an optional note,
+// replacing any earlier review of the same section
+func (t *SessionTransaction) RecordSection(section, note string, now time.Time) {
+	review := SectionReview{Section: section, ReviewedAt: now.UTC(), Note: strings.TrimSpace(note)}
+	for i := range t.Sections {
+		if t.Sections[i].Section == section {
+			t.Sections[i] = review
+			return
+		}
+	}
+	t.Sections = append(t.Sections, review)
+}
+
+// SectionReviewed reports whether a section of the output was marked as reviewed
+func (t *SessionTransaction) SectionReviewed(section string) bool {
+	for _, review := range t.Sections {
+		if review.Section == section {
+			return true
+		}
+	}
+	return false
+}
+
+// Acknowledge records that a critical warning was acknowledged at now, with an optional note
+func (t *SessionTransaction) Acknowledge(warning Warning, note string, now time.Time) {
+	t.Acknowledged = append(t.Acknowledged, Acknowledgement{Message: warning.Message, AcknowledgedAt: now.UTC(), Note: strings.TrimSpace(note)})
+}
+
+// Unacknowledged returns the critical warnings that were not acknowledged. A warning is matched
+// by its message, so a warning whose message changed, such as one naming another address, must
+// be acknowledged again.
+func (t *SessionTransaction) Unacknowledged(warnings []Warning) []Warning {
+	acknowledged := map[string]bool{}
+	for _, ack := range t.Acknowledged {
+		acknowledged[ack.Message] = true
+	}
+	var pending []Warning
+	for _, warning := range warnings {
+		if warning.Severity == SeverityCritical && !acknowledged[warning.Message] {
+			pending = append(pending, warning)
+		}
+	}
+	return pending
+}
+
+// ReviewableCalls returns the calls a reviewer steps through, in order: every call of the
+// transaction that makes no further calls. For a nested approval these are the calls of the
+// approved child transaction, since the parent only approves its hash.
+func ReviewableCalls(result *VerificationResult) []*CallData {
+	root := &result.Call
+	if result.NestedResult != nil {
+		root = &result.NestedResult.Call
+	}
+	var calls []*CallData
+	var visit func(call *CallData)
+	visit = func(call *CallData) {
+		if len(call.SubCalls) == 0 {
+			calls = append(calls, call)
+			return
+		}
+		for i := range call.SubCalls {
+			visit(&call.SubCalls[i])
+		}
+	}
+	visit(root)
+	return calls
+}
+
+// ApplyReviewSession marks the calls of a result that the session records as reviewed, and
+// returns the progress of its transaction for recording further reviews. The progress is also
+// attached to the result as its review record. A recorded review
+// whose call now has another digest or function is not carried over and is warned about, since
+// what was reviewed is not what is being verified.
+func ApplyReviewSession(result *VerificationResult, session *ReviewSession, source string) (*SessionTransaction, []Warning) {
+	progress := session.Transaction(result.ApproveHash, source)
+	result.Review = progress
+	calls := map[string]*CallData{}
+	for _, call := range ReviewableCalls(result) {
+		calls[call.Index] = call
+	}
+
+	var warnings []Warning
+	for _, review := range progress.Reviews {
+		call, ok := calls[review.Index]
+		if !ok || call.Digest != review.Digest || call.FunctionName != review.FunctionName {
+			warnings = append(warnings, newWarning(warnStaleReview, "The session records %s as reviewed, but this transaction does not make that call, so it must be reviewed again", reviewLabel(review)))
+			continue
+		}
+		review := review
+		call.Review = &review
+	}
+	return progress, warnings
+}
+
+// reviewLabel names a reviewed call in messages, such as "#3 transfer"
+func reviewLabel(review CallReview) string {
+	if review.Index == "" {
+		return review.FunctionName
+	}
+	return "#" + review.Index + " " + review.FunctionName
+}
@@ -0,0 +1,166 @@
+package core
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// sessionFixture is a batch of three transfers, the second of which is itself a batch of two
+func sessionFixture() *VerificationResult {
+	transfer := func(index, digest string) CallData {
+		return CallData{Index: index, Target: OPTokenAddress, FunctionName: "transfer", Digest: digest}
+	}
+	return &VerificationResult{
+		ApproveHash: "0xabc0000000000000000000000000000000000000000000000000000000000001",
+		Call: CallData{
+			Target:       SafeMultisendCallOnly141,
+			FunctionName: "multiSend",
+			SubCalls: []CallData{
+				transfer("1", "0x01"),
+				{Index: "2", Target: SafeMultisendCallOnly141, FunctionName: "multiSend", Digest: "0x02", SubCalls: []CallData{
+					transfer("2.1", "0x21"),
+					transfer("2.2", "0x22"),
+				}},
+				transfer("3", "0x03"),
+			},
+		},
+	}
+}
+
+func TestReviewableCalls(t *testing.T) {
+	var got []string
+	for _, call := range ReviewableCalls(sessionFixture()) {
+		got = append(got, call.Index)
+	}
+	if strings.Join(got, ",") != "1,2.1,2.2,3" {
+		t.Errorf("reviewable calls = %v, want the calls that make no further calls in order", got)
+	}
+
+	// A nested approval is reviewed by the calls of its child transaction
+	parent := &VerificationResult{Call: CallData{FunctionName: "approveHash"}, NestedResult: sessionFixture()}
+	if calls := ReviewableCalls(parent); len(calls) != 4 || calls[0].Index != "1" {
+		t.Errorf("expected the child's calls to be reviewed, got %d calls", len(calls))
+	}
+}
+
+func TestReviewSessionResume(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.json")
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	session, err := LoadReviewSessionFile(path)
+	if err != nil || len(session.Transactions) != 0 {
+		t.Fatalf("missing file should load as a new session, got %+v, %v", session, err)
+	}
+	result := sessionFixture()
+	progress, warnings := ApplyReviewSession(result, session, "batch.json")
+	if len(warnings) != 0 {
+		t.Fatalf("unexpected warnings: %v", warnings)
+	}
+	calls := ReviewableCalls(result)
+	progress.Record(calls[0], "", now)
+	progress.Record(calls[1], "  matches the grants sheet ", now)
+	if err := session.SaveReviewSessionFile(path); err != nil {
+		t.Fatalf("unexpected error saving: %v", err)
+	}
+
+	// A later run over the same transaction picks up where the first stopped
+	session, err = LoadReviewSessionFile(path)
+	if err != nil {
+		t.Fatalf("unexpected error loading: %v", err)
+	}
+	result = sessionFixture()
+	progress, warnings = ApplyReviewSession(result, session, "batch.json")
+	if len(warnings) != 0 {
+		t.Fatalf("unexpected warnings: %v", warnings)
+	}
+	calls = ReviewableCalls(result)
+	if calls[0].Review == nil || calls[1].Review == nil || calls[2].Review != nil || calls[3].Review != nil {
+		t.Fatalf("expected exactly #1 and #2.1 to be marked reviewed")
+	}
+	if calls[1].Review.Note != "matches the grants sheet" || !calls[1].Review.ReviewedAt.Equal(now) {
+		t.Errorf("unexpected review: %+v", calls[1].Review)
+	}
+	if progress.Source != "batch.json" || len(session.Transactions) != 1 {
+		t.Errorf("expected one transaction from batch.json, got %+v", session.Transactions)
+	}
+
+	// Reviewing a call again replaces its review
+	progress.Record(calls[1], "", now.Add(time.Hour))
+	if len(progress.Reviews) != 2 || progress.Reviews[1].Note != "" {
+		t.Errorf("expected the review of #2.1 to be replaced, got %+v", progress.Reviews)
+	}
+
+	// Another transaction keeps its own progress
+	other := sessionFixture()
+	other.ApproveHash = "0xabc0000000000000000000000000000000000000000000000000000000000002"
+	ApplyReviewSession(other, session, "other.json")
+	if len(session.Transactions) != 2 || ReviewableCalls(other)[0].Review != nil {
+		t.Errorf("expected a separate, unreviewed transaction, got %+v", session.Transactions)
+	}
+}
+
+func TestApplyReviewSessionChangedCall(t *testing.T) {
+	result := sessionFixture()
+	session := &ReviewSession{Transactions: []SessionTransaction{{
+		SafeTxHash: result.ApproveHash,
+		Reviews: []CallReview{
+			{Index: "1", Digest: "0x01", FunctionName: "transfer"},
+			{Index: "3", Digest: "0xff", FunctionName: "transfer"},
+			{Index: "4", Digest: "0x04", FunctionName: "transfer"},
+		},
+	}}}
+
+	_, warnings := ApplyReviewSession(result, session, "")
+	if len(warnings) != 2 {
+		t.Fatalf("expected warnings for the changed and the missing call, got %v", warnings)
+	}
+	if !strings.Contains(warnings[0].Message, "#3 transfer") {
+		t.Errorf("expected the warning to name the call, got %q", warnings[0].Message)
+	}
+	calls := ReviewableCalls(result)
+	if calls[0].Review == nil || calls[3].Review != nil {
+		t.Errorf("expected only the unchanged call to be marked reviewed")
+	}
+}
+
+func TestReviewSessionAcknowledgements(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	result := sessionFixture()
+	result.Warnings = []Warning{
+		{Severity: SeverityCritical, Message: "Denylisted address 0x000000000000000000000000000000000000dEaD"},
+		{Severity: SeverityCritical, Message: "Ownership transfer"},
+		{Severity: SeverityWarning, Message: "Lookalike address"},
+	}
+	progress, _ := ApplyReviewSession(result, &ReviewSession{}, "")
+	if result.Review != progress {
+		t.Fatal("expected the progress to be attached as the review record")
+	}
+	if pending := progress.Unacknowledged(result.Warnings); len(pending) != 2 {
+		t.Fatalf("expected both critical warnings to need acknowledging, got %v", pending)
+	}
+
+	progress.Acknowledge(result.Warnings[1], "planned handover to the new multisig", now)
+	pending := progress.Unacknowledged(result.Warnings)
+	if len(pending) != 1 || pending[0].Message != result.Warnings[0].Message {
+		t.Fatalf("expected only the denylist warning to be left, got %v", pending)
+	}
+
+	progress.RecordSection("summary", "", now)
+	progress.RecordSection("summary", "checked on the forum post", now.Add(time.Minute))
+	if !progress.SectionReviewed("summary") || progress.SectionReviewed("flow") {
+		t.Error("expected only the summary to be reviewed")
+	}
+	if len(progress.Sections) != 1 || progress.Sections[0].Note != "checked on the forum post" {
+		t.Errorf("expected the summary review to be replaced, got %+v", progress.Sections)
+	}
+
+	redacted := RedactResult(result)
+	if redacted.Review.Acknowledged[0].Note != "" || redacted.Review.Sections[0].Note != "" {
+		t.Errorf("expected the notes to be left out of a redacted review record, got %+v", redacted.Review)
+	}
+	if progress.Acknowledged[0].Note == "" {
+		t.Error("redacting modified the original review record")
+	}
+}
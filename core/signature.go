@@ -0,0 +1,188 @@
+package core
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// SignatureExportType identifies a signature export payload, so that a transaction QR code is
+// never mistaken for a signature and vice versa
+const SignatureExportType = "op-txverify-signature"
+
+// SignatureExport carries an owner's signature of a Safe transaction from the offline signing
+// machine back to an online one
+type SignatureExport struct {
+	Type       string `json:"type"`
+	Safe       string `json:"safe"`
+	Chain      int    `json:"chain"`
+	Nonce      int    `json:"nonce"`
+	SafeTxHash string `json:"safeTxHash"`
+	Signer     string `json:"signer"`
+	Signature  string `json:"signature"`
+}
+
+// SignatureCheck is a signature export that was checked against a verified transaction
+type SignatureCheck struct {
+	Export SignatureExport `json:"export"`
+
+	// Target says which transaction was signed: "transaction", or "child transaction" or
+	// "parent transaction" for nested approvals
+	Target string `json:"target"`
+}
+
+// signableHash is a Safe tx hash an owner may sign for a verified transaction
+type signableHash struct {
+	target string
+	safe   string
+	nonce  int
+	hash   string
+}
+
+// signableHashes returns the Safe tx hashes of a verified transaction: one for a plain
+// transaction, or the child and parent hashes for a nested approval
+func signableHashes(result *VerificationResult) []signableHash {
+	if result.NestedResult == nil {
+		return []signableHash{{"transaction", result.Transaction.Safe, result.Transaction.Nonce, result.ApproveHash}}
+	}
+	child := result.NestedResult
+	return []signableHash{
+		{"child transaction", child.Transaction.Safe, child.Transaction.Nonce, child.ApproveHash},
+		{"parent transaction", result.Transaction.Safe, result.Transaction.Nonce, result.ApproveHash},
+	}
+}
+
+// SignableHash resolves the Safe tx hash to sign for a verified transaction and checks it is one
+// the transaction produces. safeTxHash may be empty unless the transaction is a nested approval,
+// where either hash could be meant.
+func SignableHash(result *VerificationResult, safeTxHash string) (string, error) {
+	candidate, err := findSignableHash(result, safeTxHash)
+	if err != nil {
+		return "", err
+	}
+	return strings.ToLower(candidate.hash), nil
+}
+
+// findSignableHash returns the signable hash that safeTxHash selects
+func findSignableHash(result *VerificationResult, safeTxHash string) (*signableHash, error) {
+	candidates := signableHashes(result)
+	if safeTxHash == "" {
+		if len(candidates) > 1 {
+			return nil, fmt.Errorf("transaction is a nested approval; say whether the child (%s) or parent (%s) hash was signed",
+				candidates[0].hash, candidates[1].hash)
+		}
+		safeTxHash = candidates[0].hash
+	}
+	for i := range candidates {
+		if strings.EqualFold(candidates[i].hash, safeTxHash) {
+			return &candidates[i], nil
+		}
+	}
+	return nil, fmt.Errorf("hash %s is not a Safe tx hash of this transaction", safeTxHash)
+}
+
+// NewSignatureExport checks a 65-byte owner signature against a verified transaction and builds
+// the payload that carries it across the air gap. safeTxHash selects which hash was signed and
+// may be empty unless the transaction is a nested approval, where either hash could be meant.
+func NewSignatureExport(result *VerificationResult, safeTxHash, signature string) (*SignatureExport, error) {
+	candidate, err := findSignableHash(result, safeTxHash)
+	if err != nil {
+		return nil, err
+	}
+	signer, err := RecoverSigner(candidate.hash, signature)
+	if err != nil {
+		return nil, err
+	}
+	return &SignatureExport{
+		Type:       SignatureExportType,
+		Safe:       ChecksumAddress(StripChainPrefix(candidate.safe)),
+		Chain:      result.Transaction.Chain,
+		Nonce:      candidate.nonce,
+		SafeTxHash: strings.ToLower(candidate.hash),
+		Signer:     signer,
+		Signature:  "0x" + strings.ToLower(strings.TrimPrefix(signature, "0x")),
+	}, nil
+}
+
+// CheckSignatureExport parses a scanned signature payload and checks it against the locally
+// verified transaction: the hash must be one the transaction produces and the signature must
+// recover to the claimed signer
+func CheckSignatureExport(data string, result *VerificationResult) (*SignatureCheck, error) {
+	var export SignatureExport
+	if err := json.Unmarshal([]byte(data), &export); err != nil {
+		return nil, withKind(ErrDecodeFailure, fmt.Errorf("failed to parse signature payload: %w", err))
+	}
+	if export.Type != SignatureExportType {
+		return nil, fmt.Errorf("payload is not a signature export (type %q)", export.Type)
+	}
+	if export.Chain != result.Transaction.Chain {
+		return nil, fmt.Errorf("signature is for chain %d but the transaction is on chain %d", export.Chain, result.Transaction.Chain)
+	}
+
+	for _, candidate := range signableHashes(result) {
+		if !strings.EqualFold(candidate.hash, export.SafeTxHash) {
+			continue
+		}
+		safe := ChecksumAddress(StripChainPrefix(candidate.safe))
+		if !strings.EqualFold(safe, export.Safe) || candidate.nonce != export.Nonce {
+			return nil, fmt.Errorf("signature payload names Safe %s nonce %d, but hash %s belongs to Safe %s nonce %d",
+				export.Safe, export.Nonce, export.SafeTxHash, safe, candidate.nonce)
+		}
+		signer, err := RecoverSigner(candidate.hash, export.Signature)
+		if err != nil {
+			return nil, err
+		}
+		if !strings.EqualFold(signer, export.Signer) {
+			return nil, fmt.Errorf("signature recovers to %s, not the claimed signer %s", signer, export.Signer)
+		}
+		export.Signer = signer
+		return &SignatureCheck{Export: export, Target: candidate.target}, nil
+	}
+	return nil, fmt.Errorf("signature is for hash %s, which this transaction does not produce; it was signed for a different transaction", export.SafeTxHash)
+}
+
+// RecoverSigner returns the address that produced a 65-byte Safe owner signature over a Safe tx
+// hash. Both ECDSA signatures (v = 27/28) and eth_sign signatures (v = 31/32) are supported;
+// contract signatures and pre-approved hashes have no key to recover and are rejected.
+func RecoverSigner(safeTxHash, signature string) (string, error) {
+	hash, err := decodeSafeTxHash(safeTxHash)
+	if err != nil {
+		return "", err
+	}
+	sig, err := hex.DecodeString(strings.TrimPrefix(signature, "0x"))
+	if err != nil {
+		return "", fmt.Errorf("invalid signature: %w", err)
+	}
+	if len(sig) != 65 {
+		return "", fmt.Errorf("signature must be 65 bytes, got %d", len(sig))
+	}
+
+	// Safe encodes the signature type in v; go-ethereum expects a recovery ID of 0 or 1
+	digest := hash
+	recovery := sig[64]
+	switch recovery {
+	case 27, 28:
+		recovery -= 27
+	case 31, 32:
+		digest = crypto.Keccak256([]byte("\x19Ethereum Signed Message:\n32"), hash)
+		recovery -= 31
+	case 0:
+		return "", fmt.Errorf("signature is a contract signature (v = 0), which cannot be checked offline")
+	case 1:
+		return "", fmt.Errorf("signature is a pre-approved hash (v = 1), not an owner signature")
+	default:
+		return "", fmt.Errorf("unsupported signature type v = %d", recovery)
+	}
+
+	normalized := make([]byte, 65)
+	copy(normalized, sig)
+	normalized[64] = recovery
+	pub, err := crypto.SigToPub(digest, normalized)
+	if err != nil {
+		return "", fmt.Errorf("failed to recover signer: %w", err)
+	}
+	return crypto.PubkeyToAddress(*pub).Hex(), nil
+}
@@ -0,0 +1,139 @@
+package core
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// signSafeTxHash signs a Safe tx hash with a throwaway key the way a hardware wallet does for
+// Safe, returning the signer and the signature with v = 27/28
+func signSafeTxHash(t *testing.T, safeTxHash string) (string, string) {
+	t.Helper()
+	key, err := crypto.HexToECDSA("4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318")
+	if err != nil {
+		t.Fatalf("invalid key: %v", err)
+	}
+	hash, _ := hex.DecodeString(strings.TrimPrefix(safeTxHash, "0x"))
+	sig, err := crypto.Sign(hash, key)
+	if err != nil {
+		t.Fatalf("failed to sign: %v", err)
+	}
+	sig[64] += 27
+	return crypto.PubkeyToAddress(key.PublicKey).Hex(), "0x" + hex.EncodeToString(sig)
+}
+
+func TestSignatureExportRoundTrip(t *testing.T) {
+	result, err := VerifyTransaction(grantsTransferTx(), VerifyOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	signer, signature := signSafeTxHash(t, result.ApproveHash)
+
+	export, err := NewSignatureExport(result, "", signature)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if export.Signer != signer || export.Nonce != 155 || export.Chain != OPMainnetChainID {
+		t.Fatalf("unexpected export %+v", export)
+	}
+
+	payload, err := json.Marshal(export)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	check, err := CheckSignatureExport(string(payload), result)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if check.Target != "transaction" || check.Export.Signer != signer {
+		t.Errorf("unexpected check %+v", check)
+	}
+}
+
+func TestCheckSignatureExportRejectsTampering(t *testing.T) {
+	result, err := VerifyTransaction(grantsTransferTx(), VerifyOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_, signature := signSafeTxHash(t, result.ApproveHash)
+	export, err := NewSignatureExport(result, "", signature)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// A signature for a different nonce does not match this transaction
+	other := grantsTransferTx()
+	other.Nonce = 156
+	otherResult, err := VerifyTransaction(other, VerifyOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		modify func(e *SignatureExport)
+		result *VerificationResult
+		want   string
+	}{
+		{"different transaction", func(e *SignatureExport) {}, otherResult, "does not produce"},
+		{"claimed signer", func(e *SignatureExport) { e.Signer = grantsRecipient }, result, "not the claimed signer"},
+		{"wrong chain", func(e *SignatureExport) { e.Chain = MainnetChainID }, result, "chain"},
+		{"wrong type", func(e *SignatureExport) { e.Type = "tx" }, result, "not a signature export"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tampered := *export
+			tc.modify(&tampered)
+			payload, _ := json.Marshal(tampered)
+			if _, err := CheckSignatureExport(string(payload), tc.result); err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("error = %v, want it to mention %q", err, tc.want)
+			}
+		})
+	}
+}
+
+func TestRecoverSignerEthSign(t *testing.T) {
+	hash := "0x19767d264966e39d532d998c5354f76ad5102407124b1885d69bf23f791b6f4c"
+	key, _ := crypto.HexToECDSA("4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318")
+	raw, _ := hex.DecodeString(hash[2:])
+	sig, err := crypto.Sign(crypto.Keccak256([]byte("\x19Ethereum Signed Message:\n32"), raw), key)
+	if err != nil {
+		t.Fatalf("failed to sign: %v", err)
+	}
+	sig[64] += 31
+
+	signer, err := RecoverSigner(hash, "0x"+hex.EncodeToString(sig))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if signer != crypto.PubkeyToAddress(key.PublicKey).Hex() {
+		t.Errorf("recovered %s", signer)
+	}
+
+	sig[64] = 1
+	if _, err := RecoverSigner(hash, "0x"+hex.EncodeToString(sig)); err == nil {
+		t.Error("expected pre-approved hash signatures to be rejected")
+	}
+}
+
+func TestSignableHash(t *testing.T) {
+	result, err := VerifyTransaction(grantsTransferTx(), VerifyOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	hash, err := SignableHash(result, "")
+	if err != nil || hash != strings.ToLower(result.ApproveHash) {
+		t.Errorf("SignableHash = %s, %v; want %s", hash, err, result.ApproveHash)
+	}
+
+	// A hash the transaction does not produce is refused before anything is signed
+	other := "0x" + strings.Repeat("ab", 32)
+	if _, err := SignableHash(result, other); err == nil || !strings.Contains(err.Error(), "not a Safe tx hash") {
+		t.Errorf("expected a not a Safe tx hash error, got %v", err)
+	}
+}
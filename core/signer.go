@@ -0,0 +1,134 @@
+package core
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Signer produces Safe owner signatures. Hardware wallets sign on the device; other backends
+// implement this interface.
+type Signer interface {
+	// Address returns the owner address the signer signs for
+	Address() string
+
+	// SignSafeTxHash signs a Safe tx hash and returns the 65-byte signature with v = 27/28
+	SignSafeTxHash(ctx context.Context, safeTxHash string) (string, error)
+}
+
+// DigestSigner is a secp256k1 key held by a remote service, such as a cloud KMS, that never
+// releases the private key. Backends for new services implement this interface and are wrapped
+// in a RemoteSigner.
+type DigestSigner interface {
+	// PublicKey returns the key's DER-encoded SubjectPublicKeyInfo
+	PublicKey(ctx context.Context) ([]byte, error)
+
+	// SignDigest signs a 32-byte digest as is and returns a DER-encoded ECDSA signature
+	SignDigest(ctx context.Context, digest []byte) ([]byte, error)
+}
+
+// RemoteSigner is a Signer backed by a DigestSigner. It converts the DER signatures remote
+// services return into the 65-byte signatures Safe expects.
+type RemoteSigner struct {
+	backend DigestSigner
+	address string
+}
+
+// secp256k1OID identifies the secp256k1 curve in a SubjectPublicKeyInfo
+var secp256k1OID = asn1.ObjectIdentifier{1, 3, 132, 0, 10}
+
+// NewRemoteSigner fetches the backend's public key to learn the owner address it signs for
+func NewRemoteSigner(ctx context.Context, backend DigestSigner) (*RemoteSigner, error) {
+	der, err := backend.PublicKey(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch signer public key: %w", err)
+	}
+	pub, err := parseSecp256k1PublicKey(der)
+	if err != nil {
+		return nil, err
+	}
+	return &RemoteSigner{backend: backend, address: crypto.PubkeyToAddress(*pub).Hex()}, nil
+}
+
+// Address returns the owner address of the remote key
+func (s *RemoteSigner) Address() string {
+	return s.address
+}
+
+// SignSafeTxHash asks the backend to sign the Safe tx hash, normalizes the signature to low s,
+// and finds the recovery ID that recovers to the signer's address
+func (s *RemoteSigner) SignSafeTxHash(ctx context.Context, safeTxHash string) (string, error) {
+	hash, err := decodeSafeTxHash(safeTxHash)
+	if err != nil {
+		return "", err
+	}
+	der, err := s.backend.SignDigest(ctx, hash)
+	if err != nil {
+		return "", fmt.Errorf("remote signer failed: %w", err)
+	}
+
+	var parsed struct{ R, S *big.Int }
+	if rest, err := asn1.Unmarshal(der, &parsed); err != nil || len(rest) != 0 {
+		return "", fmt.Errorf("remote signer returned an invalid DER signature")
+	}
+	// r and s must be in [1, n-1]; anything else, including values wider than 32 bytes, is not a
+	// signature, whatever the service meant by it
+	n := crypto.S256().Params().N
+	if parsed.R.Sign() <= 0 || parsed.R.Cmp(n) >= 0 || parsed.S.Sign() <= 0 || parsed.S.Cmp(n) >= 0 {
+		return "", fmt.Errorf("remote signer returned a signature with r or s out of range")
+	}
+	// Ethereum rejects high-s signatures; services are free to return either form
+	if parsed.S.Cmp(new(big.Int).Rsh(n, 1)) > 0 {
+		parsed.S = new(big.Int).Sub(n, parsed.S)
+	}
+
+	sig := make([]byte, 65)
+	parsed.R.FillBytes(sig[:32])
+	parsed.S.FillBytes(sig[32:64])
+	for recovery := byte(0); recovery < 2; recovery++ {
+		sig[64] = recovery
+		pub, err := crypto.SigToPub(hash, sig)
+		if err == nil && crypto.PubkeyToAddress(*pub).Hex() == s.address {
+			sig[64] += 27
+			return "0x" + hex.EncodeToString(sig), nil
+		}
+	}
+	return "", fmt.Errorf("remote signature does not recover to the signer address %s", s.address)
+}
+
+// parseSecp256k1PublicKey parses a DER SubjectPublicKeyInfo holding a secp256k1 key. The standard
+// library does not support the curve, so the structure is unpacked by hand.
+func parseSecp256k1PublicKey(der []byte) (*ecdsa.PublicKey, error) {
+	var spki struct {
+		Algorithm pkix.AlgorithmIdentifier
+		PublicKey asn1.BitString
+	}
+	if rest, err := asn1.Unmarshal(der, &spki); err != nil || len(rest) != 0 {
+		return nil, fmt.Errorf("invalid signer public key")
+	}
+	var curve asn1.ObjectIdentifier
+	if _, err := asn1.Unmarshal(spki.Algorithm.Parameters.FullBytes, &curve); err != nil || !curve.Equal(secp256k1OID) {
+		return nil, fmt.Errorf("signer key is not a secp256k1 key; Ethereum owners need secp256k1")
+	}
+	pub, err := crypto.UnmarshalPubkey(spki.PublicKey.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid signer public key: %w", err)
+	}
+	return pub, nil
+}
+
+// decodeSafeTxHash decodes a hex Safe tx hash
+func decodeSafeTxHash(safeTxHash string) ([]byte, error) {
+	hash, err := hex.DecodeString(strings.TrimPrefix(safeTxHash, "0x"))
+	if err != nil || len(hash) != 32 {
+		return nil, fmt.Errorf("invalid Safe tx hash %q", safeTxHash)
+	}
+	return hash, nil
+}
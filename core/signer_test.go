@@ -0,0 +1,146 @@
+package core
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// fakeDigestSigner is a DigestSigner holding a local key, standing in for a KMS
+type fakeDigestSigner struct {
+	key *ecdsa.PrivateKey
+
+	// highS makes signatures use the high-s form some services return
+	highS bool
+
+	// response, when set, is returned instead of a signature, as a faulty service might
+	response []byte
+}
+
+func newFakeDigestSigner(t *testing.T) *fakeDigestSigner {
+	key, err := crypto.HexToECDSA("4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return &fakeDigestSigner{key: key}
+}
+
+func (f *fakeDigestSigner) PublicKey(context.Context) ([]byte, error) {
+	return publicKeyDER(&f.key.PublicKey, secp256k1OID), nil
+}
+
+func (f *fakeDigestSigner) SignDigest(_ context.Context, digest []byte) ([]byte, error) {
+	if f.response != nil {
+		return f.response, nil
+	}
+	sig, err := crypto.Sign(digest, f.key)
+	if err != nil {
+		return nil, err
+	}
+	r, s := new(big.Int).SetBytes(sig[:32]), new(big.Int).SetBytes(sig[32:64])
+	if f.highS {
+		s.Sub(crypto.S256().Params().N, s)
+	}
+	return asn1.Marshal(struct{ R, S *big.Int }{r, s})
+}
+
+// publicKeyDER encodes a public key as a SubjectPublicKeyInfo, as KMS services return it
+func publicKeyDER(pub *ecdsa.PublicKey, curveOID asn1.ObjectIdentifier) []byte {
+	curve, _ := asn1.Marshal(curveOID)
+	point := crypto.FromECDSAPub(pub)
+	der, _ := asn1.Marshal(struct {
+		Algorithm pkix.AlgorithmIdentifier
+		PublicKey asn1.BitString
+	}{
+		Algorithm: pkix.AlgorithmIdentifier{
+			Algorithm:  asn1.ObjectIdentifier{1, 2, 840, 10045, 2, 1},
+			Parameters: asn1.RawValue{FullBytes: curve},
+		},
+		PublicKey: asn1.BitString{Bytes: point, BitLength: len(point) * 8},
+	})
+	return der
+}
+
+func TestRemoteSignerSignsSafeTxHash(t *testing.T) {
+	hash := "0x19767d264966e39d532d998c5354f76ad5102407124b1885d69bf23f791b6f4c"
+	for _, highS := range []bool{false, true} {
+		backend := newFakeDigestSigner(t)
+		backend.highS = highS
+		signer, err := NewRemoteSigner(context.Background(), backend)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want := crypto.PubkeyToAddress(backend.key.PublicKey).Hex()
+		if signer.Address() != want {
+			t.Errorf("address = %s, want %s", signer.Address(), want)
+		}
+
+		signature, err := signer.SignSafeTxHash(context.Background(), hash)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		recovered, err := RecoverSigner(hash, signature)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if recovered != want {
+			t.Errorf("highS=%v: signature recovers to %s, want %s", highS, recovered, want)
+		}
+		s := new(big.Int).SetBytes(common.FromHex(signature)[32:64])
+		if s.Cmp(new(big.Int).Rsh(crypto.S256().Params().N, 1)) > 0 {
+			t.Errorf("highS=%v: signature was not normalized to low s", highS)
+		}
+	}
+}
+
+func TestRemoteSignerRejectsMalformedSignatures(t *testing.T) {
+	n := crypto.S256().Params().N
+	one := big.NewInt(1)
+	der := func(r, s *big.Int) []byte {
+		encoded, err := asn1.Marshal(struct{ R, S *big.Int }{r, s})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return encoded
+	}
+	for name, response := range map[string][]byte{
+		"r wider than 32 bytes": der(new(big.Int).Lsh(one, 264), one),
+		"s wider than 32 bytes": der(one, new(big.Int).Lsh(one, 300)),
+		"zero r":                der(big.NewInt(0), one),
+		"zero s":                der(one, big.NewInt(0)),
+		"negative r":            der(big.NewInt(-5), one),
+		"negative s":            der(one, big.NewInt(-5)),
+		"r equal to n":          der(n, one),
+		"s equal to n":          der(one, n),
+		"trailing bytes":        append(der(one, one), 0x00),
+		"not DER":               {0x30, 0xff},
+	} {
+		t.Run(name, func(t *testing.T) {
+			backend := newFakeDigestSigner(t)
+			backend.response = response
+			signer, err := NewRemoteSigner(context.Background(), backend)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if signature, err := signer.SignSafeTxHash(context.Background(), "0x19767d264966e39d532d998c5354f76ad5102407124b1885d69bf23f791b6f4c"); err == nil {
+				t.Errorf("expected an error, got signature %s", signature)
+			}
+		})
+	}
+}
+
+func TestParseSecp256k1PublicKeyRejectsOtherCurves(t *testing.T) {
+	p256 := asn1.ObjectIdentifier{1, 2, 840, 10045, 3, 1, 7}
+	der := publicKeyDER(&newFakeDigestSigner(t).key.PublicKey, p256)
+
+	if _, err := parseSecp256k1PublicKey(der); err == nil || !strings.Contains(err.Error(), "not a secp256k1 key") {
+		t.Errorf("expected a curve error, got %v", err)
+	}
+}
@@ -0,0 +1,221 @@
+package core
+
+import (
+	"math/big"
+	"reflect"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+const (
+	// similarAffixLength is the number of leading and trailing hex digits (4 bytes each) that
+	// address-poisoning attacks typically grind to match, since wallets often show only those
+	similarAffixLength = 8
+
+	// similarEditDistance is the largest edit distance between two different addresses that is
+	// treated as a lookalike; unrelated addresses differ in roughly 37 of 40 digits
+	similarEditDistance = 4
+
+	// minSignificantDigits excludes system addresses (precompiles, OP predeploys such as
+	// 0x4200...0042) that are mostly zeros and legitimately resemble each other
+	minSignificantDigits = 8
+)
+
+// checkAddressSimilarity warns about lookalike addresses: two different addresses in the same
+// transaction that share their leading and trailing 4 bytes, or an unknown address that is a
+// few characters away from a known contract on the same chain
+func checkAddressSimilarity(result *VerificationResult) []Warning {
+	chainID := uint64(result.Transaction.Chain)
+	addresses := collectResultAddresses(result)
+
+	var warnings []Warning
+	reported := map[[2]string]bool{}
+	for i, a := range addresses {
+		for _, b := range addresses[i+1:] {
+			if sharesAffixes(a, b) && significant(a) && significant(b) {
+				reported[[2]string{a, b}], reported[[2]string{b, a}] = true, true
+				warnings = append(warnings, newWarning(warnSimilarAddresses,
+					"Addresses %s and %s share the same first and last 4 bytes. This is a common address-poisoning pattern; check both addresses in full.",
+					ChecksumAddress(a), ChecksumAddress(b)))
+			}
+		}
+	}
+
+	known := KnownAddresses.EntriesOn(chainID)
+	for _, address := range addresses {
+		if _, isKnown := GetKnownContract(address, chainID); isKnown || !significant(address) {
+			continue
+		}
+		for _, entry := range known {
+			knownAddress := strings.ToLower(entry.Address)
+			if !significant(knownAddress) || reported[[2]string{address, knownAddress}] {
+				continue
+			}
+			if sharesAffixes(address, knownAddress) || editDistance(address, knownAddress) <= similarEditDistance {
+				warnings = append(warnings, newWarning(warnLookalikeContract,
+					"Address %s looks like known contract %s (%s) but is a different address. Make sure this is the intended address.",
+					ChecksumAddress(address), entry.Address, entry.Name))
+			}
+		}
+	}
+
+	return warnings
+}
+
+// collectResultAddresses returns every distinct address in a result (lowercased, in order of
+// first appearance): the Safe, targets, and every address in the decoded arguments, including
+// ABI-encoded addresses inside bytes arguments
+func collectResultAddresses(result *VerificationResult) []string {
+	seen := map[string]bool{}
+	var addresses []string
+	add := func(address string) {
+		address = strings.ToLower(StripChainPrefix(address))
+		if !common.IsHexAddress(address) || len(address) != 42 || seen[address] {
+			return
+		}
+		seen[address] = true
+		addresses = append(addresses, address)
+	}
+
+	var walk func(call CallData)
+	walk = func(call CallData) {
+		add(call.Target)
+		for _, arg := range call.ParsedData {
+			collectValueAddresses(reflect.ValueOf(arg.Value), 0, add)
+		}
+		if call.Deposit != nil {
+			add(call.Deposit.To)
+			if call.Deposit.Call != nil {
+				walk(*call.Deposit.Call)
+			}
+		}
+		for _, sub := range call.SubCalls {
+			walk(sub)
+		}
+	}
+
+	for r := result; r != nil; r = r.NestedResult {
+		add(r.Transaction.Safe)
+		add(r.Transaction.To)
+		walk(r.Call)
+	}
+	return addresses
+}
+
+// collectValueAddresses calls add with every address in a decoded argument value: address
+// values, strings that are addresses, and the addresses ABI-encoded in bytes values
+func collectValueAddresses(value reflect.Value, depth int, add func(string)) {
+	for value.IsValid() && (value.Kind() == reflect.Interface || value.Kind() == reflect.Pointer) {
+		if value.Kind() == reflect.Pointer && value.Type() == reflect.TypeOf((*big.Int)(nil)) {
+			return
+		}
+		value = value.Elem()
+	}
+	if !value.IsValid() || depth > 8 {
+		return
+	}
+
+	switch v := value.Interface().(type) {
+	case common.Address:
+		add(v.Hex())
+		return
+	case []byte:
+		encodedAddresses(v, add)
+		return
+	case string:
+		if common.IsHexAddress(v) && len(v) == 42 {
+			add(v)
+		} else if raw, err := hexutil.Decode(v); err == nil {
+			encodedAddresses(raw, add)
+		}
+		return
+	}
+
+	switch value.Kind() {
+	case reflect.Array:
+		if value.Type().Elem().Kind() == reflect.Uint8 {
+			raw := make([]byte, value.Len())
+			reflect.Copy(reflect.ValueOf(raw), value)
+			encodedAddresses(raw, add)
+			return
+		}
+		fallthrough
+	case reflect.Slice:
+		for i := 0; i < value.Len(); i++ {
+			collectValueAddresses(value.Index(i), depth+1, add)
+		}
+	case reflect.Struct:
+		for i := 0; i < value.NumField(); i++ {
+			if value.Type().Field(i).IsExported() {
+				collectValueAddresses(value.Field(i), depth+1, add)
+			}
+		}
+	case reflect.Map:
+		iter := value.MapRange()
+		for iter.Next() {
+			collectValueAddresses(iter.Value(), depth+1, add)
+		}
+	}
+}
+
+// encodedAddresses calls add with the addresses ABI-encoded in bytes, such as calldata or an
+// abi.encode payload: 32-byte words, aligned to the start or to a 4-byte selector, that hold an
+// address left-padded with zeros. A word whose top 4 address bytes are zero is taken for a
+// number, not an address, so amounts are not mistaken for addresses.
+func encodedAddresses(data []byte, add func(string)) {
+	for _, start := range []int{0, 4} {
+		for i := start; i+32 <= len(data); i += 32 {
+			word := data[i : i+32]
+			if allZero(word[:12]) && !allZero(word[12:16]) {
+				add(common.BytesToAddress(word[12:]).Hex())
+			}
+		}
+	}
+}
+
+// allZero reports whether every byte is zero
+func allZero(b []byte) bool {
+	for _, c := range b {
+		if c != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// sharesAffixes reports whether two different addresses share their leading and trailing digits
+func sharesAffixes(a, b string) bool {
+	a, b = strings.ToLower(strings.TrimPrefix(a, "0x")), strings.ToLower(strings.TrimPrefix(b, "0x"))
+	return a != b &&
+		a[:similarAffixLength] == b[:similarAffixLength] &&
+		a[len(a)-similarAffixLength:] == b[len(b)-similarAffixLength:]
+}
+
+// significant reports whether an address has enough non-zero digits to be worth comparing
+func significant(address string) bool {
+	return len(strings.ReplaceAll(strings.TrimPrefix(address, "0x"), "0", "")) >= minSignificantDigits
+}
+
+// editDistance returns the Levenshtein distance between two case-insensitive strings
+func editDistance(a, b string) int {
+	a, b = strings.ToLower(a), strings.ToLower(b)
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,112 @@
+package core
+
+import (
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+func TestCheckAddressSimilarityPoisonedRecipient(t *testing.T) {
+	// Transfer to an address that shares the first and last 4 bytes of the Safe itself
+	tx := grantsTransferTx()
+	tx.Data = "0xa9059cbb0000000000000000000000002501c477deadbeefdeadbeefdeadbeefa9a8b3f0000000000000000000000000000000000000000000034f086f3b33b684000000"
+
+	result, err := VerifyTransaction(tx, VerifyOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Warnings) != 1 || !strings.Contains(result.Warnings[0].Message, "address-poisoning") {
+		t.Fatalf("expected one address-poisoning warning, got %+v", result.Warnings)
+	}
+}
+
+func TestCheckAddressSimilarityKnownContractLookalike(t *testing.T) {
+	// Target differs from the mainnet USDC address in a single character
+	lookalike := []byte(strings.ToLower(USDCMainnetAddress))
+	lookalike[20] = map[bool]byte{true: '1', false: '0'}[lookalike[20] == '0']
+
+	tx := grantsTransferTx()
+	tx.Chain = MainnetChainID
+	tx.To = string(lookalike)
+
+	result, err := VerifyTransaction(tx, VerifyOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Warnings) == 0 || !strings.Contains(result.Warnings[0].Message, "USDC") {
+		t.Fatalf("expected a lookalike warning naming USDC, got %+v", result.Warnings)
+	}
+}
+
+func TestCheckAddressSimilarityIgnoresPredeploys(t *testing.T) {
+	tx := grantsTransferTx()
+	tx.To = "0x4200000000000000000000000000000000000016"
+
+	result, err := VerifyTransaction(tx, VerifyOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Warnings) != 0 {
+		t.Fatalf("predeploys should not be flagged as lookalikes, got %+v", result.Warnings)
+	}
+}
+
+func TestCollectResultAddressesWalksArgumentValues(t *testing.T) {
+	inner := common.HexToAddress("0x1111111122222222333333334444444455555555")
+	encoded := common.HexToAddress("0x6666666677777777888888889999999900000001")
+	nested := common.HexToAddress("0xaaaaaaaabbbbbbbbccccccccddddddddeeeeeeee")
+
+	// transfer(encoded, 10**30): the amount must not be taken for an address
+	calldata := append(hexutil.MustDecode("0xa9059cbb"), common.LeftPadBytes(encoded.Bytes(), 32)...)
+	calldata = append(calldata, common.LeftPadBytes(new(big.Int).Exp(big.NewInt(10), big.NewInt(30), nil).Bytes(), 32)...)
+
+	result := &VerificationResult{
+		Transaction: SafeTransaction{Safe: fixtureGrantsSafe},
+		Call: CallData{
+			Target: fixtureParentSafe,
+			ParsedData: []Argument{
+				{Name: "data", Type: "bytes", Value: hexutil.Encode(calldata)},
+				{Name: "target", Type: "address", Value: inner},
+				{Name: "order", Type: "tuple", Value: struct {
+					Receivers []common.Address
+					Salt      [32]byte
+				}{Receivers: []common.Address{nested}}},
+			},
+		},
+	}
+
+	addresses := collectResultAddresses(result)
+	want := []string{
+		strings.ToLower(fixtureGrantsSafe),
+		strings.ToLower(fixtureParentSafe),
+		strings.ToLower(encoded.Hex()),
+		strings.ToLower(inner.Hex()),
+		strings.ToLower(nested.Hex()),
+	}
+	if !reflect.DeepEqual(addresses, want) {
+		t.Errorf("addresses = %v, want %v", addresses, want)
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"abc", "abc", 0},
+		{"abc", "abd", 1},
+		{"abc", "ab", 1},
+		{"0xABC", "0xabc", 0},
+		{"kitten", "sitting", 3},
+	}
+	for _, tt := range tests {
+		if got := editDistance(tt.a, tt.b); got != tt.want {
+			t.Errorf("editDistance(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
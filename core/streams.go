@@ -0,0 +1,277 @@
+package core
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// streamABIJSON contains the Superfluid vesting scheduler entry points.
+// createVestingScheduleFromAmountAndDuration with a claim period is already in KnownABIJSON.
+var streamABIJSON = []string{
+	// Superfluid VestingSchedulerV2
+	`[{"inputs":[{"name":"superToken","type":"address"},{"name":"receiver","type":"address"},{"name":"startDate","type":"uint32"},{"name":"cliffDate","type":"uint32"},{"name":"flowRate","type":"int96"},{"name":"cliffAmount","type":"uint256"},{"name":"endDate","type":"uint32"},{"name":"ctx","type":"bytes"}],"name":"createVestingSchedule","type":"function"}]`,
+	`[{"inputs":[{"name":"superToken","type":"address"},{"name":"receiver","type":"address"},{"name":"startDate","type":"uint32"},{"name":"cliffDate","type":"uint32"},{"name":"flowRate","type":"int96"},{"name":"cliffAmount","type":"uint256"},{"name":"endDate","type":"uint32"},{"name":"claimValidityDate","type":"uint32"}],"name":"createVestingSchedule","type":"function"}]`,
+	`[{"inputs":[{"name":"superToken","type":"address"},{"name":"receiver","type":"address"},{"name":"startDate","type":"uint32"},{"name":"cliffDate","type":"uint32"},{"name":"flowRate","type":"int96"},{"name":"cliffAmount","type":"uint256"},{"name":"endDate","type":"uint32"},{"name":"claimValidityDate","type":"uint32"},{"name":"ctx","type":"bytes"}],"name":"createVestingSchedule","type":"function"}]`,
+	`[{"inputs":[{"name":"superToken","type":"address"},{"name":"receiver","type":"address"},{"name":"totalAmount","type":"uint256"},{"name":"totalDuration","type":"uint32"}],"name":"createVestingScheduleFromAmountAndDuration","type":"function"}]`,
+	`[{"inputs":[{"name":"superToken","type":"address"},{"name":"receiver","type":"address"},{"name":"totalAmount","type":"uint256"},{"name":"totalDuration","type":"uint32"},{"name":"startDate","type":"uint32"},{"name":"cliffPeriod","type":"uint32"},{"name":"claimPeriod","type":"uint32"},{"name":"ctx","type":"bytes"}],"name":"createVestingScheduleFromAmountAndDuration","type":"function"}]`,
+	`[{"inputs":[{"name":"superToken","type":"address"},{"name":"receiver","type":"address"},{"name":"totalAmount","type":"uint256"},{"name":"totalDuration","type":"uint32"}],"name":"createAndExecuteVestingScheduleFromAmountAndDuration","type":"function"}]`,
+	`[{"inputs":[{"name":"superToken","type":"address"},{"name":"receiver","type":"address"},{"name":"endDate","type":"uint32"},{"name":"ctx","type":"bytes"}],"name":"updateVestingSchedule","type":"function"}]`,
+	`[{"inputs":[{"name":"superToken","type":"address"},{"name":"receiver","type":"address"},{"name":"ctx","type":"bytes"}],"name":"deleteVestingSchedule","type":"function"}]`,
+	`[{"inputs":[{"name":"superToken","type":"address"},{"name":"sender","type":"address"},{"name":"receiver","type":"address"}],"name":"executeCliffAndFlow","type":"function"}]`,
+	`[{"inputs":[{"name":"superToken","type":"address"},{"name":"sender","type":"address"},{"name":"receiver","type":"address"}],"name":"executeEndVesting","type":"function"}]`,
+}
+
+func init() {
+	registerKnownABIs(streamABIJSON)
+
+	for _, sig := range []string{
+		"createVestingSchedule(address,address,uint32,uint32,int96,uint256,uint32,bytes)",
+		"createVestingSchedule(address,address,uint32,uint32,int96,uint256,uint32,uint32)",
+		"createVestingSchedule(address,address,uint32,uint32,int96,uint256,uint32,uint32,bytes)",
+	} {
+		CallDecoders[sig] = decodeSuperfluidVestingSchedule
+	}
+	for _, sig := range []string{
+		"createVestingScheduleFromAmountAndDuration(address,address,uint256,uint32,uint32,uint32,uint32)",
+		"createVestingScheduleFromAmountAndDuration(address,address,uint256,uint32)",
+		"createVestingScheduleFromAmountAndDuration(address,address,uint256,uint32,uint32,uint32,uint32,bytes)",
+		"createAndExecuteVestingScheduleFromAmountAndDuration(address,address,uint256,uint32)",
+	} {
+		CallDecoders[sig] = decodeSuperfluidVestingFromAmount
+	}
+	CallDecoders["updateVestingSchedule(address,address,uint32,bytes)"] = decodeSuperfluidVestingUpdate
+	CallDecoders["deleteVestingSchedule(address,address,bytes)"] = decodeSuperfluidVestingUpdate
+
+	// cancel(uint256) is also Governor Bravo's, so the Sablier functions are decoded only on the
+	// lockup contracts
+	for _, lockup := range []string{SablierLockupLinear, SablierLockupLinearOP, SablierLockupLinearBase} {
+		registerContractFunction(lockup, sablierCreateWithDurationsABIJSON, decodeSablierCreateWithDurations)
+		registerContractFunction(lockup, sablierCancelABIJSON, decodeSablierCancel)
+	}
+}
+
+// Sablier V2.1 LockupLinear entry points
+const (
+	sablierCreateWithDurationsABIJSON = `[{"inputs":[{"components":[{"name":"sender","type":"address"},{"name":"recipient","type":"address"},{"name":"totalAmount","type":"uint128"},{"name":"asset","type":"address"},{"name":"cancelable","type":"bool"},{"name":"transferable","type":"bool"},{"components":[{"name":"cliff","type":"uint40"},{"name":"total","type":"uint40"}],"name":"durations","type":"tuple"},{"components":[{"name":"account","type":"address"},{"name":"fee","type":"uint256"}],"name":"broker","type":"tuple"}],"name":"params","type":"tuple"}],"name":"createWithDurations","type":"function"}]`
+	sablierCancelABIJSON              = `[{"inputs":[{"name":"streamId","type":"uint256"}],"name":"cancel","type":"function"}]`
+)
+
+// formatDuration renders a number of seconds along with a days/hours/minutes breakdown
+func formatDuration(seconds uint64) string {
+	if seconds == 0 {
+		return "0 seconds (none)"
+	}
+	days, rest := seconds/86400, seconds%86400
+	parts := ""
+	if days > 0 {
+		parts = fmt.Sprintf("%dd", days)
+	}
+	if hours := rest / 3600; hours > 0 {
+		parts += fmt.Sprintf(" %dh", hours)
+	}
+	if minutes := rest % 3600 / 60; minutes > 0 {
+		parts += fmt.Sprintf(" %dm", minutes)
+	}
+	if secs := rest % 60; secs > 0 || parts == "" {
+		parts += fmt.Sprintf(" %ds", secs)
+	}
+	return fmt.Sprintf("%d seconds (%s)", seconds, strings.TrimSpace(parts))
+}
+
+// formatStreamDate renders a stream timestamp, where zero means "not set" for the given field
+func formatStreamDate(timestamp uint32, unset string) string {
+	if timestamp == 0 {
+		return "0 (" + unset + ")"
+	}
+	return formatDeadline(new(big.Int).SetUint64(uint64(timestamp)))
+}
+
+// formatFlowRate renders a per-second flow rate together with the amount streamed per 30 days
+func formatFlowRate(flowRate *big.Int, token common.Address, chainID uint64) string {
+	perMonth := new(big.Int).Mul(flowRate, big.NewInt(30*86400))
+	return fmt.Sprintf("%s/second (%s per 30 days)", flowRate, formatTokenAmount(perMonth, token, chainID))
+}
+
+// decodeSuperfluidVestingSchedule summarizes createVestingSchedule, including the total it vests
+func decodeSuperfluidVestingSchedule(args map[string]interface{}, chainID uint64) (map[string]interface{}, error) {
+	superToken, err := argValue[common.Address](args, "superToken")
+	if err != nil {
+		return nil, err
+	}
+	receiver, err := argValue[common.Address](args, "receiver")
+	if err != nil {
+		return nil, err
+	}
+	startDate, err := argValue[uint32](args, "startDate")
+	if err != nil {
+		return nil, err
+	}
+	cliffDate, err := argValue[uint32](args, "cliffDate")
+	if err != nil {
+		return nil, err
+	}
+	endDate, err := argValue[uint32](args, "endDate")
+	if err != nil {
+		return nil, err
+	}
+	flowRate, err := argValue[*big.Int](args, "flowRate")
+	if err != nil {
+		return nil, err
+	}
+	cliffAmount, err := argValue[*big.Int](args, "cliffAmount")
+	if err != nil {
+		return nil, err
+	}
+
+	// The flow starts at the cliff (or the start date when there is no cliff) and runs until the end date
+	flowStart := startDate
+	if cliffDate != 0 {
+		flowStart = cliffDate
+	}
+	summary := map[string]interface{}{
+		"superToken":  describeAddress(superToken, chainID),
+		"receiver":    describeAddress(receiver, chainID),
+		"startDate":   formatStreamDate(startDate, "unset"),
+		"cliffDate":   formatStreamDate(cliffDate, "no cliff"),
+		"endDate":     formatStreamDate(endDate, "unset"),
+		"flowRate":    formatFlowRate(flowRate, superToken, chainID),
+		"cliffAmount": formatTokenAmount(cliffAmount, superToken, chainID),
+	}
+	if endDate > flowStart {
+		summary["flowDuration"] = formatDuration(uint64(endDate - flowStart))
+		total := new(big.Int).Mul(flowRate, big.NewInt(int64(endDate-flowStart)))
+		summary["totalAmount"] = formatTokenAmount(total.Add(total, cliffAmount), superToken, chainID) + " (cliff + flow until end date)"
+	}
+	if claimValidity, ok := args["claimValidityDate"].(uint32); ok {
+		summary["claimValidityDate"] = formatStreamDate(claimValidity, "no claim required")
+	}
+	return summary, nil
+}
+
+// decodeSuperfluidVestingFromAmount summarizes the amount-and-duration vesting schedule helpers
+func decodeSuperfluidVestingFromAmount(args map[string]interface{}, chainID uint64) (map[string]interface{}, error) {
+	superToken, err := argValue[common.Address](args, "superToken")
+	if err != nil {
+		return nil, err
+	}
+	receiver, err := argValue[common.Address](args, "receiver")
+	if err != nil {
+		return nil, err
+	}
+	totalAmount, err := argValue[*big.Int](args, "totalAmount")
+	if err != nil {
+		return nil, err
+	}
+	totalDuration, err := argValue[uint32](args, "totalDuration")
+	if err != nil {
+		return nil, err
+	}
+
+	summary := map[string]interface{}{
+		"superToken":    describeAddress(superToken, chainID),
+		"receiver":      describeAddress(receiver, chainID),
+		"totalAmount":   formatTokenAmount(totalAmount, superToken, chainID),
+		"totalDuration": formatDuration(uint64(totalDuration)),
+		"startDate":     "0 (starts when executed)",
+		"cliffPeriod":   formatDuration(0),
+	}
+	if startDate, ok := args["startDate"].(uint32); ok {
+		summary["startDate"] = formatStreamDate(startDate, "starts when executed")
+	}
+	if cliffPeriod, ok := args["cliffPeriod"].(uint32); ok {
+		summary["cliffPeriod"] = formatDuration(uint64(cliffPeriod))
+	}
+	if claimPeriod, ok := args["claimPeriod"].(uint32); ok {
+		summary["claimPeriod"] = formatDuration(uint64(claimPeriod))
+		if claimPeriod == 0 {
+			summary["claimPeriod"] = "0 (no claim required)"
+		}
+	}
+	return summary, nil
+}
+
+// decodeSuperfluidVestingUpdate summarizes updating or deleting a vesting schedule
+func decodeSuperfluidVestingUpdate(args map[string]interface{}, chainID uint64) (map[string]interface{}, error) {
+	superToken, err := argValue[common.Address](args, "superToken")
+	if err != nil {
+		return nil, err
+	}
+	receiver, err := argValue[common.Address](args, "receiver")
+	if err != nil {
+		return nil, err
+	}
+	summary := map[string]interface{}{
+		"superToken": describeAddress(superToken, chainID),
+		"receiver":   describeAddress(receiver, chainID),
+	}
+	if endDate, ok := args["endDate"].(uint32); ok {
+		summary["endDate"] = formatStreamDate(endDate, "unset")
+	} else {
+		summary["effect"] = "deletes the vesting schedule; nothing further vests to the receiver"
+	}
+	return summary, nil
+}
+
+// decodeSablierCreateWithDurations summarizes a Sablier V2 linear lockup stream
+func decodeSablierCreateWithDurations(args map[string]interface{}, chainID uint64) (map[string]interface{}, error) {
+	params := args["params"]
+	var sender, recipient, asset common.Address
+	var totalAmount *big.Int
+	var cancelable, transferable bool
+	if err := tupleFields(params, map[string]interface{}{
+		"sender": &sender, "recipient": &recipient, "asset": &asset, "totalAmount": &totalAmount,
+		"cancelable": &cancelable, "transferable": &transferable,
+	}); err != nil {
+		return nil, err
+	}
+	durations, err := structField(params, "durations")
+	if err != nil {
+		return nil, err
+	}
+	var cliff, total *big.Int
+	if err := tupleFields(durations, map[string]interface{}{"cliff": &cliff, "total": &total}); err != nil {
+		return nil, err
+	}
+	broker, err := structField(params, "broker")
+	if err != nil {
+		return nil, err
+	}
+	var brokerAccount common.Address
+	var brokerFee *big.Int
+	if err := tupleFields(broker, map[string]interface{}{"account": &brokerAccount, "fee": &brokerFee}); err != nil {
+		return nil, err
+	}
+
+	summary := map[string]interface{}{
+		"sender":       describeAddress(sender, chainID),
+		"recipient":    describeAddress(recipient, chainID),
+		"asset":        describeAddress(asset, chainID),
+		"totalAmount":  formatTokenAmount(totalAmount, asset, chainID),
+		"cancelable":   cancelable,
+		"transferable": transferable,
+		"cliff":        formatDuration(cliff.Uint64()),
+		"duration":     formatDuration(total.Uint64()),
+		"start":        "when executed",
+	}
+	if brokerFee.Sign() != 0 || brokerAccount != (common.Address{}) {
+		// The broker fee is a UD60x18 fraction of the total amount (1e18 = 100%)
+		percent := new(big.Rat).SetFrac(brokerFee, big.NewInt(1e16))
+		summary["brokerFee"] = fmt.Sprintf("%s%% to %s", percent.FloatString(2), describeAddress(brokerAccount, chainID))
+	}
+	return summary, nil
+}
+
+// decodeSablierCancel summarizes cancelling a Sablier V2 stream
+func decodeSablierCancel(args map[string]interface{}, chainID uint64) (map[string]interface{}, error) {
+	streamID, err := argValue[*big.Int](args, "streamId")
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"streamId": streamID,
+		"effect":   "cancels the stream: streamed tokens stay withdrawable by the recipient, the rest is refunded to the sender (Sablier V2)",
+	}, nil
+}
@@ -0,0 +1,145 @@
+package core
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// tokens returns n whole units of an 18 decimal token
+func tokens(n int64) *big.Int {
+	return new(big.Int).Mul(big.NewInt(n), big.NewInt(1e18))
+}
+
+func TestDecodeSuperfluidVestingSchedule(t *testing.T) {
+	start := uint32(swapDeadline.Int64())
+	cliff := start + 30*86400
+	end := cliff + 335*86400
+	data := encodeKnownCall(t, "createVestingSchedule(address,address,uint32,uint32,int96,uint256,uint32,uint32)",
+		common.HexToAddress(SuperfluidOP), common.HexToAddress(OPGrants2), start, cliff, big.NewInt(1e15), tokens(1000), end, uint32(0))
+
+	summary := parseKnownCall(t, SuperfluidOP, OPMainnetChainID, data)
+	expectFields(t, summary, map[string]string{
+		"superToken":        "(SUPERFLUID OP 🔍)",
+		"receiver":          "(OP GRANTS 2 (1BE) 🔍)",
+		"startDate":         "2023-11-14 22:13:20 UTC",
+		"cliffDate":         "2023-12-14 22:13:20 UTC",
+		"flowRate":          "1000000000000000/second (2,592.00 SUPERFLUID OP",
+		"cliffAmount":       "1,000.00 SUPERFLUID OP",
+		"flowDuration":      "(335d)",
+		"totalAmount":       "29,944.00 SUPERFLUID OP",
+		"claimValidityDate": "no claim required",
+	})
+}
+
+func TestDecodeSuperfluidVestingFromAmountAndDuration(t *testing.T) {
+	data := encodeKnownCall(t, "createVestingScheduleFromAmountAndDuration(address,address,uint256,uint32,uint32,uint32,uint32)",
+		common.HexToAddress(SuperfluidOP), common.HexToAddress(OPGrants2), tokens(50000), uint32(365*86400), uint32(0), uint32(90*86400), uint32(0))
+
+	summary := parseKnownCall(t, SuperfluidOP, OPMainnetChainID, data)
+	expectFields(t, summary, map[string]string{
+		"totalAmount":   "50,000.00 SUPERFLUID OP",
+		"totalDuration": "31536000 seconds (365d)",
+		"startDate":     "starts when executed",
+		"cliffPeriod":   "(90d)",
+		"claimPeriod":   "no claim required",
+	})
+}
+
+func TestDecodeSuperfluidDeleteVestingSchedule(t *testing.T) {
+	data := encodeKnownCall(t, "deleteVestingSchedule(address,address,bytes)", common.HexToAddress(SuperfluidOP), common.HexToAddress(OPGrants2), []byte{})
+
+	summary := parseKnownCall(t, SuperfluidOP, OPMainnetChainID, data)
+	expectFields(t, summary, map[string]string{"effect": "deletes the vesting schedule"})
+}
+
+func TestDecodeSablierCreateWithDurations(t *testing.T) {
+	type durations struct {
+		Cliff *big.Int
+		Total *big.Int
+	}
+	type broker struct {
+		Account common.Address
+		Fee     *big.Int
+	}
+	params := struct {
+		Sender       common.Address
+		Recipient    common.Address
+		TotalAmount  *big.Int
+		Asset        common.Address
+		Cancelable   bool
+		Transferable bool
+		Durations    durations
+		Broker       broker
+	}{
+		Sender:      common.HexToAddress(ProxyAdminOwner),
+		Recipient:   common.HexToAddress(OPGrants1),
+		TotalAmount: big.NewInt(120_000_000_000),
+		Asset:       common.HexToAddress(USDCMainnetAddress),
+		Cancelable:  true,
+		Durations:   durations{Cliff: big.NewInt(90 * 86400), Total: big.NewInt(365 * 86400)},
+		Broker:      broker{Fee: big.NewInt(0)},
+	}
+	data := encodeKnownCall(t, "createWithDurations((address,address,uint128,address,bool,bool,(uint40,uint40),(address,uint256)))", params)
+
+	summary := parseKnownCall(t, SablierLockupLinear, MainnetChainID, data)
+	expectFields(t, summary, map[string]string{
+		"sender":      "(SUPERCHAIN PROXY ADMIN OWNER 🔍)",
+		"recipient":   OPGrants1,
+		"totalAmount": "120,000.00 USDC",
+		"cliff":       "7776000 seconds (90d)",
+		"duration":    "31536000 seconds (365d)",
+	})
+	if summary["cancelable"] != true || summary["transferable"] != false {
+		t.Errorf("unexpected flags in %+v", summary)
+	}
+	if _, ok := summary["brokerFee"]; ok {
+		t.Error("expected no broker fee")
+	}
+}
+
+func TestDecodeSablierCancel(t *testing.T) {
+	data := encodeKnownCall(t, "cancel(uint256)", big.NewInt(42))
+
+	summary := parseKnownCall(t, SablierLockupLinearOP, OPMainnetChainID, data)
+	if summary["streamId"].(*big.Int).Int64() != 42 {
+		t.Errorf("unexpected stream id %v", summary["streamId"])
+	}
+	expectFields(t, summary, map[string]string{"effect": "refunded to the sender"})
+}
+
+func TestDecodeCancelDependsOnTarget(t *testing.T) {
+	data := encodeKnownCall(t, "cancel(uint256)", big.NewInt(42))
+
+	// On Governor Bravo the same selector cancels a proposal
+	call, err := ParseTransactionData(CompoundGovernorBravo, data, MainnetChainID, VerifyOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := call.Argument("proposalId"); !ok || call.Summary != nil {
+		t.Errorf("expected a proposal id and no stream summary, got %+v and %+v", call.ParsedData, call.Summary)
+	}
+
+	// On any other contract it is not decoded at all
+	call, err = ParseTransactionData(OPGrants1, data, MainnetChainID, VerifyOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if call.FunctionName != "unknown" || call.RawData == "" {
+		t.Errorf("expected cancel on an unknown target to stay undecoded, got %s %+v", call.FunctionName, call.ParsedData)
+	}
+}
+
+func TestFormatDuration(t *testing.T) {
+	for seconds, want := range map[uint64]string{
+		0:     "0 seconds (none)",
+		59:    "59 seconds (59s)",
+		90061: "90061 seconds (1d 1h 1m 1s)",
+		86400: "86400 seconds (1d)",
+	} {
+		if got := formatDuration(seconds); got != want {
+			t.Errorf("formatDuration(%d) = %q, want %q", seconds, got, want)
+		}
+	}
+}
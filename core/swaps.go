@@ -0,0 +1,472 @@
+package core
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// swapABIJSON contains the ABIs of the common DEX router entry points
+var swapABIJSON = []string{
+	// Uniswap V3 SwapRouter (with deadline)
+	`[{"inputs":[{"components":[{"name":"tokenIn","type":"address"},{"name":"tokenOut","type":"address"},{"name":"fee","type":"uint24"},{"name":"recipient","type":"address"},{"name":"deadline","type":"uint256"},{"name":"amountIn","type":"uint256"},{"name":"amountOutMinimum","type":"uint256"},{"name":"sqrtPriceLimitX96","type":"uint160"}],"name":"params","type":"tuple"}],"name":"exactInputSingle","type":"function"}]`,
+	`[{"inputs":[{"components":[{"name":"path","type":"bytes"},{"name":"recipient","type":"address"},{"name":"deadline","type":"uint256"},{"name":"amountIn","type":"uint256"},{"name":"amountOutMinimum","type":"uint256"}],"name":"params","type":"tuple"}],"name":"exactInput","type":"function"}]`,
+	`[{"inputs":[{"components":[{"name":"tokenIn","type":"address"},{"name":"tokenOut","type":"address"},{"name":"fee","type":"uint24"},{"name":"recipient","type":"address"},{"name":"deadline","type":"uint256"},{"name":"amountOut","type":"uint256"},{"name":"amountInMaximum","type":"uint256"},{"name":"sqrtPriceLimitX96","type":"uint160"}],"name":"params","type":"tuple"}],"name":"exactOutputSingle","type":"function"}]`,
+	`[{"inputs":[{"components":[{"name":"path","type":"bytes"},{"name":"recipient","type":"address"},{"name":"deadline","type":"uint256"},{"name":"amountOut","type":"uint256"},{"name":"amountInMaximum","type":"uint256"}],"name":"params","type":"tuple"}],"name":"exactOutput","type":"function"}]`,
+	// Uniswap SwapRouter02 (deadline enforced by the surrounding multicall)
+	`[{"inputs":[{"components":[{"name":"tokenIn","type":"address"},{"name":"tokenOut","type":"address"},{"name":"fee","type":"uint24"},{"name":"recipient","type":"address"},{"name":"amountIn","type":"uint256"},{"name":"amountOutMinimum","type":"uint256"},{"name":"sqrtPriceLimitX96","type":"uint160"}],"name":"params","type":"tuple"}],"name":"exactInputSingle","type":"function"}]`,
+	`[{"inputs":[{"components":[{"name":"path","type":"bytes"},{"name":"recipient","type":"address"},{"name":"amountIn","type":"uint256"},{"name":"amountOutMinimum","type":"uint256"}],"name":"params","type":"tuple"}],"name":"exactInput","type":"function"}]`,
+	`[{"inputs":[{"components":[{"name":"tokenIn","type":"address"},{"name":"tokenOut","type":"address"},{"name":"fee","type":"uint24"},{"name":"recipient","type":"address"},{"name":"amountOut","type":"uint256"},{"name":"amountInMaximum","type":"uint256"},{"name":"sqrtPriceLimitX96","type":"uint160"}],"name":"params","type":"tuple"}],"name":"exactOutputSingle","type":"function"}]`,
+	`[{"inputs":[{"components":[{"name":"path","type":"bytes"},{"name":"recipient","type":"address"},{"name":"amountOut","type":"uint256"},{"name":"amountInMaximum","type":"uint256"}],"name":"params","type":"tuple"}],"name":"exactOutput","type":"function"}]`,
+	// Uniswap Universal Router
+	`[{"inputs":[{"name":"commands","type":"bytes"},{"name":"inputs","type":"bytes[]"},{"name":"deadline","type":"uint256"}],"name":"execute","type":"function"}]`,
+	`[{"inputs":[{"name":"commands","type":"bytes"},{"name":"inputs","type":"bytes[]"}],"name":"execute","type":"function"}]`,
+	// 1inch Aggregation Router V5 and V6
+	`[{"inputs":[{"name":"executor","type":"address"},{"components":[{"name":"srcToken","type":"address"},{"name":"dstToken","type":"address"},{"name":"srcReceiver","type":"address"},{"name":"dstReceiver","type":"address"},{"name":"amount","type":"uint256"},{"name":"minReturnAmount","type":"uint256"},{"name":"flags","type":"uint256"}],"name":"desc","type":"tuple"},{"name":"permit","type":"bytes"},{"name":"data","type":"bytes"}],"name":"swap","type":"function"}]`,
+	`[{"inputs":[{"name":"executor","type":"address"},{"components":[{"name":"srcToken","type":"address"},{"name":"dstToken","type":"address"},{"name":"srcReceiver","type":"address"},{"name":"dstReceiver","type":"address"},{"name":"amount","type":"uint256"},{"name":"minReturnAmount","type":"uint256"},{"name":"flags","type":"uint256"}],"name":"desc","type":"tuple"},{"name":"data","type":"bytes"}],"name":"swap","type":"function"}]`,
+	// CowSwap GPv2Settlement
+	`[{"inputs":[{"name":"tokens","type":"address[]"},{"name":"clearingPrices","type":"uint256[]"},{"components":[{"name":"sellTokenIndex","type":"uint256"},{"name":"buyTokenIndex","type":"uint256"},{"name":"receiver","type":"address"},{"name":"sellAmount","type":"uint256"},{"name":"buyAmount","type":"uint256"},{"name":"validTo","type":"uint32"},{"name":"appData","type":"bytes32"},{"name":"feeAmount","type":"uint256"},{"name":"flags","type":"uint256"},{"name":"executedAmount","type":"uint256"},{"name":"signature","type":"bytes"}],"name":"trades","type":"tuple[]"},{"components":[{"name":"target","type":"address"},{"name":"value","type":"uint256"},{"name":"callData","type":"bytes"}],"name":"interactions","type":"tuple[][3]"}],"name":"settle","type":"function"}]`,
+	`[{"inputs":[{"name":"orderUid","type":"bytes"},{"name":"signed","type":"bool"}],"name":"setPreSignature","type":"function"}]`,
+}
+
+func init() {
+	registerKnownABIs(swapABIJSON)
+
+	// The swaps are summarized only on the routers themselves: the same selectors on another
+	// contract may do something else entirely
+	registerContractDecoder(UniswapV3SwapRouter, "exactInputSingle((address,address,uint24,address,uint256,uint256,uint256,uint160))", decodeUniswapV3Single)
+	registerContractDecoder(UniswapV3SwapRouter, "exactOutputSingle((address,address,uint24,address,uint256,uint256,uint256,uint160))", decodeUniswapV3Single)
+	registerContractDecoder(UniswapV3SwapRouter, "exactInput((bytes,address,uint256,uint256,uint256))", decodeUniswapV3Path)
+	registerContractDecoder(UniswapV3SwapRouter, "exactOutput((bytes,address,uint256,uint256,uint256))", decodeUniswapV3Path)
+	for _, router := range []string{UniswapSwapRouter02, UniswapSwapRouter02Base} {
+		registerContractDecoder(router, "exactInputSingle((address,address,uint24,address,uint256,uint256,uint160))", decodeUniswapV3Single)
+		registerContractDecoder(router, "exactOutputSingle((address,address,uint24,address,uint256,uint256,uint160))", decodeUniswapV3Single)
+		registerContractDecoder(router, "exactInput((bytes,address,uint256,uint256))", decodeUniswapV3Path)
+		registerContractDecoder(router, "exactOutput((bytes,address,uint256,uint256))", decodeUniswapV3Path)
+	}
+	for _, router := range []string{UniswapUniversalRouter, UniswapUniversalRouterOP, UniswapUniversalRouterBase} {
+		registerContractDecoder(router, "execute(bytes,bytes[],uint256)", decodeUniversalRouter)
+		registerContractDecoder(router, "execute(bytes,bytes[])", decodeUniversalRouter)
+	}
+	registerContractDecoder(OneInchRouterV5, "swap(address,(address,address,address,address,uint256,uint256,uint256),bytes,bytes)", decodeOneInchSwap)
+	registerContractDecoder(OneInchRouterV6, "swap(address,(address,address,address,address,uint256,uint256,uint256),bytes)", decodeOneInchSwap)
+	registerContractDecoder(CowSwapSettlement, "settle(address[],uint256[],(uint256,uint256,address,uint256,uint256,uint32,bytes32,uint256,uint256,uint256,bytes)[],(address,uint256,bytes)[][3])", decodeCowSwapSettle)
+	registerContractDecoder(CowSwapSettlement, "setPreSignature(bytes,bool)", decodeCowSwapPreSignature)
+}
+
+// describeToken renders a swap token, including the placeholder routers use for the native token
+func describeToken(token common.Address, chainID uint64) string {
+	if token == common.HexToAddress(NativeTokenPlaceholder) {
+		return token.Hex() + " (NATIVE TOKEN)"
+	}
+	return describeAddress(token, chainID)
+}
+
+// decodeUniswapV3Single summarizes exactInputSingle / exactOutputSingle on either V3 router
+func decodeUniswapV3Single(args map[string]interface{}, chainID uint64) (map[string]interface{}, error) {
+	params := args["params"]
+	var tokenIn, tokenOut, recipient common.Address
+	var fee *big.Int
+	if err := tupleFields(params, map[string]interface{}{
+		"tokenIn": &tokenIn, "tokenOut": &tokenOut, "recipient": &recipient, "fee": &fee,
+	}); err != nil {
+		return nil, err
+	}
+
+	summary := map[string]interface{}{
+		"tokenIn":   describeToken(tokenIn, chainID),
+		"tokenOut":  describeToken(tokenOut, chainID),
+		"fee":       formatPoolFee(fee),
+		"recipient": describeAddress(recipient, chainID),
+	}
+	if err := addSwapAmounts(summary, params, tokenIn, tokenOut, chainID); err != nil {
+		return nil, err
+	}
+	return summary, nil
+}
+
+// decodeUniswapV3Path summarizes exactInput / exactOutput on either V3 router
+func decodeUniswapV3Path(args map[string]interface{}, chainID uint64) (map[string]interface{}, error) {
+	params := args["params"]
+	var path []byte
+	var recipient common.Address
+	if err := tupleFields(params, map[string]interface{}{"path": &path, "recipient": &recipient}); err != nil {
+		return nil, err
+	}
+
+	// Exact output paths are encoded from the output token back to the input token
+	_, exactOutput := structFieldOK(params, "amountOut")
+	tokens, fees, err := decodeV3Path(path)
+	if err != nil {
+		return nil, err
+	}
+	if exactOutput {
+		reverseV3Path(tokens, fees)
+	}
+
+	tokenIn, tokenOut := tokens[0], tokens[len(tokens)-1]
+	summary := map[string]interface{}{
+		"tokenIn":   describeToken(tokenIn, chainID),
+		"tokenOut":  describeToken(tokenOut, chainID),
+		"route":     formatV3Route(tokens, fees, chainID),
+		"recipient": describeAddress(recipient, chainID),
+	}
+	if err := addSwapAmounts(summary, params, tokenIn, tokenOut, chainID); err != nil {
+		return nil, err
+	}
+	return summary, nil
+}
+
+// addSwapAmounts adds the exact and limit amounts (and deadline, when present) of a V3 swap
+func addSwapAmounts(summary map[string]interface{}, params interface{}, tokenIn, tokenOut common.Address, chainID uint64) error {
+	if amountIn, ok := structFieldOK(params, "amountIn"); ok {
+		var minimum *big.Int
+		if err := tupleFields(params, map[string]interface{}{"amountOutMinimum": &minimum}); err != nil {
+			return err
+		}
+		summary["amountIn"] = formatTokenAmount(amountIn.(*big.Int), tokenIn, chainID)
+		summary["amountOutMinimum"] = formatMinimumAmount(minimum, tokenOut, chainID)
+	} else {
+		var amountOut, maximum *big.Int
+		if err := tupleFields(params, map[string]interface{}{"amountOut": &amountOut, "amountInMaximum": &maximum}); err != nil {
+			return err
+		}
+		summary["amountOut"] = formatTokenAmount(amountOut, tokenOut, chainID)
+		summary["amountInMaximum"] = formatTokenAmount(maximum, tokenIn, chainID)
+	}
+	if deadline, ok := structFieldOK(params, "deadline"); ok {
+		summary["deadline"] = formatDeadline(deadline.(*big.Int))
+	}
+	return nil
+}
+
+// structFieldOK returns a tuple field and whether it exists
+func structFieldOK(value interface{}, name string) (interface{}, bool) {
+	field, err := structField(value, name)
+	return field, err == nil
+}
+
+// decodeV3Path decodes a packed Uniswap V3 path: token (20 bytes) followed by any number of
+// fee (3 bytes) + token (20 bytes) hops
+func decodeV3Path(path []byte) ([]common.Address, []*big.Int, error) {
+	if len(path) < 43 || (len(path)-20)%23 != 0 {
+		return nil, nil, fmt.Errorf("invalid Uniswap V3 path length %d", len(path))
+	}
+	tokens := []common.Address{common.BytesToAddress(path[:20])}
+	var fees []*big.Int
+	for pos := 20; pos < len(path); pos += 23 {
+		fees = append(fees, new(big.Int).SetBytes(path[pos:pos+3]))
+		tokens = append(tokens, common.BytesToAddress(path[pos+3:pos+23]))
+	}
+	return tokens, fees, nil
+}
+
+// reverseV3Path reverses a decoded path in place
+func reverseV3Path(tokens []common.Address, fees []*big.Int) {
+	for i, j := 0, len(tokens)-1; i < j; i, j = i+1, j-1 {
+		tokens[i], tokens[j] = tokens[j], tokens[i]
+	}
+	for i, j := 0, len(fees)-1; i < j; i, j = i+1, j-1 {
+		fees[i], fees[j] = fees[j], fees[i]
+	}
+}
+
+// formatV3Route renders a decoded path as "tokenA → [fee] → tokenB → ..."
+func formatV3Route(tokens []common.Address, fees []*big.Int, chainID uint64) string {
+	parts := []string{describeToken(tokens[0], chainID)}
+	for i, fee := range fees {
+		parts = append(parts, fmt.Sprintf("[%s]", formatPoolFee(fee)), describeToken(tokens[i+1], chainID))
+	}
+	return strings.Join(parts, " → ")
+}
+
+// formatPoolFee renders a Uniswap V3 fee tier (in hundredths of a basis point) as a percentage
+func formatPoolFee(fee *big.Int) string {
+	percent := new(big.Rat).SetFrac(fee, big.NewInt(10000))
+	return strings.TrimRight(strings.TrimRight(percent.FloatString(4), "0"), ".") + "% pool"
+}
+
+// Universal Router commands (the low 6 bits of each command byte)
+const (
+	urV3SwapExactIn  = 0x00
+	urV3SwapExactOut = 0x01
+	urSweep          = 0x04
+	urTransfer       = 0x05
+	urV2SwapExactIn  = 0x08
+	urV2SwapExactOut = 0x09
+	urWrapETH        = 0x0b
+	urUnwrapWETH     = 0x0c
+)
+
+// universalRouterCommandNames names every Universal Router command, decoded or not
+var universalRouterCommandNames = map[byte]string{
+	0x00: "V3_SWAP_EXACT_IN",
+	0x01: "V3_SWAP_EXACT_OUT",
+	0x02: "PERMIT2_TRANSFER_FROM",
+	0x03: "PERMIT2_PERMIT_BATCH",
+	0x04: "SWEEP",
+	0x05: "TRANSFER",
+	0x06: "PAY_PORTION",
+	0x08: "V2_SWAP_EXACT_IN",
+	0x09: "V2_SWAP_EXACT_OUT",
+	0x0a: "PERMIT2_PERMIT",
+	0x0b: "WRAP_ETH",
+	0x0c: "UNWRAP_WETH",
+	0x0d: "PERMIT2_TRANSFER_FROM_BATCH",
+	0x10: "V4_SWAP",
+}
+
+// universalRouterInputs are the ABI encodings of the decoded Universal Router command inputs
+var universalRouterInputs = map[byte]abi.Arguments{}
+
+func init() {
+	mustType := func(t string) abi.Type {
+		typ, err := abi.NewType(t, "", nil)
+		if err != nil {
+			panic(err)
+		}
+		return typ
+	}
+	args := func(types ...string) abi.Arguments {
+		var out abi.Arguments
+		for _, t := range types {
+			out = append(out, abi.Argument{Type: mustType(t)})
+		}
+		return out
+	}
+	universalRouterInputs[urV3SwapExactIn] = args("address", "uint256", "uint256", "bytes", "bool")
+	universalRouterInputs[urV3SwapExactOut] = args("address", "uint256", "uint256", "bytes", "bool")
+	universalRouterInputs[urSweep] = args("address", "address", "uint256")
+	universalRouterInputs[urTransfer] = args("address", "address", "uint256")
+	universalRouterInputs[urV2SwapExactIn] = args("address", "uint256", "uint256", "address[]", "bool")
+	universalRouterInputs[urV2SwapExactOut] = args("address", "uint256", "uint256", "address[]", "bool")
+	universalRouterInputs[urWrapETH] = args("address", "uint256")
+	universalRouterInputs[urUnwrapWETH] = args("address", "uint256")
+}
+
+// describeRouterRecipient renders a Universal Router recipient, resolving its sentinel values
+func describeRouterRecipient(recipient common.Address, chainID uint64) string {
+	switch recipient {
+	case common.BigToAddress(big.NewInt(1)):
+		return recipient.Hex() + " (MSG_SENDER: the Safe)"
+	case common.BigToAddress(big.NewInt(2)):
+		return recipient.Hex() + " (ADDRESS_THIS: the router)"
+	}
+	return describeAddress(recipient, chainID)
+}
+
+// decodeUniversalRouter summarizes each command of a Universal Router execute call
+func decodeUniversalRouter(args map[string]interface{}, chainID uint64) (map[string]interface{}, error) {
+	commandsHex, err := argValue[string](args, "commands")
+	if err != nil {
+		return nil, err
+	}
+	commands, err := hex.DecodeString(strings.TrimPrefix(commandsHex, "0x"))
+	if err != nil {
+		return nil, err
+	}
+	inputs, err := argValue[[][]byte](args, "inputs")
+	if err != nil {
+		return nil, err
+	}
+	if len(commands) != len(inputs) {
+		return nil, fmt.Errorf("universal router has %d commands but %d inputs", len(commands), len(inputs))
+	}
+
+	var steps []map[string]interface{}
+	for i, command := range commands {
+		step, err := decodeUniversalRouterCommand(command, inputs[i], chainID)
+		if err != nil {
+			return nil, fmt.Errorf("command %d: %w", i, err)
+		}
+		steps = append(steps, step)
+	}
+
+	summary := map[string]interface{}{"commands": steps}
+	if deadline, ok := args["deadline"].(*big.Int); ok {
+		summary["deadline"] = formatDeadline(deadline)
+	} else {
+		summary["deadline"] = "none (this execute overload does not expire)"
+	}
+	return summary, nil
+}
+
+// decodeUniversalRouterCommand decodes the input of a single Universal Router command
+func decodeUniversalRouterCommand(command byte, input []byte, chainID uint64) (map[string]interface{}, error) {
+	kind := command & 0x3f
+	name, ok := universalRouterCommandNames[kind]
+	if !ok {
+		name = fmt.Sprintf("UNKNOWN (0x%02x)", kind)
+	}
+	step := map[string]interface{}{"command": name}
+	if command&0x80 != 0 {
+		step["allowRevert"] = true
+	}
+
+	arguments, ok := universalRouterInputs[kind]
+	if !ok {
+		step["input"] = "0x" + hex.EncodeToString(input)
+		return step, nil
+	}
+	values, err := arguments.Unpack(input)
+	if err != nil {
+		return nil, err
+	}
+
+	switch kind {
+	case urV3SwapExactIn, urV3SwapExactOut:
+		tokens, fees, err := decodeV3Path(values[3].([]byte))
+		if err != nil {
+			return nil, err
+		}
+		if kind == urV3SwapExactOut {
+			reverseV3Path(tokens, fees)
+		}
+		addRouterSwap(step, kind == urV3SwapExactIn, values, tokens[0], tokens[len(tokens)-1], chainID)
+		step["route"] = formatV3Route(tokens, fees, chainID)
+	case urV2SwapExactIn, urV2SwapExactOut:
+		path := values[3].([]common.Address)
+		if len(path) < 2 {
+			return nil, errors.New("V2 swap path needs at least two tokens")
+		}
+		addRouterSwap(step, kind == urV2SwapExactIn, values, path[0], path[len(path)-1], chainID)
+		var route []string
+		for _, token := range path {
+			route = append(route, describeToken(token, chainID))
+		}
+		step["route"] = strings.Join(route, " → ")
+	case urSweep, urTransfer:
+		token := values[0].(common.Address)
+		step["token"] = describeToken(token, chainID)
+		step["recipient"] = describeRouterRecipient(values[1].(common.Address), chainID)
+		if kind == urSweep {
+			step["amountMinimum"] = formatTokenAmount(values[2].(*big.Int), token, chainID)
+		} else {
+			step["amount"] = formatTokenAmount(values[2].(*big.Int), token, chainID)
+		}
+	case urWrapETH, urUnwrapWETH:
+		step["recipient"] = describeRouterRecipient(values[0].(common.Address), chainID)
+		step["amountMinimum"] = fmt.Sprintf("%s wei", values[1].(*big.Int))
+	}
+	return step, nil
+}
+
+// addRouterSwap adds the common fields of a Universal Router V2/V3 swap command
+func addRouterSwap(step map[string]interface{}, exactIn bool, values []interface{}, tokenIn, tokenOut common.Address, chainID uint64) {
+	step["recipient"] = describeRouterRecipient(values[0].(common.Address), chainID)
+	step["tokenIn"] = describeToken(tokenIn, chainID)
+	step["tokenOut"] = describeToken(tokenOut, chainID)
+	step["payerIsUser"] = values[4].(bool)
+	if exactIn {
+		step["amountIn"] = formatTokenAmount(values[1].(*big.Int), tokenIn, chainID)
+		step["amountOutMinimum"] = formatMinimumAmount(values[2].(*big.Int), tokenOut, chainID)
+	} else {
+		step["amountOut"] = formatTokenAmount(values[1].(*big.Int), tokenOut, chainID)
+		step["amountInMaximum"] = formatTokenAmount(values[2].(*big.Int), tokenIn, chainID)
+	}
+}
+
+// decodeOneInchSwap summarizes a 1inch Aggregation Router swap
+func decodeOneInchSwap(args map[string]interface{}, chainID uint64) (map[string]interface{}, error) {
+	executor, err := argValue[common.Address](args, "executor")
+	if err != nil {
+		return nil, err
+	}
+	var srcToken, dstToken, srcReceiver, dstReceiver common.Address
+	var amount, minReturn *big.Int
+	if err := tupleFields(args["desc"], map[string]interface{}{
+		"srcToken": &srcToken, "dstToken": &dstToken, "srcReceiver": &srcReceiver, "dstReceiver": &dstReceiver,
+		"amount": &amount, "minReturnAmount": &minReturn,
+	}); err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"executor":         describeAddress(executor, chainID),
+		"tokenInReceiver":  describeAddress(srcReceiver, chainID),
+		"tokenIn":          describeToken(srcToken, chainID),
+		"tokenOut":         describeToken(dstToken, chainID),
+		"amountIn":         formatTokenAmount(amount, srcToken, chainID),
+		"amountOutMinimum": formatMinimumAmount(minReturn, dstToken, chainID),
+		"recipient":        describeAddress(dstReceiver, chainID),
+		"deadline":         "none (1inch swaps do not expire)",
+	}, nil
+}
+
+// decodeCowSwapSettle summarizes the trades of a CowSwap settlement
+func decodeCowSwapSettle(args map[string]interface{}, chainID uint64) (map[string]interface{}, error) {
+	tokens, err := argValue[[]common.Address](args, "tokens")
+	if err != nil {
+		return nil, err
+	}
+	rawTrades, ok := args["trades"]
+	if !ok {
+		return nil, errors.New("missing trades")
+	}
+
+	var trades []map[string]interface{}
+	for _, trade := range toInterfaceSlice(rawTrades) {
+		var sellIndex, buyIndex, sellAmount, buyAmount, feeAmount *big.Int
+		var receiver common.Address
+		var validTo uint32
+		if err := tupleFields(trade, map[string]interface{}{
+			"sellTokenIndex": &sellIndex, "buyTokenIndex": &buyIndex, "receiver": &receiver,
+			"sellAmount": &sellAmount, "buyAmount": &buyAmount, "validTo": &validTo, "feeAmount": &feeAmount,
+		}); err != nil {
+			return nil, err
+		}
+		if !sellIndex.IsInt64() || !buyIndex.IsInt64() || sellIndex.Int64() >= int64(len(tokens)) || buyIndex.Int64() >= int64(len(tokens)) {
+			return nil, errors.New("trade token index out of range")
+		}
+		sellToken, buyToken := tokens[sellIndex.Int64()], tokens[buyIndex.Int64()]
+
+		recipient := describeAddress(receiver, chainID)
+		if receiver == (common.Address{}) {
+			recipient = receiver.Hex() + " (order owner)"
+		}
+		trades = append(trades, map[string]interface{}{
+			"tokenIn":          describeToken(sellToken, chainID),
+			"tokenOut":         describeToken(buyToken, chainID),
+			"amountIn":         formatTokenAmount(sellAmount, sellToken, chainID),
+			"amountOutMinimum": formatMinimumAmount(buyAmount, buyToken, chainID),
+			"fee":              formatTokenAmount(feeAmount, sellToken, chainID),
+			"recipient":        recipient,
+			"deadline":         formatDeadline(new(big.Int).SetUint64(uint64(validTo))),
+		})
+	}
+
+	return map[string]interface{}{"trades": trades}, nil
+}
+
+// decodeCowSwapPreSignature summarizes a CowSwap order pre-signature. The order UID packs the
+// order digest (32 bytes), the owner (20 bytes), and the validTo timestamp (4 bytes).
+func decodeCowSwapPreSignature(args map[string]interface{}, chainID uint64) (map[string]interface{}, error) {
+	uidHex, err := argValue[string](args, "orderUid")
+	if err != nil {
+		return nil, err
+	}
+	uid, err := hex.DecodeString(strings.TrimPrefix(uidHex, "0x"))
+	if err != nil {
+		return nil, err
+	}
+	if len(uid) != 56 {
+		return nil, fmt.Errorf("invalid order UID length %d", len(uid))
+	}
+	signed, err := argValue[bool](args, "signed")
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"orderUid":    uidHex,
+		"orderDigest": "0x" + hex.EncodeToString(uid[:32]),
+		"owner":       describeAddress(common.BytesToAddress(uid[32:52]), chainID),
+		"deadline":    formatDeadline(new(big.Int).SetBytes(uid[52:])),
+		"signed":      signed,
+	}, nil
+}
@@ -0,0 +1,356 @@
+package core
+
+import (
+	"encoding/hex"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// swapDeadline is 2023-11-14 22:13:20 UTC
+var swapDeadline = big.NewInt(1700000000)
+
+// encodeKnownCall ABI-encodes a call to the known function with the given signature
+func encodeKnownCall(t *testing.T, sig string, args ...interface{}) string {
+	t.Helper()
+	functions := []map[string]FunctionInfo{KnownFunctions}
+	for _, contract := range contractFunctions {
+		functions = append(functions, contract)
+	}
+	for _, known := range functions {
+		for _, info := range known {
+			if info.Signature != sig {
+				continue
+			}
+			packed, err := info.ABI.Inputs.Pack(args...)
+			if err != nil {
+				t.Fatalf("failed to pack %s: %v", sig, err)
+			}
+			return "0x" + hex.EncodeToString(append(info.ABI.ID, packed...))
+		}
+	}
+	t.Fatalf("no known function with signature %s", sig)
+	return ""
+}
+
+// parseKnownCall decodes calldata against a target and returns the fields of its summary
+func parseKnownCall(t *testing.T, target string, chainID uint64, data string) map[string]interface{} {
+	t.Helper()
+	call, err := ParseTransactionData(target, data, chainID, VerifyOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if call.RawData != "" {
+		t.Fatalf("expected %s to decode, got raw data", call.FunctionName)
+	}
+	if len(call.ParsedData) == 0 {
+		t.Fatalf("expected the raw arguments of %s next to its summary", call.FunctionName)
+	}
+	summary := map[string]interface{}{}
+	for _, field := range call.Summary {
+		summary[field.Name] = field.Value
+	}
+	return summary
+}
+
+// v3Path packs a Uniswap V3 path from alternating tokens and fees
+func v3Path(tokens []string, fees []int64) []byte {
+	path := common.HexToAddress(tokens[0]).Bytes()
+	for i, fee := range fees {
+		path = append(path, common.LeftPadBytes(big.NewInt(fee).Bytes(), 3)...)
+		path = append(path, common.HexToAddress(tokens[i+1]).Bytes()...)
+	}
+	return path
+}
+
+// expectFields checks that each summarized field contains the expected text
+func expectFields(t *testing.T, summary map[string]interface{}, expected map[string]string) {
+	t.Helper()
+	for key, want := range expected {
+		got, ok := summary[key].(string)
+		if !ok || !strings.Contains(got, want) {
+			t.Errorf("%s = %v, expected it to contain %q", key, summary[key], want)
+		}
+	}
+}
+
+func TestDecodeUniswapExactInputSingle(t *testing.T) {
+	params := struct {
+		TokenIn           common.Address
+		TokenOut          common.Address
+		Fee               *big.Int
+		Recipient         common.Address
+		Deadline          *big.Int
+		AmountIn          *big.Int
+		AmountOutMinimum  *big.Int
+		SqrtPriceLimitX96 *big.Int
+	}{
+		TokenIn:           common.HexToAddress(USDCOPMainnetAddress),
+		TokenOut:          common.HexToAddress(WETHPredeployAddress),
+		Fee:               big.NewInt(500),
+		Recipient:         common.HexToAddress(OPGrants1),
+		Deadline:          swapDeadline,
+		AmountIn:          big.NewInt(1_000_000_000),
+		AmountOutMinimum:  big.NewInt(300_000_000_000_000_000),
+		SqrtPriceLimitX96: big.NewInt(0),
+	}
+	data := encodeKnownCall(t, "exactInputSingle((address,address,uint24,address,uint256,uint256,uint256,uint160))", params)
+
+	summary := parseKnownCall(t, UniswapV3SwapRouter, OPMainnetChainID, data)
+	expectFields(t, summary, map[string]string{
+		"tokenIn":          USDCOPMainnetAddress + " (USDC 🔍)",
+		"tokenOut":         "(WETH 🔍)",
+		"fee":              "0.05% pool",
+		"recipient":        OPGrants1 + " (OP GRANTS 1 (3F0) 🔍)",
+		"deadline":         "2023-11-14 22:13:20 UTC",
+		"amountIn":         "1,000.00 USDC",
+		"amountOutMinimum": "0.3 WETH",
+	})
+}
+
+func TestDecodeUniswapExactInputPath(t *testing.T) {
+	params := struct {
+		Path             []byte
+		Recipient        common.Address
+		AmountIn         *big.Int
+		AmountOutMinimum *big.Int
+	}{
+		Path:             v3Path([]string{USDCOPMainnetAddress, WETHPredeployAddress, OPTokenAddress}, []int64{500, 3000}),
+		Recipient:        common.HexToAddress(OPGrants1),
+		AmountIn:         big.NewInt(5_000_000),
+		AmountOutMinimum: big.NewInt(0),
+	}
+	data := encodeKnownCall(t, "exactInput((bytes,address,uint256,uint256))", params)
+
+	summary := parseKnownCall(t, UniswapSwapRouter02, OPMainnetChainID, data)
+	expectFields(t, summary, map[string]string{
+		"tokenIn":          "(USDC 🔍)",
+		"tokenOut":         "(OP TOKEN 🔍)",
+		"route":            "(USDC 🔍) → [0.05% pool] → " + WETHPredeployAddress + " (WETH 🔍) → [0.3% pool] → ",
+		"amountIn":         "5.00 USDC",
+		"amountOutMinimum": "NO MINIMUM",
+	})
+	if _, ok := summary["deadline"]; ok {
+		t.Error("SwapRouter02 calls carry no deadline")
+	}
+}
+
+func TestDecodeUniswapExactOutputPathIsReversed(t *testing.T) {
+	params := struct {
+		Path            []byte
+		Recipient       common.Address
+		Deadline        *big.Int
+		AmountOut       *big.Int
+		AmountInMaximum *big.Int
+	}{
+		// Exact output paths start at the output token
+		Path:            v3Path([]string{WETHPredeployAddress, USDCOPMainnetAddress}, []int64{500}),
+		Recipient:       common.HexToAddress(OPGrants1),
+		Deadline:        swapDeadline,
+		AmountOut:       big.NewInt(1_000_000_000_000_000_000),
+		AmountInMaximum: big.NewInt(4_000_000_000),
+	}
+	data := encodeKnownCall(t, "exactOutput((bytes,address,uint256,uint256,uint256))", params)
+
+	summary := parseKnownCall(t, UniswapV3SwapRouter, OPMainnetChainID, data)
+	expectFields(t, summary, map[string]string{
+		"tokenIn":         "(USDC 🔍)",
+		"tokenOut":        "(WETH 🔍)",
+		"amountOut":       "1.00 WETH",
+		"amountInMaximum": "4,000.00 USDC",
+	})
+}
+
+func TestDecodeUniversalRouter(t *testing.T) {
+	arg := func(s string) abi.Argument {
+		typ, _ := abi.NewType(s, "", nil)
+		return abi.Argument{Type: typ}
+	}
+	swapInput, err := abi.Arguments{arg("address"), arg("uint256"), arg("uint256"), arg("bytes"), arg("bool")}.Pack(
+		common.BigToAddress(big.NewInt(2)), big.NewInt(2_000_000), big.NewInt(500_000_000_000_000),
+		v3Path([]string{USDCOPMainnetAddress, WETHPredeployAddress}, []int64{500}), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	unwrapInput, err := abi.Arguments{arg("address"), arg("uint256")}.Pack(common.BigToAddress(big.NewInt(1)), big.NewInt(500_000_000_000_000))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data := encodeKnownCall(t, "execute(bytes,bytes[],uint256)", []byte{0x00, 0x0c, 0x0a}, [][]byte{swapInput, unwrapInput, {0x01}}, swapDeadline)
+	summary := parseKnownCall(t, UniswapUniversalRouterOP, OPMainnetChainID, data)
+
+	expectFields(t, summary, map[string]string{"deadline": "2023-11-14 22:13:20 UTC"})
+	commands := summary["commands"].([]map[string]interface{})
+	if len(commands) != 3 {
+		t.Fatalf("expected 3 commands, got %d", len(commands))
+	}
+	expectFields(t, commands[0], map[string]string{
+		"command":          "V3_SWAP_EXACT_IN",
+		"recipient":        "ADDRESS_THIS",
+		"amountIn":         "2.00 USDC",
+		"amountOutMinimum": "0.0005 WETH",
+	})
+	expectFields(t, commands[1], map[string]string{"command": "UNWRAP_WETH", "recipient": "MSG_SENDER"})
+	expectFields(t, commands[2], map[string]string{"command": "PERMIT2_PERMIT", "input": "0x01"})
+}
+
+func TestDecodeOneInchSwap(t *testing.T) {
+	desc := struct {
+		SrcToken        common.Address
+		DstToken        common.Address
+		SrcReceiver     common.Address
+		DstReceiver     common.Address
+		Amount          *big.Int
+		MinReturnAmount *big.Int
+		Flags           *big.Int
+	}{
+		SrcToken:        common.HexToAddress(NativeTokenPlaceholder),
+		DstToken:        common.HexToAddress(USDCMainnetAddress),
+		SrcReceiver:     common.HexToAddress("0x3a3a3a3a3a3a3a3a3a3a3a3a3a3a3a3a3a3a3a3a"),
+		DstReceiver:     common.HexToAddress(ProxyAdminOwner),
+		Amount:          big.NewInt(1_000_000_000_000_000_000),
+		MinReturnAmount: big.NewInt(0),
+		Flags:           big.NewInt(0),
+	}
+	executor := common.HexToAddress("0xe37e799d5077682fa0a244d46e5649f71457bd09")
+	data := encodeKnownCall(t, "swap(address,(address,address,address,address,uint256,uint256,uint256),bytes)", executor, desc, []byte{})
+
+	summary := parseKnownCall(t, OneInchRouterV6, MainnetChainID, data)
+	expectFields(t, summary, map[string]string{
+		"tokenIn":          "(NATIVE TOKEN)",
+		"tokenOut":         "(USDC 🔍)",
+		"amountOutMinimum": "NO MINIMUM",
+		"recipient":        "(SUPERCHAIN PROXY ADMIN OWNER 🔍)",
+		"tokenInReceiver":  "0x3A3a3A3a3A3A3a3A3a3A3a3A3a3a3A3a3A3a3a3a",
+	})
+
+	// The raw arguments stay next to the summary, with every field of the description
+	call, err := ParseTransactionData(OneInchRouterV6, data, MainnetChainID, VerifyOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	raw, ok := call.Argument("desc")
+	if !ok {
+		t.Fatalf("expected the raw desc argument, got %+v", call.ParsedData)
+	}
+	if srcReceiver, err := structField(raw.Value, "srcReceiver"); err != nil || srcReceiver != desc.SrcReceiver {
+		t.Errorf("raw srcReceiver = %v (%v), want %s", srcReceiver, err, desc.SrcReceiver)
+	}
+}
+
+func TestSwapDecodersApplyOnlyToKnownRouters(t *testing.T) {
+	params := struct {
+		TokenIn           common.Address
+		TokenOut          common.Address
+		Fee               *big.Int
+		Recipient         common.Address
+		AmountIn          *big.Int
+		AmountOutMinimum  *big.Int
+		SqrtPriceLimitX96 *big.Int
+	}{
+		TokenIn:           common.HexToAddress(USDCOPMainnetAddress),
+		TokenOut:          common.HexToAddress(WETHPredeployAddress),
+		Fee:               big.NewInt(500),
+		Recipient:         common.HexToAddress(OPGrants1),
+		AmountIn:          big.NewInt(1_000_000_000),
+		AmountOutMinimum:  big.NewInt(0),
+		SqrtPriceLimitX96: big.NewInt(0),
+	}
+	data := encodeKnownCall(t, "exactInputSingle((address,address,uint24,address,uint256,uint256,uint160))", params)
+
+	// The same calldata to a contract that is not a known router keeps its raw arguments only
+	call, err := ParseTransactionData("0x5555555555555555555555555555555555555555", data, OPMainnetChainID, VerifyOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if call.Summary != nil || len(call.ParsedData) != 1 {
+		t.Errorf("expected raw arguments and no summary, got %+v and %+v", call.ParsedData, call.Summary)
+	}
+
+	// A router is known per chain: SwapRouter02 on Base lives at another address
+	call, err = ParseTransactionData(UniswapSwapRouter02, data, BaseMainnetChainID, VerifyOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if call.Summary != nil {
+		t.Errorf("expected no summary on a chain where the router is not known, got %+v", call.Summary)
+	}
+	if summary := parseKnownCall(t, UniswapSwapRouter02Base, BaseMainnetChainID, data); summary["tokenIn"] == nil {
+		t.Errorf("expected a summary on the Base router, got %+v", summary)
+	}
+}
+
+func TestDecodeCowSwapSettle(t *testing.T) {
+	type trade struct {
+		SellTokenIndex *big.Int
+		BuyTokenIndex  *big.Int
+		Receiver       common.Address
+		SellAmount     *big.Int
+		BuyAmount      *big.Int
+		ValidTo        uint32
+		AppData        [32]byte
+		FeeAmount      *big.Int
+		Flags          *big.Int
+		ExecutedAmount *big.Int
+		Signature      []byte
+	}
+	type interaction struct {
+		Target   common.Address
+		Value    *big.Int
+		CallData []byte
+	}
+	tokens := []common.Address{common.HexToAddress(USDCMainnetAddress), common.HexToAddress(WETHMainnetAddress)}
+	trades := []trade{{
+		SellTokenIndex: big.NewInt(0), BuyTokenIndex: big.NewInt(1),
+		SellAmount: big.NewInt(2_500_000_000), BuyAmount: big.NewInt(1_000_000_000_000_000_000),
+		ValidTo: uint32(swapDeadline.Int64()), FeeAmount: big.NewInt(1_000_000),
+		Flags: big.NewInt(0), ExecutedAmount: big.NewInt(0), Signature: []byte{},
+	}}
+	data := encodeKnownCall(t, "settle(address[],uint256[],(uint256,uint256,address,uint256,uint256,uint32,bytes32,uint256,uint256,uint256,bytes)[],(address,uint256,bytes)[][3])",
+		tokens, []*big.Int{big.NewInt(1), big.NewInt(1)}, trades, [3][]interaction{{}, {}, {}})
+
+	summary := parseKnownCall(t, CowSwapSettlement, MainnetChainID, data)
+	decoded := summary["trades"].([]map[string]interface{})
+	if len(decoded) != 1 {
+		t.Fatalf("expected 1 trade, got %d", len(decoded))
+	}
+	expectFields(t, decoded[0], map[string]string{
+		"tokenIn":          "(USDC 🔍)",
+		"tokenOut":         "(WETH 🔍)",
+		"amountIn":         "2,500.00 USDC",
+		"amountOutMinimum": "1.00 WETH",
+		"recipient":        "(order owner)",
+		"deadline":         "2023-11-14 22:13:20 UTC",
+	})
+}
+
+func TestDecodeCowSwapPreSignature(t *testing.T) {
+	uid := append(make([]byte, 32), common.HexToAddress(ProxyAdminOwner).Bytes()...)
+	uid = append(uid, common.LeftPadBytes(swapDeadline.Bytes(), 4)...)
+	data := encodeKnownCall(t, "setPreSignature(bytes,bool)", uid, true)
+
+	summary := parseKnownCall(t, CowSwapSettlement, MainnetChainID, data)
+	expectFields(t, summary, map[string]string{
+		"owner":    ProxyAdminOwner,
+		"deadline": "2023-11-14 22:13:20 UTC",
+	})
+	if summary["signed"] != true {
+		t.Errorf("expected signed to be true, got %v", summary["signed"])
+	}
+}
+
+func TestSwapDecodersAreRegistered(t *testing.T) {
+	signatures := map[string]bool{}
+	for _, info := range KnownFunctions {
+		signatures[info.Signature] = true
+	}
+	for sig := range CallDecoders {
+		if !signatures[sig] {
+			t.Errorf("decoder registered for %s but the function is not known", sig)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package core
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// emptyTrieRoot is the root hash of a trie with no keys
+var emptyTrieRoot = crypto.Keccak256Hash([]byte{0x80})
+
+// walkTrieProof walks a Merkle-Patricia proof from root along key and returns the value stored at
+// key, or nil when the proof shows the key is absent. Nodes are looked up by their hash, as
+// eth_getProof returns them; a node shorter than 32 bytes is embedded in its parent.
+func walkTrieProof(root common.Hash, key []byte, proof [][]byte) ([]byte, error) {
+	if root == emptyTrieRoot {
+		return nil, nil
+	}
+	nodes := make(map[common.Hash][]byte, len(proof))
+	for _, node := range proof {
+		nodes[crypto.Keccak256Hash(node)] = node
+	}
+
+	path := keyNibbles(key)
+	ref := root.Bytes()
+	for {
+		node := ref
+		if len(ref) == common.HashLength {
+			var ok bool
+			if node, ok = nodes[common.BytesToHash(ref)]; !ok {
+				return nil, fmt.Errorf("proof is missing trie node %x", ref)
+			}
+		}
+		items, err := trieNodeItems(node)
+		if err != nil {
+			return nil, err
+		}
+
+		switch len(items) {
+		case 17:
+			if len(path) == 0 {
+				value, err := rlpStringContent(items[16])
+				if err != nil || len(value) == 0 {
+					return nil, err
+				}
+				return value, nil
+			}
+			if ref, err = childReference(items[path[0]]); err != nil {
+				return nil, err
+			}
+			path = path[1:]
+		case 2:
+			encodedPath, err := rlpStringContent(items[0])
+			if err != nil {
+				return nil, err
+			}
+			nibbles, leaf, err := compactNibbles(encodedPath)
+			if err != nil {
+				return nil, err
+			}
+			if !bytes.HasPrefix(path, nibbles) {
+				return nil, nil
+			}
+			path = path[len(nibbles):]
+			if leaf {
+				if len(path) != 0 {
+					return nil, nil
+				}
+				return rlpStringContent(items[1])
+			}
+			if ref, err = childReference(items[1]); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, fmt.Errorf("invalid trie node with %d items", len(items))
+		}
+		if len(ref) == 0 {
+			return nil, nil
+		}
+	}
+}
+
+// trieNodeItems splits an RLP list node into its raw items
+func trieNodeItems(node []byte) ([][]byte, error) {
+	content, rest, err := rlp.SplitList(node)
+	if err != nil || len(rest) != 0 {
+		return nil, errors.New("invalid trie node")
+	}
+	var items [][]byte
+	for len(content) > 0 {
+		_, _, next, err := rlp.Split(content)
+		if err != nil {
+			return nil, errors.New("invalid trie node")
+		}
+		items = append(items, content[:len(content)-len(next)])
+		content = next
+	}
+	return items, nil
+}
+
+// rlpStringContent returns the content of a raw RLP string item
+func rlpStringContent(item []byte) ([]byte, error) {
+	kind, content, _, err := rlp.Split(item)
+	if err != nil || kind == rlp.List {
+		return nil, errors.New("invalid trie node value")
+	}
+	return content, nil
+}
+
+// childReference returns the reference a branch or extension holds to its child: the child's
+// hash, the child node itself when it is embedded, or nothing for an empty branch slot
+func childReference(item []byte) ([]byte, error) {
+	kind, content, _, err := rlp.Split(item)
+	switch {
+	case err != nil:
+		return nil, errors.New("invalid trie node reference")
+	case kind == rlp.List:
+		return item, nil
+	case len(content) == 0 || len(content) == common.HashLength:
+		return content, nil
+	default:
+		return nil, fmt.Errorf("invalid trie node reference of %d bytes", len(content))
+	}
+}
+
+// keyNibbles splits a key into the 4-bit nibbles the trie branches on
+func keyNibbles(key []byte) []byte {
+	nibbles := make([]byte, 0, len(key)*2)
+	for _, b := range key {
+		nibbles = append(nibbles, b>>4, b&0x0f)
+	}
+	return nibbles
+}
+
+// compactNibbles decodes the hex-prefix encoded path of a leaf or extension node
+func compactNibbles(encoded []byte) ([]byte, bool, error) {
+	if len(encoded) == 0 {
+		return nil, false, errors.New("invalid trie node path")
+	}
+	flag := encoded[0] >> 4
+	if flag > 3 {
+		return nil, false, errors.New("invalid trie node path")
+	}
+	nibbles := keyNibbles(encoded)[1:]
+	if flag&1 == 0 {
+		nibbles = nibbles[1:]
+	}
+	return nibbles, flag&2 != 0, nil
+}
@@ -0,0 +1,85 @@
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// recordedProof is a proof go-ethereum's trie made for a key, recorded in testdata so that core
+// does not depend on the trie package even in its tests. Value is empty when the key is absent.
+type recordedProof struct {
+	Trie  string          `json:"trie"`
+	Root  common.Hash     `json:"root"`
+	Key   hexutil.Bytes   `json:"key"`
+	Proof []hexutil.Bytes `json:"proof"`
+	Value hexutil.Bytes   `json:"value"`
+}
+
+// loadRecordedProofs reads the proofs in testdata/trie-proofs.json
+func loadRecordedProofs(t *testing.T) []recordedProof {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", "trie-proofs.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var proofs []recordedProof
+	if err := json.Unmarshal(data, &proofs); err != nil {
+		t.Fatal(err)
+	}
+	return proofs
+}
+
+// proveKey returns the root of a recorded trie and its proof of key
+func proveKey(t *testing.T, trie string, key []byte) (common.Hash, [][]byte) {
+	t.Helper()
+	for _, recorded := range loadRecordedProofs(t) {
+		if recorded.Trie == trie && bytes.Equal(recorded.Key, key) {
+			proof := make([][]byte, len(recorded.Proof))
+			for i, node := range recorded.Proof {
+				proof[i] = node
+			}
+			return recorded.Root, proof
+		}
+	}
+	t.Fatalf("no recorded proof of %x in the %s trie", key, trie)
+	return common.Hash{}, nil
+}
+
+func TestWalkTrieProofMatchesGoEthereum(t *testing.T) {
+	// The hashed trie has the shape of a storage trie; the short one has embedded nodes
+	for _, recorded := range loadRecordedProofs(t) {
+		root, proof := proveKey(t, recorded.Trie, recorded.Key)
+		got, err := walkTrieProof(root, recorded.Key, proof)
+		if err != nil {
+			t.Fatalf("%s trie, key %x: unexpected error: %v", recorded.Trie, recorded.Key, err)
+		}
+		if (len(recorded.Value) == 0) != (got == nil) || !bytes.Equal(got, recorded.Value) {
+			t.Errorf("%s trie, key %x = %x, want %x", recorded.Trie, recorded.Key, got, recorded.Value)
+		}
+	}
+}
+
+func TestWalkTrieProofRejectsIncompleteProofs(t *testing.T) {
+	key := crypto.Keccak256([]byte{3})
+	root, proof := proveKey(t, "hashed", key)
+
+	if _, err := walkTrieProof(root, key, proof[:len(proof)-1]); err == nil {
+		t.Error("expected an error for a proof missing its last node")
+	}
+	tampered := append([][]byte{}, proof...)
+	tampered[len(tampered)-1] = append([]byte{}, tampered[len(tampered)-1]...)
+	tampered[len(tampered)-1][len(tampered[len(tampered)-1])-1] ^= 0x01
+	if _, err := walkTrieProof(root, key, tampered); err == nil {
+		t.Error("expected an error for a proof with a modified node")
+	}
+	if value, err := walkTrieProof(emptyTrieRoot, key, nil); value != nil || err != nil {
+		t.Errorf("empty trie = %x, %v; want an absent key", value, err)
+	}
+}
@@ -0,0 +1,231 @@
+package core
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"reflect"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Kinds of a TransactionChange
+const (
+	ChangeModified = "changed"
+	ChangeAdded    = "added"
+	ChangeRemoved  = "removed"
+)
+
+// TransactionChange is a field or decoded value that differs between two transactions. Decoded
+// values are named by their call and argument path, such as "call #2 amount" or
+// "call request.data.recipient" for the root call, with the calls of an approved child
+// transaction prefixed with "child ".
+type TransactionChange struct {
+	Kind     string `json:"kind"`
+	Field    string `json:"field"`
+	Previous string `json:"previous,omitempty"`
+	Current  string `json:"current,omitempty"`
+}
+
+// TransactionDiff is how a transaction differs from the one at another nonce of the same Safe,
+// such as the transaction it corrects. The nonces themselves are expected to differ and are not
+// listed as a change.
+type TransactionDiff struct {
+	PreviousNonce       int                 `json:"previousNonce"`
+	PreviousSafeTxHash  string              `json:"previousSafeTxHash"`
+	TransactionChanges  []TransactionChange `json:"transactionChanges"`
+	DecodedValueChanges []TransactionChange `json:"decodedValueChanges"`
+}
+
+// Empty reports whether the transactions only differ in their nonces
+func (d *TransactionDiff) Empty() bool {
+	return len(d.TransactionChanges) == 0 && len(d.DecodedValueChanges) == 0
+}
+
+// DiffTransactions compares a verified transaction with a verified earlier one: the hashed fields
+// of each, and every decoded call target, function, value, and argument
+func DiffTransactions(previous, current *VerificationResult) *TransactionDiff {
+	diff := &TransactionDiff{
+		PreviousNonce:       previous.Transaction.Nonce,
+		PreviousSafeTxHash:  previous.ApproveHash,
+		TransactionChanges:  diffFields(transactionFields(previous), transactionFields(current)),
+		DecodedValueChanges: diffFields(decodedValues(previous), decodedValues(current)),
+	}
+	return diff
+}
+
+// diffField is a named value of a transaction, in display order
+type diffField struct {
+	name, value string
+}
+
+// diffFields compares two lists of fields by name, listing changes in the order of the current
+// fields followed by removed ones
+func diffFields(previous, current []diffField) []TransactionChange {
+	previousValues := map[string]string{}
+	for _, field := range previous {
+		previousValues[field.name] = field.value
+	}
+	currentNames := map[string]bool{}
+
+	changes := []TransactionChange{}
+	for _, field := range current {
+		currentNames[field.name] = true
+		old, ok := previousValues[field.name]
+		switch {
+		case !ok:
+			changes = append(changes, TransactionChange{Kind: ChangeAdded, Field: field.name, Current: field.value})
+		case old != field.value:
+			changes = append(changes, TransactionChange{Kind: ChangeModified, Field: field.name, Previous: old, Current: field.value})
+		}
+	}
+	for _, field := range previous {
+		if !currentNames[field.name] {
+			changes = append(changes, TransactionChange{Kind: ChangeRemoved, Field: field.name, Previous: field.value})
+		}
+	}
+	return changes
+}
+
+// transactionFields returns the hashed fields of a transaction other than its nonce, and those
+// of an approved child transaction
+func transactionFields(result *VerificationResult) []diffField {
+	var fields []diffField
+	add := func(prefix string, tx SafeTransaction) {
+		value := "0"
+		if tx.Value != nil {
+			value = tx.Value.String()
+		}
+		data := common.FromHex(tx.Data)
+		fields = append(fields,
+			diffField{prefix + "safe", ChecksumAddress(tx.Safe)},
+			diffField{prefix + "to", ChecksumAddress(tx.To)},
+			diffField{prefix + "value", value},
+			diffField{prefix + "data", fmt.Sprintf("%d bytes, keccak256 %s", len(data), crypto.Keccak256Hash(data).Hex())},
+			diffField{prefix + "operation", fmt.Sprint(tx.Operation)},
+			diffField{prefix + "safe_tx_gas", fmt.Sprint(tx.SafeTxGas)},
+			diffField{prefix + "base_gas", fmt.Sprint(tx.BaseGas)},
+			diffField{prefix + "gas_price", fmt.Sprint(tx.GasPrice)},
+			diffField{prefix + "gas_token", ChecksumAddress(tx.GasToken)},
+			diffField{prefix + "refund_receiver", ChecksumAddress(tx.RefundReceiver)},
+		)
+	}
+	if result.NestedResult != nil {
+		add("child ", result.NestedResult.Transaction)
+		fields = append(fields, diffField{"child nonce", fmt.Sprint(result.NestedResult.Transaction.Nonce)})
+	}
+	add("", result.Transaction)
+	return fields
+}
+
+// decodedValues returns the target, function, value, and decoded arguments of every call of a
+// transaction and of an approved child transaction, and of the L2 transactions they deposit
+func decodedValues(result *VerificationResult) []diffField {
+	var fields []diffField
+	var visit func(prefix string, call CallData)
+	visit = func(prefix string, call CallData) {
+		name := prefix + "call "
+		if call.Index != "" {
+			name = prefix + "call #" + call.Index + " "
+		}
+		fields = append(fields,
+			diffField{name + "(target)", ChecksumAddress(call.Target)},
+			diffField{name + "(function)", call.FunctionName})
+		if call.Value != nil {
+			fields = append(fields, diffField{name + "(value)", call.Value.String()})
+		}
+		if call.RawData != "" {
+			fields = append(fields, diffField{name + "(calldata)", call.RawData})
+		}
+		for _, arg := range call.ParsedData {
+			if arg.Display != "" {
+				fields = append(fields, diffField{name + arg.Name, arg.Display})
+				continue
+			}
+			flattenDecodedValue(name+arg.Name, reflect.ValueOf(arg.Value), 0, func(path, value string) {
+				fields = append(fields, diffField{path, value})
+			})
+		}
+		if deposit := call.Deposit; deposit != nil {
+			fields = append(fields,
+				diffField{name + "(L2 value)", deposit.Value.String()},
+				diffField{name + "(L2 gas limit)", fmt.Sprint(deposit.GasLimit)})
+			if deposit.IsCreation {
+				fields = append(fields, diffField{name + "(L2 init code)", deposit.InitCodeHash})
+			}
+			if deposit.Call != nil {
+				visit(prefix+"L2 ", *deposit.Call)
+			}
+		}
+		for _, subcall := range call.SubCalls {
+			visit(prefix, subcall)
+		}
+	}
+	if result.NestedResult != nil {
+		visit("child ", result.NestedResult.Call)
+	}
+	visit("", result.Call)
+	return fields
+}
+
+// flattenDecodedValue calls emit with the path and text of every leaf of a decoded value
+func flattenDecodedValue(path string, value reflect.Value, depth int, emit func(path, value string)) {
+	for value.IsValid() && (value.Kind() == reflect.Interface || value.Kind() == reflect.Pointer) {
+		if value.Kind() == reflect.Pointer && value.Type() == reflect.TypeOf((*big.Int)(nil)) {
+			break
+		}
+		value = value.Elem()
+	}
+	if !value.IsValid() {
+		emit(path, "nil")
+		return
+	}
+	if depth > 8 {
+		emit(path, fmt.Sprintf("%v", value.Interface()))
+		return
+	}
+
+	switch v := value.Interface().(type) {
+	case common.Address:
+		emit(path, v.Hex())
+		return
+	case *big.Int:
+		emit(path, v.String())
+		return
+	case []byte:
+		emit(path, "0x"+hex.EncodeToString(v))
+		return
+	}
+
+	switch value.Kind() {
+	case reflect.Array, reflect.Slice:
+		if value.Type().Elem().Kind() == reflect.Uint8 {
+			raw := make([]byte, value.Len())
+			for i := range raw {
+				raw[i] = uint8(value.Index(i).Uint())
+			}
+			emit(path, "0x"+hex.EncodeToString(raw))
+			return
+		}
+		emit(path+".length", fmt.Sprint(value.Len()))
+		for i := 0; i < value.Len(); i++ {
+			flattenDecodedValue(fmt.Sprintf("%s[%d]", path, i), value.Index(i), depth+1, emit)
+		}
+	case reflect.Struct:
+		for i := 0; i < value.NumField(); i++ {
+			if field := value.Type().Field(i); field.PkgPath == "" {
+				flattenDecodedValue(path+"."+TupleFieldName(field), value.Field(i), depth+1, emit)
+			}
+		}
+	case reflect.Map:
+		keys := value.MapKeys()
+		sort.Slice(keys, func(i, j int) bool { return fmt.Sprint(keys[i]) < fmt.Sprint(keys[j]) })
+		for _, key := range keys {
+			flattenDecodedValue(fmt.Sprintf("%s.%v", path, key), value.MapIndex(key), depth+1, emit)
+		}
+	default:
+		emit(path, fmt.Sprintf("%v", value.Interface()))
+	}
+}
@@ -0,0 +1,57 @@
+package core
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestDiffTransactions(t *testing.T) {
+	verify := func(nonce int, amount int64) *VerificationResult {
+		tx := multiSendTx(t,
+			erc20Transfer(airdropToken, airdropAlice, big.NewInt(100)),
+			erc20Transfer(airdropToken, airdropBob, big.NewInt(amount)))
+		tx.Safe, tx.SafeVersion, tx.Nonce = effectsSafe, "1.3.0", nonce
+		result, err := VerifyTransaction(tx, VerifyOptions{})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return result
+	}
+
+	previous := verify(4, 200)
+	diff := DiffTransactions(previous, verify(5, 250))
+	if diff.PreviousNonce != 4 || diff.PreviousSafeTxHash != previous.ApproveHash {
+		t.Fatalf("unexpected previous transaction: %+v", diff)
+	}
+	if len(diff.TransactionChanges) != 1 || diff.TransactionChanges[0].Field != "data" {
+		t.Errorf("expected only the data to change, got %+v", diff.TransactionChanges)
+	}
+	want := TransactionChange{Kind: ChangeModified, Field: "call #2 amount", Previous: "200", Current: "250"}
+	if len(diff.DecodedValueChanges) != 1 || diff.DecodedValueChanges[0] != want {
+		t.Errorf("expected only the second amount to change, got %+v", diff.DecodedValueChanges)
+	}
+
+	// Only the nonce differs
+	if diff := DiffTransactions(previous, verify(5, 200)); !diff.Empty() {
+		t.Errorf("expected no changes besides the nonce, got %+v", diff)
+	}
+}
+
+func TestDiffFields(t *testing.T) {
+	changes := diffFields(
+		[]diffField{{"a", "1"}, {"b", "2"}, {"c", "3"}},
+		[]diffField{{"a", "1"}, {"b", "4"}, {"d", "5"}})
+	want := []TransactionChange{
+		{Kind: ChangeModified, Field: "b", Previous: "2", Current: "4"},
+		{Kind: ChangeAdded, Field: "d", Current: "5"},
+		{Kind: ChangeRemoved, Field: "c", Previous: "3"},
+	}
+	if len(changes) != len(want) {
+		t.Fatalf("diffFields = %+v, want %+v", changes, want)
+	}
+	for i := range want {
+		if changes[i] != want[i] {
+			t.Errorf("change %d = %+v, want %+v", i, changes[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,286 @@
+package core
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// TransactionEncodingVersion is the first byte of a transaction in the binary encoding. A later
+// version changes the layout after it. Versions are chosen so the first byte of an encoding can
+// not be mistaken for "{", which starts the JSON encoding, or for a zlib or gzip header, which
+// DecodeLinkPayload detects.
+const TransactionEncodingVersion byte = 1
+
+// encodedTransaction is the layout of version 1 of the binary encoding: the fields of a Safe
+// transaction that are hashed, in the order of the SafeTx type, and the nested approval they
+// are wrapped in, if any. Service metadata and decoded call data are left out.
+type encodedTransaction struct {
+	Chain          uint64
+	Safe           common.Address
+	SafeVersion    string
+	To             common.Address
+	Value          *big.Int
+	Data           []byte
+	Operation      uint8
+	SafeTxGas      uint64
+	BaseGas        uint64
+	GasPrice       uint64
+	GasToken       common.Address
+	RefundReceiver common.Address
+	Nonce          uint64
+	Nested         *encodedNested `rlp:"optional"`
+}
+
+// encodedNested is the layout of the nested approval of a transaction in the binary encoding
+type encodedNested struct {
+	Safe        common.Address
+	SafeVersion string
+	Nonce       uint64
+	Data        []byte
+	Operation   uint8
+	To          common.Address
+}
+
+// EncodeTransaction encodes a transaction in the compact binary encoding that share links and QR
+// codes carry instead of JSON: TransactionEncodingVersion followed by the RLP encoding of the
+// hashed fields. RLP has a single encoding for every value, so two copies of a transaction encode
+// to the same bytes and a hash of the encoding identifies the transaction. Addresses are 20 bytes
+// and the calldata is raw bytes, which makes the encoding about half the size of the JSON.
+func EncodeTransaction(tx SafeTransaction) ([]byte, error) {
+	encoded := encodedTransaction{
+		SafeVersion: tx.SafeVersion,
+		Value:       new(big.Int),
+	}
+	var err error
+	if _, err = encodingUint("chain", tx.Chain); err != nil {
+		return nil, err
+	}
+	// The chain is encoded once, so it is resolved from the chain field and the address prefixes
+	// as VerifyTransaction resolves it, and the prefixes are dropped from the addresses
+	sources, err := transactionChainSources(tx)
+	if err != nil {
+		return nil, fmt.Errorf("cannot encode transaction: %w", err)
+	}
+	if encoded.Chain, err = ResolveChainID(sources...); err != nil {
+		return nil, fmt.Errorf("cannot encode transaction: %w", err)
+	}
+	chain := encoded.Chain
+	if encoded.Safe, err = encodingAddress("safe", tx.Safe, chain, false); err != nil {
+		return nil, err
+	}
+	if encoded.To, err = encodingAddress("to", tx.To, chain, false); err != nil {
+		return nil, err
+	}
+	if tx.Value != nil {
+		if tx.Value.Sign() < 0 {
+			return nil, fmt.Errorf("cannot encode a negative value: %s", tx.Value)
+		}
+		encoded.Value.Set(tx.Value)
+	}
+	if encoded.Data, err = encodingData("data", tx.Data); err != nil {
+		return nil, err
+	}
+	if encoded.Operation, err = encodingOperation("operation", tx.Operation); err != nil {
+		return nil, err
+	}
+	if encoded.SafeTxGas, err = encodingUint("safe_tx_gas", tx.SafeTxGas); err != nil {
+		return nil, err
+	}
+	if encoded.BaseGas, err = encodingUint("base_gas", tx.BaseGas); err != nil {
+		return nil, err
+	}
+	if encoded.GasPrice, err = encodingUint("gas_price", tx.GasPrice); err != nil {
+		return nil, err
+	}
+	if encoded.GasToken, err = encodingAddress("gas_token", tx.GasToken, chain, true); err != nil {
+		return nil, err
+	}
+	if encoded.RefundReceiver, err = encodingAddress("refund_receiver", tx.RefundReceiver, chain, true); err != nil {
+		return nil, err
+	}
+	if encoded.Nonce, err = encodingUint("nonce", tx.Nonce); err != nil {
+		return nil, err
+	}
+
+	if nested := tx.Nested; nested != nil {
+		encoded.Nested = &encodedNested{SafeVersion: nested.SafeVersion}
+		if encoded.Nested.Safe, err = encodingAddress("nested safe", nested.Safe, chain, false); err != nil {
+			return nil, err
+		}
+		if encoded.Nested.Nonce, err = encodingUint("nested nonce", nested.Nonce); err != nil {
+			return nil, err
+		}
+		if encoded.Nested.Data, err = encodingData("nested data", nested.Data); err != nil {
+			return nil, err
+		}
+		if encoded.Nested.Operation, err = encodingOperation("nested operation", nested.Operation); err != nil {
+			return nil, err
+		}
+		if encoded.Nested.To, err = encodingAddress("nested to", nested.To, chain, false); err != nil {
+			return nil, err
+		}
+	}
+
+	data, err := rlp.EncodeToBytes(&encoded)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode transaction: %w", err)
+	}
+	return append([]byte{TransactionEncodingVersion}, data...), nil
+}
+
+// DecodeTransaction decodes a transaction encoded by EncodeTransaction. Only the canonical
+// encoding is accepted: trailing bytes, integers with leading zeros, and any other encoding that
+// EncodeTransaction would not produce are refused, so a transaction has exactly one encoding.
+func DecodeTransaction(data []byte) (*SafeTransaction, error) {
+	tx, err := decodeTransaction(data)
+	if err != nil {
+		return nil, withKind(ErrDecodeFailure, err)
+	}
+	return tx, nil
+}
+
+// decodeTransaction decodes the binary encoding for DecodeTransaction
+func decodeTransaction(data []byte) (*SafeTransaction, error) {
+	if len(data) == 0 {
+		return nil, fmt.Errorf("empty transaction encoding")
+	}
+	if data[0] != TransactionEncodingVersion {
+		return nil, fmt.Errorf("unsupported transaction encoding version %d; this build of op-txverify reads version %d", data[0], TransactionEncodingVersion)
+	}
+	var encoded encodedTransaction
+	if err := rlp.DecodeBytes(data[1:], &encoded); err != nil {
+		return nil, fmt.Errorf("invalid transaction encoding: %w", err)
+	}
+	if encoded.Operation > 1 || (encoded.Nested != nil && encoded.Nested.Operation > 1) {
+		return nil, fmt.Errorf("invalid transaction encoding: operation must be 0 (call) or 1 (delegatecall)")
+	}
+	canonical, err := rlp.EncodeToBytes(&encoded)
+	if err != nil || !bytes.Equal(canonical, data[1:]) {
+		return nil, fmt.Errorf("invalid transaction encoding: not in canonical form")
+	}
+
+	tx := &SafeTransaction{
+		Safe:           encoded.Safe.Hex(),
+		SafeVersion:    encoded.SafeVersion,
+		To:             encoded.To.Hex(),
+		Value:          encoded.Value,
+		Data:           hexutil.Encode(encoded.Data),
+		Operation:      int(encoded.Operation),
+		GasToken:       encoded.GasToken.Hex(),
+		RefundReceiver: encoded.RefundReceiver.Hex(),
+	}
+	// Integers are read into ints, which must not wrap negative on a crafted encoding
+	for _, field := range []struct {
+		name  string
+		value uint64
+		into  *int
+	}{
+		{"chain", encoded.Chain, &tx.Chain},
+		{"safe_tx_gas", encoded.SafeTxGas, &tx.SafeTxGas},
+		{"base_gas", encoded.BaseGas, &tx.BaseGas},
+		{"gas_price", encoded.GasPrice, &tx.GasPrice},
+		{"nonce", encoded.Nonce, &tx.Nonce},
+	} {
+		if field.value > math.MaxInt {
+			return nil, fmt.Errorf("invalid transaction encoding: %s %d is too large", field.name, field.value)
+		}
+		*field.into = int(field.value)
+	}
+	if nested := encoded.Nested; nested != nil {
+		if nested.Nonce > math.MaxInt {
+			return nil, fmt.Errorf("invalid transaction encoding: nested nonce %d is too large", nested.Nonce)
+		}
+		tx.Nested = &Nested{
+			Safe:        nested.Safe.Hex(),
+			SafeVersion: nested.SafeVersion,
+			Nonce:       int(nested.Nonce),
+			Data:        hexutil.Encode(nested.Data),
+			Operation:   int(nested.Operation),
+			To:          nested.To.Hex(),
+		}
+	}
+	return tx, nil
+}
+
+// DecodeTransactionPayload decodes a transaction that a link or QR code carries in either
+// encoding: JSON, as transaction files and older links hold, or the binary encoding, as raw bytes
+// or as base64 text, which is how the QR scanner page passes it on
+func DecodeTransactionPayload(data []byte) (*SafeTransaction, error) {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) > 0 && trimmed[0] == '{' {
+		tx, err := decodeTransactionJSON(trimmed, nil)
+		if err != nil {
+			return nil, withKind(ErrDecodeFailure, fmt.Errorf("failed to parse transaction JSON: %w", err))
+		}
+		return &tx, nil
+	}
+	if len(data) > 0 && data[0] == TransactionEncodingVersion {
+		return DecodeTransaction(data)
+	}
+	decoded, err := decodeBase64Payload(string(trimmed))
+	if err != nil || len(decoded) == 0 {
+		return nil, withKind(ErrDecodeFailure, fmt.Errorf("transaction is neither JSON nor in the binary encoding"))
+	}
+	return DecodeTransaction(decoded)
+}
+
+// encodingUint checks that an integer field fits the binary encoding
+func encodingUint(field string, value int) (uint64, error) {
+	if value < 0 {
+		return 0, fmt.Errorf("cannot encode a negative %s: %d", field, value)
+	}
+	return uint64(value), nil
+}
+
+// encodingOperation checks that an operation is a call or a delegatecall
+func encodingOperation(field string, operation int) (uint8, error) {
+	if operation != 0 && operation != 1 {
+		return 0, fmt.Errorf("cannot encode %s %d: must be 0 (call) or 1 (delegatecall)", field, operation)
+	}
+	return uint8(operation), nil
+}
+
+// encodingAddress parses an address field. Fields that may be empty, like the gas token, encode
+// an empty value as the zero address, which is what the Safe hashes for them. An EIP-3770 prefix
+// is dropped once it is checked to name the transaction's chain.
+func encodingAddress(field, address string, chain uint64, optional bool) (common.Address, error) {
+	if optional && address == "" {
+		return common.Address{}, nil
+	}
+	if idx := strings.Index(address, ":"); idx != -1 {
+		prefixChain, err := ChainIDFromPrefix(address)
+		if err != nil {
+			return common.Address{}, fmt.Errorf("cannot encode %s: %w", field, err)
+		}
+		if prefixChain != chain {
+			return common.Address{}, fmt.Errorf("cannot encode %s: prefix %q is for chain %d, not chain %d", field, address[:idx], prefixChain, chain)
+		}
+		address = address[idx+1:]
+	}
+	if !common.IsHexAddress(address) {
+		return common.Address{}, fmt.Errorf("cannot encode %s: invalid address %q", field, address)
+	}
+	return common.HexToAddress(address), nil
+}
+
+// encodingData parses a hex calldata field
+func encodingData(field, data string) ([]byte, error) {
+	if data == "" || data == "0x" {
+		return []byte{}, nil
+	}
+	if !strings.HasPrefix(data, "0x") {
+		data = "0x" + data
+	}
+	decoded, err := hexutil.Decode(data)
+	if err != nil {
+		return nil, fmt.Errorf("cannot encode %s: %w", field, err)
+	}
+	return decoded, nil
+}
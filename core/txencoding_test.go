@@ -0,0 +1,401 @@
+package core
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"math"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// encodingFixtures are transactions that exercise every field of the binary encoding: zero and
+// maximal values, calldata on both sides of RLP's long length prefix, and nested approvals
+func encodingFixtures() map[string]SafeTransaction {
+	maxUint256 := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1))
+	transfer := "0xa9059cbb000000000000000000000000000000000000000000000000000000000000dead0000000000000000000000000000000000000000000000000000000000000001"
+	zero := "0x0000000000000000000000000000000000000000"
+
+	base := SafeTransaction{
+		Safe:           fixtureGrantsSafe,
+		SafeVersion:    "1.3.0",
+		Chain:          OPMainnetChainID,
+		To:             OPTokenAddress,
+		Value:          big.NewInt(0),
+		Data:           transfer,
+		GasToken:       zero,
+		RefundReceiver: zero,
+		Nonce:          7,
+	}
+
+	fixtures := map[string]SafeTransaction{"token transfer": base}
+
+	empty := base
+	empty.Data = "0x"
+	empty.Value = big.NewInt(1)
+	fixtures["empty calldata"] = empty
+
+	single := base
+	single.Data = "0x01"
+	fixtures["one byte below 0x80"] = single
+
+	high := base
+	high.Data = "0x80"
+	fixtures["one byte from 0x80"] = high
+
+	short := base
+	short.Data = "0x" + strings.Repeat("ab", 55)
+	fixtures["55 bytes of calldata"] = short
+
+	long := base
+	long.Data = "0x" + strings.Repeat("cd", 56)
+	fixtures["56 bytes of calldata"] = long
+
+	large := base
+	large.Data = "0x" + strings.Repeat("ef", 70000)
+	fixtures["calldata over 64 KiB"] = large
+
+	max := base
+	max.Value = maxUint256
+	max.Chain = 1<<31 - 1
+	max.SafeTxGas = 1<<31 - 1
+	max.BaseGas = 1 << 20
+	max.GasPrice = 127
+	max.Nonce = 128
+	max.GasToken = OPTokenAddress
+	max.RefundReceiver = fixtureGrantsSafe
+	fixtures["maximal values"] = max
+
+	delegate := base
+	delegate.To = SafeMultisendCallOnly141
+	delegate.Operation = 1
+	delegate.SafeVersion = "1.4.1+L2"
+	fixtures["delegatecall"] = delegate
+
+	unset := base
+	unset.SafeVersion = ""
+	unset.GasToken = ""
+	unset.RefundReceiver = ""
+	unset.Nonce = 0
+	fixtures["unset optional fields"] = unset
+
+	nested := base
+	nested.Nested = &Nested{
+		Safe:        OPTokenAddress,
+		SafeVersion: "1.4.1",
+		Nonce:       3,
+		Data:        "0xd4d9bdcd" + strings.Repeat("11", 32),
+		To:          fixtureGrantsSafe,
+	}
+	fixtures["nested approval"] = nested
+
+	nestedDelegate := nested
+	nestedDelegate.Nested = &Nested{Safe: OPTokenAddress, Data: "0x", Operation: 1, To: fixtureGrantsSafe}
+	fixtures["nested delegatecall"] = nestedDelegate
+
+	return fixtures
+}
+
+// normalizedTransaction is how a transaction reads after decoding: checksummed addresses, the
+// zero address for unset ones, and lowercase calldata
+func normalizedTransaction(tx SafeTransaction) SafeTransaction {
+	address := func(a string) string { return common.HexToAddress(a).Hex() }
+	data := func(d string) string {
+		if d == "" {
+			return "0x"
+		}
+		return strings.ToLower(d)
+	}
+	tx.Safe, tx.To = address(tx.Safe), address(tx.To)
+	tx.GasToken, tx.RefundReceiver = address(tx.GasToken), address(tx.RefundReceiver)
+	tx.Data = data(tx.Data)
+	if tx.Value == nil {
+		tx.Value = new(big.Int)
+	}
+	if tx.Nested != nil {
+		nested := *tx.Nested
+		nested.Safe, nested.To, nested.Data = address(nested.Safe), address(nested.To), data(nested.Data)
+		tx.Nested = &nested
+	}
+	return tx
+}
+
+func TestTransactionEncodingRoundTrips(t *testing.T) {
+	for name, tx := range encodingFixtures() {
+		t.Run(name, func(t *testing.T) {
+			encoded, err := EncodeTransaction(tx)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if encoded[0] != TransactionEncodingVersion {
+				t.Fatalf("encoding starts with %#x, want the version byte", encoded[0])
+			}
+			decoded, err := DecodeTransaction(encoded)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			want, _ := json.Marshal(normalizedTransaction(tx))
+			got, _ := json.Marshal(decoded)
+			if string(got) != string(want) {
+				t.Errorf("decoded\n%s\nwant\n%s", got, want)
+			}
+
+			// The encoding is canonical: the decoded transaction encodes to the same bytes
+			again, err := EncodeTransaction(*decoded)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if hex.EncodeToString(again) != hex.EncodeToString(encoded) {
+				t.Errorf("re-encoding changed the bytes")
+			}
+
+			// The decoded transaction is the one that is signed
+			if tx.SafeVersion != "" {
+				wantHash, err := CalculateApproveHash(tx)
+				if err != nil {
+					t.Fatal(err)
+				}
+				gotHash, err := CalculateApproveHash(*decoded)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if gotHash != wantHash {
+					t.Errorf("Safe tx hash %s, want %s", gotHash, wantHash)
+				}
+			}
+
+			// Any prefix of the encoding is refused rather than read as a different transaction
+			for i := 0; i < len(encoded) && i < 512; i++ {
+				if _, err := DecodeTransaction(encoded[:i]); err == nil {
+					t.Fatalf("decoded a transaction from the first %d of %d bytes", i, len(encoded))
+				}
+			}
+		})
+	}
+}
+
+func TestTransactionEncodingIsCompact(t *testing.T) {
+	tx := encodingFixtures()["token transfer"]
+	encoded, err := EncodeTransaction(tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := json.Marshal(tx)
+	if len(encoded)*2 > len(data) {
+		t.Errorf("binary encoding of %d bytes is not half the %d bytes of JSON", len(encoded), len(data))
+	}
+}
+
+func TestTransactionEncodingFormat(t *testing.T) {
+	// Pins version 1 of the encoding; the QR site's encoder must produce the same bytes
+	tx := SafeTransaction{
+		Safe:        "0x0000000000000000000000000000000000000001",
+		SafeVersion: "1.3.0",
+		Chain:       10,
+		To:          "0x0000000000000000000000000000000000000002",
+		Value:       big.NewInt(1000),
+		Data:        "0xabcd",
+		Nonce:       5,
+	}
+	encoded, err := EncodeTransaction(tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "01" + "f8" + "66" + "0a" +
+		"94" + "0000000000000000000000000000000000000001" +
+		"85" + hex.EncodeToString([]byte("1.3.0")) +
+		"94" + "0000000000000000000000000000000000000002" +
+		"82" + "03e8" +
+		"82" + "abcd" +
+		"80" + "80" + "80" + "80" +
+		"94" + strings.Repeat("00", 20) +
+		"94" + strings.Repeat("00", 20) +
+		"05"
+	if got := hex.EncodeToString(encoded); got != want {
+		t.Errorf("encoding\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestDecodeTransactionRefusesNonCanonical(t *testing.T) {
+	encoded, err := EncodeTransaction(encodingFixtures()["token transfer"])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The nonce 7 is the last item of the list; write it with a leading zero instead
+	leadingZero := append([]byte{}, encoded[:len(encoded)-1]...)
+	leadingZero = append(leadingZero, 0x82, 0x00, 0x07)
+	leadingZero[2] += 2
+
+	operation := append([]byte{}, encoded...)
+	operationAt := len(encoded) - 1 - 21 - 21 - 3 - 1
+	if operation[operationAt] != 0x80 {
+		t.Fatalf("operation byte not where expected: %#x", operation[operationAt])
+	}
+	operation[operationAt] = 0x02
+
+	for name, data := range map[string][]byte{
+		"empty":               nil,
+		"unknown version":     append([]byte{2}, encoded[1:]...),
+		"json version byte":   append([]byte{'{'}, encoded[1:]...),
+		"trailing bytes":      append(append([]byte{}, encoded...), 0x80),
+		"integer with zeroes": leadingZero,
+		"operation 2":         operation,
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := DecodeTransaction(data); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestEncodeTransactionRefusesInvalidFields(t *testing.T) {
+	base := encodingFixtures()["token transfer"]
+	for name, change := range map[string]func(*SafeTransaction){
+		"invalid to":          func(tx *SafeTransaction) { tx.To = "0x1234" },
+		"missing safe":        func(tx *SafeTransaction) { tx.Safe = "" },
+		"negative value":      func(tx *SafeTransaction) { tx.Value = big.NewInt(-1) },
+		"negative nonce":      func(tx *SafeTransaction) { tx.Nonce = -1 },
+		"odd length data":     func(tx *SafeTransaction) { tx.Data = "0xabc" },
+		"non-hex data":        func(tx *SafeTransaction) { tx.Data = "0xzz" },
+		"operation 2":         func(tx *SafeTransaction) { tx.Operation = 2 },
+		"invalid gas token":   func(tx *SafeTransaction) { tx.GasToken = "eth" },
+		"invalid nested safe": func(tx *SafeTransaction) { tx.Nested = &Nested{Safe: "0x", To: fixtureGrantsSafe} },
+		"other chain prefix":  func(tx *SafeTransaction) { tx.Safe = "eth:" + fixtureGrantsSafe },
+		"unknown prefix":      func(tx *SafeTransaction) { tx.To = "arb1:" + OPTokenAddress },
+		"prefixed gas token":  func(tx *SafeTransaction) { tx.GasToken = "base:" + OPTokenAddress },
+	} {
+		t.Run(name, func(t *testing.T) {
+			tx := base
+			change(&tx)
+			if _, err := EncodeTransaction(tx); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestEncodeTransactionChainPrefixes(t *testing.T) {
+	plain := encodingFixtures()["nested approval"]
+	want, err := EncodeTransaction(plain)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Prefixed addresses, as shared from the Safe UI, encode like the plain addresses
+	prefixed := plain
+	prefixed.Safe = "oeth:" + plain.Safe
+	prefixed.To = "oeth:" + plain.To
+	nested := *plain.Nested
+	nested.Safe = "oeth:" + nested.Safe
+	prefixed.Nested = &nested
+	got, err := EncodeTransaction(prefixed)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("prefixed encoding %x, want %x", got, want)
+	}
+
+	// A prefix may name the chain the chain field leaves out
+	prefixed.Chain = 0
+	if got, err := EncodeTransaction(prefixed); err != nil || !bytes.Equal(got, want) {
+		t.Errorf("encoding with the chain only in the prefixes = %x, %v; want %x", got, err, want)
+	}
+}
+
+func TestDecodeTransactionRefusesIntegerOverflow(t *testing.T) {
+	base := encodedTransaction{
+		Chain: 10,
+		Safe:  common.HexToAddress(fixtureGrantsSafe),
+		To:    common.HexToAddress(OPTokenAddress),
+		Value: new(big.Int),
+		Data:  []byte{},
+	}
+	for name, change := range map[string]func(*encodedTransaction){
+		"chain":        func(e *encodedTransaction) { e.Chain = math.MaxUint64 },
+		"nonce":        func(e *encodedTransaction) { e.Nonce = math.MaxInt64 + 1 },
+		"safe_tx_gas":  func(e *encodedTransaction) { e.SafeTxGas = math.MaxUint64 },
+		"base_gas":     func(e *encodedTransaction) { e.BaseGas = math.MaxUint64 },
+		"gas_price":    func(e *encodedTransaction) { e.GasPrice = math.MaxUint64 },
+		"nested nonce": func(e *encodedTransaction) { e.Nested = &encodedNested{Nonce: math.MaxUint64, Data: []byte{}} },
+	} {
+		t.Run(name, func(t *testing.T) {
+			encoded := base
+			change(&encoded)
+			data, err := rlp.EncodeToBytes(&encoded)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tx, err := DecodeTransaction(append([]byte{TransactionEncodingVersion}, data...)); err == nil {
+				t.Errorf("expected an error, decoded %+v", tx)
+			}
+		})
+	}
+}
+
+func TestDecodeTransactionPayload(t *testing.T) {
+	tx := encodingFixtures()["nested approval"]
+	encoded, err := EncodeTransaction(tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := json.Marshal(tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, payload := range map[string][]byte{
+		"json":          data,
+		"indented json": append([]byte("\n  "), data...),
+		"binary":        encoded,
+		"base64 binary": []byte(base64.StdEncoding.EncodeToString(encoded)),
+	} {
+		t.Run(name, func(t *testing.T) {
+			decoded, err := DecodeTransactionPayload(payload)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if decoded.Nonce != tx.Nonce || decoded.Nested == nil || decoded.Nested.Nonce != tx.Nested.Nonce {
+				t.Errorf("decoded %+v", decoded)
+			}
+		})
+	}
+
+	for _, payload := range []string{"", "hello", "{", "AgE="} {
+		if _, err := DecodeTransactionPayload([]byte(payload)); err == nil {
+			t.Errorf("expected %q to be refused", payload)
+		}
+	}
+}
+
+func FuzzDecodeTransaction(f *testing.F) {
+	for _, tx := range encodingFixtures() {
+		if encoded, err := EncodeTransaction(tx); err == nil && len(encoded) < 1024 {
+			f.Add(encoded)
+		}
+	}
+	f.Add([]byte{TransactionEncodingVersion})
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		tx, err := DecodeTransaction(data)
+		if err != nil {
+			return
+		}
+		// Whatever decodes is canonical, so it encodes back to the same bytes
+		encoded, err := EncodeTransaction(*tx)
+		if err != nil {
+			t.Fatalf("decoded transaction does not encode: %v", err)
+		}
+		if hex.EncodeToString(encoded) != hex.EncodeToString(data) {
+			t.Fatalf("decoded %x but it encodes to %x", data, encoded)
+		}
+	})
+}
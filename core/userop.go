@@ -0,0 +1,554 @@
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+)
+
+// Safe4337Deployment is a Safe4337Module release and the EntryPoint it works with. Safes operated
+// through the module are signed over a SafeOp instead of a SafeTx, so the classic Safe tx hash
+// never appears.
+type Safe4337Deployment struct {
+	Version    string
+	Module     string
+	EntryPoint string
+}
+
+// Safe4337Deployments are the canonical Safe4337Module deployments
+var Safe4337Deployments = []Safe4337Deployment{
+	{Version: "0.2.0", Module: "0xa581c4A4DB7175302464fF3C06380BC3270b4037", EntryPoint: "0x5FF137D4b0FDCD49DcA30c7CF57E578a026d2789"},
+	{Version: "0.3.0", Module: "0x75cf11467937ce3F2f357CE24ffc3DBF8fD5c226", EntryPoint: "0x0000000071727De22E5E9d8BAf0edAc6f37da032"},
+}
+
+// safeOpTypes are the SafeOp EIP-712 struct types by module version. Version 0.2.0 signs the
+// EntryPoint v0.6 field layout and 0.3.0 the packed v0.7 gas fields.
+var safeOpTypes = map[string][]apitypes.Type{
+	"0.2.0": {
+		{Name: "safe", Type: "address"},
+		{Name: "nonce", Type: "uint256"},
+		{Name: "initCode", Type: "bytes"},
+		{Name: "callData", Type: "bytes"},
+		{Name: "callGasLimit", Type: "uint256"},
+		{Name: "verificationGasLimit", Type: "uint256"},
+		{Name: "preVerificationGas", Type: "uint256"},
+		{Name: "maxFeePerGas", Type: "uint256"},
+		{Name: "maxPriorityFeePerGas", Type: "uint256"},
+		{Name: "paymasterAndData", Type: "bytes"},
+		{Name: "validAfter", Type: "uint48"},
+		{Name: "validUntil", Type: "uint48"},
+		{Name: "entryPoint", Type: "address"},
+	},
+	"0.3.0": {
+		{Name: "safe", Type: "address"},
+		{Name: "nonce", Type: "uint256"},
+		{Name: "initCode", Type: "bytes"},
+		{Name: "callData", Type: "bytes"},
+		{Name: "verificationGasLimit", Type: "uint128"},
+		{Name: "callGasLimit", Type: "uint128"},
+		{Name: "preVerificationGas", Type: "uint256"},
+		{Name: "maxPriorityFeePerGas", Type: "uint128"},
+		{Name: "maxFeePerGas", Type: "uint128"},
+		{Name: "paymasterAndData", Type: "bytes"},
+		{Name: "validAfter", Type: "uint48"},
+		{Name: "validUntil", Type: "uint48"},
+		{Name: "entryPoint", Type: "address"},
+	},
+}
+
+// Safe4337Module entry points that UserOperation callData must call
+var (
+	executeUserOpSelector                = crypto.Keccak256([]byte("executeUserOp(address,uint256,bytes,uint8)"))[:4]
+	executeUserOpWithErrorStringSelector = crypto.Keccak256([]byte("executeUserOpWithErrorString(address,uint256,bytes,uint8)"))[:4]
+)
+
+// Quantity is an unsigned integer in a UserOperation, given as a hex or decimal string or a JSON
+// number and written as hex, as bundler RPCs do
+type Quantity struct {
+	*big.Int
+}
+
+// UnmarshalJSON parses a quantity
+func (q *Quantity) UnmarshalJSON(data []byte) error {
+	var value interface{}
+	decoder := json.NewDecoder(strings.NewReader(string(data)))
+	decoder.UseNumber()
+	if err := decoder.Decode(&value); err != nil {
+		return err
+	}
+	if value == nil {
+		q.Int = nil
+		return nil
+	}
+	if number, ok := value.(json.Number); ok {
+		value = number.String()
+	}
+	n, err := typedInteger(value)
+	if err != nil {
+		return err
+	}
+	if n.Sign() < 0 {
+		return fmt.Errorf("negative quantity %s", n)
+	}
+	q.Int = n
+	return nil
+}
+
+// MarshalJSON writes a quantity as hex
+func (q Quantity) MarshalJSON() ([]byte, error) {
+	if q.Int == nil {
+		return []byte("null"), nil
+	}
+	return json.Marshal("0x" + q.Text(16))
+}
+
+// value returns the quantity, treating a missing quantity as zero
+func (q Quantity) value() *big.Int {
+	if q.Int == nil {
+		return new(big.Int)
+	}
+	return q.Int
+}
+
+// UserOperation is an ERC-4337 UserOperation in bundler RPC form. EntryPoint v0.6 operations use
+// InitCode and PaymasterAndData; v0.7 operations split them into Factory/FactoryData and the
+// Paymaster fields.
+type UserOperation struct {
+	Sender                        string   `json:"sender"`
+	Nonce                         Quantity `json:"nonce"`
+	InitCode                      string   `json:"initCode,omitempty"`
+	Factory                       string   `json:"factory,omitempty"`
+	FactoryData                   string   `json:"factoryData,omitempty"`
+	CallData                      string   `json:"callData"`
+	CallGasLimit                  Quantity `json:"callGasLimit"`
+	VerificationGasLimit          Quantity `json:"verificationGasLimit"`
+	PreVerificationGas            Quantity `json:"preVerificationGas"`
+	MaxFeePerGas                  Quantity `json:"maxFeePerGas"`
+	MaxPriorityFeePerGas          Quantity `json:"maxPriorityFeePerGas"`
+	PaymasterAndData              string   `json:"paymasterAndData,omitempty"`
+	Paymaster                     string   `json:"paymaster,omitempty"`
+	PaymasterVerificationGasLimit Quantity `json:"paymasterVerificationGasLimit,omitempty"`
+	PaymasterPostOpGasLimit       Quantity `json:"paymasterPostOpGasLimit,omitempty"`
+	PaymasterData                 string   `json:"paymasterData,omitempty"`
+	Signature                     string   `json:"signature,omitempty"`
+}
+
+// UserOperationRequest is a UserOperation awaiting owner signatures, with the context needed to
+// compute what the owners sign
+type UserOperationRequest struct {
+	Chain      int    `json:"chain"`
+	EntryPoint string `json:"entryPoint"`
+
+	// Module is the Safe4337Module the Safe uses; it defaults to the canonical module for the
+	// EntryPoint
+	Module string `json:"module,omitempty"`
+
+	// ValidAfter and ValidUntil bound when the operation can be included; 0 means unbounded
+	ValidAfter uint64 `json:"validAfter"`
+	ValidUntil uint64 `json:"validUntil"`
+
+	UserOperation UserOperation `json:"userOperation"`
+}
+
+// UserOperationResult is a verified UserOperation
+type UserOperationResult struct {
+	Request       UserOperationRequest `json:"request"`
+	Chain         int                  `json:"chain"`
+	Safe          string               `json:"safe"`
+	EntryPoint    string               `json:"entryPoint"`
+	Module        string               `json:"module"`
+	ModuleVersion string               `json:"moduleVersion"`
+
+	// NonceKey and NonceSequence split the 4337 nonce into its 192-bit key and 64-bit sequence
+	NonceKey      *big.Int `json:"nonceKey"`
+	NonceSequence *big.Int `json:"nonceSequence"`
+
+	// DomainHash, MessageHash and SafeOpHash are the EIP-712 hashes the owners sign; UserOpHash
+	// is the EntryPoint's identifier for the operation, which bundlers and explorers show
+	DomainHash  string `json:"domainHash"`
+	MessageHash string `json:"messageHash"`
+	SafeOpHash  string `json:"safeOpHash"`
+	UserOpHash  string `json:"userOpHash"`
+
+	// To, Value, Operation and Call are the Safe call the operation executes
+	To        string   `json:"to"`
+	Value     *big.Int `json:"value"`
+	Operation int      `json:"operation"`
+	Call      CallData `json:"call"`
+
+	Factory   string `json:"factory,omitempty"`
+	Paymaster string `json:"paymaster,omitempty"`
+
+	// MaxGasCost is the most the operation can cost in wei, paid by the Safe unless a paymaster
+	// sponsors it
+	MaxGasCost *big.Int `json:"maxGasCost"`
+
+	Warnings []Warning `json:"warnings,omitempty"`
+}
+
+// VerifyUserOperation computes the SafeOp hash owners sign for a UserOperation executed through
+// Safe4337Module, decodes the call it makes, and flags risky fields
+func VerifyUserOperation(request UserOperationRequest, options VerifyOptions) (*UserOperationResult, error) {
+	op := request.UserOperation
+	chainID := uint64(request.Chain)
+	if chainID == 0 {
+		return nil, fmt.Errorf("chain is required")
+	}
+	for _, field := range []struct{ name, value string }{
+		{"sender", op.Sender}, {"entryPoint", request.EntryPoint}, {"module", request.Module},
+		{"factory", op.Factory}, {"paymaster", op.Paymaster},
+	} {
+		if field.value == "" && field.name != "sender" && field.name != "entryPoint" {
+			continue
+		}
+		if !common.IsHexAddress(field.value) {
+			return nil, fmt.Errorf("invalid %s address %q: addresses must be given in full (40 hex digits)", field.name, field.value)
+		}
+	}
+	for _, field := range []struct {
+		name  string
+		value *big.Int
+	}{
+		{"callGasLimit", op.CallGasLimit.value()}, {"verificationGasLimit", op.VerificationGasLimit.value()},
+		{"maxFeePerGas", op.MaxFeePerGas.value()}, {"maxPriorityFeePerGas", op.MaxPriorityFeePerGas.value()},
+		{"paymasterVerificationGasLimit", op.PaymasterVerificationGasLimit.value()}, {"paymasterPostOpGasLimit", op.PaymasterPostOpGasLimit.value()},
+	} {
+		if field.value.BitLen() > 128 {
+			return nil, fmt.Errorf("%s %s does not fit in 128 bits", field.name, field.value)
+		}
+	}
+	if request.ValidAfter >= 1<<48 || request.ValidUntil >= 1<<48 {
+		return nil, fmt.Errorf("validAfter and validUntil must fit in 48 bits")
+	}
+
+	var deployment *Safe4337Deployment
+	for i := range Safe4337Deployments {
+		if strings.EqualFold(Safe4337Deployments[i].EntryPoint, request.EntryPoint) {
+			deployment = &Safe4337Deployments[i]
+		}
+	}
+	if deployment == nil {
+		return nil, fmt.Errorf("unsupported EntryPoint %s: Safe4337Module supports EntryPoint v0.6 (%s) and v0.7 (%s)",
+			request.EntryPoint, Safe4337Deployments[0].EntryPoint, Safe4337Deployments[1].EntryPoint)
+	}
+	v07 := deployment.Version == "0.3.0"
+	if !v07 && (op.Factory != "" || op.Paymaster != "") {
+		return nil, fmt.Errorf("EntryPoint v0.6 operations use initCode and paymasterAndData, not factory or paymaster")
+	}
+	if v07 && (op.InitCode != "" && op.InitCode != "0x" || op.PaymasterAndData != "" && op.PaymasterAndData != "0x") {
+		return nil, fmt.Errorf("EntryPoint v0.7 operations use factory and paymaster fields, not initCode or paymasterAndData")
+	}
+
+	var warnings []Warning
+	module := deployment.Module
+	if request.Module != "" && !strings.EqualFold(request.Module, module) {
+		module = request.Module
+		warnings = append(warnings, newWarning(warnNonCanonicalModule,
+			"Module %s is not the canonical Safe4337Module %s for this EntryPoint; hashes assume it is a v%s module.",
+			ChecksumAddress(module), deployment.Module, deployment.Version))
+	}
+
+	initCode, err := op.initCode()
+	if err != nil {
+		return nil, err
+	}
+	paymasterAndData, err := op.paymasterAndData()
+	if err != nil {
+		return nil, err
+	}
+	callData, err := userOpBytes("callData", op.CallData)
+	if err != nil {
+		return nil, err
+	}
+
+	typedData := safeOpTypedData(deployment.Version, chainID, module, request, initCode, paymasterAndData, callData)
+	domainHash, err := typedData.HashStruct("EIP712Domain", typedData.Domain.Map())
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash SafeOp domain: %w", err)
+	}
+	messageHash, err := typedData.HashStruct("SafeOp", typedData.Message)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash SafeOp: %w", err)
+	}
+	safeOpHash := crypto.Keccak256Hash([]byte{0x19, 0x01}, domainHash, messageHash)
+
+	result := &UserOperationResult{
+		Request:       request,
+		Chain:         request.Chain,
+		Safe:          ChecksumAddress(op.Sender),
+		EntryPoint:    ChecksumAddress(request.EntryPoint),
+		Module:        ChecksumAddress(module),
+		ModuleVersion: deployment.Version,
+		NonceKey:      new(big.Int).Rsh(op.Nonce.value(), 64),
+		NonceSequence: new(big.Int).And(op.Nonce.value(), new(big.Int).SetUint64(^uint64(0))),
+		DomainHash:    common.BytesToHash(domainHash).Hex(),
+		MessageHash:   common.BytesToHash(messageHash).Hex(),
+		SafeOpHash:    safeOpHash.Hex(),
+		UserOpHash:    userOpHash(op, v07, request.EntryPoint, chainID, initCode, paymasterAndData, callData).Hex(),
+	}
+	if len(initCode) >= 20 {
+		result.Factory = common.BytesToAddress(initCode[:20]).Hex()
+	}
+	if len(paymasterAndData) >= 20 {
+		result.Paymaster = common.BytesToAddress(paymasterAndData[:20]).Hex()
+	}
+
+	// The operation pays for every gas limit at maxFeePerGas; v0.6 charges the verification limit
+	// up to three times when a paymaster is involved
+	gas := new(big.Int).Add(op.CallGasLimit.value(), op.PreVerificationGas.value())
+	verification := new(big.Int).Set(op.VerificationGasLimit.value())
+	if v07 {
+		gas.Add(gas, op.PaymasterVerificationGasLimit.value())
+		gas.Add(gas, op.PaymasterPostOpGasLimit.value())
+	} else if result.Paymaster != "" {
+		verification.Mul(verification, big.NewInt(3))
+	}
+	gas.Add(gas, verification)
+	result.MaxGasCost = gas.Mul(gas, op.MaxFeePerGas.value())
+
+	callWarnings, err := result.decodeCall(callData, chainID, options)
+	if err != nil {
+		return nil, err
+	}
+	warnings = append(warnings, callWarnings...)
+	warnings = append(warnings, result.checkFields(op)...)
+
+	// Reuse the transaction checks by presenting the executed call as a Safe transaction
+	asTransaction := &VerificationResult{
+		Transaction: SafeTransaction{Safe: result.Safe, To: result.To, Chain: request.Chain},
+		Call:        result.Call,
+	}
+	warnings = append(warnings, checkAddressSimilarity(asTransaction)...)
+	warnings = append(warnings, checkDenylist(asTransaction, options.Denylist)...)
+	for _, address := range []string{result.Factory, result.Paymaster} {
+		if address == "" || options.Denylist == nil {
+			continue
+		}
+		if entry, ok := options.Denylist.Lookup(address); ok {
+			reason := entry.Reason
+			if reason == "" {
+				reason = "no reason given"
+			}
+			warnings = append(warnings, newWarning(warnDenylisted,
+				"Address %s is on the denylist (%s; from %s). DO NOT SIGN.", entry.Address, reason, entry.Source))
+		}
+	}
+	result.Warnings = warnings
+
+	return result, nil
+}
+
+// decodeCall decodes the executeUserOp call the Safe makes through the module
+func (r *UserOperationResult) decodeCall(callData []byte, chainID uint64, options VerifyOptions) ([]Warning, error) {
+	if len(callData) < 4 || (!bytes.Equal(callData[:4], executeUserOpSelector) && !bytes.Equal(callData[:4], executeUserOpWithErrorStringSelector)) {
+		r.Call = CallData{Target: r.Safe, FunctionName: "unknown", RawData: hexBytes(callData)}
+		return []Warning{newWarning(warnNotExecuteUserOp,
+			"UserOperation callData does not call executeUserOp or executeUserOpWithErrorString; Safe4337Module will reject it. DO NOT SIGN.")}, nil
+	}
+
+	arguments := abi.Arguments{
+		{Type: mustABIType("address")},
+		{Type: mustABIType("uint256")},
+		{Type: mustABIType("bytes")},
+		{Type: mustABIType("uint8")},
+	}
+	values, err := arguments.Unpack(callData[4:])
+	if err != nil {
+		return nil, withKind(ErrDecodeFailure, fmt.Errorf("failed to decode UserOperation callData: %w", err))
+	}
+	r.To = values[0].(common.Address).Hex()
+	r.Value = values[1].(*big.Int)
+	r.Operation = int(values[3].(uint8))
+
+	call, err := ParseTransactionData(r.To, hexBytes(values[2].([]byte)), chainID, options)
+	if err != nil {
+		return nil, withKind(ErrDecodeFailure, fmt.Errorf("failed to parse UserOperation call: %w", err))
+	}
+	call.IsDelegateCall = r.Operation == 1
+	PredictDeployments(call, r.Safe, call.IsDelegateCall)
+	PredictDeposits(call, r.Safe, r.Value, call.IsDelegateCall)
+	LabelAddresses(call, chainID, options.AddressBook)
+	r.Call = *call
+
+	var warnings []Warning
+	switch r.Operation {
+	case 0:
+	case 1:
+		warnings = append(warnings, newWarning(warnUserOpDelegatecall,
+			"The Safe DELEGATECALLs %s, which runs that contract's code with full control of the Safe.", r.To))
+	default:
+		warnings = append(warnings, newWarning(warnUnknownOperation, "Unknown operation type %d. DO NOT SIGN.", r.Operation))
+	}
+	return warnings, nil
+}
+
+// checkFields flags UserOperation fields that change who pays, when the operation can run, or
+// what gets deployed
+func (r *UserOperationResult) checkFields(op UserOperation) []Warning {
+	var warnings []Warning
+	if r.Factory != "" {
+		warnings = append(warnings, newWarning(warnFactoryDeploy,
+			"The operation deploys the Safe through factory %s. Check the factory and its setup data create the Safe with the intended owners.", r.Factory))
+	}
+	if r.Paymaster != "" {
+		warnings = append(warnings, newWarning(warnPaymaster,
+			"Gas is handled by paymaster %s. Token paymasters charge the Safe in ERC-20 tokens; check the Safe has not approved it for more than expected.", r.Paymaster))
+	} else {
+		warnings = append(warnings, newWarning(warnNoPaymaster,
+			"No paymaster: the Safe pays up to %s ETH in gas.", ParseDecimals(r.MaxGasCost, 18)))
+	}
+	if op.MaxPriorityFeePerGas.value().Cmp(op.MaxFeePerGas.value()) > 0 {
+		warnings = append(warnings, newWarning(warnPriorityFee,
+			"maxPriorityFeePerGas (%s) is above maxFeePerGas (%s); the operation cannot be included as is.", op.MaxPriorityFeePerGas.value(), op.MaxFeePerGas.value()))
+	}
+	request := r.Request
+	if request.ValidUntil == 0 {
+		warnings = append(warnings, newWarning(warnNeverExpires,
+			"The operation never expires (validUntil is 0); once signed it can be submitted until nonce %s is used.", r.NonceSequence))
+	} else if request.ValidAfter > request.ValidUntil {
+		warnings = append(warnings, newWarning(warnNeverValid,
+			"validAfter (%d) is later than validUntil (%d); the operation can never be included.", request.ValidAfter, request.ValidUntil))
+	}
+
+	// Safe4337Module prefixes owner signatures with the validity window they signed
+	if signature, err := userOpBytes("signature", op.Signature); err == nil && len(signature) >= 12 {
+		after := new(big.Int).SetBytes(signature[:6]).Uint64()
+		until := new(big.Int).SetBytes(signature[6:12]).Uint64()
+		if after != request.ValidAfter || until != request.ValidUntil {
+			warnings = append(warnings, newWarning(warnValidityMismatch,
+				"The signature carries validAfter %d and validUntil %d but the request says %d and %d; existing signatures are for a different SafeOp.",
+				after, until, request.ValidAfter, request.ValidUntil))
+		}
+	}
+	return warnings
+}
+
+// safeOpTypedData builds the EIP-712 SafeOp payload owners sign
+func safeOpTypedData(version string, chainID uint64, module string, request UserOperationRequest, initCode, paymasterAndData, callData []byte) apitypes.TypedData {
+	op := request.UserOperation
+	message := map[string]interface{}{
+		"safe":                 op.Sender,
+		"nonce":                op.Nonce.value().String(),
+		"initCode":             hexBytes(initCode),
+		"callData":             hexBytes(callData),
+		"callGasLimit":         op.CallGasLimit.value().String(),
+		"verificationGasLimit": op.VerificationGasLimit.value().String(),
+		"preVerificationGas":   op.PreVerificationGas.value().String(),
+		"maxFeePerGas":         op.MaxFeePerGas.value().String(),
+		"maxPriorityFeePerGas": op.MaxPriorityFeePerGas.value().String(),
+		"paymasterAndData":     hexBytes(paymasterAndData),
+		"validAfter":           fmt.Sprint(request.ValidAfter),
+		"validUntil":           fmt.Sprint(request.ValidUntil),
+		"entryPoint":           request.EntryPoint,
+	}
+	return apitypes.TypedData{
+		Types: apitypes.Types{
+			"EIP712Domain": {{Name: "chainId", Type: "uint256"}, {Name: "verifyingContract", Type: "address"}},
+			"SafeOp":       safeOpTypes[version],
+		},
+		PrimaryType: "SafeOp",
+		Domain:      apitypes.TypedDataDomain{ChainId: (*math.HexOrDecimal256)(new(big.Int).SetUint64(chainID)), VerifyingContract: module},
+		Message:     message,
+	}
+}
+
+// userOpHash computes the EntryPoint's hash of a UserOperation
+func userOpHash(op UserOperation, v07 bool, entryPoint string, chainID uint64, initCode, paymasterAndData, callData []byte) common.Hash {
+	word := func(n *big.Int) []byte { return math.U256Bytes(new(big.Int).Set(n)) }
+	packed := [][]byte{
+		common.LeftPadBytes(common.HexToAddress(op.Sender).Bytes(), 32),
+		word(op.Nonce.value()),
+		crypto.Keccak256(initCode),
+		crypto.Keccak256(callData),
+	}
+	if v07 {
+		packed = append(packed,
+			packUint128Pair(op.VerificationGasLimit.value(), op.CallGasLimit.value()),
+			word(op.PreVerificationGas.value()),
+			packUint128Pair(op.MaxPriorityFeePerGas.value(), op.MaxFeePerGas.value()),
+		)
+	} else {
+		packed = append(packed,
+			word(op.CallGasLimit.value()),
+			word(op.VerificationGasLimit.value()),
+			word(op.PreVerificationGas.value()),
+			word(op.MaxFeePerGas.value()),
+			word(op.MaxPriorityFeePerGas.value()),
+		)
+	}
+	packed = append(packed, crypto.Keccak256(paymasterAndData))
+
+	return crypto.Keccak256Hash(
+		crypto.Keccak256(packed...),
+		common.LeftPadBytes(common.HexToAddress(entryPoint).Bytes(), 32),
+		word(new(big.Int).SetUint64(chainID)),
+	)
+}
+
+// initCode returns the factory and its data as one field, as the EntryPoint and SafeOp hash them
+func (op UserOperation) initCode() ([]byte, error) {
+	if op.Factory == "" {
+		return userOpBytes("initCode", op.InitCode)
+	}
+	factoryData, err := userOpBytes("factoryData", op.FactoryData)
+	if err != nil {
+		return nil, err
+	}
+	return append(common.HexToAddress(op.Factory).Bytes(), factoryData...), nil
+}
+
+// paymasterAndData returns the paymaster fields as one field, as the EntryPoint and SafeOp hash them
+func (op UserOperation) paymasterAndData() ([]byte, error) {
+	if op.Paymaster == "" {
+		return userOpBytes("paymasterAndData", op.PaymasterAndData)
+	}
+	paymasterData, err := userOpBytes("paymasterData", op.PaymasterData)
+	if err != nil {
+		return nil, err
+	}
+	packed := common.HexToAddress(op.Paymaster).Bytes()
+	packed = append(packed, packUint128Pair(op.PaymasterVerificationGasLimit.value(), op.PaymasterPostOpGasLimit.value())...)
+	return append(packed, paymasterData...), nil
+}
+
+// packUint128Pair packs two 128-bit values into one 32-byte word, high then low
+func packUint128Pair(high, low *big.Int) []byte {
+	word := make([]byte, 32)
+	high.FillBytes(word[:16])
+	low.FillBytes(word[16:])
+	return word
+}
+
+// userOpBytes decodes an optional hex bytes field
+func userOpBytes(name, value string) ([]byte, error) {
+	if value == "" || value == "0x" {
+		return []byte{}, nil
+	}
+	raw, err := typedBytes(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", name, err)
+	}
+	return raw, nil
+}
+
+// hexBytes encodes bytes as 0x-prefixed hex
+func hexBytes(data []byte) string {
+	return "0x" + common.Bytes2Hex(data)
+}
+
+// mustABIType parses an elementary ABI type
+func mustABIType(name string) abi.Type {
+	typ, err := abi.NewType(name, "", nil)
+	if err != nil {
+		panic(err)
+	}
+	return typ
+}
@@ -0,0 +1,297 @@
+package core
+
+import (
+	"encoding/json"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+const (
+	userOpSafe      = "0x9e2C8F3c8C4D1A4F8b5a6e7d0c1B2a3F4e5D6c7B"
+	userOpRecipient = "0x1111111111111111111111111111111111111111"
+)
+
+// executeUserOpCallData encodes executeUserOp(to, value, data, operation)
+func executeUserOpCallData(t *testing.T, to string, value int64, data []byte, operation uint8) string {
+	t.Helper()
+	arguments := abi.Arguments{{Type: mustABIType("address")}, {Type: mustABIType("uint256")}, {Type: mustABIType("bytes")}, {Type: mustABIType("uint8")}}
+	packed, err := arguments.Pack(common.HexToAddress(to), big.NewInt(value), data, operation)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return hexBytes(append(append([]byte{}, executeUserOpSelector...), packed...))
+}
+
+func newUserOpRequest(t *testing.T) UserOperationRequest {
+	return UserOperationRequest{
+		Chain:      10,
+		EntryPoint: Safe4337Deployments[1].EntryPoint,
+		ValidUntil: 1893456000,
+		UserOperation: UserOperation{
+			Sender:               userOpSafe,
+			Nonce:                Quantity{big.NewInt(7)},
+			CallData:             executeUserOpCallData(t, userOpRecipient, 1e15, nil, 0),
+			CallGasLimit:         Quantity{big.NewInt(100000)},
+			VerificationGasLimit: Quantity{big.NewInt(500000)},
+			PreVerificationGas:   Quantity{big.NewInt(50000)},
+			MaxFeePerGas:         Quantity{big.NewInt(2000000000)},
+			MaxPriorityFeePerGas: Quantity{big.NewInt(1000000)},
+		},
+	}
+}
+
+func TestVerifyUserOperationSafeOpHash(t *testing.T) {
+	request := newUserOpRequest(t)
+	result, err := VerifyUserOperation(request, VerifyOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Encode the SafeOp by hand from the typehash constant in Safe4337Module v0.3.0
+	op := request.UserOperation
+	word := func(n int64) []byte { return math.U256Bytes(big.NewInt(n)) }
+	address := func(a string) []byte { return common.LeftPadBytes(common.HexToAddress(a).Bytes(), 32) }
+	structHash := crypto.Keccak256(
+		common.FromHex("0xc03dfc11d8b10bf9cf703d558958c8c42777f785d998c62060d85a4f0ef6ea7f"),
+		address(userOpSafe), word(7), crypto.Keccak256(nil), crypto.Keccak256(common.FromHex(op.CallData)),
+		word(500000), word(100000), word(50000), word(1000000), word(2000000000),
+		crypto.Keccak256(nil), word(0), word(1893456000), address(request.EntryPoint),
+	)
+	domainHash := crypto.Keccak256(
+		crypto.Keccak256([]byte("EIP712Domain(uint256 chainId,address verifyingContract)")),
+		word(10), address(Safe4337Deployments[1].Module),
+	)
+	want := crypto.Keccak256Hash([]byte{0x19, 0x01}, domainHash, structHash).Hex()
+	if result.SafeOpHash != want {
+		t.Errorf("SafeOp hash = %s, want %s", result.SafeOpHash, want)
+	}
+	if result.MessageHash != common.BytesToHash(structHash).Hex() {
+		t.Errorf("message hash = %s, want %x", result.MessageHash, structHash)
+	}
+
+	if result.To != common.HexToAddress(userOpRecipient).Hex() || result.Value.Int64() != 1e15 || result.Operation != 0 {
+		t.Errorf("decoded call = %s %s %d", result.To, result.Value, result.Operation)
+	}
+	if result.ModuleVersion != "0.3.0" || result.Paymaster != "" {
+		t.Errorf("module version = %s, paymaster = %s", result.ModuleVersion, result.Paymaster)
+	}
+	// (500000 + 100000 + 50000) gas at 2 gwei
+	if result.MaxGasCost.String() != "1300000000000000" {
+		t.Errorf("max gas cost = %s", result.MaxGasCost)
+	}
+	if HasCritical(result.Warnings) {
+		t.Errorf("unexpected critical warnings: %+v", result.Warnings)
+	}
+}
+
+func TestVerifyUserOperationSafeOpHashV020(t *testing.T) {
+	request := newUserOpRequest(t)
+	request.EntryPoint = Safe4337Deployments[0].EntryPoint
+	result, err := VerifyUserOperation(request, VerifyOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Encode the SafeOp by hand from the typehash constant in Safe4337Module v0.2.0, which signs
+	// the unpacked EntryPoint v0.6 gas fields
+	op := request.UserOperation
+	word := func(n int64) []byte { return math.U256Bytes(big.NewInt(n)) }
+	address := func(a string) []byte { return common.LeftPadBytes(common.HexToAddress(a).Bytes(), 32) }
+	structHash := crypto.Keccak256(
+		common.FromHex("0x84aa190356f56b8c87825f54884392a9907c23ee0f8e1ea86336b763faf021bd"),
+		address(userOpSafe), word(7), crypto.Keccak256(nil), crypto.Keccak256(common.FromHex(op.CallData)),
+		word(100000), word(500000), word(50000), word(2000000000), word(1000000),
+		crypto.Keccak256(nil), word(0), word(1893456000), address(request.EntryPoint),
+	)
+	domainHash := crypto.Keccak256(
+		crypto.Keccak256([]byte("EIP712Domain(uint256 chainId,address verifyingContract)")),
+		word(10), address(Safe4337Deployments[0].Module),
+	)
+	want := crypto.Keccak256Hash([]byte{0x19, 0x01}, domainHash, structHash).Hex()
+	if result.SafeOpHash != want {
+		t.Errorf("SafeOp hash = %s, want %s", result.SafeOpHash, want)
+	}
+}
+
+func TestSafeOpTypehashes(t *testing.T) {
+	// SAFE_OP_TYPEHASH of each Safe4337Module release
+	want := map[string]string{
+		"0.2.0": "0x84aa190356f56b8c87825f54884392a9907c23ee0f8e1ea86336b763faf021bd",
+		"0.3.0": "0xc03dfc11d8b10bf9cf703d558958c8c42777f785d998c62060d85a4f0ef6ea7f",
+	}
+	for _, deployment := range Safe4337Deployments {
+		typedData := safeOpTypedData(deployment.Version, 10, deployment.Module, newUserOpRequest(t), nil, nil, nil)
+		if got := common.BytesToHash(typedData.TypeHash("SafeOp")).Hex(); got != want[deployment.Version] {
+			t.Errorf("v%s SafeOp typehash = %s, want %s", deployment.Version, got, want[deployment.Version])
+		}
+	}
+}
+
+func TestVerifyUserOperationUserOpHashVectors(t *testing.T) {
+	// Expected hashes were returned by getUserOpHash of the EntryPoint v0.6 and v0.7 runtime code
+	// the OP Stack preinstalls, run on chain 10 at the canonical EntryPoint addresses
+	v06 := newUserOpRequest(t)
+	v06.EntryPoint = Safe4337Deployments[0].EntryPoint
+	v06.UserOperation.InitCode = "0x4e1DCf7AD4e460CfD30791CCC4F9c8a4f820ec671688f0b9"
+	v06.UserOperation.PaymasterAndData = "0x2222222222222222222222222222222222222222abcd"
+
+	v07 := newUserOpRequest(t)
+	v07.UserOperation.Factory = "0x4e1DCf7AD4e460CfD30791CCC4F9c8a4f820ec67"
+	v07.UserOperation.FactoryData = "0x1688f0b9"
+	v07.UserOperation.Paymaster = "0x2222222222222222222222222222222222222222"
+	v07.UserOperation.PaymasterVerificationGasLimit = Quantity{big.NewInt(30000)}
+	v07.UserOperation.PaymasterPostOpGasLimit = Quantity{big.NewInt(20000)}
+	v07.UserOperation.PaymasterData = "0xabcd"
+
+	tests := []struct {
+		name    string
+		request UserOperationRequest
+		want    string
+	}{
+		{"v0.6", v06, "0x7738e79ab2be5b7ecf406bb7b5f71a61190b46a81dbd86276ed6e1fe69a5dc9e"},
+		{"v0.7", v07, "0xabce60c2a8889ab74ca020680cd83526f87313f7fcd665dc590c413f439f08ac"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := VerifyUserOperation(tt.request, VerifyOptions{})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.UserOpHash != tt.want {
+				t.Errorf("userOpHash = %s, want %s", result.UserOpHash, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerifyUserOperationUserOpHashDependsOnEntryPointVersion(t *testing.T) {
+	v07 := newUserOpRequest(t)
+	v06 := newUserOpRequest(t)
+	v06.EntryPoint = Safe4337Deployments[0].EntryPoint
+
+	a, err := VerifyUserOperation(v07, VerifyOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	b, err := VerifyUserOperation(v06, VerifyOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if a.UserOpHash == b.UserOpHash || a.SafeOpHash == b.SafeOpHash {
+		t.Error("v0.6 and v0.7 operations should hash differently")
+	}
+	if b.ModuleVersion != "0.2.0" || b.Module != Safe4337Deployments[0].Module {
+		t.Errorf("module = %s v%s", b.Module, b.ModuleVersion)
+	}
+}
+
+func TestVerifyUserOperationPaymaster(t *testing.T) {
+	paymaster := "0x2222222222222222222222222222222222222222"
+
+	v07 := newUserOpRequest(t)
+	v07.UserOperation.Paymaster = paymaster
+	v07.UserOperation.PaymasterVerificationGasLimit = Quantity{big.NewInt(30000)}
+	v07.UserOperation.PaymasterPostOpGasLimit = Quantity{big.NewInt(20000)}
+	result, err := VerifyUserOperation(v07, VerifyOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Paymaster != common.HexToAddress(paymaster).Hex() {
+		t.Errorf("paymaster = %s", result.Paymaster)
+	}
+	if result.MaxGasCost.String() != "1400000000000000" {
+		t.Errorf("max gas cost = %s", result.MaxGasCost)
+	}
+	if !hasWarningContaining(result.Warnings, "paymaster") {
+		t.Errorf("expected a paymaster warning, got %+v", result.Warnings)
+	}
+
+	v06 := newUserOpRequest(t)
+	v06.EntryPoint = Safe4337Deployments[0].EntryPoint
+	v06.UserOperation.PaymasterAndData = paymaster + "abcd"
+	result, err = VerifyUserOperation(v06, VerifyOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// The verification gas limit counts three times with a v0.6 paymaster
+	if result.Paymaster != common.HexToAddress(paymaster).Hex() || result.MaxGasCost.String() != "3300000000000000" {
+		t.Errorf("paymaster = %s, max gas cost = %s", result.Paymaster, result.MaxGasCost)
+	}
+
+	v06.UserOperation.Paymaster = paymaster
+	if _, err := VerifyUserOperation(v06, VerifyOptions{}); err == nil {
+		t.Error("expected an error for v0.7 fields on a v0.6 operation")
+	}
+}
+
+func TestVerifyUserOperationWarnings(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*UserOperationRequest)
+		want   string
+	}{
+		{"not executeUserOp", func(r *UserOperationRequest) { r.UserOperation.CallData = "0xdeadbeef" }, "does not call executeUserOp"},
+		{"delegatecall", func(r *UserOperationRequest) {
+			r.UserOperation.CallData = executeUserOpCallData(t, userOpRecipient, 0, nil, 1)
+		}, "DELEGATECALL"},
+		{"signature window", func(r *UserOperationRequest) {
+			r.UserOperation.Signature = "0x" + strings.Repeat("00", 12) + strings.Repeat("11", 65)
+		}, "existing signatures are for a different SafeOp"},
+		{"never expires", func(r *UserOperationRequest) { r.ValidUntil = 0 }, "never expires"},
+		{"factory", func(r *UserOperationRequest) {
+			r.UserOperation.Factory = "0x4e1DCf7AD4e460CfD30791CCC4F9c8a4f820ec67"
+			r.UserOperation.FactoryData = "0x1234"
+		}, "deploys the Safe"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := newUserOpRequest(t)
+			tt.modify(&request)
+			result, err := VerifyUserOperation(request, VerifyOptions{})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !hasWarningContaining(result.Warnings, tt.want) {
+				t.Errorf("expected a warning containing %q, got %+v", tt.want, result.Warnings)
+			}
+		})
+	}
+}
+
+func TestVerifyUserOperationRejectsUnsupportedEntryPoint(t *testing.T) {
+	request := newUserOpRequest(t)
+	request.EntryPoint = userOpRecipient
+	if _, err := VerifyUserOperation(request, VerifyOptions{}); err == nil || !strings.Contains(err.Error(), "unsupported EntryPoint") {
+		t.Errorf("expected an unsupported EntryPoint error, got %v", err)
+	}
+}
+
+func TestQuantityJSON(t *testing.T) {
+	var op UserOperation
+	if err := json.Unmarshal([]byte(`{"nonce": "0x10", "callGasLimit": "100", "maxFeePerGas": 5}`), &op); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if op.Nonce.Int64() != 16 || op.CallGasLimit.Int64() != 100 || op.MaxFeePerGas.Int64() != 5 {
+		t.Errorf("parsed %s %s %s", op.Nonce, op.CallGasLimit, op.MaxFeePerGas)
+	}
+	data, _ := json.Marshal(op.Nonce)
+	if string(data) != `"0x10"` {
+		t.Errorf("marshaled nonce = %s", data)
+	}
+}
+
+// hasWarningContaining reports whether any warning mentions the given text
+func hasWarningContaining(warnings []Warning, text string) bool {
+	for _, warning := range warnings {
+		if strings.Contains(warning.Message, text) {
+			return true
+		}
+	}
+	return false
+}
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"math/big"
 	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
 )
 
 // VerificationResult represents the complete output of the verification process
@@ -14,6 +16,34 @@ type VerificationResult struct {
 	ApproveHash  string              `json:"approveHash"`
 	Call         CallData            `json:"call"`
 	NestedResult *VerificationResult `json:"nestedResult,omitempty"`
+	Warnings     []Warning           `json:"warnings,omitempty"`
+
+	// OwnerDiff is how the transaction changes its Safe's owners and threshold, when it does
+	// and CheckOwnerChanges was run
+	OwnerDiff *OwnerDiff `json:"ownerDiff,omitempty"`
+
+	// Schedule is where the transaction stood in its signing window, when it has one and
+	// CheckSchedule was run
+	Schedule *ScheduleStatus `json:"schedule,omitempty"`
+
+	// Compliance summarizes the transaction's value flows, when VerifyOptions.Compliance is set
+	Compliance *ComplianceSummary `json:"compliance,omitempty"`
+
+	// PreviousDiff is how the transaction differs from the one at an earlier nonce, when
+	// DiffTransactions was run
+	PreviousDiff *TransactionDiff `json:"previousDiff,omitempty"`
+
+	// Rejection is set when the transaction only burns its nonce, cancelling any other
+	// transaction queued for it
+	Rejection bool `json:"rejection,omitempty"`
+
+	// Redacted is set on a copy made by RedactResult, whose recipients, amounts, and calldata
+	// are masked
+	Redacted bool `json:"redacted,omitempty"`
+
+	// Review is the review record of the transaction, when it was reviewed in a review session
+	// applied with ApplyReviewSession
+	Review *SessionTransaction `json:"review,omitempty"`
 }
 
 // Nested represents the data about nested approve hash transactions
@@ -24,6 +54,21 @@ type Nested struct {
 	Data        string `json:"data"`
 	Operation   int    `json:"operation"`
 	To          string `json:"to"`
+
+	// ServiceSafeTxHash is the safeTxHash the Safe service reported for the parent transaction
+	ServiceSafeTxHash string `json:"service_safe_tx_hash,omitempty"`
+
+	// SignerProgress is how many owners of the parent Safe have signed the approval
+	SignerProgress *SignerProgress `json:"signer_progress,omitempty"`
+
+	// Execution is where the Safe service says the parent transaction was executed
+	Execution *Execution `json:"execution,omitempty"`
+
+	// Proposal is who proposed the parent transaction to the Safe service, with which app, and when
+	Proposal *Proposal `json:"proposal,omitempty"`
+
+	// ServiceDecoded is the Safe service's decoding of the parent transaction's calldata
+	ServiceDecoded *ServiceDecodedCall `json:"service_data_decoded,omitempty"`
 }
 
 // SafeTransaction represents a Gnosis Safe transaction
@@ -43,32 +88,159 @@ type SafeTransaction struct {
 	Nonce          int      `json:"nonce"`
 	Nested         *Nested  `json:"nested,omitempty"`
 	Call           CallData `json:"call"`
+
+	// ServiceSafeTxHash is the safeTxHash the Safe service reported for this transaction. It is
+	// cross-checked against the locally computed hash; a mismatch means the service (or the
+	// file) is inconsistent about what is being signed.
+	ServiceSafeTxHash string `json:"service_safe_tx_hash,omitempty"`
+
+	// ServiceDecoded is the Safe service's decoding of the calldata, which is compared with the
+	// local decoding. Not hashed and only set for generated transactions.
+	ServiceDecoded *ServiceDecodedCall `json:"service_data_decoded,omitempty"`
+
+	// Provenance records, per hashed field, whether the value came from the Safe service or
+	// was defaulted because the service omitted it. Only set for generated transactions.
+	Provenance map[string]string `json:"provenance,omitempty"`
+
+	// SignerProgress is how many owners have signed according to the Safe service. It is not
+	// hashed and only set for generated transactions.
+	SignerProgress *SignerProgress `json:"signer_progress,omitempty"`
+
+	// Execution is where the Safe service says the transaction was executed, if it already was.
+	// It is not hashed and only set for generated transactions.
+	Execution *Execution `json:"execution,omitempty"`
+
+	// Proposal is who proposed the transaction to the Safe service, with which app, and when. Not
+	// hashed and only set for generated transactions.
+	Proposal *Proposal `json:"proposal,omitempty"`
+
+	// Replacements are the other transactions queued for the same nonce, such as a replacement
+	// or a cancellation. Only one of them can execute. Not hashed and only set for generated
+	// transactions.
+	Replacements []PendingTransaction `json:"replacements,omitempty"`
+
+	// FallbackSources name where the Safe service data came from when the Safe Transaction
+	// Service failed, such as a mirror, its cached responses, or events on chain. Not hashed and
+	// only set for generated transactions.
+	FallbackSources []string `json:"fallback_sources,omitempty"`
+}
+
+// SignerProgress records the owner signatures the Safe service has collected for a transaction
+type SignerProgress struct {
+	Required      int               `json:"required"`
+	Confirmations []APIConfirmation `json:"confirmations"`
 }
 
 // CallData represents a function call with parsed arguments
 type CallData struct {
+	Index          string      `json:"index,omitempty"`
 	Target         string      `json:"target"`
 	TargetName     string      `json:"targetName,omitempty"`
+	TargetLabel    string      `json:"targetLabel,omitempty"`
 	FunctionName   string      `json:"functionName"`
 	FunctionData   string      `json:"functionData,omitempty"`
 	RawData        string      `json:"rawData,omitempty"`
-	ParsedData     interface{} `json:"parsedData,omitempty"`
+	ParsedData     []Argument  `json:"parsedData,omitempty"`
 	SubCalls       []CallData  `json:"subCalls,omitempty"`
 	IsDelegateCall bool        `json:"isDelegateCall,omitempty"`
+	Deployment     *Deployment `json:"deployment,omitempty"`
+	Deposit        *Deposit    `json:"deposit,omitempty"`
+
+	// Summary is the readable summary of a well-known call (a swap, a governance proposal), in
+	// field name order and without types. It is shown next to ParsedData, never instead of it.
+	Summary []Argument `json:"summary,omitempty"`
+
+	// Emergency is set when the call is to an emergency function, such as pause or blacklist
+	Emergency bool `json:"emergency,omitempty"`
+
+	// Digest identifies a batched subcall by what it does; see SubcallDigest
+	Digest string `json:"digest,omitempty"`
+
+	// Value is the ETH a batched subcall sends, when it sends any. The value of the transaction
+	// itself is on the transaction.
+	Value *big.Int `json:"value,omitempty"`
+
+	// Annotations are the facilitator's explanations of decoded arguments, keyed by argument path
+	Annotations map[string]string `json:"annotations,omitempty"`
+
+	// Review records that the call was reviewed in a review session
+	Review *CallReview `json:"review,omitempty"`
+}
+
+// Argument is a decoded argument of a call. The arguments of a call are in signature order and
+// carry their ABI type; the fields of a call's summary have no type. Byte strings are given as
+// hex.
+type Argument struct {
+	Name  string      `json:"name"`
+	Type  string      `json:"type,omitempty"`
+	Value interface{} `json:"value"`
+
+	// Display is how the value is shown when it differs from the value itself, such as an
+	// address with the name of the contract or a token amount scaled by its decimals
+	Display string `json:"display,omitempty"`
+}
+
+// shown returns the argument as it is shown: its display text, or else its value
+func (a Argument) shown() interface{} {
+	if a.Display != "" {
+		return a.Display
+	}
+	return a.Value
+}
+
+// Argument returns the decoded argument of a call with a name
+func (c CallData) Argument(name string) (Argument, bool) {
+	for _, arg := range c.ParsedData {
+		if arg.Name == name {
+			return arg, true
+		}
+	}
+	return Argument{}, false
 }
 
 // VerifyOptions contains configuration options for verification
 type VerifyOptions struct {
-	Verbose bool
+	// Verbosity is how much detail the caller will show, from 0 for the standard output upwards
+	Verbosity int
+
+	// Denylist, when set, raises a critical warning for any listed address in the transaction
+	Denylist *Denylist
+
+	// AddressBook, when set, labels the addresses it names, including counterfactual ones
+	AddressBook *AddressRegistry
+
+	// IndependentDecode, when set, decodes calldata a second time with an independent decoder
+	// and fails verification if the two decodings disagree
+	IndependentDecode bool
+
+	// Compliance, when set, adds a summary of the transaction's value flows to the result
+	Compliance bool
+
+	// Annotations, when set, attaches explanations to the decoded arguments they name
+	Annotations *ArgumentAnnotations
+
+	// Grants, when set, is the grants manifest the vesting schedules a transaction creates must
+	// match
+	Grants *GrantsManifest
 }
 
 // VerifyTransaction verifies a Safe transaction
 func VerifyTransaction(tx SafeTransaction, options VerifyOptions) (*VerificationResult, error) {
+	// Refuse to continue if the chain field and any address prefixes disagree
+	sources, err := transactionChainSources(tx)
+	if err != nil {
+		return nil, err
+	}
+	chainID, err := ResolveChainID(sources...)
+	if err != nil {
+		return nil, err
+	}
+	tx.Chain = int(chainID)
+
 	// Check if this is a nested transaction
 	var nestedResult *VerificationResult
 	if tx.Nested != nil {
 		// Verify the inner transaction first
-		var err error
 		nestedResult, err = verifyTransactionInternal(tx, options)
 		if err != nil {
 			return nil, fmt.Errorf("failed to verify nested transaction: %w", err)
@@ -82,6 +254,9 @@ func VerifyTransaction(tx SafeTransaction, options VerifyOptions) (*Verification
 		tx.Value = big.NewInt(0)
 		tx.Data = tx.Nested.Data
 		tx.SafeVersion = tx.Nested.SafeVersion
+		tx.SignerProgress = tx.Nested.SignerProgress
+		tx.Execution = tx.Nested.Execution
+		tx.Proposal = tx.Nested.Proposal
 	}
 
 	// Verify the main transaction
@@ -93,6 +268,42 @@ func VerifyTransaction(tx SafeTransaction, options VerifyOptions) (*Verification
 	// Attach nested result if it exists
 	result.NestedResult = nestedResult
 
+	// Cross-check the locally computed hashes against what the Safe service claims
+	if nestedResult != nil {
+		result.Warnings = append(result.Warnings, checkServiceHash("child transaction", nestedResult.ApproveHash, nestedResult.Transaction.ServiceSafeTxHash)...)
+		result.Warnings = append(result.Warnings, checkServiceHash("parent transaction", result.ApproveHash, tx.Nested.ServiceSafeTxHash)...)
+		result.Warnings = append(result.Warnings, checkApprovedHash(tx.Nested.Data, nestedResult.ApproveHash)...)
+		result.Warnings = append(result.Warnings, checkServiceDecoding("child transaction", nestedResult.Transaction.ServiceDecoded, nestedResult.Call)...)
+		result.Warnings = append(result.Warnings, checkServiceDecoding("parent transaction", tx.Nested.ServiceDecoded, result.Call)...)
+	} else {
+		result.Warnings = append(result.Warnings, checkServiceHash("transaction", result.ApproveHash, tx.ServiceSafeTxHash)...)
+		result.Warnings = append(result.Warnings, checkServiceDecoding("transaction", tx.ServiceDecoded, result.Call)...)
+	}
+	result.Warnings = append(result.Warnings, checkReplacements(tx.Replacements)...)
+	result.Warnings = append(result.Warnings, checkFallbackSources(tx.FallbackSources)...)
+	if nestedResult != nil {
+		result.Warnings = append(result.Warnings, checkProposal("child transaction", nestedResult.Transaction.Proposal)...)
+		result.Warnings = append(result.Warnings, checkProposal("parent transaction", tx.Proposal)...)
+	} else {
+		result.Warnings = append(result.Warnings, checkProposal("transaction", tx.Proposal)...)
+	}
+
+	// Flag lookalike addresses (address poisoning / vanity spoofing)
+	result.Warnings = append(result.Warnings, checkAddressSimilarity(result)...)
+	result.Warnings = append(result.Warnings, checkSelfCalls(result)...)
+	result.Warnings = append(result.Warnings, checkRoleChanges(result)...)
+	result.Warnings = append(result.Warnings, checkOwnershipTransfers(result)...)
+	result.Warnings = append(result.Warnings, checkLabelConflicts(result, options.AddressBook)...)
+	result.Warnings = append(result.Warnings, checkDenylist(result, options.Denylist)...)
+	result.Warnings = append(result.Warnings, annotateArguments(result, options.Annotations)...)
+	result.Warnings = append(result.Warnings, checkGrants(result, options.Grants)...)
+
+	if options.Compliance {
+		if result.Compliance, err = SummarizeValueFlows(result, options.AddressBook); err != nil {
+			return nil, err
+		}
+	}
+
 	return result, nil
 }
 
@@ -102,13 +313,41 @@ func verifyTransactionInternal(tx SafeTransaction, options VerifyOptions) (*Veri
 	tx.To = StripChainPrefix(tx.To)
 	tx.Safe = StripChainPrefix(tx.Safe)
 
+	// A shortened or mangled address would still hash (HexToAddress pads it), producing hashes for
+	// an address nobody reviewed; require every address that is hashed to be given in full
+	for _, field := range []struct{ name, value string }{
+		{"safe", tx.Safe}, {"to", tx.To}, {"gas_token", tx.GasToken}, {"refund_receiver", tx.RefundReceiver},
+	} {
+		if err := ValidateFullAddress(field.name, field.value); err != nil {
+			return nil, err
+		}
+	}
+
 	// Parse the transaction data
 	call, err := ParseTransactionData(tx.To, tx.Data, uint64(tx.Chain), options)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse transaction data: %w", err)
+		return nil, withKind(ErrDecodeFailure, fmt.Errorf("failed to parse transaction data: %w", err))
+	}
+	PredictDeployments(call, tx.Safe, tx.Operation == 1)
+	PredictDeposits(call, tx.Safe, tx.Value, tx.Operation == 1)
+	LabelAddresses(call, uint64(tx.Chain), options.AddressBook)
+	rejection := IsRejection(tx)
+	if rejection {
+		call.FunctionName = RejectionFunctionName
+		call.RawData = ""
 	}
 	tx.Call = *call
 
+	if options.IndependentDecode {
+		if mismatches := CrossCheckDecoding(tx.To, tx.Data, uint64(tx.Chain)); len(mismatches) > 0 {
+			messages := make([]string, len(mismatches))
+			for i, mismatch := range mismatches {
+				messages[i] = mismatch.String()
+			}
+			return nil, fmt.Errorf("independent decoders disagree: %s", strings.Join(messages, "; "))
+		}
+	}
+
 	// Calculate the domain and message hashes
 	domainHash, err := CalculateDomainHash(tx)
 	if err != nil {
@@ -132,11 +371,76 @@ func verifyTransactionInternal(tx SafeTransaction, options VerifyOptions) (*Veri
 		MessageHash: messageHash,
 		ApproveHash: approveHash,
 		Call:        *call,
+		Rejection:   rejection,
 	}
 
 	return result, nil
 }
 
+// checkServiceHash compares a locally computed safeTxHash with the one reported by the Safe service
+func checkServiceHash(label, localHash, serviceHash string) []Warning {
+	if serviceHash == "" {
+		return nil
+	}
+	if !strings.EqualFold(localHash, serviceHash) {
+		return []Warning{newWarning(warnServiceHashMismatch,
+			"Safe service reported safeTxHash %s for the %s but the locally computed hash is %s. The service and the transaction contents disagree; DO NOT SIGN.",
+			serviceHash, label, localHash)}
+	}
+	return nil
+}
+
+// checkReplacements warns about the other transactions queued for the same nonce, since a
+// signer who verified one of them must not sign another
+func checkReplacements(replacements []PendingTransaction) []Warning {
+	if len(replacements) == 0 {
+		return nil
+	}
+	hashes := make([]string, len(replacements))
+	for i, replacement := range replacements {
+		hashes[i] = replacement.SafeTxHash
+	}
+	return []Warning{newWarning(warnReplacements,
+		"%d other transaction(s) are queued for the same nonce (%s). Only one of them can execute; make sure the safeTxHash you sign is the one verified here.",
+		len(replacements), strings.Join(hashes, ", "))}
+}
+
+// checkApprovedHash ensures the hash approved by a parent approveHash call is the child hash we computed
+func checkApprovedHash(parentData, childHash string) []Warning {
+	data := strings.TrimPrefix(strings.ToLower(parentData), "0x")
+	if !strings.HasPrefix(data, "d4d9bdcd") || len(data) < 72 {
+		return nil
+	}
+	approved := "0x" + data[8:72]
+	if !strings.EqualFold(approved, childHash) {
+		return []Warning{newWarning(warnApprovedHashMismatch,
+			"Parent transaction approves hash %s but the child transaction hashes to %s; DO NOT SIGN.",
+			approved, childHash)}
+	}
+	return nil
+}
+
+// ValidateFullAddress checks that an address is a complete 20-byte hex address. Empty values are
+// allowed for optional fields; anything truncated (e.g. "0x1234…abcd") or malformed is rejected.
+func ValidateFullAddress(name, address string) error {
+	if address == "" && name != "safe" && name != "to" {
+		return nil
+	}
+	if !common.IsHexAddress(address) {
+		return fmt.Errorf("invalid %s address %q: addresses must be given in full (40 hex digits)", name, address)
+	}
+	return nil
+}
+
+// ChecksumAddress returns an address in full with EIP-55 checksum casing. Values that are not
+// addresses are returned unchanged.
+func ChecksumAddress(address string) string {
+	if !common.IsHexAddress(address) {
+		return address
+	}
+	return common.HexToAddress(address).Hex()
+}
+
 // stripChainPrefix removes chain prefixes like "oeth:", "eth:", etc. from addresses
 func StripChainPrefix(address string) string {
 	if idx := strings.Index(address, ":"); idx != -1 {
@@ -0,0 +1,98 @@
+package core
+
+import (
+	"math/big"
+	"testing"
+)
+
+// grantsTransferTx is the OP Mainnet grants transfer used across the hashing tests
+func grantsTransferTx() SafeTransaction {
+	return SafeTransaction{
+		Safe:           "0x2501c477D0A35545a387Aa4A3EEe4292A9a8B3F0",
+		SafeVersion:    "1.3.0",
+		Chain:          OPMainnetChainID,
+		To:             OPTokenAddress,
+		Value:          big.NewInt(0),
+		Data:           "0xa9059cbb0000000000000000000000008b8b2f214d92527bf1b1148dc2e609a4c1c2fd69000000000000000000000000000000000000000000034f086f3b33b684000000",
+		GasToken:       ZeroAddress,
+		RefundReceiver: ZeroAddress,
+		Nonce:          155,
+	}
+}
+
+func TestVerifyTransactionServiceHashMatches(t *testing.T) {
+	tx := grantsTransferTx()
+	tx.ServiceSafeTxHash = "0x19767D264966E39D532D998C5354F76AD5102407124B1885D69BF23F791B6F4C"
+
+	result, err := VerifyTransaction(tx, VerifyOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Warnings) != 0 {
+		t.Fatalf("expected no warnings, got %+v", result.Warnings)
+	}
+}
+
+func TestVerifyTransactionServiceHashMismatch(t *testing.T) {
+	tx := grantsTransferTx()
+	tx.ServiceSafeTxHash = "0x19767d264966e39d532d998c5354f76ad5102407124b1885d69bf23f791b6f4d"
+
+	result, err := VerifyTransaction(tx, VerifyOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !HasCritical(result.Warnings) {
+		t.Fatalf("expected a critical warning, got %+v", result.Warnings)
+	}
+}
+
+func TestVerifyTransactionApprovedHashMismatch(t *testing.T) {
+	tx := grantsTransferTx()
+	tx.Nested = &Nested{
+		Safe:        "0xE2Ed962948005AB01F2cEfE8326a0730B7D268af",
+		SafeVersion: "1.3.0",
+		Nonce:       42,
+		To:          tx.Safe,
+		Data:        "0xd4d9bdcd0000000000000000000000000000000000000000000000000000000000000001",
+	}
+
+	result, err := VerifyTransaction(tx, VerifyOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !HasCritical(result.Warnings) {
+		t.Fatalf("expected a critical warning for mismatched approved hash, got %+v", result.Warnings)
+	}
+}
+
+func TestVerifyTransactionRejectsTruncatedAddresses(t *testing.T) {
+	for _, mutate := range []func(tx *SafeTransaction){
+		func(tx *SafeTransaction) { tx.To = "0x4200…0042" },
+		func(tx *SafeTransaction) { tx.To = "0x42000000000000000000000000000000000042" },
+		func(tx *SafeTransaction) { tx.Safe = "oeth:0x2501c477...8B3F0" },
+		func(tx *SafeTransaction) { tx.RefundReceiver = "0x00" },
+	} {
+		tx := grantsTransferTx()
+		mutate(&tx)
+		if _, err := VerifyTransaction(tx, VerifyOptions{}); err == nil {
+			t.Errorf("expected error for truncated address in %+v", tx)
+		}
+	}
+
+	// Lowercase (non-checksummed) addresses and an omitted gas token are still accepted
+	tx := grantsTransferTx()
+	tx.Safe = "0x2501c477d0a35545a387aa4a3eee4292a9a8b3f0"
+	tx.GasToken = ""
+	if _, err := VerifyTransaction(tx, VerifyOptions{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestChecksumAddress(t *testing.T) {
+	if got := ChecksumAddress("0x2501c477d0a35545a387aa4a3eee4292a9a8b3f0"); got != "0x2501c477D0A35545a387Aa4A3EEe4292A9a8B3F0" {
+		t.Errorf("ChecksumAddress = %s", got)
+	}
+	if got := ChecksumAddress("not an address"); got != "not an address" {
+		t.Errorf("non-addresses should be returned unchanged, got %s", got)
+	}
+}
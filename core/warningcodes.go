@@ -0,0 +1,351 @@
+package core
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// WarningCheck is a check that raises warnings. Its code is stable across releases, so a warning
+// can be looked up with `op-txverify explain` on a machine without network access.
+type WarningCheck struct {
+	Code     string   `json:"code"`
+	Severity Severity `json:"severity"`
+	Title    string   `json:"title"`
+
+	// Meaning is what the warning says, Why is why it matters, and Action is what the signer
+	// should do about it
+	Meaning string `json:"meaning"`
+	Why     string `json:"why"`
+	Action  string `json:"action"`
+}
+
+// warningChecks are the checks by code, filled in by defineCheck
+var warningChecks = map[string]*WarningCheck{}
+
+// defineCheck adds a check to the catalog. Codes are never reused or renumbered; a check that is
+// removed keeps its code retired.
+func defineCheck(code string, severity Severity, title, meaning, why, action string) *WarningCheck {
+	if _, ok := warningChecks[code]; ok {
+		panic("duplicate warning code " + code)
+	}
+	check := &WarningCheck{Code: code, Severity: severity, Title: title, Meaning: meaning, Why: why, Action: action}
+	warningChecks[code] = check
+	return check
+}
+
+// WarningChecks returns every check, in order of code
+func WarningChecks() []WarningCheck {
+	checks := make([]WarningCheck, 0, len(warningChecks))
+	for _, check := range warningChecks {
+		checks = append(checks, *check)
+	}
+	sort.Slice(checks, func(i, j int) bool { return checks[i].Code < checks[j].Code })
+	return checks
+}
+
+// LookupWarningCheck returns the check of a warning code such as OPTX-W007. The code is matched
+// regardless of case, and the OPTX- prefix and leading zeros may be left out, as in w7.
+func LookupWarningCheck(code string) (*WarningCheck, error) {
+	normalized := strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(code)), "OPTX-")
+	if digits, ok := strings.CutPrefix(normalized, "W"); ok {
+		if number, err := strconv.Atoi(digits); err == nil && number >= 0 {
+			normalized = fmt.Sprintf("W%03d", number)
+		}
+	}
+	check, ok := warningChecks["OPTX-"+normalized]
+	if !ok {
+		return nil, fmt.Errorf("unknown warning code %q", code)
+	}
+	copied := *check
+	return &copied, nil
+}
+
+// Checks of the Safe service's data
+var (
+	warnServiceHashMismatch = defineCheck("OPTX-W001", SeverityCritical, "Safe service hash differs",
+		"The Safe service reported a safeTxHash that differs from the hash op-txverify computed from the transaction's fields.",
+		"The hash is what owners sign. If the service and the fields disagree, either the service data was tampered with or the fields were read wrongly, and a signature may approve a transaction other than the one reviewed.",
+		"Do not sign. Fetch the transaction again, compare it with another signer's output, and report the difference.")
+	warnApprovedHashMismatch = defineCheck("OPTX-W002", SeverityCritical, "Parent approves a different hash",
+		"A nested Safe transaction calls approveHash on the child Safe, but with a hash other than the child transaction's.",
+		"The parent Safe would approve a child transaction other than the one shown, which is how a nested approval can be redirected.",
+		"Do not sign. Check which child transaction the parent is meant to approve, and have it proposed again.")
+	warnReplacements = defineCheck("OPTX-W003", SeverityWarning, "Other transactions share the nonce",
+		"More than one transaction is queued for the nonce, such as a replacement or a cancellation.",
+		"Only one of them can execute. Signing the wrong one approves a transaction that was not reviewed, or wastes the ceremony.",
+		"Agree with the other signers which safeTxHash is meant, and check that it is the one verified here.")
+	warnDecodingMismatch = defineCheck("OPTX-W004", SeverityWarning, "Safe service decodes the calldata differently",
+		"The Safe service's decoding of the calldata differs from op-txverify's own.",
+		"The Safe UI shows the service's decoding. If it differs, what other signers see may not be what the calldata does, or one of the decoders has a bug.",
+		"Decode the calldata with another tool, such as cast, and decide which decoding is right before signing.")
+	warnServiceFallback = defineCheck("OPTX-W005", SeverityWarning, "Loaded without the Safe service",
+		"The Safe Transaction Service failed, so the transaction was loaded from a mirror, cached responses, or the chain.",
+		"The hashes are still computed from the fields, but signer progress, the proposer, and other transactions queued for the nonce may be stale or missing.",
+		"Compare the Safe tx hash with another signer who loaded the transaction independently before signing.")
+	warnProposerNotOwner = defineCheck("OPTX-W006", SeverityWarning, "Proposer is not an owner",
+		"The transaction was proposed by an address that is not an owner of the Safe.",
+		"Delegates may propose transactions, but so may anyone who has obtained a delegate key. A proposal from an unexpected account deserves a second look.",
+		"Confirm with the owners that the proposal, and the account that made it, are expected.")
+	warnUnknownOrigin = defineCheck("OPTX-W007", SeverityWarning, "Proposed with an unknown app",
+		"The transaction was proposed through an app op-txverify does not know.",
+		"Apps name themselves when proposing, so the name proves nothing, and malicious apps have been used to get signatures on harmful transactions.",
+		"Ask the proposer where the proposal came from, and review the calls without relying on the app's description.")
+)
+
+// Checks of the addresses a transaction touches
+var (
+	warnSimilarAddresses = defineCheck("OPTX-W008", SeverityWarning, "Addresses look alike",
+		"Two addresses in the transaction share their first and last four bytes.",
+		"Address poisoning creates addresses that match the start and end of a real one, since those are the parts people check.",
+		"Compare both addresses in full against a trusted source before signing.")
+	warnLookalikeContract = defineCheck("OPTX-W009", SeverityWarning, "Address looks like a known contract",
+		"An address in the transaction resembles a known contract without being it.",
+		"A lookalike can stand in for the real contract, so that funds or permissions go to an attacker.",
+		"Check the address in full against the contract's published deployment.")
+	warnLabelConflict = defineCheck("OPTX-W010", SeverityWarning, "Address book disagrees with a known contract",
+		"An address book names an address differently from op-txverify's known contracts.",
+		"One of the names is wrong, and a wrong name can make a harmful call look routine.",
+		"Find out which name is right and correct the address book or the registry.")
+	warnDenylisted = defineCheck("OPTX-W011", SeverityCritical, "Denylisted address",
+		"The transaction involves an address on a denylist.",
+		"Denylisted addresses are known to be malicious, sanctioned, or never meant to receive anything from the Safe.",
+		"Do not sign. Find out why the address is in the transaction.")
+	warnSelfCall = defineCheck("OPTX-W012", SeverityWarning, "Safe calls itself",
+		"The transaction makes a call from the Safe to itself with calldata.",
+		"Such calls change the Safe's owners, threshold, modules, guard, or fallback handler, which can hand over control of the Safe.",
+		"Make sure the configuration change is intended and that every address it adds is trusted.")
+	warnETHUnchecked = defineCheck("OPTX-W013", SeverityInfo, "ETH recipients not checked",
+		"The transaction sends ETH, but no RPC endpoint was given to check that the recipients accept it.",
+		"A contract without a payable receive or fallback function rejects plain ETH, and the transaction reverts.",
+		"Give an RPC endpoint with --rpc-url or in config.json to have the recipients checked.")
+	warnETHRejected = defineCheck("OPTX-W014", SeverityWarning, "Recipient rejects ETH",
+		"A plain ETH transfer in the transaction goes to a contract that rejects it.",
+		"The transfer reverts, so the transaction fails or, inside a batch, everything in it fails.",
+		"Check the recipient address. To send ETH to a contract, call the function it provides for deposits.")
+	warnETHCheckFailed = defineCheck("OPTX-W015", SeverityInfo, "ETH recipient check failed",
+		"Whether a contract accepts a plain ETH transfer could not be checked.",
+		"The transfer may revert.",
+		"Try another RPC endpoint, or check the contract's receive and fallback functions by hand.")
+	warnOwnersUnchecked = defineCheck("OPTX-W016", SeverityInfo, "Owner changes not computed",
+		"The transaction changes the owners or threshold of the Safe, but no RPC endpoint was given to read the current ones.",
+		"Without the current owners, the owners and threshold after the transaction cannot be shown.",
+		"Give an RPC endpoint with --rpc-url or in config.json to see the resulting owners.")
+	warnOwnerChangeReverts = defineCheck("OPTX-W017", SeverityCritical, "Owner changes would revert",
+		"Applied to the Safe's current owners, the owner changes break one of the Safe's rules, such as a wrong previous owner or a threshold above the number of owners.",
+		"The transaction reverts, so the nonce is used up or the change is not made.",
+		"Do not sign. Have the transaction rebuilt against the current owners.")
+	warnAdminGranted = defineCheck("OPTX-W018", SeverityCritical, "Admin role granted",
+		"The transaction grants DEFAULT_ADMIN_ROLE of an AccessControl contract.",
+		"The admin role can grant and revoke every role of the contract, including its own, so the account gains full control.",
+		"Confirm the account is meant to control the contract, and check the address in full.")
+	warnAdminRemoved = defineCheck("OPTX-W019", SeverityWarning, "Admin role revoked or renounced",
+		"The transaction revokes or renounces DEFAULT_ADMIN_ROLE of an AccessControl contract.",
+		"If no other account holds the admin role, no one can manage the contract's roles afterwards.",
+		"Check that another intended account keeps the admin role.")
+	warnUnknownRole = defineCheck("OPTX-W020", SeverityInfo, "Unknown role",
+		"The transaction grants, revokes, or renounces a role whose name op-txverify does not know.",
+		"What the role allows cannot be shown.",
+		"Look the role up in the contract's source, and add its name to \"roles\" in config.json.")
+	warnOwnershipToZero = defineCheck("OPTX-W021", SeverityCritical, "Ownership transferred to the zero address",
+		"The transaction transfers the ownership of a contract to the zero address.",
+		"With plain Ownable the contract is left without an owner for good. With Ownable2Step this only cancels a pending transfer.",
+		"Check which ownership pattern the contract uses and that leaving it ownerless is intended.")
+	warnOwnershipToSafe = defineCheck("OPTX-W022", SeverityInfo, "Ownership transferred to this Safe",
+		"The transaction transfers the ownership of a contract to the Safe itself.",
+		"The Safe's owners will control the contract.",
+		"No action is needed if the Safe is meant to own the contract.")
+	warnOwnershipTransfer = defineCheck("OPTX-W023", SeverityCritical, "Ownership handed to another account",
+		"The transaction hands the ownership of a contract to another account.",
+		"The new owner controls the contract once the transfer completes, and it may not be possible to take it back.",
+		"Check the new owner's address in full against a trusted source.")
+	warnOwnershipRenounced = defineCheck("OPTX-W024", SeverityCritical, "Ownership renounced",
+		"The transaction renounces the ownership of a contract.",
+		"No one can call the contract's owner-only functions afterwards, and this cannot be undone.",
+		"Make sure giving up control of the contract is intended.")
+	warnOwnershipAccepted = defineCheck("OPTX-W025", SeverityWarning, "Ownership accepted",
+		"The transaction makes the Safe the owner of a contract by accepting a pending transfer.",
+		"The Safe takes on control of, and responsibility for, the contract.",
+		"Make sure the Safe is meant to control the contract.")
+)
+
+// Checks against the reviewer's own files
+var (
+	warnUnmatchedAnnotation = defineCheck("OPTX-W026", SeverityWarning, "Annotation matches nothing",
+		"An argument annotation names an argument this transaction does not have.",
+		"Its explanation is not shown, and the annotation may be out of date with the calls it describes.",
+		"Check the annotation's path against the decoded calls, and fix or remove it.")
+	warnGrantsUnchecked = defineCheck("OPTX-W027", SeverityWarning, "Vesting schedules not checked",
+		"The vesting schedules a transaction creates could not be read.",
+		"They cannot be compared with the grants manifest.",
+		"Review the schedules by hand against the grants they are meant to create.")
+	warnNoGrantsManifest = defineCheck("OPTX-W028", SeverityInfo, "No grants manifest",
+		"The transaction creates vesting schedules, but no grants manifest was given to check them against.",
+		"A recipient, amount, or schedule that differs from the grant is not caught.",
+		"Pass the grants manifest with --grants.")
+	warnNoGrant = defineCheck("OPTX-W029", SeverityCritical, "Vesting without a grant",
+		"A vesting schedule goes to a recipient with no grant in the grants manifest.",
+		"Funds would vest to an account no grant was made to.",
+		"Do not sign. Check the recipient address against the grant, or add the grant to the manifest if it is missing.")
+	warnGrantMatched = defineCheck("OPTX-W030", SeverityInfo, "Vesting matches its grant",
+		"A vesting schedule matches its grant in the grants manifest.",
+		"It confirms the recipient, amount, and schedule.",
+		"No action is needed.")
+	warnGrantMismatch = defineCheck("OPTX-W031", SeverityCritical, "Vesting differs from its grant",
+		"A vesting schedule differs from its grant in the grants manifest, such as in amount, start, or duration.",
+		"The recipient would vest something other than what was granted.",
+		"Do not sign until the schedule or the manifest is corrected.")
+	warnStaleReview = defineCheck("OPTX-W032", SeverityWarning, "Recorded review no longer matches",
+		"A review session records a call as reviewed, but this transaction does not make that call.",
+		"The transaction changed since it was reviewed, so the earlier review does not cover it.",
+		"Review the call again.")
+	warnWindowNotOpen = defineCheck("OPTX-W033", SeverityCritical, "Signing window not open",
+		"The signing window of the transaction has not opened yet.",
+		"A signature made now is outside the window the signers agreed on.",
+		"Wait until the window opens, or agree a new window with the other signers.")
+	warnDeadlinePassed = defineCheck("OPTX-W034", SeverityCritical, "Signing deadline passed",
+		"The signing deadline of the transaction has passed.",
+		"A signature made now is outside the window the signers agreed on.",
+		"Agree a new window with the other signers before signing.")
+	warnDeadlineSoon = defineCheck("OPTX-W035", SeverityWarning, "Signing deadline approaching",
+		"The signing deadline of the transaction is close.",
+		"A late signature would be outside the agreed window.",
+		"Sign in time, or tell the facilitator if you cannot.")
+)
+
+// Checks of executed transactions
+var (
+	warnFinalityUnchecked = defineCheck("OPTX-W036", SeverityInfo, "Finality not checked",
+		"The transaction was already executed, but no RPC endpoint was given to check its block.",
+		"An execution that is not final can still be undone by a re-org.",
+		"Give an RPC endpoint with --rpc-url or in config.json to check finality.")
+	warnExecutionNotFound = defineCheck("OPTX-W037", SeverityCritical, "Execution not found",
+		"The node does not know the execution transaction the Safe service reported.",
+		"The execution may have been re-orged out, or the node is not synced.",
+		"Check with another RPC endpoint before relying on the execution.")
+	warnExecutionReverted = defineCheck("OPTX-W038", SeverityCritical, "Execution reverted",
+		"The execution transaction reverted.",
+		"Nothing it was meant to do took effect.",
+		"Find out why it reverted before proposing it again.")
+	warnExecutionReorged = defineCheck("OPTX-W039", SeverityWarning, "Execution moved to another block",
+		"The execution is in a different block from the one the Safe service recorded.",
+		"The chain re-orged after the service indexed the execution.",
+		"Check the execution against the chain rather than the service.")
+	warnNonCanonicalBlock = defineCheck("OPTX-W040", SeverityCritical, "Execution block re-orged out",
+		"The block that included the execution is no longer on the canonical chain.",
+		"The execution may not have taken effect.",
+		"Do not rely on the execution. Check whether it was included again in another block.")
+	warnFinalityUnknown = defineCheck("OPTX-W041", SeverityWarning, "Finality unknown",
+		"The node does not report finalized blocks, so the finality of the execution is unknown.",
+		"An execution that is not final can still be undone by a re-org.",
+		"Use an RPC endpoint that supports the finalized block tag.")
+	warnNotFinalized = defineCheck("OPTX-W042", SeverityWarning, "Execution not finalized",
+		"The execution is confirmed but its block is not finalized yet.",
+		"A re-org could still undo its effects.",
+		"Wait for the block to be finalized before attesting to the execution.")
+	warnExecutionFailure = defineCheck("OPTX-W043", SeverityCritical, "Safe reported ExecutionFailure",
+		"The Safe emitted ExecutionFailure: the transaction was executed, but its call reverted.",
+		"The nonce is used up but nothing the transaction was meant to do took effect.",
+		"Find out why the call reverted before proposing it again.")
+	warnEventMismatch = defineCheck("OPTX-W044", SeverityCritical, "Event differs from the transaction",
+		"The SafeMultiSigTransaction event of the execution does not match the verified transaction.",
+		"The Safe executed something other than what was verified.",
+		"Investigate the execution; the verified transaction is not what ran.")
+	warnWrongExecution = defineCheck("OPTX-W045", SeverityCritical, "Receipt executed another transaction",
+		"The execution receipt has no ExecutionSuccess or ExecutionFailure event for the verified safeTxHash.",
+		"The execution transaction ran a different Safe transaction.",
+		"Investigate the execution; the verified transaction is not what ran.")
+	warnMissingEffect = defineCheck("OPTX-W046", SeverityWarning, "Expected effect missing",
+		"The calldata makes a transfer or approval that no event of the execution shows.",
+		"The transaction did not do everything it was verified to do.",
+		"Check the token contract and the receipt.")
+	warnUnexpectedEffect = defineCheck("OPTX-W047", SeverityWarning, "Unexpected effect",
+		"An event of the execution shows a transfer or approval the calldata does not make.",
+		"The transaction did more than it was verified to do, such as through a hook or a malicious token.",
+		"Check the contracts the transaction called and the receipt.")
+)
+
+// Checks of ERC-4337 UserOperations
+var (
+	warnNonCanonicalModule = defineCheck("OPTX-W048", SeverityWarning, "Unknown Safe4337Module",
+		"The UserOperation's module is not the canonical Safe4337Module for its EntryPoint.",
+		"The hashes assume a module version that may not be the one deployed, and an unknown module may do anything.",
+		"Check the module address against the Safe's enabled modules and Safe's published deployments.")
+	warnNotExecuteUserOp = defineCheck("OPTX-W049", SeverityCritical, "UserOperation calls the wrong function",
+		"The UserOperation's callData does not call executeUserOp or executeUserOpWithErrorString.",
+		"Safe4337Module rejects it, so it cannot be what the signers are meant to approve.",
+		"Do not sign. Have the UserOperation built again.")
+	warnUserOpDelegatecall = defineCheck("OPTX-W050", SeverityWarning, "UserOperation delegatecalls",
+		"The UserOperation makes the Safe DELEGATECALL a contract.",
+		"The contract's code runs with full control of the Safe.",
+		"Check that the contract is a trusted library, such as MultiSendCallOnly.")
+	warnUnknownOperation = defineCheck("OPTX-W051", SeverityCritical, "Unknown operation type",
+		"The UserOperation uses an operation type other than CALL or DELEGATECALL.",
+		"Its effect cannot be verified.",
+		"Do not sign.")
+	warnFactoryDeploy = defineCheck("OPTX-W052", SeverityWarning, "UserOperation deploys the Safe",
+		"The UserOperation deploys the Safe through a factory.",
+		"The factory and its setup data decide the Safe's owners and modules.",
+		"Check the factory and that its setup data create the Safe with the intended owners.")
+	warnPaymaster = defineCheck("OPTX-W053", SeverityWarning, "Paymaster pays for gas",
+		"Gas for the UserOperation is handled by a paymaster.",
+		"Token paymasters charge the Safe in ERC-20 tokens, which needs an approval that can be abused.",
+		"Check the paymaster, and that the Safe has not approved it for more than expected.")
+	warnNoPaymaster = defineCheck("OPTX-W054", SeverityInfo, "Safe pays for gas",
+		"The UserOperation has no paymaster, so the Safe pays for its gas in ETH.",
+		"The Safe pays up to the amount shown.",
+		"Check the gas limits and fees are reasonable.")
+	warnPriorityFee = defineCheck("OPTX-W055", SeverityWarning, "Priority fee above max fee",
+		"maxPriorityFeePerGas is above maxFeePerGas.",
+		"The UserOperation cannot be included as it is.",
+		"Have the UserOperation built again with consistent fees.")
+	warnNeverExpires = defineCheck("OPTX-W056", SeverityInfo, "UserOperation never expires",
+		"The UserOperation has no validUntil.",
+		"Once signed it can be submitted at any time until its nonce is used.",
+		"Set a validUntil if the operation should only be valid for a while.")
+	warnNeverValid = defineCheck("OPTX-W057", SeverityWarning, "UserOperation can never be included",
+		"validAfter is later than validUntil.",
+		"The UserOperation can never be included.",
+		"Have the UserOperation built again with a valid window.")
+	warnValidityMismatch = defineCheck("OPTX-W058", SeverityCritical, "Signature is for another window",
+		"The signature carries a validity window other than the request's.",
+		"The existing signatures are for a different SafeOp.",
+		"Do not add a signature until the window is agreed and the request rebuilt.")
+)
+
+// Checks of the signer's own setup
+var (
+	warnKeystoreKDF = defineCheck("OPTX-W059", SeverityWarning, "Keystore is not scrypt",
+		"The keystore uses a key derivation function other than scrypt.",
+		"The passphrase is cheaper to brute force if the file leaks.",
+		"Re-encrypt the key with scrypt and the standard parameters.")
+	warnKeystoreScrypt = defineCheck("OPTX-W060", SeverityWarning, "Keystore uses light scrypt",
+		"The keystore uses scrypt with parameters lighter than the standard ones.",
+		"The passphrase is cheaper to brute force if the file leaks.",
+		"Re-encrypt the key with the standard scrypt parameters.")
+	warnUnreproducibleBuild = defineCheck("OPTX-W061", SeverityWarning, "Build is not reproducible",
+		"The binary was built with settings that differ from the release builds.",
+		"It cannot be reproduced bit for bit, so it cannot be checked against its source.",
+		"Use a release binary, or build with the release settings.")
+	warnDirtyBuild = defineCheck("OPTX-W062", SeverityWarning, "Built from uncommitted changes",
+		"The binary was built from a working tree with uncommitted changes.",
+		"Its source does not match any commit, so it cannot be reviewed.",
+		"Use a release binary, or build from a clean checkout.")
+	warnNoRevision = defineCheck("OPTX-W063", SeverityWarning, "No source revision",
+		"The binary embeds no VCS revision.",
+		"The commit it was built from cannot be checked.",
+		"Use a release binary, or build from a git checkout.")
+	warnCommitMismatch = defineCheck("OPTX-W064", SeverityCritical, "Stamped commit differs",
+		"The commit stamped into the binary differs from the VCS revision it embeds.",
+		"The binary misreports where it came from, which a tampered build might do.",
+		"Do not trust this binary. Use a release binary whose checksum you verified.")
+)
+
+// Checks of the links a transaction is shared in
+var (
+	warnUncheckedLink = defineCheck("OPTX-W065", SeverityWarning, "Link has no checksum",
+		"The transaction was read from a link or payload without a sum parameter.",
+		"Links made by op-txverify share carry the keccak256 hash of the transaction, which catches a payload truncated or altered in transit. Without it, such a change goes unnoticed.",
+		"Compare the Safe tx hash with the one the sender sees, or ask for a link made with op-txverify share.")
+)
@@ -0,0 +1,52 @@
+package core
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestWarningChecksAreComplete(t *testing.T) {
+	checks := WarningChecks()
+	for i, check := range checks {
+		// Codes are numbered without gaps, so a new check takes the next number
+		if want := fmt.Sprintf("OPTX-W%03d", i+1); check.Code != want {
+			t.Errorf("check %d has code %s, want %s", i, check.Code, want)
+		}
+		if check.Title == "" || check.Meaning == "" || check.Why == "" || check.Action == "" {
+			t.Errorf("%s is not fully explained: %+v", check.Code, check)
+		}
+		switch check.Severity {
+		case SeverityInfo, SeverityWarning, SeverityCritical:
+		default:
+			t.Errorf("%s has severity %q", check.Code, check.Severity)
+		}
+	}
+}
+
+func TestLookupWarningCheck(t *testing.T) {
+	for _, code := range []string{"OPTX-W007", "optx-w007", "W007", "w7", " OPTX-W7 "} {
+		check, err := LookupWarningCheck(code)
+		if err != nil || check.Code != "OPTX-W007" || check.Severity != SeverityWarning {
+			t.Errorf("%q: got %+v, %v", code, check, err)
+		}
+	}
+	for _, code := range []string{"", "OPTX-W999", "OPTX-E001", "7", "W-7"} {
+		if _, err := LookupWarningCheck(code); err == nil {
+			t.Errorf("%q: expected an error", code)
+		}
+	}
+
+	// The returned check is a copy; the catalog cannot be changed through it
+	check, _ := LookupWarningCheck("W1")
+	check.Severity = SeverityInfo
+	if warnServiceHashMismatch.Severity != SeverityCritical {
+		t.Error("expected the catalog to be unchanged")
+	}
+}
+
+func TestWarningsCarryTheirCheck(t *testing.T) {
+	warning := newWarning(warnDenylisted, "Address %s is on the denylist", airdropAlice)
+	if warning.Code != "OPTX-W011" || warning.Severity != SeverityCritical {
+		t.Errorf("unexpected warning %+v", warning)
+	}
+}
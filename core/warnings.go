@@ -0,0 +1,86 @@
+package core
+
+import "fmt"
+
+// Severity describes how serious a verification warning is
+type Severity string
+
+// Warning severities, from least to most serious
+const (
+	SeverityInfo     Severity = "info"
+	SeverityWarning  Severity = "warning"
+	SeverityCritical Severity = "critical"
+)
+
+// Warning is a finding raised while verifying a transaction that the signer must review. Its code
+// names the check that raised it, which `op-txverify explain` describes.
+type Warning struct {
+	Code     string   `json:"code,omitempty"`
+	Severity Severity `json:"severity"`
+	Message  string   `json:"message"`
+}
+
+// newWarning creates a warning of a check with a formatted message
+func newWarning(check *WarningCheck, format string, args ...interface{}) Warning {
+	return Warning{Code: check.Code, Severity: check.Severity, Message: fmt.Sprintf(format, args...)}
+}
+
+// HasCritical reports whether any of the warnings is critical
+func HasCritical(warnings []Warning) bool {
+	for _, warning := range warnings {
+		if warning.Severity == SeverityCritical {
+			return true
+		}
+	}
+	return false
+}
+
+// RefuseCritical returns an ErrPolicyViolation naming the action refused when any of the warnings
+// is critical, so that nothing is signed for a transaction that failed verification
+func RefuseCritical(action string, warnings []Warning) error {
+	if !HasCritical(warnings) {
+		return nil
+	}
+	return withKind(ErrPolicyViolation, fmt.Errorf("refusing to %s: verification raised a critical warning", action))
+}
+
+// FailOnNever is the --fail-on threshold that never fails a command because of its warnings
+const FailOnNever = "never"
+
+// severityRanks orders the severities from least to most serious
+var severityRanks = map[Severity]int{
+	SeverityInfo:     1,
+	SeverityWarning:  2,
+	SeverityCritical: 3,
+}
+
+// ParseFailOn parses a failure threshold: "warning", "critical", or "never". It returns the least
+// severe warning that fails a command, or "" when no warning does.
+func ParseFailOn(value string) (Severity, error) {
+	switch value {
+	case string(SeverityWarning), string(SeverityCritical):
+		return Severity(value), nil
+	case FailOnNever, "":
+		return "", nil
+	}
+	return "", fmt.Errorf("unknown failure threshold %q: use warning, critical, or never", value)
+}
+
+// CheckFailOn returns an ErrPolicyViolation when any of the warnings is at least as severe as the
+// threshold, so that pipelines can stop on findings a person would review. An empty threshold
+// never fails.
+func CheckFailOn(threshold Severity, warnings []Warning) error {
+	if threshold == "" {
+		return nil
+	}
+	count := 0
+	for _, warning := range warnings {
+		if severityRanks[warning.Severity] >= severityRanks[threshold] {
+			count++
+		}
+	}
+	if count == 0 {
+		return nil
+	}
+	return withKind(ErrPolicyViolation, fmt.Errorf("verification raised %d warning(s) of severity %s or above", count, threshold))
+}
@@ -0,0 +1,43 @@
+package core
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCheckFailOn(t *testing.T) {
+	warnings := []Warning{newWarning(warnSelfCall, "minor"), newWarning(warnSelfCall, "minor again")}
+	critical := append(warnings, newWarning(warnDenylisted, "bad"))
+
+	for _, test := range []struct {
+		failOn   string
+		warnings []Warning
+		fails    bool
+	}{
+		{"never", critical, false},
+		{"", critical, false},
+		{"critical", warnings, false},
+		{"critical", critical, true},
+		{"warning", warnings, true},
+		{"warning", nil, false},
+	} {
+		threshold, err := ParseFailOn(test.failOn)
+		if err != nil {
+			t.Fatalf("%q: unexpected error: %v", test.failOn, err)
+		}
+		err = CheckFailOn(threshold, test.warnings)
+		if test.fails != (err != nil) {
+			t.Errorf("--fail-on %q with %d warning(s): got %v", test.failOn, len(test.warnings), err)
+		}
+		if err != nil && !errors.Is(err, ErrPolicyViolation) {
+			t.Errorf("expected ErrPolicyViolation, got %v", err)
+		}
+	}
+
+	if err := CheckFailOn(SeverityWarning, critical); err == nil || err.Error() != "verification raised 3 warning(s) of severity warning or above" {
+		t.Errorf("unexpected error %v", err)
+	}
+	if _, err := ParseFailOn("info"); err == nil {
+		t.Error("expected an unknown threshold to be rejected")
+	}
+}
@@ -0,0 +1,241 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// L2ToL1MessagePasser is the L2 predeploy that commits to every withdrawal initiated on the chain
+const L2ToL1MessagePasser = "0x4200000000000000000000000000000000000016"
+
+// withdrawalABIJSON contains the OptimismPortal entry points that prove and finalize withdrawals.
+// Before fault proofs, the dispute game index of proveWithdrawalTransaction was the index of an
+// L2 output in the L2OutputOracle; the selector is the same.
+var withdrawalABIJSON = []string{
+	`[{"inputs":[{"components":[{"name":"nonce","type":"uint256"},{"name":"sender","type":"address"},{"name":"target","type":"address"},{"name":"value","type":"uint256"},{"name":"gasLimit","type":"uint256"},{"name":"data","type":"bytes"}],"name":"tx","type":"tuple"},{"name":"disputeGameIndex","type":"uint256"},{"components":[{"name":"version","type":"bytes32"},{"name":"stateRoot","type":"bytes32"},{"name":"messagePasserStorageRoot","type":"bytes32"},{"name":"latestBlockhash","type":"bytes32"}],"name":"outputRootProof","type":"tuple"},{"name":"withdrawalProof","type":"bytes[]"}],"name":"proveWithdrawalTransaction","type":"function"}]`,
+	`[{"inputs":[{"components":[{"name":"nonce","type":"uint256"},{"name":"sender","type":"address"},{"name":"target","type":"address"},{"name":"value","type":"uint256"},{"name":"gasLimit","type":"uint256"},{"name":"data","type":"bytes"}],"name":"tx","type":"tuple"}],"name":"finalizeWithdrawalTransaction","type":"function"}]`,
+	`[{"inputs":[{"components":[{"name":"nonce","type":"uint256"},{"name":"sender","type":"address"},{"name":"target","type":"address"},{"name":"value","type":"uint256"},{"name":"gasLimit","type":"uint256"},{"name":"data","type":"bytes"}],"name":"tx","type":"tuple"},{"name":"proofSubmitter","type":"address"}],"name":"finalizeWithdrawalTransactionExternalProof","type":"function"}]`,
+}
+
+func init() {
+	registerKnownABIs(withdrawalABIJSON)
+}
+
+// PortalL2Chains maps the OptimismPortal deployments on L1, keyed by lowercase address, to the
+// chain they deposit to and whose withdrawals they prove and finalize
+var PortalL2Chains = map[string]uint64{
+	strings.ToLower(OptimismPortal):            OPMainnetChainID,
+	strings.ToLower(BaseOptimismPortal):        BaseMainnetChainID,
+	strings.ToLower(OPSepoliaOptimismPortal):   OPSepoliaChainID,
+	strings.ToLower(BaseSepoliaOptimismPortal): BaseSepoliaChainID,
+}
+
+// portalL1Chains maps each chain in PortalL2Chains to the L1 chain its portal is deployed on
+var portalL1Chains = map[uint64]uint64{
+	OPMainnetChainID:   MainnetChainID,
+	BaseMainnetChainID: MainnetChainID,
+	OPSepoliaChainID:   SepoliaChainID,
+	BaseSepoliaChainID: SepoliaChainID,
+}
+
+// portalL2Chain returns the L2 chain of the OptimismPortal at address on chainID, if it is one
+func portalL2Chain(address string, chainID uint64) (uint64, bool) {
+	l2ChainID, ok := PortalL2Chains[strings.ToLower(address)]
+	if !ok || portalL1Chains[l2ChainID] != chainID {
+		return 0, false
+	}
+	return l2ChainID, true
+}
+
+// sentMessagesSelector is the selector of L2ToL1MessagePasser.sentMessages(bytes32)
+var sentMessagesSelector = crypto.Keccak256([]byte("sentMessages(bytes32)"))[:4]
+
+// withdrawalHashArguments is the encoding the withdrawal hash is computed over
+var withdrawalHashArguments = abi.Arguments{
+	{Type: mustABIType("uint256")}, {Type: mustABIType("address")}, {Type: mustABIType("address")},
+	{Type: mustABIType("uint256")}, {Type: mustABIType("uint256")}, {Type: mustABIType("bytes")},
+}
+
+// Withdrawal is an L2 to L1 withdrawal that a transaction proves or finalizes, with its hash
+// recomputed from the parameters the call supplies
+type Withdrawal struct {
+	Call     string   `json:"call"`
+	Function string   `json:"function"`
+	Portal   string   `json:"portal"`
+	Nonce    *big.Int `json:"nonce"`
+	Sender   string   `json:"sender"`
+	Target   string   `json:"target"`
+	Value    *big.Int `json:"value"`
+	GasLimit *big.Int `json:"gasLimit"`
+	Data     string   `json:"data"`
+
+	// WithdrawalHash is the hash the portal records the withdrawal under, and StorageSlot the
+	// slot of L2ToL1MessagePasser.sentMessages that commits to it
+	WithdrawalHash string `json:"withdrawalHash"`
+	StorageSlot    string `json:"storageSlot"`
+
+	// The output root and whether the storage proof shows the withdrawal in the message passer's
+	// storage root are only known for proveWithdrawalTransaction
+	DisputeGameIndex         *big.Int `json:"disputeGameIndex,omitempty"`
+	OutputRoot               string   `json:"outputRoot,omitempty"`
+	MessagePasserStorageRoot string   `json:"messagePasserStorageRoot,omitempty"`
+	ProofVerified            *bool    `json:"proofVerified,omitempty"`
+
+	// Committed is whether L2ToL1MessagePasser on the L2 chain has the withdrawal, or nil when
+	// no L2 node was asked
+	Committed *bool `json:"committed,omitempty"`
+
+	Problems []string `json:"problems,omitempty"`
+}
+
+// WithdrawalCheck is the result of recomputing the withdrawals a transaction proves or finalizes
+type WithdrawalCheck struct {
+	Withdrawals []Withdrawal `json:"withdrawals"`
+}
+
+// OK reports whether every withdrawal's proof and commitment checked out
+func (c *WithdrawalCheck) OK() bool {
+	for _, withdrawal := range c.Withdrawals {
+		if len(withdrawal.Problems) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// CheckWithdrawals recomputes the hash of every withdrawal a transaction proves or finalizes
+// through an OptimismPortal. For a proof, it also computes the output root and verifies the
+// storage proof against the message passer storage root the call supplies, so a proof for a
+// different withdrawal than the parameters describe is caught without a node.
+func CheckWithdrawals(tx SafeTransaction) (*WithdrawalCheck, error) {
+	calls, err := batchCalls(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	check := &WithdrawalCheck{Withdrawals: []Withdrawal{}}
+	for i, call := range calls {
+		if call.Operation != 0 || len(call.Data) < 4 {
+			continue
+		}
+		functionInfo, ok := KnownFunctions[hex.EncodeToString(call.Data[:4])]
+		if !ok || !strings.Contains(functionInfo.Name, "WithdrawalTransaction") {
+			continue
+		}
+		values, err := functionInfo.ABI.Inputs.Unpack(call.Data[4:])
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s call: %w", functionInfo.Name, err)
+		}
+		args := make(map[string]interface{}, len(values))
+		for j, value := range values {
+			args[functionInfo.ABI.Inputs[j].Name] = value
+		}
+
+		withdrawal := Withdrawal{Call: "The transaction", Function: functionInfo.Name, Portal: call.To.Hex()}
+		if len(calls) > 1 {
+			withdrawal.Call = fmt.Sprintf("Call #%d", i+1)
+		}
+		if err := withdrawal.recompute(args); err != nil {
+			return nil, fmt.Errorf("invalid %s call: %w", functionInfo.Name, err)
+		}
+		check.Withdrawals = append(check.Withdrawals, withdrawal)
+	}
+	if len(check.Withdrawals) == 0 {
+		return nil, fmt.Errorf("the transaction does not prove or finalize a withdrawal")
+	}
+	return check, nil
+}
+
+// recompute reads the withdrawal from a call's arguments and computes its hash, and for a proof
+// its output root and whether the storage proof holds
+func (w *Withdrawal) recompute(args map[string]interface{}) error {
+	var sender, target common.Address
+	var data []byte
+	if err := tupleFields(args["tx"], map[string]interface{}{
+		"nonce": &w.Nonce, "sender": &sender, "target": &target, "value": &w.Value, "gasLimit": &w.GasLimit, "data": &data,
+	}); err != nil {
+		return err
+	}
+	w.Sender, w.Target, w.Data = sender.Hex(), target.Hex(), "0x"+hex.EncodeToString(data)
+
+	encoded, err := withdrawalHashArguments.Pack(w.Nonce, sender, target, w.Value, w.GasLimit, data)
+	if err != nil {
+		return err
+	}
+	hash := crypto.Keccak256Hash(encoded)
+	slot := withdrawalStorageSlot(hash)
+	w.WithdrawalHash, w.StorageSlot = hash.Hex(), slot.Hex()
+
+	if w.Function != "proveWithdrawalTransaction" {
+		return nil
+	}
+	if w.DisputeGameIndex, err = argValue[*big.Int](args, "disputeGameIndex"); err != nil {
+		return err
+	}
+	var version, stateRoot, storageRoot, blockHash [32]byte
+	if err := tupleFields(args["outputRootProof"], map[string]interface{}{
+		"version": &version, "stateRoot": &stateRoot, "messagePasserStorageRoot": &storageRoot, "latestBlockhash": &blockHash,
+	}); err != nil {
+		return err
+	}
+	w.OutputRoot = crypto.Keccak256Hash(version[:], stateRoot[:], storageRoot[:], blockHash[:]).Hex()
+	w.MessagePasserStorageRoot = common.Hash(storageRoot).Hex()
+	if version != ([32]byte{}) {
+		w.Problems = append(w.Problems, fmt.Sprintf("the output root proof has version %s, only version 0 is known", common.Hash(version).Hex()))
+	}
+
+	proof, err := argValue[[][]byte](args, "withdrawalProof")
+	if err != nil {
+		return err
+	}
+	verified := verifyWithdrawalProof(storageRoot, slot, proof)
+	w.ProofVerified = &verified
+	if !verified {
+		w.Problems = append(w.Problems, "the withdrawal proof does not show the withdrawal hash in the message passer storage root")
+	}
+	return nil
+}
+
+// withdrawalStorageSlot returns the slot of sentMessages[hash] in L2ToL1MessagePasser, whose
+// sentMessages mapping is at slot 0
+func withdrawalStorageSlot(hash common.Hash) common.Hash {
+	return crypto.Keccak256Hash(hash[:], make([]byte, 32))
+}
+
+// verifyWithdrawalProof reports whether a Merkle-Patricia proof shows a storage slot set to true
+// in a storage root, as OptimismPortal checks it
+func verifyWithdrawalProof(storageRoot common.Hash, slot common.Hash, proof [][]byte) bool {
+	value, err := walkTrieProof(storageRoot, crypto.Keccak256(slot[:]), proof)
+	// The value is RLP encoded, and true is the single byte 0x01
+	return err == nil && bytes.Equal(value, []byte{0x01})
+}
+
+// CheckWithdrawalCommitments asks an L2 node whether L2ToL1MessagePasser committed to each
+// withdrawal, that is whether it was initiated on L2 as the parameters describe
+func CheckWithdrawalCommitments(ctx context.Context, client *RPCClient, check *WithdrawalCheck) error {
+	for i := range check.Withdrawals {
+		withdrawal := &check.Withdrawals[i]
+		data := append(append([]byte{}, sentMessagesSelector...), common.HexToHash(withdrawal.WithdrawalHash).Bytes()...)
+		result, err := client.Call(ctx, L2ToL1MessagePasser, data, "latest")
+		if err != nil {
+			return fmt.Errorf("failed to read the L2ToL1MessagePasser commitment: %w", err)
+		}
+		if len(result) != 32 {
+			return fmt.Errorf("unexpected sentMessages result %x", result)
+		}
+		committed := new(big.Int).SetBytes(result).Sign() != 0
+		withdrawal.Committed = &committed
+		if !committed {
+			withdrawal.Problems = append(withdrawal.Problems, "L2ToL1MessagePasser on L2 has no withdrawal with this hash")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+package core
+
+import (
+	"context"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+const (
+	proveWithdrawalSig    = "proveWithdrawalTransaction((uint256,address,address,uint256,uint256,bytes),uint256,(bytes32,bytes32,bytes32,bytes32),bytes[])"
+	finalizeWithdrawalSig = "finalizeWithdrawalTransaction((uint256,address,address,uint256,uint256,bytes))"
+)
+
+// withdrawalTx is the withdrawal parameters as the portal takes them
+type withdrawalTx struct {
+	Nonce    *big.Int
+	Sender   common.Address
+	Target   common.Address
+	Value    *big.Int
+	GasLimit *big.Int
+	Data     []byte
+}
+
+// outputRootProof is the output root preimage proveWithdrawalTransaction takes
+type outputRootProof struct {
+	Version                  [32]byte
+	StateRoot                [32]byte
+	MessagePasserStorageRoot [32]byte
+	LatestBlockhash          [32]byte
+}
+
+// messagePasserProof returns a recorded proof of a withdrawal's slot in a message passer storage
+// trie: "message passer" holds the slots of the withdrawal in TestCheckWithdrawals and of hashes
+// 0x01 and 0x02, and "other message passer" those of 0x01 and the withdrawal
+func messagePasserProof(t *testing.T, trie string, hash common.Hash) (common.Hash, [][]byte) {
+	t.Helper()
+	slot := withdrawalStorageSlot(hash)
+	return proveKey(t, trie, crypto.Keccak256(slot[:]))
+}
+
+func TestCheckWithdrawals(t *testing.T) {
+	withdrawal := withdrawalTx{
+		Nonce:    new(big.Int).Add(new(big.Int).Lsh(big.NewInt(1), 240), big.NewInt(42)),
+		Sender:   common.HexToAddress(airdropAlice),
+		Target:   common.HexToAddress(airdropBob),
+		Value:    big.NewInt(1e18),
+		GasLimit: big.NewInt(100000),
+		Data:     []byte{0xde, 0xad},
+	}
+	// The withdrawal hash is keccak256(abi.encode(nonce, sender, target, value, gasLimit, data))
+	var encoded []byte
+	for _, word := range [][]byte{
+		math.U256Bytes(new(big.Int).Set(withdrawal.Nonce)),
+		common.LeftPadBytes(withdrawal.Sender.Bytes(), 32),
+		common.LeftPadBytes(withdrawal.Target.Bytes(), 32),
+		math.U256Bytes(big.NewInt(1e18)),
+		math.U256Bytes(big.NewInt(100000)),
+		math.U256Bytes(big.NewInt(0xc0)),
+		math.U256Bytes(big.NewInt(2)),
+		common.RightPadBytes([]byte{0xde, 0xad}, 32),
+	} {
+		encoded = append(encoded, word...)
+	}
+	hash := crypto.Keccak256Hash(encoded)
+
+	storageRoot, proof := messagePasserProof(t, "message passer", hash)
+	output := outputRootProof{StateRoot: common.HexToHash("0xaa"), MessagePasserStorageRoot: storageRoot, LatestBlockhash: common.HexToHash("0xbb")}
+	portal := common.HexToAddress(OptimismPortal)
+	tx := multiSendTx(t,
+		multiSendTransaction{To: portal, Value: big.NewInt(0), Data: common.FromHex(encodeKnownCall(t, proveWithdrawalSig, withdrawal, big.NewInt(7), output, proof))},
+		multiSendTransaction{To: portal, Value: big.NewInt(0), Data: common.FromHex(encodeKnownCall(t, finalizeWithdrawalSig, withdrawal))},
+	)
+
+	check, err := CheckWithdrawals(tx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(check.Withdrawals) != 2 || !check.OK() {
+		t.Fatalf("expected two withdrawals without problems, got %+v", check)
+	}
+	prove, finalize := check.Withdrawals[0], check.Withdrawals[1]
+	if prove.WithdrawalHash != hash.Hex() || finalize.WithdrawalHash != hash.Hex() {
+		t.Errorf("expected withdrawal hash %s, got %s and %s", hash.Hex(), prove.WithdrawalHash, finalize.WithdrawalHash)
+	}
+	if prove.Call != "Call #1" || finalize.Function != "finalizeWithdrawalTransaction" || finalize.Target != withdrawal.Target.Hex() {
+		t.Errorf("unexpected withdrawals %+v", check.Withdrawals)
+	}
+	wantRoot := crypto.Keccak256Hash(make([]byte, 32), output.StateRoot[:], storageRoot[:], output.LatestBlockhash[:])
+	if prove.OutputRoot != wantRoot.Hex() || prove.DisputeGameIndex.Int64() != 7 {
+		t.Errorf("expected output root %s at game 7, got %s at %v", wantRoot.Hex(), prove.OutputRoot, prove.DisputeGameIndex)
+	}
+	if prove.ProofVerified == nil || !*prove.ProofVerified || finalize.ProofVerified != nil || finalize.OutputRoot != "" {
+		t.Errorf("expected only the proof to be verified, got %v and %v", prove.ProofVerified, finalize.ProofVerified)
+	}
+
+	// A proof for a different withdrawal does not show these parameters
+	otherRoot, otherProof := messagePasserProof(t, "other message passer", common.HexToHash("0x01"))
+	output.MessagePasserStorageRoot = otherRoot
+	tx = multiSendTx(t, multiSendTransaction{To: portal, Value: big.NewInt(0), Data: common.FromHex(encodeKnownCall(t, proveWithdrawalSig, withdrawal, big.NewInt(7), output, otherProof))})
+	check, err = CheckWithdrawals(tx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if check.OK() || !strings.Contains(check.Withdrawals[0].Problems[0], "does not show the withdrawal hash") {
+		t.Errorf("expected the proof to fail, got %+v", check.Withdrawals[0])
+	}
+
+	if _, err := CheckWithdrawals(multiSendTx(t, erc20Transfer(airdropToken, airdropBob, big.NewInt(1)))); err == nil {
+		t.Error("expected an error for a transaction without withdrawals")
+	}
+}
+
+func TestCheckWithdrawalCommitments(t *testing.T) {
+	committed := common.HexToHash("0x01")
+	missing := common.HexToHash("0x02")
+	call := func(hash common.Hash) string {
+		return strings.ToLower(L2ToL1MessagePasser) + ":" + hexutil.Encode(append(append([]byte{}, sentMessagesSelector...), hash[:]...))
+	}
+	client := newFakeNode(t, &fakeNode{chainID: OPMainnetChainID, calls: map[string]hexutil.Bytes{
+		call(committed): common.LeftPadBytes([]byte{1}, 32),
+		call(missing):   make([]byte, 32),
+	}})
+
+	check := &WithdrawalCheck{Withdrawals: []Withdrawal{{WithdrawalHash: committed.Hex()}, {WithdrawalHash: missing.Hex()}}}
+	if err := CheckWithdrawalCommitments(context.Background(), client, check); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if first := check.Withdrawals[0]; first.Committed == nil || !*first.Committed || len(first.Problems) != 0 {
+		t.Errorf("expected the first withdrawal to be committed, got %+v", first)
+	}
+	if second := check.Withdrawals[1]; second.Committed == nil || *second.Committed || len(second.Problems) != 1 {
+		t.Errorf("expected the second withdrawal to be missing, got %+v", second)
+	}
+}
@@ -0,0 +1,288 @@
+// Package doctor checks that a machine is ready for a signing ceremony. It is used by the command
+// line tool and lives outside core, which only verifies transactions.
+package doctor
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ethereum-optimism/op-txverify/core"
+)
+
+// DefaultMaxClockSkew is how far the local clock may be from a server's before schedules,
+// deadlines, and block ages checked during a ceremony can no longer be trusted
+const DefaultMaxClockSkew = time.Minute
+
+// DefaultMaxRegistryAge is how long ago the registry cache may have been synced before its
+// labels and hashes are considered stale
+const DefaultMaxRegistryAge = 30 * 24 * time.Hour
+
+// probeTimeout bounds each request made to check that a host is reachable
+const probeTimeout = 10 * time.Second
+
+// Status is the outcome of one readiness check
+type Status string
+
+// Readiness check outcomes. Only a failed check makes the environment not ready.
+const (
+	Pass Status = "pass"
+	Warn Status = "warn"
+	Fail Status = "fail"
+	Skip Status = "skip"
+)
+
+// Check is one thing checked about the environment a ceremony runs in
+type Check struct {
+	Name   string `json:"name"`
+	Status Status `json:"status"`
+	Detail string `json:"detail"`
+}
+
+// Report is the readiness of a machine for a signing ceremony
+type Report struct {
+	Version   string    `json:"version"`
+	Commit    string    `json:"commit"`
+	CheckedAt time.Time `json:"checkedAt"`
+	Offline   bool      `json:"offline"`
+	Checks    []Check   `json:"checks"`
+}
+
+// Add records the outcome of a check
+func (r *Report) Add(name string, status Status, format string, args ...interface{}) {
+	r.Checks = append(r.Checks, Check{Name: name, Status: status, Detail: fmt.Sprintf(format, args...)})
+}
+
+// Count returns how many checks had a status
+func (r *Report) Count(status Status) int {
+	count := 0
+	for _, check := range r.Checks {
+		if check.Status == status {
+			count++
+		}
+	}
+	return count
+}
+
+// Ready reports whether no check failed
+func (r *Report) Ready() bool {
+	return r.Count(Fail) == 0
+}
+
+// Options selects what Run checks
+type Options struct {
+	Version string
+	Commit  string
+
+	// Offline is set on air-gapped machines, where every host must be unreachable
+	Offline bool
+
+	// Hosts are the URLs the ceremony needs, such as the Safe services and RPC endpoints
+	Hosts []string
+
+	MaxClockSkew time.Duration
+
+	// RegistryCache is the registry cache to check, or "" to skip the check
+	RegistryCache  string
+	MaxRegistryAge time.Duration
+
+	// Sums is a SHA256SUMS file the running executable must be listed in, or "" to only report
+	// its digest
+	Sums string
+}
+
+// Run checks that the machine is ready for a signing ceremony: the hosts it needs are
+// reachable (or, offline, that none are), its clock is right, the binary is what it claims to be,
+// and the registry cache is fresh. The camera and terminal are checked by the packages that use
+// them, which add to the report.
+func Run(ctx context.Context, options Options) *Report {
+	report := &Report{
+		Version:   options.Version,
+		Commit:    options.Commit,
+		CheckedAt: time.Now().UTC(),
+		Offline:   options.Offline,
+	}
+	skew, measured := checkHosts(ctx, report, options)
+	checkClock(report, options, skew, measured)
+	checkBinary(report, options)
+	checkRegistryCache(report, options)
+	return report
+}
+
+// checkHosts probes each host and returns the clock skew against the first that sent its time
+func checkHosts(ctx context.Context, report *Report, options Options) (time.Duration, string) {
+	var skew time.Duration
+	var measured string
+	for _, host := range options.Hosts {
+		name := host
+		if parsed, err := url.Parse(host); err == nil {
+			name = parsed.Redacted()
+		}
+		status, latency, serverTime, err := probeHost(ctx, host)
+		switch {
+		case err != nil && options.Offline:
+			report.Add("network", Pass, "%s is unreachable, as expected offline", name)
+		case err != nil:
+			report.Add("network", Fail, "%s is unreachable: %v", name, err)
+		case options.Offline:
+			report.Add("network", Fail, "%s is reachable (HTTP %d), but this machine should be offline", name, status)
+		default:
+			report.Add("network", Pass, "%s answered HTTP %d in %s", name, status, latency.Round(time.Millisecond))
+		}
+		if err == nil && measured == "" && !serverTime.IsZero() {
+			skew = time.Since(serverTime)
+			measured = name
+		}
+	}
+	if len(options.Hosts) == 0 {
+		report.Add("network", Skip, "no hosts to check")
+	}
+	return skew, measured
+}
+
+// probeHost sends a HEAD request to a host. Any HTTP response means it is reachable; its Date
+// header, when present, is the server's time.
+func probeHost(ctx context.Context, host string) (int, time.Duration, time.Time, error) {
+	ctx, cancel := context.WithTimeout(ctx, probeTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, host, nil)
+	if err != nil {
+		return 0, 0, time.Time{}, err
+	}
+	start := time.Now()
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return 0, 0, time.Time{}, err
+	}
+	resp.Body.Close()
+	latency := time.Since(start)
+
+	// The Date header is whole seconds and was written while the request was in flight
+	serverTime, err := http.ParseTime(resp.Header.Get("Date"))
+	if err != nil {
+		return resp.StatusCode, latency, time.Time{}, nil
+	}
+	return resp.StatusCode, latency, serverTime.Add(latency / 2), nil
+}
+
+// checkClock compares the local clock with a server's
+func checkClock(report *Report, options Options, skew time.Duration, measured string) {
+	maxSkew := options.MaxClockSkew
+	if maxSkew <= 0 {
+		maxSkew = DefaultMaxClockSkew
+	}
+	switch {
+	case options.Offline:
+		report.Add("clock", Skip, "no time source offline; compare %s with a trusted clock by hand", time.Now().UTC().Format(time.RFC3339))
+	case measured == "":
+		report.Add("clock", Warn, "no host sent its time, so the clock was not checked")
+	case skew > maxSkew || skew < -maxSkew:
+		report.Add("clock", Fail, "the clock is %s %s, more than %s; fix the system clock", formatSkew(skew), measured, maxSkew)
+	default:
+		report.Add("clock", Pass, "the clock is %s %s", formatSkew(skew), measured)
+	}
+}
+
+// formatSkew describes a clock skew to the second, with its direction, such as "5s ahead of"
+func formatSkew(skew time.Duration) string {
+	skew = skew.Round(time.Second)
+	switch {
+	case skew > 0:
+		return skew.String() + " ahead of"
+	case skew < 0:
+		return (-skew).String() + " behind"
+	}
+	return "within a second of"
+}
+
+// checkBinary checks the build provenance of the running executable and, given a SHA256SUMS
+// file, that the executable is listed in it
+func checkBinary(report *Report, options Options) {
+	provenance, err := core.ReadBuildProvenance(options.Version, options.Commit)
+	if err != nil {
+		report.Add("binary", Fail, "%v", err)
+		return
+	}
+	if provenance.ExecutableDigest == "" {
+		report.Add("binary", Fail, "the running executable could not be read to compute its digest")
+		return
+	}
+
+	if options.Sums != "" {
+		name, err := findSum(options.Sums, provenance.ExecutableDigest)
+		if err != nil {
+			report.Add("binary", Fail, "%v", err)
+		} else {
+			report.Add("binary", Pass, "sha256 %s matches %s in %s", provenance.ExecutableDigest, name, options.Sums)
+		}
+		return
+	}
+
+	for _, warning := range provenance.Warnings {
+		report.Add("binary", Warn, "%s", warning.Message)
+	}
+	report.Add("binary", Pass, "%s (%s), sha256 %s; compare it with the release SHA256SUMS file", provenance.Version, provenance.Commit, provenance.ExecutableDigest)
+}
+
+// findSum returns the file name a SHA256SUMS file lists a digest for
+func findSum(path, digest string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && strings.EqualFold(fields[0], digest) {
+			return strings.TrimPrefix(fields[1], "*"), nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return "", fmt.Errorf("sha256 %s of the running executable is not listed in %s; do not use this binary", digest, path)
+}
+
+// checkRegistryCache checks that the registry cache loads and was synced recently
+func checkRegistryCache(report *Report, options Options) {
+	path := options.RegistryCache
+	if path == "" {
+		report.Add("registry", Skip, "no registry cache location")
+		return
+	}
+	info, err := os.Stat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		report.Add("registry", Warn, "never synced; only built-in labels and hashes are known (run `op-txverify registry sync`)")
+		return
+	}
+	if err != nil {
+		report.Add("registry", Fail, "failed to read registry cache: %v", err)
+		return
+	}
+	registry, err := core.LoadRegistryCache(path)
+	if err != nil {
+		report.Add("registry", Fail, "%v", err)
+		return
+	}
+
+	maxAge := options.MaxRegistryAge
+	if maxAge <= 0 {
+		maxAge = DefaultMaxRegistryAge
+	}
+	age := time.Since(info.ModTime())
+	contents := fmt.Sprintf("%d labels, %d tokens, %d ABIs, %d hashes", len(registry.Labels), len(registry.Tokens), len(registry.ABIs), len(registry.Hashes))
+	if age > maxAge {
+		report.Add("registry", Warn, "synced %s ago, more than %s (%s); run `op-txverify registry sync`", core.FormatCountdown(age), core.FormatCountdown(maxAge), contents)
+		return
+	}
+	report.Add("registry", Pass, "synced %s ago (%s)", core.FormatCountdown(age), contents)
+}
@@ -0,0 +1,140 @@
+package doctor
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum-optimism/op-txverify/core"
+)
+
+// checksNamed returns the checks of a report with a name
+func checksNamed(report *Report, name string) []Check {
+	var checks []Check
+	for _, check := range report.Checks {
+		if check.Name == name {
+			checks = append(checks, check)
+		}
+	}
+	return checks
+}
+
+// clockServer is a host whose clock is offset from the local one
+func clockServer(t *testing.T, offset time.Duration) string {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Date", time.Now().Add(offset).UTC().Format(http.TimeFormat))
+	}))
+	t.Cleanup(server.Close)
+	return server.URL
+}
+
+func TestDoctorHostsAndClock(t *testing.T) {
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+
+	for _, test := range []struct {
+		name    string
+		offline bool
+		hosts   []string
+		network []Status
+		clock   Status
+	}{
+		{"online", false, []string{clockServer(t, 0)}, []Status{Pass}, Pass},
+		{"clock ahead of the server", false, []string{clockServer(t, -10*time.Minute)}, []Status{Pass}, Fail},
+		{"unreachable host", false, []string{closed.URL, clockServer(t, 0)}, []Status{Fail, Pass}, Pass},
+		{"offline", true, []string{closed.URL}, []Status{Pass}, Skip},
+		{"reachable while offline", true, []string{clockServer(t, 0)}, []Status{Fail}, Skip},
+		{"no hosts", false, nil, []Status{Skip}, Warn},
+	} {
+		report := Run(context.Background(), Options{Offline: test.offline, Hosts: test.hosts})
+		network := checksNamed(report, "network")
+		if len(network) != len(test.network) {
+			t.Fatalf("%s: expected %d network checks, got %+v", test.name, len(test.network), network)
+		}
+		for i, check := range network {
+			if check.Status != test.network[i] {
+				t.Errorf("%s: network check %d is %s: %s", test.name, i, check.Status, check.Detail)
+			}
+		}
+		if clock := checksNamed(report, "clock"); len(clock) != 1 || clock[0].Status != test.clock {
+			t.Errorf("%s: unexpected clock check %+v", test.name, clock)
+		}
+		if len(checksNamed(report, "binary")) == 0 {
+			t.Errorf("%s: expected binary checks, got %+v", test.name, report.Checks)
+		}
+	}
+
+	report := Run(context.Background(), Options{Hosts: []string{clockServer(t, -10*time.Minute)}})
+	if report.Ready() {
+		t.Error("expected a skewed clock to make the machine not ready")
+	}
+	if clock := checksNamed(report, "clock")[0]; !strings.HasPrefix(clock.Detail, "the clock is 10m") || !strings.Contains(clock.Detail, "s ahead of http") {
+		t.Errorf("unexpected clock detail %q", clock.Detail)
+	}
+}
+
+func TestDoctorRegistryCache(t *testing.T) {
+	dir := t.TempDir()
+	fresh := filepath.Join(dir, "fresh.json")
+	stale := filepath.Join(dir, "stale.json")
+	invalid := filepath.Join(dir, "invalid.json")
+	for path, data := range map[string]string{fresh: `{"hashes": [{"hash": "0x01", "name": "one"}]}`, stale: `{}`, invalid: `{`} {
+		if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	old := time.Now().Add(-60 * 24 * time.Hour)
+	if err := os.Chtimes(stale, old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, test := range []struct {
+		path   string
+		status Status
+		detail string
+	}{
+		{"", Skip, "no registry cache"},
+		{filepath.Join(dir, "missing.json"), Warn, "never synced"},
+		{fresh, Pass, "1 hashes"},
+		{stale, Warn, "synced 60d ago, more than 30d"},
+		{invalid, Fail, "invalid registry cache"},
+	} {
+		report := Run(context.Background(), Options{RegistryCache: test.path})
+		registry := checksNamed(report, "registry")
+		if len(registry) != 1 || registry[0].Status != test.status || !strings.Contains(registry[0].Detail, test.detail) {
+			t.Errorf("%q: unexpected registry check %+v", test.path, registry)
+		}
+	}
+}
+
+func TestDoctorSums(t *testing.T) {
+	provenance, err := core.ReadBuildProvenance("dev", "unknown")
+	if err != nil || provenance.ExecutableDigest == "" {
+		t.Skipf("no executable digest: %v", err)
+	}
+	dir := t.TempDir()
+	listed := filepath.Join(dir, "listed")
+	unlisted := filepath.Join(dir, "unlisted")
+	other := strings.Repeat("ab", 32)
+	if err := os.WriteFile(listed, []byte(other+"  op-txverify_linux_arm64\n"+provenance.ExecutableDigest+" *op-txverify_linux_amd64\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(unlisted, []byte(other+"  op-txverify_linux_arm64\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	report := Run(context.Background(), Options{Sums: listed})
+	if binary := checksNamed(report, "binary"); len(binary) != 1 || binary[0].Status != Pass || !strings.Contains(binary[0].Detail, "matches op-txverify_linux_amd64") {
+		t.Errorf("unexpected binary check %+v", binary)
+	}
+	report = Run(context.Background(), Options{Sums: unlisted})
+	if binary := checksNamed(report, "binary"); len(binary) != 1 || binary[0].Status != Fail || report.Ready() {
+		t.Errorf("expected an unlisted binary to fail, got %+v", binary)
+	}
+}
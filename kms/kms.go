@@ -0,0 +1,146 @@
+// Package kms provides core.DigestSigner backends for secp256k1 keys held in AWS KMS and Google
+// Cloud KMS. It lives outside core so that core does not depend on the cloud SDKs.
+package kms
+
+import (
+	"context"
+	"encoding/pem"
+	"fmt"
+	"hash/crc32"
+	"strings"
+
+	gcpkms "cloud.google.com/go/kms/apiv1"
+	"cloud.google.com/go/kms/apiv1/kmspb"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	awskms "github.com/aws/aws-sdk-go-v2/service/kms"
+	awskmstypes "github.com/aws/aws-sdk-go-v2/service/kms/types"
+	"google.golang.org/api/option"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// AWSSigner is a core.DigestSigner for an AWS KMS key with key spec ECC_SECG_P256K1
+type AWSSigner struct {
+	KeyID  string
+	Region string
+
+	client *awskms.Client
+}
+
+// NewAWSSigner creates a signer for an AWS KMS key, loading credentials and region the way the
+// AWS CLI does, adjusted by optFns. keyID may be a key ID, alias, or ARN; when no region is
+// configured it is taken from the ARN.
+func NewAWSSigner(ctx context.Context, keyID string, optFns ...func(*config.LoadOptions) error) (*AWSSigner, error) {
+	cfg, err := config.LoadDefaultConfig(ctx, optFns...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS configuration: %w", err)
+	}
+	if cfg.Region == "" {
+		if parts := strings.Split(keyID, ":"); len(parts) > 3 && parts[0] == "arn" {
+			cfg.Region = parts[3]
+		}
+	}
+	if cfg.Region == "" {
+		return nil, fmt.Errorf("AWS region is required for KMS key %s", keyID)
+	}
+	return &AWSSigner{
+		KeyID:  keyID,
+		Region: cfg.Region,
+		client: awskms.NewFromConfig(cfg),
+	}, nil
+}
+
+// PublicKey calls the KMS GetPublicKey action
+func (s *AWSSigner) PublicKey(ctx context.Context) ([]byte, error) {
+	resp, err := s.client.GetPublicKey(ctx, &awskms.GetPublicKeyInput{KeyId: aws.String(s.KeyID)})
+	if err != nil {
+		return nil, fmt.Errorf("AWS KMS GetPublicKey failed: %w", err)
+	}
+	if resp.KeySpec != "" && resp.KeySpec != awskmstypes.KeySpecEccSecgP256k1 {
+		return nil, fmt.Errorf("KMS key %s has key spec %s, not ECC_SECG_P256K1", s.KeyID, resp.KeySpec)
+	}
+	return resp.PublicKey, nil
+}
+
+// SignDigest calls the KMS Sign action with a precomputed digest
+func (s *AWSSigner) SignDigest(ctx context.Context, digest []byte) ([]byte, error) {
+	resp, err := s.client.Sign(ctx, &awskms.SignInput{
+		KeyId:            aws.String(s.KeyID),
+		Message:          digest,
+		MessageType:      awskmstypes.MessageTypeDigest,
+		SigningAlgorithm: awskmstypes.SigningAlgorithmSpecEcdsaSha256,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("AWS KMS Sign failed: %w", err)
+	}
+	return resp.Signature, nil
+}
+
+// GCPSigner is a core.DigestSigner for a Google Cloud KMS key version with algorithm
+// EC_SIGN_SECP256K1_SHA256
+type GCPSigner struct {
+	// KeyVersion is the resource name
+	// projects/{project}/locations/{location}/keyRings/{ring}/cryptoKeys/{key}/cryptoKeyVersions/{version}
+	KeyVersion string
+
+	client *gcpkms.KeyManagementClient
+}
+
+// NewGCPSigner creates a signer for a Cloud KMS key version. It uses the REST API and
+// Application Default Credentials unless opts say otherwise.
+func NewGCPSigner(ctx context.Context, keyVersion string, opts ...option.ClientOption) (*GCPSigner, error) {
+	if !strings.HasPrefix(keyVersion, "projects/") || !strings.Contains(keyVersion, "/cryptoKeyVersions/") {
+		return nil, fmt.Errorf("invalid Cloud KMS key version %q: expected projects/.../cryptoKeyVersions/N", keyVersion)
+	}
+	client, err := gcpkms.NewKeyManagementRESTClient(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Cloud KMS client: %w", err)
+	}
+	return &GCPSigner{KeyVersion: keyVersion, client: client}, nil
+}
+
+// PublicKey fetches the key version's PEM public key
+func (s *GCPSigner) PublicKey(ctx context.Context) ([]byte, error) {
+	resp, err := s.client.GetPublicKey(ctx, &kmspb.GetPublicKeyRequest{Name: s.KeyVersion})
+	if err != nil {
+		return nil, fmt.Errorf("Cloud KMS GetPublicKey failed: %w", err)
+	}
+	if resp.Algorithm != kmspb.CryptoKeyVersion_EC_SIGN_SECP256K1_SHA256 {
+		return nil, fmt.Errorf("KMS key %s has algorithm %s, not EC_SIGN_SECP256K1_SHA256", s.KeyVersion, resp.Algorithm)
+	}
+	block, _ := pem.Decode([]byte(resp.Pem))
+	if block == nil {
+		return nil, fmt.Errorf("KMS key %s returned an invalid PEM public key", s.KeyVersion)
+	}
+	return block.Bytes, nil
+}
+
+// SignDigest calls asymmetricSign with a precomputed digest. Checksums of the digest and the
+// signature are checked in both directions, as Cloud KMS recommends.
+func (s *GCPSigner) SignDigest(ctx context.Context, digest []byte) ([]byte, error) {
+	resp, err := s.client.AsymmetricSign(ctx, &kmspb.AsymmetricSignRequest{
+		Name:         s.KeyVersion,
+		Digest:       &kmspb.Digest{Digest: &kmspb.Digest_Sha256{Sha256: digest}},
+		DigestCrc32C: wrapperspb.Int64(crc32c(digest)),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Cloud KMS AsymmetricSign failed: %w", err)
+	}
+	if !resp.VerifiedDigestCrc32C {
+		return nil, fmt.Errorf("Cloud KMS did not verify the digest checksum")
+	}
+	if resp.SignatureCrc32C == nil || resp.SignatureCrc32C.Value != crc32c(resp.Signature) {
+		return nil, fmt.Errorf("Cloud KMS signature does not match its checksum")
+	}
+	return resp.Signature, nil
+}
+
+// Close releases the Cloud KMS client
+func (s *GCPSigner) Close() error {
+	return s.client.Close()
+}
+
+// crc32c returns the CRC32C checksum Cloud KMS uses to detect corrupted requests and responses
+func crc32c(data []byte) int64 {
+	return int64(crc32.Checksum(data, crc32.MakeTable(crc32.Castagnoli)))
+}
@@ -0,0 +1,216 @@
+package kms
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/ethereum-optimism/op-txverify/core"
+	"github.com/ethereum/go-ethereum/crypto"
+	"google.golang.org/api/option"
+)
+
+// fakeBackend signs with a local key and encodes keys and signatures as the KMS services do
+type fakeBackend struct {
+	key *ecdsa.PrivateKey
+}
+
+func newFakeDigestSigner(t *testing.T) *fakeBackend {
+	key, err := crypto.HexToECDSA("4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return &fakeBackend{key: key}
+}
+
+// PublicKey returns the key as a DER SubjectPublicKeyInfo on the secp256k1 curve
+func (f *fakeBackend) PublicKey(context.Context) ([]byte, error) {
+	curve, _ := asn1.Marshal(asn1.ObjectIdentifier{1, 3, 132, 0, 10})
+	point := crypto.FromECDSAPub(&f.key.PublicKey)
+	return asn1.Marshal(struct {
+		Algorithm pkix.AlgorithmIdentifier
+		PublicKey asn1.BitString
+	}{
+		Algorithm: pkix.AlgorithmIdentifier{
+			Algorithm:  asn1.ObjectIdentifier{1, 2, 840, 10045, 2, 1},
+			Parameters: asn1.RawValue{FullBytes: curve},
+		},
+		PublicKey: asn1.BitString{Bytes: point, BitLength: len(point) * 8},
+	})
+}
+
+// SignDigest returns a DER-encoded ECDSA signature of the digest
+func (f *fakeBackend) SignDigest(_ context.Context, digest []byte) ([]byte, error) {
+	sig, err := crypto.Sign(digest, f.key)
+	if err != nil {
+		return nil, err
+	}
+	return asn1.Marshal(struct{ R, S *big.Int }{new(big.Int).SetBytes(sig[:32]), new(big.Int).SetBytes(sig[32:64])})
+}
+
+// isolateAWSConfig points the AWS SDK at credentials in the environment only
+func isolateAWSConfig(t *testing.T) {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("AWS_CONFIG_FILE", filepath.Join(dir, "config"))
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", filepath.Join(dir, "credentials"))
+	t.Setenv("AWS_PROFILE", "")
+	t.Setenv("AWS_CA_BUNDLE", "")
+	t.Setenv("AWS_REGION", "")
+	t.Setenv("AWS_DEFAULT_REGION", "")
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKID")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+	t.Setenv("AWS_SESSION_TOKEN", "session")
+}
+
+func TestAWSSigner(t *testing.T) {
+	isolateAWSConfig(t)
+	t.Setenv("AWS_REGION", "us-east-1")
+
+	backend := newFakeDigestSigner(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKID/") ||
+			r.Header.Get("X-Amz-Security-Token") != "session" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"__type":"AccessDeniedException","message":"denied"}`))
+			return
+		}
+		var input map[string]string
+		json.NewDecoder(r.Body).Decode(&input)
+		if input["KeyId"] != "alias/owner" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"__type":"NotFoundException","message":"no such key"}`))
+			return
+		}
+		w.Header().Set("Content-Type", "application/x-amz-json-1.1")
+		switch r.Header.Get("X-Amz-Target") {
+		case "TrentService.GetPublicKey":
+			der, _ := backend.PublicKey(r.Context())
+			json.NewEncoder(w).Encode(map[string]interface{}{"PublicKey": der, "KeySpec": "ECC_SECG_P256K1"})
+		case "TrentService.Sign":
+			if input["MessageType"] != "DIGEST" {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(`{"__type":"ValidationException"}`))
+				return
+			}
+			digest, _ := base64.StdEncoding.DecodeString(input["Message"])
+			sig, _ := backend.SignDigest(r.Context(), digest)
+			json.NewEncoder(w).Encode(map[string]interface{}{"Signature": sig})
+		}
+	}))
+	defer server.Close()
+
+	kms, err := NewAWSSigner(context.Background(), "alias/owner", config.WithBaseEndpoint(server.URL), config.WithRetryMaxAttempts(1))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assertRemoteSignerRoundTrip(t, kms)
+
+	kms.KeyID = "alias/missing"
+	if _, err := core.NewRemoteSigner(context.Background(), kms); err == nil || !strings.Contains(err.Error(), "NotFoundException") {
+		t.Errorf("expected the service error to be reported, got %v", err)
+	}
+}
+
+func TestNewAWSSignerRegionFromARN(t *testing.T) {
+	isolateAWSConfig(t)
+
+	kms, err := NewAWSSigner(context.Background(), "arn:aws:kms:eu-west-2:111122223333:key/1234abcd")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if kms.Region != "eu-west-2" {
+		t.Errorf("region = %s", kms.Region)
+	}
+	if _, err := NewAWSSigner(context.Background(), "alias/owner"); err == nil {
+		t.Error("expected an error without a region")
+	}
+}
+
+func TestGCPSigner(t *testing.T) {
+	backend := newFakeDigestSigner(t)
+	keyVersion := "projects/p/locations/global/keyRings/r/cryptoKeys/owner/cryptoKeyVersions/1"
+	var corrupt bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/v1/" + keyVersion + "/publicKey":
+			der, _ := backend.PublicKey(r.Context())
+			json.NewEncoder(w).Encode(map[string]string{
+				"pem":       string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})),
+				"algorithm": "EC_SIGN_SECP256K1_SHA256",
+			})
+		case "/v1/" + keyVersion + ":asymmetricSign":
+			var input struct {
+				Digest struct {
+					SHA256 []byte `json:"sha256"`
+				} `json:"digest"`
+				DigestCrc32C string `json:"digestCrc32c"`
+			}
+			json.NewDecoder(r.Body).Decode(&input)
+			sig, _ := backend.SignDigest(r.Context(), input.Digest.SHA256)
+			checksum := crc32c(sig)
+			if corrupt {
+				checksum++
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"signature":            sig,
+				"signatureCrc32c":      strconv.FormatInt(checksum, 10),
+				"verifiedDigestCrc32c": input.DigestCrc32C == strconv.FormatInt(crc32c(input.Digest.SHA256), 10),
+			})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	kms, err := NewGCPSigner(context.Background(), keyVersion, option.WithEndpoint(server.URL), option.WithoutAuthentication())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer kms.Close()
+	assertRemoteSignerRoundTrip(t, kms)
+
+	corrupt = true
+	if _, err := kms.SignDigest(context.Background(), make([]byte, 32)); err == nil || !strings.Contains(err.Error(), "checksum") {
+		t.Errorf("expected a checksum error, got %v", err)
+	}
+
+	if _, err := NewGCPSigner(context.Background(), "projects/p/locations/global/keyRings/r/cryptoKeys/owner"); err == nil {
+		t.Error("expected an error for a key without a version")
+	}
+}
+
+// assertRemoteSignerRoundTrip signs through a KMS backend and checks the signature recovers to
+// the key's address
+func assertRemoteSignerRoundTrip(t *testing.T, backend core.DigestSigner) {
+	t.Helper()
+	signer, err := core.NewRemoteSigner(context.Background(), backend)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	hash := "0x0a9321a7dc6bc12a6c64e288f2af84af7bd022cc179e0ed3c7f5d9447cf674e0"
+	signature, err := signer.SignSafeTxHash(context.Background(), hash)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	recovered, err := core.RecoverSigner(hash, signature)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if recovered != signer.Address() {
+		t.Errorf("signature recovers to %s, want %s", recovered, signer.Address())
+	}
+}
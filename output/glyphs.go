@@ -0,0 +1,77 @@
+package output
+
+import (
+	"io"
+	"strings"
+)
+
+// asciiGlyphs replaces the box-drawing characters, emoji, and symbols of terminal output with
+// ASCII, for consoles whose font or code page cannot show them. Each emoji becomes a short tag
+// so that what it marked, such as a failed check, still stands out.
+var asciiGlyphs = strings.NewReplacer(
+	"⚠️", "[!]",
+	"⚠", "[!]",
+	"❌", "[X]",
+	"✅", "[OK]",
+	"✓", "[OK]",
+	"🚨", "[!!]",
+	"ℹ️", "[i]",
+	"ℹ", "[i]",
+	"🔍", "[?]",
+	"🔒", "[REDACTED]",
+	"📥", "[IN]",
+	"✍️", "[SIG]",
+	"✍", "[SIG]",
+	"📒", "[BOOK]",
+	"⬇️", "v",
+	"⬇", "v",
+	"⬆️", "^",
+	"⬆", "^",
+	"↓", "v",
+	"↑", "^",
+	"◀", "<",
+	"→", "->",
+	"━", "=",
+	"═", "=",
+	"─", "-",
+	"║", "|",
+	"│", "|",
+	"‖", "||",
+	"├", "|",
+	"└", "`",
+	"╔", "+",
+	"╗", "+",
+	"╚", "+",
+	"╝", "+",
+	"—", "--",
+	"–", "-",
+	"…", "...",
+	"×", "x",
+	"·", "-",
+	"️", "",
+)
+
+// ASCIIText replaces the glyphs of terminal output that legacy consoles cannot show with ASCII
+func ASCIIText(text string) string {
+	return asciiGlyphs.Replace(text)
+}
+
+// asciiWriter writes to w with the glyphs of terminal output replaced by ASCII
+type asciiWriter struct {
+	w io.Writer
+}
+
+// NewASCIIWriter returns a writer that writes to w with the glyphs of terminal output replaced by
+// ASCII. The formatters write whole lines or strings at a time, so a glyph is never split across
+// writes.
+func NewASCIIWriter(w io.Writer) io.Writer {
+	return asciiWriter{w: w}
+}
+
+// Write replaces the glyphs of p and writes it, reporting all of p as written on success
+func (a asciiWriter) Write(p []byte) (int, error) {
+	if _, err := io.WriteString(a.w, asciiGlyphs.Replace(string(p))); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
@@ -0,0 +1,95 @@
+package output
+
+import (
+	"bytes"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode"
+
+	"github.com/ethereum-optimism/op-txverify/core"
+)
+
+func TestASCIIText(t *testing.T) {
+	for text, expected := range map[string]string{
+		"⚠️  WARNING: DELEGATECALL ⚠️":    "[!]  WARNING: DELEGATECALL [!]",
+		"❌ MISMATCH — DO NOT SIGN":        "[X] MISMATCH -- DO NOT SIGN",
+		"━━━━":                            "====",
+		"   └─ approves Safe":             "   `- approves Safe",
+		"Safe (Gnosis Safe 🔍)":            "Safe (Gnosis Safe [?])",
+		"transfer() ×3 · 1 ETH":           "transfer() x3 - 1 ETH",
+		"ℹ️  INFO: plain":                 "[i]  INFO: plain",
+		"0xABCD… → byte 4":                "0xABCD... -> byte 4",
+		"already plain ASCII, unchanged.": "already plain ASCII, unchanged.",
+	} {
+		if got := ASCIIText(text); got != expected {
+			t.Errorf("ASCIIText(%q) = %q, expected %q", text, got, expected)
+		}
+	}
+}
+
+// TestASCIITextCoversSourceGlyphs guards against a new glyph in terminal output that the ASCII
+// fallback does not replace
+func TestASCIITextCoversSourceGlyphs(t *testing.T) {
+	for _, dir := range []string{".", "../core", "../cmd/op-txverify"} {
+		files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, file := range files {
+			if strings.HasSuffix(file, "_test.go") {
+				continue
+			}
+			source, err := os.ReadFile(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, r := range ASCIIText(string(source)) {
+				if r > unicode.MaxASCII {
+					t.Errorf("%s: %q is not replaced in ASCII output", file, r)
+				}
+			}
+		}
+	}
+}
+
+func TestNewASCIIWriterFormatTerminal(t *testing.T) {
+	tx := core.SafeTransaction{
+		Safe:        "0x2501c477d0a35545a387aa4a3eee4292a9a8b3f0",
+		SafeVersion: "1.3.0",
+		Chain:       int(core.OPMainnetChainID),
+		To:          "0x4200000000000000000000000000000000000042",
+		Value:       big.NewInt(0),
+		Data:        "0xa9059cbb0000000000000000000000008b8b2f214d92527bf1b1148dc2e609a4c1c2fd69000000000000000000000000000000000000000000034f086f3b33b684000000",
+		Nonce:       155,
+		Nested: &core.Nested{
+			Safe:        "0xe2ed962948005ab01f2cefe8326a0730b7d268af",
+			SafeVersion: "1.3.0",
+			Nonce:       42,
+			To:          "0x2501c477d0a35545a387aa4a3eee4292a9a8b3f0",
+			Data:        "0xd4d9bdcd19767d264966e39d532d998c5354f76ad5102407124b1885d69bf23f791b6f4c",
+		},
+	}
+	result, err := core.VerifyTransaction(tx, core.VerifyOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := FormatTerminal(result, NewASCIIWriter(&buf)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out := buf.String()
+	for i, r := range out {
+		if r > unicode.MaxASCII {
+			t.Fatalf("output contains %q at byte %d:\n%s", r, i, out)
+		}
+	}
+	for _, expected := range []string{"[!]  WARNING: CHILD TRANSACTION DETECTED  [!]", "+======", "|                          THIS OUTPUT IS IMPORTANT!"} {
+		if !strings.Contains(out, expected) {
+			t.Errorf("expected output to contain %q:\n%s", expected, out)
+		}
+	}
+}
@@ -0,0 +1,177 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum-optimism/op-txverify/core"
+)
+
+// callGraphNode is a Safe or a call target of the call graph
+type callGraphNode struct {
+	id    string
+	lines []string
+}
+
+// callGraphEdge is a call from one node to another
+type callGraphEdge struct {
+	from, to string
+	label    string
+	delegate bool
+}
+
+// callGraph is the decoded call tree of a transaction, from the signing Safe through batches
+// and approved child transactions down to every target
+type callGraph struct {
+	nodes []callGraphNode
+	edges []callGraphEdge
+}
+
+// newCallGraph builds the call graph of a result. Runs of identical subcalls are collapsed into
+// one node unless expandAll is set, as in the terminal output.
+func newCallGraph(result *core.VerificationResult, expandAll bool) *callGraph {
+	g := &callGraph{}
+	tx := result.Transaction
+	// Masked calls look alike, so they are never grouped as identical
+	expandAll = expandAll || result.Redacted
+	safe := g.addNode(append([]string{"Safe"}, graphAddress(tx.Safe, "", "", uint64(tx.Chain))...)...)
+
+	root := result.Call
+	root.Value = tx.Value
+	target := g.addCall(safe, "", root, tx.Operation == 1, 1, expandAll)
+
+	if result.NestedResult != nil {
+		// The child Safe is the target of the approveHash call, and makes the child call itself
+		child := result.NestedResult.Transaction
+		childRoot := result.NestedResult.Call
+		childRoot.Value = child.Value
+		g.addCall(target, fmt.Sprintf("approved tx, nonce %d", child.Nonce), childRoot, child.Operation == 1, 1, expandAll)
+	}
+	return g
+}
+
+// addNode adds a node and returns its id
+func (g *callGraph) addNode(lines ...string) string {
+	id := fmt.Sprintf("n%d", len(g.nodes))
+	g.nodes = append(g.nodes, callGraphNode{id: id, lines: lines})
+	return id
+}
+
+// addCall adds the target of a call made by from, or of a run of count identical calls, and then
+// its subcalls. It returns the id of the target.
+func (g *callGraph) addCall(from, prefix string, call core.CallData, delegate bool, count int, expandAll bool) string {
+	function := call.FunctionName
+	switch {
+	case call.RawData == "0x":
+		function = "no calldata"
+	case function == "unknown":
+		function = "unknown function"
+	}
+	if count > 1 {
+		function = fmt.Sprintf("%s ×%d", function, count)
+	}
+	to := g.addNode(append(graphAddress(call.Target, call.TargetName, call.TargetLabel, 0), function)...)
+
+	var label []string
+	if prefix != "" {
+		label = append(label, prefix)
+	}
+	if call.Index != "" {
+		label = append(label, "#"+call.Index)
+	}
+	if delegate {
+		label = append(label, "DELEGATECALL")
+	} else {
+		label = append(label, "CALL")
+	}
+	if call.Value != nil && call.Value.Sign() > 0 {
+		label = append(label, core.ParseDecimals(new(big.Int).Set(call.Value), 18)+" ETH")
+	}
+	g.edges = append(g.edges, callGraphEdge{from: from, to: to, label: strings.Join(label, " "), delegate: delegate})
+
+	for i := 0; i < len(call.SubCalls); {
+		run := 1
+		if !expandAll {
+			if n := identicalSubcallRun(call.SubCalls, i); n >= minGroupedSubcalls {
+				run = n
+			}
+		}
+		subcall := call.SubCalls[i]
+		if run > 1 {
+			subcall.Index = fmt.Sprintf("%s–#%s", subcall.Index, call.SubCalls[i+run-1].Index)
+		}
+		g.addCall(to, "", subcall, subcall.IsDelegateCall, run, expandAll)
+		i += run
+	}
+	return to
+}
+
+// graphAddress returns the lines naming an address in a node: its name, when it has one, and the
+// full address
+func graphAddress(address, name, addressLabel string, chainID uint64) []string {
+	if name == "" && chainID != 0 {
+		if info, ok := core.GetKnownContract(address, chainID); ok {
+			name = info.Name
+		}
+	}
+	if name == "" {
+		name = addressLabel
+	}
+	if name == "" {
+		return []string{core.ChecksumAddress(address)}
+	}
+	return []string{name, core.ChecksumAddress(address)}
+}
+
+// FormatMermaid outputs the call graph of a transaction as a Mermaid flowchart, for embedding
+// in runbooks and governance posts. Delegatecalls are drawn as thick arrows.
+func FormatMermaid(result *core.VerificationResult, w io.Writer, expandAll bool) error {
+	g := newCallGraph(result, expandAll)
+	escape := strings.NewReplacer("#", "#35;", `"`, "#quot;", "<", "#lt;", ">", "#gt;")
+
+	fmt.Fprintln(w, "flowchart TD")
+	for _, node := range g.nodes {
+		lines := make([]string, len(node.lines))
+		for i, line := range node.lines {
+			lines[i] = escape.Replace(line)
+		}
+		fmt.Fprintf(w, "  %s[\"%s\"]\n", node.id, strings.Join(lines, "<br/>"))
+	}
+	for _, edge := range g.edges {
+		arrow := "-->"
+		if edge.delegate {
+			arrow = "==>"
+		}
+		fmt.Fprintf(w, "  %s %s|\"%s\"| %s\n", edge.from, arrow, escape.Replace(edge.label), edge.to)
+	}
+	return nil
+}
+
+// FormatDOT outputs the call graph of a transaction in Graphviz DOT. Delegatecalls are drawn as
+// bold red arrows.
+func FormatDOT(result *core.VerificationResult, w io.Writer, expandAll bool) error {
+	g := newCallGraph(result, expandAll)
+	escape := strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+
+	fmt.Fprintln(w, "digraph calls {")
+	fmt.Fprintln(w, `  node [shape=box, fontname="monospace"];`)
+	fmt.Fprintln(w, `  edge [fontname="monospace"];`)
+	for _, node := range g.nodes {
+		lines := make([]string, len(node.lines))
+		for i, line := range node.lines {
+			lines[i] = escape.Replace(line)
+		}
+		fmt.Fprintf(w, "  %s [label=\"%s\"];\n", node.id, strings.Join(lines, `\n`))
+	}
+	for _, edge := range g.edges {
+		style := ""
+		if edge.delegate {
+			style = `, style=bold, color="red"`
+		}
+		fmt.Fprintf(w, "  %s -> %s [label=\"%s\"%s];\n", edge.from, edge.to, escape.Replace(edge.label), style)
+	}
+	fmt.Fprintln(w, "}")
+	return nil
+}
@@ -0,0 +1,88 @@
+package output
+
+import (
+	"bytes"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum-optimism/op-txverify/core"
+)
+
+// graphResult is a nested approval whose child batch makes a delegatecall and a run of transfers
+func graphResult() *core.VerificationResult {
+	transfer := func(index string) core.CallData {
+		return core.CallData{Index: index, Target: "0x0b2C639c533813f4Aa9D7837CAf62653d097Ff85", FunctionName: "transfer", ParsedData: []core.Argument{{Name: "amount", Value: "1"}}}
+	}
+	child := core.SafeTransaction{Safe: "0x2501c477D0A35545a387Aa4A3EEe4292A9a8B3F0", Chain: 10, Nonce: 7, Operation: 1, Value: big.NewInt(0)}
+	return &core.VerificationResult{
+		Transaction: core.SafeTransaction{Safe: "0xE2Ed962948005AB01F2cEfE8326a0730B7D268af", Chain: 10, To: child.Safe, Value: big.NewInt(0)},
+		Call:        core.CallData{Target: child.Safe, FunctionName: "approveHash"},
+		NestedResult: &core.VerificationResult{
+			Transaction: child,
+			Call: core.CallData{
+				Target:       "0x40A2aCCbd92BCA938b02010E17A5b8929b49130D",
+				TargetName:   "MultiSendCallOnly",
+				FunctionName: "multiSend",
+				SubCalls: []core.CallData{
+					{Index: "1", Target: "0x1111111111111111111111111111111111111111", FunctionName: "unknown", RawData: "0x", Value: big.NewInt(1500000000000000000)},
+					{Index: "2", Target: "0x2222222222222222222222222222222222222222", FunctionName: "upgrade", IsDelegateCall: true, TargetLabel: `Upgrader "v2"`},
+					transfer("3"), transfer("4"), transfer("5"),
+				},
+			},
+		},
+	}
+}
+
+func TestFormatMermaid(t *testing.T) {
+	var buf bytes.Buffer
+	if err := FormatMermaid(graphResult(), &buf, false); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"flowchart TD\n",
+		`n0["Safe<br/>0xE2Ed962948005AB01F2cEfE8326a0730B7D268af"]`,
+		`n1["0x2501c477D0A35545a387Aa4A3EEe4292A9a8B3F0<br/>approveHash"]`,
+		`n2["MultiSendCallOnly<br/>0x40A2aCCbd92BCA938b02010E17A5b8929b49130D<br/>multiSend"]`,
+		`n4["Upgrader #quot;v2#quot;<br/>0x2222222222222222222222222222222222222222<br/>upgrade"]`,
+		`n5["0x0b2C639c533813f4Aa9D7837CAf62653d097Ff85<br/>transfer ×3"]`,
+		`n0 -->|"CALL"| n1`,
+		`n1 ==>|"approved tx, nonce 7 DELEGATECALL"| n2`,
+		`n2 -->|"#35;1 CALL 1.5 ETH"| n3`,
+		`n2 ==>|"#35;2 DELEGATECALL"| n4`,
+		`n2 -->|"#35;3–#35;5 CALL"| n5`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+
+	buf.Reset()
+	if err := FormatMermaid(graphResult(), &buf, true); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out := buf.String(); strings.Contains(out, "×3") || !strings.Contains(out, `n2 -->|"#35;5 CALL"| n7`) {
+		t.Errorf("expanded graph still groups transfers:\n%s", out)
+	}
+}
+
+func TestFormatDOT(t *testing.T) {
+	var buf bytes.Buffer
+	if err := FormatDOT(graphResult(), &buf, false); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"digraph calls {\n",
+		`n0 [label="Safe\n0xE2Ed962948005AB01F2cEfE8326a0730B7D268af"];`,
+		`n4 [label="Upgrader \"v2\"\n0x2222222222222222222222222222222222222222\nupgrade"];`,
+		`n2 -> n3 [label="#1 CALL 1.5 ETH"];`,
+		`n2 -> n4 [label="#2 DELEGATECALL", style=bold, color="red"];`,
+		"}\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
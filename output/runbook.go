@@ -0,0 +1,233 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ethereum-optimism/op-txverify/core"
+)
+
+// DefaultQRSiteURL is the hosted page that turns a transaction into animated QR codes for the
+// qr command to scan
+const DefaultQRSiteURL = "https://op-txverify.optimism.io/"
+
+// RunbookOptions describes where signers fetch the transaction from during a signing ceremony
+type RunbookOptions struct {
+	Network string
+	Safe    string
+	Nonce   uint64
+
+	// QRSiteURL overrides DefaultQRSiteURL
+	QRSiteURL string
+
+	// Show, when set, lists the output sections every signer prints and compares
+	Show []string
+}
+
+// QRLink returns a link to the QR site that displays the given transaction as QR codes, as made
+// by core.ShareLink
+func QRLink(tx core.SafeTransaction, site string) (string, error) {
+	if site == "" {
+		site = DefaultQRSiteURL
+	}
+	return core.ShareLink(tx, site)
+}
+
+// FormatRunbookMarkdown writes a markdown runbook for a signing ceremony: the commands each
+// signer runs, the hashes they should see, and checklists for signers and the facilitator. tx is
+// the transaction as fetched, before verification rewrites it for nested transactions.
+func FormatRunbookMarkdown(tx core.SafeTransaction, result *core.VerificationResult, options RunbookOptions, w io.Writer) error {
+	qrLink, err := QRLink(tx, options.QRSiteURL)
+	if err != nil {
+		return err
+	}
+
+	outer := result.Transaction
+	chainName, ok := core.ChainNames[uint64(outer.Chain)]
+	if !ok {
+		chainName = "unknown chain"
+	}
+	safe := core.ChecksumAddress(outer.Safe)
+
+	fmt.Fprintf(w, "# Signing ceremony: %s nonce %d\n\n", runbookAddress(safe, uint64(outer.Chain)), outer.Nonce)
+	fmt.Fprintln(w, "> Every signer verifies the transaction independently on their own machine and compares")
+	fmt.Fprintln(w, "> the hashes below with what their hardware wallet shows. If anything differs, stop and tell")
+	fmt.Fprintln(w, "> the facilitator. Do not sign.")
+	fmt.Fprintln(w, "")
+
+	if len(result.Warnings) > 0 {
+		fmt.Fprintln(w, "## ⚠️ Warnings")
+		fmt.Fprintln(w, "")
+		if core.HasCritical(result.Warnings) {
+			fmt.Fprintln(w, "**This transaction raised a critical warning. Do not hold the ceremony until it is resolved.**")
+			fmt.Fprintln(w, "")
+		}
+		for _, warning := range result.Warnings {
+			fmt.Fprintf(w, "- **%s**: %s\n", strings.TrimSuffix(warningLabel(strings.ToUpper(string(warning.Severity)), warning), ":"), warning.Message)
+		}
+		fmt.Fprintln(w, "")
+	}
+
+	fmt.Fprintln(w, "## Transaction")
+	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, "| Field | Value |")
+	fmt.Fprintln(w, "| --- | --- |")
+	fmt.Fprintf(w, "| Safe | %s |\n", runbookAddress(safe, uint64(outer.Chain)))
+	fmt.Fprintf(w, "| Chain | %d (%s) |\n", outer.Chain, chainName)
+	fmt.Fprintf(w, "| Nonce | %d |\n", outer.Nonce)
+	fmt.Fprintf(w, "| Target | %s |\n", runbookAddress(outer.To, uint64(outer.Chain)))
+	fmt.Fprintf(w, "| ETH Value | %s |\n", core.ParseDecimals(outer.Value, 18))
+	fmt.Fprintf(w, "| Operation | %s |\n", runbookOperation(outer.Operation))
+	fmt.Fprintf(w, "| Function | `%s` |\n", result.Call.FunctionName)
+	fmt.Fprintln(w, "")
+	if len(result.Call.SubCalls) > 0 {
+		fmt.Fprintln(w, "Calls made by this transaction:")
+		fmt.Fprintln(w, "")
+		printRunbookCalls(w, result.Call.SubCalls, uint64(outer.Chain), "")
+		fmt.Fprintln(w, "")
+	}
+
+	fmt.Fprintln(w, "## Expected hashes")
+	fmt.Fprintln(w, "")
+	if result.NestedResult != nil {
+		child := result.NestedResult
+		fmt.Fprintf(w, "This is a nested approval. Signers of the child Safe %s sign these hashes:\n\n", runbookAddress(child.Transaction.Safe, uint64(child.Transaction.Chain)))
+		printRunbookHashes(w, child)
+		fmt.Fprintln(w, "The parent transaction, which approves the child hash above, has these hashes:")
+		fmt.Fprintln(w, "")
+	}
+	printRunbookHashes(w, result)
+
+	fmt.Fprintln(w, "Read out over a call, the Safe tx hash is:")
+	fmt.Fprintln(w, "")
+	if groups, err := core.PhoneticHash(result.ApproveHash); err == nil {
+		for i, group := range groups {
+			fmt.Fprintf(w, "%d. %s\n", i+1, group)
+		}
+	}
+	fmt.Fprintln(w, "")
+
+	fmt.Fprintln(w, "## Verify the transaction")
+	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, "Use whichever option matches your setup. Every option must produce the hashes above.")
+	fmt.Fprintln(w, "")
+	show := ""
+	if len(options.Show) > 0 {
+		show = " --show " + strings.Join(options.Show, ",")
+		fmt.Fprintf(w, "The commands print only these sections, which every signer compares: %s.\n", strings.Join(options.Show, ", "))
+		fmt.Fprintln(w, "")
+	}
+	// With other transactions queued for the nonce, every signer must fetch this one
+	target := fmt.Sprintf("--network %s --safe %s --nonce %d", options.Network, options.Safe, options.Nonce)
+	if len(tx.Replacements) > 0 {
+		target += " --safe-tx-hash " + result.ApproveHash
+	}
+	fmt.Fprintln(w, "**Online** (verification machine has internet access):")
+	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, "```bash")
+	fmt.Fprintf(w, "op-txverify online %s%s\n", target, show)
+	fmt.Fprintln(w, "```")
+	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, "**Offline** (download on a connected machine, copy the file across, verify on the air-gapped one):")
+	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, "```bash")
+	fmt.Fprintf(w, "op-txverify download %s --output tx.json\n", target)
+	fmt.Fprintf(w, "op-txverify offline --tx tx.json%s\n", show)
+	fmt.Fprintln(w, "```")
+	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, "**QR** (air-gapped, nothing copied across): open the link below on your phone, then run the")
+	fmt.Fprintln(w, "scanner on the verification machine and show it the QR codes.")
+	fmt.Fprintln(w, "")
+	fmt.Fprintf(w, "[Open QR codes](%s)\n", qrLink)
+	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, "```bash")
+	fmt.Fprintf(w, "op-txverify qr%s\n", show)
+	fmt.Fprintln(w, "```")
+	fmt.Fprintln(w, "")
+
+	fmt.Fprintln(w, "## Signer checklist")
+	fmt.Fprintln(w, "")
+	checklist := []string{
+		"Installed op-txverify from the official release and checked its checksum",
+		"Verified the transaction myself using one of the options above",
+	}
+	if len(options.Show) > 0 {
+		checklist = append(checklist, "My output shows exactly these sections: "+strings.Join(options.Show, ", "))
+	}
+	checklist = append(checklist,
+		"The Safe, chain, target, value, and operation match the table above",
+		"The decoded calls match what this ceremony is meant to do",
+		"No warnings in my output that are not listed above",
+		"Domain hash, message hash, and Safe tx hash match this runbook",
+		"The hashes on my hardware wallet screen match this runbook character for character",
+		"Posted my op-txverify output for the facilitator to compare",
+	)
+	for _, item := range checklist {
+		fmt.Fprintf(w, "- [ ] %s\n", item)
+	}
+	fmt.Fprintln(w, "")
+
+	fmt.Fprintln(w, "## Facilitator checklist")
+	fmt.Fprintln(w, "")
+	for _, item := range []string{
+		"Shared this runbook with every signer through a channel they trust",
+		"Collected each signer's op-txverify output",
+		"Ran `op-txverify compare-hashes` over the collected outputs and every hash matched",
+		"Confirmed the required number of signatures before execution",
+		"Executed the transaction and recorded the execution hash",
+	} {
+		fmt.Fprintf(w, "- [ ] %s\n", item)
+	}
+	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, "```bash")
+	fmt.Fprintln(w, "op-txverify compare-hashes signer1.txt signer2.txt signer3.txt")
+	fmt.Fprintln(w, "```")
+
+	return nil
+}
+
+// printRunbookHashes writes the three hashes of a result as a code block
+func printRunbookHashes(w io.Writer, result *core.VerificationResult) {
+	fmt.Fprintln(w, "```")
+	fmt.Fprintf(w, "Domain Hash:  %s\n", formatHash(result.DomainHash))
+	fmt.Fprintf(w, "Message Hash: %s\n", formatHash(result.MessageHash))
+	fmt.Fprintf(w, "Safe Tx Hash: %s\n", formatHash(result.ApproveHash))
+	fmt.Fprintln(w, "```")
+	fmt.Fprintln(w, "")
+}
+
+// printRunbookCalls writes a nested list of the calls a transaction makes
+func printRunbookCalls(w io.Writer, calls []core.CallData, chainID uint64, indent string) {
+	for _, call := range calls {
+		suffix := ""
+		if call.IsDelegateCall {
+			suffix = " (DELEGATECALL)"
+		}
+		if call.Deployment != nil && call.Deployment.Address != "" {
+			suffix += fmt.Sprintf(", deploys `%s`", call.Deployment.Address)
+		}
+		fmt.Fprintf(w, "%s- `%s` on %s%s\n", indent, call.FunctionName, runbookAddress(call.Target, chainID), suffix)
+		printRunbookCalls(w, call.SubCalls, chainID, indent+"  ")
+	}
+}
+
+// runbookAddress renders an address with its known name, if any
+func runbookAddress(address string, chainID uint64) string {
+	display := fmt.Sprintf("`%s`", core.ChecksumAddress(address))
+	if info, ok := core.GetKnownContract(address, chainID); ok {
+		display = fmt.Sprintf("%s (%s)", display, info.Name)
+	}
+	return display
+}
+
+// runbookOperation names a Safe operation type
+func runbookOperation(operation int) string {
+	switch operation {
+	case 0:
+		return "CALL"
+	case 1:
+		return "DELEGATECALL"
+	}
+	return fmt.Sprintf("UNKNOWN (%d)", operation)
+}
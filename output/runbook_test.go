@@ -0,0 +1,104 @@
+package output
+
+import (
+	"bytes"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum-optimism/op-txverify/core"
+)
+
+func runbookTx() core.SafeTransaction {
+	return core.SafeTransaction{
+		Safe:           "0x2501c477D0A35545a387Aa4A3EEe4292A9a8B3F0",
+		SafeVersion:    "1.3.0",
+		Chain:          core.OPMainnetChainID,
+		To:             core.OPTokenAddress,
+		Value:          big.NewInt(0),
+		Data:           "0xa9059cbb0000000000000000000000008b8b2f214d92527bf1b1148dc2e609a4c1c2fd69000000000000000000000000000000000000000000034f086f3b33b684000000",
+		GasToken:       core.ZeroAddress,
+		RefundReceiver: core.ZeroAddress,
+		Nonce:          155,
+	}
+}
+
+func TestFormatRunbookMarkdown(t *testing.T) {
+	tx := runbookTx()
+	result, err := core.VerifyTransaction(tx, core.VerifyOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var buf bytes.Buffer
+	options := RunbookOptions{Network: "op", Safe: tx.Safe, Nonce: 155}
+	if err := FormatRunbookMarkdown(tx, result, options, &buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"# Signing ceremony: `0x2501c477D0A35545a387Aa4A3EEe4292A9a8B3F0`",
+		"Safe Tx Hash: 0x19767D264966E39D532D998C5354F76AD5102407124B1885D69BF23F791B6F4C",
+		"1. one niner seven six",
+		"op-txverify online --network op --safe 0x2501c477D0A35545a387Aa4A3EEe4292A9a8B3F0 --nonce 155",
+		"op-txverify download --network op --safe 0x2501c477D0A35545a387Aa4A3EEe4292A9a8B3F0 --nonce 155 --output tx.json",
+		"| Function | `transfer` |",
+		"[Open QR codes](" + DefaultQRSiteURL + "?tx=",
+		"- [ ] The hashes on my hardware wallet screen match this runbook character for character",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("runbook missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "Warnings") {
+		t.Errorf("expected no warnings section:\n%s", out)
+	}
+
+	// With other transactions queued for the nonce, the commands pick this one
+	tx.Replacements = []core.PendingTransaction{{Nonce: 155, SafeTxHash: "0x72fffece9936d1d571eb46782721e25d99b81299613e5eb02da751b15bbca0fe"}}
+	buf.Reset()
+	if err := FormatRunbookMarkdown(tx, result, options, &buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "--nonce 155 --safe-tx-hash " + result.ApproveHash + " --output tx.json"; !strings.Contains(buf.String(), want) {
+		t.Errorf("runbook missing %q:\n%s", want, buf.String())
+	}
+
+	// Sections chosen for the ceremony are passed to every command
+	tx.Replacements = nil
+	options.Show = []string{SectionSummary, SectionHashes}
+	buf.Reset()
+	if err := FormatRunbookMarkdown(tx, result, options, &buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{
+		"op-txverify online --network op --safe 0x2501c477D0A35545a387Aa4A3EEe4292A9a8B3F0 --nonce 155 --show summary,hashes",
+		"op-txverify offline --tx tx.json --show summary,hashes",
+		"op-txverify qr --show summary,hashes",
+		"- [ ] My output shows exactly these sections: summary, hashes",
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("runbook missing %q:\n%s", want, buf.String())
+		}
+	}
+}
+
+func TestQRLinkRoundTrips(t *testing.T) {
+	tx := runbookTx()
+	link, err := QRLink(tx, "https://example.com/")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// The qr command decodes links the same way
+	got, warnings, err := core.DecodeTransactionLink(link)
+	if err != nil {
+		t.Fatalf("invalid link: %v", err)
+	}
+	if len(warnings) != 0 {
+		t.Errorf("a link with a sum should decode without warnings: %+v", warnings)
+	}
+	if got.Data != tx.Data || got.Nonce != tx.Nonce || got.Safe != tx.Safe {
+		t.Errorf("transaction did not round-trip: %+v", got)
+	}
+}
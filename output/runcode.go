@@ -0,0 +1,59 @@
+package output
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/ethereum-optimism/op-txverify/core"
+)
+
+// runCodeEncoding is Crockford's base32 alphabet, which leaves out letters easily misread as digits
+var runCodeEncoding = base32.NewEncoding("0123456789ABCDEFGHJKMNPQRSTVWXYZ").WithPadding(base32.NoPadding)
+
+// RunCode identifies a single verification run. It mixes the verified hashes with local entropy,
+// so it is new on every run: a screenshot of an earlier, legitimate run shows a different code
+// and cannot be passed off as the verification of a new transaction.
+type RunCode struct {
+	Code string
+	Time time.Time
+
+	// SafeTxHash is the hash the run verified, shown next to the code
+	SafeTxHash string
+}
+
+// NewRunCode derives a run code from a verification result and fresh randomness
+func NewRunCode(result *core.VerificationResult) (*RunCode, error) {
+	entropy := make([]byte, 16)
+	if _, err := rand.Read(entropy); err != nil {
+		return nil, fmt.Errorf("failed to read entropy for the run code: %w", err)
+	}
+	return newRunCode(result, entropy, time.Now()), nil
+}
+
+func newRunCode(result *core.VerificationResult, entropy []byte, now time.Time) *RunCode {
+	digest := sha256.New()
+	digest.Write([]byte("op-txverify run code"))
+	for r := result; r != nil; r = r.NestedResult {
+		digest.Write([]byte(r.DomainHash + r.MessageHash + r.ApproveHash))
+	}
+	digest.Write(entropy)
+	digest.Write([]byte(now.UTC().Format(time.RFC3339Nano)))
+
+	code := runCodeEncoding.EncodeToString(digest.Sum(nil))[:8]
+	return &RunCode{Code: code[:4] + "-" + code[4:], Time: now.UTC(), SafeTxHash: result.ApproveHash}
+}
+
+// printRunCode prints the run code banner shown at the top and bottom of the output
+func printRunCode(w io.Writer, runCode *RunCode, important func(a ...interface{}) string) {
+	if runCode == nil {
+		return
+	}
+	fmt.Fprintln(w, important(fmt.Sprintf("RUN CODE %s · %s · Safe tx hash %s", runCode.Code, runCode.Time.Format("2006-01-02 15:04:05 MST"), formatHash(runCode.SafeTxHash))))
+	fmt.Fprintln(w, "This code is new on every run. When sharing this output, say the code in your message;")
+	fmt.Fprintln(w, "a screenshot whose code does not match the one in the message is from a different run.")
+	fmt.Fprintln(w, "")
+}
@@ -0,0 +1,69 @@
+package output
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum-optimism/op-txverify/core"
+)
+
+func TestRunCodeChangesEveryRun(t *testing.T) {
+	result := &core.VerificationResult{DomainHash: "0x01", MessageHash: "0x02", ApproveHash: "0x" + strings.Repeat("ab", 32)}
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	a := newRunCode(result, []byte{1}, now)
+	if !regexp.MustCompile(`^[0-9A-Z]{4}-[0-9A-Z]{4}$`).MatchString(a.Code) {
+		t.Fatalf("unexpected run code format %q", a.Code)
+	}
+	if b := newRunCode(result, []byte{1}, now); b.Code != a.Code {
+		t.Error("the same inputs should give the same code")
+	}
+	if b := newRunCode(result, []byte{2}, now); b.Code == a.Code {
+		t.Error("different entropy should give a different code")
+	}
+	other := *result
+	other.ApproveHash = "0x" + strings.Repeat("cd", 32)
+	if b := newRunCode(&other, []byte{1}, now); b.Code == a.Code {
+		t.Error("a different transaction should give a different code")
+	}
+
+	x, err := NewRunCode(result)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	y, _ := NewRunCode(result)
+	if x.Code == y.Code {
+		t.Error("two runs should not share a code")
+	}
+}
+
+func TestFormatTerminalPrintsRunCodeTopAndBottom(t *testing.T) {
+	result := &core.VerificationResult{
+		Transaction: core.SafeTransaction{Chain: 10, Safe: "0x2501c477D0A35545a387Aa4A3EEe4292A9a8B3F0", To: "0x1111111111111111111111111111111111111111", Data: "0x"},
+		ApproveHash: "0x" + strings.Repeat("ab", 32),
+	}
+	runCode := newRunCode(result, []byte{1}, time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC))
+
+	var buf bytes.Buffer
+	if err := FormatTerminalWithOptions(result, &buf, TerminalOptions{RunCode: runCode}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	banner := "RUN CODE " + runCode.Code + " · 2024-05-01 12:00:00 UTC · Safe tx hash 0x" + strings.Repeat("AB", 32) + "\n"
+	if n := strings.Count(buf.String(), banner); n != 2 {
+		t.Errorf("run code banner appears %d times, want 2:\n%s", n, buf.String())
+	}
+	if match := truncationPattern.FindString(buf.String()); match != "" {
+		t.Errorf("output contains truncated value %q:\n%s", match, buf.String())
+	}
+
+	buf.Reset()
+	if err := FormatTerminal(result, &buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Contains(buf.String(), "RUN CODE") {
+		t.Error("no run code should be printed unless one is given")
+	}
+}
@@ -0,0 +1,108 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"strings"
+	"text/template"
+
+	"github.com/ethereum-optimism/op-txverify/core"
+)
+
+// TemplateCall is a call of the decoded call tree, as the calls template function lists them
+type TemplateCall struct {
+	core.CallData
+
+	// Depth is how deeply the call is batched: 0 for the call of a transaction, 1 for its
+	// subcalls, and so on
+	Depth int
+}
+
+// templateFuncs are the functions a report template can use besides the text/template built-ins
+var templateFuncs = template.FuncMap{
+	// hash writes a hash in upper case after its 0x prefix, as the terminal output does
+	"hash": formatHash,
+	// checksum writes an address with its EIP-55 checksum
+	"checksum": core.ChecksumAddress,
+	// chain names a chain ID
+	"chain": func(chainID int) string {
+		if name, ok := core.ChainNames[uint64(chainID)]; ok {
+			return name
+		}
+		return fmt.Sprintf("chain %d", chainID)
+	},
+	// ether writes a wei amount in ether
+	"ether": func(wei *big.Int) string {
+		if wei == nil {
+			return "0"
+		}
+		return core.ParseDecimals(wei, 18)
+	},
+	// calls lists a call and all of its subcalls in order, each with its depth
+	"calls": func(call core.CallData) []TemplateCall {
+		var calls []TemplateCall
+		var walk func(call core.CallData, depth int)
+		walk = func(call core.CallData, depth int) {
+			calls = append(calls, TemplateCall{CallData: call, Depth: depth})
+			for _, subcall := range call.SubCalls {
+				walk(subcall, depth+1)
+			}
+		}
+		walk(call, 0)
+		return calls
+	},
+	// severity keeps the warnings of a severity: "info", "warning", or "critical"
+	"severity": func(severity string, warnings []core.Warning) []core.Warning {
+		var kept []core.Warning
+		for _, warning := range warnings {
+			if string(warning.Severity) == severity {
+				kept = append(kept, warning)
+			}
+		}
+		return kept
+	},
+	// json writes a value as indented JSON, as --output json does
+	"json": func(value interface{}) (string, error) {
+		data, err := json.MarshalIndent(value, "", "  ")
+		return string(data), err
+	},
+	"upper":  strings.ToUpper,
+	"lower":  strings.ToLower,
+	"join":   strings.Join,
+	"indent": func(n int) string { return strings.Repeat("  ", n) },
+}
+
+// ParseTemplate parses a report template. Templates are Go text/templates executed against a
+// core.VerificationResult, with the functions of templateFuncs. Referring to a field that does
+// not exist is an error when the template is executed, not an empty string.
+func ParseTemplate(name, text string) (*template.Template, error) {
+	tmpl, err := template.New(name).Funcs(templateFuncs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// LoadTemplate reads and parses a report template file
+func LoadTemplate(path string) (*template.Template, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read template: %w", err)
+	}
+	return ParseTemplate(path, string(data))
+}
+
+// FormatTemplate renders a result with a report template. Nothing is written when the template
+// fails, so a broken template does not produce a partial report.
+func FormatTemplate(result *core.VerificationResult, tmpl *template.Template, w io.Writer) error {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, result); err != nil {
+		return fmt.Errorf("failed to render template: %w", err)
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
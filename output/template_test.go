@@ -0,0 +1,65 @@
+package output
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/ethereum-optimism/op-txverify/core"
+)
+
+func TestFormatTemplate(t *testing.T) {
+	result := graphResult()
+	result.ApproveHash = "0xabcdef"
+	result.Warnings = []core.Warning{
+		{Severity: core.SeverityInfo, Message: "just so you know"},
+		{Severity: core.SeverityCritical, Message: "do not sign"},
+	}
+
+	tmpl, err := ParseTemplate("report", `Safe: {{checksum .Transaction.Safe}} on {{chain .Transaction.Chain}}
+Hash: {{hash .ApproveHash}}
+{{- with .NestedResult}}
+{{- range calls .Call}}
+{{indent .Depth}}{{.Index}} {{.FunctionName}}{{if .Value}} ({{ether .Value}} ETH){{end}}
+{{- end}}
+{{- end}}
+{{- range severity "critical" .Warnings}}
+CRITICAL {{.Message}}
+{{- end}}
+`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := FormatTemplate(result, tmpl, &buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := `Safe: 0xE2Ed962948005AB01F2cEfE8326a0730B7D268af on OP Mainnet
+Hash: 0xABCDEF
+ multiSend
+  1 unknown (1.5 ETH)
+  2 upgrade
+  3 transfer
+  4 transfer
+  5 transfer
+CRITICAL do not sign
+`
+	if buf.String() != want {
+		t.Errorf("unexpected report:\n%s\nwant:\n%s", buf.String(), want)
+	}
+}
+
+func TestFormatTemplateErrors(t *testing.T) {
+	if _, err := ParseTemplate("report", "{{.Transaction.Safe"); err == nil || !strings.Contains(err.Error(), "invalid template") {
+		t.Errorf("expected a parse error, got %v", err)
+	}
+
+	tmpl, err := ParseTemplate("report", "before {{.Transaction.NoSuchField}}")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := FormatTemplate(graphResult(), tmpl, &buf); err == nil || buf.Len() != 0 {
+		t.Errorf("expected an error and no partial output, got %v and %q", err, buf.String())
+	}
+}
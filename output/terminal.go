@@ -7,149 +7,1977 @@ import (
 	"reflect"
 	"sort"
 	"strings"
+	"time"
 
 	"encoding/hex"
 
 	"github.com/ethereum-optimism/op-txverify/core"
+	"github.com/ethereum-optimism/op-txverify/doctor"
+	"github.com/ethereum-optimism/op-txverify/registry"
+	"github.com/ethereum-optimism/op-txverify/safes"
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/fatih/color"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
 )
 
+// TerminalOptions controls optional sections of the terminal output
+type TerminalOptions struct {
+	// Verbosity adds detail to the standard output: VerbosityDetailed adds the provenance of
+	// every hashed field and VerbosityRaw also the raw calldata
+	Verbosity int
+
+	// Show, when set, prints only these sections (Section*) whatever the verbosity. Critical
+	// warnings are printed regardless.
+	Show []string
+
+	// ExpandAll prints every subcall in full instead of collapsing runs of identical calls
+	ExpandAll bool
+
+	// Phonetic spells out the Safe tx hash in NATO alphabet groups for comparison by voice
+	Phonetic bool
+
+	// AnnotateCalldata prints the raw calldata word by word, labelled with the decoded ABI fields
+	AnnotateCalldata bool
+
+	// Role tailors the instructions to a ceremony role (RoleSigner or RoleFacilitator); empty
+	// shows the standard output
+	Role string
+
+	// RunCode, when set, is printed at the top and bottom of the output
+	RunCode *RunCode
+}
+
+// Verbosity levels of the terminal output
+const (
+	VerbosityStandard = 0
+	VerbosityDetailed = 1
+	VerbosityRaw      = 2
+)
+
+// Sections of the terminal output that TerminalOptions.Show selects
+const (
+	SectionWarnings   = "warnings"
+	SectionSummary    = "summary"
+	SectionProvenance = "provenance"
+	SectionFlow       = "flow"
+	SectionSubcalls   = "subcalls"
+	SectionRaw        = "raw"
+	SectionEffects    = "effects"
+	SectionHashes     = "hashes"
+	SectionCompliance = "compliance"
+)
+
+// Sections lists the sections of the terminal output in the order they are printed
+var Sections = []string{
+	SectionWarnings, SectionSummary, SectionProvenance, SectionFlow, SectionSubcalls, SectionRaw,
+	SectionEffects, SectionHashes, SectionCompliance,
+}
+
+// ParseSections parses a comma-separated list of section names, as given to --show
+func ParseSections(list string) ([]string, error) {
+	var sections []string
+	for _, name := range strings.Split(list, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		known := false
+		for _, section := range Sections {
+			known = known || section == name
+		}
+		if !known {
+			return nil, fmt.Errorf("unknown section: %s (must be one of %s)", name, strings.Join(Sections, ", "))
+		}
+		sections = append(sections, name)
+	}
+	return sections, nil
+}
+
+// shows reports whether a section is printed. Without Show, the provenance table is printed from
+// VerbosityDetailed and the raw calldata from VerbosityRaw or when it is annotated.
+func (o TerminalOptions) shows(section string) bool {
+	if len(o.Show) > 0 {
+		for _, shown := range o.Show {
+			if shown == section {
+				return true
+			}
+		}
+		return false
+	}
+	switch section {
+	case SectionProvenance:
+		return o.Verbosity >= VerbosityDetailed
+	case SectionRaw:
+		return o.Verbosity >= VerbosityRaw || o.AnnotateCalldata
+	}
+	return true
+}
+
+// Ceremony roles accepted by ApplyRole
+const (
+	RoleSigner      = "signer"
+	RoleFacilitator = "facilitator"
+)
+
+// ApplyRole returns options preset for a ceremony role. Facilitators get the verbose output
+// along with signer progress, Safe service cross-checks, and links; signers get a short
+// review-and-compare flow.
+func ApplyRole(options TerminalOptions, role string) (TerminalOptions, error) {
+	switch role {
+	case "":
+	case RoleFacilitator:
+		options.Verbosity = max(options.Verbosity, VerbosityDetailed)
+	case RoleSigner:
+	default:
+		return options, fmt.Errorf("unknown role: %s (must be %s or %s)", role, RoleSigner, RoleFacilitator)
+	}
+	options.Role = role
+	return options, nil
+}
+
+// minGroupedSubcalls is the shortest run of identical subcalls that is collapsed into a group
+const minGroupedSubcalls = 3
+
 // FormatTerminal outputs the verification result in a human-readable format to the provided writer.
 // It displays transaction details, nested transactions, call data, and verification instructions
 // in a color-coded terminal-friendly format.
 func FormatTerminal(result *core.VerificationResult, w io.Writer) error {
+	return FormatTerminalWithOptions(result, w, TerminalOptions{})
+}
+
+// FormatTerminalWithOptions is FormatTerminal with control over optional sections
+func FormatTerminalWithOptions(result *core.VerificationResult, w io.Writer, options TerminalOptions) error {
 	// Set up colors for consistent formatting
-	heading := color.New(color.FgCyan, color.Bold).SprintFunc()
-	divider := color.New(color.FgCyan).SprintFunc()
-	label := color.New(color.FgMagenta).SprintFunc()
-	bold := color.New(color.Bold).SprintFunc()
-	yellow := color.New(color.FgYellow).SprintFunc()
-	warning := color.New(color.FgYellow, color.Bold).SprintFunc()
-	important := color.New(color.FgRed, color.Bold).SprintFunc()
+	heading := themed(colorHeading).SprintFunc()
+	divider := themed(colorDivider).SprintFunc()
+	label := themed(colorLabel).SprintFunc()
+	bold := themed(colorBold).SprintFunc()
+	yellow := themed(colorCaution).SprintFunc()
+	warning := themed(colorWarning).SprintFunc()
+	important := themed(colorImportant).SprintFunc()
+
+	// Print important header warning
+	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, important("╔══════════════════════════════════════════════════════════════════════════════╗"))
+	fmt.Fprintln(w, important("║                                                                              ║"))
+	fmt.Fprintln(w, important("║                          THIS OUTPUT IS IMPORTANT!                           ║"))
+	fmt.Fprintln(w, important("║                                                                              ║"))
+	fmt.Fprintln(w, important("║    You need to verify this output by looking at exactly what's happening     ║"))
+	fmt.Fprintln(w, important("║    in this transaction and validate that it's interacting with the RIGHT     ║"))
+	fmt.Fprintln(w, important("║    ADDRESSES, with the RIGHT ETH VALUE, on the RIGHT CHAIN, and with the     ║"))
+	fmt.Fprintln(w, important("║    RIGHT DATA!                                                               ║"))
+	fmt.Fprintln(w, important("║                                                                              ║"))
+	fmt.Fprintln(w, important("╚══════════════════════════════════════════════════════════════════════════════╝"))
+	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, "")
+	printRunCode(w, options.RunCode, important)
+	if result.Redacted {
+		printRedactionNotice(w, warning)
+		// Masked calls look alike, so they are never grouped as identical
+		options.ExpandAll = true
+	}
+
+	// Print any warnings raised during verification before anything else
+	if options.shows(SectionWarnings) {
+		printWarnings(w, result.Warnings, heading, divider, warning, important)
+	} else {
+		printHiddenWarnings(w, result.Warnings, heading, divider, warning, important)
+	}
+
+	// Extract transaction details
+	tx := result.Transaction
+
+	if options.shows(SectionSummary) {
+		printSummary(w, result, heading, divider, bold, label, warning, important)
+	}
+
+	// Show where each hashed field came from when the transaction was generated from the Safe service
+	printProvenance(w, tx.Provenance, options.shows(SectionProvenance), heading, divider, warning, label)
+
+	// Show the structure of the calls before their details
+	if options.shows(SectionFlow) {
+		printCallFlow(w, result, options, heading, divider, bold, yellow)
+	}
+
+	// Check if this is a nested transaction
+	if result.NestedResult != nil {
+		fmt.Fprintln(w, warning("⚠️  WARNING: CHILD TRANSACTION DETECTED  ⚠️"))
+		fmt.Fprintln(w, divider("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━"))
+		fmt.Fprintln(w, bold("This transaction is approving the execution of a transaction in a child Safe."))
+		fmt.Fprintln(w, "")
+
+		fmt.Fprintln(w, "")
+		fmt.Fprintln(w, important("⬇️  START OF CHILD TRANSACTION DETAILS  ⬇️"))
+		fmt.Fprintln(w, "")
+
+		nestedTx := result.NestedResult.Transaction
+
+		if options.shows(SectionSummary) {
+			// Display nested Safe if we can
+			nestedSafeInfo, isKnownNestedSafe := core.GetKnownContract(nestedTx.Safe, uint64(nestedTx.Chain))
+			nestedSafeDisplay := core.ChecksumAddress(nestedTx.Safe)
+			if isKnownNestedSafe {
+				nestedSafeDisplay = fmt.Sprintf("%s (%s 🔍)", nestedSafeDisplay, nestedSafeInfo.Name)
+			}
+
+			fmt.Fprintln(w, heading("CHILD TRANSACTION SUMMARY"))
+			fmt.Fprintln(w, divider("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━"))
+			fmt.Fprintf(w, "%s: %s\n", bold("Child Safe"), nestedSafeDisplay)
+			fmt.Fprintf(w, "%s: %d\n", bold("Child Nonce"), nestedTx.Nonce)
+			fmt.Fprintf(w, "%s: %s\n", bold("Child Hash"), result.NestedResult.ApproveHash)
+			printExecution(w, nestedTx.Execution, bold, warning, important)
+			fmt.Fprintln(w, "")
+			printRejection(w, "CHILD ", result.NestedResult, heading, divider, bold, warning)
+		}
+
+		// Use the existing function to print the child call details
+		if options.shows(SectionSubcalls) {
+			printCallDetails(w, result.NestedResult.Call, 0, options, heading, divider, label, yellow, bold)
+		}
+		if options.shows(SectionRaw) {
+			printRawCalldata(w, "CHILD ", nestedTx, options, heading, divider, label, warning)
+		}
+		if options.shows(SectionEffects) {
+			printExecutionEffects(w, "CHILD EXECUTION EFFECTS", nestedTx.Execution, heading, divider, label, warning, important)
+			printOwnerDiff(w, "CHILD OWNERS AND THRESHOLD", result.NestedResult.OwnerDiff, heading, divider, label, warning, important)
+		}
+
+		// Add a divider after the child details
+		fmt.Fprintln(w, important("⬆️   END OF CHILD TRANSACTION DETAILS   ⬆️"))
+		fmt.Fprintln(w, "")
+		fmt.Fprintln(w, "")
+	}
+
+	// Print call details (of the outer transaction in case of nested)
+	if options.shows(SectionSubcalls) {
+		printCallDetails(w, result.Call, 0, options, heading, divider, label, yellow, bold)
+	}
+	if options.shows(SectionRaw) {
+		printRawCalldata(w, "", tx, options, heading, divider, label, warning)
+	}
+	if options.shows(SectionEffects) {
+		printExecutionEffects(w, "EXECUTION EFFECTS", tx.Execution, heading, divider, label, warning, important)
+		printOwnerDiff(w, "OWNERS AND THRESHOLD", result.OwnerDiff, heading, divider, label, warning, important)
+	}
+
+	printReviewRecord(w, result, heading, divider, label, important)
+
+	if options.shows(SectionHashes) {
+		printHashes(w, result, options, heading, divider, label, bold)
+	}
+
+	if options.Role == RoleFacilitator {
+		printFacilitatorDetails(w, result, heading, divider, label, warning, important)
+	}
+
+	// Keep the compliance summary apart from the technical detail above
+	if options.shows(SectionCompliance) {
+		printCompliance(w, result.Compliance, heading, divider, bold, label, warning)
+	}
+
+	// Print verification instructions
+	fmt.Fprintln(w, heading("VERIFICATION INSTRUCTIONS"))
+	fmt.Fprintln(w, divider("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━"))
+	switch options.Role {
+	case RoleSigner:
+		fmt.Fprintf(w, "%s\n", bold("1. Check the summary and calls above match what the facilitator said this transaction does."))
+		fmt.Fprintf(w, "%s\n", bold("2. Post the hash block above and check it EXACTLY MATCHES the other signers."))
+		fmt.Fprintf(w, "%s\n", bold("3. Sign only if your hardware wallet shows the EXACT SAME HASHES."))
+		fmt.Fprintf(w, "%s\n", bold("4. WHEN IN DOUBT, STOP AND ASK THE FACILITATOR."))
+	case RoleFacilitator:
+		fmt.Fprintf(w, "%s\n", bold("1. Transaction details should EXACTLY MATCH the proposal being executed."))
+		fmt.Fprintf(w, "%s\n", bold("2. Collect every signer's output and run op-txverify compare-hashes over them."))
+		fmt.Fprintf(w, "%s\n", bold("3. Have each signer confirm their hardware wallet shows the EXACT SAME HASHES."))
+		fmt.Fprintf(w, "%s\n", bold("4. Do not execute until the threshold is met and every hash matched."))
+		fmt.Fprintf(w, "%s\n", bold("5. WHEN IN DOUBT, STOP THE CEREMONY."))
+	default:
+		fmt.Fprintf(w, "%s\n", bold("1. Transaction details should EXACTLY MATCH what you expect to see."))
+		fmt.Fprintf(w, "%s\n", bold("2. Domain and message hashes should EXACTLY MATCH other machines."))
+		fmt.Fprintf(w, "%s\n", bold("3. Your hardware wallet should show you the EXACT SAME HASHES."))
+		fmt.Fprintf(w, "%s\n", bold("4. WHEN IN DOUBT, ASK FOR HELP."))
+	}
+	fmt.Fprintln(w, "")
+	printRunCode(w, options.RunCode, important)
+
+	return nil
+}
+
+// FormatSectionTerminal prints one section of the terminal output on its own, as a review
+// session shows them one at a time. Only the summary and the call flow are supported.
+func FormatSectionTerminal(result *core.VerificationResult, section string, w io.Writer) error {
+	heading := themed(colorHeading).SprintFunc()
+	divider := themed(colorDivider).SprintFunc()
+	label := themed(colorLabel).SprintFunc()
+	bold := themed(colorBold).SprintFunc()
+	yellow := themed(colorCaution).SprintFunc()
+	warning := themed(colorWarning).SprintFunc()
+	important := themed(colorImportant).SprintFunc()
+
+	switch section {
+	case SectionSummary:
+		printSummary(w, result, heading, divider, bold, label, warning, important)
+	case SectionFlow:
+		printCallFlow(w, result, TerminalOptions{}, heading, divider, bold, yellow)
+	default:
+		return fmt.Errorf("section %s cannot be printed on its own", section)
+	}
+	return nil
+}
+
+// FormatCallTerminal prints the details of a single call, as a review session shows the calls
+// of a transaction one at a time
+func FormatCallTerminal(call core.CallData, w io.Writer) error {
+	heading := themed(colorHeading).SprintFunc()
+	divider := themed(colorDivider).SprintFunc()
+	label := themed(colorLabel).SprintFunc()
+	bold := themed(colorBold).SprintFunc()
+	yellow := themed(colorCaution).SprintFunc()
+
+	depth := 0
+	if call.Index != "" {
+		depth = 1
+	}
+	printCallDetails(w, call, depth, TerminalOptions{ExpandAll: true}, heading, divider, label, yellow, bold)
+	return nil
+}
+
+// printReviewRecord prints what was reviewed in a review session: the sections and calls marked
+// as reviewed and the critical warnings acknowledged, with the reviewer's notes
+func printReviewRecord(w io.Writer, result *core.VerificationResult, heading, divider, label, important func(a ...interface{}) string) {
+	record := result.Review
+	if record == nil {
+		return
+	}
+	fmt.Fprintln(w, heading("REVIEW RECORD"))
+	fmt.Fprintln(w, divider("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━"))
+	if record.Source != "" {
+		fmt.Fprintf(w, "%s: %s\n", label("Source"), record.Source)
+	}
+	for _, section := range record.Sections {
+		text := "✓ " + section.ReviewedAt.UTC().Format("2006-01-02 15:04 UTC")
+		if section.Note != "" {
+			text += " — " + section.Note
+		}
+		fmt.Fprintf(w, "%s: %s\n", label("Section "+section.Section), text)
+	}
+
+	calls := core.ReviewableCalls(result)
+	reviewed := 0
+	for _, call := range calls {
+		if call.Review != nil {
+			reviewed++
+		}
+	}
+	fmt.Fprintf(w, "%s: %d of %d\n", label("Calls reviewed"), reviewed, len(calls))
+
+	for _, ack := range record.Acknowledged {
+		fmt.Fprintf(w, "%s: %s\n", label("Acknowledged"), ack.Message)
+		if ack.Note != "" {
+			fmt.Fprintf(w, "  %s\n", ack.Note)
+		}
+	}
+	if pending := record.Unacknowledged(result.Warnings); len(pending) > 0 {
+		fmt.Fprintln(w, important(fmt.Sprintf("❌ %d CRITICAL WARNING(S) NOT ACKNOWLEDGED", len(pending))))
+	}
+	fmt.Fprintln(w, "")
+}
+
+// printSummary prints the fields of a transaction a reviewer checks first, along with its
+// signing window, the changes since an earlier nonce, and whether it burns the nonce
+func printSummary(w io.Writer, result *core.VerificationResult, heading, divider, bold, label, warning, important func(a ...interface{}) string) {
+	fmt.Fprintln(w, heading("TRANSACTION SUMMARY"))
+	fmt.Fprintln(w, divider("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━"))
+
+	// Extract transaction details
+	tx := result.Transaction
+
+	// Display verified Safe if we can
+	safeContractInfo, isKnownSafeContract := core.GetKnownContract(tx.Safe, uint64(tx.Chain))
+	safeDisplay := core.ChecksumAddress(tx.Safe)
+	if isKnownSafeContract {
+		safeDisplay = fmt.Sprintf("%s (%s 🔍)", safeDisplay, safeContractInfo.Name)
+	}
+
+	// Display verified Target if we can
+	targetContractInfo, isKnownTargetContract := core.GetKnownContract(tx.To, uint64(tx.Chain))
+	targetDisplay := core.ChecksumAddress(tx.To)
+	if isKnownTargetContract {
+		targetDisplay = fmt.Sprintf("%s (%s 🔍)", targetDisplay, targetContractInfo.Name)
+	}
+
+	// Parse out the operation being performed
+	var operation string
+	if tx.Operation == 0 {
+		operation = "CALL"
+	} else if tx.Operation == 1 {
+		operation = "DELEGATECALL"
+	} else {
+		operation = "UNKNOWN OPERATION ❌"
+	}
+
+	// Parse out the value being sent
+	value := core.ParseDecimals(tx.Value, 18)
+	if tx.Value == nil && result.Redacted {
+		value = core.RedactedAmount
+	}
+
+	// Parse out network
+	network, isKnownNetwork := core.ChainNames[uint64(tx.Chain)]
+	chainDisplay := fmt.Sprintf("%d", int(tx.Chain))
+	if isKnownNetwork {
+		chainDisplay = fmt.Sprintf("%d (%s 🔍)", int(tx.Chain), network)
+	}
+
+	fmt.Fprintf(w, "%s: %s\n", bold("Safe"), safeDisplay)
+	fmt.Fprintf(w, "%s: %s\n", bold("Chain ID"), chainDisplay)
+	fmt.Fprintf(w, "%s: %s\n", bold("Target"), targetDisplay)
+	fmt.Fprintf(w, "%s: %s\n", bold("ETH Value"), value)
+	fmt.Fprintf(w, "%s: %d\n", bold("Nonce"), tx.Nonce)
+	fmt.Fprintf(w, "%s: %s\n", bold("Operation"), operation)
+	printProposal(w, tx.Proposal, bold, warning)
+	printExecution(w, tx.Execution, bold, warning, important)
+	for _, source := range tx.FallbackSources {
+		fmt.Fprintf(w, "%s: %s\n", bold("Loaded From"), warning(source+" ⚠️"))
+	}
+	fmt.Fprintln(w, "")
+
+	printSchedule(w, result.Schedule, heading, divider, bold, warning, important)
+	printPreviousDiff(w, result.PreviousDiff, heading, divider, bold, label, warning)
+	printRejection(w, "", result, heading, divider, bold, warning)
+}
+
+// printHashes prints the hashes to compare with the hardware wallet and the other signers
+func printHashes(w io.Writer, result *core.VerificationResult, options TerminalOptions, heading, divider, label, bold func(a ...interface{}) string) {
+	fmt.Fprintln(w, heading("HASHES"))
+	fmt.Fprintln(w, divider("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━"))
+	fmt.Fprintf(w, "%s:  %s\n", label(bold("Domain Hash")), formatHash(result.DomainHash))
+	fmt.Fprintf(w, "%s: %s\n", label(bold("Message Hash")), formatHash(result.MessageHash))
+	fmt.Fprintf(w, "%s: %s\n", label(bold("Safe Tx Hash")), formatHash(result.ApproveHash))
+	fmt.Fprintln(w, "")
+
+	// Print the same hashes as a bare block that can be pasted into chat for comparison
+	fmt.Fprintln(w, "Domain, message, and Safe tx hash for copying:")
+	fmt.Fprintln(w, "```")
+	fmt.Fprintln(w, HashBlock(result))
+	fmt.Fprintln(w, "```")
+	fmt.Fprintln(w, "")
+
+	if options.Phonetic {
+		printPhoneticHash(w, result.ApproveHash, heading, divider, label)
+	}
+}
+
+// printRedactionNotice explains that a redacted result's hashes are of the full transaction
+func printRedactionNotice(w io.Writer, warning func(a ...interface{}) string) {
+	fmt.Fprintln(w, warning("🔒 REDACTED: recipient addresses, amounts, and calldata are masked for sharing."))
+	fmt.Fprintln(w, "The hashes are those of the full transaction. Signers must verify it unredacted.")
+	fmt.Fprintln(w, "")
+}
+
+// printRawCalldata prints the calldata of a transaction in full, or word by word with the
+// decoded ABI fields when the calldata is annotated
+func printRawCalldata(w io.Writer, prefix string, tx core.SafeTransaction, options TerminalOptions, heading, divider, label, warning func(a ...interface{}) string) {
+	data := tx.Data
+	if data == core.RedactedData {
+		fmt.Fprintln(w, heading(prefix+"RAW CALLDATA"))
+		fmt.Fprintln(w, divider("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━"))
+		fmt.Fprintln(w, data)
+		fmt.Fprintln(w, "")
+		return
+	}
+	if options.AnnotateCalldata {
+		printCalldataAnnotation(w, prefix+"CALLDATA ANNOTATION", tx.To, uint64(tx.Chain), data, heading, divider, label, warning)
+		return
+	}
+	raw := common.FromHex(data)
+	fmt.Fprintln(w, heading(prefix+"RAW CALLDATA"))
+	fmt.Fprintln(w, divider("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━"))
+	fmt.Fprintf(w, "%s: %d bytes, keccak256 %s\n", label("Length"), len(raw), crypto.Keccak256Hash(raw).Hex())
+	fmt.Fprintf(w, "0x%s\n", hex.EncodeToString(raw))
+	fmt.Fprintln(w, "")
+}
+
+// printProposal prints who proposed a transaction to the Safe service, with which app, and when
+func printProposal(w io.Writer, proposal *core.Proposal, bold, warning func(a ...interface{}) string) {
+	if proposal == nil {
+		return
+	}
+	if proposal.Proposer != "" {
+		proposer := core.ChecksumAddress(proposal.Proposer)
+		switch isOwner, ok := proposal.ProposerIsOwner(); {
+		case ok && isOwner:
+			proposer += " (owner)"
+		case ok:
+			proposer += " " + warning("⚠️  NOT AN OWNER")
+		}
+		fmt.Fprintf(w, "%s: %s\n", bold("Proposed By"), proposer)
+	}
+	switch origin := proposal.Origin(); {
+	case origin == "":
+		fmt.Fprintf(w, "%s: %s\n", bold("Proposed With"), "not recorded")
+	case proposal.KnownOrigin():
+		fmt.Fprintf(w, "%s: %s\n", bold("Proposed With"), origin)
+	default:
+		fmt.Fprintf(w, "%s: %s %s\n", bold("Proposed With"), origin, warning("⚠️  UNKNOWN APP"))
+	}
+	if proposal.Submitted != "" {
+		fmt.Fprintf(w, "%s: %s\n", bold("Proposed At"), proposal.Submitted)
+	}
+}
+
+// printExecution prints where an already executed transaction was executed and, when a node was
+// asked, whether that block is final
+func printExecution(w io.Writer, execution *core.Execution, bold, warning, important func(a ...interface{}) string) {
+	if execution == nil {
+		return
+	}
+	fmt.Fprintf(w, "%s: %s (block %d)\n", bold("Executed In"), execution.TransactionHash, execution.BlockNumber)
+	finality := execution.Finality
+	switch {
+	case finality == nil:
+		fmt.Fprintf(w, "%s: %s\n", bold("Finality"), warning("not checked"))
+	case !finality.Found || !finality.Canonical || finality.Reverted:
+		fmt.Fprintf(w, "%s: %s\n", bold("Finality"), important("❌ NOT ON THE CANONICAL CHAIN OR REVERTED"))
+	case finality.Finalized:
+		fmt.Fprintf(w, "%s: ✅ finalized (block %d, %d confirmations)\n", bold("Finality"), finality.BlockNumber, finality.Confirmations)
+	default:
+		fmt.Fprintf(w, "%s: %s\n", bold("Finality"), warning(fmt.Sprintf("⚠️  NOT FINALIZED (block %d, %d confirmations, finalized block %d)",
+			finality.BlockNumber, finality.Confirmations, finality.FinalizedBlock)))
+	}
+}
+
+// printSchedule prints the signing window of a transaction and the time left until its deadline
+func printSchedule(w io.Writer, status *core.ScheduleStatus, heading, divider, bold, warning, important func(a ...interface{}) string) {
+	if status == nil {
+		return
+	}
+	fmt.Fprintln(w, heading("SIGNING WINDOW"))
+	fmt.Fprintln(w, divider("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━"))
+	if status.Description != "" {
+		fmt.Fprintln(w, status.Description)
+	}
+	if status.Opens != nil {
+		fmt.Fprintf(w, "%s: %s\n", bold("Opens"), status.Opens.UTC().Format(time.RFC3339))
+	}
+	if status.Closes != nil {
+		fmt.Fprintf(w, "%s: %s\n", bold("Deadline"), status.Closes.UTC().Format(time.RFC3339))
+	}
+	switch {
+	case status.Opens != nil && status.CheckedAt.Before(*status.Opens):
+		fmt.Fprintln(w, important("❌ THE SIGNING WINDOW HAS NOT OPENED YET"))
+	case status.Closes != nil && status.Remaining == "":
+		fmt.Fprintln(w, important("❌ THE DEADLINE HAS PASSED"))
+	case status.Closes != nil && status.Closes.Sub(status.CheckedAt) < core.DeadlineWarningPeriod:
+		fmt.Fprintf(w, "%s: %s\n", bold("Time Remaining"), warning(status.Remaining))
+	case status.Closes != nil:
+		fmt.Fprintf(w, "%s: %s\n", bold("Time Remaining"), status.Remaining)
+	}
+	fmt.Fprintln(w, "")
+}
+
+// printPreviousDiff prints what changed since the transaction at an earlier nonce
+func printPreviousDiff(w io.Writer, diff *core.TransactionDiff, heading, divider, bold, label, warning func(a ...interface{}) string) {
+	if diff == nil {
+		return
+	}
+	fmt.Fprintln(w, heading(fmt.Sprintf("CHANGES SINCE NONCE %d", diff.PreviousNonce)))
+	fmt.Fprintln(w, divider("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━"))
+	fmt.Fprintf(w, "%s: %s\n", bold("Previous Safe Tx Hash"), formatHash(diff.PreviousSafeTxHash))
+	if diff.Empty() {
+		fmt.Fprintln(w, "Nothing changed besides the nonce.")
+		fmt.Fprintln(w, "")
+		return
+	}
+	for _, group := range []struct {
+		title   string
+		changes []core.TransactionChange
+	}{
+		{"Transaction fields", diff.TransactionChanges},
+		{"Decoded values", diff.DecodedValueChanges},
+	} {
+		if len(group.changes) == 0 {
+			continue
+		}
+		fmt.Fprintf(w, "%s:\n", bold(group.title))
+		for _, change := range group.changes {
+			switch change.Kind {
+			case core.ChangeAdded:
+				fmt.Fprintf(w, "  %s %s: %s\n", warning("+"), label(change.Field), change.Current)
+			case core.ChangeRemoved:
+				fmt.Fprintf(w, "  %s %s: %s\n", warning("-"), label(change.Field), change.Previous)
+			default:
+				fmt.Fprintf(w, "  %s %s: %s → %s\n", warning("~"), label(change.Field), change.Previous, warning(change.Current))
+			}
+		}
+	}
+	fmt.Fprintln(w, "Check that only the fields you meant to correct changed.")
+	fmt.Fprintln(w, "")
+}
+
+// printRejection explains what a rejection transaction does, since it would otherwise look like
+// an empty call to the Safe
+func printRejection(w io.Writer, prefix string, result *core.VerificationResult, heading, divider, bold, warning func(a ...interface{}) string) {
+	if !result.Rejection {
+		return
+	}
+	fmt.Fprintln(w, heading(prefix+core.RejectionFunctionName))
+	fmt.Fprintln(w, divider("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━"))
+	fmt.Fprintln(w, bold("This transaction rejects whatever else is queued for its nonce."))
+	fmt.Fprintln(w, "It calls the Safe itself with no value and no data, so executing it changes nothing except")
+	fmt.Fprintf(w, "using up nonce %d. %s\n", result.Transaction.Nonce,
+		warning("Any other transaction queued for that nonce can then never execute and must be proposed again."))
+	fmt.Fprintln(w, "Sign it only if you mean to cancel the transaction it replaces.")
+	fmt.Fprintln(w, "")
+}
+
+// printCompliance prints the value flow summary for compliance review
+func printCompliance(w io.Writer, summary *core.ComplianceSummary, heading, divider, bold, label, warning func(a ...interface{}) string) {
+	if summary == nil {
+		return
+	}
+	fmt.Fprintln(w, heading("COMPLIANCE: VALUE FLOWS"))
+	fmt.Fprintln(w, divider("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━"))
+	fmt.Fprintf(w, "%s: %s on %s (chain %d), nonce %d\n", bold("Safe"), summary.Safe, summary.Network, summary.ChainID, summary.Nonce)
+	fmt.Fprintf(w, "%s: %s\n", bold("Safe Tx Hash"), formatHash(summary.SafeTxHash))
+	if len(summary.Flows) == 0 {
+		fmt.Fprintln(w, "No value flows.")
+	}
+	for i, flow := range summary.Flows {
+		asset := flow.Asset
+		if flow.AssetAddress != "" && flow.AssetAddress != flow.Asset {
+			asset = fmt.Sprintf("%s (%s)", flow.Asset, flow.AssetAddress)
+		}
+		amount := flow.Amount
+		if flow.Decimals == nil {
+			amount += " raw units"
+		}
+		counterparty := flow.Counterparty
+		if flow.Label != "" {
+			counterparty = fmt.Sprintf("%s (%s)", flow.Counterparty, flow.Label)
+		}
+		direction := "to"
+		if flow.Type == core.EffectApproval {
+			direction = "approved for"
+		}
+		fmt.Fprintf(w, "%s %s %s %s %s\n", label(fmt.Sprintf("%d. %s", i+1, strings.ToUpper(flow.Type))), amount, asset, direction, counterparty)
+		fmt.Fprintf(w, "   from %s on %s\n", flow.From, flow.Network)
+	}
+	for _, note := range summary.Notes {
+		fmt.Fprintln(w, warning("⚠️  "+note))
+	}
+	fmt.Fprintln(w, "")
+}
+
+// printOwnerDiff prints the owner changes a transaction makes and a before/after table of the
+// Safe's owners and threshold
+func printOwnerDiff(w io.Writer, title string, diff *core.OwnerDiff, heading, divider, label, warning, important func(a ...interface{}) string) {
+	if diff == nil {
+		return
+	}
+
+	fmt.Fprintln(w, heading(title))
+	fmt.Fprintln(w, divider("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━"))
+	fmt.Fprintln(w, label("Changes:"))
+	for _, change := range diff.Changes {
+		if change.Index != 0 {
+			fmt.Fprintf(w, "  #%d %s\n", change.Index, change.Description)
+		} else {
+			fmt.Fprintf(w, "  %s\n", change.Description)
+		}
+	}
+	fmt.Fprintln(w, "")
+
+	switch {
+	case diff.Before == nil:
+		fmt.Fprintln(w, warning("⚠️  Resulting owners not computed: no RPC endpoint was given to read the current owners"))
+	case diff.After == nil:
+		fmt.Fprintf(w, "%s %s\n", important("❌ THE OWNER CHANGES WOULD REVERT:"), diff.Revert)
+	default:
+		added, removed := map[string]bool{}, map[string]bool{}
+		for _, owner := range diff.Added {
+			added[owner] = true
+		}
+		for _, owner := range diff.Removed {
+			removed[owner] = true
+		}
+
+		// Only the last column is colored, so the padding of the others is not thrown off
+		row := "  %-44s %-12s %s\n"
+		fmt.Fprintf(w, "  %-44s %s %s\n", "", label(fmt.Sprintf("%-12s", "Before")), label("After"))
+		fmt.Fprintf(w, row, "Threshold",
+			fmt.Sprintf("%d of %d", diff.Before.Threshold, len(diff.Before.Owners)),
+			fmt.Sprintf("%d of %d", diff.After.Threshold, len(diff.After.Owners)))
+		for _, owner := range diff.After.Owners {
+			if added[owner] {
+				fmt.Fprintf(w, row, owner, "-", warning("added"))
+			} else {
+				fmt.Fprintf(w, row, owner, "owner", "owner")
+			}
+		}
+		for _, owner := range diff.Before.Owners {
+			if removed[owner] {
+				fmt.Fprintf(w, row, owner, "owner", warning("removed"))
+			}
+		}
+	}
+	fmt.Fprintln(w, "")
+}
+
+// printExecutionEffects prints the events an executed transaction emitted and compares its
+// transfers and approvals with those in the calldata
+func printExecutionEffects(w io.Writer, title string, execution *core.Execution, heading, divider, label, warning, important func(a ...interface{}) string) {
+	if execution == nil || execution.Effects == nil {
+		return
+	}
+	effects := execution.Effects
+
+	fmt.Fprintln(w, heading(title))
+	fmt.Fprintln(w, divider("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━"))
+	if effects.Succeeded {
+		fmt.Fprintln(w, "✅ The Safe emitted ExecutionSuccess for this transaction")
+	} else {
+		fmt.Fprintln(w, important("❌ The Safe did not emit ExecutionSuccess for this transaction"))
+	}
+	fmt.Fprintln(w, "")
+
+	fmt.Fprintln(w, label("Events:"))
+	for _, event := range effects.Events {
+		emitter := event.Address
+		if event.Contract != "" {
+			emitter = fmt.Sprintf("%s (%s)", event.Address, event.Contract)
+		}
+		line := fmt.Sprintf("  %d. %s from %s", event.LogIndex, event.Name, emitter)
+		if event.Description != "" {
+			line += ": " + event.Description
+		}
+		fmt.Fprintln(w, line)
+	}
+	fmt.Fprintln(w, "")
+
+	fmt.Fprintln(w, label("Expected vs actual effects:"))
+	// Missing effects are a subset of the expected ones; count them so duplicates are marked once each
+	missing := map[string]int{}
+	for _, effect := range effects.Missing {
+		missing[effect.Description]++
+	}
+	for _, effect := range effects.Expected {
+		switch {
+		case effect.Kind == core.EffectNative:
+			fmt.Fprintf(w, "  ℹ️  %s (native transfers emit no events)\n", effect.Description)
+		case missing[effect.Description] > 0:
+			missing[effect.Description]--
+			fmt.Fprintf(w, "  %s %s\n", important("❌ NO EVENT:"), effect.Description)
+		default:
+			fmt.Fprintf(w, "  ✅ %s\n", effect.Description)
+		}
+	}
+	for _, effect := range effects.Unexpected {
+		fmt.Fprintf(w, "  %s %s\n", warning("⚠️  NOT IN CALLDATA:"), effect.Description)
+	}
+	for _, effect := range effects.Received {
+		fmt.Fprintf(w, "  📥 received %s\n", effect.Description)
+	}
+	if len(effects.Expected) == 0 && len(effects.Unexpected) == 0 && len(effects.Received) == 0 {
+		fmt.Fprintln(w, "  No transfers or approvals")
+	}
+	fmt.Fprintln(w, "")
+}
+
+// printFacilitatorDetails prints what a facilitator tracks during a ceremony: how many owners have
+// signed, whether the Safe service agrees with the local hashes, and links to share with signers
+func printFacilitatorDetails(w io.Writer, result *core.VerificationResult, heading, divider, label, warning, important func(a ...interface{}) string) {
+	fmt.Fprintln(w, heading("FACILITATOR DETAILS"))
+	fmt.Fprintln(w, divider("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━"))
+
+	tx := result.Transaction
+	original := tx
+	if result.NestedResult != nil {
+		original = result.NestedResult.Transaction
+		printSignerProgress(w, "Child signer progress", result.NestedResult.Transaction.SignerProgress, label, warning)
+		printServiceCrossCheck(w, "Child service hash", result.NestedResult.ApproveHash, result.NestedResult.Transaction.ServiceSafeTxHash, label, warning, important)
+		printSignerProgress(w, "Parent signer progress", tx.SignerProgress, label, warning)
+		printServiceCrossCheck(w, "Parent service hash", result.ApproveHash, tx.Nested.ServiceSafeTxHash, label, warning, important)
+	} else {
+		printSignerProgress(w, "Signer progress", tx.SignerProgress, label, warning)
+		printServiceCrossCheck(w, "Service hash", result.ApproveHash, tx.ServiceSafeTxHash, label, warning, important)
+	}
+
+	if link, ok := core.SafeUILink(uint64(tx.Chain), tx.Safe, result.ApproveHash); ok {
+		fmt.Fprintf(w, "%s: %s\n", label("Safe UI"), link)
+	}
+	// A redacted transaction cannot be scanned, and the full one would reveal what was masked
+	if link, err := QRLink(original, ""); err == nil && !result.Redacted {
+		fmt.Fprintf(w, "%s: %s\n", label("QR codes"), link)
+	}
+	fmt.Fprintln(w, "")
+}
+
+// printSignerProgress prints how many owner signatures the Safe service has collected
+func printSignerProgress(w io.Writer, title string, progress *core.SignerProgress, label, warning func(a ...interface{}) string) {
+	if progress == nil {
+		fmt.Fprintf(w, "%s: %s\n", label(title), warning("not reported (transaction was not fetched from the Safe service)"))
+		return
+	}
+	fmt.Fprintf(w, "%s: %d of %d signatures collected\n", label(title), len(progress.Confirmations), progress.Required)
+	for _, confirmation := range progress.Confirmations {
+		fmt.Fprintf(w, "  ✍️  %s (%s)\n", core.ChecksumAddress(confirmation.Owner), confirmation.SubmissionDate)
+	}
+}
+
+// printServiceCrossCheck prints whether the Safe service reported the same safeTxHash as computed locally
+func printServiceCrossCheck(w io.Writer, title, localHash, serviceHash string, label, warning, important func(a ...interface{}) string) {
+	switch {
+	case serviceHash == "":
+		fmt.Fprintf(w, "%s: %s\n", label(title), warning("not available to cross-check"))
+	case strings.EqualFold(localHash, serviceHash):
+		fmt.Fprintf(w, "%s: ✅ matches the locally computed hash\n", label(title))
+	default:
+		fmt.Fprintf(w, "%s: %s\n", label(title), important("❌ "+formatHash(serviceHash)+" DOES NOT MATCH — DO NOT SIGN"))
+	}
+}
+
+// FormatTransferTerminal outputs a Safe transfer record in a human-readable format. Transfers are
+// not multisig transactions, so there are no hashes to verify; the output explains this and shows
+// what moved, in which direction, and where to find it on-chain.
+func FormatTransferTerminal(result *core.TransferResult, w io.Writer) error {
+	heading := themed(colorHeading).SprintFunc()
+	divider := themed(colorDivider).SprintFunc()
+	bold := themed(colorBold).SprintFunc()
+	warning := themed(colorWarning).SprintFunc()
+
+	transfer := result.Transfer
+
+	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, warning("ℹ️  THIS ITEM IS A TRANSFER, NOT A MULTISIG TRANSACTION  ℹ️"))
+	fmt.Fprintln(w, divider("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━"))
+	fmt.Fprintln(w, bold("Transfers are "+core.QueueItemTransfer.Explain()+"."))
+	fmt.Fprintln(w, "")
+
+	network, isKnownNetwork := core.ChainNames[uint64(result.Chain)]
+	chainDisplay := fmt.Sprintf("%d", result.Chain)
+	if isKnownNetwork {
+		chainDisplay = fmt.Sprintf("%d (%s 🔍)", result.Chain, network)
+	}
+
+	asset := transfer.Asset()
+	if transfer.TokenAddress != "" {
+		asset = fmt.Sprintf("%s (%s)", asset, core.ChecksumAddress(transfer.TokenAddress))
+	}
+
+	fmt.Fprintln(w, heading("TRANSFER SUMMARY"))
+	fmt.Fprintln(w, divider("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━"))
+	fmt.Fprintf(w, "%s: %s\n", bold("Safe"), core.ChecksumAddress(result.Safe))
+	fmt.Fprintf(w, "%s: %s\n", bold("Chain ID"), chainDisplay)
+	fmt.Fprintf(w, "%s: %s\n", bold("Type"), transfer.Type)
+	fmt.Fprintf(w, "%s: %s\n", bold("Direction"), result.Direction())
+	fmt.Fprintf(w, "%s: %s\n", bold("From"), core.ChecksumAddress(transfer.From))
+	fmt.Fprintf(w, "%s: %s\n", bold("To"), core.ChecksumAddress(transfer.To))
+	fmt.Fprintf(w, "%s: %s\n", bold("Asset"), asset)
+	fmt.Fprintf(w, "%s: %s\n", bold("Amount"), transfer.DisplayAmount())
+	fmt.Fprintf(w, "%s: %s\n", bold("Tx Hash"), transfer.TransactionHash)
+	fmt.Fprintf(w, "%s: %d\n", bold("Block"), transfer.BlockNumber)
+	fmt.Fprintf(w, "%s: %s\n", bold("Executed"), transfer.ExecutionDate)
+	fmt.Fprintln(w, "")
+
+	return nil
+}
+
+// FormatModuleTransactionsTerminal outputs module transactions in a human-readable format. Module
+// transactions were executed without owner signatures, so there is nothing to sign; the output is
+// meant for reviewers auditing what a Safe's enabled modules have done.
+func FormatModuleTransactionsTerminal(results []core.ModuleTransactionResult, w io.Writer) error {
+	heading := themed(colorHeading).SprintFunc()
+	divider := themed(colorDivider).SprintFunc()
+	label := themed(colorLabel).SprintFunc()
+	bold := themed(colorBold).SprintFunc()
+	yellow := themed(colorCaution).SprintFunc()
+	warning := themed(colorWarning).SprintFunc()
+	important := themed(colorImportant).SprintFunc()
+
+	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, warning("ℹ️  MODULE TRANSACTIONS WERE EXECUTED WITHOUT OWNER SIGNATURES  ℹ️"))
+	fmt.Fprintln(w, divider("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━"))
+	fmt.Fprintln(w, bold("Module transactions are "+core.QueueItemModule.Explain()+"."))
+	fmt.Fprintln(w, "")
+
+	if len(results) == 0 {
+		fmt.Fprintln(w, "No module transactions found.")
+		fmt.Fprintln(w, "")
+		return nil
+	}
+
+	for i, result := range results {
+		moduleDisplay := core.ChecksumAddress(result.Module)
+		if info, ok := core.GetKnownContract(result.Module, uint64(result.Chain)); ok {
+			moduleDisplay = fmt.Sprintf("%s (%s 🔍)", moduleDisplay, info.Name)
+		}
+
+		status := "SUCCESS"
+		if !result.IsSuccessful {
+			status = important("FAILED")
+		}
+
+		operation := "CALL"
+		if result.Operation == 1 {
+			operation = important("DELEGATECALL")
+		}
+
+		fmt.Fprintln(w, heading(fmt.Sprintf("MODULE TRANSACTION %d OF %d", i+1, len(results))))
+		fmt.Fprintln(w, divider("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━"))
+		fmt.Fprintf(w, "%s: %s\n", bold("Safe"), core.ChecksumAddress(result.Safe))
+		fmt.Fprintf(w, "%s: %s\n", bold("Module"), moduleDisplay)
+		fmt.Fprintf(w, "%s: %s\n", bold("ETH Value"), core.ParseDecimals(result.Value, 18))
+		fmt.Fprintf(w, "%s: %s\n", bold("Operation"), operation)
+		fmt.Fprintf(w, "%s: %s\n", bold("Status"), status)
+		fmt.Fprintf(w, "%s: %s\n", bold("Tx Hash"), result.TransactionHash)
+		fmt.Fprintf(w, "%s: %d\n", bold("Block"), result.BlockNumber)
+		fmt.Fprintf(w, "%s: %s\n", bold("Executed"), result.ExecutionDate)
+		fmt.Fprintln(w, "")
+
+		printCallDetails(w, result.Call, 0, TerminalOptions{}, heading, divider, label, yellow, bold)
+	}
+
+	return nil
+}
+
+// FormatUserOperationTerminal outputs a verified ERC-4337 UserOperation for a Safe operated
+// through Safe4337Module, with the SafeOp hashes the owners sign
+func FormatUserOperationTerminal(result *core.UserOperationResult, w io.Writer) error {
+	heading := themed(colorHeading).SprintFunc()
+	divider := themed(colorDivider).SprintFunc()
+	label := themed(colorLabel).SprintFunc()
+	bold := themed(colorBold).SprintFunc()
+	yellow := themed(colorCaution).SprintFunc()
+	warning := themed(colorWarning).SprintFunc()
+	important := themed(colorImportant).SprintFunc()
+
+	chainID := uint64(result.Chain)
+	chainName, ok := core.ChainNames[chainID]
+	if !ok {
+		chainName = "unknown chain"
+	}
+	operation := "CALL"
+	if result.Operation == 1 {
+		operation = important("DELEGATECALL")
+	}
+	validity := func(timestamp uint64) string {
+		if timestamp == 0 {
+			return "none"
+		}
+		return fmt.Sprintf("%d (%s)", timestamp, time.Unix(int64(timestamp), 0).UTC().Format(time.RFC3339))
+	}
+
+	fmt.Fprintln(w, "")
+	printWarnings(w, result.Warnings, heading, divider, warning, important)
+
+	fmt.Fprintln(w, heading("USER OPERATION SUMMARY"))
+	fmt.Fprintln(w, divider("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━"))
+	fmt.Fprintf(w, "%s: %s\n", bold("Safe"), result.Safe)
+	fmt.Fprintf(w, "%s: %d (%s)\n", bold("Chain ID"), result.Chain, chainName)
+	fmt.Fprintf(w, "%s: %s (Safe4337Module v%s)\n", bold("Module"), result.Module, result.ModuleVersion)
+	fmt.Fprintf(w, "%s: %s\n", bold("EntryPoint"), result.EntryPoint)
+	fmt.Fprintf(w, "%s: %s (key %s)\n", bold("Nonce"), result.NonceSequence, result.NonceKey)
+	fmt.Fprintf(w, "%s: %s\n", bold("Target"), core.ChecksumAddress(result.To))
+	fmt.Fprintf(w, "%s: %s\n", bold("ETH Value"), core.ParseDecimals(result.Value, 18))
+	fmt.Fprintf(w, "%s: %s\n", bold("Operation"), operation)
+	fmt.Fprintf(w, "%s: %s\n", bold("Valid After"), validity(result.Request.ValidAfter))
+	fmt.Fprintf(w, "%s: %s\n", bold("Valid Until"), validity(result.Request.ValidUntil))
+	if result.Factory != "" {
+		fmt.Fprintf(w, "%s: %s\n", bold("Factory"), warning(result.Factory))
+	}
+	paymaster := "none (the Safe pays for gas)"
+	if result.Paymaster != "" {
+		paymaster = warning(result.Paymaster)
+	}
+	fmt.Fprintf(w, "%s: %s\n", bold("Paymaster"), paymaster)
+	fmt.Fprintf(w, "%s: %s ETH\n", bold("Max Gas Cost"), core.ParseDecimals(result.MaxGasCost, 18))
+	fmt.Fprintln(w, "")
+
+	printCallDetails(w, result.Call, 0, TerminalOptions{}, heading, divider, label, yellow, bold)
+
+	fmt.Fprintln(w, heading("HASHES"))
+	fmt.Fprintln(w, divider("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━"))
+	fmt.Fprintf(w, "%s:  %s\n", label(bold("Domain Hash")), formatHash(result.DomainHash))
+	fmt.Fprintf(w, "%s: %s\n", label(bold("Message Hash")), formatHash(result.MessageHash))
+	fmt.Fprintf(w, "%s:  %s\n", label(bold("SafeOp Hash")), formatHash(result.SafeOpHash))
+	fmt.Fprintf(w, "%s:  %s\n", label(bold("UserOp Hash")), formatHash(result.UserOpHash))
+	fmt.Fprintln(w, "")
+
+	fmt.Fprintln(w, heading("VERIFICATION INSTRUCTIONS"))
+	fmt.Fprintln(w, divider("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━"))
+	fmt.Fprintf(w, "%s\n", bold("1. Owners sign the SafeOp, not a Safe transaction; there is no Safe tx hash to compare."))
+	fmt.Fprintf(w, "%s\n", bold("2. Your hardware wallet should show the EXACT SAME domain and message hashes."))
+	fmt.Fprintf(w, "%s\n", bold("3. The UserOp hash is what bundlers and explorers show once the operation is submitted."))
+	fmt.Fprintf(w, "%s\n", bold("4. WHEN IN DOUBT, ASK FOR HELP."))
+	fmt.Fprintln(w, "")
+
+	return nil
+}
+
+// FormatMessagesTerminal outputs Safe off-chain messages and their hashes in a human-readable format
+func FormatMessagesTerminal(results []core.MessageResult, w io.Writer) error {
+	heading := themed(colorHeading).SprintFunc()
+	divider := themed(colorDivider).SprintFunc()
+	label := themed(colorLabel).SprintFunc()
+	bold := themed(colorBold).SprintFunc()
+	yellow := themed(colorCaution).SprintFunc()
+	warning := themed(colorWarning).SprintFunc()
+	important := themed(colorImportant).SprintFunc()
+
+	fmt.Fprintln(w, "")
+	if len(results) == 0 {
+		fmt.Fprintln(w, "No messages found.")
+		fmt.Fprintln(w, "")
+		return nil
+	}
+
+	for i, result := range results {
+		printWarnings(w, result.Warnings, heading, divider, warning, important)
+
+		fmt.Fprintln(w, heading(fmt.Sprintf("MESSAGE %d OF %d", i+1, len(results))))
+		fmt.Fprintln(w, divider("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━"))
+		fmt.Fprintf(w, "%s: %s\n", bold("Safe"), core.ChecksumAddress(result.Safe))
+		fmt.Fprintf(w, "%s: %d\n", bold("Chain ID"), result.Chain)
+		fmt.Fprintf(w, "%s: %s\n", bold("Proposed By"), core.ChecksumAddress(result.ProposedBy))
+		fmt.Fprintf(w, "%s: %s\n", bold("Created"), result.Created)
+		if result.Threshold > 0 {
+			fmt.Fprintf(w, "%s: %d of %d\n", bold("Confirmations"), result.Confirmations, result.Threshold)
+		} else {
+			fmt.Fprintf(w, "%s: %d\n", bold("Confirmations"), result.Confirmations)
+		}
+		fmt.Fprintln(w, "")
+
+		switch message := result.Message.(type) {
+		case string:
+			fmt.Fprintln(w, heading("MESSAGE TEXT"))
+			fmt.Fprintln(w, divider("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━"))
+			fmt.Fprintln(w, message)
+			fmt.Fprintln(w, "")
+		case apitypes.TypedData:
+			fmt.Fprintln(w, heading("TYPED DATA"))
+			fmt.Fprintln(w, divider("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━"))
+			fmt.Fprintf(w, "%s: %s\n", bold("Primary Type"), message.PrimaryType)
+			fmt.Fprintln(w, bold("Domain:"))
+			domain := message.Domain.Map()
+			for _, field := range message.Types["EIP712Domain"] {
+				prettyPrintValue(w, field.Name, domain[field.Name], yellow, "  ", 0, "", nil)
+			}
+			fmt.Fprintln(w, bold("Message:"))
+			for _, field := range message.Types[message.PrimaryType] {
+				prettyPrintValue(w, field.Name, message.Message[field.Name], yellow, "  ", 0, "", nil)
+			}
+			fmt.Fprintln(w, "")
+		}
+
+		fmt.Fprintln(w, heading("HASHES"))
+		fmt.Fprintln(w, divider("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━"))
+		fmt.Fprintf(w, "%s:    %s\n", label(bold("Domain Hash")), formatHash(result.DomainHash))
+		fmt.Fprintf(w, "%s:      %s\n", label(bold("Data Hash")), formatHash(result.DataHash))
+		fmt.Fprintf(w, "%s: %s\n", label(bold("Safe Msg Hash")), formatHash(result.MessageHash))
+		fmt.Fprintln(w, "")
+	}
+
+	return nil
+}
+
+// HashBlock returns the domain, message, and Safe tx hashes of a result, one per line, in the
+// same format the terminal output displays them
+func HashBlock(result *core.VerificationResult) string {
+	return strings.Join([]string{
+		formatHash(result.DomainHash),
+		formatHash(result.MessageHash),
+		formatHash(result.ApproveHash),
+	}, "\n")
+}
+
+// hashDisplayNames maps core hash names to the labels used elsewhere in the terminal output
+var hashDisplayNames = map[string]string{
+	core.HashDomain:  "Domain Hash",
+	core.HashMessage: "Message Hash",
+	core.HashSafeTx:  "Safe Tx Hash",
+}
+
+// FormatHashComparisonTerminal outputs a per-hash match/mismatch report for compare-hashes
+func FormatHashComparisonTerminal(comparisons []core.HashComparison, w io.Writer) error {
+	heading := themed(colorHeading).SprintFunc()
+	divider := themed(colorDivider).SprintFunc()
+	bold := themed(colorBold).SprintFunc()
+	good := themed(colorSuccess).SprintFunc()
+	important := themed(colorImportant).SprintFunc()
+	warning := themed(colorCaution).SprintFunc()
+
+	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, heading("HASH COMPARISON"))
+	fmt.Fprintln(w, divider("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━"))
+
+	for _, comparison := range comparisons {
+		sources := make([]string, 0, len(comparison.Values))
+		for source := range comparison.Values {
+			sources = append(sources, source)
+		}
+		sort.Strings(sources)
+
+		if comparison.Match {
+			fmt.Fprintf(w, "%s %s: %s (%d sources)\n", good("✅"), bold(hashDisplayNames[comparison.Name]), good("MATCH"), len(sources))
+			fmt.Fprintf(w, "   %s\n", formatHash(comparison.Values[sources[0]]))
+		} else {
+			fmt.Fprintf(w, "%s %s: %s\n", important("❌"), bold(hashDisplayNames[comparison.Name]), important("MISMATCH — DO NOT SIGN"))
+			for _, source := range sources {
+				fmt.Fprintf(w, "   %s  %s\n", formatHash(comparison.Values[source]), source)
+			}
+		}
+		if len(comparison.Missing) > 0 {
+			fmt.Fprintf(w, "   %s\n", warning("not reported by: "+strings.Join(comparison.Missing, ", ")))
+		}
+		fmt.Fprintln(w, "")
+	}
+
+	return nil
+}
+
+// FormatSignatureTerminal outputs an owner signature carried across the air gap. target is set
+// once the signature has been checked against a transaction on the receiving machine.
+func FormatSignatureTerminal(export core.SignatureExport, target string, w io.Writer) error {
+	heading := themed(colorHeading).SprintFunc()
+	divider := themed(colorDivider).SprintFunc()
+	bold := themed(colorBold).SprintFunc()
+	good := themed(colorSuccess).SprintFunc()
+
+	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, heading("OWNER SIGNATURE"))
+	fmt.Fprintln(w, divider("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━"))
+	fmt.Fprintf(w, "%s: %s\n", bold("Signer"), export.Signer)
+	fmt.Fprintf(w, "%s: %s\n", bold("Safe"), export.Safe)
+	fmt.Fprintf(w, "%s: %d\n", bold("Chain ID"), export.Chain)
+	fmt.Fprintf(w, "%s: %d\n", bold("Nonce"), export.Nonce)
+	fmt.Fprintf(w, "%s: %s\n", bold("Safe Tx Hash"), formatHash(export.SafeTxHash))
+	fmt.Fprintf(w, "%s: %s\n", bold("Signature"), export.Signature)
+	fmt.Fprintln(w, "")
+
+	if target == "" {
+		fmt.Fprintln(w, "Check the signer is your owner address before letting the other machine scan this.")
+		fmt.Fprintln(w, "")
+		return nil
+	}
+
+	fmt.Fprintf(w, "%s\n", good(fmt.Sprintf("✅ Signature recovers to the signer and matches the %s hash computed here.", target)))
+	fmt.Fprintln(w, "Check the signer is an owner of the Safe before submitting it.")
+	if service, ok := core.SafeServiceURLs[uint64(export.Chain)]; ok {
+		fmt.Fprintln(w, "")
+		fmt.Fprintln(w, "To submit it to the Safe service:")
+		fmt.Fprintf(w, "curl -X POST -H 'Content-Type: application/json' -d '{\"signature\":\"%s\"}' %s/api/v1/multisig-transactions/%s/confirmations/\n",
+			export.Signature, service, export.SafeTxHash)
+	}
+	fmt.Fprintln(w, "")
+	return nil
+}
+
+// FormatBuildProvenanceTerminal outputs how the running binary was built
+func FormatBuildProvenanceTerminal(provenance *core.BuildProvenance, w io.Writer, verbose bool) error {
+	heading := themed(colorHeading).SprintFunc()
+	divider := themed(colorDivider).SprintFunc()
+	bold := themed(colorBold).SprintFunc()
+	label := themed(colorLabel).SprintFunc()
+	warning := themed(colorWarning).SprintFunc()
+	important := themed(colorImportant).SprintFunc()
+
+	fmt.Fprintln(w, "")
+	printWarnings(w, provenance.Warnings, heading, divider, warning, important)
+
+	fmt.Fprintln(w, heading("BUILD PROVENANCE"))
+	fmt.Fprintln(w, divider("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━"))
+	fmt.Fprintf(w, "%s: %s\n", bold("Version"), provenance.Version)
+	fmt.Fprintf(w, "%s: %s\n", bold("Commit"), provenance.Commit)
+	fmt.Fprintf(w, "%s: %s %s\n", bold("Module"), provenance.Module.Path, provenance.Module.Version)
+	fmt.Fprintf(w, "%s: %s\n", bold("Go"), provenance.GoVersion)
+	fmt.Fprintln(w, "")
+
+	fmt.Fprintln(w, heading("BUILD SETTINGS"))
+	fmt.Fprintln(w, divider("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━"))
+	keys := make([]string, 0, len(provenance.Settings))
+	for key := range provenance.Settings {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fmt.Fprintf(w, "%s: %s\n", label(key), provenance.Settings[key])
+	}
+	fmt.Fprintln(w, "")
+
+	fmt.Fprintln(w, heading("DIGESTS"))
+	fmt.Fprintln(w, divider("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━"))
+	fmt.Fprintf(w, "%s: %s\n", label("Build inputs"), provenance.InputsDigest)
+	if provenance.ExecutableDigest != "" {
+		fmt.Fprintf(w, "%s:   %s\n", label("Executable"), provenance.ExecutableDigest)
+	}
+	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, "Compare the executable digest with the release SHA256SUMS file, or rebuild with")
+	fmt.Fprintln(w, "`just reproduce` at the same commit and compare both digests.")
+	fmt.Fprintln(w, "")
+
+	if verbose {
+		fmt.Fprintln(w, heading(fmt.Sprintf("DEPENDENCIES (%d)", len(provenance.Deps))))
+		fmt.Fprintln(w, divider("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━"))
+		for _, dep := range provenance.Deps {
+			line := fmt.Sprintf("%s %s %s", dep.Path, dep.Version, dep.Sum)
+			if dep.Replace != "" {
+				line += " => " + dep.Replace
+			}
+			fmt.Fprintln(w, line)
+		}
+		fmt.Fprintln(w, "")
+	}
+
+	return nil
+}
+
+// FormatWarningChecksTerminal lists the checks that raise warnings, by code
+func FormatWarningChecksTerminal(checks []core.WarningCheck, w io.Writer) error {
+	heading := themed(colorHeading).SprintFunc()
+	divider := themed(colorDivider).SprintFunc()
+	label := themed(colorLabel).SprintFunc()
+
+	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, heading("WARNING CODES"))
+	fmt.Fprintln(w, divider("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━"))
+	for _, check := range checks {
+		fmt.Fprintf(w, "%s  %-8s  %s\n", label(check.Code), check.Severity, check.Title)
+	}
+	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, "Run `op-txverify explain <code>` for what a warning means and what to do about it.")
+	fmt.Fprintln(w, "")
+	return nil
+}
+
+// FormatWarningCheckTerminal explains a check: what its warning means, why it matters, and what
+// the signer should do
+func FormatWarningCheckTerminal(check *core.WarningCheck, w io.Writer) error {
+	heading := themed(colorHeading).SprintFunc()
+	divider := themed(colorDivider).SprintFunc()
+	bold := themed(colorBold).SprintFunc()
+	warning := themed(colorWarning).SprintFunc()
+	important := themed(colorImportant).SprintFunc()
+
+	severity := strings.ToUpper(string(check.Severity))
+	switch check.Severity {
+	case core.SeverityCritical:
+		severity = important("❌ " + severity)
+	case core.SeverityWarning:
+		severity = warning("⚠️  " + severity)
+	default:
+		severity = "ℹ️  " + severity
+	}
+
+	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, heading(check.Code+": "+strings.ToUpper(check.Title)))
+	fmt.Fprintln(w, divider("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━"))
+	fmt.Fprintf(w, "%s: %s\n", bold("Severity"), severity)
+	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, bold("What it means"))
+	fmt.Fprintln(w, check.Meaning)
+	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, bold("Why it matters"))
+	fmt.Fprintln(w, check.Why)
+	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, bold("What to do"))
+	fmt.Fprintln(w, check.Action)
+	fmt.Fprintln(w, "")
+	return nil
+}
+
+// FormatCheckManifestTerminal lists the analyzers of a build, when each runs, its parameters, and
+// the warnings it raises
+func FormatCheckManifestTerminal(manifest *core.CheckManifest, w io.Writer) error {
+	heading := themed(colorHeading).SprintFunc()
+	divider := themed(colorDivider).SprintFunc()
+	bold := themed(colorBold).SprintFunc()
+	label := themed(colorLabel).SprintFunc()
+
+	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, heading("CHECK MANIFEST"))
+	fmt.Fprintln(w, divider("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━"))
+	fmt.Fprintf(w, "%s: %s (%s)\n", bold("Build"), manifest.Version, manifest.Commit)
+	fmt.Fprintf(w, "%s: %d analyzers, %d warning codes\n", bold("Checks"), len(manifest.Analyzers), len(manifest.Checks))
+	fmt.Fprintf(w, "%s: %s\n", bold("Digest"), manifest.Digest)
+
+	for _, analyzer := range manifest.Analyzers {
+		fmt.Fprintln(w, "")
+		fmt.Fprintf(w, "%s v%d\n", label(analyzer.Name), analyzer.Version)
+		fmt.Fprintf(w, "  %s\n", analyzer.Description)
+		runs := analyzer.RunsWhen
+		if analyzer.Option != "" {
+			runs = fmt.Sprintf("with %s", analyzer.Option)
+		}
+		fmt.Fprintf(w, "  %s: %s\n", bold("Runs"), runs)
+
+		var names []string
+		for name := range analyzer.Parameters {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fmt.Fprintf(w, "  %s: %s\n", bold(name), analyzer.Parameters[name])
+		}
+		if len(analyzer.Warnings) > 0 {
+			fmt.Fprintf(w, "  %s: %s\n", bold("Warnings"), strings.Join(analyzer.Warnings, ", "))
+		}
+	}
+	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, "Run `op-txverify explain <code>` for what a warning means and what to do about it.")
+	fmt.Fprintln(w, "")
+	return nil
+}
+
+// FormatAirdropCheckTerminal outputs the result of cross-checking a CSV airdrop file against the
+// transfers a batch transaction makes
+func FormatAirdropCheckTerminal(check *core.AirdropCheck, w io.Writer) error {
+	heading := themed(colorHeading).SprintFunc()
+	divider := themed(colorDivider).SprintFunc()
+	bold := themed(colorBold).SprintFunc()
+	label := themed(colorLabel).SprintFunc()
+	success := themed(colorSuccess).SprintFunc()
+	warning := themed(colorWarning).SprintFunc()
+	important := themed(colorImportant).SprintFunc()
+
+	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, heading("AIRDROP FILE CROSS-CHECK"))
+	fmt.Fprintln(w, divider("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━"))
+	fmt.Fprintf(w, "%s: %d\n", bold("CSV Rows"), check.Rows)
+	fmt.Fprintf(w, "%s: %d\n", bold("Transaction Calls"), check.Transfers)
+	fmt.Fprintf(w, "%s: %d\n", bold("Matched"), check.Matched)
+	fmt.Fprintln(w, "")
+
+	if len(check.Totals) > 0 {
+		fmt.Fprintln(w, heading("TOTALS SENT BY THE TRANSACTION"))
+		fmt.Fprintln(w, divider("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━"))
+		printAirdropTotals(w, check.Totals, label)
+		fmt.Fprintln(w, "")
+	}
+
+	if check.OK() {
+		fmt.Fprintln(w, success("✅ Every CSV row matches a transfer and the transaction sends nothing else."))
+		fmt.Fprintln(w, "")
+		return nil
+	}
+
+	fmt.Fprintln(w, heading("DIVERGENCES"))
+	fmt.Fprintln(w, divider("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━"))
+	for _, divergence := range check.Divergences {
+		switch divergence.Kind {
+		case core.AirdropUnverified:
+			fmt.Fprintf(w, "%s %s\n", warning("⚠️  UNVERIFIED:"), divergence.Message)
+		default:
+			fmt.Fprintf(w, "%s %s\n", important("❌ "+strings.ToUpper(divergence.Kind)+":"), divergence.Message)
+		}
+	}
+	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, important("The transaction does not do what the CSV file says. DO NOT SIGN until this is explained."))
+	fmt.Fprintln(w, "")
+
+	return nil
+}
+
+// printAirdropTotals lists the amount sent of each token
+func printAirdropTotals(w io.Writer, totals []core.AirdropTotal, label func(a ...interface{}) string) {
+	for _, total := range totals {
+		token := "ETH"
+		if total.TokenType != core.AirdropNative {
+			token = fmt.Sprintf("%s %s", strings.ToUpper(total.TokenType), total.Token)
+		}
+		fmt.Fprintf(w, "%s: %s in %d transfers\n", label(token), total.Amount, total.Transfers)
+	}
+}
+
+// FormatRPCHealthTerminal prints the health checks of RPC endpoints, grouped by chain
+func FormatRPCHealthTerminal(checks []core.RPCHealth, w io.Writer) error {
+	heading := themed(colorHeading).SprintFunc()
+	divider := themed(colorDivider).SprintFunc()
+	label := themed(colorLabel).SprintFunc()
+	important := themed(colorImportant).SprintFunc()
+
+	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, heading("RPC ENDPOINTS"))
+	fmt.Fprintln(w, divider("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━"))
+	for i, health := range checks {
+		if i == 0 || checks[i-1].ExpectedChainID != health.ExpectedChainID {
+			name := core.ChainNames[health.ExpectedChainID]
+			if name == "" {
+				name = "unknown chain"
+			}
+			fmt.Fprintln(w, label(fmt.Sprintf("Chain %d (%s)", health.ExpectedChainID, name)))
+		}
+		if health.Healthy() {
+			fmt.Fprintf(w, "  ✅ %s: block %d (%s old), %s\n", health.URL, health.LatestBlock, health.BlockAge, health.Latency.Round(time.Millisecond))
+			continue
+		}
+		fmt.Fprintf(w, "  %s %s: %s\n", important("❌"), health.URL, important(strings.Join(health.Problems, "; ")))
+	}
+	fmt.Fprintln(w, "")
+	return nil
+}
+
+// FormatDoctorReportTerminal prints the readiness checks of a machine before a ceremony and
+// whether it is ready
+func FormatDoctorReportTerminal(report *doctor.Report, w io.Writer) error {
+	heading := themed(colorHeading).SprintFunc()
+	divider := themed(colorDivider).SprintFunc()
+	bold := themed(colorBold).SprintFunc()
+	label := themed(colorLabel).SprintFunc()
+	success := themed(colorSuccess).SprintFunc()
+	warning := themed(colorWarning).SprintFunc()
+	important := themed(colorImportant).SprintFunc()
+
+	mode := "online"
+	if report.Offline {
+		mode = "offline"
+	}
+	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, heading("CEREMONY READINESS"))
+	fmt.Fprintln(w, divider("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━"))
+	fmt.Fprintf(w, "%s: %s (%s)\n", bold("Build"), report.Version, report.Commit)
+	fmt.Fprintf(w, "%s: %s\n", bold("Checked At"), report.CheckedAt.Format(time.RFC3339))
+	fmt.Fprintf(w, "%s: %s\n", bold("Mode"), mode)
+	fmt.Fprintln(w, "")
+	for _, check := range report.Checks {
+		var status string
+		switch check.Status {
+		case doctor.Pass:
+			status = success("✅")
+		case doctor.Warn:
+			status = warning("⚠️ ")
+		case doctor.Fail:
+			status = important("❌")
+		default:
+			status = "ℹ️ "
+		}
+		detail := check.Detail
+		if check.Status == doctor.Fail {
+			detail = important(detail)
+		}
+		fmt.Fprintf(w, "%s %s %s\n", status, label(fmt.Sprintf("%-9s", check.Name)), detail)
+	}
+	fmt.Fprintln(w, "")
+	if report.Ready() {
+		fmt.Fprintln(w, success(fmt.Sprintf("READY (%d warning(s) to review)", report.Count(doctor.Warn))))
+	} else {
+		fmt.Fprintln(w, important(fmt.Sprintf("NOT READY: %d check(s) failed", report.Count(doctor.Fail))))
+	}
+	fmt.Fprintln(w, "")
+	return nil
+}
+
+// FormatSafeProfilesTerminal lists saved Safe profiles
+func FormatSafeProfilesTerminal(profiles []safes.Profile, w io.Writer) error {
+	heading := themed(colorHeading).SprintFunc()
+	divider := themed(colorDivider).SprintFunc()
+	label := themed(colorLabel).SprintFunc()
+	bold := themed(colorBold).SprintFunc()
+
+	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, heading("SAFE PROFILES"))
+	fmt.Fprintln(w, divider("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━"))
+	if len(profiles) == 0 {
+		fmt.Fprintln(w, "No saved profiles. Add one with `op-txverify safes add`.")
+	}
+	for i, profile := range profiles {
+		if i > 0 {
+			fmt.Fprintln(w, "")
+		}
+		fmt.Fprintln(w, bold(profile.Name))
+		if profile.Description != "" {
+			fmt.Fprintf(w, "  %s\n", profile.Description)
+		}
+		fmt.Fprintf(w, "  %s %s\n", label("Network:"), profile.Network)
+		fmt.Fprintf(w, "  %s %s\n", label("Safe:"), profile.Safe)
+		if profile.Policy != "" {
+			fmt.Fprintf(w, "  %s %s\n", label("Policy:"), profile.Policy)
+		}
+	}
+	fmt.Fprintln(w, "")
+	return nil
+}
+
+// FormatCeremonyTerminal outputs the check of every transaction of a ceremony, followed by the
+// hashes each signer signs
+func FormatCeremonyTerminal(report *core.CeremonyReport, w io.Writer) error {
+	heading := themed(colorHeading).SprintFunc()
+	divider := themed(colorDivider).SprintFunc()
+	bold := themed(colorBold).SprintFunc()
+	label := themed(colorLabel).SprintFunc()
+	success := themed(colorSuccess).SprintFunc()
+	important := themed(colorImportant).SprintFunc()
+
+	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, heading("CEREMONY: "+strings.ToUpper(report.Name)))
+	fmt.Fprintln(w, divider("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━"))
+	for _, check := range report.Transactions {
+		mark := success("✅")
+		if len(check.Problems) > 0 {
+			mark = important("❌")
+		}
+		fmt.Fprintf(w, "%s %s: nonce %d of %s on %s\n", mark, bold(check.Name), check.Nonce, check.Safe, check.Network)
+		fmt.Fprintf(w, "   %s %s\n", label("Safe Tx Hash:"), formatHash(check.SafeTxHash))
+		if check.ChildSafeTxHash != "" {
+			fmt.Fprintf(w, "   %s %s\n", label("Approves Child:"), formatHash(check.ChildSafeTxHash))
+		}
+		for _, problem := range check.Problems {
+			fmt.Fprintf(w, "   %s\n", important(problem))
+		}
+	}
+	for _, problem := range report.Problems {
+		fmt.Fprintf(w, "%s %s\n", important("❌"), important(problem))
+	}
+	fmt.Fprintln(w, "")
+
+	fmt.Fprintln(w, heading("HASHES BY SIGNER"))
+	fmt.Fprintln(w, divider("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━"))
+	for i, signer := range report.Signers {
+		if i > 0 {
+			fmt.Fprintln(w, "")
+		}
+		fmt.Fprintln(w, bold(signer.Signer))
+		for _, signature := range signer.Signatures {
+			fmt.Fprintf(w, "  %s: nonce %d of %s on %s\n", label(signature.Transaction), signature.Nonce, signature.Safe, signature.Network)
+			fmt.Fprintf(w, "    Domain Hash:  %s\n", formatHash(signature.DomainHash))
+			fmt.Fprintf(w, "    Message Hash: %s\n", formatHash(signature.MessageHash))
+			fmt.Fprintf(w, "    Safe Tx Hash: %s\n", formatHash(signature.SafeTxHash))
+		}
+	}
+	fmt.Fprintln(w, "")
+
+	if report.OK() {
+		fmt.Fprintln(w, success("✅ Every transaction makes its intended call and the transactions agree with each other."))
+	} else {
+		fmt.Fprintln(w, important("The ceremony's transactions are not what the ceremony file intends. DO NOT SIGN until this is explained."))
+	}
+	fmt.Fprintln(w, "")
+	return nil
+}
+
+// FormatCoverageTerminal outputs how the calls of a transaction decoded, and where to find the
+// ABIs of the selectors that did not
+func FormatCoverageTerminal(report *core.CoverageReport, w io.Writer) error {
+	heading := themed(colorHeading).SprintFunc()
+	divider := themed(colorDivider).SprintFunc()
+	bold := themed(colorBold).SprintFunc()
+	label := themed(colorLabel).SprintFunc()
+	warning := themed(colorCaution).SprintFunc()
+	success := themed(colorSuccess).SprintFunc()
+
+	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, heading("DECODING COVERAGE"))
+	fmt.Fprintln(w, divider("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━"))
+	fmt.Fprintf(w, "%s %d\n", label("Calls:"), report.Calls)
+	fmt.Fprintf(w, "%s %d\n", label("Fully decoded:"), report.Full)
+	fmt.Fprintf(w, "%s %d\n", label("Arguments not decoded:"), report.Partial)
+	fmt.Fprintf(w, "%s %d\n", label("Unknown function:"), report.Unknown)
+	fmt.Fprintf(w, "%s %d\n", label("No calldata:"), report.NoCalldata)
+	fmt.Fprintln(w, "")
+
+	if len(report.Undecoded) == 0 {
+		fmt.Fprintln(w, success("✅ Every call with calldata decoded fully."))
+		fmt.Fprintln(w, "")
+		return nil
+	}
+
+	fmt.Fprintln(w, heading("MISSING SELECTORS"))
+	fmt.Fprintln(w, divider("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━"))
+	for i, missing := range report.Undecoded {
+		if i > 0 {
+			fmt.Fprintln(w, "")
+		}
+		description := "unknown function"
+		if missing.Kind == core.CoveragePartial {
+			description = missing.Function + ", arguments not decoded"
+		}
+		fmt.Fprintf(w, "%s (%s) ×%d\n", bold(missing.Selector), warning(description), missing.Calls)
+		for _, target := range missing.Targets {
+			fmt.Fprintf(w, "  %s %s\n", label("Target:"), target)
+		}
+		for _, link := range missing.Links {
+			fmt.Fprintf(w, "  %s\n", link)
+		}
+	}
+	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, "Add the ABIs of these functions to KnownABIJSON in core/constants.go to decode them.")
+	fmt.Fprintln(w, "")
+	return nil
+}
+
+// FormatRegistryDiffTerminal outputs what a registry sync changes in the local cache, and who
+// signed each downloaded file
+func FormatRegistryDiffTerminal(diff *registry.Diff, signedBy map[string]string, w io.Writer) error {
+	heading := themed(colorHeading).SprintFunc()
+	divider := themed(colorDivider).SprintFunc()
+	label := themed(colorLabel).SprintFunc()
+	success := themed(colorSuccess).SprintFunc()
+	warning := themed(colorCaution).SprintFunc()
+	important := themed(colorImportant).SprintFunc()
+
+	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, heading("REGISTRY SYNC"))
+	fmt.Fprintln(w, divider("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━"))
+	urls := make([]string, 0, len(signedBy))
+	for url := range signedBy {
+		urls = append(urls, url)
+	}
+	sort.Strings(urls)
+	for _, url := range urls {
+		fmt.Fprintf(w, "%s %s\n", success("✅"), url)
+		fmt.Fprintf(w, "   %s %s\n", label("Signed by:"), signedBy[url])
+	}
+	fmt.Fprintln(w, "")
+
+	if diff.Empty() {
+		fmt.Fprintln(w, "No changes.")
+		fmt.Fprintln(w, "")
+		return nil
+	}
+	for _, change := range diff.Added {
+		fmt.Fprintf(w, "%s %s %s: %s\n", success("+"), change.Kind, change.Key, change.New)
+	}
+	for _, change := range diff.Changed {
+		fmt.Fprintf(w, "%s %s %s: %s → %s\n", warning("~"), change.Kind, change.Key, change.Old, change.New)
+	}
+	for _, change := range diff.Removed {
+		fmt.Fprintf(w, "%s %s %s: %s\n", important("-"), change.Kind, change.Key, change.Old)
+	}
+	fmt.Fprintf(w, "\n%d added, %d changed, %d removed\n", len(diff.Added), len(diff.Changed), len(diff.Removed))
+	fmt.Fprintln(w, "")
+	return nil
+}
+
+// FormatRegistryLintTerminal outputs the problems found in registry files and what the files add
+// to each chain
+func FormatRegistryLintTerminal(report *core.RegistryLintReport, w io.Writer) error {
+	heading := themed(colorHeading).SprintFunc()
+	divider := themed(colorDivider).SprintFunc()
+	label := themed(colorLabel).SprintFunc()
+	success := themed(colorSuccess).SprintFunc()
+	warning := themed(colorCaution).SprintFunc()
+	important := themed(colorImportant).SprintFunc()
+
+	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, heading("REGISTRY LINT"))
+	fmt.Fprintln(w, divider("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━"))
+	if len(report.Issues) == 0 {
+		fmt.Fprintln(w, success("✅ No problems found."))
+	}
+	for _, issue := range report.Issues {
+		marker := warning("⚠️ ")
+		if issue.Severity == core.SeverityCritical {
+			marker = important("❌")
+		}
+		location := issue.File
+		if issue.Entry != "" {
+			location += " " + issue.Entry
+		}
+		fmt.Fprintf(w, "%s %s: %s\n", marker, label(location), issue.Message)
+	}
+	fmt.Fprintln(w, "")
+
+	fmt.Fprintln(w, heading("COVERAGE"))
+	fmt.Fprintln(w, divider("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━"))
+	for _, chain := range report.Chains {
+		name := chain.Chain
+		if !chain.Supported {
+			name = warning("not supported")
+		}
+		fmt.Fprintf(w, "%s %d labels, %d tokens, %d new addresses (%d built in)\n",
+			label(fmt.Sprintf("Chain %d (%s):", chain.ChainID, name)), chain.Labels, chain.Tokens, chain.New, chain.BuiltIn)
+	}
+	fmt.Fprintf(w, "%s %d, %d with new selectors\n", label("Functions:"), report.Functions, report.NewFunctions)
+	fmt.Fprintf(w, "%s %d, %d new\n", label("Hashes:"), report.Hashes, report.NewHashes)
+	fmt.Fprintf(w, "\n%d errors, %d warnings\n", report.Count(core.SeverityCritical), report.Count(core.SeverityWarning))
+	fmt.Fprintln(w, "")
+	return nil
+}
+
+// FormatBatchCommitmentTerminal outputs the commitment to a batch and its chunk roots. When
+// chunk is not zero, the calls of that chunk are listed for review.
+func FormatBatchCommitmentTerminal(commitment *core.BatchCommitment, chunk int, w io.Writer) error {
+	heading := themed(colorHeading).SprintFunc()
+	divider := themed(colorDivider).SprintFunc()
+	bold := themed(colorBold).SprintFunc()
+	label := themed(colorLabel).SprintFunc()
+	warning := themed(colorWarning).SprintFunc()
+
+	if chunk != 0 {
+		reviewed, calls, err := commitment.Chunk(chunk)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(w, "")
+		fmt.Fprintln(w, heading(fmt.Sprintf("CHUNK %d OF %d (CALLS %d-%d)", reviewed.Number, len(commitment.Chunks), reviewed.First, reviewed.Last)))
+		fmt.Fprintln(w, divider("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━"))
+		for _, call := range calls {
+			if call.Transfer.TokenType == "" {
+				fmt.Fprintf(w, "%s %s\n", label(fmt.Sprintf("%d.", call.Index)), warning(call.Description))
+				continue
+			}
+			fmt.Fprintf(w, "%s %s\n", label(fmt.Sprintf("%d.", call.Index)), call.Description)
+		}
+		fmt.Fprintln(w, "")
+		printAirdropTotals(w, reviewed.Totals, label)
+		fmt.Fprintf(w, "%s %s\n", bold("Chunk Root:"), formatHash(reviewed.Root))
+		fmt.Fprintln(w, "")
+	}
+
+	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, heading("BATCH COMMITMENT"))
+	fmt.Fprintln(w, divider("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━"))
+	fmt.Fprintf(w, "%s: %d\n", bold("Calls"), commitment.CallCount)
+	if commitment.OtherCalls > 0 {
+		fmt.Fprintf(w, "%s\n", warning(fmt.Sprintf("⚠️  %d calls are not plain transfers and are not included in the totals", commitment.OtherCalls)))
+	}
+	printAirdropTotals(w, commitment.Totals, label)
+	fmt.Fprintln(w, "")
+	fmt.Fprintf(w, "%s  %s\n", bold("Merkle Root:"), formatHash(commitment.Root))
+	fmt.Fprintf(w, "%s  %s\n", bold("Totals Hash:"), formatHash(commitment.TotalsHash))
+	fmt.Fprintf(w, "%s   %s\n", bold("Commitment:"), formatHash(commitment.Commitment))
+	fmt.Fprintln(w, "")
+
+	fmt.Fprintln(w, heading(fmt.Sprintf("CHUNK ROOTS (%d CALLS PER CHUNK)", commitment.ChunkSize)))
+	fmt.Fprintln(w, divider("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━"))
+	for _, c := range commitment.Chunks {
+		fmt.Fprintf(w, "%s %s\n", label(fmt.Sprintf("%d (calls %d-%d):", c.Number, c.First, c.Last)), formatHash(c.Root))
+	}
+	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, "Each reviewer checks one chunk with --chunk N and records its root. Everyone must")
+	fmt.Fprintln(w, "see the same chunk roots and the same commitment, or they reviewed different batches.")
+	fmt.Fprintln(w, "")
+
+	return nil
+}
+
+// FormatWithdrawalCheckTerminal outputs the recomputed hash, output root, and proof and
+// commitment checks of each withdrawal a transaction proves or finalizes
+func FormatWithdrawalCheckTerminal(check *core.WithdrawalCheck, w io.Writer) error {
+	heading := themed(colorHeading).SprintFunc()
+	divider := themed(colorDivider).SprintFunc()
+	bold := themed(colorBold).SprintFunc()
+	label := themed(colorLabel).SprintFunc()
+	success := themed(colorSuccess).SprintFunc()
+	warning := themed(colorWarning).SprintFunc()
+	important := themed(colorImportant).SprintFunc()
+
+	for _, withdrawal := range check.Withdrawals {
+		fmt.Fprintln(w, "")
+		fmt.Fprintln(w, heading(fmt.Sprintf("WITHDRAWAL: %s (%s)", strings.ToUpper(withdrawal.Call), withdrawal.Function)))
+		fmt.Fprintln(w, divider("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━"))
+		fmt.Fprintf(w, "%s: %s\n", label("Portal"), withdrawal.Portal)
+		fmt.Fprintf(w, "%s: %s\n", label("Nonce"), withdrawal.Nonce)
+		fmt.Fprintf(w, "%s: %s\n", label("Sender"), withdrawal.Sender)
+		fmt.Fprintf(w, "%s: %s\n", label("Target"), withdrawal.Target)
+		fmt.Fprintf(w, "%s: %s ETH\n", label("Value"), core.ParseDecimals(new(big.Int).Set(withdrawal.Value), 18))
+		fmt.Fprintf(w, "%s: %s\n", label("Gas Limit"), withdrawal.GasLimit)
+		fmt.Fprintf(w, "%s: %s\n", label("Data"), withdrawal.Data)
+		fmt.Fprintln(w, "")
+		fmt.Fprintf(w, "%s  %s\n", bold("Withdrawal Hash:"), formatHash(withdrawal.WithdrawalHash))
+		fmt.Fprintf(w, "%s     %s\n", bold("Storage Slot:"), formatHash(withdrawal.StorageSlot))
+		if withdrawal.OutputRoot != "" {
+			fmt.Fprintf(w, "%s      %s\n", bold("Output Root:"), formatHash(withdrawal.OutputRoot))
+			fmt.Fprintf(w, "%s %s\n", label("Message Passer Storage Root:"), withdrawal.MessagePasserStorageRoot)
+			fmt.Fprintf(w, "%s %s\n", label("Dispute Game Index:"), withdrawal.DisputeGameIndex)
+		}
+		fmt.Fprintln(w, "")
+
+		if withdrawal.ProofVerified != nil && *withdrawal.ProofVerified {
+			fmt.Fprintln(w, success("✅ The withdrawal proof shows this withdrawal in the message passer storage root."))
+		}
+		switch {
+		case withdrawal.Committed == nil:
+			fmt.Fprintln(w, warning("⚠️  Not checked against L2ToL1MessagePasser on L2 (no L2 node). Pass --l2-rpc-url to check it."))
+		case *withdrawal.Committed:
+			fmt.Fprintln(w, success("✅ L2ToL1MessagePasser on L2 committed to this withdrawal."))
+		}
+		for _, problem := range withdrawal.Problems {
+			fmt.Fprintf(w, "%s %s\n", important("❌"), problem)
+		}
+	}
+	fmt.Fprintln(w, "")
+
+	if !check.OK() {
+		fmt.Fprintln(w, important("The withdrawal parameters do not match the proof or the L2 commitment. DO NOT SIGN until this is explained."))
+		fmt.Fprintln(w, "")
+	}
+	return nil
+}
+
+// FormatPerturbationsTerminal outputs the hashes of deliberately perturbed copies of a
+// transaction next to the originals, marking which of them changed
+func FormatPerturbationsTerminal(report *core.PerturbationReport, w io.Writer) error {
+	heading := themed(colorHeading).SprintFunc()
+	divider := themed(colorDivider).SprintFunc()
+	bold := themed(colorBold).SprintFunc()
+	label := themed(colorCaution).SprintFunc()
+	changed := themed(colorSuccess).SprintFunc()
+	important := themed(colorImportant).SprintFunc()
 
-	// Print important header warning
-	fmt.Fprintln(w, "")
 	fmt.Fprintln(w, "")
-	fmt.Fprintln(w, important("╔══════════════════════════════════════════════════════════════════════════════╗"))
-	fmt.Fprintln(w, important("║                                                                              ║"))
-	fmt.Fprintln(w, important("║                          THIS OUTPUT IS IMPORTANT!                           ║"))
-	fmt.Fprintln(w, important("║                                                                              ║"))
-	fmt.Fprintln(w, important("║    You need to verify this output by looking at exactly what's happening     ║"))
-	fmt.Fprintln(w, important("║    in this transaction and validate that it's interacting with the RIGHT     ║"))
-	fmt.Fprintln(w, important("║    ADDRESSES, with the RIGHT ETH VALUE, on the RIGHT CHAIN, and with the     ║"))
-	fmt.Fprintln(w, important("║    RIGHT DATA!                                                               ║"))
-	fmt.Fprintln(w, important("║                                                                              ║"))
-	fmt.Fprintln(w, important("╚══════════════════════════════════════════════════════════════════════════════╝"))
+	fmt.Fprintln(w, heading("HASH SENSITIVITY (TRAINING MODE — DO NOT SIGN ANY OF THESE)"))
+	fmt.Fprintln(w, divider("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━"))
+	fmt.Fprintln(w, "Each field below was changed slightly and the hashes recomputed. A hash that binds")
+	fmt.Fprintln(w, "the field changes completely, so comparing every character on your device matters.")
 	fmt.Fprintln(w, "")
+
+	fmt.Fprintln(w, bold("Original"))
+	fmt.Fprintf(w, "%s %s\n", label("Domain Hash: "), formatHash(report.Baseline.DomainHash))
+	fmt.Fprintf(w, "%s %s\n", label("Message Hash:"), formatHash(report.Baseline.MessageHash))
+	fmt.Fprintf(w, "%s %s\n", label("Safe Tx Hash:"), formatHash(report.Baseline.ApproveHash))
 	fmt.Fprintln(w, "")
 
-	// Print basic transaction details
-	fmt.Fprintln(w, heading("TRANSACTION SUMMARY"))
-	fmt.Fprintln(w, divider("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━"))
+	for _, p := range report.Perturbations {
+		fmt.Fprintf(w, "%s %s\n", bold(strings.ToUpper(p.Field)+":"), p.Description)
+		fmt.Fprintf(w, "  %s %s\n", label("was:"), p.Original)
+		fmt.Fprintf(w, "  %s %s\n", label("now:"), p.Perturbed)
+		for _, hash := range []struct{ name, original, perturbed string }{
+			{"Domain Hash: ", report.Baseline.DomainHash, p.Hashes.DomainHash},
+			{"Message Hash:", report.Baseline.MessageHash, p.Hashes.MessageHash},
+			{"Safe Tx Hash:", report.Baseline.ApproveHash, p.Hashes.ApproveHash},
+		} {
+			status := changed("changed")
+			if strings.EqualFold(hash.original, hash.perturbed) {
+				status = "unchanged"
+			}
+			fmt.Fprintf(w, "  %s %s  %s\n", label(hash.name), formatHash(hash.perturbed), status)
+		}
+		if strings.EqualFold(report.Baseline.ApproveHash, p.Hashes.ApproveHash) {
+			fmt.Fprintf(w, "  %s\n", important("⚠️ the Safe tx hash did not change — this field is not bound by the signature"))
+		}
+		fmt.Fprintln(w, "")
+	}
 
-	// Extract transaction details
-	tx := result.Transaction
+	return nil
+}
 
-	// Display verified Safe if we can
-	safeContractInfo, isKnownSafeContract := core.GetKnownContract(tx.Safe, uint64(tx.Chain))
-	safeDisplay := tx.Safe
-	if isKnownSafeContract {
-		safeDisplay = fmt.Sprintf("%s (%s 🔍)", tx.Safe, safeContractInfo.Name)
+// printCalldataAnnotation prints calldata to a target one ABI word per line with its byte offset
+// and the decoded field it encodes, so the decoding can be checked by hand
+func printCalldataAnnotation(w io.Writer, title, to string, chainID uint64, data string, heading, divider, label, warning func(a ...interface{}) string) {
+	fmt.Fprintln(w, heading(title))
+	fmt.Fprintln(w, divider("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━"))
+
+	if strings.TrimPrefix(data, "0x") == "" {
+		fmt.Fprintln(w, "No calldata (plain value transfer).")
+		fmt.Fprintln(w, "")
+		return
 	}
 
-	// Display verified Target if we can
-	targetContractInfo, isKnownTargetContract := core.GetKnownContract(tx.To, uint64(tx.Chain))
-	targetDisplay := tx.To
-	if isKnownTargetContract {
-		targetDisplay = fmt.Sprintf("%s (%s 🔍)", tx.To, targetContractInfo.Name)
+	words, err := core.AnnotateCalldata(to, chainID, data)
+	for _, word := range words {
+		fmt.Fprintf(w, "%s  %-64s  %s\n", label(fmt.Sprintf("0x%04x", word.Offset)), word.Data, word.Label)
 	}
+	if err != nil {
+		fmt.Fprintln(w, warning(fmt.Sprintf("⚠️  Could not annotate the calldata: %v", err)))
+	}
+	fmt.Fprintln(w, "")
+}
 
-	// Parse out the operation being performed
-	var operation string
-	if tx.Operation == 0 {
-		operation = "CALL"
-	} else if tx.Operation == 1 {
-		operation = "DELEGATECALL"
-	} else {
-		operation = "UNKNOWN OPERATION ❌"
+// printPhoneticHash prints the Safe tx hash as numbered groups of NATO alphabet words
+func printPhoneticHash(w io.Writer, hash string, heading, divider, label func(a ...interface{}) string) {
+	chunks, err := core.PhoneticHash(hash)
+	if err != nil {
+		return
 	}
 
-	// Parse out the value being sent
-	value := core.ParseDecimals(tx.Value, 18)
+	digits := strings.ToUpper(strings.TrimPrefix(hash, "0x"))
+	fmt.Fprintln(w, heading("SAFE TX HASH (PHONETIC)"))
+	fmt.Fprintln(w, divider("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━"))
+	for i, chunk := range chunks {
+		fmt.Fprintf(w, "%s  %s  %s\n", label(fmt.Sprintf("%2d.", i+1)), digits[i*4:i*4+4], chunk)
+	}
+	fmt.Fprintln(w, "")
+}
 
-	// Parse out network
-	network, isKnownNetwork := core.ChainNames[uint64(tx.Chain)]
-	chainDisplay := fmt.Sprintf("%d", int(tx.Chain))
-	if isKnownNetwork {
-		chainDisplay = fmt.Sprintf("%d (%s 🔍)", int(tx.Chain), network)
+// printWarnings prints the warnings raised during verification, critical ones first
+func printWarnings(w io.Writer, warnings []core.Warning, heading, divider, warning, important func(a ...interface{}) string) {
+	if len(warnings) == 0 {
+		return
 	}
 
-	fmt.Fprintf(w, "%s: %s\n", bold("Safe"), safeDisplay)
-	fmt.Fprintf(w, "%s: %s\n", bold("Chain ID"), chainDisplay)
-	fmt.Fprintf(w, "%s: %s\n", bold("Target"), targetDisplay)
-	fmt.Fprintf(w, "%s: %s\n", bold("ETH Value"), value)
-	fmt.Fprintf(w, "%s: %d\n", bold("Nonce"), tx.Nonce)
-	fmt.Fprintf(w, "%s: %s\n", bold("Operation"), operation)
+	fmt.Fprintln(w, heading("WARNINGS"))
+	fmt.Fprintln(w, divider("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━"))
+	coded := false
+	for _, severity := range []core.Severity{core.SeverityCritical, core.SeverityWarning, core.SeverityInfo} {
+		for _, finding := range warnings {
+			if finding.Severity != severity {
+				continue
+			}
+			coded = coded || finding.Code != ""
+			switch severity {
+			case core.SeverityCritical:
+				fmt.Fprintf(w, "%s %s\n", important("❌ "+warningLabel("CRITICAL", finding)), important(finding.Message))
+			case core.SeverityWarning:
+				fmt.Fprintf(w, "%s %s\n", warning("⚠️  "+warningLabel("WARNING", finding)), finding.Message)
+			default:
+				fmt.Fprintf(w, "ℹ️  %s %s\n", warningLabel("INFO", finding), finding.Message)
+			}
+		}
+	}
+	if coded {
+		fmt.Fprintln(w, "Run `op-txverify explain <code>` for what a warning means and what to do about it.")
+	}
 	fmt.Fprintln(w, "")
+}
 
-	// Check if this is a nested transaction
-	if result.NestedResult != nil {
-		fmt.Fprintln(w, warning("⚠️  WARNING: CHILD TRANSACTION DETECTED  ⚠️"))
-		fmt.Fprintln(w, divider("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━"))
-		fmt.Fprintln(w, bold("This transaction is approving the execution of a transaction in a child Safe."))
-		fmt.Fprintln(w, "")
+// warningLabel labels a warning with its severity and, when it has one, its code
+func warningLabel(severity string, finding core.Warning) string {
+	if finding.Code == "" {
+		return severity + ":"
+	}
+	return fmt.Sprintf("%s [%s]:", severity, finding.Code)
+}
 
+// printHiddenWarnings stands in for the warnings section when it is not shown: critical warnings
+// are still printed, and the others counted
+func printHiddenWarnings(w io.Writer, warnings []core.Warning, heading, divider, warning, important func(a ...interface{}) string) {
+	var critical []core.Warning
+	for _, finding := range warnings {
+		if finding.Severity == core.SeverityCritical {
+			critical = append(critical, finding)
+		}
+	}
+	printWarnings(w, critical, heading, divider, warning, important)
+	if hidden := len(warnings) - len(critical); hidden > 0 {
+		fmt.Fprintln(w, warning(fmt.Sprintf("⚠️  %d WARNING(S) NOT SHOWN — add warnings to --show to see them", hidden)))
 		fmt.Fprintln(w, "")
-		fmt.Fprintln(w, important("⬇️  START OF CHILD TRANSACTION DETAILS  ⬇️"))
-		fmt.Fprintln(w, "")
+	}
+}
 
-		nestedTx := result.NestedResult.Transaction
+// printProvenance prints the per-field provenance of a generated transaction. Fields defaulted
+// because the service omitted them are always shown; the full table only when full is set.
+func printProvenance(w io.Writer, provenance map[string]string, full bool, heading, divider, warning, label func(a ...interface{}) string) {
+	if len(provenance) == 0 {
+		return
+	}
 
-		// Display nested Safe if we can
-		nestedSafeInfo, isKnownNestedSafe := core.GetKnownContract(nestedTx.Safe, uint64(nestedTx.Chain))
-		nestedSafeDisplay := nestedTx.Safe
-		if isKnownNestedSafe {
-			nestedSafeDisplay = fmt.Sprintf("%s (%s 🔍)", nestedTx.Safe, nestedSafeInfo.Name)
+	fields := make([]string, 0, len(provenance))
+	var defaulted []string
+	for field, source := range provenance {
+		fields = append(fields, field)
+		if source != core.FieldSourceService {
+			defaulted = append(defaulted, field)
 		}
+	}
+	sort.Strings(fields)
+	sort.Strings(defaulted)
 
-		fmt.Fprintln(w, heading("CHILD TRANSACTION SUMMARY"))
-		fmt.Fprintln(w, divider("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━"))
-		fmt.Fprintf(w, "%s: %s\n", bold("Child Safe"), nestedSafeDisplay)
-		fmt.Fprintf(w, "%s: %d\n", bold("Child Nonce"), nestedTx.Nonce)
-		fmt.Fprintf(w, "%s: %s\n", bold("Child Hash"), result.NestedResult.ApproveHash)
-		fmt.Fprintln(w, "")
-
-		// Use the existing function to print the child call details
-		printCallDetails(w, result.NestedResult.Call, 0, heading, divider, label, yellow, bold)
-
-		// Add a divider after the child details
-		fmt.Fprintln(w, important("⬆️   END OF CHILD TRANSACTION DETAILS   ⬆️"))
-		fmt.Fprintln(w, "")
+	if len(defaulted) > 0 {
+		fmt.Fprintln(w, warning(fmt.Sprintf("⚠️  WARNING: SAFE SERVICE OMITTED %d FIELD(S), DEFAULTS WERE USED: %s", len(defaulted), strings.Join(defaulted, ", "))))
 		fmt.Fprintln(w, "")
 	}
 
-	// Print call details (of the outer transaction in case of nested)
-	printCallDetails(w, result.Call, 0, heading, divider, label, yellow, bold)
-
-	// Print hashes
-	fmt.Fprintln(w, heading("HASHES"))
-	fmt.Fprintln(w, divider("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━"))
-	fmt.Fprintf(w, "%s:  %s\n", label(bold("Domain Hash")), formatHash(result.DomainHash))
-	fmt.Fprintf(w, "%s: %s\n", label(bold("Message Hash")), formatHash(result.MessageHash))
-	fmt.Fprintf(w, "%s: %s\n", label(bold("Safe Tx Hash")), formatHash(result.ApproveHash))
-	fmt.Fprintln(w, "")
+	if !full {
+		return
+	}
 
-	// Print verification instructions
-	fmt.Fprintln(w, heading("VERIFICATION INSTRUCTIONS"))
+	fmt.Fprintln(w, heading("FIELD PROVENANCE"))
 	fmt.Fprintln(w, divider("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━"))
-	fmt.Fprintf(w, "%s\n", bold("1. Transaction details should EXACTLY MATCH what you expect to see."))
-	fmt.Fprintf(w, "%s\n", bold("2. Domain and message hashes should EXACTLY MATCH other machines."))
-	fmt.Fprintf(w, "%s\n", bold("3. Your hardware wallet should show you the EXACT SAME HASHES."))
-	fmt.Fprintf(w, "%s\n", bold("4. WHEN IN DOUBT, ASK FOR HELP."))
+	for _, field := range fields {
+		fmt.Fprintf(w, "%s: %s\n", label(field), provenance[field])
+	}
 	fmt.Fprintln(w, "")
-
-	return nil
 }
 
 // printCallDetails recursively prints the details of a call and any subcalls.
@@ -158,13 +1986,13 @@ func FormatTerminal(result *core.VerificationResult, w io.Writer) error {
 // - call: the call data to print
 // - depth: current recursion depth (0 for main call, increments for subcalls)
 // - heading, divider, label, yellow, bold: formatting functions for consistent styling
-func printCallDetails(w io.Writer, call core.CallData, depth int, heading, divider, label, yellow, bold func(a ...interface{}) string) {
+func printCallDetails(w io.Writer, call core.CallData, depth int, options TerminalOptions, heading, divider, label, yellow, bold func(a ...interface{}) string) {
 	// Determine heading based on depth
 	if depth == 0 {
 		fmt.Fprintln(w, heading("FUNCTION CALL DETAILS"))
 	} else {
 		fmt.Fprintln(w)
-		fmt.Fprintf(w, "%s\n", heading(fmt.Sprintf("SUBCALL DETAILS (SUBCALL #%d)", depth)))
+		fmt.Fprintf(w, "%s\n", heading(fmt.Sprintf("SUBCALL DETAILS (SUBCALL #%s)", call.Index)))
 	}
 	fmt.Fprintln(w, divider("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━"))
 
@@ -174,13 +2002,35 @@ func printCallDetails(w io.Writer, call core.CallData, depth int, heading, divid
 		fmt.Fprintln(w, "")
 	}
 
+	// Emergency functions get a banner of their own, so they stand out in a long batch
+	if call.Emergency {
+		emergency := themed(colorImportant).SprintFunc()
+		fmt.Fprintln(w, emergency(fmt.Sprintf("🚨 EMERGENCY ACTION: %s 🚨", strings.ToUpper(call.FunctionName))))
+		fmt.Fprintln(w, "")
+	}
+
 	// Print target and function name
-	targetDisplay := call.Target
+	targetDisplay := core.ChecksumAddress(call.Target)
 	if call.TargetName != "" {
-		targetDisplay = fmt.Sprintf("%s (%s 🔍)", call.Target, call.TargetName)
+		targetDisplay = fmt.Sprintf("%s (%s 🔍)", targetDisplay, call.TargetName)
+	} else if call.TargetLabel != "" {
+		targetDisplay = fmt.Sprintf("%s (%s)", targetDisplay, call.TargetLabel)
 	}
 	fmt.Fprintf(w, "%s: %s\n", label("Target"), targetDisplay)
 	fmt.Fprintf(w, "%s: %s\n", label("Function"), call.FunctionName)
+	if call.Value != nil && call.Value.Sign() > 0 {
+		fmt.Fprintf(w, "%s: %s ETH\n", label("Value"), core.ParseDecimals(new(big.Int).Set(call.Value), 18))
+	}
+	if call.Digest != "" {
+		fmt.Fprintf(w, "%s: %s\n", label("Subcall Digest"), formatHash(call.Digest))
+	}
+	if call.Review != nil {
+		fmt.Fprintf(w, "%s: %s\n", label("Reviewed"), reviewDisplay(call.Review))
+	}
+
+	if call.Deployment != nil {
+		printDeployment(w, call.Deployment, label, bold)
+	}
 
 	// If there's raw data, print it
 	if call.RawData != "" {
@@ -188,39 +2038,22 @@ func printCallDetails(w io.Writer, call core.CallData, depth int, heading, divid
 		return
 	}
 
-	// If there's parsed data, print it
-	if call.ParsedData != nil {
-		// Check if ParsedData is a map
-		if parsedMap, ok := call.ParsedData.(map[string]interface{}); ok {
-			// Get keys and sort them
-			keys := make([]string, 0, len(parsedMap))
-			for key := range parsedMap {
-				keys = append(keys, key)
-			}
-			// Sort the keys alphabetically
-			sort.Strings(keys)
-
-			// Print values using sorted keys
-			for _, key := range keys {
-				// Skip printing array elements with index notation if we already printed the full array
-				if strings.Contains(key, "[") && strings.HasSuffix(key, "]") {
-					// Extract the base name before the bracket
-					baseName := key[:strings.Index(key, "[")]
-					if contains(keys, baseName) {
-						// Skip individual elements since we'll format the array better
-						continue
-					}
-				}
-
-				value := parsedMap[key]
-				prettyPrintValue(w, key, value, yellow, "", 0)
+	// If there are parsed arguments, print them in signature order
+	if len(call.ParsedData) > 0 {
+		for _, arg := range call.ParsedData {
+			if arg.Display != "" {
+				fmt.Fprintf(w, "%s: %s%s\n", yellow(arg.Name), arg.Display, annotationSuffix(call.Annotations, arg.Name))
+				continue
 			}
-		} else {
-			// If it's not a map, just print the value
-			fmt.Fprintf(w, "%v\n", formatSimpleValue(call.ParsedData))
+			prettyPrintValue(w, arg.Name, arg.Value, yellow, "", 0, arg.Name, call.Annotations)
 		}
 		fmt.Fprintln(w, "")
 	}
+	printCallSummary(w, call.Summary, label, yellow)
+
+	if call.Deposit != nil {
+		printDeposit(w, call.Deposit, depth, options, heading, divider, label, yellow, bold)
+	}
 
 	// If there are subcalls, print them recursively
 	if len(call.SubCalls) > 0 {
@@ -228,24 +2061,330 @@ func printCallDetails(w io.Writer, call core.CallData, depth int, heading, divid
 		fmt.Fprintln(w, heading("THIS TRANSACTION INCLUDES MULTIPLE CONTRACT INTERACTIONS"))
 		fmt.Fprintln(w, divider("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━"))
 		fmt.Fprintf(w, "%s: %d\n", bold("Number of subcalls"), len(call.SubCalls))
+		if indices := emergencySubcalls(call); len(indices) > 0 {
+			emergency := themed(colorImportant).SprintFunc()
+			fmt.Fprintf(w, "%s: %s\n", bold("Emergency actions"), emergency(strings.Join(indices, ", ")))
+		}
+		if reviewed, total := reviewProgress(call); reviewed > 0 {
+			fmt.Fprintf(w, "%s: %d of %d\n", bold("Reviewed subcalls"), reviewed, total)
+		}
 
 		// Process each subcall
-		for i, subcall := range call.SubCalls {
-			// Increment depth for subcalls
-			printCallDetails(w, subcall, depth+i+1, heading, divider, label, yellow, bold)
+		for i := 0; i < len(call.SubCalls); {
+			// Collapse long runs of the same function on the same target (e.g. disbursement batches)
+			if run := identicalSubcallRun(call.SubCalls, i); !options.ExpandAll && run >= minGroupedSubcalls {
+				printSubcallGroup(w, call.SubCalls[i:i+run], heading, divider, label, yellow)
+				i += run
+				continue
+			}
+			printCallDetails(w, call.SubCalls[i], depth+1, options, heading, divider, label, yellow, bold)
+			i++
 		}
 	}
 }
 
-// contains checks if a string slice contains a given value.
-// Returns true if the value is found, false otherwise.
-func contains(slice []string, value string) bool {
-	for _, item := range slice {
-		if item == value {
-			return true
+// printCallFlow prints the calls a transaction makes as a tree, from the signing Safe through
+// batches and approved child transactions down to every target
+func printCallFlow(w io.Writer, result *core.VerificationResult, options TerminalOptions, heading, divider, bold, yellow func(a ...interface{}) string) {
+	fmt.Fprintln(w, heading("CALL FLOW"))
+	fmt.Fprintln(w, divider("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━"))
+
+	tx := result.Transaction
+	fmt.Fprintf(w, "%s %s\n", bold("Safe"), flowTarget(tx.Safe, "", "", uint64(tx.Chain)))
+	root := result.Call
+	root.Value = tx.Value
+	printFlowCall(w, "", true, root, tx.Operation == 1, options, yellow)
+	if result.NestedResult != nil {
+		child := result.NestedResult.Transaction
+		fmt.Fprintf(w, "   └─ %s %s nonce %d\n", bold("approves Safe"), flowTarget(child.Safe, "", "", uint64(child.Chain)), child.Nonce)
+		childRoot := result.NestedResult.Call
+		childRoot.Value = child.Value
+		printFlowCall(w, "      ", true, childRoot, child.Operation == 1, options, yellow)
+	}
+	fmt.Fprintln(w, "")
+}
+
+// printFlowCall prints one call of the call flow tree and, below it, its subcalls. Runs of
+// identical subcalls, which send the same value, are collapsed as in the call details unless
+// every call is expanded.
+func printFlowCall(w io.Writer, prefix string, last bool, call core.CallData, delegate bool, options TerminalOptions, yellow func(a ...interface{}) string) {
+	printFlowLine(w, prefix, last, call, delegate, 1, yellow)
+
+	childPrefix := prefix + "│  "
+	if last {
+		childPrefix = prefix + "   "
+	}
+	for i := 0; i < len(call.SubCalls); {
+		run := 1
+		if !options.ExpandAll {
+			if n := identicalSubcallRun(call.SubCalls, i); n >= minGroupedSubcalls {
+				run = n
+			}
 		}
+		subcall := call.SubCalls[i]
+		lastSubcall := i+run == len(call.SubCalls)
+		if run > 1 {
+			subcall.Index = fmt.Sprintf("%s–#%s", subcall.Index, call.SubCalls[i+run-1].Index)
+			printFlowLine(w, childPrefix, lastSubcall, subcall, subcall.IsDelegateCall, run, yellow)
+		} else {
+			printFlowCall(w, childPrefix, lastSubcall, subcall, subcall.IsDelegateCall, options, yellow)
+		}
+		i += run
 	}
-	return false
+}
+
+// printFlowLine prints a single line of the call flow tree for a call, or for a run of count
+// identical calls with the value of each and their total
+func printFlowLine(w io.Writer, prefix string, last bool, call core.CallData, delegate bool, count int, yellow func(a ...interface{}) string) {
+	connector := "├─ "
+	if last {
+		connector = "└─ "
+	}
+	operation := "CALL"
+	if delegate {
+		operation = yellow("DELEGATECALL")
+	}
+	index := ""
+	if call.Index != "" {
+		index = "#" + call.Index + " "
+	}
+
+	function := call.FunctionName
+	switch {
+	case call.RawData == "0x":
+		function = "no calldata"
+	case function == "unknown":
+		function = "unknown function"
+	}
+	if count > 1 {
+		function = fmt.Sprintf("%s ×%d", function, count)
+	}
+	if call.Emergency {
+		function = yellow("🚨 " + function)
+	}
+	value := ""
+	if call.Value != nil && call.Value.Sign() > 0 {
+		value = fmt.Sprintf(" · %s ETH", core.ParseDecimals(new(big.Int).Set(call.Value), 18))
+		if count > 1 {
+			// Only calls sending the same value are collapsed, so the run sends count times it
+			total := new(big.Int).Mul(call.Value, big.NewInt(int64(count)))
+			value += fmt.Sprintf(" each, %s ETH in total", core.ParseDecimals(total, 18))
+		}
+	}
+	fmt.Fprintf(w, "%s%s%s%s %s · %s%s\n", prefix, connector, index, operation, flowTarget(call.Target, call.TargetName, call.TargetLabel, 0), function, value)
+}
+
+// flowTarget renders an address of the call flow tree with its name, when it has one. Known
+// contracts are looked up when a chain is given.
+func flowTarget(address, name, addressLabel string, chainID uint64) string {
+	if name == "" && chainID != 0 {
+		if info, ok := core.GetKnownContract(address, chainID); ok {
+			name = info.Name
+		}
+	}
+	display := core.ChecksumAddress(address)
+	switch {
+	case name != "":
+		return fmt.Sprintf("%s (%s 🔍)", display, name)
+	case addressLabel != "":
+		return fmt.Sprintf("%s (%s)", display, addressLabel)
+	}
+	return display
+}
+
+// identicalSubcallRun returns how many consecutive subcalls starting at start call the same decoded
+// function on the same target, with the same operation and value. Calls with their own subcalls or
+// undecoded data are never grouped.
+func identicalSubcallRun(subcalls []core.CallData, start int) int {
+	first := subcalls[start]
+	if !groupable(first) {
+		return 1
+	}
+
+	run := 1
+	for _, next := range subcalls[start+1:] {
+		if !groupable(next) || !strings.EqualFold(next.Target, first.Target) ||
+			next.FunctionName != first.FunctionName || next.IsDelegateCall != first.IsDelegateCall ||
+			!sameValue(next.Value, first.Value) {
+			break
+		}
+		run++
+	}
+	return run
+}
+
+// sameValue reports whether two subcalls send the same ETH; a missing value is none
+func sameValue(a, b *big.Int) bool {
+	if a == nil {
+		a = new(big.Int)
+	}
+	if b == nil {
+		b = new(big.Int)
+	}
+	return a.Cmp(b) == 0
+}
+
+// groupable reports whether a subcall is simple enough to be shown as one line of a group.
+// Annotated subcalls are shown in full so their annotations appear next to the values, and
+// emergency calls so their banners are.
+func groupable(call core.CallData) bool {
+	return call.ParsedData != nil && call.Summary == nil && call.RawData == "" && len(call.SubCalls) == 0 && call.Deployment == nil && call.Deposit == nil && !call.Emergency && len(call.Annotations) == 0 && (call.Review == nil || call.Review.Note == "")
+}
+
+// reviewProgress counts the calls below a call, at any depth, that make no further calls, and
+// how many of them a review session marked as reviewed
+func reviewProgress(call core.CallData) (reviewed, total int) {
+	for _, subcall := range call.SubCalls {
+		if len(subcall.SubCalls) > 0 {
+			r, t := reviewProgress(subcall)
+			reviewed, total = reviewed+r, total+t
+			continue
+		}
+		total++
+		if subcall.Review != nil {
+			reviewed++
+		}
+	}
+	return reviewed, total
+}
+
+// reviewDisplay is how a call's review is shown: when it was reviewed and the reviewer's note
+func reviewDisplay(review *core.CallReview) string {
+	text := "✓ " + review.ReviewedAt.UTC().Format("2006-01-02 15:04 UTC")
+	if review.Note != "" {
+		text += " — " + review.Note
+	}
+	return text
+}
+
+// emergencySubcalls returns the indices of the emergency calls below a call, at any depth, such
+// as "#3 pause"
+func emergencySubcalls(call core.CallData) []string {
+	var indices []string
+	for _, subcall := range call.SubCalls {
+		if subcall.Emergency {
+			indices = append(indices, fmt.Sprintf("#%s %s", subcall.Index, subcall.FunctionName))
+		}
+		indices = append(indices, emergencySubcalls(subcall)...)
+	}
+	return indices
+}
+
+// printDeployment prints the contract a call deploys through a known deployer
+func printDeployment(w io.Writer, deployment *core.Deployment, label, bold func(a ...interface{}) string) {
+	fmt.Fprintf(w, "%s: %s from %s\n", label("Deploys"), deployment.Method, deployment.Deployer)
+	if deployment.Salt != "" {
+		fmt.Fprintf(w, "%s: %s\n", label("Salt"), deployment.Salt)
+	}
+	fmt.Fprintf(w, "%s: %d bytes, keccak256 %s\n", label("Init Code"), deployment.InitCodeSize, deployment.InitCodeHash)
+	if deployment.Value != nil && deployment.Value.Sign() > 0 {
+		fmt.Fprintf(w, "%s: %s ETH\n", label("Constructor Value"), core.ParseDecimals(deployment.Value, 18))
+	}
+	if deployment.Address != "" {
+		fmt.Fprintf(w, "%s: %s\n", label("Deployment Address"), bold(deployment.Address))
+	} else {
+		fmt.Fprintf(w, "%s: depends on the nonce of %s at execution\n", label("Deployment Address"), deployment.Deployer)
+	}
+}
+
+// printDeposit prints the L2 transaction a depositTransaction call creates and its decoded call
+func printDeposit(w io.Writer, deposit *core.Deposit, depth int, options TerminalOptions, heading, divider, label, yellow, bold func(a ...interface{}) string) {
+	fmt.Fprintln(w, heading(fmt.Sprintf("L2 TRANSACTION ON %s", strings.ToUpper(core.ChainNames[deposit.L2ChainID]))))
+	fmt.Fprintln(w, divider("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━"))
+	if deposit.From != "" {
+		fmt.Fprintf(w, "%s: %s (aliased %s)\n", label("From"), bold(deposit.From), deposit.L1Sender)
+	}
+	if deposit.IsCreation {
+		fmt.Fprintf(w, "%s: contract creation\n", label("To"))
+		fmt.Fprintf(w, "%s: %d bytes, keccak256 %s\n", label("Init Code"), deposit.InitCodeSize, deposit.InitCodeHash)
+	}
+	if deposit.Mint != nil && deposit.Mint.Sign() > 0 {
+		fmt.Fprintf(w, "%s: %s ETH\n", label("Minted on L2"), core.ParseDecimals(new(big.Int).Set(deposit.Mint), 18))
+	}
+	if deposit.Value != nil {
+		fmt.Fprintf(w, "%s: %s ETH\n", label("Value"), core.ParseDecimals(new(big.Int).Set(deposit.Value), 18))
+	} else {
+		fmt.Fprintf(w, "%s: %s\n", label("Value"), core.RedactedAmount)
+	}
+	fmt.Fprintf(w, "%s: %d\n", label("Gas Limit"), deposit.GasLimit)
+	if deposit.Call == nil {
+		fmt.Fprintln(w, "")
+		return
+	}
+
+	call := deposit.Call
+	targetDisplay := core.ChecksumAddress(call.Target)
+	if call.TargetName != "" {
+		targetDisplay = fmt.Sprintf("%s (%s 🔍)", targetDisplay, call.TargetName)
+	}
+	fmt.Fprintf(w, "%s: %s\n", label("To"), targetDisplay)
+	fmt.Fprintf(w, "%s: %s\n", label("Function"), call.FunctionName)
+	if call.RawData != "" {
+		fmt.Fprintf(w, "%s: %s\n\n", label("Calldata"), call.RawData)
+		return
+	}
+	for _, arg := range call.ParsedData {
+		if arg.Display != "" {
+			fmt.Fprintf(w, "%s: %s\n", yellow(arg.Name), arg.Display)
+			continue
+		}
+		prettyPrintValue(w, arg.Name, arg.Value, yellow, "", 0, arg.Name, nil)
+	}
+	fmt.Fprintln(w, "")
+	printCallSummary(w, call.Summary, label, yellow)
+	for _, subcall := range call.SubCalls {
+		printCallDetails(w, subcall, depth+1, options, heading, divider, label, yellow, bold)
+	}
+}
+
+// printCallSummary prints the summary of a well-known call, such as a swap, below its raw
+// arguments
+func printCallSummary(w io.Writer, summary []core.Argument, label, yellow func(a ...interface{}) string) {
+	if len(summary) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "%s:\n", label("Summary"))
+	for _, field := range summary {
+		prettyPrintValue(w, field.Name, field.Value, yellow, "  ", 1, field.Name, nil)
+	}
+	fmt.Fprintln(w, "")
+}
+
+// printSubcallGroup prints a run of identical subcalls as a heading and one line of arguments per call
+func printSubcallGroup(w io.Writer, group []core.CallData, heading, divider, label, yellow func(a ...interface{}) string) {
+	first := group[0]
+	target := first.Target
+	if first.TargetName != "" {
+		target = first.TargetName
+	}
+
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, heading(fmt.Sprintf("SUBCALLS #%s–#%s: %s() on %s × %d", first.Index, group[len(group)-1].Index, first.FunctionName, target, len(group))))
+	fmt.Fprintln(w, divider("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━"))
+	if first.IsDelegateCall {
+		fmt.Fprintln(w, yellow("⚠️  WARNING: DELEGATECALL ⚠️"))
+	}
+	fmt.Fprintf(w, "%s: %s\n", label("Target"), core.ChecksumAddress(first.Target))
+	fmt.Fprintln(w, "Identical calls collapsed to one line each — expand with --expand-all")
+	fmt.Fprintln(w, "")
+
+	for _, call := range group {
+		parts := make([]string, 0, len(call.ParsedData))
+		for _, arg := range call.ParsedData {
+			parts = append(parts, fmt.Sprintf("%s=%v", yellow(arg.Name), formatArgument(arg)))
+		}
+		if call.Value != nil && call.Value.Sign() > 0 {
+			parts = append(parts, fmt.Sprintf("%s=%s ETH", yellow("value"), core.ParseDecimals(new(big.Int).Set(call.Value), 18)))
+		}
+		if call.Digest != "" {
+			parts = append(parts, "digest "+formatHash(call.Digest))
+		}
+		if call.Review != nil {
+			parts = append(parts, "✓ reviewed")
+		}
+		fmt.Fprintf(w, "  #%-6s %s\n", call.Index, strings.Join(parts, "  "))
+	}
+	fmt.Fprintln(w, "")
 }
 
 // prettyPrintValue recursively formats and prints a value with proper indentation.
@@ -256,16 +2395,24 @@ func contains(slice []string, value string) bool {
 // - keyColor: formatting function for the key
 // - indent: current indentation string
 // - depth: current recursion depth to prevent infinite recursion
-func prettyPrintValue(w io.Writer, key string, value interface{}, keyColor func(a ...interface{}) string, indent string, depth int) {
+// - path: argument path of the value, as core.ArgumentAnnotations resolves it
+// - notes: annotations of the call, keyed by argument path (nil for none)
+func prettyPrintValue(w io.Writer, key string, value interface{}, keyColor func(a ...interface{}) string, indent string, depth int, path string, notes map[string]string) {
 	// Prevent excessive recursion
 	if depth > 5 {
-		fmt.Fprintf(w, "%s%s: [complex nested structure]\n", indent, keyColor(key))
+		fmt.Fprintf(w, "%s%s: [complex nested structure]%s\n", indent, keyColor(key), annotationSuffix(notes, path))
 		return
 	}
 
 	// Handle nil values
 	if value == nil {
-		fmt.Fprintf(w, "%s%s: nil\n", indent, keyColor(key))
+		fmt.Fprintf(w, "%s%s: nil%s\n", indent, keyColor(key), annotationSuffix(notes, path))
+		return
+	}
+
+	// Addresses are byte arrays underneath; print them in full with checksum casing, not as raw bytes
+	if address, ok := value.(common.Address); ok {
+		fmt.Fprintf(w, "%s%s: %s%s\n", indent, keyColor(key), address.Hex(), annotationSuffix(notes, path))
 		return
 	}
 
@@ -275,30 +2422,31 @@ func prettyPrintValue(w io.Writer, key string, value interface{}, keyColor func(
 	// Simple types can be printed directly
 	if valueKind != reflect.Array && valueKind != reflect.Slice &&
 		valueKind != reflect.Map && valueKind != reflect.Struct {
-		fmt.Fprintf(w, "%s%s: %v\n", indent, keyColor(key), formatSimpleValue(value))
+		fmt.Fprintf(w, "%s%s: %v%s\n", indent, keyColor(key), formatSimpleValue(value), annotationSuffix(notes, path))
 		return
 	}
 
 	// For complex types, use specialized formatting functions
 	if valueKind == reflect.Slice || valueKind == reflect.Array {
-		prettyPrintArray(w, key, value, keyColor, indent, depth)
+		prettyPrintArray(w, key, value, keyColor, indent, depth, path, notes)
 	} else if valueKind == reflect.Map {
-		prettyPrintMap(w, key, value, keyColor, indent, depth)
+		prettyPrintMap(w, key, value, keyColor, indent, depth, path, notes)
 	} else if valueKind == reflect.Struct {
-		prettyPrintStructObj(w, key, value, keyColor, indent, depth)
+		prettyPrintStructObj(w, key, value, keyColor, indent, depth, path, notes)
 	}
 }
 
 // prettyPrintArray formats and prints an array or slice with appropriate formatting.
 // For byte arrays, it uses hex encoding. For small arrays of simple types, it uses
 // inline formatting. For larger or complex arrays, it formats items vertically.
-func prettyPrintArray(w io.Writer, key string, arr interface{}, keyColor func(a ...interface{}) string, indent string, depth int) {
+func prettyPrintArray(w io.Writer, key string, arr interface{}, keyColor func(a ...interface{}) string, indent string, depth int, path string, notes map[string]string) {
 	arrValue := reflect.ValueOf(arr)
 	arrLen := arrValue.Len()
+	note := annotationSuffix(notes, path)
 
 	// For empty arrays
 	if arrLen == 0 {
-		fmt.Fprintf(w, "%s%s: []\n", indent, keyColor(key))
+		fmt.Fprintf(w, "%s%s: []%s\n", indent, keyColor(key), note)
 		return
 	}
 
@@ -309,12 +2457,20 @@ func prettyPrintArray(w io.Writer, key string, arr interface{}, keyColor func(a
 		for i := 0; i < arrLen; i++ {
 			byteArr[i] = uint8(arrValue.Index(i).Uint())
 		}
-		fmt.Fprintf(w, "%s%s: 0x%s\n", indent, keyColor(key), hex.EncodeToString(byteArr))
+		// Nested bytes32 values are interpreted without a chain, so only global names apply
+		interpretation := ""
+		if arrValue.Kind() == reflect.Array && arrLen == 32 {
+			if meaning := core.InterpretBytes32(common.BytesToHash(byteArr), core.AnyChain); meaning != "" {
+				interpretation = " (" + meaning + ")"
+			}
+		}
+		fmt.Fprintf(w, "%s%s: 0x%s%s%s\n", indent, keyColor(key), hex.EncodeToString(byteArr), interpretation, note)
 		return
 	}
 
-	// For arrays with fewer than 5 simple elements, print inline
-	if arrLen < 5 {
+	// For arrays with fewer than 5 simple elements, print inline (unless an element is annotated,
+	// which needs a line of its own)
+	if arrLen < 5 && !hasAnnotationWithin(notes, path+"[") {
 		allSimple := true
 		for i := 0; i < arrLen; i++ {
 			itemKind := arrValue.Index(i).Kind()
@@ -333,45 +2489,46 @@ func prettyPrintArray(w io.Writer, key string, arr interface{}, keyColor func(a
 				}
 				fmt.Fprintf(w, "%v", formatSimpleValue(arrValue.Index(i).Interface()))
 			}
-			fmt.Fprintln(w, "]")
+			fmt.Fprintf(w, "]%s\n", note)
 			return
 		}
 	}
 
 	// For larger or complex arrays, print items vertically
-	fmt.Fprintf(w, "%s%s: [\n", indent, keyColor(key))
+	fmt.Fprintf(w, "%s%s: [%s\n", indent, keyColor(key), note)
 	for i := 0; i < arrLen; i++ {
 		item := arrValue.Index(i).Interface()
 		itemKind := arrValue.Index(i).Kind()
+		itemPath := fmt.Sprintf("%s[%d]", path, i)
 
 		if itemKind == reflect.Struct || itemKind == reflect.Map {
 			// For complex items, recursively print them
-			fmt.Fprintf(w, "%s  Item #%d:\n", indent, i)
+			fmt.Fprintf(w, "%s  Item #%d:%s\n", indent, i, annotationSuffix(notes, itemPath))
 			if itemKind == reflect.Struct {
-				prettyPrintStructObj(w, "", item, keyColor, indent+"    ", depth+1)
+				prettyPrintStructObj(w, "", item, keyColor, indent+"    ", depth+1, itemPath, notes)
 			} else {
-				prettyPrintMap(w, "", item, keyColor, indent+"    ", depth+1)
+				prettyPrintMap(w, "", item, keyColor, indent+"    ", depth+1, itemPath, notes)
 			}
 		} else {
 			// For simple items
-			fmt.Fprintf(w, "%s  Item #%d: %v\n", indent, i, formatSimpleValue(item))
+			fmt.Fprintf(w, "%s  Item #%d: %v%s\n", indent, i, formatSimpleValue(item), annotationSuffix(notes, itemPath))
 		}
 	}
 	fmt.Fprintf(w, "%s]\n", indent)
 }
 
 // prettyPrintMap formats and prints a map with keys sorted alphabetically.
-func prettyPrintMap(w io.Writer, key string, m interface{}, keyColor func(a ...interface{}) string, indent string, depth int) {
+func prettyPrintMap(w io.Writer, key string, m interface{}, keyColor func(a ...interface{}) string, indent string, depth int, path string, notes map[string]string) {
 	mapValue := reflect.ValueOf(m)
 
 	// For empty maps
 	if mapValue.Len() == 0 {
-		fmt.Fprintf(w, "%s%s: {}\n", indent, keyColor(key))
+		fmt.Fprintf(w, "%s%s: {}%s\n", indent, keyColor(key), annotationSuffix(notes, path))
 		return
 	}
 
 	if key != "" {
-		fmt.Fprintf(w, "%s%s: {\n", indent, keyColor(key))
+		fmt.Fprintf(w, "%s%s: {%s\n", indent, keyColor(key), annotationSuffix(notes, path))
 	} else {
 		fmt.Fprintf(w, "%s{\n", indent)
 	}
@@ -386,24 +2543,24 @@ func prettyPrintMap(w io.Writer, key string, m interface{}, keyColor func(a ...i
 	for _, k := range mapKeys {
 		mapKey := fmt.Sprintf("%v", k)
 		mapItem := mapValue.MapIndex(k).Interface()
-		prettyPrintValue(w, mapKey, mapItem, keyColor, indent+"  ", depth+1)
+		prettyPrintValue(w, mapKey, mapItem, keyColor, indent+"  ", depth+1, path+"."+mapKey, notes)
 	}
 
 	fmt.Fprintf(w, "%s}\n", indent)
 }
 
 // prettyPrintStructObj formats and prints a struct with its fields.
-func prettyPrintStructObj(w io.Writer, key string, s interface{}, keyColor func(a ...interface{}) string, indent string, depth int) {
+func prettyPrintStructObj(w io.Writer, key string, s interface{}, keyColor func(a ...interface{}) string, indent string, depth int, path string, notes map[string]string) {
 	structValue := reflect.ValueOf(s)
 	structType := structValue.Type()
 
 	if key != "" {
-		fmt.Fprintf(w, "%s%s: {\n", indent, keyColor(key))
+		fmt.Fprintf(w, "%s%s: {%s\n", indent, keyColor(key), annotationSuffix(notes, path))
 	} else {
 		fmt.Fprintf(w, "%s{\n", indent)
 	}
 
-	// Print each field
+	// Print each field in declared order, under its Solidity name for ABI-decoded tuples
 	for i := 0; i < structValue.NumField(); i++ {
 		field := structType.Field(i)
 		fieldValue := structValue.Field(i).Interface()
@@ -413,12 +2570,40 @@ func prettyPrintStructObj(w io.Writer, key string, s interface{}, keyColor func(
 			continue
 		}
 
-		prettyPrintValue(w, field.Name, fieldValue, keyColor, indent+"  ", depth+1)
+		name := core.TupleFieldName(field)
+		prettyPrintValue(w, name, fieldValue, keyColor, indent+"  ", depth+1, path+"."+name, notes)
 	}
 
 	fmt.Fprintf(w, "%s}\n", indent)
 }
 
+// annotationSuffix returns the facilitator's annotation of an argument, to print after its value
+func annotationSuffix(notes map[string]string, path string) string {
+	note, ok := notes[path]
+	if !ok || path == "" {
+		return ""
+	}
+	return "  " + themed(colorNote).Sprint("◀ "+note)
+}
+
+// hasAnnotationWithin reports whether any annotation path starts with a prefix
+func hasAnnotationWithin(notes map[string]string, prefix string) bool {
+	for path := range notes {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// formatArgument returns how a decoded argument is shown on one line
+func formatArgument(arg core.Argument) interface{} {
+	if arg.Display != "" {
+		return arg.Display
+	}
+	return formatSimpleValue(arg.Value)
+}
+
 // formatSimpleValue handles special formatting for various types like byte arrays,
 // addresses, and other common Ethereum-specific types.
 // Returns a properly formatted representation of the value.
@@ -1,7 +1,23 @@
 package output
 
 import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/ethereum-optimism/op-txverify/core"
+	"github.com/ethereum-optimism/op-txverify/doctor"
+	"github.com/ethereum-optimism/op-txverify/registry"
+	"github.com/ethereum-optimism/op-txverify/safes"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
 )
 
 func TestFormatHash(t *testing.T) {
@@ -12,3 +28,1694 @@ func TestFormatHash(t *testing.T) {
 		t.Fatalf("formatHash no prefix stays upper = %q", got)
 	}
 }
+
+func TestPrintProvenanceShowsDefaultedFields(t *testing.T) {
+	plain := func(a ...interface{}) string { return fmt.Sprint(a...) }
+	provenance := map[string]string{
+		"value":     core.FieldSourceService,
+		"safeTxGas": core.FieldSourceMissing,
+	}
+
+	var buf bytes.Buffer
+	printProvenance(&buf, provenance, false, plain, plain, plain, plain)
+	if !strings.Contains(buf.String(), "safeTxGas") || strings.Contains(buf.String(), "FIELD PROVENANCE") {
+		t.Fatalf("non-verbose output should only warn about defaulted fields, got:\n%s", buf.String())
+	}
+
+	buf.Reset()
+	printProvenance(&buf, provenance, true, plain, plain, plain, plain)
+	if !strings.Contains(buf.String(), "FIELD PROVENANCE") || !strings.Contains(buf.String(), "value: service") {
+		t.Fatalf("verbose output should list every field, got:\n%s", buf.String())
+	}
+}
+
+func TestFormatTransferTerminal(t *testing.T) {
+	result := &core.TransferResult{
+		Safe:  "0x2501c477D0A35545a387Aa4A3EEe4292A9a8B3F0",
+		Chain: int(core.OPMainnetChainID),
+		Transfer: core.Transfer{
+			Type:  "ETHER_TRANSFER",
+			From:  "0x2501c477D0A35545a387Aa4A3EEe4292A9a8B3F0",
+			To:    "0x9A69d97a451643a0Bb4462476942D2bC844431cE",
+			Value: "1000000000000000000",
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := FormatTransferTerminal(result, &buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{"NOT A MULTISIG TRANSACTION", "nothing to sign", "OUTGOING", "ETH"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestFormatMessagesTerminal(t *testing.T) {
+	results := []core.MessageResult{
+		{
+			Safe:          "0x2501c477D0A35545a387Aa4A3EEe4292A9a8B3F0",
+			Chain:         10,
+			MessageType:   core.MessageTypeText,
+			Message:       "I approve the OP grants budget",
+			MessageHash:   "0xbeef",
+			Confirmations: 1,
+			Threshold:     2,
+		},
+		{
+			MessageType: core.MessageTypeTypedV4,
+			Message: apitypes.TypedData{
+				Types: apitypes.Types{
+					"EIP712Domain": {{Name: "chainId", Type: "uint256"}},
+					"Permit":       {{Name: "spender", Type: "address"}},
+				},
+				PrimaryType: "Permit",
+				Domain:      apitypes.TypedDataDomain{ChainId: math.NewHexOrDecimal256(10)},
+				Message:     map[string]interface{}{"spender": "0x9A69d97a451643a0Bb4462476942D2bC844431cE"},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := FormatMessagesTerminal(results, &buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{"MESSAGE 1 OF 2", "I approve the OP grants budget", "1 of 2", "Permit", "spender", "0xBEEF"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestPrintCallDetailsUsesHierarchicalIndices(t *testing.T) {
+	plain := func(a ...interface{}) string { return fmt.Sprint(a...) }
+	call := core.CallData{
+		FunctionName: "multiSend",
+		SubCalls: []core.CallData{
+			{FunctionName: "transfer", RawData: "0x"},
+			{FunctionName: "multiSend", SubCalls: []core.CallData{
+				{FunctionName: "transfer", RawData: "0x"},
+				{FunctionName: "transfer", RawData: "0x"},
+			}},
+		},
+	}
+	core.AssignCallIndices(&call)
+
+	var buf bytes.Buffer
+	printCallDetails(&buf, call, 0, TerminalOptions{}, plain, plain, plain, plain, plain)
+	out := buf.String()
+	for _, want := range []string{"SUBCALL #1)", "SUBCALL #2)", "SUBCALL #2.1)", "SUBCALL #2.2)"} {
+		if strings.Count(out, want) != 1 {
+			t.Errorf("expected exactly one %q in output:\n%s", want, out)
+		}
+	}
+}
+
+func TestPrintCallDetailsGroupsIdenticalSubcalls(t *testing.T) {
+	plain := func(a ...interface{}) string { return fmt.Sprint(a...) }
+
+	transfer := func(to string) core.CallData {
+		return core.CallData{
+			Target:       "0x4200000000000000000000000000000000000042",
+			TargetName:   "OP Token",
+			FunctionName: "transfer",
+			ParsedData:   []core.Argument{{Name: "to", Value: to}, {Name: "amount", Value: "1"}},
+		}
+	}
+	call := core.CallData{FunctionName: "multiSend"}
+	for i := 0; i < 4; i++ {
+		call.SubCalls = append(call.SubCalls, transfer(fmt.Sprintf("0x%040d", i)))
+	}
+	call.SubCalls = append(call.SubCalls, core.CallData{Target: "0xdead", FunctionName: "unknown", RawData: "0x1234"})
+	core.AssignCallIndices(&call)
+
+	var buf bytes.Buffer
+	printCallDetails(&buf, call, 0, TerminalOptions{}, plain, plain, plain, plain, plain)
+	out := buf.String()
+	if !strings.Contains(out, "SUBCALLS #1–#4: transfer() on OP Token × 4") {
+		t.Errorf("expected grouped heading:\n%s", out)
+	}
+	if strings.Contains(out, "SUBCALL #2)") || !strings.Contains(out, "SUBCALL #5)") {
+		t.Errorf("grouped calls should not be printed individually:\n%s", out)
+	}
+	for i := 0; i < 4; i++ {
+		if !strings.Contains(out, fmt.Sprintf("to=0x%040d", i)) {
+			t.Errorf("group is missing recipient %d:\n%s", i, out)
+		}
+	}
+
+	buf.Reset()
+	printCallDetails(&buf, call, 0, TerminalOptions{ExpandAll: true}, plain, plain, plain, plain, plain)
+	if out := buf.String(); strings.Contains(out, "SUBCALLS #") || !strings.Contains(out, "SUBCALL #4)") {
+		t.Errorf("expand-all should print every subcall:\n%s", out)
+	}
+}
+
+func TestPrintCallDetailsGroupsOnlyEqualValues(t *testing.T) {
+	plain := func(a ...interface{}) string { return fmt.Sprint(a...) }
+	call := core.CallData{FunctionName: "multiSend"}
+	for i := 0; i < 5; i++ {
+		call.SubCalls = append(call.SubCalls, core.CallData{
+			Target:       core.OPTokenAddress,
+			FunctionName: "transfer",
+			ParsedData:   []core.Argument{{Name: "to", Value: "0x8b8B2F214D92527BF1b1148DC2e609a4C1c2Fd69"}, {Name: "amount", Value: "1.00"}},
+		})
+	}
+	call.SubCalls[3].Value = new(big.Int).Mul(big.NewInt(100), big.NewInt(1e18))
+	core.AssignCallIndices(&call)
+
+	var buf bytes.Buffer
+	printCallDetails(&buf, call, 0, TerminalOptions{}, plain, plain, plain, plain, plain)
+	out := buf.String()
+	if strings.Contains(out, "× 5") || !strings.Contains(out, "SUBCALLS #1–#3: transfer()") {
+		t.Errorf("a call with another value should end the group:\n%s", out)
+	}
+	if !strings.Contains(out, "SUBCALL #4)") || !strings.Contains(out, "Value: 100.00 ETH") {
+		t.Errorf("the call sending ETH should be printed with its value:\n%s", out)
+	}
+
+	for i := range call.SubCalls {
+		call.SubCalls[i].Value = big.NewInt(1e18)
+	}
+	buf.Reset()
+	printCallDetails(&buf, call, 0, TerminalOptions{}, plain, plain, plain, plain, plain)
+	if out := buf.String(); strings.Count(out, "value=1.00 ETH") != 5 {
+		t.Errorf("every grouped call should show its value:\n%s", out)
+	}
+}
+
+func TestPrintCallDetailsAnnotations(t *testing.T) {
+	plain := func(a ...interface{}) string { return fmt.Sprint(a...) }
+	type recipient struct{ Account string }
+
+	transfer := func(to string, annotations map[string]string) core.CallData {
+		return core.CallData{
+			Target:       "0x4200000000000000000000000000000000000042",
+			TargetName:   "OP Token",
+			FunctionName: "transfer",
+			ParsedData:   []core.Argument{{Name: "to", Value: to}, {Name: "amount", Value: "1"}, {Name: "recipients", Value: []recipient{{Account: to}}}},
+			Annotations:  annotations,
+		}
+	}
+	call := core.CallData{FunctionName: "multiSend"}
+	for i := 0; i < 4; i++ {
+		call.SubCalls = append(call.SubCalls, transfer(fmt.Sprintf("0x%040d", i), nil))
+	}
+	call.SubCalls[2].Annotations = map[string]string{"amount": "Q3 grant tranche", "recipients[0].Account": "grants multisig"}
+	core.AssignCallIndices(&call)
+
+	var buf bytes.Buffer
+	printCallDetails(&buf, call, 0, TerminalOptions{}, plain, plain, plain, plain, plain)
+	out := buf.String()
+	if !strings.Contains(out, "amount: 1  ◀ Q3 grant tranche") || !strings.Contains(out, "◀ grants multisig") {
+		t.Errorf("expected the annotations next to the values:\n%s", out)
+	}
+	// The annotated subcall is shown in full rather than as a line of a group
+	if !strings.Contains(out, "SUBCALL #3)") || strings.Contains(out, "SUBCALLS #1–#4") {
+		t.Errorf("expected the annotated subcall to be shown in full:\n%s", out)
+	}
+}
+
+func TestPrintCallDetailsArgumentOrder(t *testing.T) {
+	plain := func(a ...interface{}) string { return fmt.Sprint(a...) }
+	call := core.CallData{
+		Target:       "0x4200000000000000000000000000000000000042",
+		FunctionName: "transfer",
+		ParsedData: []core.Argument{
+			{Name: "to", Type: "address", Value: "0x0000000000000000000000000000000000000001"},
+			{Name: "amount", Type: "uint256", Value: big.NewInt(1000), Display: "0.000000000000001"},
+		},
+	}
+
+	var buf bytes.Buffer
+	printCallDetails(&buf, call, 0, TerminalOptions{}, plain, plain, plain, plain, plain)
+	out := buf.String()
+	if !strings.Contains(out, "to: 0x0000000000000000000000000000000000000001\namount: 0.000000000000001\n") {
+		t.Errorf("expected the arguments in signature order, the amount as displayed:\n%s", out)
+	}
+}
+
+func TestPrintCallDetailsSummary(t *testing.T) {
+	plain := func(a ...interface{}) string { return fmt.Sprint(a...) }
+	call := core.CallData{
+		Target:       core.CowSwapSettlement,
+		FunctionName: "setPreSignature",
+		ParsedData: []core.Argument{
+			{Name: "orderUid", Type: "bytes", Value: "0x01"},
+			{Name: "signed", Type: "bool", Value: true},
+		},
+		Summary: []core.Argument{
+			{Name: "owner", Value: "0x0000000000000000000000000000000000000001"},
+		},
+	}
+
+	var buf bytes.Buffer
+	printCallDetails(&buf, call, 0, TerminalOptions{}, plain, plain, plain, plain, plain)
+	out := buf.String()
+	if !strings.Contains(out, "orderUid: 0x01\nsigned: true\n\nSummary:\n  owner: 0x0000000000000000000000000000000000000001\n") {
+		t.Errorf("expected the summary below the raw arguments:\n%s", out)
+	}
+}
+
+func TestPrintCallDetailsDeposit(t *testing.T) {
+	plain := func(a ...interface{}) string { return fmt.Sprint(a...) }
+	call := core.CallData{
+		Target:       core.OptimismPortal,
+		FunctionName: "depositTransaction",
+		ParsedData:   []core.Argument{{Name: "gasLimit", Type: "uint64", Value: uint64(200000)}},
+		Deposit: &core.Deposit{
+			L2ChainID: core.OPMainnetChainID,
+			L1Sender:  "0x4444444444444444444444444444444444444444",
+			From:      "0x5555444444444444444444444444444444445555",
+			Mint:      big.NewInt(1e18),
+			Value:     big.NewInt(0),
+			GasLimit:  200000,
+			Call: &core.CallData{
+				Target:       core.OPTokenAddress,
+				TargetName:   "OP TOKEN",
+				FunctionName: "transfer",
+				ParsedData:   []core.Argument{{Name: "amount", Type: "uint256", Value: big.NewInt(25e17), Display: "2.5"}},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	printCallDetails(&buf, call, 0, TerminalOptions{}, plain, plain, plain, plain, plain)
+	out := buf.String()
+	for _, want := range []string{
+		"L2 TRANSACTION ON OP MAINNET",
+		"From: 0x5555444444444444444444444444444444445555 (aliased 0x4444444444444444444444444444444444444444)",
+		"Minted on L2: 1.00 ETH",
+		"To: 0x4200000000000000000000000000000000000042 (OP TOKEN 🔍)",
+		"Function: transfer\namount: 2.5\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+	if groupable(call) {
+		t.Error("a deposit should not be shown as one line of a group")
+	}
+}
+
+func TestPrintCallDetailsEmergency(t *testing.T) {
+	plain := func(a ...interface{}) string { return fmt.Sprint(a...) }
+	pause := core.CallData{Target: core.OPTokenAddress, FunctionName: "pause", ParsedData: []core.Argument{}, Emergency: true}
+	call := core.CallData{Target: "0x40A2aCCbd92BCA938b02010E17A5b8929b49130D", FunctionName: "multiSend"}
+	for i := 1; i <= 8; i++ {
+		subcall := pause
+		subcall.Index = strconv.Itoa(i)
+		call.SubCalls = append(call.SubCalls, subcall)
+	}
+
+	var buf bytes.Buffer
+	printCallDetails(&buf, call, 0, TerminalOptions{}, plain, plain, plain, plain, plain)
+	out := buf.String()
+	if !strings.Contains(out, "Emergency actions: #1 pause, #2 pause, #3 pause") {
+		t.Errorf("expected the emergency subcalls to be listed up front:\n%s", out)
+	}
+	if n := strings.Count(out, "🚨 EMERGENCY ACTION: PAUSE 🚨"); n != 8 {
+		t.Errorf("expected every emergency call to be shown with its banner, got %d:\n%s", n, out)
+	}
+}
+
+func TestPrintCallDetailsSubcallDigests(t *testing.T) {
+	plain := func(a ...interface{}) string { return fmt.Sprint(a...) }
+	transfer := core.CallData{Target: core.OPTokenAddress, FunctionName: "transfer", ParsedData: []core.Argument{}}
+	call := core.CallData{Target: "0x40A2aCCbd92BCA938b02010E17A5b8929b49130D", FunctionName: "multiSend"}
+	for i := 1; i <= 8; i++ {
+		subcall := transfer
+		subcall.Index = strconv.Itoa(i)
+		subcall.Digest = fmt.Sprintf("0x%064x", i)
+		call.SubCalls = append(call.SubCalls, subcall)
+	}
+
+	var buf bytes.Buffer
+	printCallDetails(&buf, call, 0, TerminalOptions{}, plain, plain, plain, plain, plain)
+	out := buf.String()
+	for i := 1; i <= 8; i++ {
+		if !strings.Contains(out, "digest "+fmt.Sprintf("0x%064X", i)) {
+			t.Errorf("expected grouped subcall %d to show its full digest:\n%s", i, out)
+		}
+	}
+	if match := truncationPattern.FindString(out); match != "" {
+		t.Errorf("grouped output contains truncated value %q:\n%s", match, out)
+	}
+
+	buf.Reset()
+	printCallDetails(&buf, call, 0, TerminalOptions{ExpandAll: true}, plain, plain, plain, plain, plain)
+	if out := buf.String(); !strings.Contains(out, "Subcall Digest: "+fmt.Sprintf("0x%064X", 8)) {
+		t.Errorf("expected the full digest of an expanded subcall:\n%s", out)
+	}
+}
+
+func TestPrintCallDetailsReviewed(t *testing.T) {
+	plain := func(a ...interface{}) string { return fmt.Sprint(a...) }
+	reviewedAt := time.Date(2025, 3, 1, 12, 30, 0, 0, time.UTC)
+	transfer := core.CallData{Target: core.OPTokenAddress, FunctionName: "transfer", ParsedData: []core.Argument{}}
+	call := core.CallData{Target: "0x40A2aCCbd92BCA938b02010E17A5b8929b49130D", FunctionName: "multiSend"}
+	for i := 1; i <= 7; i++ {
+		subcall := transfer
+		subcall.Index = strconv.Itoa(i)
+		if i <= 4 {
+			subcall.Review = &core.CallReview{Index: subcall.Index, FunctionName: "transfer", ReviewedAt: reviewedAt}
+		}
+		call.SubCalls = append(call.SubCalls, subcall)
+	}
+	call.SubCalls[3].Review.Note = "checked against the grants sheet"
+
+	var buf bytes.Buffer
+	printCallDetails(&buf, call, 0, TerminalOptions{}, plain, plain, plain, plain, plain)
+	out := buf.String()
+	for _, want := range []string{
+		"Reviewed subcalls: 4 of 7",
+		"#1      ✓ reviewed",
+		"Reviewed: ✓ 2025-03-01 12:30 UTC — checked against the grants sheet",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "#4      ✓ reviewed") {
+		t.Errorf("a call with a review note should be shown in full, not grouped:\n%s", out)
+	}
+}
+
+func TestPrintReviewRecord(t *testing.T) {
+	plain := func(a ...interface{}) string { return fmt.Sprint(a...) }
+	reviewedAt := time.Date(2025, 3, 1, 12, 30, 0, 0, time.UTC)
+	result := &core.VerificationResult{
+		Call: core.CallData{FunctionName: "multiSend", SubCalls: []core.CallData{
+			{Index: "1", FunctionName: "transfer", Review: &core.CallReview{Index: "1", ReviewedAt: reviewedAt}},
+			{Index: "2", FunctionName: "transfer"},
+		}},
+		Warnings: []core.Warning{
+			{Severity: core.SeverityCritical, Message: "the target is denylisted"},
+			{Severity: core.SeverityCritical, Message: "ownership is transferred"},
+		},
+	}
+	result.Review = &core.SessionTransaction{
+		Source:       "tx.json",
+		Sections:     []core.SectionReview{{Section: SectionSummary, ReviewedAt: reviewedAt, Note: "matches the proposal"}},
+		Acknowledged: []core.Acknowledgement{{Message: "ownership is transferred", AcknowledgedAt: reviewedAt, Note: "planned handover"}},
+	}
+
+	var buf bytes.Buffer
+	printReviewRecord(&buf, result, plain, plain, plain, plain)
+	out := buf.String()
+	for _, want := range []string{
+		"REVIEW RECORD",
+		"Source: tx.json",
+		"Section summary: ✓ 2025-03-01 12:30 UTC — matches the proposal",
+		"Calls reviewed: 1 of 2",
+		"Acknowledged: ownership is transferred\n  planned handover\n",
+		"1 CRITICAL WARNING(S) NOT ACKNOWLEDGED",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+
+	buf.Reset()
+	result.Review = nil
+	printReviewRecord(&buf, result, plain, plain, plain, plain)
+	if buf.Len() != 0 {
+		t.Errorf("expected no review record outside of a review session:\n%s", buf.String())
+	}
+}
+
+func TestPrettyPrintTupleFieldNames(t *testing.T) {
+	plain := func(a ...interface{}) string { return fmt.Sprint(a...) }
+	// ABI-decoded tuples keep the Solidity component names in their json tags
+	type input struct {
+		SystemConfigProxy string `json:"systemConfigProxy"`
+		ProxyAdmin        string `json:"proxyAdmin"`
+		AbsolutePrestate  string `json:"absolutePrestate"`
+		Untagged          string
+	}
+	notes := map[string]string{"inputs[0].absolutePrestate": "cannon prestate"}
+
+	var buf bytes.Buffer
+	prettyPrintValue(&buf, "inputs", []input{{"0x1", "0x2", "0x3", "x"}}, plain, "", 0, "inputs", notes)
+	out := buf.String()
+	want := "      systemConfigProxy: 0x1\n      proxyAdmin: 0x2\n      absolutePrestate: 0x3  ◀ cannon prestate\n      Untagged: x\n"
+	if !strings.Contains(out, want) {
+		t.Errorf("expected the fields under their Solidity names in declared order:\n%s", out)
+	}
+}
+
+func TestPrettyPrintBytes32Interpretation(t *testing.T) {
+	plain := func(a ...interface{}) string { return fmt.Sprint(a...) }
+	type input struct {
+		Schema [32]byte `json:"schema"`
+		Salt   [32]byte `json:"salt"`
+	}
+	var text [32]byte
+	copy(text[:], "grants")
+
+	var buf bytes.Buffer
+	prettyPrintValue(&buf, "request", input{Schema: text, Salt: [32]byte{1, 2, 3}}, plain, "", 0, "request", nil)
+	out := buf.String()
+	if !strings.Contains(out, "schema: 0x6772616e7473"+strings.Repeat("0", 52)+` (text "grants")`) {
+		t.Errorf("expected the text to be shown beside the value:\n%s", out)
+	}
+	if !strings.Contains(out, "salt: 0x010203"+strings.Repeat("0", 58)+"\n") {
+		t.Errorf("expected no interpretation of an unrecognized value:\n%s", out)
+	}
+}
+
+func TestPrintPreviousDiff(t *testing.T) {
+	plain := func(a ...interface{}) string { return fmt.Sprint(a...) }
+	diff := &core.TransactionDiff{
+		PreviousNonce:      4,
+		PreviousSafeTxHash: "0xabc",
+		TransactionChanges: []core.TransactionChange{{Kind: core.ChangeModified, Field: "data", Previous: "68 bytes", Current: "100 bytes"}},
+		DecodedValueChanges: []core.TransactionChange{
+			{Kind: core.ChangeModified, Field: "call #2 amount", Previous: "200", Current: "250"},
+			{Kind: core.ChangeAdded, Field: "call #3 (target)", Current: "0x1111"},
+		},
+	}
+
+	var buf bytes.Buffer
+	printPreviousDiff(&buf, diff, plain, plain, plain, plain, plain)
+	out := buf.String()
+	for _, want := range []string{"CHANGES SINCE NONCE 4", "0xABC", "~ call #2 amount: 200 → 250", "+ call #3 (target): 0x1111", "~ data: 68 bytes → 100 bytes"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in output:\n%s", want, out)
+		}
+	}
+
+	buf.Reset()
+	printPreviousDiff(&buf, &core.TransactionDiff{PreviousNonce: 4}, plain, plain, plain, plain, plain)
+	if !strings.Contains(buf.String(), "Nothing changed besides the nonce") {
+		t.Errorf("expected an unchanged transaction to say so:\n%s", buf.String())
+	}
+}
+
+func TestPrintRejection(t *testing.T) {
+	plain := func(a ...interface{}) string { return fmt.Sprint(a...) }
+	result := &core.VerificationResult{Transaction: core.SafeTransaction{Nonce: 12}, Rejection: true}
+
+	var buf bytes.Buffer
+	printRejection(&buf, "CHILD ", result, plain, plain, plain, plain)
+	out := buf.String()
+	for _, want := range []string{"CHILD REJECTION / NONCE BURN", "using up nonce 12", "can then never execute"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in output:\n%s", want, out)
+		}
+	}
+
+	buf.Reset()
+	printRejection(&buf, "", &core.VerificationResult{}, plain, plain, plain, plain)
+	if buf.Len() != 0 {
+		t.Errorf("expected nothing for other transactions:\n%s", buf.String())
+	}
+}
+
+func TestHashBlock(t *testing.T) {
+	result := &core.VerificationResult{DomainHash: "0xaa", MessageHash: "0xbb", ApproveHash: "0xcc"}
+	if got, want := HashBlock(result), "0xAA\n0xBB\n0xCC"; got != want {
+		t.Fatalf("HashBlock = %q, want %q", got, want)
+	}
+}
+
+func TestPrintPhoneticHash(t *testing.T) {
+	plain := func(a ...interface{}) string { return fmt.Sprint(a...) }
+
+	var buf bytes.Buffer
+	printPhoneticHash(&buf, "0x19767d264966e39d532d998c5354f76ad5102407124b1885d69bf23f791b6f4c", plain, plain, plain)
+	out := buf.String()
+	for _, want := range []string{" 1.  1976  one niner seven six", "16.  6F4C  six foxtrot four charlie"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+// truncationPattern matches shortened hex values such as "0x1234…abcd" or "0x1234...abcd"
+var truncationPattern = regexp.MustCompile(`0x[0-9a-fA-F]*(…|\.\.\.)`)
+
+func TestFormatTerminalNeverTruncates(t *testing.T) {
+	tx := core.SafeTransaction{
+		Safe:        "0x2501c477d0a35545a387aa4a3eee4292a9a8b3f0",
+		SafeVersion: "1.3.0",
+		Chain:       int(core.OPMainnetChainID),
+		To:          "0x4200000000000000000000000000000000000042",
+		Value:       big.NewInt(0),
+		Data:        "0xa9059cbb0000000000000000000000008b8b2f214d92527bf1b1148dc2e609a4c1c2fd69000000000000000000000000000000000000000000034f086f3b33b684000000",
+		Nonce:       155,
+		Nested: &core.Nested{
+			Safe:        "0xe2ed962948005ab01f2cefe8326a0730b7d268af",
+			SafeVersion: "1.3.0",
+			Nonce:       42,
+			To:          "0x2501c477d0a35545a387aa4a3eee4292a9a8b3f0",
+			Data:        "0xd4d9bdcd19767d264966e39d532d998c5354f76ad5102407124b1885d69bf23f791b6f4c",
+		},
+	}
+	result, err := core.VerifyTransaction(tx, core.VerifyOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := FormatTerminalWithOptions(result, &buf, TerminalOptions{Verbosity: VerbosityDetailed, Phonetic: true}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out := buf.String()
+
+	if match := truncationPattern.FindString(out); match != "" {
+		t.Errorf("output contains truncated value %q:\n%s", match, out)
+	}
+	for _, address := range []string{
+		"0x2501c477D0A35545a387Aa4A3EEe4292A9a8B3F0",
+		"0xE2Ed962948005AB01F2cEfE8326a0730B7D268af",
+		"0x8b8B2F214D92527BF1b1148DC2e609a4C1c2Fd69",
+	} {
+		if !strings.Contains(out, address) {
+			t.Errorf("output should contain %s in full with checksum casing:\n%s", address, out)
+		}
+	}
+}
+
+func TestFormatTerminalNeverTruncatesGroups(t *testing.T) {
+	// A MultiSend batch of four identical OP transfers, which the call details collapse into a group
+	transfer := "a9059cbb0000000000000000000000008b8b2f214d92527bf1b1148dc2e609a4c1c2fd69000000000000000000000000000000000000000000034f086f3b33b684000000"
+	entry := "00" + "4200000000000000000000000000000000000042" + fmt.Sprintf("%064x%064x", 0, len(transfer)/2) + transfer
+	packed, err := core.KnownFunctions["8d80ff0a"].ABI.Inputs.Pack(common.FromHex(strings.Repeat(entry, 4)))
+	if err != nil {
+		t.Fatalf("failed to pack multiSend: %v", err)
+	}
+	tx := core.SafeTransaction{
+		Safe:        "0x2501c477d0a35545a387aa4a3eee4292a9a8b3f0",
+		SafeVersion: "1.3.0",
+		Chain:       int(core.OPMainnetChainID),
+		To:          core.SafeMultisendCallOnly141,
+		Value:       big.NewInt(0),
+		Data:        "0x8d80ff0a" + common.Bytes2Hex(packed),
+		Operation:   1,
+		Nonce:       155,
+	}
+	result, err := core.VerifyTransaction(tx, core.VerifyOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := FormatTerminalWithOptions(result, &buf, TerminalOptions{Verbosity: VerbosityDetailed}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out := buf.String()
+	if !strings.Contains(out, "SUBCALLS #1–#4") {
+		t.Fatalf("expected the transfers to be grouped:\n%s", out)
+	}
+	if match := truncationPattern.FindString(out); match != "" {
+		t.Errorf("grouped output contains truncated value %q:\n%s", match, out)
+	}
+	if digest := formatHash(result.Call.SubCalls[0].Digest); strings.Count(out, digest) != 4 {
+		t.Errorf("expected the full digest %s on every grouped call:\n%s", digest, out)
+	}
+}
+
+func TestPrintCalldataAnnotation(t *testing.T) {
+	plain := func(a ...interface{}) string { return fmt.Sprint(a...) }
+
+	var buf bytes.Buffer
+	printCalldataAnnotation(&buf, "CALLDATA ANNOTATION", core.OPTokenAddress, core.OPMainnetChainID, "0xa9059cbb0000000000000000000000008b8b2f214d92527bf1b1148dc2e609a4c1c2fd69000000000000000000000000000000000000000000034f086f3b33b684000000", plain, plain, plain, plain)
+	out := buf.String()
+	for _, want := range []string{
+		"0x0000  a9059cbb",
+		"selector: transfer(address,uint256)",
+		"0x0004  0000000000000000000000008b8b2f214d92527bf1b1148dc2e609a4c1c2fd69  to (address) = 0x8b8B2F214D92527BF1b1148DC2e609a4C1c2Fd69",
+		"0x0024  000000000000000000000000000000000000000000034f086f3b33b684000000  amount (uint256)",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+
+	buf.Reset()
+	printCalldataAnnotation(&buf, "CALLDATA ANNOTATION", core.OPTokenAddress, core.OPMainnetChainID, "0x", plain, plain, plain, plain)
+	if !strings.Contains(buf.String(), "No calldata") {
+		t.Errorf("expected empty calldata to be explained:\n%s", buf.String())
+	}
+}
+
+func TestFormatPerturbationsTerminal(t *testing.T) {
+	report := &core.PerturbationReport{
+		Baseline: core.TransactionHashes{DomainHash: "0xaa", MessageHash: "0xbb", ApproveHash: "0xcc"},
+		Perturbations: []core.Perturbation{
+			{
+				Field:       core.PerturbNonce,
+				Description: "nonce increased by 1",
+				Original:    "155",
+				Perturbed:   "156",
+				Hashes:      core.TransactionHashes{DomainHash: "0xaa", MessageHash: "0xbd", ApproveHash: "0xcd"},
+			},
+			{
+				Field:       core.PerturbValue,
+				Description: "value multiplied by 10",
+				Original:    "1",
+				Perturbed:   "10",
+				Hashes:      core.TransactionHashes{DomainHash: "0xaa", MessageHash: "0xbb", ApproveHash: "0xcc"},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := FormatPerturbationsTerminal(report, &buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{"NONCE:", "was: 155", "now: 156", "0xBD  changed", "0xAA  unchanged", "not bound by the signature"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Count(out, "not bound by the signature") != 1 {
+		t.Errorf("expected only the unchanged perturbation to be flagged:\n%s", out)
+	}
+}
+
+func TestFormatTerminalRoles(t *testing.T) {
+	tx := core.SafeTransaction{
+		Safe:           "0x2501c477D0A35545a387Aa4A3EEe4292A9a8B3F0",
+		SafeVersion:    "1.3.0",
+		Chain:          core.OPMainnetChainID,
+		To:             core.OPTokenAddress,
+		Value:          big.NewInt(0),
+		Data:           "0xa9059cbb0000000000000000000000008b8b2f214d92527bf1b1148dc2e609a4c1c2fd69000000000000000000000000000000000000000000034f086f3b33b684000000",
+		GasToken:       core.ZeroAddress,
+		RefundReceiver: core.ZeroAddress,
+		Nonce:          155,
+
+		ServiceSafeTxHash: "0x19767d264966e39d532d998c5354f76ad5102407124b1885d69bf23f791b6f4c",
+		SignerProgress: &core.SignerProgress{
+			Required:      2,
+			Confirmations: []core.APIConfirmation{{Owner: "0x9a69d97a451643a0bb4462476942d2bc844431ce", SubmissionDate: "2025-03-11T17:05:12.482Z"}},
+		},
+	}
+	result, err := core.VerifyTransaction(tx, core.VerifyOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	render := func(role string) string {
+		options, err := ApplyRole(TerminalOptions{}, role)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		var buf bytes.Buffer
+		if err := FormatTerminalWithOptions(result, &buf, options); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return buf.String()
+	}
+
+	facilitator := render(RoleFacilitator)
+	for _, want := range []string{
+		"FACILITATOR DETAILS",
+		"Signer progress: 1 of 2 signatures collected",
+		core.ChecksumAddress("0x9a69d97a451643a0bb4462476942d2bc844431ce") + " (2025-03-11T17:05:12.482Z)",
+		"Service hash: ✅ matches the locally computed hash",
+		"Safe UI: https://app.safe.global/transactions/tx?safe=oeth:0x2501c477D0A35545a387Aa4A3EEe4292A9a8B3F0&id=multisig_",
+		"QR codes: " + DefaultQRSiteURL + "?tx=",
+		"run op-txverify compare-hashes",
+	} {
+		if !strings.Contains(facilitator, want) {
+			t.Errorf("facilitator output missing %q:\n%s", want, facilitator)
+		}
+	}
+
+	signer := render(RoleSigner)
+	if strings.Contains(signer, "FACILITATOR DETAILS") {
+		t.Errorf("signer output should not include facilitator details:\n%s", signer)
+	}
+	if !strings.Contains(signer, "STOP AND ASK THE FACILITATOR") {
+		t.Errorf("signer output missing signer instructions:\n%s", signer)
+	}
+
+	if _, err := ApplyRole(TerminalOptions{}, "observer"); err == nil {
+		t.Error("expected an error for an unknown role")
+	}
+}
+
+func TestParseSections(t *testing.T) {
+	sections, err := ParseSections(" Hashes, warnings,,subcalls ")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []string{SectionHashes, SectionWarnings, SectionSubcalls}; !reflect.DeepEqual(sections, want) {
+		t.Errorf("expected %v, got %v", want, sections)
+	}
+	if sections, err := ParseSections(""); err != nil || sections != nil {
+		t.Errorf("expected no sections for an empty list, got %v, %v", sections, err)
+	}
+	if _, err := ParseSections("hashes,calldata"); err == nil || !strings.Contains(err.Error(), "calldata") {
+		t.Errorf("expected an error naming the unknown section, got %v", err)
+	}
+}
+
+func TestFormatTerminalSections(t *testing.T) {
+	tx := core.SafeTransaction{
+		Safe:           "0x2501c477D0A35545a387Aa4A3EEe4292A9a8B3F0",
+		SafeVersion:    "1.3.0",
+		Chain:          core.OPMainnetChainID,
+		To:             core.OPTokenAddress,
+		Value:          big.NewInt(0),
+		Data:           "0xa9059cbb0000000000000000000000008b8b2f214d92527bf1b1148dc2e609a4c1c2fd69000000000000000000000000000000000000000000034f086f3b33b684000000",
+		GasToken:       core.ZeroAddress,
+		RefundReceiver: core.ZeroAddress,
+		Nonce:          155,
+	}
+	result, err := core.VerifyTransaction(tx, core.VerifyOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	result.Warnings = []core.Warning{
+		{Severity: core.SeverityCritical, Message: "the target is denylisted"},
+		{Severity: core.SeverityInfo, Message: "the service was not asked"},
+	}
+
+	render := func(options TerminalOptions) string {
+		var buf bytes.Buffer
+		if err := FormatTerminalWithOptions(result, &buf, options); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return buf.String()
+	}
+
+	standard := render(TerminalOptions{})
+	for _, want := range []string{"TRANSACTION SUMMARY", "the service was not asked", "HASHES"} {
+		if !strings.Contains(standard, want) {
+			t.Errorf("standard output missing %q:\n%s", want, standard)
+		}
+	}
+	if strings.Contains(standard, "RAW CALLDATA") {
+		t.Errorf("standard output should not include the raw calldata:\n%s", standard)
+	}
+
+	raw := render(TerminalOptions{Verbosity: VerbosityRaw})
+	if want := "0xa9059cbb0000000000000000000000008b8b2f214d92527bf1b1148dc2e609a4c1c2fd69"; !strings.Contains(raw, "RAW CALLDATA") || !strings.Contains(raw, want) {
+		t.Errorf("raw output missing the raw calldata:\n%s", raw)
+	}
+
+	// Only the chosen sections are printed, but critical warnings never go unseen
+	hashes := render(TerminalOptions{Show: []string{SectionHashes}, Verbosity: VerbosityRaw})
+	for _, want := range []string{"HASHES", "the target is denylisted", "1 WARNING(S) NOT SHOWN"} {
+		if !strings.Contains(hashes, want) {
+			t.Errorf("hashes output missing %q:\n%s", want, hashes)
+		}
+	}
+	for _, unwanted := range []string{"TRANSACTION SUMMARY", "the service was not asked", "RAW CALLDATA", "transfer"} {
+		if strings.Contains(hashes, unwanted) {
+			t.Errorf("hashes output should not include %q:\n%s", unwanted, hashes)
+		}
+	}
+}
+
+func TestFormatTerminalRedacted(t *testing.T) {
+	tx := core.SafeTransaction{
+		Safe:           "0x2501c477D0A35545a387Aa4A3EEe4292A9a8B3F0",
+		SafeVersion:    "1.3.0",
+		Chain:          core.OPMainnetChainID,
+		To:             core.OPTokenAddress,
+		Value:          big.NewInt(0),
+		Data:           "0xa9059cbb0000000000000000000000008b8b2f214d92527bf1b1148dc2e609a4c1c2fd69000000000000000000000000000000000000000000034f086f3b33b684000000",
+		GasToken:       core.ZeroAddress,
+		RefundReceiver: core.ZeroAddress,
+		Nonce:          155,
+	}
+	result, err := core.VerifyTransaction(tx, core.VerifyOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var buf bytes.Buffer
+	options := TerminalOptions{Verbosity: VerbosityRaw, Role: RoleFacilitator}
+	if err := FormatTerminalWithOptions(core.RedactResult(result), &buf, options); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"REDACTED",
+		"to: " + core.RedactedAddress,
+		"amount: " + core.RedactedAmount,
+		"Target: " + core.OPTokenAddress,
+		core.RedactedData,
+		formatHash(result.ApproveHash),
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("redacted output missing %q:\n%s", want, out)
+		}
+	}
+	for _, leaked := range []string{"8b8b2f214d92527bf1b1148dc2e609a4c1c2fd69", "QR codes"} {
+		if strings.Contains(strings.ToLower(out), strings.ToLower(leaked)) {
+			t.Errorf("redacted output should not include %q:\n%s", leaked, out)
+		}
+	}
+}
+
+func TestFormatSignatureTerminal(t *testing.T) {
+	export := core.SignatureExport{
+		Type:       core.SignatureExportType,
+		Safe:       "0x2501c477D0A35545a387Aa4A3EEe4292A9a8B3F0",
+		Chain:      core.OPMainnetChainID,
+		Nonce:      155,
+		SafeTxHash: "0x19767d264966e39d532d998c5354f76ad5102407124b1885d69bf23f791b6f4c",
+		Signer:     "0x90F79bf6EB2c4f870365E785982E1f101E93b906",
+		Signature:  "0xabcd",
+	}
+
+	var buf bytes.Buffer
+	if err := FormatSignatureTerminal(export, "", &buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Contains(buf.String(), "curl") {
+		t.Errorf("unchecked export should not suggest submitting:\n%s", buf.String())
+	}
+
+	buf.Reset()
+	if err := FormatSignatureTerminal(export, "transaction", &buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"Signer: 0x90F79bf6EB2c4f870365E785982E1f101E93b906",
+		"matches the transaction hash computed here",
+		"https://safe-transaction-optimism.safe.global/api/v1/multisig-transactions/0x19767d264966e39d532d998c5354f76ad5102407124b1885d69bf23f791b6f4c/confirmations/",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestFormatUserOperationTerminal(t *testing.T) {
+	result := &core.UserOperationResult{
+		Request:       core.UserOperationRequest{ValidUntil: 1893456000},
+		Chain:         int(core.OPMainnetChainID),
+		Safe:          "0x2501c477D0A35545a387Aa4A3EEe4292A9a8B3F0",
+		EntryPoint:    core.Safe4337Deployments[1].EntryPoint,
+		Module:        core.Safe4337Deployments[1].Module,
+		ModuleVersion: "0.3.0",
+		NonceKey:      big.NewInt(0),
+		NonceSequence: big.NewInt(7),
+		DomainHash:    "0x1111111111111111111111111111111111111111111111111111111111111111",
+		MessageHash:   "0x2222222222222222222222222222222222222222222222222222222222222222",
+		SafeOpHash:    "0x3333333333333333333333333333333333333333333333333333333333333333",
+		UserOpHash:    "0x4444444444444444444444444444444444444444444444444444444444444444",
+		To:            "0x1111111111111111111111111111111111111111",
+		Value:         big.NewInt(1e15),
+		Call:          core.CallData{Target: "0x1111111111111111111111111111111111111111", FunctionName: "unknown"},
+		Paymaster:     "0x2222222222222222222222222222222222222222",
+		MaxGasCost:    big.NewInt(1e15),
+		Warnings:      []core.Warning{{Severity: core.SeverityWarning, Message: "Gas is handled by paymaster"}},
+	}
+
+	var buf bytes.Buffer
+	if err := FormatUserOperationTerminal(result, &buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"Safe4337Module v0.3.0",
+		"Nonce: 7 (key 0)",
+		"Valid Until: 1893456000 (2030-01-01T00:00:00Z)",
+		"Paymaster: 0x2222222222222222222222222222222222222222",
+		"SafeOp Hash:  0x3333",
+		"UserOp Hash:  0x4444",
+		"Gas is handled by paymaster",
+		"there is no Safe tx hash to compare",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestFormatAirdropCheckTerminal(t *testing.T) {
+	check := &core.AirdropCheck{
+		Rows:      2,
+		Transfers: 2,
+		Matched:   1,
+		Totals: []core.AirdropTotal{
+			{TokenType: core.AirdropNative, Transfers: 1, Amount: "1.00"},
+			{TokenType: core.AirdropERC20, Token: core.USDCMainnetAddress, Transfers: 1, Amount: "25.00"},
+		},
+		Divergences: []core.AirdropDivergence{{Kind: core.AirdropMismatch, Message: "line 3: CSV sends 2.5 to 0x2222"}},
+	}
+
+	var buf bytes.Buffer
+	if err := FormatAirdropCheckTerminal(check, &buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"ETH: 1.00 in 1 transfers",
+		"ERC20 " + core.USDCMainnetAddress + ": 25.00 in 1 transfers",
+		"MISMATCH: line 3: CSV sends 2.5 to 0x2222",
+		"DO NOT SIGN",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+
+	check.Divergences = nil
+	buf.Reset()
+	if err := FormatAirdropCheckTerminal(check, &buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(buf.String(), "Every CSV row matches") {
+		t.Errorf("expected a success line:\n%s", buf.String())
+	}
+}
+
+func TestFormatBatchCommitmentTerminal(t *testing.T) {
+	commitment := &core.BatchCommitment{
+		CallCount:  3,
+		OtherCalls: 1,
+		ChunkSize:  2,
+		Root:       "0x" + strings.Repeat("ab", 32),
+		TotalsHash: "0x" + strings.Repeat("cd", 32),
+		Commitment: "0x" + strings.Repeat("ef", 32),
+		Totals:     []core.AirdropTotal{{TokenType: core.AirdropNative, Transfers: 2, Amount: "1.50"}},
+		Chunks: []core.BatchChunk{
+			{Number: 1, First: 1, Last: 2, Root: "0x" + strings.Repeat("12", 32), Totals: []core.AirdropTotal{{TokenType: core.AirdropNative, Transfers: 2, Amount: "1.50"}}},
+			{Number: 2, First: 3, Last: 3, Root: "0x" + strings.Repeat("34", 32)},
+		},
+		Calls: []core.BatchCall{
+			{Index: 1, Transfer: core.AirdropTransfer{TokenType: core.AirdropNative}, Description: "1.00 ETH to 0x1111"},
+			{Index: 2, Transfer: core.AirdropTransfer{TokenType: core.AirdropNative}, Description: "0.50 ETH to 0x2222"},
+			{Index: 3, Description: "call to 0x3333 with 0 ETH (not a transfer)"},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := FormatBatchCommitmentTerminal(commitment, 1, &buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"CHUNK 1 OF 2 (CALLS 1-2)",
+		"2. 0.50 ETH to 0x2222",
+		"Chunk Root: 0x" + strings.Repeat("12", 32),
+		"1 calls are not plain transfers",
+		"Commitment:   0x" + strings.Repeat("EF", 32),
+		"2 (calls 3-3): 0x" + strings.Repeat("34", 32),
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "not a transfer)") {
+		t.Errorf("chunk 1 should not list call 3:\n%s", out)
+	}
+
+	if err := FormatBatchCommitmentTerminal(commitment, 3, &buf); err == nil {
+		t.Error("expected an error for a chunk past the end")
+	}
+}
+
+func TestFormatWithdrawalCheckTerminal(t *testing.T) {
+	verified, committed := true, false
+	check := &core.WithdrawalCheck{Withdrawals: []core.Withdrawal{{
+		Call:                     "Call #1",
+		Function:                 "proveWithdrawalTransaction",
+		Portal:                   core.OptimismPortal,
+		Nonce:                    big.NewInt(42),
+		Sender:                   "0x1111111111111111111111111111111111111111",
+		Target:                   "0x2222222222222222222222222222222222222222",
+		Value:                    big.NewInt(15e17),
+		GasLimit:                 big.NewInt(100000),
+		Data:                     "0x",
+		WithdrawalHash:           "0x" + strings.Repeat("ab", 32),
+		StorageSlot:              "0x" + strings.Repeat("cd", 32),
+		OutputRoot:               "0x" + strings.Repeat("ef", 32),
+		MessagePasserStorageRoot: "0x" + strings.Repeat("12", 32),
+		DisputeGameIndex:         big.NewInt(7),
+		ProofVerified:            &verified,
+		Committed:                &committed,
+		Problems:                 []string{"L2ToL1MessagePasser on L2 has no withdrawal with this hash"},
+	}}}
+
+	var buf bytes.Buffer
+	if err := FormatWithdrawalCheckTerminal(check, &buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"WITHDRAWAL: CALL #1 (proveWithdrawalTransaction)",
+		"Value: 1.5 ETH",
+		"Withdrawal Hash:  0x" + strings.Repeat("AB", 32),
+		"Output Root:      0x" + strings.Repeat("EF", 32),
+		"Dispute Game Index: 7",
+		"withdrawal proof shows this withdrawal",
+		"❌ L2ToL1MessagePasser on L2 has no withdrawal",
+		"DO NOT SIGN",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+
+	check.Withdrawals[0].Committed, check.Withdrawals[0].Problems = nil, nil
+	buf.Reset()
+	if err := FormatWithdrawalCheckTerminal(check, &buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out := buf.String(); !strings.Contains(out, "Pass --l2-rpc-url") || strings.Contains(out, "DO NOT SIGN") {
+		t.Errorf("expected an unchecked commitment without a failure:\n%s", out)
+	}
+}
+
+func TestFormatTerminalExecution(t *testing.T) {
+	result := &core.VerificationResult{
+		Transaction: core.SafeTransaction{
+			Safe:  "0x2501c477D0A35545a387Aa4A3EEe4292A9a8B3F0",
+			Chain: 10,
+			To:    "0x1111111111111111111111111111111111111111",
+			Value: big.NewInt(0),
+			Data:  "0x",
+			Execution: &core.Execution{
+				TransactionHash: "0xabc",
+				BlockNumber:     100,
+				Finality:        &core.Finality{Found: true, Canonical: true, BlockNumber: 100, Confirmations: 5, FinalizedBlock: 90},
+			},
+		},
+		Call: core.CallData{Target: "0x1111111111111111111111111111111111111111"},
+	}
+
+	var buf bytes.Buffer
+	if err := FormatTerminal(result, &buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{"Executed In: 0xabc (block 100)", "NOT FINALIZED (block 100, 5 confirmations, finalized block 90)"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("output missing %q:\n%s", want, buf.String())
+		}
+	}
+
+	result.Transaction.Execution.Effects = &core.ExecutionEffects{
+		Succeeded: true,
+		Events:    []core.DecodedEvent{{LogIndex: 3, Address: "0xA0b8", Contract: "USDC", Name: "Transfer", Description: "2.5 USDC from 0x4444 to 0x2222"}},
+		Expected: []core.Effect{
+			{Kind: core.EffectTransfer, Description: "2.5 USDC from 0x4444 to 0x2222"},
+			{Kind: core.EffectTransfer, Description: "1 USDC from 0x4444 to 0x3333"},
+		},
+		Missing:    []core.Effect{{Kind: core.EffectTransfer, Description: "1 USDC from 0x4444 to 0x3333"}},
+		Unexpected: []core.Effect{{Kind: core.EffectApproval, Description: "0x4444 approves 0x5555 to spend 9 USDC"}},
+	}
+	result.Transaction.Execution.Finality.Finalized = true
+	buf.Reset()
+	if err := FormatTerminal(result, &buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{
+		"✅ finalized (block 100, 5 confirmations)",
+		"EXECUTION EFFECTS",
+		"3. Transfer from 0xA0b8 (USDC): 2.5 USDC from 0x4444 to 0x2222",
+		"✅ 2.5 USDC from 0x4444 to 0x2222",
+		"❌ NO EVENT: 1 USDC from 0x4444 to 0x3333",
+		"NOT IN CALLDATA: 0x4444 approves 0x5555 to spend 9 USDC",
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("output missing %q:\n%s", want, buf.String())
+		}
+	}
+}
+
+func TestFormatTerminalDeployment(t *testing.T) {
+	deployer := "0x9b35Af71d77eaf8d7e40252370304687390A1A52"
+	result := &core.VerificationResult{
+		Transaction: core.SafeTransaction{
+			Safe:  "0x2501c477D0A35545a387Aa4A3EEe4292A9a8B3F0",
+			Chain: 10,
+			To:    deployer,
+			Value: big.NewInt(0),
+			Data:  "0x",
+		},
+		Call: core.CallData{
+			Target:       deployer,
+			FunctionName: "performCreate",
+			ParsedData:   []core.Argument{{Name: "value", Type: "uint256", Value: big.NewInt(0)}},
+			Deployment: &core.Deployment{
+				Method:       core.DeploymentCreate,
+				Deployer:     "0x2501c477D0A35545a387Aa4A3EEe4292A9a8B3F0",
+				Value:        big.NewInt(0),
+				InitCodeHash: "0x1234",
+				InitCodeSize: 6,
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := FormatTerminal(result, &buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{
+		"Deploys: CREATE from 0x2501c477D0A35545a387Aa4A3EEe4292A9a8B3F0",
+		"Init Code: 6 bytes, keccak256 0x1234",
+		"Deployment Address: depends on the nonce of 0x2501c477D0A35545a387Aa4A3EEe4292A9a8B3F0 at execution",
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("output missing %q:\n%s", want, buf.String())
+		}
+	}
+
+	result.Call.Deployment.Method = core.DeploymentCreate2
+	result.Call.Deployment.Salt = "0x00aa"
+	result.Call.Deployment.Address = "0x5555555555555555555555555555555555555555"
+	buf.Reset()
+	if err := FormatTerminal(result, &buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{"Salt: 0x00aa", "Deployment Address: 0x5555555555555555555555555555555555555555"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("output missing %q:\n%s", want, buf.String())
+		}
+	}
+}
+
+func TestFormatTerminalOwnerDiff(t *testing.T) {
+	kept := "0x00000000000000000000000000000000000000A1"
+	removed := "0x00000000000000000000000000000000000000b2"
+	added := "0x00000000000000000000000000000000000000E5"
+	result := &core.VerificationResult{
+		Transaction: core.SafeTransaction{
+			Safe:  "0x2501c477D0A35545a387Aa4A3EEe4292A9a8B3F0",
+			Chain: 10,
+			To:    "0x2501c477D0A35545a387Aa4A3EEe4292A9a8B3F0",
+			Value: big.NewInt(0),
+			Data:  "0x",
+		},
+		Call: core.CallData{Target: "0x2501c477D0A35545a387Aa4A3EEe4292A9a8B3F0", FunctionName: "swapOwner"},
+		OwnerDiff: &core.OwnerDiff{
+			Changes: []core.OwnerChange{{Function: "swapOwner", Description: "replace owner " + removed + " with " + added}},
+			Before:  &core.OwnerSet{Owners: []string{kept, removed}, Threshold: 2},
+			After:   &core.OwnerSet{Owners: []string{kept, added}, Threshold: 2},
+			Added:   []string{added},
+			Removed: []string{removed},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := FormatTerminal(result, &buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"OWNERS AND THRESHOLD",
+		"replace owner " + removed + " with " + added,
+		"Threshold                                    2 of 2       2 of 2",
+		kept + "   owner        owner",
+		added + "   -            added",
+		removed + "   owner        removed",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+
+	result.OwnerDiff.After, result.OwnerDiff.Revert = nil, "swapOwner: "+removed+" is not an owner (GS205)"
+	buf.Reset()
+	if err := FormatTerminal(result, &buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(buf.String(), "THE OWNER CHANGES WOULD REVERT: swapOwner") {
+		t.Errorf("output missing revert:\n%s", buf.String())
+	}
+}
+
+func TestFormatRPCHealthTerminal(t *testing.T) {
+	checks := []core.RPCHealth{
+		{URL: "https://op.example", ExpectedChainID: 10, ChainID: 10, LatestBlock: 1000, BlockAge: 4 * time.Second, Latency: 85 * time.Millisecond},
+		{URL: "https://wrong.example", ExpectedChainID: 10, ChainID: 1, Problems: []string{"serves chain 1, not chain 10"}},
+		{URL: "https://eth.example", ExpectedChainID: 1, ChainID: 1, LatestBlock: 20, BlockAge: 12 * time.Second, Latency: 120 * time.Millisecond},
+	}
+
+	var buf bytes.Buffer
+	if err := FormatRPCHealthTerminal(checks, &buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"Chain 10 (OP Mainnet)",
+		"✅ https://op.example: block 1000 (4s old), 85ms",
+		"https://wrong.example: serves chain 1, not chain 10",
+		"Chain 1 (Ethereum)",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Count(out, "Chain 10") != 1 {
+		t.Errorf("chain heading repeated:\n%s", out)
+	}
+}
+
+func TestFormatSafeProfilesTerminal(t *testing.T) {
+	profiles := []safes.Profile{
+		{Name: "foundation-upgrade", Network: "op", Safe: "0x2501c477D0A35545a387Aa4A3EEe4292A9a8B3F0", Description: "Protocol upgrades", Policy: "/etc/op-txverify/upgrade-denylist.txt"},
+		{Name: "grants", Network: "ethereum", Safe: "0xE2Ed962948005AB01F2cEfE8326a0730B7D268af"},
+	}
+
+	var buf bytes.Buffer
+	if err := FormatSafeProfilesTerminal(profiles, &buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"foundation-upgrade",
+		"Protocol upgrades",
+		"Safe: 0x2501c477D0A35545a387Aa4A3EEe4292A9a8B3F0",
+		"Policy: /etc/op-txverify/upgrade-denylist.txt",
+		"Network: ethereum",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Count(out, "Policy:") != 1 {
+		t.Errorf("policy shown for a profile without one:\n%s", out)
+	}
+
+	buf.Reset()
+	if err := FormatSafeProfilesTerminal(nil, &buf); err != nil || !strings.Contains(buf.String(), "No saved profiles") {
+		t.Errorf("unexpected output without profiles: %v\n%s", err, buf.String())
+	}
+}
+
+func TestFormatCeremonyTerminal(t *testing.T) {
+	signature := core.CeremonySignature{
+		Transaction: "op",
+		Network:     "op",
+		Safe:        "0x2501c477D0A35545a387Aa4A3EEe4292A9a8B3F0",
+		Nonce:       97,
+		DomainHash:  "0x" + strings.Repeat("aa", 32),
+		MessageHash: "0x" + strings.Repeat("bb", 32),
+		SafeTxHash:  "0x" + strings.Repeat("cc", 32),
+	}
+	report := &core.CeremonyReport{
+		Name: "Upgrade 14",
+		Transactions: []core.CeremonyCheck{{
+			Name:       "op",
+			Network:    "op",
+			Safe:       signature.Safe,
+			Nonce:      97,
+			Signers:    []string{"Foundation"},
+			SafeTxHash: signature.SafeTxHash,
+		}},
+		Signers: []core.CeremonySigner{{Signer: "Foundation", Signatures: []core.CeremonySignature{signature}}},
+	}
+
+	var buf bytes.Buffer
+	if err := FormatCeremonyTerminal(report, &buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"CEREMONY: UPGRADE 14",
+		"op: nonce 97 of 0x2501c477D0A35545a387Aa4A3EEe4292A9a8B3F0 on op",
+		"HASHES BY SIGNER",
+		"Foundation",
+		"Domain Hash:  0x" + strings.Repeat("AA", 32),
+		"Safe Tx Hash: 0x" + strings.Repeat("CC", 32),
+		"Every transaction makes its intended call",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+
+	report.Transactions[0].Problems = []string{"the calldata hashes to 0x12, not 0x34"}
+	buf.Reset()
+	if err := FormatCeremonyTerminal(report, &buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out := buf.String(); !strings.Contains(out, "the calldata hashes to 0x12, not 0x34") || !strings.Contains(out, "DO NOT SIGN") {
+		t.Errorf("problems not shown:\n%s", out)
+	}
+}
+
+func TestFormatCoverageTerminal(t *testing.T) {
+	report := &core.CoverageReport{Calls: 4, Full: 2, Unknown: 1, NoCalldata: 1, Undecoded: []core.SelectorCoverage{{
+		Selector: "0xdeadbeef",
+		Kind:     core.CoverageUnknown,
+		Calls:    1,
+		Targets:  []string{"0x1111111111111111111111111111111111111111"},
+		Links:    []string{"https://www.4byte.directory/signatures/?bytes4_signature=0xdeadbeef"},
+	}}}
+
+	var buf bytes.Buffer
+	if err := FormatCoverageTerminal(report, &buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"DECODING COVERAGE",
+		"Fully decoded: 2",
+		"Unknown function: 1",
+		"MISSING SELECTORS",
+		"0xdeadbeef (unknown function) ×1",
+		"Target: 0x1111111111111111111111111111111111111111",
+		"bytes4_signature=0xdeadbeef",
+		"KnownABIJSON",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+
+	buf.Reset()
+	if err := FormatCoverageTerminal(&core.CoverageReport{Calls: 1, Full: 1}, &buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out := buf.String(); !strings.Contains(out, "Every call with calldata decoded fully") || strings.Contains(out, "MISSING SELECTORS") {
+		t.Errorf("unexpected output for full coverage:\n%s", out)
+	}
+}
+
+func TestFormatRegistryDiffTerminal(t *testing.T) {
+	diff := &registry.Diff{
+		Added:   []registry.Change{{Kind: "label", Key: "10:0x1111111111111111111111111111111111111111", New: "Treasury"}},
+		Changed: []registry.Change{{Kind: "token", Key: "1:0x2222222222222222222222222222222222222222", Old: "TKN (18 decimals)", New: "TKN (6 decimals)"}},
+		Removed: []registry.Change{{Kind: "function", Key: "claim(uint256)", Old: "0x379607f5"}},
+	}
+	signedBy := map[string]string{"https://registry.example/labels.json": "0x3333333333333333333333333333333333333333"}
+
+	var buf bytes.Buffer
+	if err := FormatRegistryDiffTerminal(diff, signedBy, &buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"REGISTRY SYNC",
+		"https://registry.example/labels.json",
+		"Signed by: 0x3333333333333333333333333333333333333333",
+		"+ label 10:0x1111111111111111111111111111111111111111: Treasury",
+		"~ token 1:0x2222222222222222222222222222222222222222: TKN (18 decimals) → TKN (6 decimals)",
+		"- function claim(uint256): 0x379607f5",
+		"1 added, 1 changed, 1 removed",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+
+	buf.Reset()
+	if err := FormatRegistryDiffTerminal(&registry.Diff{}, signedBy, &buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(buf.String(), "No changes.") {
+		t.Errorf("unexpected output without changes:\n%s", buf.String())
+	}
+}
+
+func TestFormatTerminalCompliance(t *testing.T) {
+	six := 6
+	result := &core.VerificationResult{
+		Transaction: core.SafeTransaction{
+			Safe:  "0x4444444444444444444444444444444444444444",
+			Chain: 10,
+			To:    "0x1111111111111111111111111111111111111111",
+			Value: big.NewInt(0),
+			Data:  "0x",
+		},
+		ApproveHash: "0x" + strings.Repeat("cc", 32),
+		Call:        core.CallData{Target: "0x1111111111111111111111111111111111111111", RawData: "0x"},
+		Compliance: &core.ComplianceSummary{
+			Schema:     core.ComplianceSchema,
+			ChainID:    10,
+			Network:    "OP Mainnet",
+			Safe:       "0x4444444444444444444444444444444444444444",
+			Nonce:      3,
+			SafeTxHash: "0x" + strings.Repeat("cc", 32),
+			Flows: []core.ValueFlow{
+				{ChainID: 10, Network: "OP Mainnet", From: "0x4444444444444444444444444444444444444444", Type: core.EffectTransfer, Asset: "USDC", AssetAddress: "0x0b2C639c533813f4Aa9D7837CAf62653d097Ff85", Decimals: &six, Amount: "2.5", RawAmount: "2500000", Counterparty: "0x1111111111111111111111111111111111111111", Label: "Payroll"},
+				{ChainID: 10, Network: "OP Mainnet", From: "0x4444444444444444444444444444444444444444", Type: core.EffectApproval, Asset: "0x3333333333333333333333333333333333333333", AssetAddress: "0x3333333333333333333333333333333333333333", Amount: "500", RawAmount: "500", Counterparty: "0x2222222222222222222222222222222222222222"},
+			},
+			Notes: []string{"0x4444444444444444444444444444444444444444 #2 delegatecalls 0x5555555555555555555555555555555555555555; any value it moves is not listed"},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := FormatTerminal(result, &buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"COMPLIANCE: VALUE FLOWS",
+		"Safe: 0x4444444444444444444444444444444444444444 on OP Mainnet (chain 10), nonce 3",
+		"1. TRANSFER 2.5 USDC (0x0b2C639c533813f4Aa9D7837CAf62653d097Ff85) to 0x1111111111111111111111111111111111111111 (Payroll)",
+		"2. APPROVAL 500 raw units 0x3333333333333333333333333333333333333333 approved for 0x2222222222222222222222222222222222222222",
+		"#2 delegatecalls",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Index(out, "COMPLIANCE: VALUE FLOWS") < strings.Index(out, "HASHES") {
+		t.Error("compliance summary should follow the technical detail")
+	}
+}
+
+func TestFormatTerminalSchedule(t *testing.T) {
+	checkedAt := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	closes := checkedAt.Add(50 * time.Hour)
+	result := &core.VerificationResult{
+		Transaction: core.SafeTransaction{
+			Safe:  "0x2501c477D0A35545a387Aa4A3EEe4292A9a8B3F0",
+			Chain: 10,
+			To:    "0x2501c477D0A35545a387Aa4A3EEe4292A9a8B3F0",
+			Value: big.NewInt(0),
+			Data:  "0x",
+		},
+		Call: core.CallData{Target: "0x2501c477D0A35545a387Aa4A3EEe4292A9a8B3F0"},
+		Schedule: &core.ScheduleStatus{
+			SigningWindow: core.SigningWindow{Closes: &closes, Description: "Security Council veto period"},
+			CheckedAt:     checkedAt,
+			Remaining:     "2d 2h",
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := FormatTerminal(result, &buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{"SIGNING WINDOW", "Security Council veto period", "Deadline: 2025-06-03T14:00:00Z", "Time Remaining: 2d 2h"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+
+	result.Schedule.CheckedAt, result.Schedule.Remaining = closes.Add(time.Hour), ""
+	buf.Reset()
+	if err := FormatTerminal(result, &buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out := buf.String(); !strings.Contains(out, "THE DEADLINE HAS PASSED") || strings.Contains(out, "Time Remaining") {
+		t.Errorf("passed deadline not shown:\n%s", out)
+	}
+}
+
+func TestFormatTerminalCallFlow(t *testing.T) {
+	transfer := func(index string) core.CallData {
+		return core.CallData{Index: index, Target: "0x0b2C639c533813f4Aa9D7837CAf62653d097Ff85", FunctionName: "transfer", ParsedData: []core.Argument{{Name: "amount", Value: "1"}}}
+	}
+	child := core.SafeTransaction{Safe: "0x2501c477D0A35545a387Aa4A3EEe4292A9a8B3F0", Chain: 10, Nonce: 7, Operation: 1, Value: big.NewInt(0)}
+	result := &core.VerificationResult{
+		Transaction: core.SafeTransaction{
+			Safe:  "0xE2Ed962948005AB01F2cEfE8326a0730B7D268af",
+			Chain: 10,
+			To:    child.Safe,
+			Value: big.NewInt(0),
+			Data:  "0x",
+		},
+		Call: core.CallData{Target: child.Safe, FunctionName: "approveHash"},
+		NestedResult: &core.VerificationResult{
+			Transaction: child,
+			Call: core.CallData{
+				Target:       "0x40A2aCCbd92BCA938b02010E17A5b8929b49130D",
+				TargetName:   "MultiSendCallOnly",
+				FunctionName: "multiSend",
+				SubCalls: []core.CallData{
+					{Index: "1", Target: "0x1111111111111111111111111111111111111111", FunctionName: "unknown", RawData: "0x"},
+					{Index: "2", Target: "0x2222222222222222222222222222222222222222", FunctionName: "upgrade", IsDelegateCall: true, TargetLabel: "Upgrader"},
+					transfer("3"), transfer("4"), transfer("5"),
+				},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := FormatTerminal(result, &buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out := buf.String()
+	flow := out[strings.Index(out, "CALL FLOW"):strings.Index(out, "CHILD TRANSACTION DETECTED")]
+	for _, want := range []string{
+		"Safe 0xE2Ed962948005AB01F2cEfE8326a0730B7D268af",
+		"└─ CALL 0x2501c477D0A35545a387Aa4A3EEe4292A9a8B3F0",
+		"   └─ approves Safe 0x2501c477D0A35545a387Aa4A3EEe4292A9a8B3F0",
+		"nonce 7",
+		"      └─ DELEGATECALL 0x40A2aCCbd92BCA938b02010E17A5b8929b49130D (MultiSendCallOnly 🔍) · multiSend",
+		"         ├─ #1 CALL 0x1111111111111111111111111111111111111111 · no calldata",
+		"         ├─ #2 DELEGATECALL 0x2222222222222222222222222222222222222222 (Upgrader) · upgrade",
+		"         └─ #3–#5 CALL 0x0b2C639c533813f4Aa9D7837CAf62653d097Ff85",
+		"transfer ×3",
+	} {
+		if !strings.Contains(flow, want) {
+			t.Errorf("call flow missing %q:\n%s", want, flow)
+		}
+	}
+	if strings.Index(out, "CALL FLOW") > strings.Index(out, "FUNCTION CALL DETAILS") {
+		t.Errorf("call flow is not above the call details")
+	}
+}
+
+func TestPrintFlowCallValues(t *testing.T) {
+	plain := func(a ...interface{}) string { return fmt.Sprint(a...) }
+	call := core.CallData{Target: "0x40A2aCCbd92BCA938b02010E17A5b8929b49130D", FunctionName: "multiSend"}
+	for i := 1; i <= 5; i++ {
+		call.SubCalls = append(call.SubCalls, core.CallData{
+			Index:        strconv.Itoa(i),
+			Target:       core.OPTokenAddress,
+			FunctionName: "transfer",
+			ParsedData:   []core.Argument{{Name: "amount", Value: "1"}},
+		})
+	}
+	call.SubCalls[3].Value = new(big.Int).Mul(big.NewInt(100), big.NewInt(1e18))
+
+	var buf bytes.Buffer
+	printFlowCall(&buf, "", true, call, false, TerminalOptions{}, plain)
+	out := buf.String()
+	if strings.Contains(out, "×5") || !strings.Contains(out, "#1–#3 CALL") {
+		t.Errorf("a call with another value should not be collapsed with the others:\n%s", out)
+	}
+	if !strings.Contains(out, "#4 CALL 0x4200000000000000000000000000000000000042 · transfer · 100.00 ETH") {
+		t.Errorf("the call sending ETH should show its value:\n%s", out)
+	}
+
+	for i := range call.SubCalls {
+		call.SubCalls[i].Value = big.NewInt(1e18)
+	}
+	buf.Reset()
+	printFlowCall(&buf, "", true, call, false, TerminalOptions{}, plain)
+	if out := buf.String(); !strings.Contains(out, "transfer ×5 · 1.00 ETH each, 5.00 ETH in total") {
+		t.Errorf("a collapsed run should show the value of each call and the total:\n%s", out)
+	}
+}
+
+func TestPrintProposal(t *testing.T) {
+	plain := func(a ...interface{}) string { return fmt.Sprint(a...) }
+
+	var buf bytes.Buffer
+	printProposal(&buf, &core.Proposal{
+		Proposer:   "0x3041ba32f451f5850c147805f5521ac206421623",
+		Submitted:  "2024-05-01T12:00:00Z",
+		OriginName: "Unknown",
+		OriginURL:  "https://example.com",
+		Owners:     []string{"0x9a69d97a451643a0Bb4462476942D2bC844431cE"},
+	}, plain, plain)
+	for _, want := range []string{"Proposed By: 0x3041", "NOT AN OWNER", "Proposed With: Unknown (https://example.com) ⚠️  UNKNOWN APP", "Proposed At: 2024-05-01T12:00:00Z"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("expected %q in:\n%s", want, buf.String())
+		}
+	}
+
+	buf.Reset()
+	printProposal(&buf, &core.Proposal{
+		Proposer: "0x9a69d97a451643a0bb4462476942d2bc844431ce",
+		Owners:   []string{"0x9a69d97a451643a0Bb4462476942D2bC844431cE"},
+	}, plain, plain)
+	if !strings.Contains(buf.String(), "(owner)") || !strings.Contains(buf.String(), "Proposed With: not recorded") {
+		t.Errorf("unexpected output for an owner's proposal without an origin:\n%s", buf.String())
+	}
+}
+
+func TestPrintWarningsCodes(t *testing.T) {
+	plain := func(a ...interface{}) string { return fmt.Sprint(a...) }
+	var buf bytes.Buffer
+	printWarnings(&buf, []core.Warning{
+		{Code: "OPTX-W003", Severity: core.SeverityWarning, Message: "2 other transaction(s) are queued"},
+		{Code: "OPTX-W011", Severity: core.SeverityCritical, Message: "on the denylist"},
+	}, plain, plain, plain, plain)
+	out := buf.String()
+	for _, want := range []string{
+		"❌ CRITICAL [OPTX-W011]: on the denylist",
+		"⚠️  WARNING [OPTX-W003]: 2 other transaction(s) are queued",
+		"op-txverify explain <code>",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+
+	buf.Reset()
+	printWarnings(&buf, []core.Warning{{Severity: core.SeverityInfo, Message: "uncoded"}}, plain, plain, plain, plain)
+	if out := buf.String(); !strings.Contains(out, "ℹ️  INFO: uncoded") || strings.Contains(out, "explain") {
+		t.Errorf("unexpected output for a warning without a code:\n%s", out)
+	}
+}
+
+func TestFormatWarningCheckTerminal(t *testing.T) {
+	check, err := core.LookupWarningCheck("OPTX-W007")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := FormatWarningCheckTerminal(check, &buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{"OPTX-W007: PROPOSED WITH AN UNKNOWN APP", "What it means\n" + check.Meaning, "Why it matters\n" + check.Why, "What to do\n" + check.Action} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("output missing %q:\n%s", want, buf.String())
+		}
+	}
+}
+
+func TestFormatCheckManifestTerminal(t *testing.T) {
+	manifest, err := core.NewCheckManifest("v1.2.3", "abc123")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := FormatCheckManifestTerminal(manifest, &buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{"Build: v1.2.3 (abc123)", "Digest: " + manifest.Digest, "service-hash v1\n", "Warnings: OPTX-W001\n"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("output missing %q:\n%s", want, buf.String())
+		}
+	}
+}
+
+func TestFormatDoctorReportTerminal(t *testing.T) {
+	report := &doctor.Report{Version: "v1.2.3", Commit: "abc123", Offline: true}
+	report.Add("network", doctor.Pass, "https://safe.example is unreachable, as expected offline")
+	report.Add("registry", doctor.Warn, "never synced")
+
+	var buf bytes.Buffer
+	if err := FormatDoctorReportTerminal(report, &buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{"Mode: offline", "✅ network   https://safe.example is unreachable", "⚠️  registry  never synced", "READY (1 warning(s) to review)"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("output missing %q:\n%s", want, buf.String())
+		}
+	}
+
+	report.Add("clock", doctor.Fail, "the clock is 10m0s ahead of https://safe.example")
+	buf.Reset()
+	if err := FormatDoctorReportTerminal(report, &buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(buf.String(), "NOT READY: 1 check(s) failed") {
+		t.Errorf("expected the machine not to be ready:\n%s", buf.String())
+	}
+}
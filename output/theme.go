@@ -0,0 +1,125 @@
+package output
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+// colorRole is what a piece of terminal output is colored for. Themes map each role to the
+// attributes it is printed with.
+type colorRole int
+
+const (
+	// colorHeading is section headings
+	colorHeading colorRole = iota
+	// colorDivider is the rules under headings
+	colorDivider
+	// colorLabel is field labels, such as "Safe:"
+	colorLabel
+	// colorBold is emphasized values
+	colorBold
+	// colorCaution is values to double check, such as unlabelled addresses
+	colorCaution
+	// colorWarning is warnings
+	colorWarning
+	// colorImportant is critical warnings and failed checks
+	colorImportant
+	// colorSuccess is passed checks
+	colorSuccess
+	// colorNote is annotations the facilitator added
+	colorNote
+)
+
+// Theme names
+const (
+	ThemeDefault      = "default"
+	ThemeHighContrast = "high-contrast"
+	ThemeColorblind   = "colorblind-safe"
+	ThemeMonochrome   = "monochrome"
+)
+
+// themes are the selectable color themes. The default is the original cyan, magenta, yellow, and
+// red scheme, which assumes a dark background. High contrast prints warnings and results on solid
+// backgrounds, which read on light and dark terminals alike. Colorblind-safe tells results apart
+// by blue and magenta rather than green and red, and marks the serious ones with underline or
+// reverse video too. Monochrome uses no color, only bold, underline, and reverse video.
+var themes = map[string]map[colorRole][]color.Attribute{
+	ThemeDefault: {
+		colorHeading:   {color.FgCyan, color.Bold},
+		colorDivider:   {color.FgCyan},
+		colorLabel:     {color.FgMagenta},
+		colorBold:      {color.Bold},
+		colorCaution:   {color.FgYellow},
+		colorWarning:   {color.FgYellow, color.Bold},
+		colorImportant: {color.FgRed, color.Bold},
+		colorSuccess:   {color.FgGreen, color.Bold},
+		colorNote:      {color.FgGreen},
+	},
+	ThemeHighContrast: {
+		colorHeading:   {color.Bold, color.Underline},
+		colorDivider:   {color.Bold},
+		colorLabel:     {color.Bold},
+		colorBold:      {color.Bold},
+		colorCaution:   {color.Underline},
+		colorWarning:   {color.FgBlack, color.BgYellow, color.Bold},
+		colorImportant: {color.FgHiWhite, color.BgRed, color.Bold},
+		colorSuccess:   {color.FgBlack, color.BgGreen, color.Bold},
+		colorNote:      {color.Italic},
+	},
+	ThemeColorblind: {
+		colorHeading:   {color.FgBlue, color.Bold},
+		colorDivider:   {color.FgBlue},
+		colorLabel:     {color.Bold},
+		colorBold:      {color.Bold},
+		colorCaution:   {color.FgYellow, color.Underline},
+		colorWarning:   {color.FgYellow, color.Bold, color.Underline},
+		colorImportant: {color.FgMagenta, color.Bold, color.ReverseVideo},
+		colorSuccess:   {color.FgBlue, color.Bold},
+		colorNote:      {color.FgBlue},
+	},
+	ThemeMonochrome: {
+		colorHeading:   {color.Bold, color.Underline},
+		colorDivider:   {},
+		colorLabel:     {},
+		colorBold:      {color.Bold},
+		colorCaution:   {color.Underline},
+		colorWarning:   {color.Bold},
+		colorImportant: {color.Bold, color.ReverseVideo},
+		colorSuccess:   {color.Bold},
+		colorNote:      {},
+	},
+}
+
+// activeTheme is the theme terminal output is printed with
+var activeTheme = themes[ThemeDefault]
+
+// ThemeNames lists the selectable themes
+func ThemeNames() []string {
+	names := make([]string, 0, len(themes))
+	for name := range themes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SetTheme selects the theme of terminal output. An empty name selects the default theme.
+func SetTheme(name string) error {
+	if name == "" {
+		name = ThemeDefault
+	}
+	theme, ok := themes[name]
+	if !ok {
+		return fmt.Errorf("unknown theme %q; choose one of %s", name, strings.Join(ThemeNames(), ", "))
+	}
+	activeTheme = theme
+	return nil
+}
+
+// themed returns the color of a role in the active theme
+func themed(role colorRole) *color.Color {
+	return color.New(activeTheme[role]...)
+}
@@ -0,0 +1,52 @@
+package output
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/fatih/color"
+)
+
+func TestThemesColorEveryRole(t *testing.T) {
+	for name, theme := range themes {
+		for role := colorHeading; role <= colorNote; role++ {
+			if _, ok := theme[role]; !ok {
+				t.Errorf("theme %s does not define role %d", name, role)
+			}
+		}
+	}
+}
+
+func TestSetTheme(t *testing.T) {
+	defer SetTheme(ThemeDefault)
+	noColor := color.NoColor
+	color.NoColor = false
+	defer func() { color.NoColor = noColor }()
+
+	if err := SetTheme("solarized"); err == nil || !strings.Contains(err.Error(), "high-contrast") {
+		t.Errorf("expected an unknown theme error listing the themes, got %v", err)
+	}
+
+	if err := SetTheme(ThemeMonochrome); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, role := range []colorRole{colorHeading, colorDivider, colorLabel, colorCaution, colorWarning, colorImportant, colorSuccess, colorNote} {
+		out := themed(role).Sprint("text")
+		for _, match := range regexp.MustCompile(`\x1b\[([0-9;]*)m`).FindAllStringSubmatch(out, -1) {
+			for _, param := range strings.Split(match[1], ";") {
+				if code, _ := strconv.Atoi(param); code >= 30 && code <= 49 || code >= 90 && code <= 107 {
+					t.Errorf("monochrome role %d printed a color: %q", role, out)
+				}
+			}
+		}
+	}
+
+	if err := SetTheme(""); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := themed(colorImportant).Sprint("x"); got != "\x1b[31;1mx\x1b[0;22m" {
+		t.Errorf("expected the default theme to print critical text in bold red, got %q", got)
+	}
+}
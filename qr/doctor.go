@@ -0,0 +1,48 @@
+package qr
+
+import (
+	"net"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/ethereum-optimism/op-txverify/doctor"
+)
+
+// CheckCamera adds to a readiness report whether the camera scanner of `qr` can start: that its
+// port is free, that the browser it opens can be started, and, where they can be listed, that a
+// camera is attached
+func CheckCamera(report *doctor.Report, options Options) {
+	listener, err := net.Listen("tcp", cameraServerAddr)
+	if err != nil {
+		report.Add("camera", doctor.Fail, "the scanner cannot listen on %s: %v", cameraServerAddr, err)
+		return
+	}
+	listener.Close()
+
+	cameraURL := "http://localhost" + cameraServerAddr
+	args, ok := browserCommands[runtime.GOOS]
+	if !ok {
+		args = defaultBrowserCommand
+	}
+	if options.NoExec {
+		report.Add("camera", doctor.Warn, "the browser is not started with --no-exec; open %s by hand to scan", cameraURL)
+		return
+	}
+	if _, err := exec.LookPath(args[0]); err != nil {
+		report.Add("camera", doctor.Warn, "%s is not installed to open the scanner; open %s by hand to scan", args[0], cameraURL)
+		return
+	}
+
+	if runtime.GOOS != "linux" {
+		report.Add("camera", doctor.Pass, "the scanner can start; the browser asks for the camera when it opens")
+		return
+	}
+	devices, _ := filepath.Glob("/dev/video*")
+	if len(devices) == 0 {
+		report.Add("camera", doctor.Warn, "no camera device found under /dev/video*")
+		return
+	}
+	report.Add("camera", doctor.Pass, "the scanner can start; found %s", strings.Join(devices, ", "))
+}
@@ -0,0 +1,16 @@
+package qr
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ethereum-optimism/op-txverify/doctor"
+)
+
+func TestCheckCameraNoExec(t *testing.T) {
+	report := &doctor.Report{}
+	CheckCamera(report, Options{NoExec: true})
+	if len(report.Checks) != 1 || report.Checks[0].Status != doctor.Warn || !strings.Contains(report.Checks[0].Detail, "open http://localhost:8081 by hand") {
+		t.Errorf("unexpected camera check %+v", report.Checks)
+	}
+}
@@ -0,0 +1,254 @@
+// Package parts splits data too large for one QR code into parts and assembles them again, for
+// moving transactions and signatures across an air gap. It only deals in the text of the codes,
+// so it works with any camera, scanner, or display, such as a scanner that types each code it
+// reads as a line.
+package parts
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Prefix starts the text of every part. A code without it is a complete transfer by itself.
+const Prefix = "PART:"
+
+// transferIDSize is the length in hex of a transfer ID, the start of the SHA-256 of the whole data
+const transferIDSize = 16
+
+// ErrOtherTransfer is the error of a part that does not belong to the transfer being assembled
+var ErrOtherTransfer = errors.New("part of another transfer")
+
+// checksumPrefix starts the optional checksum of a part. Neither JSON nor base64 data can start
+// with it, so data is never mistaken for a checksum.
+const checksumPrefix = "sha256="
+
+// Part is one code of a multi-part transfer, written as "PART:index:total:transfer:data", or
+// "PART:index:total:transfer:sha256=<hex>:data" with the SHA-256 of its data. Indexes start at 1.
+// The transfer ID is the first 8 bytes of the SHA-256 of the whole data, in hex, so parts of two
+// transfers are never mixed and the assembled data can be checked.
+type Part struct {
+	Index    int
+	Total    int
+	Transfer string
+	Checksum string
+	Data     string
+}
+
+// String returns the text of the code that carries the part
+func (p Part) String() string {
+	if p.Checksum != "" {
+		return fmt.Sprintf("%s%d:%d:%s:%s%s:%s", Prefix, p.Index, p.Total, p.Transfer, checksumPrefix, p.Checksum, p.Data)
+	}
+	return fmt.Sprintf("%s%d:%d:%s:%s", Prefix, p.Index, p.Total, p.Transfer, p.Data)
+}
+
+// IsPart reports whether the text of a code is a part of a multi-part transfer
+func IsPart(text string) bool {
+	return strings.HasPrefix(text, Prefix)
+}
+
+// ParsePart parses the text of a part and checks its checksum, when it has one
+func ParsePart(text string) (Part, error) {
+	if !IsPart(text) {
+		return Part{}, fmt.Errorf("not a part: does not start with %s", Prefix)
+	}
+	fields := strings.SplitN(strings.TrimPrefix(text, Prefix), ":", 4)
+	if len(fields) != 4 {
+		return Part{}, fmt.Errorf("invalid part: expected %sindex:total:transfer:data", Prefix)
+	}
+	index, err := strconv.Atoi(fields[0])
+	if err != nil {
+		return Part{}, fmt.Errorf("invalid part index %q", fields[0])
+	}
+	total, err := strconv.Atoi(fields[1])
+	if err != nil {
+		return Part{}, fmt.Errorf("invalid part total %q", fields[1])
+	}
+	if total < 1 || index < 1 || index > total {
+		return Part{}, fmt.Errorf("invalid part %d of %d", index, total)
+	}
+	if _, err := hex.DecodeString(fields[2]); err != nil || len(fields[2]) != transferIDSize {
+		return Part{}, fmt.Errorf("invalid part %d of %d: transfer ID %q is not %d hex digits", index, total, fields[2], transferIDSize)
+	}
+	part := Part{Index: index, Total: total, Transfer: strings.ToLower(fields[2]), Data: fields[3]}
+
+	if rest, ok := strings.CutPrefix(part.Data, checksumPrefix); ok {
+		checksum, data, ok := strings.Cut(rest, ":")
+		if !ok {
+			return Part{}, fmt.Errorf("invalid part %d of %d: checksum without data", index, total)
+		}
+		if sum := checksumOf(data); !strings.EqualFold(checksum, sum) {
+			return Part{}, fmt.Errorf("part %d of %d is corrupted: checksum %s, expected %s", index, total, sum, checksum)
+		}
+		part.Checksum, part.Data = checksum, data
+	}
+	return part, nil
+}
+
+// checksumOf returns the hex SHA-256 of the data of a part
+func checksumOf(data string) string {
+	sum := sha256.Sum256([]byte(data))
+	return hex.EncodeToString(sum[:])
+}
+
+// transferID returns the ID of the transfer of data
+func transferID(data string) string {
+	return checksumOf(data)[:transferIDSize]
+}
+
+// Split splits data into parts of at most size bytes of data, each with the transfer ID and a
+// checksum
+func Split(data string, size int) ([]Part, error) {
+	if size < 1 {
+		return nil, fmt.Errorf("invalid part size %d", size)
+	}
+	total := (len(data) + size - 1) / size
+	if total == 0 {
+		total = 1
+	}
+	transfer := transferID(data)
+	parts := make([]Part, total)
+	for i := range parts {
+		chunk := data[min(i*size, len(data)):min((i+1)*size, len(data))]
+		parts[i] = Part{Index: i + 1, Total: total, Transfer: transfer, Checksum: checksumOf(chunk), Data: chunk}
+	}
+	return parts, nil
+}
+
+// Progress is the state of a transfer after a code was added
+type Progress struct {
+	// Index is the part just added, and Total how many the transfer has
+	Index    int
+	Total    int
+	Transfer string
+
+	// Received is how many different parts have been added
+	Received int
+
+	// Duplicate is set when the part had been added before, as happens when a code is scanned
+	// again
+	Duplicate bool
+}
+
+// Remaining returns how many parts are still missing
+func (p Progress) Remaining() int {
+	return p.Total - p.Received
+}
+
+// Assembler collects the parts of a transfer in any order until it is complete. A part of
+// another transfer is rejected until the transfer is complete or Reset. It is not safe for
+// concurrent use.
+type Assembler struct {
+	// OnProgress, when set, is called after each part is added
+	OnProgress func(Progress)
+
+	total    int
+	transfer string
+	parts    map[int]string
+}
+
+// Add adds the text of a scanned code. Once every part has been added, it checks the assembled
+// data against the transfer ID and returns it and true, and the assembler is ready for the next
+// transfer. A code that is not a part is returned as it is.
+func (a *Assembler) Add(text string) (string, bool, error) {
+	if !IsPart(text) {
+		return text, true, nil
+	}
+	part, err := ParsePart(text)
+	if err != nil {
+		return "", false, err
+	}
+	if a.parts == nil {
+		a.total, a.transfer = part.Total, part.Transfer
+		a.parts = make(map[int]string, part.Total)
+	} else if part.Transfer != a.transfer || part.Total != a.total {
+		return "", false, fmt.Errorf("%w: part %d of %d is from transfer %s, not %s of %d parts", ErrOtherTransfer, part.Index, part.Total, part.Transfer, a.transfer, a.total)
+	}
+	_, duplicate := a.parts[part.Index]
+	a.parts[part.Index] = part.Data
+
+	if a.OnProgress != nil {
+		a.OnProgress(Progress{Index: part.Index, Total: part.Total, Transfer: part.Transfer, Received: len(a.parts), Duplicate: duplicate})
+	}
+	if len(a.parts) < a.total {
+		return "", false, nil
+	}
+
+	var data strings.Builder
+	for i := 1; i <= a.total; i++ {
+		data.WriteString(a.parts[i])
+	}
+	transfer := a.transfer
+	a.Reset()
+	if id := transferID(data.String()); id != transfer {
+		return "", false, fmt.Errorf("assembled data does not match transfer %s (its ID is %s); scan the transfer again", transfer, id)
+	}
+	return data.String(), true, nil
+}
+
+// Received returns how many different parts of the current transfer have been added, and how
+// many it has
+func (a *Assembler) Received() (int, int) {
+	return len(a.parts), a.total
+}
+
+// Reset drops the parts added so far
+func (a *Assembler) Reset() {
+	a.total, a.transfer = 0, ""
+	a.parts = nil
+}
+
+// maxLineSize bounds a line read by Receive; the largest QR code holds under 3 KB
+const maxLineSize = 64 * 1024
+
+// Receive reads the text of one scanned code per line from r, skipping empty lines, until a
+// transfer is complete, and returns its data. onProgress, when not nil, is called after each
+// part.
+func Receive(r io.Reader, onProgress func(Progress)) (string, error) {
+	assembler := &Assembler{OnProgress: onProgress}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 4096), maxLineSize)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimRight(scanner.Text(), "\r")
+		if text == "" {
+			continue
+		}
+		data, complete, err := assembler.Add(text)
+		if err != nil {
+			return "", fmt.Errorf("line %d: %w", line, err)
+		}
+		if complete {
+			return data, nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+	if received, total := assembler.Received(); total > 0 {
+		return "", fmt.Errorf("%w: received %d of %d parts", io.ErrUnexpectedEOF, received, total)
+	}
+	return "", errors.New("no code was read")
+}
+
+// Send splits data into parts of at most size bytes of data and writes the text of each as a
+// line to w, to be shown as QR codes one after the other
+func Send(w io.Writer, data string, size int) error {
+	parts, err := Split(data, size)
+	if err != nil {
+		return err
+	}
+	for _, part := range parts {
+		if _, err := fmt.Fprintln(w, part.String()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
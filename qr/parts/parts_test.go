@@ -0,0 +1,148 @@
+package parts
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestSendReceive(t *testing.T) {
+	data := `{"safe":"0x2501c477D0A35545a387Aa4A3EEe4292A9a8B3F0","nonce":155}`
+	var sent strings.Builder
+	if err := Send(&sent, data, 16); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	lines := strings.Split(strings.TrimSuffix(sent.String(), "\n"), "\n")
+	if len(lines) != 5 || !strings.HasPrefix(lines[0], "PART:1:5:"+transferID(data)+":sha256=") {
+		t.Fatalf("unexpected parts:\n%s", sent.String())
+	}
+
+	// Scanned out of order, with a repeat and blank lines in between
+	scanned := strings.Join([]string{lines[3], lines[0], "", lines[3], lines[4], lines[2], lines[1]}, "\r\n")
+	var progress []Progress
+	received, err := Receive(strings.NewReader(scanned), func(p Progress) { progress = append(progress, p) })
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if received != data {
+		t.Errorf("received %q, sent %q", received, data)
+	}
+	if len(progress) != 6 || !progress[2].Duplicate || progress[2].Received != 2 || progress[5].Remaining() != 0 || progress[0].Transfer != transferID(data) {
+		t.Errorf("unexpected progress %+v", progress)
+	}
+}
+
+func TestReceiveSingleCode(t *testing.T) {
+	received, err := Receive(strings.NewReader("\n{\"nonce\":1}\nPART:1:2:ignored\n"), nil)
+	if err != nil || received != `{"nonce":1}` {
+		t.Errorf("received %q, %v", received, err)
+	}
+}
+
+func TestReceiveIncomplete(t *testing.T) {
+	id := transferID("abc")
+	_, err := Receive(strings.NewReader("PART:1:3:"+id+":a\nPART:3:3:"+id+":c\n"), nil)
+	if !errors.Is(err, io.ErrUnexpectedEOF) || !strings.Contains(err.Error(), "received 2 of 3 parts") {
+		t.Errorf("expected an incomplete transfer, got %v", err)
+	}
+	if _, err := Receive(strings.NewReader("\n\n"), nil); err == nil {
+		t.Error("expected an error without any code")
+	}
+}
+
+func TestAssemblerRejectsOtherTransfers(t *testing.T) {
+	stale, err := Split("stale data", 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	current, err := Split("abc:d", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var assembler Assembler
+	if _, complete, err := assembler.Add(stale[0].String()); complete || err != nil {
+		t.Fatalf("unexpected result: %v, %v", complete, err)
+	}
+	// Another transfer is rejected, even with the same number of parts
+	other, _ := Split("other data", 5)
+	if _, _, err := assembler.Add(other[1].String()); !errors.Is(err, ErrOtherTransfer) {
+		t.Fatalf("expected a part of another transfer to be rejected, got %v", err)
+	}
+	if _, _, err := assembler.Add(current[0].String()); !errors.Is(err, ErrOtherTransfer) {
+		t.Fatalf("expected a part of another transfer to be rejected, got %v", err)
+	}
+	if received, total := assembler.Received(); received != 1 || total != 2 {
+		t.Errorf("received %d of %d parts", received, total)
+	}
+
+	assembler.Reset()
+	for _, i := range []int{1, 0} {
+		if _, complete, err := assembler.Add(current[i].String()); complete || err != nil {
+			t.Fatalf("unexpected result: %v, %v", complete, err)
+		}
+	}
+	data, complete, err := assembler.Add(current[2].String())
+	if err != nil || !complete || data != "abc:d" {
+		t.Errorf("assembled %q, %v, %v", data, complete, err)
+	}
+	if received, total := assembler.Received(); received != 0 || total != 0 {
+		t.Errorf("expected the assembler to be reset, got %d of %d", received, total)
+	}
+}
+
+func TestAssemblerChecksAssembledData(t *testing.T) {
+	// Parts without checksums that claim the ID of other data
+	id := transferID("hello world")
+	var assembler Assembler
+	assembler.Add("PART:1:2:" + id + ":hello ")
+	if _, complete, err := assembler.Add("PART:2:2:" + id + ":there"); complete || err == nil || !strings.Contains(err.Error(), "does not match transfer "+id) {
+		t.Errorf("expected the assembled data to be rejected, got %v, %v", complete, err)
+	}
+	if received, total := assembler.Received(); received != 0 || total != 0 {
+		t.Errorf("expected the assembler to be reset, got %d of %d", received, total)
+	}
+}
+
+func TestParsePart(t *testing.T) {
+	part, err := ParsePart("PART:007:10:0123456789ABCDEF:data")
+	if err != nil || part.Index != 7 || part.Total != 10 || part.Transfer != "0123456789abcdef" || part.Data != "data" || part.Checksum != "" {
+		t.Errorf("unexpected part %+v, %v", part, err)
+	}
+
+	parts, err := Split("payload", 100)
+	if err != nil || len(parts) != 1 {
+		t.Fatalf("unexpected parts %+v, %v", parts, err)
+	}
+	if parsed, err := ParsePart(parts[0].String()); err != nil || parsed != parts[0] {
+		t.Errorf("round trip gave %+v, %v", parsed, err)
+	}
+
+	id := transferID("payload")
+	for _, text := range []string{
+		"{}",
+		"PART:1:2",
+		"PART:1:2:data",
+		"PART:1:2:0123:data",
+		"PART:1:2:0123456789abcdeg:data",
+		"PART:abc:2:" + id + ":data",
+		"PART:1:x:" + id + ":data",
+		"PART:-1:2:" + id + ":data",
+		"PART:0:2:" + id + ":data",
+		"PART:3:2:" + id + ":data",
+		"PART:1:1:" + id + ":sha256=00",
+		"PART:1:1:" + id + ":sha256=" + checksumOf("payload") + ":corrupted",
+	} {
+		if _, err := ParsePart(text); err == nil {
+			t.Errorf("expected %q to be rejected", text)
+		}
+	}
+
+	if _, err := Split("payload", 0); err == nil {
+		t.Error("expected a part size of 0 to be rejected")
+	}
+	if parts, _ := Split("", 10); len(parts) != 1 || parts[0].Total != 1 {
+		t.Errorf("expected one empty part, got %+v", parts)
+	}
+}
@@ -0,0 +1,278 @@
+// Package qr scans QR codes with the camera and shows them, through pages served from this
+// machine and opened in the browser. It is kept apart from core so that programs using the
+// verification logic do not embed the web pages or start a camera server.
+package qr
+
+import (
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	"html/template"
+	"net"
+	"net/http"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum-optimism/op-txverify/core"
+	"github.com/ethereum-optimism/op-txverify/qr/parts"
+)
+
+//go:embed web/reader.html web/index.html web/lib/*
+var templateFS embed.FS
+
+// ScanQRCode opens the camera device and scans for a QR code
+// Returns the decoded string content of the QR code. Cancelling ctx stops the scan and
+// shuts down the local camera server.
+func ScanQRCode(ctx context.Context, deviceID string, options Options) (string, error) {
+	// Extended timeout for multi-part scanning
+	ctx, cancel := context.WithTimeout(ctx, 300*time.Second)
+	defer cancel()
+
+	// Create channels to receive the QR code result; buffered so handlers never block
+	resultChan := make(chan string, 1)
+	errChan := make(chan error, 1)
+
+	// Bind the port up front so failures are reported before we tell the user to scan
+	listener, err := net.Listen("tcp", cameraServerAddr)
+	if err != nil {
+		return "", fmt.Errorf("error starting camera server: %w", err)
+	}
+
+	server, err := newCameraServer(resultChan)
+	if err != nil {
+		listener.Close()
+		return "", err
+	}
+
+	// Start the server
+	go func() {
+		if err := server.Serve(listener); err != nil && err != http.ErrServerClosed {
+			errChan <- fmt.Errorf("server error: %w", err)
+		}
+	}()
+
+	// Always shut the server down so the port is released and handler goroutines exit
+	defer func() {
+		shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer shutdownCancel()
+		server.Shutdown(shutdownCtx)
+
+		// Serve may not have started tracking the listener yet, so close it explicitly too
+		listener.Close()
+	}()
+
+	fmt.Println("Camera activated. Point camera at QR code...")
+	fmt.Println("For multi-part QR codes, scan each code in sequence.")
+	cameraURL := "http://localhost" + cameraServerAddr
+	if options.NoExec {
+		fmt.Println("Open " + cameraURL + " in a browser to start the camera.")
+	} else {
+		fmt.Println("A browser window should open automatically at " + cameraURL)
+	}
+	fmt.Println("Press Ctrl+C to cancel")
+
+	// Open the browser
+	openBrowser(cameraURL, options)
+
+	// Wait for result, cancellation, or timeout
+	select {
+	case result := <-resultChan:
+		return result, nil
+	case err := <-errChan:
+		return "", err
+	case <-ctx.Done():
+		if ctx.Err() == context.DeadlineExceeded {
+			return "", fmt.Errorf("timeout waiting for QR code")
+		}
+		return "", ctx.Err()
+	}
+}
+
+// DisplayQRCode shows a payload as QR codes in a local browser window, using the same encoding
+// the hosted QR page uses for transactions, so the qr scanner on another machine can read it.
+// The page is served from this machine only and needs no network access. It blocks until ctx
+// is cancelled.
+func DisplayQRCode(ctx context.Context, payload []byte, options Options) error {
+	listener, err := net.Listen("tcp", displayServerAddr)
+	if err != nil {
+		return fmt.Errorf("error starting QR display server: %w", err)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/lib/", serveLib)
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		data, err := templateFS.ReadFile("web/index.html")
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write(data)
+	})
+	server := &http.Server{Handler: mux}
+
+	errChan := make(chan error, 1)
+	go func() {
+		if err := server.Serve(listener); err != nil && err != http.ErrServerClosed {
+			errChan <- fmt.Errorf("server error: %w", err)
+		}
+	}()
+	defer func() {
+		shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer shutdownCancel()
+		server.Shutdown(shutdownCtx)
+		listener.Close()
+	}()
+
+	// The page reads the payload from the tx parameter and starts displaying immediately
+	encoded, err := core.EncodeLinkPayload(payload)
+	if err != nil {
+		return err
+	}
+	link := "http://" + displayServerAddr + "/?tx=" + encoded
+	if options.NoExec {
+		fmt.Println("Open " + link + " in a browser to show the QR codes.")
+	} else {
+		fmt.Println("Showing QR codes at " + link)
+	}
+	fmt.Println("Run op-txverify on the other machine to scan them, then press Ctrl+C here.")
+	openBrowser(link, options)
+
+	select {
+	case err := <-errChan:
+		return err
+	case <-ctx.Done():
+		return nil
+	}
+}
+
+// cameraServerAddr is the local address the camera server listens on
+const cameraServerAddr = ":8081"
+
+// displayServerAddr is the loopback address the QR display server listens on
+const displayServerAddr = "127.0.0.1:8082"
+
+// serveLib serves the embedded JavaScript libraries with proper MIME types
+func serveLib(w http.ResponseWriter, r *http.Request) {
+	// The URL path is /lib/something, but in the embedded FS it's web/lib/something
+	path := "web" + r.URL.Path
+
+	data, err := templateFS.ReadFile(path)
+	if err != nil {
+		http.Error(w, "File not found: "+path, http.StatusNotFound)
+		return
+	}
+
+	// Set the correct content type based on file extension
+	if strings.HasSuffix(path, ".js") {
+		w.Header().Set("Content-Type", "application/javascript")
+	} else if strings.HasSuffix(path, ".css") {
+		w.Header().Set("Content-Type", "text/css")
+	} else if strings.HasSuffix(path, ".wasm") {
+		w.Header().Set("Content-Type", "application/wasm")
+	}
+
+	w.Write(data)
+}
+
+// newCameraServer builds the HTTP server that hosts the scanner page and receives results
+func newCameraServer(resultChan chan<- string) (*http.Server, error) {
+	// Create a template from the embedded file
+	tmpl, err := template.ParseFS(templateFS, "web/reader.html")
+	if err != nil {
+		return nil, fmt.Errorf("error creating template: %w", err)
+	}
+
+	mux := http.NewServeMux()
+
+	// Serve static files from the embedded filesystem with proper MIME types
+	mux.HandleFunc("/lib/", serveLib)
+
+	// Assembles multi-part QR codes; handlers run concurrently
+	var (
+		assembler parts.Assembler
+		qrMutex   sync.Mutex
+	)
+
+	// deliver hands the result to ScanQRCode without blocking if a result was already delivered
+	deliver := func(result string) {
+		select {
+		case resultChan <- result:
+		default:
+		}
+	}
+
+	// Handle the root path
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		tmpl.Execute(w, nil)
+	})
+
+	// Handle the result endpoint
+	mux.HandleFunc("/result", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		// Get the QR code data, a complete transfer or one part of a multi-part one
+		r.ParseForm()
+		qrMutex.Lock()
+		var progress parts.Progress
+		assembler.OnProgress = func(p parts.Progress) { progress = p }
+		data, complete, err := assembler.Add(r.FormValue("data"))
+		qrMutex.Unlock()
+
+		switch {
+		case err != nil:
+			w.Write([]byte(`{"success":false}`))
+		case complete:
+			deliver(data)
+			w.Write([]byte(`{"success":true,"complete":true}`))
+		default:
+			// Send progress update
+			response := fmt.Sprintf(`{"success":true,"complete":false,"partIndex":%d,"totalParts":%d,"remaining":%d}`,
+				progress.Index, progress.Total, progress.Remaining())
+			w.Write([]byte(response))
+		}
+	})
+
+	return &http.Server{Addr: cameraServerAddr, Handler: mux}, nil
+}
+
+// browserCommands is the program that opens a URL in the default browser on each platform.
+// Windows uses url.dll rather than "cmd /c start", which splits the URL at every &.
+var browserCommands = map[string][]string{
+	"darwin":  {"open"},
+	"windows": {"rundll32", "url.dll,FileProtocolHandler"},
+}
+
+// defaultBrowserCommand opens a URL on the platforms browserCommands does not list
+var defaultBrowserCommand = []string{"xdg-open"}
+
+// Options controls how the pages are opened
+type Options struct {
+	// NoExec forbids starting other programs, such as the browser, for sandboxes that do not let
+	// processes spawn others. The URLs of the pages are printed to open by hand instead.
+	NoExec bool
+}
+
+// ErrNoExec is the error of anything that would start another program while Options.NoExec
+// forbids it
+var ErrNoExec = errors.New("starting other programs is disabled")
+
+// openBrowser opens the default browser to the specified URL. The URL is always printed as well,
+// so a failure only means it has to be opened by hand.
+func openBrowser(url string, options Options) error {
+	if options.NoExec {
+		return ErrNoExec
+	}
+	args, ok := browserCommands[runtime.GOOS]
+	if !ok {
+		args = defaultBrowserCommand
+	}
+	return exec.Command(args[0], append(args[1:], url)...).Start()
+}
@@ -0,0 +1,97 @@
+package qr
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/ethereum-optimism/op-txverify/qr/parts"
+)
+
+func TestScanQRCodeCancelReleasesPort(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := ScanQRCode(ctx, "", Options{}); !errors.Is(err, context.Canceled) {
+		t.Fatalf("ScanQRCode error = %v, want context.Canceled", err)
+	}
+
+	// The camera server must have released its port
+	listener, err := net.Listen("tcp", cameraServerAddr)
+	if err != nil {
+		t.Fatalf("camera server port still bound after cancel: %v", err)
+	}
+	listener.Close()
+}
+
+func TestDisplayQRCodeCancelReleasesPort(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := DisplayQRCode(ctx, []byte(`{}`), Options{}); err != nil {
+		t.Fatalf("DisplayQRCode error = %v, want nil after cancel", err)
+	}
+
+	listener, err := net.Listen("tcp", displayServerAddr)
+	if err != nil {
+		t.Fatalf("display server port still bound after cancel: %v", err)
+	}
+	listener.Close()
+}
+
+func TestNoExecOpensNoBrowser(t *testing.T) {
+	options := Options{NoExec: true}
+	if err := openBrowser("http://localhost"+cameraServerAddr, options); !errors.Is(err, ErrNoExec) {
+		t.Errorf("openBrowser error = %v, want ErrNoExec", err)
+	}
+
+	// The local server still starts and stops; only the browser is left to the user
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := DisplayQRCode(ctx, []byte(`{}`), options); err != nil {
+		t.Fatalf("DisplayQRCode error = %v, want nil after cancel", err)
+	}
+}
+
+func TestCameraServerAssemblesParts(t *testing.T) {
+	resultChan := make(chan string, 1)
+	server, err := newCameraServer(resultChan)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	post := func(data string) string {
+		recorder := httptest.NewRecorder()
+		request := httptest.NewRequest(http.MethodPost, "/result", strings.NewReader(url.Values{"data": {data}}.Encode()))
+		request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		server.Handler.ServeHTTP(recorder, request)
+		return recorder.Body.String()
+	}
+
+	sent, err := parts.Split("hello world", 6)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := parts.Split("other world", 6)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if response := post(sent[1].String()); response != `{"success":true,"complete":false,"partIndex":2,"totalParts":2,"remaining":1}` {
+		t.Errorf("unexpected response %s", response)
+	}
+	for _, rejected := range []string{"PART:3:2:oops", other[0].String()} {
+		if response := post(rejected); response != `{"success":false}` {
+			t.Errorf("%s: unexpected response %s", rejected, response)
+		}
+	}
+	if response := post(sent[0].String()); response != `{"success":true,"complete":true}` {
+		t.Errorf("unexpected response %s", response)
+	}
+	if result := <-resultChan; result != "hello world" {
+		t.Errorf("assembled %q", result)
+	}
+}
@@ -0,0 +1,262 @@
+// Package registry syncs the local registry cache from signed label, token, ABI, and hash files
+// and shows what a sync changes. It is used by the command line tool and lives outside core, which
+// only applies a registry it is given.
+package registry
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/ethereum-optimism/op-txverify/core"
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// DefaultCachePath returns the registry cache that is loaded automatically when present
+func DefaultCachePath() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "op-txverify", "registry.json"), nil
+}
+
+// VerifySignature checks that a file is signed by one of the trusted signers and returns the
+// signer. The signature is a 65-byte personal_sign signature over the file, in hex.
+func VerifySignature(data []byte, signature string, signers []string) (string, error) {
+	sig, err := hex.DecodeString(strings.TrimPrefix(strings.TrimSpace(signature), "0x"))
+	if err != nil || len(sig) != 65 {
+		return "", fmt.Errorf("signature must be 65 bytes of hex")
+	}
+	if sig[64] >= 27 {
+		sig[64] -= 27
+	}
+	pub, err := crypto.SigToPub(accounts.TextHash(data), sig)
+	if err != nil {
+		return "", fmt.Errorf("failed to recover signer: %w", err)
+	}
+	signer := crypto.PubkeyToAddress(*pub).Hex()
+	for _, trusted := range signers {
+		if strings.EqualFold(trusted, signer) {
+			return signer, nil
+		}
+	}
+	return "", fmt.Errorf("signed by %s, which is not a trusted registry signer", signer)
+}
+
+// FetchFile downloads a registry file and its signature and checks that a trusted signer signed
+// it. It returns the file and its signer.
+func FetchFile(ctx context.Context, url string, signers []string) ([]byte, string, error) {
+	if !strings.HasPrefix(url, "https://") {
+		return nil, "", fmt.Errorf("registry source URL must use https: %s", url)
+	}
+	data, err := fetchHTTPS(ctx, url)
+	if err != nil {
+		return nil, "", err
+	}
+	signature, err := fetchHTTPS(ctx, url+".sig")
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to fetch the signature of %s: %w", url, err)
+	}
+	signer, err := VerifySignature(data, string(signature), signers)
+	if err != nil {
+		return nil, "", fmt.Errorf("%s: %w", url, err)
+	}
+	return data, signer, nil
+}
+
+// fetchHTTPS downloads a file
+func fetchHTTPS(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error creating request for %s: %w", url, err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("request to %s failed with status: %s", url, resp.Status)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s: %w", url, err)
+	}
+	return data, nil
+}
+
+// Sync downloads the configured sources into a copy of the cached registry. Sources that are not
+// configured keep their cached entries. It returns the new registry and the signer of each file.
+func Sync(ctx context.Context, cached *core.Registry, sources core.RegistrySources) (*core.Registry, map[string]string, error) {
+	if err := sources.Validate(); err != nil {
+		return nil, nil, err
+	}
+	updated := *cached
+	signedBy := map[string]string{}
+
+	if sources.Labels != "" {
+		data, signer, err := FetchFile(ctx, sources.Labels, sources.Signers)
+		if err != nil {
+			return nil, nil, err
+		}
+		if updated.Labels, err = core.ParseRegistryLabels(sources.Labels, data); err != nil {
+			return nil, nil, err
+		}
+		signedBy[sources.Labels] = signer
+	}
+	if sources.Tokens != "" {
+		data, signer, err := FetchFile(ctx, sources.Tokens, sources.Signers)
+		if err != nil {
+			return nil, nil, err
+		}
+		if updated.Tokens, err = core.ParseTokenList(sources.Tokens, data); err != nil {
+			return nil, nil, err
+		}
+		signedBy[sources.Tokens] = signer
+	}
+	if sources.ABIs != "" {
+		data, signer, err := FetchFile(ctx, sources.ABIs, sources.Signers)
+		if err != nil {
+			return nil, nil, err
+		}
+		if updated.ABIs, err = core.ParseABIManifest(sources.ABIs, data); err != nil {
+			return nil, nil, err
+		}
+		signedBy[sources.ABIs] = signer
+	}
+	if sources.Hashes != "" {
+		data, signer, err := FetchFile(ctx, sources.Hashes, sources.Signers)
+		if err != nil {
+			return nil, nil, err
+		}
+		if updated.Hashes, err = core.ParseRegistryHashes(sources.Hashes, data); err != nil {
+			return nil, nil, err
+		}
+		signedBy[sources.Hashes] = signer
+	}
+	return &updated, signedBy, nil
+}
+
+// Change is an entry a sync adds, removes, or changes
+type Change struct {
+	// Kind is "label", "token", "function", or "hash"
+	Kind string `json:"kind"`
+
+	// Key identifies the entry: "<chain>:<address>" for labels and tokens, the signature for
+	// functions, and the value for hashes
+	Key string `json:"key"`
+	Old string `json:"old,omitempty"`
+	New string `json:"new,omitempty"`
+}
+
+// Diff is what a sync changes in the cached registry
+type Diff struct {
+	Added   []Change `json:"added,omitempty"`
+	Removed []Change `json:"removed,omitempty"`
+	Changed []Change `json:"changed,omitempty"`
+}
+
+// Empty reports whether nothing changed
+func (d *Diff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// NewLabels counts the labels and hashes that are added or renamed, which name addresses and
+// values in every later verification and so need the reviewer's approval
+func (d *Diff) NewLabels() int {
+	count := 0
+	for _, changes := range [][]Change{d.Added, d.Changed} {
+		for _, change := range changes {
+			if change.Kind == "label" || change.Kind == "hash" {
+				count++
+			}
+		}
+	}
+	return count
+}
+
+// Compare returns what changes between two registries
+func Compare(old, updated *core.Registry) *Diff {
+	diff := &Diff{}
+	compare := func(kind string, before, after map[string]string) {
+		for _, key := range sortedKeys(after) {
+			if value, ok := before[key]; !ok {
+				diff.Added = append(diff.Added, Change{Kind: kind, Key: key, New: after[key]})
+			} else if value != after[key] {
+				diff.Changed = append(diff.Changed, Change{Kind: kind, Key: key, Old: value, New: after[key]})
+			}
+		}
+		for _, key := range sortedKeys(before) {
+			if _, ok := after[key]; !ok {
+				diff.Removed = append(diff.Removed, Change{Kind: kind, Key: key, Old: before[key]})
+			}
+		}
+	}
+	compare("label", labelIndex(old), labelIndex(updated))
+	compare("token", tokenIndex(old), tokenIndex(updated))
+	compare("function", functionIndex(old), functionIndex(updated))
+	compare("hash", hashIndex(old), hashIndex(updated))
+	return diff
+}
+
+// labelIndex maps "<chain>:<address>" to the label
+func labelIndex(r *core.Registry) map[string]string {
+	index := map[string]string{}
+	for _, label := range r.Labels {
+		index[fmt.Sprintf("%d:%s", label.ChainID, label.Address)] = label.Name
+	}
+	return index
+}
+
+// tokenIndex maps "<chain>:<address>" to the token symbol and decimals
+func tokenIndex(r *core.Registry) map[string]string {
+	index := map[string]string{}
+	for _, token := range r.Tokens {
+		index[fmt.Sprintf("%d:%s", token.ChainID, token.Address)] = fmt.Sprintf("%s (%d decimals)", token.Symbol, token.Decimals)
+	}
+	return index
+}
+
+// functionIndex maps each function signature to its selector
+func functionIndex(r *core.Registry) map[string]string {
+	index := map[string]string{}
+	for _, abiJSON := range r.ABIs {
+		parsed, err := abi.JSON(strings.NewReader(string(abiJSON)))
+		if err != nil {
+			continue
+		}
+		for _, method := range parsed.Methods {
+			index[method.Sig] = "0x" + hex.EncodeToString(method.ID)
+		}
+	}
+	return index
+}
+
+// hashIndex maps each hash to its name
+func hashIndex(r *core.Registry) map[string]string {
+	index := map[string]string{}
+	for _, hash := range r.Hashes {
+		index[hash.Hash] = hash.Name
+	}
+	return index
+}
+
+// sortedKeys returns the keys of a map in order
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,139 @@
+package registry
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ethereum-optimism/op-txverify/core"
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Addresses the registry files label
+const (
+	airdropAlice = "0x1111111111111111111111111111111111111111"
+	airdropBob   = "0x2222222222222222222222222222222222222222"
+	airdropToken = "0x3333333333333333333333333333333333333333"
+)
+
+// signRegistryFile signs a registry file with personal_sign, as its publisher would
+func signRegistryFile(t *testing.T, data []byte) (string, string) {
+	t.Helper()
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig, err := crypto.Sign(accounts.TextHash(data), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig[64] += 27
+	return "0x" + hex.EncodeToString(sig), crypto.PubkeyToAddress(key.PublicKey).Hex()
+}
+
+func TestVerifySignature(t *testing.T) {
+	data := []byte(`{"labels": []}`)
+	signature, signer := signRegistryFile(t, data)
+
+	if got, err := VerifySignature(data, signature+"\n", []string{strings.ToLower(signer)}); err != nil || got != signer {
+		t.Fatalf("got %s, %v; want %s", got, err, signer)
+	}
+	if _, err := VerifySignature([]byte(`{"labels": [{}]}`), signature, []string{signer}); err == nil {
+		t.Error("expected an error for a modified file")
+	}
+	if _, err := VerifySignature(data, signature, []string{airdropAlice}); err == nil || !strings.Contains(err.Error(), "not a trusted registry signer") {
+		t.Errorf("expected an untrusted signer error, got %v", err)
+	}
+	if _, err := VerifySignature(data, "0x1234", []string{signer}); err == nil {
+		t.Error("expected an error for a short signature")
+	}
+}
+
+func TestSync(t *testing.T) {
+	labels := []byte(`{"labels": [{"chainId": 10, "address": "` + airdropAlice + `", "name": "Treasury"}]}`)
+	tokens := []byte(`{"name": "Tokens", "tokens": [{"chainId": 10, "address": "` + airdropToken + `", "symbol": "tkn", "decimals": 6}]}`)
+	labelsSig, signer := signRegistryFile(t, labels)
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/labels.json":
+			w.Write(labels)
+		case "/labels.json.sig":
+			w.Write([]byte(labelsSig))
+		case "/tokens.json":
+			w.Write(tokens)
+		case "/tokens.json.sig":
+			// Signed by someone else
+			sig, _ := signRegistryFile(t, tokens)
+			w.Write([]byte(sig))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	defaultClient := http.DefaultClient
+	http.DefaultClient = server.Client()
+	defer func() { http.DefaultClient = defaultClient }()
+
+	cached := &core.Registry{Tokens: []core.RegistryToken{{ChainID: 1, Address: core.ChecksumAddress(airdropBob), Symbol: "OLD", Decimals: 18}}}
+	updated, signedBy, err := Sync(context.Background(), cached, core.RegistrySources{Labels: server.URL + "/labels.json", Signers: []string{signer}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(updated.Labels) != 1 || updated.Labels[0].Name != "Treasury" || signedBy[server.URL+"/labels.json"] != signer {
+		t.Fatalf("unexpected registry: %+v, %v", updated, signedBy)
+	}
+	if len(updated.Tokens) != 1 || updated.Tokens[0].Symbol != "OLD" {
+		t.Errorf("tokens without a source should be kept: %+v", updated.Tokens)
+	}
+
+	_, _, err = Sync(context.Background(), cached, core.RegistrySources{Tokens: server.URL + "/tokens.json", Signers: []string{signer}})
+	if err == nil || !strings.Contains(err.Error(), "not a trusted registry signer") {
+		t.Errorf("expected an untrusted signer error, got %v", err)
+	}
+	if _, _, err := Sync(context.Background(), cached, core.RegistrySources{Labels: "http://example.com/labels.json", Signers: []string{signer}}); err == nil {
+		t.Error("expected an error for a plain http source")
+	}
+}
+
+func TestCompare(t *testing.T) {
+	transfer := json.RawMessage(`[{"inputs":[{"name":"to","type":"address"},{"name":"amount","type":"uint256"}],"name":"transfer","type":"function"}]`)
+	claim := json.RawMessage(`[{"inputs":[{"name":"id","type":"uint256"}],"name":"claimReward","type":"function"}]`)
+	old := &core.Registry{
+		Labels: []core.RegistryLabel{{ChainID: 10, Address: airdropAlice, Name: "Treasury"}, {ChainID: 10, Address: airdropBob, Name: "Old"}},
+		Tokens: []core.RegistryToken{{ChainID: 10, Address: airdropToken, Symbol: "TKN", Decimals: 18}},
+		ABIs:   []json.RawMessage{transfer},
+	}
+	updated := &core.Registry{
+		Labels: []core.RegistryLabel{{ChainID: 10, Address: airdropAlice, Name: "Treasury (new)"}, {ChainID: 1, Address: airdropBob, Name: "Bridge"}},
+		Tokens: []core.RegistryToken{{ChainID: 10, Address: airdropToken, Symbol: "TKN", Decimals: 18}},
+		ABIs:   []json.RawMessage{transfer, claim},
+	}
+
+	diff := Compare(old, updated)
+	if diff.Empty() || diff.NewLabels() != 2 {
+		t.Fatalf("unexpected diff: %+v", diff)
+	}
+	if len(diff.Added) != 2 || diff.Added[0].Key != "1:"+airdropBob || diff.Added[1].Key != "claimReward(uint256)" || diff.Added[1].New != "0xae169a50" {
+		t.Errorf("unexpected additions: %+v", diff.Added)
+	}
+	if len(diff.Changed) != 1 || diff.Changed[0].Old != "Treasury" || diff.Changed[0].New != "Treasury (new)" {
+		t.Errorf("unexpected changes: %+v", diff.Changed)
+	}
+	if len(diff.Removed) != 1 || diff.Removed[0].Key != "10:"+airdropBob {
+		t.Errorf("unexpected removals: %+v", diff.Removed)
+	}
+	if !Compare(updated, updated).Empty() {
+		t.Error("expected no changes between identical registries")
+	}
+
+	prestate := &core.Registry{Hashes: []core.RegistryHash{{Hash: "0x03ee2917da962ec266b091f4b62121dc9682bb0db534633707325339f99ee405", Name: "op-program v1.6.0 prestate"}}}
+	if diff := Compare(&core.Registry{}, prestate); diff.NewLabels() != 1 || diff.Added[0].Kind != "hash" {
+		t.Errorf("expected a new hash to need approval, got %+v", diff)
+	}
+}
@@ -0,0 +1,88 @@
+package safes
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ethereum-optimism/op-txverify/core"
+)
+
+// MaxHistoryEntries is the number of recently verified Safes that are remembered
+const MaxHistoryEntries = 10
+
+// HistoryEntry is a Safe a transaction was recently verified for
+type HistoryEntry struct {
+	Network  string    `json:"network"`
+	Safe     string    `json:"safe"`
+	LastUsed time.Time `json:"lastUsed"`
+}
+
+// History is the list of recently verified Safes, most recent first. It only records which
+// Safes were looked at, never transaction contents, and is used to offer them again when
+// prompting interactively.
+type History struct {
+	Safes []HistoryEntry `json:"safes"`
+}
+
+// DefaultHistoryPath returns the file recent Safes are remembered in
+func DefaultHistoryPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "op-txverify", "history.json"), nil
+}
+
+// LoadHistoryFile reads the history file at path. A missing file is an empty history.
+func LoadHistoryFile(path string) (*History, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return &History{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history: %w", err)
+	}
+
+	var history History
+	if err := json.Unmarshal(data, &history); err != nil {
+		return nil, fmt.Errorf("invalid history file %s: %w", path, err)
+	}
+	return &history, nil
+}
+
+// Record moves a Safe to the front of the history, dropping the oldest entries beyond
+// MaxHistoryEntries
+func (h *History) Record(network, safe string, now time.Time) {
+	safe = core.ChecksumAddress(core.StripChainPrefix(safe))
+	safes := []HistoryEntry{{Network: network, Safe: safe, LastUsed: now.UTC()}}
+	for _, entry := range h.Safes {
+		if entry.Network == network && strings.EqualFold(entry.Safe, safe) {
+			continue
+		}
+		if len(safes) == MaxHistoryEntries {
+			break
+		}
+		safes = append(safes, entry)
+	}
+	h.Safes = safes
+}
+
+// SaveHistoryFile writes the history to path, creating its directory if needed
+func (h *History) SaveHistoryFile(path string) error {
+	data, err := json.MarshalIndent(h, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("failed to create history directory: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o600); err != nil {
+		return fmt.Errorf("failed to write history: %w", err)
+	}
+	return nil
+}
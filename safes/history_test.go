@@ -0,0 +1,54 @@
+package safes
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestHistoryRecord(t *testing.T) {
+	start := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	history := &History{}
+	for i := 0; i < MaxHistoryEntries+2; i++ {
+		history.Record("op", fmt.Sprintf("0x%040x", i+1), start.Add(time.Duration(i)*time.Hour))
+	}
+	if len(history.Safes) != MaxHistoryEntries {
+		t.Fatalf("got %d entries, want %d", len(history.Safes), MaxHistoryEntries)
+	}
+
+	// Recording a known Safe again moves it to the front without duplicating it
+	history.Record("op", "oeth:"+fixtureGrantsSafe, start)
+	history.Record("ethereum", fixtureGrantsSafe, start)
+	history.Record("op", fixtureGrantsSafe, start.Add(time.Minute))
+	if len(history.Safes) != MaxHistoryEntries {
+		t.Fatalf("got %d entries after re-recording, want %d", len(history.Safes), MaxHistoryEntries)
+	}
+	if first := history.Safes[0]; first.Network != "op" || first.Safe != fixtureGrantsSafe || !first.LastUsed.Equal(start.Add(time.Minute)) {
+		t.Errorf("unexpected most recent entry: %+v", first)
+	}
+	if second := history.Safes[1]; second.Network != "ethereum" || second.Safe != fixtureGrantsSafe {
+		t.Errorf("the same Safe on another network should be a separate entry: %+v", second)
+	}
+}
+
+func TestHistoryFileRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "op-txverify", "history.json")
+
+	history, err := LoadHistoryFile(path)
+	if err != nil || len(history.Safes) != 0 {
+		t.Fatalf("missing file should load as empty history, got %+v, %v", history, err)
+	}
+
+	history.Record("base", fixtureParentSafe, time.Now())
+	if err := history.SaveHistoryFile(path); err != nil {
+		t.Fatalf("unexpected error saving: %v", err)
+	}
+	loaded, err := LoadHistoryFile(path)
+	if err != nil {
+		t.Fatalf("unexpected error loading: %v", err)
+	}
+	if len(loaded.Safes) != 1 || loaded.Safes[0].Safe != fixtureParentSafe || loaded.Safes[0].Network != "base" {
+		t.Fatalf("unexpected history: %+v", loaded.Safes)
+	}
+}
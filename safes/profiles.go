@@ -0,0 +1,133 @@
+// Package safes saves the Safes a signer works with: named profiles and the Safes verified
+// recently. It is used by the command line tool and lives outside core, which only verifies
+// transactions.
+package safes
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/ethereum-optimism/op-txverify/core"
+)
+
+// Profile is a named Safe, so signers can refer to it by name instead of copying its address
+type Profile struct {
+	Name        string `json:"name"`
+	Network     string `json:"network"`
+	Safe        string `json:"safe"`
+	Description string `json:"description,omitempty"`
+
+	// Policy is a denylist file of addresses this Safe must never interact with, loaded in
+	// addition to any other denylists whenever the profile is used
+	Policy string `json:"policy,omitempty"`
+}
+
+// Profiles is the set of saved Safe profiles, keyed by name
+type Profiles struct {
+	Profiles map[string]Profile
+}
+
+// profilesManifest is the JSON form of the profiles file
+type profilesManifest struct {
+	Profiles []Profile `json:"profiles"`
+}
+
+// DefaultProfilesPath returns the file Safe profiles are saved in
+func DefaultProfilesPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "op-txverify", "profiles.json"), nil
+}
+
+// LoadProfilesFile reads the profiles file at path. A missing file has no profiles.
+func LoadProfilesFile(path string) (*Profiles, error) {
+	profiles := &Profiles{Profiles: map[string]Profile{}}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return profiles, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read profiles: %w", err)
+	}
+
+	var manifest profilesManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("invalid profiles file %s: %w", path, err)
+	}
+	for _, profile := range manifest.Profiles {
+		if err := profiles.Add(profile); err != nil {
+			return nil, fmt.Errorf("invalid profiles file %s: %w", path, err)
+		}
+	}
+	return profiles, nil
+}
+
+// Add validates a profile and saves it under its name, which must not be taken
+func (p *Profiles) Add(profile Profile) error {
+	if profile.Name == "" || strings.ContainsAny(profile.Name, " \t\n") {
+		return fmt.Errorf("invalid profile name %q: names must be non-empty and contain no spaces", profile.Name)
+	}
+	if _, exists := p.Profiles[profile.Name]; exists {
+		return fmt.Errorf("profile %q already exists", profile.Name)
+	}
+	if err := core.ValidateNetwork(profile.Network); err != nil {
+		return fmt.Errorf("profile %q: %w", profile.Name, err)
+	}
+	if err := core.ValidateFullAddress("safe", core.StripChainPrefix(profile.Safe)); err != nil {
+		return fmt.Errorf("profile %q: %w", profile.Name, err)
+	}
+	profile.Safe = core.ChecksumAddress(core.StripChainPrefix(profile.Safe))
+	p.Profiles[profile.Name] = profile
+	return nil
+}
+
+// Remove deletes the profile with the given name
+func (p *Profiles) Remove(name string) error {
+	if _, exists := p.Profiles[name]; !exists {
+		return fmt.Errorf("no profile named %q", name)
+	}
+	delete(p.Profiles, name)
+	return nil
+}
+
+// Lookup returns the profile with the given name
+func (p *Profiles) Lookup(name string) (Profile, error) {
+	profile, ok := p.Profiles[name]
+	if !ok {
+		return Profile{}, fmt.Errorf("no profile named %q (see `op-txverify safes list`)", name)
+	}
+	return profile, nil
+}
+
+// Sorted returns the profiles ordered by name
+func (p *Profiles) Sorted() []Profile {
+	sorted := make([]Profile, 0, len(p.Profiles))
+	for _, profile := range p.Profiles {
+		sorted = append(sorted, profile)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+	return sorted
+}
+
+// SaveProfilesFile writes the profiles to path, creating its directory if needed
+func (p *Profiles) SaveProfilesFile(path string) error {
+	data, err := json.MarshalIndent(profilesManifest{Profiles: p.Sorted()}, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("failed to create profiles directory: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o600); err != nil {
+		return fmt.Errorf("failed to write profiles: %w", err)
+	}
+	return nil
+}
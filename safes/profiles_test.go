@@ -0,0 +1,89 @@
+package safes
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Safes the profiles and history are saved for
+const (
+	fixtureGrantsSafe = "0x2501c477D0A35545a387Aa4A3EEe4292A9a8B3F0"
+	fixtureParentSafe = "0xE2Ed962948005AB01F2cEfE8326a0730B7D268af"
+)
+
+func TestProfilesFileRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "op-txverify", "profiles.json")
+
+	profiles, err := LoadProfilesFile(path)
+	if err != nil || len(profiles.Profiles) != 0 {
+		t.Fatalf("missing file should load without profiles, got %+v, %v", profiles, err)
+	}
+
+	upgrade := Profile{Name: "foundation-upgrade", Network: "op", Safe: "oeth:" + strings.ToLower(fixtureGrantsSafe), Description: "Upgrade Safe", Policy: "/etc/op-txverify/upgrade-denylist.txt"}
+	if err := profiles.Add(upgrade); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := profiles.Add(Profile{Name: "parent", Network: "ethereum", Safe: fixtureParentSafe}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := profiles.SaveProfilesFile(path); err != nil {
+		t.Fatalf("unexpected error saving: %v", err)
+	}
+
+	loaded, err := LoadProfilesFile(path)
+	if err != nil {
+		t.Fatalf("unexpected error loading: %v", err)
+	}
+	got, err := loaded.Lookup("foundation-upgrade")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	upgrade.Safe = fixtureGrantsSafe
+	if got != upgrade {
+		t.Errorf("got %+v, want %+v", got, upgrade)
+	}
+	if sorted := loaded.Sorted(); len(sorted) != 2 || sorted[0].Name != "foundation-upgrade" || sorted[1].Name != "parent" {
+		t.Errorf("unexpected order: %+v", sorted)
+	}
+
+	if err := loaded.Remove("parent"); err != nil {
+		t.Fatalf("unexpected error removing: %v", err)
+	}
+	if _, err := loaded.Lookup("parent"); err == nil {
+		t.Errorf("removed profile is still found")
+	}
+	if err := loaded.Remove("parent"); err == nil {
+		t.Errorf("expected an error removing a missing profile")
+	}
+}
+
+func TestProfilesAddRejectsInvalidProfiles(t *testing.T) {
+	profiles := &Profiles{Profiles: map[string]Profile{}}
+	if err := profiles.Add(Profile{Name: "ops", Network: "op", Safe: fixtureGrantsSafe}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for name, profile := range map[string]Profile{
+		"duplicate name":    {Name: "ops", Network: "op", Safe: fixtureParentSafe},
+		"empty name":        {Network: "op", Safe: fixtureParentSafe},
+		"name with spaces":  {Name: "ops safe", Network: "op", Safe: fixtureParentSafe},
+		"unknown network":   {Name: "other", Network: "arbitrum", Safe: fixtureParentSafe},
+		"truncated address": {Name: "other", Network: "op", Safe: "0x2501…B3F0"},
+	} {
+		if err := profiles.Add(profile); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestLoadProfilesFileRejectsInvalidFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "profiles.json")
+	if err := os.WriteFile(path, []byte(`{"profiles": [{"name": "ops", "network": "op", "safe": "0x1234"}]}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadProfilesFile(path); err == nil {
+		t.Fatalf("expected an error for a profile with a truncated address")
+	}
+}